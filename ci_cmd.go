@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"prmate/internal/github"
+	"prmate/internal/logging"
+	"prmate/internal/review"
+	"prmate/internal/webhook"
+)
+
+// severityRank orders violation severities; higher is more severe
+var severityRank = map[string]int{
+	"suggestion": 1,
+	"warning":    2,
+	"error":      3,
+}
+
+const ciUsage = `Usage: prmate ci [--event-path <file>] [--fail-on <severity>] [--junit <file>] [--code-quality <file>] [flags]
+
+Reviews the pull request of a GitHub Actions run once and exits non-zero on blocking findings.
+LLM provider flags and environment variables are the same as for the server.
+`
+
+// runCI implements `prmate ci`: it reviews the pull request described by
+// the GitHub Actions event file once, posts comments, and exits 1 when
+// violations at or above --fail-on were found
+func runCI(args []string) int {
+	var eventPath, failOn, junitPath, codeQualityPath string
+	cfg, _, err := parseFlags("ci", ciUsage, args, func(fs *flag.FlagSet) {
+		fs.StringVar(&eventPath, "event-path", os.Getenv("GITHUB_EVENT_PATH"), "Path to the pull_request event payload (env: GITHUB_EVENT_PATH)")
+		fs.StringVar(&failOn, "fail-on", "error", "Lowest severity that fails the run: error, warning, suggestion or never")
+		fs.StringVar(&junitPath, "junit", "", "Also write findings as a JUnit XML report to this file")
+		fs.StringVar(&codeQualityPath, "code-quality", "", "Also write findings as a GitLab code quality JSON report to this file")
+	})
+	if err != nil {
+		return exitCode(err)
+	}
+	if _, ok := severityRank[failOn]; !ok && failOn != "never" {
+		fmt.Fprintf(os.Stderr, "invalid --fail-on %q: must be error, warning, suggestion or never\n", failOn)
+		return 2
+	}
+	if eventPath == "" {
+		fmt.Fprintln(os.Stderr, "no event payload: set GITHUB_EVENT_PATH or pass --event-path")
+		return 2
+	}
+	if cfg.GitHubToken == "" {
+		fmt.Fprintln(os.Stderr, "GITHUB_TOKEN is required")
+		return 2
+	}
+
+	owner, repo, prNumber, err := readPREvent(eventPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read event: %v\n", err)
+		return 2
+	}
+
+	githubClient, err := newCIClient(cfg.GitHubToken, os.Getenv("GITHUB_API_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "create github client: %v\n", err)
+		return 2
+	}
+	githubClient.SetDryRun(cfg.DryRun, cfg.DryRunRepoList())
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ctx = logging.With(ctx, "repo", owner+"/"+repo, "pr", prNumber)
+
+	llmSvc, err := startLLM(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer llmSvc.Stop()
+
+	branding, err := newBranding(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	reviewSvc := review.NewService(githubClient, llmSvc, review.Config{
+		LLMTimeout:    cfg.LLMTimeout,
+		ReviewTimeout: cfg.ReviewTimeout,
+		Offline:       cfg.Offline,
+		Branding:      branding,
+		SecretScan:    cfg.SecretScan,
+		WorkflowScan:  cfg.WorkflowScan,
+		Persona:       cfg.ReviewPersona,
+	})
+
+	pr, err := githubClient.GetPullRequest(ctx, owner, repo, prNumber)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "get pull request: %v\n", err)
+		return 1
+	}
+	if !reviewSvc.HasPRMateFile(ctx, owner, repo, pr.ConfigRef()) {
+		fmt.Fprintln(os.Stdout, "No .prmate.md found, skipping review.")
+		return 0
+	}
+
+	processor := webhook.NewProcessor(nil, nil, reviewSvc, githubClient)
+	result, err := processor.ReviewPullRequest(ctx, owner, repo, prNumber)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "review: %v\n", err)
+		return 1
+	}
+
+	printFindings(os.Stdout, result)
+	if err := writeReport(junitPath, result, review.WriteJUnit); err != nil {
+		fmt.Fprintf(os.Stderr, "write junit report: %v\n", err)
+		return 1
+	}
+	if err := writeReport(codeQualityPath, result, review.WriteCodeQuality); err != nil {
+		fmt.Fprintf(os.Stderr, "write code quality report: %v\n", err)
+		return 1
+	}
+	if n := countBlocking(result.Violations, failOn); n > 0 {
+		fmt.Fprintf(os.Stdout, "%d findings at or above %q severity\n", n, failOn)
+		return 1
+	}
+	return 0
+}
+
+// readPREvent extracts the repository and pull request number from a
+// pull_request or pull_request_target event payload
+func readPREvent(path string) (owner, repo string, prNumber int, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", 0, err
+	}
+	return parsePREvent(data)
+}
+
+func parsePREvent(data []byte) (owner, repo string, prNumber int, err error) {
+	// pull_request_target payloads have the same shape as pull_request ones
+	parsed, err := github.ParseEvent("pull_request", data)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("decode event: %w", err)
+	}
+	event := parsed.(*github.PullRequestEvent)
+	prNumber = event.PullRequest.Number
+	if prNumber == 0 {
+		return "", "", 0, fmt.Errorf("event has no pull request; run prmate ci on pull_request events")
+	}
+	owner, repo, err = github.ParseRepoFullName(event.Repo)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("parse repo name: %w", err)
+	}
+	return owner, repo, prNumber, nil
+}
+
+// newCIClient returns a github.com client, or a GitHub Enterprise client
+// when the Actions runner reports a different API URL
+func newCIClient(token, apiURL string) (*github.Client, error) {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	if apiURL == "" || apiURL == "https://api.github.com" {
+		return github.NewClient(token), nil
+	}
+	return github.NewEnterpriseClient(token, apiURL+"/")
+}
+
+// writeReport renders result into path with write; an empty path is a no-op
+func writeReport(path string, result *review.ReviewResult, write func(io.Writer, *review.ReviewResult) error) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, result); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// countBlocking returns how many violations are at or above failOn
+func countBlocking(violations []review.FileViolation, failOn string) int {
+	threshold, ok := severityRank[failOn]
+	if !ok {
+		return 0
+	}
+	n := 0
+	for _, v := range violations {
+		if severityRank[v.Severity] >= threshold {
+			n++
+		}
+	}
+	return n
+}
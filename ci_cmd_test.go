@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	"prmate/internal/review"
+)
+
+func TestParsePREvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		owner   string
+		repo    string
+		pr      int
+		wantErr bool
+	}{
+		{
+			name:    "pull request event",
+			payload: `{"action":"opened","number":7,"pull_request":{"number":7},"repository":{"full_name":"acme/widgets"}}`,
+			owner:   "acme",
+			repo:    "widgets",
+			pr:      7,
+		},
+		{name: "push event", payload: `{"ref":"refs/heads/main","repository":{"full_name":"acme/widgets"}}`, wantErr: true},
+		{name: "missing repo", payload: `{"pull_request":{"number":7}}`, wantErr: true},
+		{name: "invalid json", payload: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, repo, pr, err := parsePREvent([]byte(tt.payload))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePREvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if owner != tt.owner || repo != tt.repo || pr != tt.pr {
+				t.Errorf("parsePREvent() = %s/%s#%d, want %s/%s#%d", owner, repo, pr, tt.owner, tt.repo, tt.pr)
+			}
+		})
+	}
+}
+
+func TestCountBlocking(t *testing.T) {
+	violations := []review.FileViolation{
+		{Severity: "error"},
+		{Severity: "warning"},
+		{Severity: "warning"},
+		{Severity: "suggestion"},
+	}
+
+	tests := []struct {
+		failOn string
+		want   int
+	}{
+		{"error", 1},
+		{"warning", 3},
+		{"suggestion", 4},
+		{"never", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.failOn, func(t *testing.T) {
+			if got := countBlocking(violations, tt.failOn); got != tt.want {
+				t.Errorf("countBlocking(%q) = %d, want %d", tt.failOn, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"prmate/internal/config"
+	"prmate/internal/eval"
+	"prmate/internal/github"
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+const evalUsage = `Usage: prmate eval --cases <dir> [--a <variant.json>] [--b <variant.json>] [--json]
+       prmate eval --record <n> --cases <dir> [--repo <owner/repo>] [--instance <name>]
+
+Replays the recorded pull requests in --cases through two prompt and model
+configurations and compares their precision and recall against the human
+labels of each case. A variant file sets "name", "provider", "model" and
+"instructions"; without --a the configured provider and model are the
+baseline.
+
+With --record, writes the n most recently reviewed pull requests in the
+review store to --cases instead, with labels seeded from PRMate's review
+threads for a human to check.
+`
+
+// runEval implements `prmate eval`
+func runEval(args []string) int {
+	var casesDir, variantA, variantB, repoName, instanceName string
+	var record int
+	var asJSON bool
+	cfg, _, err := parseFlags("eval", evalUsage, args, func(fs *flag.FlagSet) {
+		fs.StringVar(&casesDir, "cases", "", "Directory of recorded pull requests, one JSON file each")
+		fs.StringVar(&variantA, "a", "", "Baseline variant file (default: the configured provider and model)")
+		fs.StringVar(&variantB, "b", "", "Candidate variant file")
+		fs.BoolVar(&asJSON, "json", false, "Print both results as JSON instead of a comparison table")
+		fs.IntVar(&record, "record", 0, "Record this many reviewed pull requests from the review store into --cases")
+		fs.StringVar(&repoName, "repo", "", "Only record pull requests of this repository, as owner/repo")
+		fs.StringVar(&instanceName, "instance", "default", "SCM instance to record pull requests from (see --scm-instances)")
+	})
+	if err != nil {
+		return exitCode(err)
+	}
+	if casesDir == "" {
+		fmt.Fprintln(os.Stderr, "--cases is required")
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if record > 0 {
+		return recordCases(ctx, cfg, casesDir, record, repoName, instanceName)
+	}
+	if variantB == "" {
+		fmt.Fprintln(os.Stderr, "--b is required")
+		return 2
+	}
+
+	cases, err := eval.LoadCases(casesDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if len(cases) == 0 {
+		fmt.Fprintf(os.Stderr, "no cases in %s\n", casesDir)
+		return 2
+	}
+
+	a := eval.Variant{Name: "baseline"}
+	if variantA != "" {
+		if a, err = eval.LoadVariant(variantA); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+	b, err := eval.LoadVariant(variantB)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var results [2]eval.Result
+	for i, v := range []eval.Variant{a, b} {
+		results[i], err = runVariant(ctx, cfg, v, cases)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", v.Name, err)
+			return 1
+		}
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "interrupted")
+			return 1
+		}
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	eval.Render(os.Stdout, results[0], results[1])
+	return 0
+}
+
+// runVariant reviews cases with the provider, model and instructions of v
+func runVariant(ctx context.Context, base *config.Config, v eval.Variant, cases []eval.Case) (eval.Result, error) {
+	cfg := *base
+	if v.Provider != "" {
+		cfg.LLMProvider = v.Provider
+	}
+	if v.Model != "" {
+		switch cfg.LLMProvider {
+		case "openai":
+			cfg.OpenAIModel = v.Model
+		case "ollama":
+			cfg.OllamaModel = v.Model
+		default:
+			cfg.CopilotModel = v.Model
+		}
+	}
+
+	llmSvc, err := startLLM(&cfg)
+	if err != nil {
+		return eval.Result{}, err
+	}
+	defer llmSvc.Stop()
+
+	fmt.Fprintf(os.Stderr, "Evaluating %s (%s) on %d cases\n", v.Name, cfg.LLMModel(), len(cases))
+	return eval.Run(ctx, v.Name, cases, llmSvc, review.Config{
+		LLMTimeout:    cfg.LLMTimeout,
+		ReviewTimeout: cfg.ReviewTimeout,
+		Offline:       cfg.Offline,
+		SecretScan:    cfg.SecretScan,
+		WorkflowScan:  cfg.WorkflowScan,
+		Instructions:  v.Instructions,
+		Concurrency:   cfg.ReviewConcurrency,
+	}), nil
+}
+
+// recordCases writes the n most recently reviewed pull requests in the
+// review store to dir, skipping those already recorded
+func recordCases(ctx context.Context, cfg *config.Config, dir string, n int, repoName, instanceName string) int {
+	var f store.Filter
+	if repoName != "" {
+		owner, repo, err := github.ParseRepoFullName(repoName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "--repo: %v\n", err)
+			return 2
+		}
+		f.Owner, f.Repo = owner, repo
+	}
+	inst, err := findInstance(cfg, instanceName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	githubClient, err := newInstanceClient(inst)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "create github client: %v\n", err)
+		return 2
+	}
+
+	reviews, err := store.OpenFileStore(cfg.ReviewStorePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open review store: %v\n", err)
+		return 1
+	}
+	records, err := reviews.ListReviews(ctx, f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "list reviews: %v\n", err)
+		return 1
+	}
+
+	seen := make(map[string]bool)
+	recorded, failed := 0, 0
+	for _, rec := range records {
+		if recorded+failed == n {
+			break
+		}
+		name := fmt.Sprintf("%s-%s-%d.json", rec.Owner, rec.Repo, rec.PRNumber)
+		if rec.Error != "" || (rec.Instance != "" && rec.Instance != inst.Name) || seen[name] {
+			continue
+		}
+		seen[name] = true
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+
+		c, err := eval.Record(ctx, githubClient, rec)
+		if err == nil {
+			err = eval.WriteCase(path, c)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stdout, "%s/%s#%d: failed: %v\n", rec.Owner, rec.Repo, rec.PRNumber, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "%s/%s#%d: %d files, %d labels -> %s\n", rec.Owner, rec.Repo, rec.PRNumber, len(c.Files), len(c.Labels), path)
+		recorded++
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d pull requests could not be recorded\n", failed, recorded+failed)
+		return 1
+	}
+	return 0
+}
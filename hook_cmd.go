@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"prmate/internal/github"
+	"prmate/internal/llm"
+	"prmate/internal/review"
+)
+
+const hookUsage = `Usage: prmate hook [--llm] [--fail-on <severity>] [--install] [flags]
+
+Reviews staged changes with the deterministic checks from the staged
+.prmate.md and exits 1 on blocking findings. Meant to run as a git
+pre-commit hook; --install writes one that calls this command.
+`
+
+// preCommitHook is written by `prmate hook --install`
+const preCommitHook = `#!/bin/sh
+# Installed by prmate hook --install
+exec prmate hook
+`
+
+// runHook implements `prmate hook`: a fast pre-commit review of the index
+func runHook(args []string) int {
+	var useLLM, install bool
+	var failOn, dir string
+	var timeout time.Duration
+	cfg, _, err := parseFlags("hook", hookUsage, args, func(fs *flag.FlagSet) {
+		fs.BoolVar(&useLLM, "llm", false, "Add a quick LLM pass on top of the deterministic checks")
+		fs.DurationVar(&timeout, "timeout", 10*time.Second, "Time limit for the LLM pass; the hook falls back to checks only when it runs out")
+		fs.StringVar(&failOn, "fail-on", "error", "Lowest severity that blocks the commit: error, warning, suggestion or never")
+		fs.StringVar(&dir, "dir", ".", "Git checkout to review")
+		fs.BoolVar(&install, "install", false, "Install a pre-commit hook running prmate hook in the checkout, then exit")
+	})
+	if err != nil {
+		return exitCode(err)
+	}
+	if _, ok := severityRank[failOn]; !ok && failOn != "never" {
+		fmt.Fprintf(os.Stderr, "invalid --fail-on %q: must be error, warning, suggestion or never\n", failOn)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	repo := review.NewLocalRepo(dir)
+	if install {
+		return installHook(ctx, repo)
+	}
+
+	if _, err := repo.GetFileContent(ctx, "", "", ".prmate.md", review.IndexRef); err != nil {
+		return 0 // nothing to check against; never block commits in repos without rules
+	}
+	files, headRef, err := repo.Diff(ctx, "", true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "prmate: read staged changes: %v\n", err)
+		return 1
+	}
+	if len(files) == 0 {
+		return 0
+	}
+
+	// The checks always run so a failing or slow LLM never lets a blocking
+	// finding through
+	req := review.ReviewRequest{HeadRef: headRef}
+	checksOnly := review.NewLocalService(repo, llm.Disabled{}, review.Config{Offline: true, SecretScan: cfg.SecretScan, WorkflowScan: cfg.WorkflowScan})
+	result, err := checksOnly.ReviewFiles(ctx, req, files)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "prmate: %v\n", err)
+		return 1
+	}
+	if useLLM && !cfg.Offline {
+		llmCfg := review.Config{LLMTimeout: cfg.LLMTimeout, ReviewTimeout: timeout, SecretScan: cfg.SecretScan, WorkflowScan: cfg.WorkflowScan}
+		llmResult, err := hookLLMPass(ctx, llmCfg, newLLMService(cfg), repo, req, files)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "prmate: LLM pass skipped: %v\n", err)
+		} else {
+			result.Violations = mergeViolations(result.Violations, llmResult.Violations)
+			result.ViolationsFound = len(result.Violations)
+		}
+	}
+
+	if len(result.Violations) == 0 {
+		return 0
+	}
+	printFindings(os.Stderr, result)
+	if n := countBlocking(result.Violations, failOn); n > 0 {
+		fmt.Fprintf(os.Stderr, "prmate: commit blocked by %d findings at or above %q (bypass with git commit --no-verify)\n", n, failOn)
+		return 1
+	}
+	return 0
+}
+
+// hookLLMPass reviews files with the LLM (and checks) within the review
+// timeout of cfg
+func hookLLMPass(ctx context.Context, cfg review.Config, llmSvc LLMService, repo *review.LocalRepo, req review.ReviewRequest, files []github.PRFile) (*review.ReviewResult, error) {
+	if err := llmSvc.Start(); err != nil {
+		return nil, fmt.Errorf("start LLM service: %w", err)
+	}
+	defer llmSvc.Stop()
+
+	svc := review.NewLocalService(repo, llmSvc, cfg)
+	result, err := svc.ReviewFiles(ctx, req, files)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("no answer within %s", cfg.ReviewTimeout)
+	}
+	return result, err
+}
+
+// installHook writes the pre-commit hook into the checkout's hooks
+// directory, refusing to replace a hook it did not write
+func installHook(ctx context.Context, repo *review.LocalRepo) int {
+	hooksDir, err := repo.HooksDir(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "locate git hooks: %v\n", err)
+		return 1
+	}
+	path := filepath.Join(hooksDir, "pre-commit")
+	if existing, err := os.ReadFile(path); err == nil && !strings.Contains(string(existing), "prmate hook") {
+		fmt.Fprintf(os.Stderr, "%s already exists; add `prmate hook` to it instead\n", path)
+		return 1
+	}
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "create hooks dir: %v\n", err)
+		return 1
+	}
+	if err := os.WriteFile(path, []byte(preCommitHook), 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "write hook: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stdout, "Installed %s\n", path)
+	return 0
+}
+
+// mergeViolations appends the violations in extra that base lacks
+func mergeViolations(base, extra []review.FileViolation) []review.FileViolation {
+	seen := make(map[review.FileViolation]bool, len(base))
+	for _, v := range base {
+		seen[v] = true
+	}
+	for _, v := range extra {
+		if !seen[v] {
+			seen[v] = true
+			base = append(base, v)
+		}
+	}
+	return base
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"testing"
+
+	"prmate/internal/review"
+)
+
+func TestMergeViolations(t *testing.T) {
+	check := review.FileViolation{Path: "main.go", Line: 3, Rule: "no-todo", Message: "TODO left in code", Severity: "error"}
+	llmOnly := review.FileViolation{Path: "main.go", Line: 8, Rule: "naming", Message: "Unclear name", Severity: "suggestion"}
+
+	got := mergeViolations([]review.FileViolation{check}, []review.FileViolation{check, llmOnly, llmOnly})
+	if len(got) != 2 {
+		t.Fatalf("mergeViolations() returned %d violations, want 2: %+v", len(got), got)
+	}
+	if got[0] != check || got[1] != llmOnly {
+		t.Errorf("mergeViolations() = %+v, want check finding then LLM finding", got)
+	}
+}
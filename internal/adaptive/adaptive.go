@@ -0,0 +1,165 @@
+// Package adaptive lowers review fidelity while the server is under load:
+// once queued work or LLM latency crosses a threshold, reviews switch to a
+// cheaper model and skip dependency context for low-risk files. Full
+// fidelity returns when both have fallen to half their thresholds, so the
+// state does not flap around a threshold.
+package adaptive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"prmate/internal/llm"
+)
+
+// latencyWeight is the weight of each new call in the moving average of
+// LLM latency
+const latencyWeight = 0.2
+
+// Config holds the thresholds at which reviews degrade
+type Config struct {
+	QueueDepth int           // queued webhooks and jobs across all queues; 0 disables
+	Latency    time.Duration // moving average of LLM call latency; 0 disables
+	Model      string        // cheaper model used while degraded; empty keeps the configured one
+}
+
+// Enabled reports whether any threshold is set
+func (c Config) Enabled() bool {
+	return c.QueueDepth > 0 || c.Latency > 0
+}
+
+// Queue reports how much work is waiting; webhook.AsyncProcessor and
+// jobs.Queue satisfy it
+type Queue interface {
+	QueueStats() (depth, capacity int)
+}
+
+// LLMProvider is the LLM call Controller.Wrap measures
+type LLMProvider interface {
+	GenerateTextWithContext(ctx context.Context, prompt string) (string, error)
+}
+
+// State is the current degradation state, published as a metric
+type State struct {
+	Degraded    bool      `json:"degraded"`
+	Reason      string    `json:"reason,omitempty"`
+	Model       string    `json:"model,omitempty"` // model used while degraded
+	QueueDepth  int       `json:"queue_depth"`
+	LatencyMS   int64     `json:"llm_latency_ms"`
+	Since       time.Time `json:"since,omitzero"`
+	Transitions int       `json:"transitions"` // times reviews were degraded since startup
+}
+
+// Controller decides whether reviews run degraded
+type Controller struct {
+	cfg         Config
+	mu          sync.Mutex
+	queues      []Queue
+	latency     time.Duration
+	degraded    bool
+	reason      string
+	since       time.Time
+	transitions int
+}
+
+// NewController creates a controller with the given thresholds
+func NewController(cfg Config) *Controller {
+	return &Controller{cfg: cfg}
+}
+
+// AddQueue counts the depth of q towards the queue threshold
+func (c *Controller) AddQueue(q Queue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queues = append(c.queues, q)
+}
+
+// Observe records the latency of one LLM call
+func (c *Controller) Observe(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.latency == 0 {
+		c.latency = d
+		return
+	}
+	c.latency += time.Duration(latencyWeight * float64(d-c.latency))
+}
+
+// Degraded reports whether reviews should run degraded now
+func (c *Controller) Degraded() bool {
+	return c.State().Degraded
+}
+
+// State re-evaluates and returns the degradation state
+func (c *Controller) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	depth := 0
+	for _, q := range c.queues {
+		d, _ := q.QueueStats()
+		depth += d
+	}
+
+	queueHigh := c.cfg.QueueDepth > 0 && depth >= c.cfg.QueueDepth
+	latencyHigh := c.cfg.Latency > 0 && c.latency >= c.cfg.Latency
+	queueLow := c.cfg.QueueDepth == 0 || depth*2 <= c.cfg.QueueDepth
+	latencyLow := c.cfg.Latency == 0 || c.latency*2 <= c.cfg.Latency
+	switch {
+	case !c.degraded && (queueHigh || latencyHigh):
+		c.degraded = true
+		c.since = time.Now().UTC()
+		c.transitions++
+		c.reason = fmt.Sprintf("queue depth %d", depth)
+		if !queueHigh {
+			c.reason = fmt.Sprintf("LLM latency %s", c.latency.Round(time.Millisecond))
+		}
+		slog.Warn("Load is high, degrading reviews", "reason", c.reason, "model", c.cfg.Model)
+	case c.degraded && queueLow && latencyLow:
+		slog.Info("Load recovered, restoring full reviews", "degraded_for", time.Since(c.since).Round(time.Second))
+		c.degraded = false
+		c.reason = ""
+		c.since = time.Time{}
+	}
+
+	s := State{
+		Degraded:    c.degraded,
+		Reason:      c.reason,
+		QueueDepth:  depth,
+		LatencyMS:   c.latency.Milliseconds(),
+		Since:       c.since,
+		Transitions: c.transitions,
+	}
+	if c.degraded {
+		s.Model = c.cfg.Model
+	}
+	return s
+}
+
+// Wrap returns next with every call timed, and answered by the cheaper
+// model while reviews are degraded
+func (c *Controller) Wrap(next LLMProvider) LLMProvider {
+	return &provider{next: next, c: c}
+}
+
+type provider struct {
+	next LLMProvider
+	c    *Controller
+}
+
+func (p *provider) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	if p.c.cfg.Model != "" && p.c.Degraded() {
+		ctx = llm.WithModel(ctx, p.c.cfg.Model)
+	}
+	start := time.Now()
+	out, err := p.next.GenerateTextWithContext(ctx, prompt)
+	// A call cut short by its timeout still says the LLM is slow
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		p.c.Observe(time.Since(start))
+	}
+	return out, err
+}
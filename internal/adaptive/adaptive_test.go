@@ -0,0 +1,77 @@
+package adaptive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"prmate/internal/llm"
+)
+
+type fakeQueue struct{ depth int }
+
+func (q *fakeQueue) QueueStats() (depth, capacity int) { return q.depth, 100 }
+
+func TestController_QueueDepth(t *testing.T) {
+	webhooks, jobs := &fakeQueue{}, &fakeQueue{}
+	c := NewController(Config{QueueDepth: 10})
+	c.AddQueue(webhooks)
+	c.AddQueue(jobs)
+
+	steps := []struct {
+		webhooks, jobs int
+		want           bool
+	}{
+		{webhooks: 3, jobs: 2, want: false},
+		{webhooks: 6, jobs: 4, want: true},  // threshold reached across queues
+		{webhooks: 4, jobs: 2, want: true},  // still above half
+		{webhooks: 3, jobs: 2, want: false}, // recovered
+	}
+	for i, s := range steps {
+		webhooks.depth, jobs.depth = s.webhooks, s.jobs
+		if got := c.Degraded(); got != s.want {
+			t.Errorf("step %d: Degraded() = %v, want %v", i, got, s.want)
+		}
+	}
+	if st := c.State(); st.Transitions != 1 || st.QueueDepth != 5 {
+		t.Errorf("State() = %+v, want 1 transition at depth 5", st)
+	}
+}
+
+type fakeLLM struct {
+	delay  time.Duration
+	models []string
+}
+
+func (f *fakeLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	f.models = append(f.models, llm.ModelFor(ctx, "gpt-4"))
+	time.Sleep(f.delay)
+	return "ok", nil
+}
+
+func TestController_LatencySwitchesModel(t *testing.T) {
+	c := NewController(Config{Latency: 20 * time.Millisecond, Model: "gpt-4o-mini"})
+	next := &fakeLLM{delay: 30 * time.Millisecond}
+	p := c.Wrap(next)
+
+	for range 2 {
+		if _, err := p.GenerateTextWithContext(context.Background(), "hi"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := next.models; len(got) != 2 || got[0] != "gpt-4" || got[1] != "gpt-4o-mini" {
+		t.Fatalf("models = %v, want the cheaper model once latency is high", got)
+	}
+	st := c.State()
+	if !st.Degraded || st.Model != "gpt-4o-mini" || st.Reason == "" {
+		t.Errorf("State() = %+v, want degraded on latency", st)
+	}
+
+	// Fast answers bring the average below half the threshold
+	for range 20 {
+		c.Observe(time.Millisecond)
+	}
+	if c.Degraded() {
+		t.Errorf("still degraded at %dms average latency", c.State().LatencyMS)
+	}
+}
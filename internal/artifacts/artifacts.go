@@ -0,0 +1,116 @@
+// Package artifacts stores files PRMate produces — generated .prmate.md
+// content, review results and reports — on the local filesystem or in an
+// object store (S3, GCS or Azure Blob Storage).
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Store persists artifacts under slash-separated keys such as
+// "scans/owner/repo/20260101T000000Z.prmate.md"
+type Store interface {
+	// Put writes data under key and returns where it was stored
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+}
+
+// Credentials authenticate against S3-compatible stores (S3, and GCS through
+// its HMAC interoperability keys)
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// Open returns the store described by rawURL:
+//
+//	/var/lib/prmate/artifacts or file:///var/lib/prmate/artifacts
+//	s3://bucket/prefix?region=eu-west-1[&endpoint=https://minio:9000]
+//	gs://bucket/prefix
+//	azure://account.blob.core.windows.net/container/prefix?<sas token>
+func Open(rawURL string, creds Credentials) (Store, error) {
+	if rawURL == "" {
+		return nil, errors.New("artifact store url is empty")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse artifact store url: %w", err)
+	}
+
+	switch u.Scheme {
+	case "", "file":
+		dir := rawURL
+		if u.Scheme == "file" {
+			dir = u.Path
+		}
+		return NewFileStore(dir), nil
+	case "s3":
+		region := u.Query().Get("region")
+		if region == "" {
+			region = "us-east-1"
+		}
+		endpoint := u.Query().Get("endpoint")
+		if endpoint == "" {
+			endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+		}
+		return newS3Store("s3", endpoint, region, u.Host, u.Path, creds)
+	case "gs":
+		return newS3Store("gs", "https://storage.googleapis.com", "auto", u.Host, u.Path, creds)
+	case "azure":
+		return newAzureStore("https://"+u.Host+u.Path, u.RawQuery)
+	default:
+		return nil, fmt.Errorf("unsupported artifact store scheme %q", u.Scheme)
+	}
+}
+
+// FileStore writes artifacts below a local directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store rooted at dir
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Put writes data to <dir>/<key>
+func (s *FileStore) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	target := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("create artifact dir: %w", err)
+	}
+	if err := os.WriteFile(target, data, 0o600); err != nil {
+		return "", fmt.Errorf("write artifact: %w", err)
+	}
+	return target, nil
+}
+
+// cleanKey normalises key and rejects keys escaping the store's root
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + strings.TrimSpace(key))[1:]
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", fmt.Errorf("invalid artifact key %q", key)
+	}
+	return cleaned, nil
+}
+
+// joinKey prefixes key with a store's configured prefix
+func joinKey(prefix, key string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return key
+	}
+	return prefix + "/" + key
+}
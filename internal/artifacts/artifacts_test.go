@@ -0,0 +1,148 @@
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpen(t *testing.T) {
+	creds := Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}
+
+	tests := []struct {
+		name    string
+		url     string
+		creds   Credentials
+		want    string // %T of the store
+		wantErr bool
+	}{
+		{name: "plain path", url: "/tmp/artifacts", want: "*artifacts.FileStore"},
+		{name: "file url", url: "file:///tmp/artifacts", want: "*artifacts.FileStore"},
+		{name: "s3", url: "s3://bucket/prefix?region=eu-west-1", creds: creds, want: "*artifacts.s3Store"},
+		{name: "s3 without keys", url: "s3://bucket", wantErr: true},
+		{name: "s3 without bucket", url: "s3:///prefix", creds: creds, wantErr: true},
+		{name: "gcs", url: "gs://bucket", creds: creds, want: "*artifacts.s3Store"},
+		{name: "azure", url: "azure://acct.blob.core.windows.net/container?sv=1&sig=x", want: "*artifacts.azureStore"},
+		{name: "azure without sas", url: "azure://acct.blob.core.windows.net/container", wantErr: true},
+		{name: "azure without container", url: "azure://acct.blob.core.windows.net?sig=x", wantErr: true},
+		{name: "unknown scheme", url: "ftp://host/dir", wantErr: true},
+		{name: "empty", url: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Open(tt.url, tt.creds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Open(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+			if err == nil && fmt.Sprintf("%T", got) != tt.want {
+				t.Errorf("Open(%q) = %s, want %s", tt.url, fmt.Sprintf("%T", got), tt.want)
+			}
+		})
+	}
+}
+
+func TestFileStore_Put(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFileStore(dir)
+
+	loc, err := s.Put(context.Background(), "scans/owner/repo/a.md", []byte("hello"), "text/markdown")
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if loc != filepath.Join(dir, "scans", "owner", "repo", "a.md") {
+		t.Errorf("location = %q", loc)
+	}
+	if got, _ := os.ReadFile(loc); string(got) != "hello" {
+		t.Errorf("content = %q, want hello", got)
+	}
+
+	for _, key := range []string{"../escape", "a/../../b", "", "a/./b"} {
+		if _, err := s.Put(context.Background(), key, nil, ""); err == nil {
+			t.Errorf("Put(%q) expected error", key)
+		}
+	}
+}
+
+func TestS3Store_Put(t *testing.T) {
+	var gotPath, gotAuth, gotHash, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotHash = r.Header.Get("X-Amz-Content-Sha256")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+	}))
+	defer srv.Close()
+
+	s, err := newS3Store("s3", srv.URL, "eu-west-1", "bucket", "/team", Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	loc, err := s.Put(context.Background(), "reviews/o/r/1.json", []byte("{}"), "application/json")
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if loc != "s3://bucket/team/reviews/o/r/1.json" {
+		t.Errorf("location = %q", loc)
+	}
+	if gotPath != "/bucket/team/reviews/o/r/1.json" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotBody != "{}" || gotHash != sha256Hex([]byte("{}")) {
+		t.Errorf("body = %q hash = %q", gotBody, gotHash)
+	}
+	wantPrefix := "AWS4-HMAC-SHA256 Credential=AKID/20260102/eu-west-1/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature="
+	if !strings.HasPrefix(gotAuth, wantPrefix) || len(gotAuth) != len(wantPrefix)+64 {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+}
+
+func TestAzureStore_Put(t *testing.T) {
+	var gotPath, gotQuery, gotType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+		gotType = r.Header.Get("X-Ms-Blob-Type")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	s, err := newAzureStore(srv.URL+"/container/prefix", "sv=1&sig=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := s.Put(context.Background(), "scans/a.md", []byte("x"), "text/markdown")
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if gotPath != "/container/prefix/scans/a.md" || gotQuery != "sv=1&sig=abc" || gotType != "BlockBlob" {
+		t.Errorf("request path=%q query=%q type=%q", gotPath, gotQuery, gotType)
+	}
+	if strings.Contains(loc, "sig=") {
+		t.Errorf("location %q leaks the sas token", loc)
+	}
+}
+
+func TestStore_PutErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	s, _ := newAzureStore(srv.URL+"/container", "sig=abc")
+	if _, err := s.Put(context.Background(), "a", nil, ""); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Put() error = %v, want 403", err)
+	}
+}
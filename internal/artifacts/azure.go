@@ -0,0 +1,53 @@
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// azureStore uploads block blobs to a container authorised by a SAS token
+type azureStore struct {
+	base   *url.URL // https://account.blob.core.windows.net/container[/prefix]
+	sas    string
+	client *http.Client
+}
+
+func newAzureStore(base, sas string) (*azureStore, error) {
+	u, err := url.Parse(base)
+	if err != nil || u.Host == "" || strings.Trim(u.Path, "/") == "" {
+		return nil, fmt.Errorf("azure artifact store: expected account host and container in %q", base)
+	}
+	if sas == "" {
+		return nil, fmt.Errorf("azure artifact store: missing sas token")
+	}
+	return &azureStore{base: u, sas: sas, client: &http.Client{Timeout: time.Minute}}, nil
+}
+
+func (s *azureStore) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	target := *s.base
+	target.Path = strings.TrimSuffix(target.Path, "/") + "/" + key
+	location := target.String()
+	target.RawQuery = s.sas
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Ms-Blob-Type", "BlockBlob")
+
+	if err := do(s.client, req); err != nil {
+		return "", err
+	}
+	return location, nil
+}
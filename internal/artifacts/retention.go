@@ -0,0 +1,104 @@
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Prune deletes artifacts last written before cutoff and the directories
+// left empty, returning how many artifacts it deleted
+func (s *FileStore) Prune(ctx context.Context, cutoff time.Time) (int, error) {
+	var removed int
+	var dirs []string
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != s.dir {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove artifact: %w", err)
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("prune artifacts: %w", err)
+	}
+
+	// Deepest first, so parents empty out after their children; a directory
+	// still holding artifacts fails to remove and is kept
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i])
+	}
+	return removed, nil
+}
+
+// StartJanitor prunes artifacts older than maxAge every interval until ctx
+// is done. A non-positive maxAge or interval disables it.
+func (s *FileStore) StartJanitor(ctx context.Context, maxAge, interval time.Duration) {
+	if maxAge <= 0 || interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			removed, err := s.Prune(ctx, time.Now().Add(-maxAge))
+			if err != nil && ctx.Err() == nil {
+				slog.Warn("artifact cleanup failed", "dir", s.dir, "removed", removed, "error", err)
+			} else if removed > 0 {
+				slog.Info("removed old artifacts", "dir", s.dir, "removed", removed, "max_age", maxAge)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// prefixStore puts every artifact under a fixed key prefix
+type prefixStore struct {
+	store  Store
+	prefix string
+}
+
+// WithPrefix returns a store that keeps artifacts of store under prefix,
+// such as the name of the SCM instance producing them
+func WithPrefix(store Store, prefix string) Store {
+	if store == nil || prefix == "" {
+		return store
+	}
+	return &prefixStore{store: store, prefix: prefix}
+}
+
+func (s *prefixStore) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	return s.store.Put(ctx, joinKey(s.prefix, key), data, contentType)
+}
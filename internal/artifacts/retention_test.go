@@ -0,0 +1,60 @@
+package artifacts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStore_Prune(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFileStore(dir)
+	ctx := context.Background()
+
+	old, _ := s.Put(ctx, "scans/owner/old/a.md", []byte("old"), "text/markdown")
+	mixed, _ := s.Put(ctx, "scans/owner/repo/b.md", []byte("old"), "text/markdown")
+	fresh, _ := s.Put(ctx, "scans/owner/repo/c.md", []byte("new"), "text/markdown")
+	aged := time.Now().Add(-48 * time.Hour)
+	for _, p := range []string{old, mixed} {
+		if err := os.Chtimes(p, aged, aged); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := s.Prune(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil || removed != 2 {
+		t.Fatalf("Prune() = %d, %v; want 2 artifacts removed", removed, err)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("fresh artifact removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "scans", "owner", "old")); !os.IsNotExist(err) {
+		t.Errorf("emptied dir kept: %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("store root removed: %v", err)
+	}
+
+	if removed, err := NewFileStore(filepath.Join(dir, "missing")).Prune(ctx, time.Now()); err != nil || removed != 0 {
+		t.Errorf("Prune() of a missing dir = %d, %v; want 0, nil", removed, err)
+	}
+}
+
+func TestWithPrefix(t *testing.T) {
+	dir := t.TempDir()
+	s := WithPrefix(NewFileStore(dir), "ghe.example.com@team")
+
+	loc, err := s.Put(context.Background(), "reviews/o/r/pr-1/abc.json", []byte("{}"), "application/json")
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if loc != filepath.Join(dir, "ghe.example.com@team", "reviews", "o", "r", "pr-1", "abc.json") {
+		t.Errorf("location = %q, want under the instance", loc)
+	}
+
+	if WithPrefix(nil, "team") != nil {
+		t.Error("WithPrefix(nil) should stay nil")
+	}
+}
@@ -0,0 +1,315 @@
+// Package audit records every write PRMate performs against GitHub — who
+// triggered it, what was written, when and why — in a queryable log.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"prmate/internal/correlation"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionCommentCreate = "comment.create"
+	ActionCommentUpdate = "comment.update"
+	ActionReviewCreate  = "review.create"
+	ActionIssueCreate   = "issue.create"
+	ActionGitPush       = "git.push"
+	ActionPRCreate      = "pr.create"
+)
+
+// Event is one write action against GitHub
+type Event struct {
+	Time          time.Time         `json:"time"`
+	Host          string            `json:"host"`
+	Action        string            `json:"action"`
+	Owner         string            `json:"owner"`
+	Repo          string            `json:"repo"`
+	PRNumber      int               `json:"pr,omitempty"`
+	Actor         string            `json:"actor,omitempty"`  // who triggered the write
+	Reason        string            `json:"reason,omitempty"` // why it was made
+	CorrelationID string            `json:"correlation_id,omitempty"`
+	DryRun        bool              `json:"dry_run"`
+	Details       map[string]string `json:"details,omitempty"`
+	Error         string            `json:"error,omitempty"`
+}
+
+// Recorder persists audit events
+type Recorder interface {
+	Record(ctx context.Context, e Event) error
+}
+
+type actorKey struct{}
+type reasonKey struct{}
+
+// WithActor returns a context attributing writes made under it to actor,
+// e.g. "github:octocat" for a webhook sender or "api:admin-key"
+func WithActor(ctx context.Context, actor string) context.Context {
+	if actor == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor set on ctx, or ""
+func ActorFrom(ctx context.Context) string {
+	s, _ := ctx.Value(actorKey{}).(string)
+	return s
+}
+
+// WithReason returns a context explaining why writes made under it happen,
+// e.g. "pull_request.opened" or "manual review"
+func WithReason(ctx context.Context, reason string) context.Context {
+	if reason == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, reasonKey{}, reason)
+}
+
+// ReasonFrom returns the reason set on ctx, or ""
+func ReasonFrom(ctx context.Context) string {
+	s, _ := ctx.Value(reasonKey{}).(string)
+	return s
+}
+
+// Complete fills in the time, actor, reason and correlation ID of e from ctx
+// and records err, if any
+func Complete(ctx context.Context, e Event, err error) Event {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.Actor == "" {
+		e.Actor = ActorFrom(ctx)
+	}
+	if e.Reason == "" {
+		e.Reason = ReasonFrom(ctx)
+	}
+	if e.CorrelationID == "" {
+		e.CorrelationID = correlation.FromContext(ctx)
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// Filter narrows List results; zero values match everything
+type Filter struct {
+	Owner  string
+	Repo   string
+	Action string
+	Actor  string
+	Since  time.Time
+	Limit  int
+}
+
+func (f Filter) matches(e Event) bool {
+	switch {
+	case f.Owner != "" && f.Owner != e.Owner,
+		f.Repo != "" && f.Repo != e.Repo,
+		f.Action != "" && f.Action != e.Action,
+		f.Actor != "" && f.Actor != e.Actor,
+		!f.Since.IsZero() && e.Time.Before(f.Since):
+		return false
+	}
+	return true
+}
+
+// memoryEvents bounds the events a Log keeps in memory; List reads older
+// ones from the file
+const memoryEvents = 10000
+
+// Log is an append-only JSON Lines audit log, its latest events mirrored in
+// memory for queries. An empty path keeps events in memory only.
+type Log struct {
+	mu     sync.RWMutex
+	path   string
+	events []Event // the latest events, at most about memoryEvents
+	older  int     // events in the file before events
+}
+
+// OpenLog loads existing events from path, creating its directory if needed.
+// Lines that cannot be parsed are skipped with a warning; a partly written
+// last line, as left by a crash, is cut off so later events start on a line
+// of their own.
+func OpenLog(path string) (*Log, error) {
+	l := &Log{path: path}
+	if path == "" {
+		return l, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create audit log dir: %w", err)
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var complete int64 // bytes up to the end of the last complete line
+	for line := 1; ; line++ {
+		b, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("read audit log: %w", err)
+		}
+		if len(b) == 0 {
+			break
+		}
+		var e Event
+		jsonErr := json.Unmarshal(b, &e)
+		switch {
+		case err == io.EOF && jsonErr != nil:
+			slog.Warn("dropping partly written last audit log line", "path", path, "line", line, "error", jsonErr)
+			if err := os.Truncate(path, complete); err != nil {
+				return nil, fmt.Errorf("truncate audit log: %w", err)
+			}
+		case err == io.EOF:
+			// Complete the line so the next event does not run into it
+			l.add(e)
+			if err := appendToFile(path, []byte{'\n'}); err != nil {
+				return nil, err
+			}
+		case jsonErr != nil:
+			slog.Warn("skipping unreadable audit log line", "path", path, "line", line, "error", jsonErr)
+		default:
+			l.add(e)
+		}
+		complete += int64(len(b))
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return l, nil
+}
+
+// add appends e to the events in memory, leaving the oldest to the file
+// once there are too many
+func (l *Log) add(e Event) {
+	l.events = append(l.events, e)
+	if l.path != "" && len(l.events) > memoryEvents+memoryEvents/10 {
+		drop := len(l.events) - memoryEvents
+		l.events = append([]Event(nil), l.events[drop:]...)
+		l.older += drop
+	}
+}
+
+// appendToFile appends data to the file at path
+func appendToFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("write audit event: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close audit log: %w", err)
+	}
+	return nil
+}
+
+// Record appends e to the log
+func (l *Log) Record(ctx context.Context, e Event) error {
+	_ = ctx
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.path != "" {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("encode audit event: %w", err)
+		}
+		if err := appendToFile(l.path, append(line, '\n')); err != nil {
+			return err
+		}
+	}
+
+	l.add(e)
+	return nil
+}
+
+// List returns matching events, newest first. Events no longer in memory
+// are read from the file when the latest ones are not enough.
+func (l *Log) List(ctx context.Context, f Filter) ([]Event, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	out := make([]Event, 0)
+	for i := len(l.events) - 1; i >= 0; i-- {
+		if !f.matches(l.events[i]) {
+			continue
+		}
+		out = append(out, l.events[i])
+		if f.Limit > 0 && len(out) == f.Limit {
+			return out, nil
+		}
+	}
+	if l.older == 0 {
+		return out, nil
+	}
+
+	older, err := l.readOlder(ctx, f, f.Limit-len(out))
+	if err != nil {
+		return nil, err
+	}
+	for i := len(older) - 1; i >= 0; i-- {
+		out = append(out, older[i])
+	}
+	return out, nil
+}
+
+// readOlder reads the events matching f that are in the file but no longer
+// in memory, oldest first, keeping the latest want of them when want > 0
+func (l *Log) readOlder(ctx context.Context, f Filter, want int) ([]Event, error) {
+	file, err := os.Open(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var out []Event
+	r := bufio.NewReader(file)
+	for read := 0; read < l.older; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		b, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("read audit log: %w", err)
+		}
+		var e Event
+		if json.Unmarshal(b, &e) == nil { // unreadable lines were skipped when the log was opened too
+			read++
+			if f.matches(e) {
+				out = append(out, e)
+			}
+			if want > 0 && len(out) > 2*want {
+				out = append(out[:0], out[len(out)-want:]...)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if want > 0 && len(out) > want {
+		out = out[len(out)-want:]
+	}
+	return out, nil
+}
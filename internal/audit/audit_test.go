@@ -0,0 +1,153 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"prmate/internal/correlation"
+)
+
+func TestComplete(t *testing.T) {
+	ctx := correlation.WithID(context.Background(), "corr-1")
+	ctx = WithActor(ctx, "github:octocat")
+	ctx = WithReason(ctx, "pull_request.opened")
+
+	e := Complete(ctx, Event{Action: ActionCommentCreate}, errors.New("boom"))
+
+	if e.Actor != "github:octocat" || e.Reason != "pull_request.opened" || e.CorrelationID != "corr-1" {
+		t.Errorf("Complete() = %+v, want actor, reason and correlation ID from ctx", e)
+	}
+	if e.Error != "boom" {
+		t.Errorf("Error = %q, want boom", e.Error)
+	}
+	if e.Time.IsZero() {
+		t.Error("Time should be set")
+	}
+}
+
+func TestLog_RecordAndList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	ctx := context.Background()
+
+	l, err := OpenLog(path)
+	if err != nil {
+		t.Fatalf("OpenLog() error = %v", err)
+	}
+	events := []Event{
+		{Action: ActionCommentCreate, Owner: "o", Repo: "a", Actor: "github:alice"},
+		{Action: ActionReviewCreate, Owner: "o", Repo: "a", Actor: "github:bob"},
+		{Action: ActionGitPush, Owner: "o", Repo: "b", Actor: "github:alice"},
+	}
+	for _, e := range events {
+		if err := l.Record(ctx, Complete(ctx, e, nil)); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	reopened, err := OpenLog(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		filter      Filter
+		wantActions []string
+	}{
+		{name: "all newest first", filter: Filter{}, wantActions: []string{ActionGitPush, ActionReviewCreate, ActionCommentCreate}},
+		{name: "by repo", filter: Filter{Repo: "a"}, wantActions: []string{ActionReviewCreate, ActionCommentCreate}},
+		{name: "by actor", filter: Filter{Actor: "github:alice"}, wantActions: []string{ActionGitPush, ActionCommentCreate}},
+		{name: "by action", filter: Filter{Action: ActionReviewCreate}, wantActions: []string{ActionReviewCreate}},
+		{name: "limit", filter: Filter{Limit: 1}, wantActions: []string{ActionGitPush}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reopened.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(got) != len(tt.wantActions) {
+				t.Fatalf("len = %d, want %d", len(got), len(tt.wantActions))
+			}
+			for i, a := range tt.wantActions {
+				if got[i].Action != a {
+					t.Errorf("got[%d].Action = %q, want %q", i, got[i].Action, a)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenLog_SkipsUnreadableLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	content := `{"action":"comment.create","repo":"a"}` + "\n" +
+		"not json\n" +
+		`{"action":"git.push","repo":"b"}` + "\n" +
+		`{"action":"review.cre`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	l, err := OpenLog(path)
+	if err != nil {
+		t.Fatalf("OpenLog() error = %v, want unreadable lines skipped", err)
+	}
+	if err := l.Record(ctx, Event{Action: ActionIssueCreate, Repo: "c"}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	// The partly written line is gone, so the new event reads back too
+	reopened, err := OpenLog(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	got, _ := reopened.List(ctx, Filter{})
+	var actions []string
+	for _, e := range got {
+		actions = append(actions, e.Action)
+	}
+	if strings.Join(actions, ",") != "issue.create,git.push,comment.create" {
+		t.Errorf("actions = %v, want the readable events", actions)
+	}
+}
+
+func TestLog_ListReadsOlderEventsFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	total := memoryEvents + memoryEvents/10 + 5
+	var sb strings.Builder
+	for i := range total {
+		repo := "new"
+		if i < 3 {
+			repo = "old"
+		}
+		fmt.Fprintf(&sb, `{"action":"comment.create","repo":%q,"pr":%d}`+"\n", repo, i+1)
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	l, err := OpenLog(path)
+	if err != nil {
+		t.Fatalf("OpenLog() error = %v", err)
+	}
+	if len(l.events) > memoryEvents+memoryEvents/10 || l.older == 0 {
+		t.Errorf("%d events in memory, want the oldest left in the file", len(l.events))
+	}
+
+	all, err := l.List(ctx, Filter{})
+	if err != nil || len(all) != total || all[0].PRNumber != total || all[total-1].PRNumber != 1 {
+		t.Fatalf("List() = %d events, %v; want all %d newest first", len(all), err, total)
+	}
+	old, err := l.List(ctx, Filter{Repo: "old", Limit: 2})
+	if err != nil || len(old) != 2 || old[0].PRNumber != 3 || old[1].PRNumber != 2 {
+		t.Errorf("List(old, limit 2) = %+v, %v; want PRs 3 and 2 from the file", old, err)
+	}
+}
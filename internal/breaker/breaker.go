@@ -0,0 +1,168 @@
+// Package breaker stops calling a failing dependency for a while. After a
+// run of consecutive failures a breaker opens and fails calls at once for
+// a cool-down period; then a single probe call is let through, which closes
+// the breaker again when it succeeds.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrOpen is matched by the errors of calls an open breaker refused
+var ErrOpen = errors.New("circuit breaker open")
+
+// OpenError is returned for a call refused by an open breaker
+type OpenError struct {
+	Name    string
+	RetryAt time.Time // when the breaker lets a probe call through
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s unavailable: circuit breaker open until %s", e.Name, e.RetryAt.Format(time.RFC3339))
+}
+
+// Is makes errors.Is(err, ErrOpen) true for an OpenError
+func (e *OpenError) Is(target error) bool {
+	return target == ErrOpen
+}
+
+// RetryAfter reports whether err was caused by an open breaker, and how long
+// to wait before retrying the work that failed
+func RetryAfter(err error) (time.Duration, bool) {
+	var open *OpenError
+	if !errors.As(err, &open) {
+		return 0, false
+	}
+	return max(time.Until(open.RetryAt), 0), true
+}
+
+// Breaker states
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// Config sets when a breaker opens and for how long
+type Config struct {
+	Failures int           // consecutive failures that open the breaker; 0 disables it
+	CoolDown time.Duration // how long the breaker stays open
+}
+
+// State is a snapshot of a breaker, published as a metric
+type State struct {
+	State    string    `json:"state"`
+	Failures int       `json:"consecutive_failures"`
+	Opens    int       `json:"opens"` // times the breaker opened since startup
+	RetryAt  time.Time `json:"retry_at,omitzero"`
+}
+
+// Breaker guards one dependency. A nil Breaker allows every call.
+type Breaker struct {
+	name string
+	cfg  Config
+	now  func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	opens    int
+	openedAt time.Time
+	open     bool
+	probing  bool
+}
+
+// New creates a closed breaker for the dependency called name
+func New(name string, cfg Config) *Breaker {
+	return &Breaker{name: name, cfg: cfg, now: time.Now}
+}
+
+// Name returns the dependency the breaker guards
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Allow returns an *OpenError when the call must not be made. Every allowed
+// call must be followed by Done.
+func (b *Breaker) Allow() error {
+	if b == nil || b.cfg.Failures <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return nil
+	}
+	retryAt := b.openedAt.Add(b.cfg.CoolDown)
+	if b.probing || b.now().Before(retryAt) {
+		return &OpenError{Name: b.name, RetryAt: retryAt}
+	}
+	b.probing = true
+	return nil
+}
+
+// Done records the outcome of a call Allow let through
+func (b *Breaker) Done(failed bool) {
+	if b == nil || b.cfg.Failures <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	probe := b.probing
+	b.probing = false
+	if !failed {
+		if b.open {
+			slog.Info("Circuit breaker closed", "breaker", b.name)
+		}
+		b.open = false
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if probe || (!b.open && b.failures >= b.cfg.Failures) {
+		if !b.open {
+			b.opens++
+		}
+		b.open = true
+		b.openedAt = b.now()
+		slog.Warn("Circuit breaker opened", "breaker", b.name, "failures", b.failures, "cool_down", b.cfg.CoolDown)
+	}
+}
+
+// Check reports an *OpenError while the breaker is open, for readiness
+// checks; it never uses up the probe call
+func (b *Breaker) Check(ctx context.Context) error {
+	_ = ctx
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.open {
+		return &OpenError{Name: b.name, RetryAt: b.openedAt.Add(b.cfg.CoolDown)}
+	}
+	return nil
+}
+
+// State returns a snapshot of the breaker
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := State{State: StateClosed, Failures: b.failures, Opens: b.opens}
+	if b.open {
+		s.State = StateOpen
+		s.RetryAt = b.openedAt.Add(b.cfg.CoolDown)
+		if b.probing || !b.now().Before(s.RetryAt) {
+			s.State = StateHalfOpen
+		}
+	}
+	return s
+}
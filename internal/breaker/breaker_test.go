@@ -0,0 +1,91 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBreaker_OpensAndRecovers(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	b := New("llm", Config{Failures: 3, CoolDown: 30 * time.Second})
+	b.now = func() time.Time { return now }
+
+	call := func(failed bool) error {
+		if err := b.Allow(); err != nil {
+			return err
+		}
+		b.Done(failed)
+		return nil
+	}
+
+	// A success resets the count of consecutive failures
+	for _, failed := range []bool{true, true, false, true, true} {
+		if err := call(failed); err != nil {
+			t.Fatalf("call refused while closed: %v", err)
+		}
+	}
+	if err := call(true); err != nil {
+		t.Fatalf("third consecutive failure refused: %v", err)
+	}
+
+	err := b.Allow()
+	if !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow() = %v, want ErrOpen after 3 failures", err)
+	}
+	var open *OpenError
+	if !errors.As(err, &open) || !open.RetryAt.Equal(now.Add(30*time.Second)) {
+		t.Errorf("Allow() = %v, want retry at the end of the cool-down", err)
+	}
+	if _, ok := RetryAfter(fmt.Errorf("review: %w", err)); !ok {
+		t.Error("RetryAfter() did not match a wrapped OpenError")
+	}
+	if b.Check(context.Background()) == nil {
+		t.Error("Check() = nil while open")
+	}
+	if s := b.State(); s.State != StateOpen || s.Opens != 1 {
+		t.Errorf("State() = %+v, want open once", s)
+	}
+
+	// After the cool-down one probe goes through; a failed probe reopens
+	now = now.Add(31 * time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe refused after cool-down: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("second call during probe = %v, want ErrOpen", err)
+	}
+	b.Done(true)
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow() after failed probe = %v, want ErrOpen", err)
+	}
+
+	// A successful probe closes the breaker
+	now = now.Add(31 * time.Second)
+	if err := call(false); err != nil {
+		t.Fatalf("probe refused: %v", err)
+	}
+	if s := b.State(); s.State != StateClosed || s.Failures != 0 || s.Opens != 1 {
+		t.Errorf("State() = %+v, want closed", s)
+	}
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	var nilBreaker *Breaker
+	for name, b := range map[string]*Breaker{"nil": nilBreaker, "zero failures": New("github", Config{})} {
+		for range 10 {
+			if err := b.Allow(); err != nil {
+				t.Fatalf("%s: Allow() = %v", name, err)
+			}
+			b.Done(true)
+		}
+		if err := b.Check(context.Background()); err != nil {
+			t.Errorf("%s: Check() = %v", name, err)
+		}
+	}
+	if _, ok := RetryAfter(errors.New("boom")); ok {
+		t.Error("RetryAfter() matched an unrelated error")
+	}
+}
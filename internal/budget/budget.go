@@ -0,0 +1,110 @@
+// Package budget caps the LLM tokens reviews may spend per repository and
+// per organization, using the estimates the review store records for every
+// review. A repository over budget is reviewed with the deterministic
+// checks only until its budget period ends.
+package budget
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"prmate/internal/store"
+)
+
+// Limits are token budgets per UTC day and calendar month; 0 disables one
+type Limits struct {
+	RepoDaily   int
+	RepoMonthly int
+	OrgDaily    int
+	OrgMonthly  int
+}
+
+// Enabled reports whether any budget is set
+func (l Limits) Enabled() bool {
+	return l.RepoDaily > 0 || l.RepoMonthly > 0 || l.OrgDaily > 0 || l.OrgMonthly > 0
+}
+
+// Reviews lists recorded reviews; store.FileStore satisfies it
+type Reviews interface {
+	ListReviews(ctx context.Context, f store.Filter) ([]store.ReviewRecord, error)
+}
+
+// Exceeded describes a budget a repository has used up
+type Exceeded struct {
+	Scope  string // "owner/repo" for repository budgets, "owner" for organization ones
+	Period string // "daily" or "monthly"
+	Used   int
+	Limit  int
+	Start  time.Time // start of the budget period
+	Resets time.Time // end of the budget period
+}
+
+// Key identifies the budget and period, e.g. "acme/api:daily:2026-10-15"
+func (e *Exceeded) Key() string {
+	layout := "2006-01-02"
+	if e.Period == "monthly" {
+		layout = "2006-01"
+	}
+	return e.Scope + ":" + e.Period + ":" + e.Start.Format(layout)
+}
+
+// Tracker checks the token spend of one SCM instance's repositories
+// against its limits
+type Tracker struct {
+	reviews  Reviews
+	instance string
+	limits   Limits
+	now      func() time.Time
+}
+
+// NewTracker creates a tracker for the reviews recorded under instance
+func NewTracker(reviews Reviews, instance string, limits Limits) *Tracker {
+	return &Tracker{reviews: reviews, instance: instance, limits: limits, now: time.Now}
+}
+
+// Check returns the first budget owner/repo has used up, monthly budgets
+// before daily ones and repository budgets before organization ones, or
+// nil when every budget has tokens left
+func (t *Tracker) Check(ctx context.Context, owner, repo string) (*Exceeded, error) {
+	if !t.limits.Enabled() {
+		return nil, nil
+	}
+
+	now := t.now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	records, err := t.reviews.ListReviews(ctx, store.Filter{Instance: t.instance, Owner: owner, Since: month})
+	if err != nil {
+		return nil, fmt.Errorf("list reviews: %w", err)
+	}
+
+	var repoDay, repoMonth, orgDay, orgMonth int
+	for _, r := range records {
+		today := !r.FinishedAt.Before(day)
+		orgMonth += r.EstimatedTokens
+		if today {
+			orgDay += r.EstimatedTokens
+		}
+		if r.Repo == repo {
+			repoMonth += r.EstimatedTokens
+			if today {
+				repoDay += r.EstimatedTokens
+			}
+		}
+	}
+
+	fullName := owner + "/" + repo
+	for _, b := range []Exceeded{
+		{Scope: fullName, Period: "monthly", Used: repoMonth, Limit: t.limits.RepoMonthly, Start: month, Resets: month.AddDate(0, 1, 0)},
+		{Scope: owner, Period: "monthly", Used: orgMonth, Limit: t.limits.OrgMonthly, Start: month, Resets: month.AddDate(0, 1, 0)},
+		{Scope: fullName, Period: "daily", Used: repoDay, Limit: t.limits.RepoDaily, Start: day, Resets: day.AddDate(0, 0, 1)},
+		{Scope: owner, Period: "daily", Used: orgDay, Limit: t.limits.OrgDaily, Start: day, Resets: day.AddDate(0, 0, 1)},
+	} {
+		if b.Limit > 0 && b.Used >= b.Limit {
+			return &b, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,67 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"prmate/internal/store"
+)
+
+func TestTracker_Check(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	s, err := store.OpenFileStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []store.ReviewRecord{
+		{Instance: "acme", Owner: "acme", Repo: "api", FinishedAt: now.Add(-time.Hour), EstimatedTokens: 400},
+		{Instance: "acme", Owner: "acme", Repo: "api", FinishedAt: now.AddDate(0, 0, -3), EstimatedTokens: 1000},
+		{Instance: "acme", Owner: "acme", Repo: "web", FinishedAt: now.Add(-2 * time.Hour), EstimatedTokens: 300},
+		{Instance: "acme", Owner: "acme", Repo: "api", FinishedAt: now.AddDate(0, -1, 0), EstimatedTokens: 9000}, // last month
+		{Instance: "other", Owner: "acme", Repo: "api", FinishedAt: now.Add(-time.Hour), EstimatedTokens: 9000},
+	} {
+		if err := s.RecordReview(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		limits  Limits
+		repo    string
+		wantKey string
+		wantUse int
+	}{
+		{name: "no limits", limits: Limits{}, repo: "api"},
+		{name: "under every budget", limits: Limits{RepoDaily: 500, RepoMonthly: 2000, OrgDaily: 800, OrgMonthly: 3000}, repo: "api"},
+		{name: "repo daily", limits: Limits{RepoDaily: 400}, repo: "api", wantKey: "acme/api:daily:2026-10-15", wantUse: 400},
+		{name: "org daily from another repo", limits: Limits{RepoDaily: 400, OrgDaily: 700}, repo: "web", wantKey: "acme:daily:2026-10-15", wantUse: 700},
+		{name: "monthly before daily", limits: Limits{RepoDaily: 100, RepoMonthly: 1400}, repo: "api", wantKey: "acme/api:monthly:2026-10", wantUse: 1400},
+		{name: "org monthly", limits: Limits{OrgMonthly: 1700}, repo: "web", wantKey: "acme:monthly:2026-10", wantUse: 1700},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTracker(s, "acme", tt.limits)
+			tr.now = func() time.Time { return now }
+
+			got, err := tr.Check(context.Background(), "acme", tt.repo)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if tt.wantKey == "" {
+				if got != nil {
+					t.Errorf("Check() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Key() != tt.wantKey || got.Used != tt.wantUse {
+				t.Fatalf("Check() = %+v, want %s with %d used", got, tt.wantKey, tt.wantUse)
+			}
+			if !got.Resets.After(now) {
+				t.Errorf("Resets = %v, want after %v", got.Resets, now)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+// Package changelog checks that pull requests changing user-facing code
+// come with a changelog entry, in whichever form the repository keeps its
+// changelog: a CHANGELOG file, changesets, or release-drafter labels. When
+// the entry is missing it comments on the pull request, with a draft entry
+// written by the LLM.
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/logging"
+)
+
+const (
+	// Marker tags the missing-entry comment; it is posted once per PR
+	Marker = "<!-- prmate-changelog -->"
+	// maxPatchBytes bounds the diff sent to the LLM for a draft
+	maxPatchBytes = 16 * 1024
+	// exampleLines of the existing changelog show the LLM its style
+	exampleLines = 40
+)
+
+// Kinds of changelog a repository can keep
+const (
+	KindFile           = "file"
+	KindChangesets     = "changesets"
+	KindReleaseDrafter = "release-drafter"
+)
+
+// skipLabels mark pull requests that need no changelog entry
+var skipLabels = []string{"skip-changelog", "no-changelog", "skip-release-notes"}
+
+// changelogFiles are the root files recognized as changelogs, in order
+var changelogFiles = []string{"CHANGELOG.md", "CHANGELOG", "CHANGES.md", "HISTORY.md"}
+
+// fragmentDirs hold one file per change that tools such as towncrier
+// compile into the changelog file
+var fragmentDirs = []string{"changelog.d/", ".changes/"}
+
+// GitHubClient is the GitHub access the checker needs
+type GitHubClient interface {
+	GetPullRequest(ctx context.Context, owner, repo string, prNumber int) (*ghclient.PullRequest, error)
+	GetPRFiles(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.PRFile, error)
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
+	ListPRComments(ctx context.Context, owner, repo string, prNumber int) ([]string, error)
+	CreatePRComment(ctx context.Context, owner, repo string, prNumber int, body string) error
+}
+
+// LLMProvider drafts changelog entries
+type LLMProvider interface {
+	GenerateTextWithContext(ctx context.Context, prompt string) (string, error)
+}
+
+// Format describes how a repository keeps its changelog
+type Format struct {
+	Kind    string
+	Path    string   // changelog file, or the changesets directory
+	Example string   // beginning of the changelog file
+	Labels  []string // release-drafter category labels
+}
+
+// Checker comments on pull requests missing a changelog entry
+type Checker struct {
+	gh  GitHubClient
+	llm LLMProvider
+}
+
+// NewChecker returns a checker; llm may be nil to skip the draft entry
+func NewChecker(gh GitHubClient, llm LLMProvider) *Checker {
+	return &Checker{gh: gh, llm: llm}
+}
+
+// Check looks for a changelog entry in a pull request and, when one is
+// needed but missing and the PR was not commented on yet, posts a comment.
+// It reports whether a comment was posted.
+func (c *Checker) Check(ctx context.Context, owner, repo string, prNumber int) (bool, error) {
+	pr, err := c.gh.GetPullRequest(ctx, owner, repo, prNumber)
+	if err != nil {
+		return false, fmt.Errorf("get pull request: %w", err)
+	}
+	if hasAny(pr.Labels, skipLabels) {
+		return false, nil
+	}
+
+	format, err := c.Detect(ctx, owner, repo, pr.BaseRef)
+	if err != nil {
+		return false, err
+	}
+	if format == nil {
+		return false, nil
+	}
+
+	files, err := c.gh.GetPRFiles(ctx, owner, repo, prNumber)
+	if err != nil {
+		return false, fmt.Errorf("get pr files: %w", err)
+	}
+	if HasEntry(format, pr, files) || !UserFacing(files) {
+		return false, nil
+	}
+
+	comments, err := c.gh.ListPRComments(ctx, owner, repo, prNumber)
+	if err != nil {
+		return false, fmt.Errorf("list pr comments: %w", err)
+	}
+	for _, body := range comments {
+		if strings.Contains(body, Marker) {
+			return false, nil
+		}
+	}
+	logging.FromContext(ctx).Info("Pull request has no changelog entry", "changelog", format.Kind)
+
+	body := Marker + "\n" + c.render(ctx, format, pr, files)
+	if err := c.gh.CreatePRComment(ctx, owner, repo, prNumber, body); err != nil {
+		return false, fmt.Errorf("post changelog comment: %w", err)
+	}
+	return true, nil
+}
+
+// Detect returns how the repository keeps its changelog at ref, or nil
+// when it keeps none PRMate recognizes
+func (c *Checker) Detect(ctx context.Context, owner, repo, ref string) (*Format, error) {
+	if _, err := c.gh.GetFileContent(ctx, owner, repo, ".changeset/config.json", ref); err == nil {
+		return &Format{Kind: KindChangesets, Path: ".changeset"}, nil
+	}
+
+	if content, err := c.gh.GetFileContent(ctx, owner, repo, ".github/release-drafter.yml", ref); err == nil {
+		labels, err := drafterLabels(content)
+		if err != nil {
+			return nil, fmt.Errorf("parse release-drafter.yml: %w", err)
+		}
+		if len(labels) > 0 {
+			return &Format{Kind: KindReleaseDrafter, Path: ".github/release-drafter.yml", Labels: labels}, nil
+		}
+	}
+
+	for _, name := range changelogFiles {
+		content, err := c.gh.GetFileContent(ctx, owner, repo, name, ref)
+		if err != nil {
+			continue
+		}
+		lines := strings.Split(content, "\n")
+		if len(lines) > exampleLines {
+			lines = lines[:exampleLines]
+		}
+		return &Format{Kind: KindFile, Path: name, Example: strings.Join(lines, "\n")}, nil
+	}
+	return nil, nil
+}
+
+// drafterLabels returns the labels release-drafter sorts changes by
+func drafterLabels(content string) ([]string, error) {
+	var cfg struct {
+		Categories []struct {
+			Label  string   `yaml:"label"`
+			Labels []string `yaml:"labels"`
+		} `yaml:"categories"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		return nil, err
+	}
+	var labels []string
+	for _, cat := range cfg.Categories {
+		if cat.Label != "" {
+			labels = append(labels, cat.Label)
+		}
+		labels = append(labels, cat.Labels...)
+	}
+	return labels, nil
+}
+
+// HasEntry reports whether the pull request carries a changelog entry in
+// format
+func HasEntry(format *Format, pr *ghclient.PullRequest, files []ghclient.PRFile) bool {
+	switch format.Kind {
+	case KindReleaseDrafter:
+		return hasAny(pr.Labels, format.Labels)
+	case KindChangesets:
+		for _, f := range files {
+			if f.Status == "added" && path.Dir(f.Filename) == format.Path && path.Ext(f.Filename) == ".md" {
+				return true
+			}
+		}
+	case KindFile:
+		for _, f := range files {
+			if f.Filename == format.Path {
+				return true
+			}
+			for _, dir := range fragmentDirs {
+				if strings.HasPrefix(f.Filename, dir) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// UserFacing reports whether any file changes more than tests, docs, CI
+// or repository tooling
+func UserFacing(files []ghclient.PRFile) bool {
+	for _, f := range files {
+		if !internalFile(f.Filename) {
+			return true
+		}
+	}
+	return false
+}
+
+func internalFile(name string) bool {
+	base := path.Base(name)
+	if strings.HasPrefix(base, ".") || strings.HasPrefix(name, ".") {
+		return true
+	}
+	switch strings.ToLower(path.Ext(base)) {
+	case ".md", ".rst", ".txt":
+		return true
+	}
+	if strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") || strings.HasPrefix(base, "test_") {
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(name), "/") {
+		switch dir {
+		case "test", "tests", "testdata", "__tests__", "docs", "doc", "examples":
+			return true
+		}
+	}
+	return false
+}
+
+func hasAny(have, want []string) bool {
+	for _, h := range have {
+		for _, w := range want {
+			if strings.EqualFold(h, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// render builds the comment asking for an entry
+func (c *Checker) render(ctx context.Context, format *Format, pr *ghclient.PullRequest, files []ghclient.PRFile) string {
+	var sb strings.Builder
+	sb.WriteString("## 📝 Changelog entry missing\n\n")
+	switch format.Kind {
+	case KindReleaseDrafter:
+		fmt.Fprintf(&sb, "This pull request changes user-facing code but has none of the labels release-drafter sorts release notes by: %s.", quoteList(format.Labels))
+	case KindChangesets:
+		fmt.Fprintf(&sb, "This pull request changes user-facing code but does not add a changeset under `%s/`.", format.Path)
+	default:
+		fmt.Fprintf(&sb, "This pull request changes user-facing code but does not add an entry to `%s`.", format.Path)
+	}
+	fmt.Fprintf(&sb, " Add one, or label the pull request `%s` if it needs none.\n", skipLabels[0])
+
+	if c.llm == nil {
+		return sb.String()
+	}
+	draft, err := c.llm.GenerateTextWithContext(ctx, buildPrompt(format, pr, files))
+	if err != nil {
+		logging.FromContext(ctx).Warn("could not draft changelog entry", "error", err)
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "\n### Suggested entry\n\n```markdown\n%s\n```\n", stripFence(draft))
+	return sb.String()
+}
+
+func buildPrompt(format *Format, pr *ghclient.PullRequest, files []ghclient.PRFile) string {
+	var sb strings.Builder
+	sb.WriteString("Draft a changelog entry for this pull request, written for its users rather than its developers.\n\n")
+	fmt.Fprintf(&sb, "## Title\n\n%s\n\n", pr.Title)
+	if body := strings.TrimSpace(pr.Body); body != "" {
+		if len(body) > 2000 {
+			body = body[:2000]
+		}
+		fmt.Fprintf(&sb, "## Description\n\n%s\n\n", body)
+	}
+
+	sb.WriteString("## Changes\n\n")
+	budget := maxPatchBytes
+	for _, f := range files {
+		fmt.Fprintf(&sb, "### %s (%s)\n", f.Filename, f.Status)
+		if f.Patch != "" && len(f.Patch) <= budget {
+			fmt.Fprintf(&sb, "```diff\n%s\n```\n", f.Patch)
+			budget -= len(f.Patch)
+		}
+	}
+
+	sb.WriteString("\n## Format\n\n")
+	switch format.Kind {
+	case KindReleaseDrafter:
+		fmt.Fprintf(&sb, "Release notes are generated from pull request titles. Respond with a release-note title on the first line and, on the second, `Label: ` followed by the best fitting of: %s.\n", strings.Join(format.Labels, ", "))
+	case KindChangesets:
+		sb.WriteString("Respond with a complete changeset file: YAML front matter mapping each affected package to patch, minor or major, then a one-sentence summary.\n")
+	default:
+		fmt.Fprintf(&sb, "The changelog begins:\n\n```\n%s\n```\n\nRespond with only the lines to add for this change, matching the style of the existing entries.\n", format.Example)
+	}
+	sb.WriteString("Do not add any explanation.\n")
+	return sb.String()
+}
+
+// stripFence removes a code fence the LLM wrapped its answer in
+func stripFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+	if i := strings.Index(s, "\n"); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "```"))
+}
+
+func quoteList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = "`" + item + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
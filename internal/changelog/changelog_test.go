@@ -0,0 +1,161 @@
+package changelog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+type fakeGitHub struct {
+	labels   []string
+	files    []ghclient.PRFile
+	contents map[string]string // path -> content on the base branch
+	comments []string
+}
+
+func (f *fakeGitHub) GetPullRequest(ctx context.Context, owner, repo string, prNumber int) (*ghclient.PullRequest, error) {
+	return &ghclient.PullRequest{Number: prNumber, Title: "Add retries", BaseRef: "main", Labels: f.labels}, nil
+}
+
+func (f *fakeGitHub) GetPRFiles(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.PRFile, error) {
+	return f.files, nil
+}
+
+func (f *fakeGitHub) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	content, ok := f.contents[path]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return content, nil
+}
+
+func (f *fakeGitHub) ListPRComments(ctx context.Context, owner, repo string, prNumber int) ([]string, error) {
+	return f.comments, nil
+}
+
+func (f *fakeGitHub) CreatePRComment(ctx context.Context, owner, repo string, prNumber int, body string) error {
+	f.comments = append(f.comments, body)
+	return nil
+}
+
+type fakeLLM struct{ prompt string }
+
+func (f *fakeLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	f.prompt = prompt
+	return "```markdown\n- Retry failed uploads\n```", nil
+}
+
+const releaseDrafter = `categories:
+  - title: Features
+    labels: [feature, enhancement]
+  - title: Fixes
+    label: bug
+`
+
+func TestChecker_Check(t *testing.T) {
+	code := ghclient.PRFile{Filename: "internal/upload/upload.go", Status: "modified", Patch: "+retry()"}
+	tests := []struct {
+		name     string
+		gh       *fakeGitHub
+		wantPost bool
+		want     []string
+	}{
+		{
+			name:     "changelog file missing entry",
+			gh:       &fakeGitHub{files: []ghclient.PRFile{code}, contents: map[string]string{"CHANGELOG.md": "# Changelog\n\n- Fix crash\n"}},
+			wantPost: true,
+			want:     []string{Marker, "add an entry to `CHANGELOG.md`", "skip-changelog", "```markdown\n- Retry failed uploads\n```"},
+		},
+		{
+			name: "changelog file updated",
+			gh:   &fakeGitHub{files: []ghclient.PRFile{code, {Filename: "CHANGELOG.md"}}, contents: map[string]string{"CHANGELOG.md": ""}},
+		},
+		{
+			name: "fragment added",
+			gh:   &fakeGitHub{files: []ghclient.PRFile{code, {Filename: "changelog.d/42.feature.md", Status: "added"}}, contents: map[string]string{"CHANGELOG.md": ""}},
+		},
+		{
+			name: "tests and docs only",
+			gh:   &fakeGitHub{files: []ghclient.PRFile{{Filename: "internal/upload/upload_test.go"}, {Filename: "docs/upload.md"}, {Filename: ".github/workflows/ci.yml"}}, contents: map[string]string{"CHANGELOG.md": ""}},
+		},
+		{
+			name: "skip label",
+			gh:   &fakeGitHub{labels: []string{"Skip-Changelog"}, files: []ghclient.PRFile{code}, contents: map[string]string{"CHANGELOG.md": ""}},
+		},
+		{
+			name: "no changelog",
+			gh:   &fakeGitHub{files: []ghclient.PRFile{code}, contents: map[string]string{}},
+		},
+		{
+			name: "already commented",
+			gh:   &fakeGitHub{files: []ghclient.PRFile{code}, contents: map[string]string{"CHANGELOG.md": ""}, comments: []string{Marker + "\nearlier"}},
+		},
+		{
+			name:     "changeset missing",
+			gh:       &fakeGitHub{files: []ghclient.PRFile{code}, contents: map[string]string{".changeset/config.json": "{}"}},
+			wantPost: true,
+			want:     []string{"add a changeset under `.changeset/`"},
+		},
+		{
+			name: "changeset added",
+			gh:   &fakeGitHub{files: []ghclient.PRFile{code, {Filename: ".changeset/quiet-cats.md", Status: "added"}}, contents: map[string]string{".changeset/config.json": "{}"}},
+		},
+		{
+			name:     "release-drafter label missing",
+			gh:       &fakeGitHub{labels: []string{"backend"}, files: []ghclient.PRFile{code}, contents: map[string]string{".github/release-drafter.yml": releaseDrafter}},
+			wantPost: true,
+			want:     []string{"`feature`, `enhancement`, `bug`"},
+		},
+		{
+			name: "release-drafter label present",
+			gh:   &fakeGitHub{labels: []string{"bug"}, files: []ghclient.PRFile{code}, contents: map[string]string{".github/release-drafter.yml": releaseDrafter}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(tt.gh.comments)
+			posted, err := NewChecker(tt.gh, &fakeLLM{}).Check(context.Background(), "acme", "api", 7)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if posted != tt.wantPost || len(tt.gh.comments)-before != map[bool]int{true: 1}[tt.wantPost] {
+				t.Fatalf("Check() posted = %v with %d new comments, want %v", posted, len(tt.gh.comments)-before, tt.wantPost)
+			}
+			if !posted {
+				return
+			}
+			body := tt.gh.comments[len(tt.gh.comments)-1]
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("comment missing %q:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestChecker_Check_WithoutLLM(t *testing.T) {
+	gh := &fakeGitHub{files: []ghclient.PRFile{{Filename: "main.go"}}, contents: map[string]string{"CHANGELOG.md": ""}}
+	posted, err := NewChecker(gh, nil).Check(context.Background(), "acme", "api", 7)
+	if err != nil || !posted {
+		t.Fatalf("Check() = %v, %v", posted, err)
+	}
+	if strings.Contains(gh.comments[0], "Suggested entry") {
+		t.Errorf("comment has a draft without an LLM:\n%s", gh.comments[0])
+	}
+}
+
+func TestBuildPrompt_FileExample(t *testing.T) {
+	format := &Format{Kind: KindFile, Path: "CHANGELOG.md", Example: "## Unreleased\n- Fix crash"}
+	pr := &ghclient.PullRequest{Title: "Add retries", Body: "Uploads now retry."}
+	prompt := buildPrompt(format, pr, []ghclient.PRFile{{Filename: "upload.go", Status: "modified", Patch: "+retry()"}})
+	for _, want := range []string{"Add retries", "Uploads now retry.", "### upload.go (modified)", "+retry()", "## Unreleased\n- Fix crash", "matching the style"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package checks
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	ghclient "prmate/internal/github"
+)
+
+// AssetPolicy bounds the files a change adds without a text diff, such as
+// binaries, images and archives. It is declared in a prmate-checks record of
+// its own:
+//
+//	max-binary-size: 2MB
+//	forbidden-extensions: .exe, .jar, .zip
+//	lfs: **/*.psd, assets/video/**
+//	severity: error
+type AssetPolicy struct {
+	MaxBinarySize int64    // bytes a binary committed outside Git LFS may have; 0 disables
+	Forbidden     []string // extensions, lower case with the leading dot, that may not be added
+	LFS           []string // globs of files that must be stored in Git LFS
+	Severity      string
+}
+
+// Rule names of asset policy findings
+const (
+	RuleBinarySize    = "binary-size"
+	RuleForbiddenFile = "forbidden-extension"
+	RuleLFSRequired   = "lfs-required"
+)
+
+// lfsPointerVersion starts the first line of every Git LFS pointer file
+const lfsPointerVersion = "version https://git-lfs.github.com/spec/"
+
+// assetKeys are the keys of an asset policy record
+var assetKeys = map[string]bool{"max-binary-size": true, "forbidden-extensions": true, "lfs": true, "severity": true}
+
+// isAssetRecord reports whether record declares an asset policy
+func isAssetRecord(record map[string]string) bool {
+	_, size := record["max-binary-size"]
+	_, ext := record["forbidden-extensions"]
+	_, lfs := record["lfs"]
+	return size || ext || lfs
+}
+
+// addAssets merges an asset policy record into the set
+func (s *Set) addAssets(record map[string]string) error {
+	for key := range record {
+		if !assetKeys[key] {
+			return fmt.Errorf("%s is not allowed in an asset policy record", key)
+		}
+	}
+	if s.Assets == nil {
+		s.Assets = &AssetPolicy{Severity: "warning"}
+	}
+	a := s.Assets
+
+	if v, ok := record["max-binary-size"]; ok {
+		n, err := parseSize(v)
+		if err != nil {
+			return fmt.Errorf("max-binary-size: %w", err)
+		}
+		a.MaxBinarySize = n
+	}
+	for _, ext := range splitList(record["forbidden-extensions"]) {
+		ext = strings.ToLower(ext)
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		a.Forbidden = append(a.Forbidden, ext)
+	}
+	a.LFS = append(a.LFS, splitList(record["lfs"])...)
+	if v, ok := record["severity"]; ok {
+		switch v {
+		case "error", "warning", "suggestion":
+			a.Severity = v
+		default:
+			return fmt.Errorf("asset policy: severity %q must be error, warning or suggestion", v)
+		}
+	}
+	return nil
+}
+
+// parseSize reads a byte count such as 500KB, 2MB, 1.5GB or 1048576
+func parseSize(s string) (int64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		mult   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(v, unit.suffix) {
+			v, mult = strings.TrimSpace(strings.TrimSuffix(v, unit.suffix)), unit.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q must be a positive size such as 500KB or 2MB", s)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+// formatSize renders a byte count the way parseSize reads it
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return strconv.FormatFloat(float64(n)/(1<<30), 'f', 1, 64) + "GB"
+	case n >= 1<<20:
+		return strconv.FormatFloat(float64(n)/(1<<20), 'f', 1, 64) + "MB"
+	case n >= 1<<10:
+		return strconv.FormatFloat(float64(n)/(1<<10), 'f', 1, 64) + "KB"
+	}
+	return strconv.FormatInt(n, 10) + "B"
+}
+
+// Binary reports whether file changes content GitHub shows no diff for.
+// Large text diffs also lose their patch, but keep their line counts.
+func Binary(file ghclient.PRFile) bool {
+	return file.Patch == "" && file.Additions == 0 && file.Deletions == 0 &&
+		(file.Status == "added" || file.Status == "modified")
+}
+
+// lfsPointer reports whether patch leaves a Git LFS pointer file behind.
+// An updated pointer keeps its version line as context.
+func lfsPointer(patch string) bool {
+	for _, hunk := range ghclient.ParsePatch(patch) {
+		for _, line := range hunk.Lines {
+			if line.Type != "remove" && strings.HasPrefix(line.Content[min(1, len(line.Content)):], lfsPointerVersion) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckAsset applies the asset policy to one changed file. Findings have no
+// line: they concern the file as a whole. size returns the size of the
+// file's new content and is only called for binaries when the policy bounds
+// their size.
+func (s *Set) CheckAsset(file ghclient.PRFile, size func() (int64, error)) ([]Finding, error) {
+	if s == nil || s.Assets == nil || s.Ignored(file.Filename) || file.Status == "removed" {
+		return nil, nil
+	}
+	a := s.Assets
+	name := file.Filename
+
+	var findings []Finding
+	if file.Status == "added" || file.Status == "renamed" {
+		ext := strings.ToLower(path.Ext(name))
+		for _, forbidden := range a.Forbidden {
+			if ext == forbidden {
+				findings = append(findings, a.finding(RuleForbiddenFile,
+					fmt.Sprintf("Files with the %s extension may not be committed to this repository", ext)))
+				break
+			}
+		}
+	}
+
+	inLFS := lfsPointer(file.Patch)
+	if !inLFS && matchAny(a.LFS, name) && (Binary(file) || file.Patch != "") {
+		findings = append(findings, a.finding(RuleLFSRequired,
+			"This file must be stored in Git LFS. Run `git lfs track` for its path and commit it again"))
+	}
+
+	if !inLFS && a.MaxBinarySize > 0 && Binary(file) {
+		n, err := size()
+		if err != nil {
+			return findings, fmt.Errorf("size of %s: %w", name, err)
+		}
+		if n > a.MaxBinarySize {
+			findings = append(findings, a.finding(RuleBinarySize,
+				fmt.Sprintf("Binary file is %s, over the %s limit. Store it in Git LFS or outside the repository", formatSize(n), formatSize(a.MaxBinarySize))))
+		}
+	}
+
+	return findings, nil
+}
+
+func (a *AssetPolicy) finding(rule, msg string) Finding {
+	return Finding{Rule: rule, Message: msg, Severity: a.Severity}
+}
@@ -0,0 +1,97 @@
+package checks
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+const assetPolicy = fence + "\n" +
+	"max-binary-size: 1.5MB\n" +
+	"forbidden-extensions: .exe, jar\n" +
+	"lfs: assets/video/**\n" +
+	"severity: error\n" +
+	"\n" +
+	"ignore: third_party/**\n" +
+	"```\n"
+
+func TestParse_AssetPolicy(t *testing.T) {
+	set, err := Parse(assetPolicy)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	a := set.Assets
+	if a == nil {
+		t.Fatal("Assets = nil")
+	}
+	if a.MaxBinarySize != 1572864 || strings.Join(a.Forbidden, ",") != ".exe,.jar" || len(a.LFS) != 1 || a.Severity != "error" {
+		t.Errorf("Assets = %+v", a)
+	}
+	if set.Empty() {
+		t.Error("a set with only an asset policy should not be empty")
+	}
+
+	for record, want := range map[string]string{
+		"max-binary-size: big":                    "positive size",
+		"max-binary-size: 0":                      "positive size",
+		"lfs: **/*.psd\nid: a":                    "id is not allowed",
+		"forbidden-extensions: .exe\nseverity: x": "severity",
+	} {
+		if _, err := Parse(fence + "\n" + record + "\n```\n"); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want it to mention %q", record, err, want)
+		}
+	}
+}
+
+func TestSet_CheckAsset(t *testing.T) {
+	set, err := Parse(assetPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pointer := "@@ -0,0 +1,3 @@\n+version https://git-lfs.github.com/spec/v1\n+oid sha256:4d7a\n+size 52428800"
+
+	tests := []struct {
+		name  string
+		file  ghclient.PRFile
+		size  int64
+		rules []string
+	}{
+		{name: "large binary", file: ghclient.PRFile{Filename: "img/hero.png", Status: "added"}, size: 2 << 20, rules: []string{RuleBinarySize}},
+		{name: "small binary", file: ghclient.PRFile{Filename: "img/icon.png", Status: "modified"}, size: 4096},
+		{name: "forbidden", file: ghclient.PRFile{Filename: "bin/Setup.EXE", Status: "added"}, size: 100, rules: []string{RuleForbiddenFile}},
+		{name: "forbidden rename", file: ghclient.PRFile{Filename: "lib/app.jar", Status: "renamed"}, rules: []string{RuleForbiddenFile}},
+		{name: "lfs missing", file: ghclient.PRFile{Filename: "assets/video/intro.mp4", Status: "added"}, size: 2 << 20, rules: []string{RuleLFSRequired, RuleBinarySize}},
+		{name: "lfs pointer", file: ghclient.PRFile{Filename: "assets/video/intro.mp4", Status: "added", Additions: 3, Patch: pointer}},
+		{name: "lfs pointer update", file: ghclient.PRFile{Filename: "assets/video/intro.mp4", Status: "modified", Additions: 1, Deletions: 1, Patch: "@@ -1,3 +1,3 @@\n version https://git-lfs.github.com/spec/v1\n-oid sha256:4d7a\n+oid sha256:9e1c\n size 52428800"}},
+		{name: "large text diff", file: ghclient.PRFile{Filename: "data/seed.sql", Status: "added", Additions: 90000}, size: 8 << 20},
+		{name: "removed", file: ghclient.PRFile{Filename: "old.exe", Status: "removed"}},
+		{name: "ignored", file: ghclient.PRFile{Filename: "third_party/tool.exe", Status: "added"}, size: 9 << 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := set.CheckAsset(tt.file, func() (int64, error) { return tt.size, nil })
+			if err != nil {
+				t.Fatalf("CheckAsset() error = %v", err)
+			}
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.Rule)
+				if f.Line != 0 || f.Severity != "error" {
+					t.Errorf("finding = %+v, want a file-level error", f)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.rules, ",") {
+				t.Errorf("CheckAsset() rules = %v, want %v", got, tt.rules)
+			}
+		})
+	}
+
+	if _, err := set.CheckAsset(ghclient.PRFile{Filename: "a.png", Status: "added"}, func() (int64, error) {
+		return 0, errors.New("boom")
+	}); err == nil || !strings.Contains(err.Error(), "size of a.png") {
+		t.Errorf("CheckAsset() error = %v, want the size lookup error", err)
+	}
+}
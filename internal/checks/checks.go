@@ -0,0 +1,316 @@
+// Package checks runs the deterministic review checks a repository declares
+// in .prmate.md. Checks need no LLM: they match added lines against regular
+// expressions or bound the size of a change, so they are cheap, repeatable
+// and usable offline.
+//
+// Checks live in fenced prmate-checks blocks, one record per paragraph:
+//
+//	```prmate-checks
+//	id: no-println
+//	pattern: fmt\.Println\(
+//	paths: **/*.go
+//	exclude: **/*_test.go
+//	severity: error
+//	message: Use the structured logger
+//	url: https://wiki.example.com/logging
+//
+//	id: small-files
+//	max-added-lines: 400
+//
+//	ignore: vendor/**, **/*.pb.go
+//
+//	protected: internal/auth/**, migrations/**
+//
+//	language: sv
+//	```
+//
+// A record holding only ignore lists paths that are never reviewed, neither
+// by checks nor by the LLM. A record holding only protected lists paths
+// where every finding is an error and the LLM always sees the whole file. A record holding only language asks the LLM to
+// write its findings in that language (an ISO 639-1 code such as sv or de).
+// A record holding max-binary-size, forbidden-extensions or lfs declares the
+// asset policy for files without a diff; see AssetPolicy. A record holding
+// docs declares the documentation expected to change with the interfaces it
+// describes; see DocsPolicy. A record holding post-hours or freeze declares
+// when automatic reviews may be posted; see Window. A record holding route
+// gives a directory its own rules, severity gate, notification channel and
+// model; see Route. A record holding suppress stops findings of some rules
+// from being reported; see Suppression.
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ghclient "prmate/internal/github"
+)
+
+const fence = "```prmate-checks"
+
+// Check is one deterministic rule
+type Check struct {
+	ID            string
+	Severity      string // "error", "warning" (default) or "suggestion"
+	Message       string
+	Pattern       *regexp.Regexp // flags each added line that matches
+	MaxAddedLines int            // flags a file adding more lines than this; 0 disables
+	Paths         []string       // globs the check applies to; empty means every file
+	Exclude       []string       // globs the check skips
+	URL           string         // documents why the check exists; linked from its findings
+}
+
+// Set holds the checks and path filters declared in one .prmate.md
+type Set struct {
+	Checks   []Check
+	Ignore   []string      // globs of files excluded from every review
+	Protect  []string      // globs of files where every finding is an error
+	Language string        // language LLM findings are written in; empty means English
+	Assets   *AssetPolicy  // limits on binaries and other files without a diff; nil when not declared
+	Docs     *DocsPolicy   // documentation to update with interface changes; nil when not declared
+	Window   *Window       // when automatic reviews may be posted; nil allows any time
+	Routes   []Route       // teams reviewing directories of a monorepo their own way
+	Suppress []Suppression // rules whose findings are not reported
+}
+
+// languageCode matches language tags such as sv, pt-BR or zh-Hant
+var languageCode = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// Finding is a check failure on one line of a file
+type Finding struct {
+	Line     int
+	Rule     string
+	Message  string
+	Severity string
+}
+
+// Parse reads every prmate-checks block in content. A file without blocks
+// yields an empty set.
+func Parse(content string) (*Set, error) {
+	set := &Set{}
+	for i, block := range fencedBlocks(content) {
+		for j, record := range records(block) {
+			if err := set.add(record); err != nil {
+				return nil, fmt.Errorf("prmate-checks block %d, record %d: %w", i+1, j+1, err)
+			}
+		}
+	}
+	return set, nil
+}
+
+// Empty reports whether the set declares no checks and no asset or docs
+// policy
+func (s *Set) Empty() bool {
+	return s == nil || (len(s.Checks) == 0 && s.Assets == nil && s.Docs == nil)
+}
+
+// Ignored reports whether path is excluded from review
+func (s *Set) Ignored(path string) bool {
+	return s != nil && matchAny(s.Ignore, path)
+}
+
+// Protected reports whether path is in a protected area of the repository
+func (s *Set) Protected(path string) bool {
+	return s != nil && matchAny(s.Protect, path)
+}
+
+// Run applies every check that covers path to the lines patch adds.
+// .prmate.md itself is never checked: it holds the patterns it would match.
+func (s *Set) Run(path, patch string) []Finding {
+	if s.Empty() || s.Ignored(path) || path == ".prmate.md" {
+		return nil
+	}
+
+	var added []ghclient.PatchLine
+	for _, hunk := range ghclient.ParsePatch(patch) {
+		for _, line := range hunk.Lines {
+			if line.Type == "add" {
+				added = append(added, line)
+			}
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	var findings []Finding
+	for _, c := range s.Checks {
+		if !c.covers(path) {
+			continue
+		}
+		if c.Pattern != nil {
+			for _, line := range added {
+				if c.Pattern.MatchString(strings.TrimPrefix(line.Content, "+")) {
+					findings = append(findings, c.finding(line.NewLineNo, c.Message))
+				}
+			}
+		}
+		if c.MaxAddedLines > 0 && len(added) > c.MaxAddedLines {
+			msg := fmt.Sprintf("%s (%d lines added, limit %d)", c.Message, len(added), c.MaxAddedLines)
+			findings = append(findings, c.finding(added[0].NewLineNo, msg))
+		}
+	}
+	return findings
+}
+
+func (c Check) covers(path string) bool {
+	if len(c.Paths) > 0 && !matchAny(c.Paths, path) {
+		return false
+	}
+	return !matchAny(c.Exclude, path)
+}
+
+func (c Check) finding(line int, msg string) Finding {
+	return Finding{Line: line, Rule: c.ID, Message: msg, Severity: c.Severity}
+}
+
+// add turns one key: value record into a check or ignore list
+func (s *Set) add(record map[string]string) error {
+	if ignore, ok := record["ignore"]; ok {
+		if len(record) > 1 {
+			return fmt.Errorf("ignore must be in a record of its own")
+		}
+		s.Ignore = append(s.Ignore, splitList(ignore)...)
+		return nil
+	}
+	if protected, ok := record["protected"]; ok {
+		if len(record) > 1 {
+			return fmt.Errorf("protected must be in a record of its own")
+		}
+		s.Protect = append(s.Protect, splitList(protected)...)
+		return nil
+	}
+	if language, ok := record["language"]; ok {
+		if len(record) > 1 {
+			return fmt.Errorf("language must be in a record of its own")
+		}
+		if !languageCode.MatchString(language) {
+			return fmt.Errorf("language %q must be a language code such as sv or pt-BR", language)
+		}
+		s.Language = language
+		return nil
+	}
+
+	if _, ok := record["docs"]; ok {
+		return s.addDocs(record)
+	}
+	if isWindowRecord(record) {
+		return s.addWindow(record)
+	}
+	if _, ok := record["route"]; ok {
+		return s.addRoute(record)
+	}
+	if _, ok := record["suppress"]; ok {
+		return s.addSuppression(record)
+	}
+	if isAssetRecord(record) {
+		return s.addAssets(record)
+	}
+
+	c := Check{
+		ID:       record["id"],
+		Severity: record["severity"],
+		Message:  record["message"],
+		Paths:    splitList(record["paths"]),
+		Exclude:  splitList(record["exclude"]),
+		URL:      record["url"],
+	}
+	if c.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	if c.URL != "" && !strings.HasPrefix(c.URL, "https://") && !strings.HasPrefix(c.URL, "http://") {
+		return fmt.Errorf("check %q: url %q must start with https:// or http://", c.ID, c.URL)
+	}
+	switch c.Severity {
+	case "":
+		c.Severity = "warning"
+	case "error", "warning", "suggestion":
+	default:
+		return fmt.Errorf("check %q: severity %q must be error, warning or suggestion", c.ID, c.Severity)
+	}
+	if p, ok := record["pattern"]; ok {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("check %q: %w", c.ID, err)
+		}
+		c.Pattern = re
+	}
+	if v, ok := record["max-added-lines"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("check %q: max-added-lines must be a positive number", c.ID)
+		}
+		c.MaxAddedLines = n
+	}
+	if c.Pattern == nil && c.MaxAddedLines == 0 {
+		return fmt.Errorf("check %q: needs pattern or max-added-lines", c.ID)
+	}
+	if c.Message == "" {
+		c.Message = "Violates " + c.ID
+	}
+
+	s.Checks = append(s.Checks, c)
+	return nil
+}
+
+// fencedBlocks returns the bodies of the prmate-checks fences in content
+func fencedBlocks(content string) []string {
+	var blocks []string
+	var current []string
+	inBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !inBlock && trimmed == fence:
+			inBlock = true
+			current = nil
+		case inBlock && trimmed == "```":
+			inBlock = false
+			blocks = append(blocks, strings.Join(current, "\n"))
+		case inBlock:
+			current = append(current, line)
+		}
+	}
+	return blocks
+}
+
+// records splits a block into blank-line separated key: value maps,
+// skipping # comments
+func records(block string) []map[string]string {
+	var out []map[string]string
+	current := map[string]string{}
+	flush := func() {
+		if len(current) > 0 {
+			out = append(out, current)
+			current = map[string]string{}
+		}
+	}
+	for _, line := range strings.Split(block, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			flush()
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		current[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	flush()
+	return out
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,150 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+)
+
+const sample = "# PRMate Context\n\n" +
+	"```prmate-checks\n" +
+	"# no stray prints\n" +
+	"id: no-println\n" +
+	"pattern: fmt\\.Println\\(\n" +
+	"paths: **/*.go\n" +
+	"exclude: **/*_test.go\n" +
+	"severity: error\n" +
+	"message: Use the structured logger\n" +
+	"url: https://wiki.example.com/logging\n" +
+	"\n" +
+	"id: small-files\n" +
+	"max-added-lines: 2\n" +
+	"\n" +
+	"ignore: vendor/**, *.pb.go\n" +
+	"\n" +
+	"protected: migrations/**\n" +
+	"\n" +
+	"language: sv\n" +
+	"```\n"
+
+const patch = "@@ -1,2 +1,5 @@\n" +
+	" package main\n" +
+	"+\n" +
+	"+func main() {\n" +
+	"+\tfmt.Println(\"hi\")\n" +
+	" }"
+
+func TestParse(t *testing.T) {
+	set, err := Parse(sample)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(set.Checks) != 2 {
+		t.Fatalf("got %d checks, want 2", len(set.Checks))
+	}
+	if c := set.Checks[0]; c.URL != "https://wiki.example.com/logging" {
+		t.Errorf("URL = %q, want the wiki page", c.URL)
+	}
+	if c := set.Checks[1]; c.Severity != "warning" || c.Message != "Violates small-files" {
+		t.Errorf("defaults not applied: %+v", c)
+	}
+	if len(set.Ignore) != 2 {
+		t.Errorf("Ignore = %v, want 2 entries", set.Ignore)
+	}
+	if !set.Protected("migrations/001_users.sql") || set.Protected("cmd/main.go") {
+		t.Errorf("Protect = %v, want migrations/** only", set.Protect)
+	}
+	if set.Language != "sv" {
+		t.Errorf("Language = %q, want sv", set.Language)
+	}
+
+	empty, err := Parse("# No checks here\n")
+	if err != nil || !empty.Empty() {
+		t.Errorf("Parse() without blocks = %+v, %v; want empty set", empty, err)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		want   string
+	}{
+		{"missing id", "pattern: x", "id is required"},
+		{"bad regex", "id: a\npattern: (", "check \"a\""},
+		{"no condition", "id: a\nmessage: nothing to match", "needs pattern"},
+		{"bad severity", "id: a\npattern: x\nseverity: fatal", "severity"},
+		{"bad limit", "id: a\nmax-added-lines: -1", "max-added-lines"},
+		{"bad url", "id: a\npattern: x\nurl: wiki/logging", "url"},
+		{"ignore with check", "id: a\npattern: x\nignore: vendor/**", "ignore must be"},
+		{"protected with check", "id: a\npattern: x\nprotected: auth/**", "protected must be"},
+		{"language with ignore", "language: sv\nignore: vendor/**", "must be in a record of its own"},
+		{"bad language", "language: Swedish please", "language code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(fence + "\n" + tt.record + "\n```\n")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Parse() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSet_Run(t *testing.T) {
+	set, err := Parse(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path  string
+		rules []string
+		line  int
+	}{
+		{path: "cmd/main.go", rules: []string{"no-println", "small-files"}, line: 4},
+		{path: "cmd/main_test.go", rules: []string{"small-files"}},
+		{path: "vendor/x/main.go"},
+		{path: "api/types.pb.go"},
+		{path: ".prmate.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			findings := set.Run(tt.path, patch)
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.Rule)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.rules, ",") {
+				t.Fatalf("Run() rules = %v, want %v", got, tt.rules)
+			}
+			if tt.line != 0 && findings[0].Line != tt.line {
+				t.Errorf("finding line = %d, want %d", findings[0].Line, tt.line)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "a/b/c.go", true},
+		{"**/*.go", "c.go", true},
+		{"**/*.go", "a/b/c.go", true},
+		{"vendor/**", "vendor/x/y.go", true},
+		{"vendor/**", "src/vendor/y.go", false},
+		{"internal/*/service.go", "internal/review/service.go", true},
+		{"internal/*/service.go", "internal/a/b/service.go", false},
+		{"docs/**/*.md", "docs/guide/intro.md", true},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	ghclient "prmate/internal/github"
+)
+
+// DocsPolicy asks for documentation to change along with the interfaces it
+// describes. It is declared in a prmate-checks record of its own:
+//
+//	docs: README.md, docs/**, api/openapi.yaml
+//	exclude: internal/**
+//	severity: suggestion
+//
+// When a pull request adds, removes or changes an exported Go function, a
+// command line flag or an HTTP route outside the excluded paths, and updates
+// none of the docs files, each such change is reported as probable
+// documentation drift.
+type DocsPolicy struct {
+	Docs     []string // globs of the documentation files
+	Exclude  []string // globs of source files whose interfaces need no docs
+	Severity string
+}
+
+// RuleDocDrift names documentation drift findings
+const RuleDocDrift = "doc-drift"
+
+// maxDriftFindings bounds the drift findings of one review; a large change
+// to the interface needs one docs update, not a comment on every line
+const maxDriftFindings = 10
+
+// docsKeys are the keys of a docs policy record
+var docsKeys = map[string]bool{"docs": true, "exclude": true, "severity": true}
+
+var (
+	// exportedFunc matches the declaration of an exported Go function or
+	// method
+	exportedFunc = regexp.MustCompile(`^func\s+(?:\([^)]*\)\s*)?([A-Z]\w*)\s*[\[(]`)
+	// flagDef matches the definition of a flag with the flag package or
+	// pflag, capturing its name
+	flagDef = regexp.MustCompile(`\.(?:Bool|String|StringSlice|Int|Int64|Uint|Uint64|Float64|Duration|Func|BoolFunc|TextVar|Var)(?:Var)?P?\([^")]*"([A-Za-z][\w.-]*)"`)
+	// routeDef matches route registrations in gin, chi, net/http and
+	// Express, capturing the path with its method if it has one
+	routeDef = regexp.MustCompile("\\.(?:GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS|Any|Handle|HandleFunc|Get|Post|Put|Patch|Delete|get|post|put|patch|delete)\\(\\s*[\"'`]((?:[A-Z]+\\s+)?/[^\"'`]*)[\"'`]")
+)
+
+// FileFinding is a finding on a file of a pull request
+type FileFinding struct {
+	Path string
+	Finding
+}
+
+// addDocs sets the docs policy from a record
+func (s *Set) addDocs(record map[string]string) error {
+	for key := range record {
+		if !docsKeys[key] {
+			return fmt.Errorf("%s is not allowed in a docs record", key)
+		}
+	}
+	if s.Docs != nil {
+		return fmt.Errorf("docs may only be declared once")
+	}
+	d := &DocsPolicy{Docs: splitList(record["docs"]), Exclude: splitList(record["exclude"]), Severity: "suggestion"}
+	if len(d.Docs) == 0 {
+		return fmt.Errorf("docs must list at least one path")
+	}
+	if v, ok := record["severity"]; ok {
+		switch v {
+		case "error", "warning", "suggestion":
+			d.Severity = v
+		default:
+			return fmt.Errorf("docs: severity %q must be error, warning or suggestion", v)
+		}
+	}
+	s.Docs = d
+	return nil
+}
+
+// interfaceChange is an exported function, flag or route a patch adds,
+// removes or changes
+type interfaceChange struct {
+	kind string // "exported function", "flag" or "route"
+	name string
+	line int    // line of the new definition; 0 when it was removed
+	verb string // "added", "removed" or "changed"
+}
+
+// DocDrift reports the interface changes in files when the pull request
+// updates no documentation. all holds every file the pull request changes;
+// files, the ones being reviewed now, may be fewer on incremental reviews.
+func (s *Set) DocDrift(files, all []ghclient.PRFile) []FileFinding {
+	if s == nil || s.Docs == nil {
+		return nil
+	}
+	d := s.Docs
+	for _, f := range all {
+		if matchAny(d.Docs, f.Filename) {
+			return nil
+		}
+	}
+
+	var findings []FileFinding
+	for _, f := range files {
+		if s.Ignored(f.Filename) || matchAny(d.Exclude, f.Filename) || f.Filename == ".prmate.md" || strings.HasSuffix(f.Filename, "_test.go") {
+			continue
+		}
+		for _, c := range interfaceChanges(f.Filename, f.Patch) {
+			if len(findings) == maxDriftFindings {
+				return findings
+			}
+			msg := fmt.Sprintf("%s `%s` was %s, but no documentation (%s) was updated in this pull request. Update the docs if they describe it",
+				capitalize(c.kind), c.name, c.verb, strings.Join(d.Docs, ", "))
+			findings = append(findings, FileFinding{
+				Path:    f.Filename,
+				Finding: Finding{Line: c.line, Rule: RuleDocDrift, Message: msg, Severity: d.Severity},
+			})
+		}
+	}
+	return findings
+}
+
+// interfaceChanges finds the definitions patch adds and removes. Functions
+// count as changed when their signature line differs; flags and routes only
+// when one is added or removed, so that edits to help text are not reported.
+func interfaceChanges(path, patch string) []interfaceChange {
+	type def struct {
+		kind, name, text string
+		line             int
+	}
+	var added, removed []def
+	for _, hunk := range ghclient.ParsePatch(patch) {
+		for _, line := range hunk.Lines {
+			if line.Type != "add" && line.Type != "remove" {
+				continue
+			}
+			text := strings.TrimSpace(line.Content[min(1, len(line.Content)):])
+			var defs []def
+			if strings.HasSuffix(path, ".go") {
+				if m := exportedFunc.FindStringSubmatch(text); m != nil {
+					defs = append(defs, def{kind: "exported function", name: m[1], text: text})
+				}
+			}
+			for _, m := range flagDef.FindAllStringSubmatch(text, -1) {
+				defs = append(defs, def{kind: "flag", name: m[1]})
+			}
+			for _, m := range routeDef.FindAllStringSubmatch(text, -1) {
+				defs = append(defs, def{kind: "route", name: m[1]})
+			}
+			for _, d := range defs {
+				if line.Type == "add" {
+					d.line = line.NewLineNo
+					added = append(added, d)
+				} else {
+					removed = append(removed, d)
+				}
+			}
+		}
+	}
+
+	find := func(defs []def, kind, name string) (def, bool) {
+		for _, d := range defs {
+			if d.kind == kind && d.name == name {
+				return d, true
+			}
+		}
+		return def{}, false
+	}
+	var changes []interfaceChange
+	for _, a := range added {
+		r, ok := find(removed, a.kind, a.name)
+		switch {
+		case !ok:
+			changes = append(changes, interfaceChange{kind: a.kind, name: a.name, line: a.line, verb: "added"})
+		case a.text != r.text:
+			changes = append(changes, interfaceChange{kind: a.kind, name: a.name, line: a.line, verb: "changed"})
+		}
+	}
+	for _, r := range removed {
+		if _, ok := find(added, r.kind, r.name); !ok {
+			changes = append(changes, interfaceChange{kind: r.kind, name: r.name, verb: "removed"})
+		}
+	}
+	return changes
+}
+
+func capitalize(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
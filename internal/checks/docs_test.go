@@ -0,0 +1,100 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+const docsPolicy = fence + "\n" +
+	"docs: README.md, docs/**\n" +
+	"exclude: internal/**\n" +
+	"```\n"
+
+func TestParse_DocsPolicy(t *testing.T) {
+	set, err := Parse(docsPolicy)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	d := set.Docs
+	if d == nil {
+		t.Fatal("Docs = nil")
+	}
+	if strings.Join(d.Docs, ",") != "README.md,docs/**" || strings.Join(d.Exclude, ",") != "internal/**" || d.Severity != "suggestion" {
+		t.Errorf("Docs = %+v", d)
+	}
+	if set.Empty() {
+		t.Error("a set with only a docs policy should not be empty")
+	}
+
+	for record, want := range map[string]string{
+		"docs: ,":                         "at least one path",
+		"docs: README.md\nid: a":          "id is not allowed",
+		"docs: README.md\nseverity: high": "severity",
+		"docs: README.md\n\ndocs: x.md":   "only be declared once",
+	} {
+		if _, err := Parse(fence + "\n" + record + "\n```\n"); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want it to mention %q", record, err, want)
+		}
+	}
+}
+
+func TestSet_DocDrift(t *testing.T) {
+	set, err := Parse(docsPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := ghclient.PRFile{Filename: "serve.go", Status: "modified", Patch: "@@ -10,6 +10,7 @@\n" +
+		"-func Serve(addr string) error {\n" +
+		"+func Serve(addr string, opts Options) error {\n" +
+		" \tr := gin.New()\n" +
+		"-\tr.GET(\"/api/jobs\", listJobs)\n" +
+		"+\tr.GET(\"/api/v2/jobs\", listJobs)\n" +
+		"-\tfs.BoolVar(&c.Verbose, \"verbose\", false, \"Log more\")\n" +
+		"+\tfs.BoolVar(&c.Verbose, \"verbose\", false, \"Log every request\")\n" +
+		"+\tfs.Duration(\"timeout\", time.Minute, \"Request timeout\")\n" +
+		"+func helper() {}\n"}
+	moved := ghclient.PRFile{Filename: "api.go", Status: "modified", Patch: "@@ -1,3 +1,3 @@\n" +
+		"-func (c *Client) Get(ctx context.Context) error {\n" +
+		"+func (c *Client) Get(ctx context.Context) error {\n"}
+	internal := ghclient.PRFile{Filename: "internal/store/store.go", Status: "modified", Patch: "@@ -1,1 +1,1 @@\n+func Open() {}\n"}
+	test := ghclient.PRFile{Filename: "serve_test.go", Status: "modified", Patch: "@@ -1,1 +1,1 @@\n+func TestServe(t *testing.T) {\n"}
+	docs := ghclient.PRFile{Filename: "docs/api.md", Status: "modified", Patch: "@@ -1,1 +1,1 @@\n+## Jobs\n"}
+
+	files := []ghclient.PRFile{server, moved, internal, test}
+	findings := set.DocDrift(files, files)
+	want := []string{
+		"Exported function `Serve` was changed",
+		"Route `/api/v2/jobs` was added",
+		"Flag `timeout` was added",
+		"Route `/api/jobs` was removed",
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("DocDrift() = %+v, want %d findings", findings, len(want))
+	}
+	for i, f := range findings {
+		if !strings.HasPrefix(f.Message, want[i]) {
+			t.Errorf("finding %d = %q, want it to start with %q", i, f.Message, want[i])
+		}
+		if f.Path != "serve.go" || f.Rule != RuleDocDrift || f.Severity != "suggestion" {
+			t.Errorf("finding = %+v", f)
+		}
+	}
+	if findings[0].Line != 10 || findings[3].Line != 0 {
+		t.Errorf("lines = %d and %d, want 10 and 0 for a removal", findings[0].Line, findings[3].Line)
+	}
+	if !strings.Contains(findings[0].Message, "(README.md, docs/**)") {
+		t.Errorf("message = %q, want the docs paths", findings[0].Message)
+	}
+
+	// A docs update anywhere in the pull request, even in a file reviewed
+	// earlier, clears the drift
+	if findings := set.DocDrift(files, append(files, docs)); len(findings) != 0 {
+		t.Errorf("DocDrift() with a docs change = %+v, want none", findings)
+	}
+	if findings := (&Set{}).DocDrift(files, files); len(findings) != 0 {
+		t.Errorf("DocDrift() without a policy = %+v, want none", findings)
+	}
+}
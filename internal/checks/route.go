@@ -0,0 +1,101 @@
+package checks
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Route hands the review of one directory of a monorepo to the team owning
+// it. It is declared in a prmate-checks record of its own:
+//
+//	route: services/payments
+//	rules: services/payments/PRMATE.md
+//	gate: warning
+//	notify: payments
+//	model: gpt-4o
+//
+// Files under the directory are reviewed with the rules and checklist of
+// the rules file, written like .prmate.md, instead of those of .prmate.md.
+// gate is the lowest severity that makes the review request changes, error
+// by default or none to never request them. notify names a notification
+// channel configured on the server that hears about reviews changing the
+// directory, and model the LLM model its files are analyzed with. When
+// routes are nested, a file follows the longest prefix it is under.
+type Route struct {
+	Prefix string
+	Rules  string // path of the rules file; empty keeps those of .prmate.md
+	Gate   string // "error", "warning", "suggestion" or "none"
+	Notify string // notification channel; empty notifies no one extra
+	Model  string // LLM model; empty keeps the configured one
+}
+
+// routeKeys are the keys of a route record
+var routeKeys = map[string]bool{"route": true, "rules": true, "gate": true, "notify": true, "model": true}
+
+// channelName matches the names of notification channels
+var channelName = regexp.MustCompile(`^[a-zA-Z0-9][\w.-]*$`)
+
+var gateRank = map[string]int{"none": 4, "error": 3, "warning": 2, "suggestion": 1}
+
+// addRoute adds a route from a record
+func (s *Set) addRoute(record map[string]string) error {
+	for key := range record {
+		if !routeKeys[key] {
+			return fmt.Errorf("%s is not allowed in a route record", key)
+		}
+	}
+	prefix := path.Clean(strings.Trim(strings.TrimPrefix(record["route"], "./"), "/"))
+	if prefix == "." || strings.HasPrefix(prefix, "..") || strings.ContainsAny(prefix, "*?[") {
+		return fmt.Errorf("route %q must be a directory of the repository", record["route"])
+	}
+	for _, r := range s.Routes {
+		if r.Prefix == prefix {
+			return fmt.Errorf("route %s is declared twice", prefix)
+		}
+	}
+
+	r := Route{Prefix: prefix, Rules: record["rules"], Gate: "error", Notify: record["notify"], Model: record["model"]}
+	if v, ok := record["gate"]; ok {
+		if _, known := gateRank[v]; !known {
+			return fmt.Errorf("route %s: gate %q must be error, warning, suggestion or none", prefix, v)
+		}
+		r.Gate = v
+	}
+	if r.Notify != "" && !channelName.MatchString(r.Notify) {
+		return fmt.Errorf("route %s: notify %q is not a channel name", prefix, r.Notify)
+	}
+	if strings.ContainsAny(r.Model, " \t") {
+		return fmt.Errorf("route %s: model %q is not a model name", prefix, r.Model)
+	}
+	s.Routes = append(s.Routes, r)
+	return nil
+}
+
+// RouteFor returns the route whose directory holds file, the innermost one
+// when routes are nested, or nil when no route covers it
+func (s *Set) RouteFor(file string) *Route {
+	if s == nil {
+		return nil
+	}
+	var best *Route
+	for i, r := range s.Routes {
+		if strings.HasPrefix(file, r.Prefix+"/") && (best == nil || len(r.Prefix) > len(best.Prefix)) {
+			best = &s.Routes[i]
+		}
+	}
+	return best
+}
+
+// Blocks reports whether a finding of severity in file makes the review
+// request changes: at or above the gate of its route, or an error outside
+// any route
+func (s *Set) Blocks(file, severity string) bool {
+	gate := "error"
+	if r := s.RouteFor(file); r != nil {
+		gate = r.Gate
+	}
+	rank, ok := gateRank[severity]
+	return ok && severity != "none" && rank >= gateRank[gate]
+}
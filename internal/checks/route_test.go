@@ -0,0 +1,76 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse_Route(t *testing.T) {
+	set, err := Parse(fence + "\nroute: ./services/payments/\nrules: services/payments/PRMATE.md\ngate: warning\nnotify: payments\nmodel: gpt-4o\n\nroute: services/payments/ledger\ngate: none\n```\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(set.Routes) != 2 {
+		t.Fatalf("Routes = %+v, want 2", set.Routes)
+	}
+	want := Route{Prefix: "services/payments", Rules: "services/payments/PRMATE.md", Gate: "warning", Notify: "payments", Model: "gpt-4o"}
+	if set.Routes[0] != want {
+		t.Errorf("Routes[0] = %+v, want %+v", set.Routes[0], want)
+	}
+	if set.Routes[1].Gate != "none" {
+		t.Errorf("Routes[1].Gate = %q, want none", set.Routes[1].Gate)
+	}
+
+	for record, want := range map[string]string{
+		"route: /":                      "must be a directory",
+		"route: ../other":               "must be a directory",
+		"route: services/*":             "must be a directory",
+		"route: web\ngate: fatal":       "gate",
+		"route: web\nnotify: #web team": "not a channel name",
+		"route: web\nseverity: error":   "severity is not allowed",
+		"route: web\n\nroute: web/":     "declared twice",
+		"route: web\nmodel: gpt 4":      "not a model name",
+	} {
+		if _, err := Parse(fence + "\n" + record + "\n```\n"); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want it to mention %q", record, err, want)
+		}
+	}
+}
+
+func TestSet_RouteFor(t *testing.T) {
+	set, err := Parse(fence + "\nroute: services/payments\ngate: warning\n\nroute: services/payments/ledger\ngate: none\n```\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path       string
+		wantPrefix string
+		severity   string
+		wantBlocks bool
+	}{
+		{path: "services/payments/charge.go", wantPrefix: "services/payments", severity: "warning", wantBlocks: true},
+		{path: "services/payments/charge.go", wantPrefix: "services/payments", severity: "suggestion"},
+		{path: "services/payments/ledger/book.go", wantPrefix: "services/payments/ledger", severity: "error"},
+		{path: "services/payments-v2/charge.go", severity: "warning"},
+		{path: "services/payments-v2/charge.go", severity: "error", wantBlocks: true},
+		{path: "services/payments", severity: "error", wantBlocks: true},
+	}
+	for _, tt := range tests {
+		prefix := ""
+		if r := set.RouteFor(tt.path); r != nil {
+			prefix = r.Prefix
+		}
+		if prefix != tt.wantPrefix {
+			t.Errorf("RouteFor(%s) = %q, want %q", tt.path, prefix, tt.wantPrefix)
+		}
+		if got := set.Blocks(tt.path, tt.severity); got != tt.wantBlocks {
+			t.Errorf("Blocks(%s, %s) = %v, want %v", tt.path, tt.severity, got, tt.wantBlocks)
+		}
+	}
+
+	var none *Set
+	if none.RouteFor("a.go") != nil || !none.Blocks("a.go", "error") || none.Blocks("a.go", "warning") {
+		t.Error("a nil set should route nothing and block on errors only")
+	}
+}
@@ -0,0 +1,56 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Suppression stops findings of some rules from being reported, in every
+// file or in the files matching Paths. It is declared in a prmate-checks
+// record of its own:
+//
+//	suppress: no-println, Error Handling
+//	paths: legacy/**, tools/**
+//
+// Rules are check IDs, rule IDs or the rule names shown in findings,
+// compared without regard to case.
+type Suppression struct {
+	Rules []string
+	Paths []string // globs; empty suppresses the rules everywhere
+}
+
+// suppressKeys are the keys of a suppression record
+var suppressKeys = map[string]bool{"suppress": true, "paths": true}
+
+// addSuppression reads a suppression record
+func (s *Set) addSuppression(record map[string]string) error {
+	for key := range record {
+		if !suppressKeys[key] {
+			return fmt.Errorf("%s is not allowed in a suppress record", key)
+		}
+	}
+	x := Suppression{Rules: splitList(record["suppress"]), Paths: splitList(record["paths"])}
+	if len(x.Rules) == 0 {
+		return fmt.Errorf("suppress must list at least one rule")
+	}
+	s.Suppress = append(s.Suppress, x)
+	return nil
+}
+
+// Suppressed reports whether findings of rule in path are suppressed
+func (s *Set) Suppressed(path, rule string) bool {
+	if s == nil {
+		return false
+	}
+	for _, x := range s.Suppress {
+		if len(x.Paths) > 0 && !matchAny(x.Paths, path) {
+			continue
+		}
+		for _, r := range x.Rules {
+			if strings.EqualFold(r, rule) {
+				return true
+			}
+		}
+	}
+	return false
+}
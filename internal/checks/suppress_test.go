@@ -0,0 +1,44 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse_Suppress(t *testing.T) {
+	set, err := Parse("```prmate-checks\nsuppress: no-println, Error Handling\npaths: legacy/**\n\nsuppress: small-files\n```\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tests := []struct {
+		path, rule string
+		want       bool
+	}{
+		{"legacy/old.go", "no-println", true},
+		{"legacy/old.go", "error handling", true},
+		{"cmd/main.go", "no-println", false},
+		{"cmd/main.go", "small-files", true},
+		{"legacy/old.go", "naming", false},
+	}
+	for _, tt := range tests {
+		if got := set.Suppressed(tt.path, tt.rule); got != tt.want {
+			t.Errorf("Suppressed(%q, %q) = %v, want %v", tt.path, tt.rule, got, tt.want)
+		}
+	}
+	var none *Set
+	if none.Suppressed("a.go", "no-println") {
+		t.Error("nil set suppresses findings")
+	}
+}
+
+func TestParse_SuppressErrors(t *testing.T) {
+	for content, want := range map[string]string{
+		"suppress: no-println\nseverity: error": "severity is not allowed in a suppress record",
+		"suppress:\npaths: legacy/**":           "at least one rule",
+	} {
+		_, err := Parse("```prmate-checks\n" + content + "\n```\n")
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want %q", content, err, want)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window limits when PRMate posts automatic reviews on a repository. It is
+// declared in a prmate-checks record of its own:
+//
+//	post-hours: Mon-Fri 09:00-17:00
+//	timezone: Europe/Stockholm
+//	freeze: 2026-12-19..2027-01-06, 2027-03-01
+//
+// post-hours lists the days, as single days or ranges, and the hours of
+// each day posting is allowed; either may be left out to allow every day or
+// the whole day. freeze lists dates or inclusive date ranges when nothing
+// is posted at all. Times and dates are in timezone, UTC by default.
+type Window struct {
+	Days     [7]bool // indexed by time.Weekday
+	Start    int     // minutes after midnight posting opens
+	End      int     // minutes after midnight posting closes
+	Location *time.Location
+	Freezes  []Freeze
+}
+
+// Freeze is an inclusive range of dates when nothing is posted
+type Freeze struct {
+	From, To time.Time // midnight of the first and last day
+}
+
+// windowKeys are the keys of a posting window record
+var windowKeys = map[string]bool{"post-hours": true, "timezone": true, "freeze": true}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// isWindowRecord reports whether record declares the posting window
+func isWindowRecord(record map[string]string) bool {
+	_, hours := record["post-hours"]
+	_, freeze := record["freeze"]
+	return hours || freeze
+}
+
+// addWindow sets the posting window from a record
+func (s *Set) addWindow(record map[string]string) error {
+	for key := range record {
+		if !windowKeys[key] {
+			return fmt.Errorf("%s is not allowed in a posting window record", key)
+		}
+	}
+	if s.Window != nil {
+		return fmt.Errorf("the posting window may only be declared once")
+	}
+
+	w := &Window{End: 24 * 60, Location: time.UTC}
+	if tz, ok := record["timezone"]; ok {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return fmt.Errorf("timezone %q: %w", tz, err)
+		}
+		w.Location = loc
+	}
+	if err := w.parseHours(record["post-hours"]); err != nil {
+		return fmt.Errorf("post-hours: %w", err)
+	}
+	for _, f := range splitList(record["freeze"]) {
+		freeze, err := parseFreeze(f, w.Location)
+		if err != nil {
+			return fmt.Errorf("freeze: %w", err)
+		}
+		w.Freezes = append(w.Freezes, freeze)
+	}
+	s.Window = w
+	return nil
+}
+
+// parseHours reads "Mon-Fri 09:00-17:00", "Mon,Wed" or "08:00-18:00"
+func (w *Window) parseHours(v string) error {
+	days, hours := "", ""
+	for _, field := range strings.Fields(v) {
+		if strings.Contains(field, ":") {
+			hours = field
+		} else {
+			days += field
+		}
+	}
+
+	if days == "" {
+		w.Days = [7]bool{true, true, true, true, true, true, true}
+	}
+	for _, part := range splitList(days) {
+		from, to, isRange := strings.Cut(strings.ToLower(part), "-")
+		first, ok := weekdays[from]
+		last, ok2 := weekdays[to]
+		if !isRange {
+			last, ok2 = first, ok
+		}
+		if !ok || !ok2 {
+			return fmt.Errorf("%q is not a day such as Mon or a range such as Mon-Fri", part)
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			w.Days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+
+	if hours == "" {
+		return nil
+	}
+	from, to, ok := strings.Cut(hours, "-")
+	start, err1 := parseClock(from)
+	end, err2 := parseClock(to)
+	if !ok || err1 != nil || err2 != nil {
+		return fmt.Errorf("%q is not a time range such as 09:00-17:00", hours)
+	}
+	if end <= start {
+		return fmt.Errorf("%q must end after it starts", hours)
+	}
+	w.Start, w.End = start, end
+	return nil
+}
+
+// parseClock returns the minutes after midnight of "15:04", or of "24:00"
+func parseClock(v string) (int, error) {
+	if v == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseFreeze reads "2026-12-19..2027-01-06" or a single date
+func parseFreeze(v string, loc *time.Location) (Freeze, error) {
+	from, to, isRange := strings.Cut(v, "..")
+	if !isRange {
+		to = from
+	}
+	first, err1 := time.ParseInLocation(time.DateOnly, strings.TrimSpace(from), loc)
+	last, err2 := time.ParseInLocation(time.DateOnly, strings.TrimSpace(to), loc)
+	if err1 != nil || err2 != nil {
+		return Freeze{}, fmt.Errorf("%q is not a date such as 2026-12-24 or a range such as 2026-12-19..2027-01-06", v)
+	}
+	if last.Before(first) {
+		return Freeze{}, fmt.Errorf("%q ends before it starts", v)
+	}
+	return Freeze{From: first, To: last}, nil
+}
+
+// Open reports whether PRMate may post at t. A nil window is always open.
+func (w *Window) Open(t time.Time) bool {
+	return w.NextOpen(t).Equal(t)
+}
+
+// NextOpen returns the first moment at or after t when PRMate may post
+func (w *Window) NextOpen(t time.Time) time.Time {
+	if w == nil {
+		return t
+	}
+	local := t.In(w.Location)
+	// Every step moves on to a later day or skips a whole freeze, which
+	// bounds the search to about two years
+	for range 2*366 + len(w.Freezes) {
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, w.Location)
+		next := day.AddDate(0, 0, 1)
+		if f, ok := w.frozen(day); ok {
+			local = f.To.AddDate(0, 0, 1)
+			continue
+		}
+		if !w.Days[day.Weekday()] {
+			local = next
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), w.Start/60, w.Start%60, 0, 0, w.Location)
+		end := time.Date(day.Year(), day.Month(), day.Day(), w.End/60, w.End%60, 0, 0, w.Location)
+		switch {
+		case local.Before(start):
+			return start.In(t.Location())
+		case !local.Before(end):
+			local = next
+		default:
+			return local.In(t.Location())
+		}
+	}
+	return local.In(t.Location())
+}
+
+// frozen returns the freeze covering day
+func (w *Window) frozen(day time.Time) (Freeze, bool) {
+	for _, f := range w.Freezes {
+		if !day.Before(f.From) && !day.After(f.To) {
+			return f, true
+		}
+	}
+	return Freeze{}, false
+}
@@ -0,0 +1,86 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse_Window(t *testing.T) {
+	set, err := Parse(fence + "\npost-hours: Mon-Fri 09:00-17:00\ntimezone: Europe/Stockholm\nfreeze: 2026-12-19..2027-01-06, 2027-03-01\n```\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	w := set.Window
+	if w == nil {
+		t.Fatal("Window = nil")
+	}
+	if w.Days != [7]bool{false, true, true, true, true, true, false} || w.Start != 9*60 || w.End != 17*60 || w.Location.String() != "Europe/Stockholm" || len(w.Freezes) != 2 {
+		t.Errorf("Window = %+v", w)
+	}
+	if !set.Empty() {
+		t.Error("a posting window is not a check")
+	}
+
+	for record, want := range map[string]string{
+		"post-hours: Mon-Fri 17:00-09:00":          "must end after it starts",
+		"post-hours: Funday":                       "not a day",
+		"post-hours: 9:00-5":                       "not a time range",
+		"post-hours: Mon\ntimezone: Mars/Olympus":  "timezone",
+		"freeze: 2027-01-06..2026-12-19":           "ends before it starts",
+		"freeze: christmas":                        "not a date",
+		"post-hours: Mon\nid: a":                   "id is not allowed",
+		"post-hours: Mon\n\nfreeze: 2026-12-24":    "only be declared once",
+		"timezone: UTC\npost-hours: Sat-Sun 24:00": "not a time range",
+	} {
+		if _, err := Parse(fence + "\n" + record + "\n```\n"); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want it to mention %q", record, err, want)
+		}
+	}
+}
+
+func TestWindow_NextOpen(t *testing.T) {
+	set, err := Parse(fence + "\npost-hours: Mon-Fri 09:00-17:00\nfreeze: 2026-12-24..2026-12-28\n```\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := set.Window
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name string
+		now  string
+		want string
+	}{
+		{name: "open", now: "2026-10-15 10:30", want: "2026-10-15 10:30"},
+		{name: "before hours", now: "2026-10-15 07:00", want: "2026-10-15 09:00"},
+		{name: "after hours", now: "2026-10-15 17:00", want: "2026-10-16 09:00"},
+		{name: "friday evening", now: "2026-10-16 18:00", want: "2026-10-19 09:00"},
+		{name: "weekend", now: "2026-10-17 12:00", want: "2026-10-19 09:00"},
+		{name: "freeze", now: "2026-12-23 18:00", want: "2026-12-29 09:00"},
+		{name: "inside freeze", now: "2026-12-24 10:00", want: "2026-12-29 09:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := at(tt.now)
+			if got := w.NextOpen(now); !got.Equal(at(tt.want)) {
+				t.Errorf("NextOpen(%s) = %s, want %s", tt.now, got.Format(time.DateTime), tt.want)
+			}
+			if w.Open(now) != (tt.now == tt.want) {
+				t.Errorf("Open(%s) = %v", tt.now, !(tt.now == tt.want))
+			}
+		})
+	}
+
+	var none *Window
+	if now := at("2026-10-17 03:00"); !none.Open(now) {
+		t.Error("a nil window should always be open")
+	}
+}
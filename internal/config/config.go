@@ -1,101 +1,516 @@
 package config
 
 import (
+	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
 // Config holds application configuration
 type Config struct {
 	Port             string
+	AdminPort        string // separate listener for admin routes when set
+	TLSCertFile      string
+	TLSKeyFile       string
+	TLSClientCAFile  string // require client certs signed by this CA (mTLS)
 	GinMode          string
+	LogLevel         string
+	LogFormat        string
 	CopilotModel     string
 	GitHubToken      string
 	WebhookSecret    string
+	AdminAPIKey      string
+	ReadOnlyAPIKeys  string // comma-separated
 	WorkBaseDir      string
+	WorkspaceTTL     time.Duration // PR workspaces unused this long are deleted; 0 disables
+	WorkspaceCleanup time.Duration // how often stale workspaces are looked for
+	DiskQuotaBytes   int           // cap on workspaces plus scan clones; 0 disables
+	ReviewStorePath  string
+	AuditLogPath     string
+	StateDBPath      string // SQLite database of per-PR review state; "none" keeps it in summary comments only
 	WebhookQueueSize int
+	DryRun           bool    // log GitHub writes instead of making them
+	DryRunRepos      string  // comma-separated owner/repo or owner/* entries
+	ShadowMode       bool    // review and record without writing to GitHub, for comparison with human reviews
+	RateLimitRPS     float64 // per client IP on /api; 0 disables
+	RateLimitBurst   int
+	WebhookRPS       float64 // per client IP on /webhook; 0 disables, as GitHub delivers from a few IPs
+	TrustedProxies   string  // comma-separated IPs or CIDRs whose X-Forwarded-For gives the client IP
+	MaxBodyBytes     int
 	WebhookWorkers   int
 	ShutdownTimeout  time.Duration
 	ReadTimeout      time.Duration
 	WriteTimeout     time.Duration
 	IdleTimeout      time.Duration
+	// Readiness probe tuning
+	ReadinessCheckTimeout time.Duration
+	ReadinessCacheTTL     time.Duration
+	LLMTimeout            time.Duration // per LLM call
+	ReviewTimeout         time.Duration // whole PR review
+	ReviewConcurrency     int           // files of one PR analyzed at once
+	ProgressInterval      time.Duration // how often a long review shows its progress; 0 never
+	RulesCacheTTL         time.Duration // how long rules loaded from .prmate.md are reused; 0 never
+	CloneTimeout          time.Duration // per git clone
+	ScanTimeout           time.Duration // whole scan including clones
+	SmokeTimeout          time.Duration // per smoke check command
+	BreakerFailures       int           // consecutive GitHub or LLM failures that open a circuit breaker; 0 disables
+	BreakerCoolDown       time.Duration // how long an open breaker fails calls at once
 	// LLM Provider configuration
-	LLMProvider    string // "copilot" or "openai" (default: copilot)
+	LLMProvider    string // "copilot", "openai" or "ollama" (default: copilot)
+	Offline        bool   // deterministic checks only; the LLM is never called
+	ModelCheck     string // "warn", "fail" or "off" when the model is not offered
+	FixCommand     bool   // let "@prmate fix" comments push fixes to PR branches
+	ExplainCommand bool   // answer "@prmate explain path:lines" comments
+	ExemptCommand  bool   // let "@prmate exempt" comments suppress rules until a date
+	MuteCommand    bool   // let PR authors mute rules or inline comments on their own PRs
+	ConflictHelp   bool   // comment on PRs with merge conflicts
+	ConflictDiffs  bool   // propose a resolution diff in conflict comments
+	Changelog      bool   // ask for a changelog entry on PRs changing user-facing code
+	SecretScan     bool   // flag credentials on added lines and redact them from LLM prompts
+	WorkflowScan   bool   // check changed GitHub Actions workflows for security problems
+	SmokeChecks    string // semicolon-separated commands run in PR workspaces; failures become findings
+	DepsReview     bool   // report dependency changes in go.mod, package.json and requirements.txt
+	Onboarding     bool   // open a PR adding .prmate.md to repositories without one
+	IssueTriage    bool   // classify newly opened issues with a comment
+	DepsRegistry   string // deps.dev-compatible API for license and maintenance data; "none" skips it
 	OpenAIAPIKey   string
 	OpenAIBaseURL  string
 	OpenAIModel    string
+	OllamaBaseURL  string
+	OllamaModel    string
+	// LLM token budgets per UTC day and month; 0 disables a budget
+	RepoBudgetDaily   int
+	RepoBudgetMonthly int
+	OrgBudgetDaily    int
+	OrgBudgetMonthly  int
+	// Degraded reviews under load: a cheaper model and less context
+	DegradeQueueDepth int           // queued webhooks and jobs; 0 disables
+	DegradeLatency    time.Duration // moving average of LLM call latency; 0 disables
+	DegradedModel     string        // model used while degraded; empty keeps the configured one
+	// Artifact storage: a directory, s3://, gs:// or azure:// URL; empty disables
+	ArtifactStoreURL    string
+	ArtifactAccessKeyID string
+	ArtifactSecretKey   string
+	ArtifactRetention   time.Duration // artifacts in a directory store older than this are deleted; 0 keeps them
+	// Error reporting
+	SentryDSN      string
+	ErrorReportURL string
+	Environment    string
+	// Review notifications and outbound events
+	TeamsWebhookURL    string
+	DiscordWebhookURL  string
+	DiscordUsername    string
+	DiscordAvatarURL   string
+	DiscordFindings    bool   // list top findings in Discord embeds
+	DiscordScans       bool   // post scan completions to Discord
+	EventWebhookURLs   string // comma-separated
+	EventWebhookSecret string // HMAC key signing event deliveries
+	EventWebhookEvents string // comma-separated event types; empty means all
+	NotifyChannelsJSON string // JSON object of channels .prmate.md routes notify
+	// Email digests of review activity (see Digest)
+	DigestsJSON  string
+	DigestHour   int // UTC hour digests, quality reports and stale PR nudges are sent at
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	// Tickets for findings left open at merge (see TicketRoute)
+	TicketRoutesJSON string
+	JiraBaseURL      string
+	JiraEmail        string
+	JiraAPIToken     string
+	LinearAPIKey     string
+	// Weekly quality report issues
+	QualityReportRepos string // comma-separated owner/repo or owner/* entries
+	// Nudges on inactive pull requests (see StalePolicy)
+	StalePRsJSON string
+	// Review history export to CSV or BigQuery
+	ExportURL             string
+	ExportInterval        time.Duration
+	ExportCredentialsFile string // Google service account key for BigQuery
+	// Comment branding; empty text settings keep PRMate's own wording
+	CommentEmoji  bool   // false posts plain text without emoji
+	SeverityEmoji string // comma-separated severity=emoji overrides
+	CommentPrefix string // prepended to every inline comment
+	ReviewHeader  string // review body; {count} is the number of findings
+	SummaryHeader string
+	CommentFooter string // {version} is the PRMate version; "none" drops it
+	ReviewPersona string // "security", "mentor" or "terse" for repositories whose .prmate.md chooses none
+	// Tracing
+	OTLPEndpoint    string
+	OTELServiceName string
+	// Additional SCM instances as a JSON array (see SCMInstance)
+	SCMInstancesJSON string
+
+	loadErrs []error // values Load could not use, reported by Validate
 }
 
-// Load loads configuration from environment variables
+// Load loads configuration from environment variables and the config file
+// (see loadFile), env taking precedence. Values it cannot use are kept as
+// load errors, reported by Validate, and their settings left at defaults.
 func Load() *Config {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+	l := newLoader()
 
-	ginMode := os.Getenv("GIN_MODE")
-	if ginMode == "" {
-		ginMode = "debug"
-	}
+	port := l.getOr("PORT", "8080")
+	ginMode := l.getOr("GIN_MODE", "debug")
+	copilotModel := l.getOr("COPILOT_MODEL", "gpt-5-mini")
 
-	copilotModel := os.Getenv("COPILOT_MODEL")
-	if copilotModel == "" {
-		copilotModel = "gpt-5-mini"
-	}
+	webhookSecret := l.get("WEBHOOK_SECRET")
+	githubToken := l.get("GITHUB_TOKEN")
+
+	workBaseDir := l.getOr("PR_WORK_BASE_DIR", "/tmp/prmate")
 
-	webhookSecret := os.Getenv("WEBHOOK_SECRET")
-	githubToken := os.Getenv("GITHUB_TOKEN")
+	webhookQueueSize := l.positiveInt("WEBHOOK_QUEUE_SIZE", 100)
+	webhookWorkers := l.positiveInt("WEBHOOK_WORKERS", 1)
 
-	workBaseDir := os.Getenv("PR_WORK_BASE_DIR")
-	if workBaseDir == "" {
-		workBaseDir = "/tmp/prmate"
+	// LLM Provider config
+	llmProvider := l.getOr("LLM_PROVIDER", "copilot")
+
+	openAIAPIKey := l.get("OPENAI_API_KEY")
+	openAIBaseURL := l.get("OPENAI_BASE_URL")
+	openAIModel := l.getOr("OPENAI_MODEL", "gpt-4")
+
+	// Local models answer far slower than hosted ones
+	llmTimeout := 2 * time.Minute
+	if llmProvider == "ollama" {
+		llmTimeout = 10 * time.Minute
 	}
 
-	webhookQueueSize := 100
-	if v := os.Getenv("WEBHOOK_QUEUE_SIZE"); v != "" {
-		if parsed, err := parsePositiveInt(v); err == nil {
-			webhookQueueSize = parsed
-		}
+	cfg := &Config{
+		Port:                  port,
+		AdminPort:             l.get("ADMIN_PORT"),
+		TLSCertFile:           l.get("TLS_CERT_FILE"),
+		TLSKeyFile:            l.get("TLS_KEY_FILE"),
+		TLSClientCAFile:       l.get("TLS_CLIENT_CA_FILE"),
+		GinMode:               ginMode,
+		LogLevel:              l.getOr("LOG_LEVEL", "info"),
+		LogFormat:             l.getOr("LOG_FORMAT", "text"),
+		CopilotModel:          copilotModel,
+		GitHubToken:           githubToken,
+		WebhookSecret:         webhookSecret,
+		AdminAPIKey:           l.get("ADMIN_API_KEY"),
+		ReadOnlyAPIKeys:       l.get("READ_ONLY_API_KEYS"),
+		WorkBaseDir:           workBaseDir,
+		WorkspaceTTL:          l.durationOrZero("WORKSPACE_TTL", 7*24*time.Hour),
+		WorkspaceCleanup:      l.duration("WORKSPACE_CLEANUP_INTERVAL", time.Hour),
+		DiskQuotaBytes:        l.int("DISK_QUOTA_BYTES", 0),
+		ReviewStorePath:       l.getOr("REVIEW_STORE_PATH", filepath.Join(workBaseDir, "reviews.jsonl")),
+		AuditLogPath:          l.getOr("AUDIT_LOG_PATH", filepath.Join(workBaseDir, "audit.jsonl")),
+		StateDBPath:           l.getOr("STATE_DB_PATH", filepath.Join(workBaseDir, "state.db")),
+		ArtifactStoreURL:      l.get("ARTIFACT_STORE_URL"),
+		ArtifactAccessKeyID:   l.get("ARTIFACT_ACCESS_KEY_ID"),
+		ArtifactSecretKey:     l.get("ARTIFACT_SECRET_ACCESS_KEY"),
+		ArtifactRetention:     l.durationOrZero("ARTIFACT_RETENTION", 30*24*time.Hour),
+		WebhookQueueSize:      webhookQueueSize,
+		DryRun:                l.bool("DRY_RUN", false),
+		DryRunRepos:           l.get("DRY_RUN_REPOS"),
+		ShadowMode:            l.bool("SHADOW_MODE", false),
+		RateLimitRPS:          l.float("RATE_LIMIT_RPS", 10),
+		RateLimitBurst:        l.int("RATE_LIMIT_BURST", 50),
+		WebhookRPS:            l.float("WEBHOOK_RATE_LIMIT_RPS", 0),
+		TrustedProxies:        l.get("TRUSTED_PROXIES"),
+		MaxBodyBytes:          l.int("MAX_BODY_BYTES", 25<<20),
+		WebhookWorkers:        webhookWorkers,
+		ShutdownTimeout:       l.duration("SHUTDOWN_TIMEOUT", 10*time.Second),
+		ReadTimeout:           l.duration("READ_TIMEOUT", 15*time.Second),
+		WriteTimeout:          l.duration("WRITE_TIMEOUT", 15*time.Second),
+		IdleTimeout:           l.duration("IDLE_TIMEOUT", 60*time.Second),
+		ReadinessCheckTimeout: l.duration("READINESS_CHECK_TIMEOUT", 5*time.Second),
+		ReadinessCacheTTL:     l.duration("READINESS_CACHE_TTL", 30*time.Second),
+		LLMTimeout:            l.duration("LLM_TIMEOUT", llmTimeout),
+		ReviewTimeout:         l.duration("REVIEW_TIMEOUT", 15*time.Minute),
+		ReviewConcurrency:     l.int("REVIEW_CONCURRENCY", 4),
+		ProgressInterval:      l.durationOrZero("REVIEW_PROGRESS_INTERVAL", time.Minute),
+		RulesCacheTTL:         l.durationOrZero("RULES_CACHE_TTL", 5*time.Minute),
+		CloneTimeout:          l.duration("CLONE_TIMEOUT", 5*time.Minute),
+		ScanTimeout:           l.duration("SCAN_TIMEOUT", 15*time.Minute),
+		SmokeTimeout:          l.duration("SMOKE_TIMEOUT", 5*time.Minute),
+		BreakerFailures:       l.int("BREAKER_FAILURES", 5),
+		BreakerCoolDown:       l.duration("BREAKER_COOLDOWN", 30*time.Second),
+		LLMProvider:           llmProvider,
+		Offline:               l.bool("OFFLINE", false),
+		ModelCheck:            l.getOr("MODEL_CHECK", "warn"),
+		FixCommand:            l.bool("FIX_COMMAND", false),
+		ExplainCommand:        l.bool("EXPLAIN_COMMAND", false),
+		ExemptCommand:         l.bool("EXEMPT_COMMAND", false),
+		MuteCommand:           l.bool("MUTE_COMMAND", true),
+		ConflictHelp:          l.bool("CONFLICT_HELP", false),
+		ConflictDiffs:         l.bool("CONFLICT_DIFFS", true),
+		Changelog:             l.bool("CHANGELOG_CHECK", false),
+		SecretScan:            l.bool("SECRET_SCAN", true),
+		WorkflowScan:          l.bool("WORKFLOW_SCAN", true),
+		SmokeChecks:           l.get("SMOKE_CHECKS"),
+		DepsReview:            l.bool("DEPENDENCY_REVIEW", false),
+		Onboarding:            l.bool("ONBOARDING", true),
+		IssueTriage:           l.bool("ISSUE_TRIAGE", false),
+		DepsRegistry:          l.getOr("DEPENDENCY_REGISTRY", "https://api.deps.dev"),
+		RepoBudgetDaily:       l.int("REPO_TOKEN_BUDGET_DAILY", 0),
+		RepoBudgetMonthly:     l.int("REPO_TOKEN_BUDGET_MONTHLY", 0),
+		OrgBudgetDaily:        l.int("ORG_TOKEN_BUDGET_DAILY", 0),
+		OrgBudgetMonthly:      l.int("ORG_TOKEN_BUDGET_MONTHLY", 0),
+		DegradeQueueDepth:     l.int("DEGRADE_QUEUE_DEPTH", 0),
+		DegradeLatency:        l.durationOrZero("DEGRADE_LLM_LATENCY", 0),
+		DegradedModel:         l.get("DEGRADED_MODEL"),
+		OpenAIAPIKey:          openAIAPIKey,
+		OpenAIBaseURL:         openAIBaseURL,
+		OpenAIModel:           openAIModel,
+		OllamaBaseURL:         l.getOr("OLLAMA_BASE_URL", "http://localhost:11434"),
+		OllamaModel:           l.get("OLLAMA_MODEL"),
+		SCMInstancesJSON:      l.get("SCM_INSTANCES"),
+		SentryDSN:             l.get("SENTRY_DSN"),
+		ErrorReportURL:        l.get("ERROR_REPORT_URL"),
+		Environment:           l.getOr("ENVIRONMENT", "production"),
+		TeamsWebhookURL:       l.get("TEAMS_WEBHOOK_URL"),
+		DiscordWebhookURL:     l.get("DISCORD_WEBHOOK_URL"),
+		DiscordUsername:       l.get("DISCORD_USERNAME"),
+		DiscordAvatarURL:      l.get("DISCORD_AVATAR_URL"),
+		DiscordFindings:       l.bool("DISCORD_FINDINGS", true),
+		DiscordScans:          l.bool("DISCORD_SCANS", true),
+		EventWebhookURLs:      l.get("EVENT_WEBHOOK_URLS"),
+		EventWebhookSecret:    l.get("EVENT_WEBHOOK_SECRET"),
+		EventWebhookEvents:    l.get("EVENT_WEBHOOK_EVENTS"),
+		NotifyChannelsJSON:    l.get("NOTIFY_CHANNELS"),
+		DigestsJSON:           l.get("DIGESTS"),
+		DigestHour:            l.int("DIGEST_HOUR", 8),
+		SMTPAddr:              l.get("SMTP_ADDR"),
+		SMTPUsername:          l.get("SMTP_USERNAME"),
+		SMTPPassword:          l.get("SMTP_PASSWORD"),
+		SMTPFrom:              l.get("SMTP_FROM"),
+		TicketRoutesJSON:      l.get("TICKET_ROUTES"),
+		JiraBaseURL:           l.get("JIRA_BASE_URL"),
+		JiraEmail:             l.get("JIRA_EMAIL"),
+		JiraAPIToken:          l.get("JIRA_API_TOKEN"),
+		LinearAPIKey:          l.get("LINEAR_API_KEY"),
+		QualityReportRepos:    l.get("QUALITY_REPORT_REPOS"),
+		StalePRsJSON:          l.get("STALE_PRS"),
+		ExportURL:             l.get("EXPORT_URL"),
+		ExportInterval:        l.duration("EXPORT_INTERVAL", time.Hour),
+		ExportCredentialsFile: l.get("EXPORT_GOOGLE_CREDENTIALS"),
+		CommentEmoji:          l.bool("COMMENT_EMOJI", true),
+		SeverityEmoji:         l.get("SEVERITY_EMOJI"),
+		CommentPrefix:         l.get("COMMENT_PREFIX"),
+		ReviewHeader:          l.get("REVIEW_HEADER"),
+		SummaryHeader:         l.get("SUMMARY_HEADER"),
+		CommentFooter:         l.get("COMMENT_FOOTER"),
+		ReviewPersona:         l.get("REVIEW_PERSONA"),
+		OTLPEndpoint:          l.get("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		OTELServiceName:       l.getOr("OTEL_SERVICE_NAME", "prmate"),
 	}
+	cfg.loadErrs = l.finish()
+	return cfg
+}
+
+// RegisterFlags binds every configuration field to a flag on fs, using the
+// current field values (normally loaded from env) as defaults.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Port, "port", c.Port, envUsage("HTTP server port", "PORT"))
+	fs.StringVar(&c.AdminPort, "admin-port", c.AdminPort, envUsage("Serve admin, dashboard and debug routes on this port instead of the public one", "ADMIN_PORT"))
+	fs.StringVar(&c.TLSCertFile, "tls-cert-file", c.TLSCertFile, envUsage("PEM certificate for HTTPS; plain HTTP when empty", "TLS_CERT_FILE"))
+	fs.StringVar(&c.TLSKeyFile, "tls-key-file", c.TLSKeyFile, envUsage("PEM private key for HTTPS", "TLS_KEY_FILE"))
+	fs.StringVar(&c.TLSClientCAFile, "tls-client-ca-file", c.TLSClientCAFile, envUsage("PEM CA bundle; when set, clients of the admin listener must present a certificate it signed", "TLS_CLIENT_CA_FILE"))
+	fs.StringVar(&c.GinMode, "gin-mode", c.GinMode, envUsage("Gin mode: debug, release or test", "GIN_MODE"))
+	fs.StringVar(&c.LogLevel, "log-level", c.LogLevel, envUsage("Log level: debug, info, warn or error", "LOG_LEVEL"))
+	fs.StringVar(&c.LogFormat, "log-format", c.LogFormat, envUsage("Log format: text or json", "LOG_FORMAT"))
+	fs.StringVar(&c.GitHubToken, "github-token", c.GitHubToken, envUsage("GitHub token with repo access", "GITHUB_TOKEN"))
+	fs.StringVar(&c.WebhookSecret, "webhook-secret", c.WebhookSecret, envUsage("GitHub webhook secret for signature validation", "WEBHOOK_SECRET"))
+	fs.StringVar(&c.AdminAPIKey, "admin-api-key", c.AdminAPIKey, envUsage("API key for admin endpoints; admin API is disabled when empty", "ADMIN_API_KEY"))
+	fs.StringVar(&c.ReadOnlyAPIKeys, "read-only-api-keys", c.ReadOnlyAPIKeys, envUsage("Comma-separated API keys with read-only access to the dashboard and job APIs", "READ_ONLY_API_KEYS"))
+	fs.StringVar(&c.WorkBaseDir, "work-base-dir", c.WorkBaseDir, envUsage("Working directory for PR processing", "PR_WORK_BASE_DIR"))
+	fs.DurationVar(&c.WorkspaceTTL, "workspace-ttl", c.WorkspaceTTL, envUsage("Delete PR workspaces unused for this long; 0 disables", "WORKSPACE_TTL"))
+	fs.DurationVar(&c.WorkspaceCleanup, "workspace-cleanup-interval", c.WorkspaceCleanup, envUsage("How often to look for stale PR workspaces", "WORKSPACE_CLEANUP_INTERVAL"))
+	fs.IntVar(&c.DiskQuotaBytes, "disk-quota-bytes", c.DiskQuotaBytes, envUsage("Disk quota for PR workspaces and scan clones; old workspaces are evicted first, 0 disables", "DISK_QUOTA_BYTES"))
+	fs.StringVar(&c.ReviewStorePath, "review-store-path", c.ReviewStorePath, envUsage("JSON Lines file recording completed reviews for the dashboard", "REVIEW_STORE_PATH"))
+	fs.StringVar(&c.AuditLogPath, "audit-log-path", c.AuditLogPath, envUsage("JSON Lines file recording every write PRMate makes to GitHub", "AUDIT_LOG_PATH"))
+	fs.StringVar(&c.StateDBPath, "state-db-path", c.StateDBPath, envUsage(`SQLite database keeping the review state of each PR, or "none"`, "STATE_DB_PATH"))
+	fs.StringVar(&c.ArtifactStoreURL, "artifact-store-url", c.ArtifactStoreURL, envUsage("Where generated .prmate.md files and review results are kept: a directory, s3://bucket/prefix?region=, gs://bucket/prefix or azure://account.blob.core.windows.net/container?<sas>; empty keeps none", "ARTIFACT_STORE_URL"))
+	fs.DurationVar(&c.ArtifactRetention, "artifact-retention", c.ArtifactRetention, envUsage("Delete artifacts in a directory store older than this; 0 keeps them", "ARTIFACT_RETENTION"))
+	fs.StringVar(&c.ArtifactAccessKeyID, "artifact-access-key-id", c.ArtifactAccessKeyID, envUsage("Access key for s3:// and gs:// (HMAC) artifact stores", "ARTIFACT_ACCESS_KEY_ID"))
+	fs.StringVar(&c.ArtifactSecretKey, "artifact-secret-access-key", c.ArtifactSecretKey, envUsage("Secret key for s3:// and gs:// (HMAC) artifact stores", "ARTIFACT_SECRET_ACCESS_KEY"))
+	fs.BoolVar(&c.DryRun, "dry-run", c.DryRun, envUsage("Log comments, reviews and pushes instead of writing them to GitHub", "DRY_RUN"))
+	fs.StringVar(&c.DryRunRepos, "dry-run-repos", c.DryRunRepos, envUsage("Comma-separated owner/repo or owner/* entries to run in dry-run mode", "DRY_RUN_REPOS"))
+	fs.BoolVar(&c.ShadowMode, "shadow-mode", c.ShadowMode, envUsage("Review pull requests and record the findings without writing anything to GitHub", "SHADOW_MODE"))
+	fs.IntVar(&c.WebhookQueueSize, "webhook-queue-size", c.WebhookQueueSize, envUsage("Async webhook queue size", "WEBHOOK_QUEUE_SIZE"))
+	fs.Float64Var(&c.RateLimitRPS, "rate-limit-rps", c.RateLimitRPS, envUsage("Requests per second allowed per client IP on /api; 0 disables", "RATE_LIMIT_RPS"))
+	fs.IntVar(&c.RateLimitBurst, "rate-limit-burst", c.RateLimitBurst, envUsage("Burst size for the per-IP rate limits", "RATE_LIMIT_BURST"))
+	fs.Float64Var(&c.WebhookRPS, "webhook-rate-limit-rps", c.WebhookRPS, envUsage("Requests per second allowed per client IP on /webhook; 0 disables", "WEBHOOK_RATE_LIMIT_RPS"))
+	fs.StringVar(&c.TrustedProxies, "trusted-proxies", c.TrustedProxies, envUsage("Comma-separated proxy IPs or CIDRs whose X-Forwarded-For header gives the client IP; none by default", "TRUSTED_PROXIES"))
+	fs.IntVar(&c.MaxBodyBytes, "max-body-bytes", c.MaxBodyBytes, envUsage("Maximum request body size for /webhook and /api", "MAX_BODY_BYTES"))
+	fs.IntVar(&c.WebhookWorkers, "webhook-workers", c.WebhookWorkers, envUsage("Number of webhook processing workers", "WEBHOOK_WORKERS"))
+	fs.DurationVar(&c.ShutdownTimeout, "shutdown-timeout", c.ShutdownTimeout, envUsage("Graceful shutdown timeout", "SHUTDOWN_TIMEOUT"))
+	fs.DurationVar(&c.ReadTimeout, "read-timeout", c.ReadTimeout, envUsage("HTTP server read timeout", "READ_TIMEOUT"))
+	fs.DurationVar(&c.WriteTimeout, "write-timeout", c.WriteTimeout, envUsage("HTTP server write timeout", "WRITE_TIMEOUT"))
+	fs.DurationVar(&c.IdleTimeout, "idle-timeout", c.IdleTimeout, envUsage("HTTP server idle timeout", "IDLE_TIMEOUT"))
+	fs.DurationVar(&c.ReadinessCheckTimeout, "readiness-check-timeout", c.ReadinessCheckTimeout, envUsage("Timeout for each /readyz dependency check", "READINESS_CHECK_TIMEOUT"))
+	fs.DurationVar(&c.ReadinessCacheTTL, "readiness-cache-ttl", c.ReadinessCacheTTL, envUsage("How long /readyz results are cached", "READINESS_CACHE_TTL"))
+	fs.DurationVar(&c.LLMTimeout, "llm-timeout", c.LLMTimeout, envUsage("Timeout for a single LLM call", "LLM_TIMEOUT"))
+	fs.DurationVar(&c.ReviewTimeout, "review-timeout", c.ReviewTimeout, envUsage("Deadline for a complete PR review", "REVIEW_TIMEOUT"))
+	fs.IntVar(&c.ReviewConcurrency, "review-concurrency", c.ReviewConcurrency, envUsage("Number of files of a PR analyzed at once", "REVIEW_CONCURRENCY"))
+	fs.DurationVar(&c.ProgressInterval, "review-progress-interval", c.ProgressInterval, envUsage("How often a long review shows its progress on the summary comment (0 never)", "REVIEW_PROGRESS_INTERVAL"))
+	fs.DurationVar(&c.RulesCacheTTL, "rules-cache-ttl", c.RulesCacheTTL, envUsage("How long rules loaded from .prmate.md are reused (0 never)", "RULES_CACHE_TTL"))
+	fs.DurationVar(&c.CloneTimeout, "clone-timeout", c.CloneTimeout, envUsage("Timeout for a single git clone", "CLONE_TIMEOUT"))
+	fs.DurationVar(&c.ScanTimeout, "scan-timeout", c.ScanTimeout, envUsage("Deadline for a complete codebase scan", "SCAN_TIMEOUT"))
+	fs.DurationVar(&c.SmokeTimeout, "smoke-timeout", c.SmokeTimeout, envUsage("Timeout for each smoke check command", "SMOKE_TIMEOUT"))
+	fs.IntVar(&c.BreakerFailures, "breaker-failures", c.BreakerFailures, envUsage("Consecutive failed GitHub or LLM calls after which calls fail at once for BREAKER_COOLDOWN; 0 disables the circuit breakers", "BREAKER_FAILURES"))
+	fs.DurationVar(&c.BreakerCoolDown, "breaker-cooldown", c.BreakerCoolDown, envUsage("How long an open circuit breaker fails calls before letting a probe call through", "BREAKER_COOLDOWN"))
+	fs.StringVar(&c.LLMProvider, "llm-provider", c.LLMProvider, envUsage("LLM provider: copilot, openai or ollama", "LLM_PROVIDER"))
+	fs.BoolVar(&c.Offline, "offline", c.Offline, envUsage("Run only the deterministic checks from .prmate.md; the LLM is disabled", "OFFLINE"))
+	fs.StringVar(&c.ModelCheck, "model-check", c.ModelCheck, envUsage("At startup, warn, fail or do nothing (off) when the configured model is not offered by the provider", "MODEL_CHECK"))
+	fs.BoolVar(&c.FixCommand, "fix-command", c.FixCommand, envUsage("Let collaborators with write access comment \"@prmate fix [file]\" to have PRMate push a commit fixing its findings", "FIX_COMMAND"))
+	fs.BoolVar(&c.ExplainCommand, "explain-command", c.ExplainCommand, envUsage("Let PR authors and collaborators with write access comment \"@prmate explain path:start-end\" to have PRMate explain those lines", "EXPLAIN_COMMAND"))
+	fs.BoolVar(&c.ExemptCommand, "exempt-command", c.ExemptCommand, envUsage("Let collaborators with write access comment \"@prmate exempt <rule> reason=... until=YYYY-MM-DD\" to suppress a rule until a date", "EXEMPT_COMMAND"))
+	fs.BoolVar(&c.MuteCommand, "mute-command", c.MuteCommand, envUsage("Let developers comment \"@prmate mute <rule>\" or \"@prmate inline off\" to stop inline comments of a rule, or all of them, on the pull requests they author", "MUTE_COMMAND"))
+	fs.BoolVar(&c.ConflictHelp, "conflict-help", c.ConflictHelp, envUsage("Comment on pull requests that can no longer be merged, explaining each conflicting file", "CONFLICT_HELP"))
+	fs.BoolVar(&c.ConflictDiffs, "conflict-diffs", c.ConflictDiffs, envUsage("Propose a resolution diff for each file in conflict comments", "CONFLICT_DIFFS"))
+	fs.BoolVar(&c.Changelog, "changelog-check", c.Changelog, envUsage("Ask for a changelog entry, with a drafted one, on pull requests that change user-facing code", "CHANGELOG_CHECK"))
+	fs.BoolVar(&c.SecretScan, "secret-scan", c.SecretScan, envUsage("Report credentials on added lines as errors and redact them from LLM prompts", "SECRET_SCAN"))
+	fs.BoolVar(&c.WorkflowScan, "workflow-scan", c.WorkflowScan, envUsage("Check changed GitHub Actions workflows for security problems", "WORKFLOW_SCAN"))
+	fs.StringVar(&c.SmokeChecks, "smoke-checks", c.SmokeChecks, envUsage("Semicolon-separated commands, such as \"go vet ./...;go build ./...\", run in each pull request's workspace before it is reviewed; failures become findings. Pull requests from forks are not checked", "SMOKE_CHECKS"))
+	fs.BoolVar(&c.DepsReview, "dependency-review", c.DepsReview, envUsage("Report added, upgraded and removed dependencies when a PR changes go.mod, package.json or requirements.txt", "DEPENDENCY_REVIEW"))
+	fs.BoolVar(&c.Onboarding, "onboarding", c.Onboarding, envUsage("Open a pull request adding a generated .prmate.md to repositories whose pull requests arrive without one", "ONBOARDING"))
+	fs.BoolVar(&c.IssueTriage, "issue-triage", c.IssueTriage, envUsage("Comment on newly opened issues with their kind, suggested labels, likely relevant files and code owners", "ISSUE_TRIAGE"))
+	fs.StringVar(&c.DepsRegistry, "dependency-registry", c.DepsRegistry, envUsage("deps.dev-compatible API used for dependency licenses and maintenance; \"none\" skips the lookups", "DEPENDENCY_REGISTRY"))
+	fs.IntVar(&c.RepoBudgetDaily, "repo-token-budget-daily", c.RepoBudgetDaily, envUsage("Estimated LLM tokens a repository's reviews may use per UTC day before they run the deterministic checks only; 0 disables", "REPO_TOKEN_BUDGET_DAILY"))
+	fs.IntVar(&c.RepoBudgetMonthly, "repo-token-budget-monthly", c.RepoBudgetMonthly, envUsage("Estimated LLM tokens a repository's reviews may use per calendar month; 0 disables", "REPO_TOKEN_BUDGET_MONTHLY"))
+	fs.IntVar(&c.OrgBudgetDaily, "org-token-budget-daily", c.OrgBudgetDaily, envUsage("Estimated LLM tokens the reviews of an organization's repositories may use per UTC day; 0 disables", "ORG_TOKEN_BUDGET_DAILY"))
+	fs.IntVar(&c.OrgBudgetMonthly, "org-token-budget-monthly", c.OrgBudgetMonthly, envUsage("Estimated LLM tokens the reviews of an organization's repositories may use per calendar month; 0 disables", "ORG_TOKEN_BUDGET_MONTHLY"))
+	fs.IntVar(&c.DegradeQueueDepth, "degrade-queue-depth", c.DegradeQueueDepth, envUsage("Queued webhooks and jobs at which reviews switch to DEGRADED_MODEL and skip dependency context for small changes; 0 disables", "DEGRADE_QUEUE_DEPTH"))
+	fs.DurationVar(&c.DegradeLatency, "degrade-llm-latency", c.DegradeLatency, envUsage("Average LLM call latency at which reviews degrade the same way; 0 disables", "DEGRADE_LLM_LATENCY"))
+	fs.StringVar(&c.DegradedModel, "degraded-model", c.DegradedModel, envUsage("Cheaper model reviews use while degraded; the configured model when empty", "DEGRADED_MODEL"))
+	fs.StringVar(&c.CopilotModel, "copilot-model", c.CopilotModel, envUsage("Copilot model to use", "COPILOT_MODEL"))
+	fs.StringVar(&c.OpenAIAPIKey, "openai-api-key", c.OpenAIAPIKey, envUsage("OpenAI API key", "OPENAI_API_KEY"))
+	fs.StringVar(&c.OpenAIBaseURL, "openai-base-url", c.OpenAIBaseURL, envUsage("OpenAI-compatible API base URL", "OPENAI_BASE_URL"))
+	fs.StringVar(&c.OpenAIModel, "openai-model", c.OpenAIModel, envUsage("OpenAI model to use", "OPENAI_MODEL"))
+	fs.StringVar(&c.OllamaBaseURL, "ollama-base-url", c.OllamaBaseURL, envUsage("Ollama server URL", "OLLAMA_BASE_URL"))
+	fs.StringVar(&c.OllamaModel, "ollama-model", c.OllamaModel, envUsage("Ollama model to use, e.g. qwen2.5-coder:14b", "OLLAMA_MODEL"))
+	fs.StringVar(&c.SentryDSN, "sentry-dsn", c.SentryDSN, envUsage("Sentry DSN for reporting panics and processing failures", "SENTRY_DSN"))
+	fs.StringVar(&c.ErrorReportURL, "error-report-url", c.ErrorReportURL, envUsage("URL receiving failures as JSON POSTs when Sentry is not used", "ERROR_REPORT_URL"))
+	fs.StringVar(&c.Environment, "environment", c.Environment, envUsage("Deployment environment attached to error reports", "ENVIRONMENT"))
+	fs.StringVar(&c.TeamsWebhookURL, "teams-webhook-url", c.TeamsWebhookURL, envUsage("Microsoft Teams incoming or Workflows webhook URL receiving a card per finished review", "TEAMS_WEBHOOK_URL"))
+	fs.StringVar(&c.DiscordWebhookURL, "discord-webhook-url", c.DiscordWebhookURL, envUsage("Discord channel webhook URL receiving an embed per finished review", "DISCORD_WEBHOOK_URL"))
+	fs.StringVar(&c.DiscordUsername, "discord-username", c.DiscordUsername, envUsage("Name Discord messages are posted under; the webhook's name when empty", "DISCORD_USERNAME"))
+	fs.StringVar(&c.DiscordAvatarURL, "discord-avatar-url", c.DiscordAvatarURL, envUsage("Avatar image URL for Discord messages; the webhook's avatar when empty", "DISCORD_AVATAR_URL"))
+	fs.BoolVar(&c.DiscordFindings, "discord-findings", c.DiscordFindings, envUsage("List the top findings of each review in Discord embeds", "DISCORD_FINDINGS"))
+	fs.BoolVar(&c.DiscordScans, "discord-scans", c.DiscordScans, envUsage("Also post codebase scan completions to Discord", "DISCORD_SCANS"))
+	fs.StringVar(&c.EventWebhookURLs, "event-webhook-urls", c.EventWebhookURLs, envUsage("Comma-separated URLs receiving review.completed, scan.completed and job.failed events as JSON POSTs", "EVENT_WEBHOOK_URLS"))
+	fs.StringVar(&c.EventWebhookSecret, "event-webhook-secret", c.EventWebhookSecret, envUsage("Secret signing event deliveries (X-PRMate-Signature-256 HMAC-SHA256)", "EVENT_WEBHOOK_SECRET"))
+	fs.StringVar(&c.EventWebhookEvents, "event-webhook-events", c.EventWebhookEvents, envUsage("Comma-separated event types to deliver; all when empty", "EVENT_WEBHOOK_EVENTS"))
+	fs.StringVar(&c.NotifyChannelsJSON, "notify-channels", c.NotifyChannelsJSON, envUsage("JSON object of named Teams or Discord webhooks that .prmate.md routes send review notifications to", "NOTIFY_CHANNELS"))
+	fs.StringVar(&c.DigestsJSON, "digests", c.DigestsJSON, envUsage("JSON array of scheduled email digests: name, to, repos, schedule (daily or weekly)", "DIGESTS"))
+	fs.IntVar(&c.DigestHour, "digest-hour", c.DigestHour, envUsage("UTC hour (0-23) digests, quality reports and stale PR nudges are sent at; weekly ones go out on Mondays", "DIGEST_HOUR"))
+	fs.StringVar(&c.SMTPAddr, "smtp-addr", c.SMTPAddr, envUsage("SMTP server as host:port; STARTTLS is used when offered", "SMTP_ADDR"))
+	fs.StringVar(&c.SMTPUsername, "smtp-username", c.SMTPUsername, envUsage("SMTP username; no authentication when empty", "SMTP_USERNAME"))
+	fs.StringVar(&c.SMTPPassword, "smtp-password", c.SMTPPassword, envUsage("SMTP password", "SMTP_PASSWORD"))
+	fs.StringVar(&c.SMTPFrom, "smtp-from", c.SMTPFrom, envUsage("Sender address of digest emails", "SMTP_FROM"))
+	fs.StringVar(&c.TicketRoutesJSON, "ticket-routes", c.TicketRoutesJSON, envUsage("JSON array mapping repos to the Jira or Linear project that gets tickets for error findings still open at merge", "TICKET_ROUTES"))
+	fs.StringVar(&c.JiraBaseURL, "jira-base-url", c.JiraBaseURL, envUsage("Jira site URL, e.g. https://acme.atlassian.net", "JIRA_BASE_URL"))
+	fs.StringVar(&c.JiraEmail, "jira-email", c.JiraEmail, envUsage("Jira account email for API token authentication", "JIRA_EMAIL"))
+	fs.StringVar(&c.JiraAPIToken, "jira-api-token", c.JiraAPIToken, envUsage("Jira API token", "JIRA_API_TOKEN"))
+	fs.StringVar(&c.LinearAPIKey, "linear-api-key", c.LinearAPIKey, envUsage("Linear API key", "LINEAR_API_KEY"))
+	fs.StringVar(&c.QualityReportRepos, "quality-report-repos", c.QualityReportRepos, envUsage("Comma-separated owner/repo or owner/* entries that get a weekly quality report issue", "QUALITY_REPORT_REPOS"))
+	fs.StringVar(&c.StalePRsJSON, "stale-prs", c.StalePRsJSON, envUsage("JSON array of stale PR policies: repos, days, mention (all, author, reviewers or none), drafts", "STALE_PRS"))
+	fs.StringVar(&c.ExportURL, "export-url", c.ExportURL, envUsage("Where review records are exported: a directory for CSV files or bigquery://project/dataset", "EXPORT_URL"))
+	fs.DurationVar(&c.ExportInterval, "export-interval", c.ExportInterval, envUsage("How often review records are exported", "EXPORT_INTERVAL"))
+	fs.StringVar(&c.ExportCredentialsFile, "export-google-credentials", c.ExportCredentialsFile, envUsage("Google service account key file for BigQuery export; the metadata server is used when empty", "EXPORT_GOOGLE_CREDENTIALS"))
+	fs.BoolVar(&c.CommentEmoji, "comment-emoji", c.CommentEmoji, envUsage("Use emoji in review comments; false posts plain text", "COMMENT_EMOJI"))
+	fs.StringVar(&c.SeverityEmoji, "severity-emoji", c.SeverityEmoji, envUsage("Comma-separated severity=emoji pairs for inline comments, e.g. error=🛑,suggestion=", "SEVERITY_EMOJI"))
+	fs.StringVar(&c.CommentPrefix, "comment-prefix", c.CommentPrefix, envUsage("Text prepended to every inline review comment, e.g. [Acme Bot]", "COMMENT_PREFIX"))
+	fs.StringVar(&c.ReviewHeader, "review-header", c.ReviewHeader, envUsage("Body of the review holding inline comments; {count} is the number of findings", "REVIEW_HEADER"))
+	fs.StringVar(&c.SummaryHeader, "summary-header", c.SummaryHeader, envUsage("Heading of the review summary comment", "SUMMARY_HEADER"))
+	fs.StringVar(&c.CommentFooter, "comment-footer", c.CommentFooter, envUsage("Footer of the review summary comment; {version} is the PRMate version, none drops it", "COMMENT_FOOTER"))
+	fs.StringVar(&c.ReviewPersona, "review-persona", c.ReviewPersona, envUsage("Reviewer persona for repositories whose .prmate.md front matter chooses none: security, mentor or terse", "REVIEW_PERSONA"))
+	fs.StringVar(&c.OTLPEndpoint, "otlp-endpoint", c.OTLPEndpoint, envUsage("OTLP/HTTP endpoint for trace export; tracing is disabled when empty", "OTEL_EXPORTER_OTLP_ENDPOINT"))
+	fs.StringVar(&c.OTELServiceName, "otel-service-name", c.OTELServiceName, envUsage("Service name reported in traces", "OTEL_SERVICE_NAME"))
+	fs.StringVar(&c.SCMInstancesJSON, "scm-instances", c.SCMInstancesJSON, envUsage("JSON array of additional GitHub Enterprise instances", "SCM_INSTANCES"))
+
+	hideDefaults(fs, "github-token", "webhook-secret", "admin-api-key", "read-only-api-keys", "openai-api-key", "sentry-dsn", "error-report-url", "scm-instances", "artifact-store-url", "artifact-secret-access-key", "teams-webhook-url", "discord-webhook-url", "notify-channels", "event-webhook-urls", "event-webhook-secret", "smtp-password", "jira-api-token", "linear-api-key")
+}
 
-	webhookWorkers := 1
-	if v := os.Getenv("WEBHOOK_WORKERS"); v != "" {
-		if parsed, err := parsePositiveInt(v); err == nil {
-			webhookWorkers = parsed
+// hideDefaults keeps secret values loaded from env out of --help output
+func hideDefaults(fs *flag.FlagSet, names ...string) {
+	for _, name := range names {
+		if f := fs.Lookup(name); f != nil {
+			f.DefValue = ""
 		}
 	}
+}
 
-	// LLM Provider config
-	llmProvider := os.Getenv("LLM_PROVIDER")
-	if llmProvider == "" {
-		llmProvider = "copilot"
+// ReadOnlyKeys returns the configured read-only API keys
+func (c *Config) ReadOnlyKeys() []string {
+	return splitList(c.ReadOnlyAPIKeys)
+}
+
+// TrustedProxyList returns the proxies whose X-Forwarded-For is believed
+func (c *Config) TrustedProxyList() []string {
+	return splitList(c.TrustedProxies)
+}
+
+// DryRunRepoList returns the repositories configured for dry-run mode
+func (c *Config) DryRunRepoList() []string {
+	return splitList(c.DryRunRepos)
+}
+
+// QualityReportRepoList returns the repositories that get weekly quality
+// report issues
+func (c *Config) QualityReportRepoList() []string {
+	return splitList(c.QualityReportRepos)
+}
+
+// EventWebhookURLList returns the URLs receiving outbound events
+func (c *Config) EventWebhookURLList() []string {
+	return splitList(c.EventWebhookURLs)
+}
+
+// EventWebhookEventList returns the event types delivered to the event
+// webhooks; empty means every type
+func (c *Config) EventWebhookEventList() []string {
+	return splitList(c.EventWebhookEvents)
+}
+
+// LLMModel returns the model configured for the selected LLM provider
+func (c *Config) LLMModel() string {
+	switch c.LLMProvider {
+	case "openai":
+		return c.OpenAIModel
+	case "ollama":
+		return c.OllamaModel
 	}
+	return c.CopilotModel
+}
 
-	openAIAPIKey := os.Getenv("OPENAI_API_KEY")
-	openAIBaseURL := os.Getenv("OPENAI_BASE_URL")
-	openAIModel := os.Getenv("OPENAI_MODEL")
-	if openAIModel == "" {
-		openAIModel = "gpt-4"
+// SeverityEmojiMap parses SeverityEmoji; an empty emoji removes it for
+// that severity
+func (c *Config) SeverityEmojiMap() (map[string]string, error) {
+	out := map[string]string{}
+	for _, pair := range splitList(c.SeverityEmoji) {
+		severity, emoji, ok := strings.Cut(pair, "=")
+		severity = strings.ToLower(strings.TrimSpace(severity))
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("SEVERITY_EMOJI entry %q must be severity=emoji", pair)
+		case severity != "error" && severity != "warning" && severity != "suggestion":
+			return nil, fmt.Errorf("SEVERITY_EMOJI severity %q must be error, warning or suggestion", severity)
+		}
+		out[severity] = strings.TrimSpace(emoji)
 	}
+	return out, nil
+}
 
-	return &Config{
-		Port:             port,
-		GinMode:          ginMode,
-		CopilotModel:     copilotModel,
-		GitHubToken:      githubToken,
-		WebhookSecret:    webhookSecret,
-		WorkBaseDir:      workBaseDir,
-		WebhookQueueSize: webhookQueueSize,
-		WebhookWorkers:   webhookWorkers,
-		ShutdownTimeout:  10 * time.Second,
-		ReadTimeout:      15 * time.Second,
-		WriteTimeout:     15 * time.Second,
-		IdleTimeout:      60 * time.Second,
-		LLMProvider:      llmProvider,
-		OpenAIAPIKey:     openAIAPIKey,
-		OpenAIBaseURL:    openAIBaseURL,
-		OpenAIModel:      openAIModel,
+// splitList splits a comma-separated value, dropping blank entries
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
 	}
+	return out
+}
+
+func envUsage(description, envVar string) string {
+	return fmt.Sprintf("%s [%s]", description, envVar)
 }
 
 func parsePositiveInt(s string) (int, error) {
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Digest describes one scheduled email summary of review activity
+type Digest struct {
+	Name     string   `json:"name"`
+	To       []string `json:"to"`
+	Repos    []string `json:"repos"`    // owner/repo or owner/* entries; empty means every repo
+	Schedule string   `json:"schedule"` // "daily" (default) or "weekly"
+}
+
+// Digests returns the digests configured in DIGESTS
+func (c *Config) Digests() ([]Digest, error) {
+	if strings.TrimSpace(c.DigestsJSON) == "" {
+		return nil, nil
+	}
+
+	var digests []Digest
+	if err := json.Unmarshal([]byte(c.DigestsJSON), &digests); err != nil {
+		return nil, fmt.Errorf("parse DIGESTS: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range digests {
+		d := &digests[i]
+		d.Schedule = strings.ToLower(strings.TrimSpace(d.Schedule))
+		if d.Schedule == "" {
+			d.Schedule = "daily"
+		}
+		if d.Schedule != "daily" && d.Schedule != "weekly" {
+			return nil, fmt.Errorf("DIGESTS[%d]: schedule %q must be daily or weekly", i, d.Schedule)
+		}
+		if len(d.To) == 0 {
+			return nil, fmt.Errorf("DIGESTS[%d]: at least one recipient is required", i)
+		}
+		if d.Name == "" {
+			d.Name = fmt.Sprintf("digest-%d", i+1)
+		}
+		if seen[d.Name] {
+			return nil, fmt.Errorf("DIGESTS[%d]: duplicate name %q", i, d.Name)
+		}
+		seen[d.Name] = true
+	}
+
+	return digests, nil
+}
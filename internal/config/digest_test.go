@@ -0,0 +1,53 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_Digests(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    []Digest
+		wantErr string
+	}{
+		{name: "unset"},
+		{
+			name: "defaults",
+			json: `[{"to":["team@example.com"],"repos":["acme/*"]},{"name":"api","to":["api@example.com"],"schedule":"Weekly"}]`,
+			want: []Digest{
+				{Name: "digest-1", To: []string{"team@example.com"}, Repos: []string{"acme/*"}, Schedule: "daily"},
+				{Name: "api", To: []string{"api@example.com"}, Schedule: "weekly"},
+			},
+		},
+		{name: "invalid json", json: `{`, wantErr: "parse DIGESTS"},
+		{name: "no recipients", json: `[{"name":"x"}]`, wantErr: "recipient"},
+		{name: "unknown schedule", json: `[{"to":["a@example.com"],"schedule":"hourly"}]`, wantErr: "daily or weekly"},
+		{name: "duplicate name", json: `[{"name":"x","to":["a@example.com"]},{"name":"x","to":["b@example.com"]}]`, wantErr: "duplicate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&Config{DigestsJSON: tt.json}).Digests()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Digests() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Digests() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Digests() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if g.Name != w.Name || g.Schedule != w.Schedule || strings.Join(g.To, ",") != strings.Join(w.To, ",") || strings.Join(g.Repos, ",") != strings.Join(w.Repos, ",") {
+					t.Errorf("Digests()[%d] = %+v, want %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFiles are looked for in the working directory when CONFIG_FILE is
+// not set; the first found is read
+var configFiles = []string{"prmate.yml", "prmate.yaml", "prmate.json"}
+
+// listSeparators join the lists of the config file for the settings whose
+// env var does not separate entries with commas
+var listSeparators = map[string]string{"SMOKE_CHECKS": ";"}
+
+// repoSettings are the settings the config file sets for one repository,
+// under repos. Only these two can be set per repository; any other key is
+// reported as an error.
+type repoSettings struct {
+	DryRun        bool `json:"dry_run"`
+	QualityReport bool `json:"quality_report"`
+}
+
+// loader reads settings from the environment and then the config file,
+// keeping an error for each value it cannot use
+type loader struct {
+	path string            // config file read, if any
+	file map[string]string // its settings by env var name
+	used map[string]bool   // settings Load asked for
+	errs []error
+}
+
+func newLoader() *loader {
+	l := &loader{file: make(map[string]string), used: make(map[string]bool)}
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		for _, name := range configFiles {
+			if _, err := os.Stat(name); err == nil {
+				path = name
+				break
+			}
+		}
+	}
+	if path != "" {
+		l.loadFile(path)
+	}
+	return l
+}
+
+// loadFile reads the settings of a YAML or JSON config file. Its keys are
+// the names of env vars or flags, in any case; values are what the env var
+// would hold, except that lists and objects may be written out.
+func (l *loader) loadFile(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("read config file: %w", err))
+		return
+	}
+	var raw map[string]any
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("parse config file %s: %w", path, err))
+		return
+	}
+	l.path = path
+
+	// The repos section adds to the lists the file sets, so it is read last
+	var repos any
+	flagEnv := flagEnvNames()
+	for key, v := range raw {
+		name, ok := flagEnv[strings.ToLower(key)]
+		if !ok {
+			name = strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+		}
+		if name == "REPOS" {
+			repos = v
+			continue
+		}
+		value, err := settingValue(v, listSeparators[name])
+		if err != nil {
+			l.errs = append(l.errs, fmt.Errorf("%s: %s: %w", path, key, err))
+			continue
+		}
+		l.file[name] = value
+	}
+	if repos != nil {
+		l.loadRepos(repos)
+	}
+}
+
+// flagEnvNames maps every flag name to the env var of its setting, taken
+// from the "[ENV_VAR]" suffix RegisterFlags gives each usage
+func flagEnvNames() map[string]string {
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	new(Config).RegisterFlags(fs)
+	names := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		if i := strings.LastIndex(f.Usage, " ["); i >= 0 && strings.HasSuffix(f.Usage, "]") {
+			names[f.Name] = f.Usage[i+2 : len(f.Usage)-1]
+		}
+	})
+	return names
+}
+
+// loadRepos adds the repositories of the repos section to the lists of the
+// settings they turn on
+func (l *loader) loadRepos(v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: repos: %w", l.path, err))
+		return
+	}
+	var repos map[string]repoSettings
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&repos); err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: repos: %w", l.path, err))
+		return
+	}
+
+	var dryRun, qualityReport []string
+	for _, name := range slices.Sorted(maps.Keys(repos)) {
+		if owner, repo, ok := strings.Cut(name, "/"); !ok || owner == "" || repo == "" {
+			l.errs = append(l.errs, fmt.Errorf("%s: repos: %q must be owner/repo or owner/*", l.path, name))
+			continue
+		}
+		if repos[name].DryRun {
+			dryRun = append(dryRun, name)
+		}
+		if repos[name].QualityReport {
+			qualityReport = append(qualityReport, name)
+		}
+	}
+	l.appendList("DRY_RUN_REPOS", dryRun)
+	l.appendList("QUALITY_REPORT_REPOS", qualityReport)
+}
+
+func (l *loader) appendList(key string, entries []string) {
+	if len(entries) == 0 {
+		return
+	}
+	if l.file[key] != "" {
+		entries = append([]string{l.file[key]}, entries...)
+	}
+	l.file[key] = strings.Join(entries, ",")
+}
+
+// settingValue returns the string the env var of a setting would hold for a
+// config file value: lists of scalars are joined with sep, or commas, and
+// objects and lists of objects are JSON, as SCM_INSTANCES is
+func settingValue(v any, sep string) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case map[string]any:
+		return jsonValue(v)
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]any, []any:
+				return jsonValue(v)
+			}
+			parts = append(parts, fmt.Sprint(item))
+		}
+		if sep == "" {
+			sep = ","
+		}
+		return strings.Join(parts, sep), nil
+	}
+	return fmt.Sprint(v), nil
+}
+
+func jsonValue(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// lookup returns the value of the setting key from the environment, or else
+// the config file, and the name to report a bad value by
+func (l *loader) lookup(key string) (value, name string) {
+	l.used[key] = true
+	if v := os.Getenv(key); v != "" {
+		return v, key
+	}
+	if v := l.file[key]; v != "" {
+		return v, l.path + ": " + strings.ToLower(key)
+	}
+	return "", key
+}
+
+func (l *loader) get(key string) string {
+	v, _ := l.lookup(key)
+	return v
+}
+
+func (l *loader) getOr(key, fallback string) string {
+	if v := l.get(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// invalid records that the value of name is not what was wanted; its
+// setting keeps the default
+func (l *loader) invalid(name, value, want string) {
+	l.errs = append(l.errs, fmt.Errorf("%s %q must be %s", name, value, want))
+}
+
+func (l *loader) duration(key string, fallback time.Duration) time.Duration {
+	v, name := l.lookup(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		l.invalid(name, v, "a positive duration such as 30s")
+		return fallback
+	}
+	return d
+}
+
+// durationOrZero is duration for the settings 0 turns off
+func (l *loader) durationOrZero(key string, fallback time.Duration) time.Duration {
+	v, name := l.lookup(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		l.invalid(name, v, "a duration such as 30s, or 0")
+		return fallback
+	}
+	return d
+}
+
+func (l *loader) int(key string, fallback int) int {
+	v, name := l.lookup(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		l.invalid(name, v, "a non-negative integer")
+		return fallback
+	}
+	return n
+}
+
+func (l *loader) positiveInt(key string, fallback int) int {
+	v, name := l.lookup(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := parsePositiveInt(v)
+	if err != nil {
+		l.invalid(name, v, "a positive integer")
+		return fallback
+	}
+	return n
+}
+
+func (l *loader) bool(key string, fallback bool) bool {
+	v, name := l.lookup(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		l.invalid(name, v, "true or false")
+		return fallback
+	}
+	return b
+}
+
+func (l *loader) float(key string, fallback float64) float64 {
+	v, name := l.lookup(key)
+	if v == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		l.invalid(name, v, "a non-negative number")
+		return fallback
+	}
+	return f
+}
+
+// finish returns the errors met, with one for each setting of the config
+// file Load did not ask for
+func (l *loader) finish() []error {
+	var unknown []string
+	for key := range l.file {
+		if !l.used[key] {
+			unknown = append(unknown, strings.ToLower(key))
+		}
+	}
+	slices.Sort(unknown)
+	for _, key := range unknown {
+		l.errs = append(l.errs, fmt.Errorf("%s: unknown setting %q", l.path, key))
+	}
+	return l.errs
+}
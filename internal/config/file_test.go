@@ -0,0 +1,156 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_ConfigFile(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, "prmate.yml", `
+port: 9000
+webhook-workers: 4
+work-base-dir: /srv/prmate
+OTLP-Endpoint: http://collector:4318
+LLM_PROVIDER: ollama
+review_timeout: 20m
+dry_run_repos: org2/*
+read_only_api_keys: [key-one, key-two]
+smoke_checks: [go vet ./..., go test ./...]
+scm_instances:
+  - name: acme
+    host: github.acme.com
+    token: ghp_yyyy
+repos:
+  acme/payments:
+    dry_run: true
+  acme/web:
+    quality_report: true
+`))
+	t.Setenv("PORT", "7000")
+
+	cfg := Load()
+	if err := cfg.LoadErr(); err != nil {
+		t.Fatalf("LoadErr() = %v", err)
+	}
+	if cfg.Port != "7000" {
+		t.Errorf("Port = %q, want 7000 from env", cfg.Port)
+	}
+	if cfg.WebhookWorkers != 4 || cfg.LLMProvider != "ollama" || cfg.ReviewTimeout != 20*time.Minute {
+		t.Errorf("WebhookWorkers, LLMProvider, ReviewTimeout = %d, %q, %v; want the file's", cfg.WebhookWorkers, cfg.LLMProvider, cfg.ReviewTimeout)
+	}
+	if cfg.WorkBaseDir != "/srv/prmate" || cfg.OTLPEndpoint != "http://collector:4318" {
+		t.Errorf("WorkBaseDir, OTLPEndpoint = %q, %q; want the file's, set by flag name", cfg.WorkBaseDir, cfg.OTLPEndpoint)
+	}
+	if cfg.LLMTimeout != 10*time.Minute {
+		t.Errorf("LLMTimeout = %v, want the ollama default", cfg.LLMTimeout)
+	}
+	if got := strings.Join(cfg.ReadOnlyKeys(), " "); got != "key-one key-two" {
+		t.Errorf("ReadOnlyKeys() = %q", got)
+	}
+	if cfg.SmokeChecks != "go vet ./...;go test ./..." {
+		t.Errorf("SmokeChecks = %q", cfg.SmokeChecks)
+	}
+	if got := strings.Join(cfg.DryRunRepoList(), " "); got != "org2/* acme/payments" {
+		t.Errorf("DryRunRepoList() = %q", got)
+	}
+	if got := strings.Join(cfg.QualityReportRepoList(), " "); got != "acme/web" {
+		t.Errorf("QualityReportRepoList() = %q", got)
+	}
+	instances, err := cfg.SCMInstances()
+	if err != nil || len(instances) != 2 || instances[1].Host != "github.acme.com" {
+		t.Errorf("SCMInstances() = %+v, %v; want the file's instance", instances, err)
+	}
+}
+
+func TestLoad_DefaultConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "prmate.json"), []byte(`{"max_body_bytes": 26214400, "dry_run": true}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	cfg := Load()
+	if err := cfg.LoadErr(); err != nil {
+		t.Fatalf("LoadErr() = %v", err)
+	}
+	if cfg.MaxBodyBytes != 26214400 || !cfg.DryRun {
+		t.Errorf("MaxBodyBytes, DryRun = %d, %v; want the file's", cfg.MaxBodyBytes, cfg.DryRun)
+	}
+}
+
+func TestLoad_ReportsEveryBadValue(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, "prmate.yml", `
+dry_run: yes please
+review_timeout: -1m
+revew_concurrency: 8
+repos:
+  payments:
+    dry_run: true
+`))
+	t.Setenv("WEBHOOK_WORKERS", "0")
+	t.Setenv("RATE_LIMIT_RPS", "fast")
+	t.Setenv("DIGEST_HOUR", "0")
+	t.Setenv("RULES_CACHE_TTL", "0")
+
+	cfg := Load()
+	if cfg.WebhookWorkers != 1 || cfg.ReviewTimeout != 15*time.Minute {
+		t.Errorf("WebhookWorkers, ReviewTimeout = %d, %v; want the defaults", cfg.WebhookWorkers, cfg.ReviewTimeout)
+	}
+	if cfg.DigestHour != 0 || cfg.RulesCacheTTL != 0 {
+		t.Errorf("DigestHour, RulesCacheTTL = %d, %v; want 0", cfg.DigestHour, cfg.RulesCacheTTL)
+	}
+
+	cfg.GitHubToken = "ghp_x"
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil")
+	}
+	for _, want := range []string{
+		`WEBHOOK_WORKERS "0" must be a positive integer`,
+		`RATE_LIMIT_RPS "fast" must be a non-negative number`,
+		`prmate.yml: dry_run "yes please" must be true or false`,
+		`prmate.yml: review_timeout "-1m" must be a positive duration`,
+		`prmate.yml: unknown setting "revew_concurrency"`,
+		`prmate.yml: repos: "payments" must be owner/repo`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error missing %q:\n%v", want, err)
+		}
+	}
+	if n := len(strings.Split(err.Error(), "\n")); n != 6 {
+		t.Errorf("Validate() reported %d problems, want 6:\n%v", n, err)
+	}
+}
+
+func TestLoad_UnreadableConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "missing", path: filepath.Join(t.TempDir(), "prmate.yml"), wantErr: "read config file"},
+		{name: "not yaml", path: writeConfigFile(t, "prmate.yml", "port: [8080"), wantErr: "parse config file"},
+		{name: "not json", path: writeConfigFile(t, "prmate.json", `{"port": 8080`), wantErr: "parse config file"},
+		{name: "bad repos", path: writeConfigFile(t, "prmate.yml", "repos:\n  acme/web:\n    dryrun: true\n"), wantErr: `unknown field "dryrun"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", tt.path)
+			if err := Load().LoadErr(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadErr() = %v, want mention of %s", err, tt.wantErr)
+			}
+		})
+	}
+}
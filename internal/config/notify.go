@@ -0,0 +1,43 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// NotifyChannel is a named destination for the review notifications of
+// the .prmate.md routes naming it
+type NotifyChannel struct {
+	Teams   string `json:"teams"`   // Teams incoming or Workflows webhook URL
+	Discord string `json:"discord"` // Discord channel webhook URL
+}
+
+// NotifyChannels returns the channels configured in NOTIFY_CHANNELS, by
+// name
+func (c *Config) NotifyChannels() (map[string]NotifyChannel, error) {
+	if strings.TrimSpace(c.NotifyChannelsJSON) == "" {
+		return nil, nil
+	}
+
+	var channels map[string]NotifyChannel
+	if err := json.Unmarshal([]byte(c.NotifyChannelsJSON), &channels); err != nil {
+		return nil, fmt.Errorf("parse NOTIFY_CHANNELS: %w", err)
+	}
+	for name, ch := range channels {
+		if ch.Teams == "" && ch.Discord == "" {
+			return nil, fmt.Errorf("NOTIFY_CHANNELS[%q]: needs a teams or discord webhook URL", name)
+		}
+		for _, u := range []string{ch.Teams, ch.Discord} {
+			if u == "" {
+				continue
+			}
+			parsed, err := url.Parse(u)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return nil, fmt.Errorf("NOTIFY_CHANNELS[%q]: invalid URL %q", name, u)
+			}
+		}
+	}
+	return channels, nil
+}
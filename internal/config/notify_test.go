@@ -0,0 +1,47 @@
+package config
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestConfig_NotifyChannels(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    map[string]NotifyChannel
+		wantErr string
+	}{
+		{name: "unset"},
+		{
+			name: "channels",
+			json: `{"payments":{"teams":"https://example.webhook.office.com/a"},"web":{"discord":"https://discord.com/api/webhooks/1/x"}}`,
+			want: map[string]NotifyChannel{
+				"payments": {Teams: "https://example.webhook.office.com/a"},
+				"web":      {Discord: "https://discord.com/api/webhooks/1/x"},
+			},
+		},
+		{name: "invalid json", json: `[`, wantErr: "parse NOTIFY_CHANNELS"},
+		{name: "no url", json: `{"payments":{}}`, wantErr: "needs a teams or discord webhook URL"},
+		{name: "bad url", json: `{"payments":{"teams":"ftp://example.com"}}`, wantErr: "invalid URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&Config{NotifyChannelsJSON: tt.json}).NotifyChannels()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NotifyChannels() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NotifyChannels() error = %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("NotifyChannels() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
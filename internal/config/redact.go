@@ -0,0 +1,59 @@
+package config
+
+import "strings"
+
+const redactedValue = "***"
+
+// Redacted returns a copy of the configuration with secrets masked, safe to
+// expose to operators. Secrets that are set become "***", unset ones stay empty.
+func (c *Config) Redacted() Config {
+	out := *c
+	out.GitHubToken = redact(c.GitHubToken)
+	out.WebhookSecret = redact(c.WebhookSecret)
+	out.OpenAIAPIKey = redact(c.OpenAIAPIKey)
+	out.AdminAPIKey = redact(c.AdminAPIKey)
+	out.ReadOnlyAPIKeys = redact(c.ReadOnlyAPIKeys)
+	out.SCMInstancesJSON = redact(c.SCMInstancesJSON)
+	out.SentryDSN = redact(c.SentryDSN)
+	out.ErrorReportURL = redact(c.ErrorReportURL)
+	out.TeamsWebhookURL = redact(c.TeamsWebhookURL)
+	out.DiscordWebhookURL = redact(c.DiscordWebhookURL)
+	out.NotifyChannelsJSON = redact(c.NotifyChannelsJSON)
+	out.EventWebhookURLs = redact(c.EventWebhookURLs)
+	out.EventWebhookSecret = redact(c.EventWebhookSecret)
+	out.SMTPPassword = redact(c.SMTPPassword)
+	out.JiraAPIToken = redact(c.JiraAPIToken)
+	out.LinearAPIKey = redact(c.LinearAPIKey)
+	out.ArtifactSecretKey = redact(c.ArtifactSecretKey)
+	out.ArtifactStoreURL = redactQuery(c.ArtifactStoreURL)
+	return out
+}
+
+// RedactedSCMInstances returns the configured SCM instances with credentials masked
+func (c *Config) RedactedSCMInstances() ([]SCMInstance, error) {
+	instances, err := c.SCMInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range instances {
+		instances[i].Token = redact(instances[i].Token)
+		instances[i].WebhookSecret = redact(instances[i].WebhookSecret)
+	}
+	return instances, nil
+}
+
+// redactQuery masks the query string of a URL, which may carry a SAS token
+func redactQuery(rawURL string) string {
+	if i := strings.IndexByte(rawURL, '?'); i >= 0 && strings.Contains(rawURL[i:], "sig=") {
+		return rawURL[:i+1] + redactedValue
+	}
+	return rawURL
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedValue
+}
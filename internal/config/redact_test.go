@@ -0,0 +1,83 @@
+package config
+
+import "testing"
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := &Config{
+		Port:             "8080",
+		GitHubToken:      "ghp_secret",
+		WebhookSecret:    "",
+		OpenAIAPIKey:     "sk-secret",
+		AdminAPIKey:      "admin-secret",
+		SentryDSN:        "https://key@o0.ingest.sentry.io/0",
+		ErrorReportURL:   "https://errors.example.com/hook?token=secret",
+		EventWebhookURLs: "https://hooks.example.com/T0/B0/secret,https://ci.example.com/events",
+	}
+
+	r := cfg.Redacted()
+
+	if r.GitHubToken != "***" {
+		t.Errorf("GitHubToken = %q, want masked", r.GitHubToken)
+	}
+	if r.WebhookSecret != "" {
+		t.Errorf("WebhookSecret = %q, want empty when unset", r.WebhookSecret)
+	}
+	if r.OpenAIAPIKey != "***" || r.AdminAPIKey != "***" {
+		t.Error("API keys should be masked")
+	}
+	if r.SentryDSN != "***" || r.ErrorReportURL != "***" {
+		t.Errorf("SentryDSN = %q, ErrorReportURL = %q; want error reporting endpoints masked", r.SentryDSN, r.ErrorReportURL)
+	}
+	if r.EventWebhookURLs != "***" {
+		t.Errorf("EventWebhookURLs = %q, want masked: the URLs may embed tokens", r.EventWebhookURLs)
+	}
+	if r.Port != "8080" {
+		t.Errorf("Port = %q, non-secret fields should be kept", r.Port)
+	}
+	if cfg.GitHubToken != "ghp_secret" {
+		t.Error("Redacted() must not modify the original config")
+	}
+}
+
+func TestConfig_RedactedSCMInstances(t *testing.T) {
+	cfg := &Config{
+		GitHubToken:      "ghp_default",
+		SCMInstancesJSON: `[{"host":"ghe.example.com","token":"ghe-token","webhook_secret":"s"}]`,
+	}
+
+	instances, err := cfg.RedactedSCMInstances()
+	if err != nil {
+		t.Fatalf("RedactedSCMInstances() error = %v", err)
+	}
+
+	for _, inst := range instances {
+		if inst.Token != "***" {
+			t.Errorf("instance %s token = %q, want masked", inst.Host, inst.Token)
+		}
+	}
+	if instances[1].WebhookSecret != "***" {
+		t.Errorf("webhook secret = %q, want masked", instances[1].WebhookSecret)
+	}
+}
+
+func TestConfig_RedactedArtifactStore(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "/var/lib/prmate/artifacts", want: "/var/lib/prmate/artifacts"},
+		{url: "s3://bucket/prefix?region=eu-west-1", want: "s3://bucket/prefix?region=eu-west-1"},
+		{url: "azure://acct.blob.core.windows.net/c?sv=2022&sig=abc", want: "azure://acct.blob.core.windows.net/c?***"},
+	}
+
+	for _, tt := range tests {
+		cfg := &Config{ArtifactStoreURL: tt.url, ArtifactSecretKey: "secret"}
+		r := cfg.Redacted()
+		if r.ArtifactStoreURL != tt.want {
+			t.Errorf("ArtifactStoreURL = %q, want %q", r.ArtifactStoreURL, tt.want)
+		}
+		if r.ArtifactSecretKey != "***" {
+			t.Errorf("ArtifactSecretKey = %q, want masked", r.ArtifactSecretKey)
+		}
+	}
+}
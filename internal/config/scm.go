@@ -0,0 +1,199 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SCMInstance describes one source-control instance PRMate receives webhooks
+// from. Instances sharing a host, such as several organizations on
+// github.com served as separate tenants, are told apart by TargetID or Orgs.
+type SCMInstance struct {
+	Name          string   `json:"name"`
+	Kind          string   `json:"kind"`      // "github" (default)
+	Host          string   `json:"host"`      // webhook host, e.g. github.com or ghe.example.com
+	APIURL        string   `json:"api_url"`   // empty for github.com
+	TargetID      string   `json:"target_id"` // optional X-GitHub-Hook-Installation-Target-ID to route on
+	Orgs          []string `json:"orgs"`      // optional repository owners on Host to route on
+	Token         string   `json:"token"`
+	WebhookSecret string   `json:"webhook_secret"`
+	// Routes are the keys deliveries are routed to the instance by, set
+	// by SCMInstances: an installation target ID, host/org pairs, or a host
+	Routes []string `json:"-"`
+
+	// Overrides of the server-wide settings for this instance; unset
+	// fields keep the server's value
+	Offline        *bool `json:"offline,omitempty"`
+	DryRun         *bool `json:"dry_run,omitempty"`
+	ShadowMode     *bool `json:"shadow_mode,omitempty"`
+	FixCommand     *bool `json:"fix_command,omitempty"`
+	ExplainCommand *bool `json:"explain_command,omitempty"`
+	ExemptCommand  *bool `json:"exempt_command,omitempty"`
+	MuteCommand    *bool `json:"mute_command,omitempty"`
+	SecretScan     *bool `json:"secret_scan,omitempty"`
+	WorkflowScan   *bool `json:"workflow_scan,omitempty"`
+	Workers        int   `json:"workers,omitempty"`
+	QueueSize      int   `json:"queue_size,omitempty"`
+	DiskQuotaBytes int   `json:"disk_quota_bytes,omitempty"`
+
+	RepoBudgetDaily   int `json:"repo_token_budget_daily,omitempty"`
+	RepoBudgetMonthly int `json:"repo_token_budget_monthly,omitempty"`
+	OrgBudgetDaily    int `json:"org_token_budget_daily,omitempty"`
+	OrgBudgetMonthly  int `json:"org_token_budget_monthly,omitempty"`
+}
+
+// ForInstance returns a copy of c with the overrides of inst applied
+func (c *Config) ForInstance(inst SCMInstance) *Config {
+	out := *c
+	for _, o := range []struct {
+		v   *bool
+		dst *bool
+	}{
+		{inst.Offline, &out.Offline},
+		{inst.DryRun, &out.DryRun},
+		{inst.ShadowMode, &out.ShadowMode},
+		{inst.FixCommand, &out.FixCommand},
+		{inst.ExplainCommand, &out.ExplainCommand},
+		{inst.ExemptCommand, &out.ExemptCommand},
+		{inst.MuteCommand, &out.MuteCommand},
+		{inst.SecretScan, &out.SecretScan},
+		{inst.WorkflowScan, &out.WorkflowScan},
+	} {
+		if o.v != nil {
+			*o.dst = *o.v
+		}
+	}
+	for _, o := range []struct {
+		v   int
+		dst *int
+	}{
+		{inst.Workers, &out.WebhookWorkers},
+		{inst.QueueSize, &out.WebhookQueueSize},
+		{inst.DiskQuotaBytes, &out.DiskQuotaBytes},
+		{inst.RepoBudgetDaily, &out.RepoBudgetDaily},
+		{inst.RepoBudgetMonthly, &out.RepoBudgetMonthly},
+		{inst.OrgBudgetDaily, &out.OrgBudgetDaily},
+		{inst.OrgBudgetMonthly, &out.OrgBudgetMonthly},
+	} {
+		if o.v > 0 {
+			*o.dst = o.v
+		}
+	}
+	return &out
+}
+
+// SCMInstances returns the default github.com instance built from
+// GITHUB_TOKEN/WEBHOOK_SECRET plus any extra instances from SCM_INSTANCES.
+func (c *Config) SCMInstances() ([]SCMInstance, error) {
+	instances := []SCMInstance{{
+		Name:          "default",
+		Kind:          "github",
+		Host:          "github.com",
+		Token:         c.GitHubToken,
+		WebhookSecret: c.WebhookSecret,
+		Routes:        []string{"github.com"},
+	}}
+
+	if strings.TrimSpace(c.SCMInstancesJSON) == "" {
+		return instances, nil
+	}
+
+	var extra []SCMInstance
+	if err := json.Unmarshal([]byte(c.SCMInstancesJSON), &extra); err != nil {
+		return nil, fmt.Errorf("parse SCM_INSTANCES: %w", err)
+	}
+
+	seen := map[string]bool{"github.com": true}
+	names := map[string]bool{"default": true}
+	for i, inst := range extra {
+		if err := normalizeSCMInstance(&inst); err != nil {
+			return nil, fmt.Errorf("SCM_INSTANCES[%d]: %w", i, err)
+		}
+		if names[inst.Name] {
+			return nil, fmt.Errorf("SCM_INSTANCES[%d]: duplicate name %q", i, inst.Name)
+		}
+		names[inst.Name] = true
+		for _, key := range inst.routeKeys() {
+			if seen[key] {
+				return nil, fmt.Errorf("SCM_INSTANCES[%d]: duplicate route %q", i, key)
+			}
+			seen[key] = true
+			inst.Routes = append(inst.Routes, key)
+		}
+		// An instance keyed by target ID alone also takes deliveries from
+		// its host while no other instance does
+		if len(inst.Orgs) == 0 && inst.TargetID != "" && !seen[inst.Host] {
+			seen[inst.Host] = true
+			inst.Routes = append(inst.Routes, inst.Host)
+		}
+		if c.Offline && inst.Offline != nil && !*inst.Offline {
+			return nil, fmt.Errorf("SCM_INSTANCES[%d]: offline cannot be turned off while the server runs with OFFLINE=true", i)
+		}
+		instances = append(instances, inst)
+	}
+
+	return instances, nil
+}
+
+// routeKeys returns the keys the instance needs to route on: its
+// installation target ID and host/org pairs for its organizations, or else
+// its host
+func (i SCMInstance) routeKeys() []string {
+	if i.TargetID == "" && len(i.Orgs) == 0 {
+		return []string{i.Host}
+	}
+	var keys []string
+	if i.TargetID != "" {
+		keys = append(keys, i.TargetID)
+	}
+	for _, org := range i.Orgs {
+		keys = append(keys, i.Host+"/"+org)
+	}
+	return keys
+}
+
+// SharesHost reports whether the instance is one of several on its host,
+// reached by target ID or organization only
+func (i SCMInstance) SharesHost() bool {
+	return !slices.Contains(i.Routes, i.Host)
+}
+
+func normalizeSCMInstance(inst *SCMInstance) error {
+	inst.Kind = strings.ToLower(strings.TrimSpace(inst.Kind))
+	if inst.Kind == "" {
+		inst.Kind = "github"
+	}
+	if inst.Kind != "github" {
+		return fmt.Errorf("scm kind %q is not supported", inst.Kind)
+	}
+
+	inst.Host = strings.ToLower(strings.TrimSpace(inst.Host))
+	if inst.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if inst.Host != "github.com" && inst.APIURL == "" {
+		inst.APIURL = fmt.Sprintf("https://%s/api/v3/", inst.Host)
+	}
+	if inst.Name == "" {
+		inst.Name = inst.Host
+	}
+	for i, org := range inst.Orgs {
+		inst.Orgs[i] = strings.ToLower(strings.TrimSpace(org))
+		if inst.Orgs[i] == "" || strings.Contains(inst.Orgs[i], "/") {
+			return fmt.Errorf("invalid org %q", org)
+		}
+	}
+	if inst.Workers < 0 || inst.QueueSize < 0 || inst.DiskQuotaBytes < 0 {
+		return fmt.Errorf("workers, queue_size and disk_quota_bytes must not be negative")
+	}
+	if inst.RepoBudgetDaily < 0 || inst.RepoBudgetMonthly < 0 || inst.OrgBudgetDaily < 0 || inst.OrgBudgetMonthly < 0 {
+		return fmt.Errorf("token budgets must not be negative")
+	}
+	if inst.Token == "" {
+		return fmt.Errorf("token is required for host %q", inst.Host)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestConfig_SCMInstances(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name:      "default only",
+			json:      "",
+			wantCount: 1,
+		},
+		{
+			name:      "enterprise instance",
+			json:      `[{"host":"ghe.example.com","token":"t","webhook_secret":"s"}]`,
+			wantCount: 2,
+		},
+		{
+			name:    "gitlab not supported",
+			json:    `[{"kind":"gitlab","host":"gitlab.com","token":"t"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "duplicate default host",
+			json:    `[{"host":"github.com","token":"t"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing token",
+			json:    `[{"host":"ghe.example.com"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			json:    `{`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{GitHubToken: "default-token", SCMInstancesJSON: tt.json}
+
+			instances, err := cfg.SCMInstances()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("SCMInstances() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SCMInstances() unexpected error: %v", err)
+			}
+			if len(instances) != tt.wantCount {
+				t.Errorf("len(instances) = %d, want %d", len(instances), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestConfig_SCMInstances_DefaultsAPIURL(t *testing.T) {
+	cfg := &Config{SCMInstancesJSON: `[{"host":"GHE.example.com","token":"t"}]`}
+
+	instances, err := cfg.SCMInstances()
+	if err != nil {
+		t.Fatalf("SCMInstances() unexpected error: %v", err)
+	}
+
+	ghe := instances[1]
+	if ghe.Host != "ghe.example.com" {
+		t.Errorf("Host = %q, want ghe.example.com", ghe.Host)
+	}
+	if ghe.APIURL != "https://ghe.example.com/api/v3/" {
+		t.Errorf("APIURL = %q, want https://ghe.example.com/api/v3/", ghe.APIURL)
+	}
+}
+
+func TestConfig_SCMInstances_Tenants(t *testing.T) {
+	cfg := &Config{GitHubToken: "t", SCMInstancesJSON: `[
+		{"name":"acme","host":"github.com","orgs":["Acme"],"token":"t1","webhook_secret":"s1","offline":true,"queue_size":5,"org_token_budget_monthly":90000},
+		{"name":"globex","host":"github.com","target_id":"4242","token":"t2"},
+		{"name":"corp","host":"ghe.example.com","target_id":"7","token":"t3"}
+	]`}
+
+	instances, err := cfg.SCMInstances()
+	if err != nil {
+		t.Fatalf("SCMInstances() unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"default": "[github.com]",
+		"acme":    "[github.com/acme]",
+		"globex":  "[4242]",
+		"corp":    "[7 ghe.example.com]",
+	}
+	for _, inst := range instances {
+		if got := fmt.Sprint(inst.Routes); got != want[inst.Name] {
+			t.Errorf("%s routes = %s, want %s", inst.Name, got, want[inst.Name])
+		}
+	}
+	if !instances[1].SharesHost() || instances[3].SharesHost() {
+		t.Error("acme shares github.com; corp has ghe.example.com to itself")
+	}
+
+	acme := cfg.ForInstance(instances[1])
+	if !acme.Offline || acme.WebhookQueueSize != 5 || acme.OrgBudgetMonthly != 90000 || cfg.Offline {
+		t.Errorf("ForInstance() = offline %v, queue %d, org budget %d; server offline %v", acme.Offline, acme.WebhookQueueSize, acme.OrgBudgetMonthly, cfg.Offline)
+	}
+
+	for name, json := range map[string]string{
+		"overlapping orgs": `[{"name":"a","orgs":["acme"],"host":"github.com","token":"t"},{"name":"b","orgs":["ACME"],"host":"github.com","token":"t"}]`,
+		"duplicate name":   `[{"name":"a","host":"ghe.example.com","token":"t"},{"name":"a","host":"ghe2.example.com","token":"t"}]`,
+		"org with slash":   `[{"name":"a","orgs":["acme/api"],"host":"github.com","token":"t"}]`,
+		"negative workers": `[{"name":"a","host":"ghe.example.com","token":"t","workers":-1}]`,
+		"negative budget":  `[{"name":"a","host":"ghe.example.com","token":"t","repo_token_budget_daily":-1}]`,
+	} {
+		if _, err := (&Config{SCMInstancesJSON: json}).SCMInstances(); err == nil {
+			t.Errorf("%s: SCMInstances() expected error, got nil", name)
+		}
+	}
+	offline := &Config{Offline: true, SCMInstancesJSON: `[{"name":"a","orgs":["acme"],"host":"github.com","token":"t","offline":false}]`}
+	if _, err := offline.SCMInstances(); err == nil {
+		t.Error("an instance cannot use the LLM when the server runs offline")
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// StalePolicy decides when open pull requests in a set of repositories get
+// a nudge with their status summary
+type StalePolicy struct {
+	Repos   []string `json:"repos"`   // owner/repo or owner/* entries; empty means every repo
+	Days    int      `json:"days"`    // days without activity; default 7
+	Mention string   `json:"mention"` // "all" (default), "author", "reviewers" or "none"
+	Drafts  bool     `json:"drafts"`  // also nudge draft pull requests
+}
+
+// StalePolicies returns the policies configured in STALE_PRS; the first
+// policy covering a repository applies to it
+func (c *Config) StalePolicies() ([]StalePolicy, error) {
+	if strings.TrimSpace(c.StalePRsJSON) == "" {
+		return nil, nil
+	}
+
+	var policies []StalePolicy
+	if err := json.Unmarshal([]byte(c.StalePRsJSON), &policies); err != nil {
+		return nil, fmt.Errorf("parse STALE_PRS: %w", err)
+	}
+
+	for i := range policies {
+		p := &policies[i]
+		if p.Days == 0 {
+			p.Days = 7
+		}
+		if p.Days < 0 {
+			return nil, fmt.Errorf("STALE_PRS[%d]: days must be positive", i)
+		}
+		p.Mention = strings.ToLower(strings.TrimSpace(p.Mention))
+		switch p.Mention {
+		case "":
+			p.Mention = "all"
+		case "all", "author", "reviewers", "none":
+		default:
+			return nil, fmt.Errorf("STALE_PRS[%d]: mention %q must be all, author, reviewers or none", i, p.Mention)
+		}
+	}
+
+	return policies, nil
+}
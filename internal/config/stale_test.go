@@ -0,0 +1,52 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_StalePolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    []StalePolicy
+		wantErr string
+	}{
+		{name: "unset"},
+		{
+			name: "defaults",
+			json: `[{"repos":["acme/api"],"days":3,"mention":"Author","drafts":true},{}]`,
+			want: []StalePolicy{
+				{Repos: []string{"acme/api"}, Days: 3, Mention: "author", Drafts: true},
+				{Days: 7, Mention: "all"},
+			},
+		},
+		{name: "invalid json", json: `{`, wantErr: "parse STALE_PRS"},
+		{name: "negative days", json: `[{"days":-1}]`, wantErr: "days must be positive"},
+		{name: "unknown mention", json: `[{"mention":"team"}]`, wantErr: "all, author, reviewers or none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&Config{StalePRsJSON: tt.json}).StalePolicies()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("StalePolicies() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("StalePolicies() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("StalePolicies() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if g.Days != w.Days || g.Mention != w.Mention || g.Drafts != w.Drafts || strings.Join(g.Repos, ",") != strings.Join(w.Repos, ",") {
+					t.Errorf("StalePolicies()[%d] = %+v, want %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// TicketRoute maps repositories to the issue tracker project that receives
+// tickets for findings still open when their pull request is merged
+type TicketRoute struct {
+	Repos     []string `json:"repos"`      // owner/repo or owner/* entries; empty means every repo
+	Tracker   string   `json:"tracker"`    // "jira" or "linear"
+	Project   string   `json:"project"`    // Jira project key or Linear team key
+	IssueType string   `json:"issue_type"` // Jira issue type; default "Bug"
+	Rules     []string `json:"rules"`      // only findings whose rule contains one of these (case-insensitive); empty means all
+	Labels    []string `json:"labels"`
+}
+
+// TicketRoutes returns the routes configured in TICKET_ROUTES
+func (c *Config) TicketRoutes() ([]TicketRoute, error) {
+	if strings.TrimSpace(c.TicketRoutesJSON) == "" {
+		return nil, nil
+	}
+
+	var routes []TicketRoute
+	if err := json.Unmarshal([]byte(c.TicketRoutesJSON), &routes); err != nil {
+		return nil, fmt.Errorf("parse TICKET_ROUTES: %w", err)
+	}
+
+	for i := range routes {
+		r := &routes[i]
+		r.Tracker = strings.ToLower(strings.TrimSpace(r.Tracker))
+		switch r.Tracker {
+		case "jira":
+			if c.JiraBaseURL == "" || c.JiraEmail == "" || c.JiraAPIToken == "" {
+				return nil, fmt.Errorf("TICKET_ROUTES[%d]: jira needs JIRA_BASE_URL, JIRA_EMAIL and JIRA_API_TOKEN", i)
+			}
+			if r.IssueType == "" {
+				r.IssueType = "Bug"
+			}
+		case "linear":
+			if c.LinearAPIKey == "" {
+				return nil, fmt.Errorf("TICKET_ROUTES[%d]: linear needs LINEAR_API_KEY", i)
+			}
+		default:
+			return nil, fmt.Errorf("TICKET_ROUTES[%d]: tracker %q must be jira or linear", i, r.Tracker)
+		}
+		r.Project = strings.ToUpper(strings.TrimSpace(r.Project))
+		if r.Project == "" {
+			return nil, fmt.Errorf("TICKET_ROUTES[%d]: project is required", i)
+		}
+	}
+
+	return routes, nil
+}
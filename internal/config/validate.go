@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+)
+
+// LoadErr reports the values Load could not use, joined into one error
+func (c *Config) LoadErr() error {
+	return errors.Join(c.loadErrs...)
+}
+
+// Validate reports every configuration problem that would stop the server
+// from starting or serving reviews, joined into one error
+func (c *Config) Validate() error {
+	errs := slices.Clone(c.loadErrs)
+
+	switch c.LLMProvider {
+	case "copilot":
+	case "openai":
+		if c.OpenAIAPIKey == "" && !c.Offline {
+			errs = append(errs, fmt.Errorf("OPENAI_API_KEY is required when LLM_PROVIDER=openai"))
+		}
+	case "ollama":
+		if c.OllamaModel == "" && !c.Offline {
+			errs = append(errs, fmt.Errorf("OLLAMA_MODEL is required when LLM_PROVIDER=ollama"))
+		}
+		if u, err := url.Parse(c.OllamaBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("OLLAMA_BASE_URL %q must be an http or https URL", c.OllamaBaseURL))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("LLM_PROVIDER %q is not supported: use copilot, openai or ollama", c.LLMProvider))
+	}
+
+	switch c.ModelCheck {
+	case "warn", "fail", "off":
+	default:
+		errs = append(errs, fmt.Errorf("MODEL_CHECK %q is not supported: use warn, fail or off", c.ModelCheck))
+	}
+	if c.GitHubToken == "" {
+		errs = append(errs, fmt.Errorf("GITHUB_TOKEN is required"))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	for _, p := range c.TrustedProxyList() {
+		if _, _, err := net.ParseCIDR(p); err != nil && net.ParseIP(p) == nil {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES entry %q must be an IP address or CIDR", p))
+		}
+	}
+	if c.TLSClientCAFile != "" && c.TLSCertFile == "" {
+		errs = append(errs, fmt.Errorf("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE"))
+	}
+	// GitHub cannot present a client certificate when delivering webhooks
+	if c.TLSClientCAFile != "" && c.AdminPort == "" {
+		errs = append(errs, fmt.Errorf("TLS_CLIENT_CA_FILE requires ADMIN_PORT so webhooks are not asked for client certificates"))
+	}
+	if _, err := c.SCMInstances(); err != nil {
+		errs = append(errs, err)
+	}
+	digests, err := c.Digests()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(digests) > 0 && (c.SMTPAddr == "" || c.SMTPFrom == "") {
+		errs = append(errs, fmt.Errorf("SMTP_ADDR and SMTP_FROM are required when DIGESTS is set"))
+	}
+	if _, err := c.StalePolicies(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := c.TicketRoutes(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := c.NotifyChannels(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.ReviewConcurrency < 0 {
+		errs = append(errs, fmt.Errorf("REVIEW_CONCURRENCY %d must not be negative", c.ReviewConcurrency))
+	}
+	if c.DigestHour < 0 || c.DigestHour > 23 {
+		errs = append(errs, fmt.Errorf("DIGEST_HOUR %d must be between 0 and 23", c.DigestHour))
+	}
+	switch c.ReviewPersona {
+	case "", "security", "mentor", "terse":
+	default:
+		errs = append(errs, fmt.Errorf("REVIEW_PERSONA %q is not supported: use security, mentor or terse", c.ReviewPersona))
+	}
+	if _, err := c.SeverityEmojiMap(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.DepsReview && c.DepsRegistry != "none" {
+		if u, err := url.Parse(c.DepsRegistry); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("DEPENDENCY_REGISTRY %q must be an http(s) URL or none", c.DepsRegistry))
+		}
+	}
+	if c.ExportURL != "" && c.ExportInterval <= 0 {
+		errs = append(errs, fmt.Errorf("EXPORT_INTERVAL must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
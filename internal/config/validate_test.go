@@ -0,0 +1,73 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{LLMProvider: "copilot", GitHubToken: "ghp_x", ModelCheck: "warn"}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(c *Config)
+		wantErr string
+	}{
+		{name: "valid", mutate: func(c *Config) {}},
+		{name: "openai without key", mutate: func(c *Config) { c.LLMProvider = "openai" }, wantErr: "OPENAI_API_KEY"},
+		{name: "ollama without model", mutate: func(c *Config) { c.LLMProvider = "ollama"; c.OllamaBaseURL = "http://localhost:11434" }, wantErr: "OLLAMA_MODEL"},
+		{name: "ollama url without scheme", mutate: func(c *Config) {
+			c.LLMProvider = "ollama"
+			c.OllamaModel = "llama3.1"
+			c.OllamaBaseURL = "localhost:11434"
+		}, wantErr: "OLLAMA_BASE_URL"},
+		{name: "ollama", mutate: func(c *Config) {
+			c.LLMProvider = "ollama"
+			c.OllamaModel = "llama3.1"
+			c.OllamaBaseURL = "http://gpu-box:11434"
+		}},
+		{name: "unknown provider", mutate: func(c *Config) { c.LLMProvider = "bard" }, wantErr: "LLM_PROVIDER"},
+		{name: "missing token", mutate: func(c *Config) { c.GitHubToken = "" }, wantErr: "GITHUB_TOKEN"},
+		{name: "cert without key", mutate: func(c *Config) { c.TLSCertFile = "cert.pem" }, wantErr: "TLS_KEY_FILE"},
+		{name: "client ca without tls", mutate: func(c *Config) { c.TLSClientCAFile = "ca.pem"; c.AdminPort = "9090" }, wantErr: "TLS_CERT_FILE"},
+		{name: "client ca without admin port", mutate: func(c *Config) {
+			c.TLSCertFile, c.TLSKeyFile, c.TLSClientCAFile = "cert.pem", "key.pem", "ca.pem"
+		}, wantErr: "ADMIN_PORT"},
+		{name: "client ca with admin port", mutate: func(c *Config) {
+			c.TLSCertFile, c.TLSKeyFile, c.TLSClientCAFile, c.AdminPort = "cert.pem", "key.pem", "ca.pem", "9090"
+		}},
+		{name: "trusted proxy not an address", mutate: func(c *Config) { c.TrustedProxies = "10.0.0.0/8, proxy.internal" }, wantErr: `TRUSTED_PROXIES entry "proxy.internal"`},
+		{name: "trusted proxies", mutate: func(c *Config) { c.TrustedProxies = "10.0.0.0/8, 192.168.1.4" }},
+		{name: "bad scm instances", mutate: func(c *Config) { c.SCMInstancesJSON = "[" }, wantErr: "SCM_INSTANCES"},
+		{name: "digests without smtp", mutate: func(c *Config) { c.DigestsJSON = `[{"to":["a@example.com"]}]` }, wantErr: "SMTP_ADDR"},
+		{name: "dependency registry not a url", mutate: func(c *Config) { c.DepsReview = true; c.DepsRegistry = "deps.dev" }, wantErr: "DEPENDENCY_REGISTRY"},
+		{name: "stale policy with unknown mention", mutate: func(c *Config) { c.StalePRsJSON = `[{"mention":"team"}]` }, wantErr: "STALE_PRS[0]"},
+		{name: "jira route without credentials", mutate: func(c *Config) { c.TicketRoutesJSON = `[{"tracker":"jira","project":"SEC"}]` }, wantErr: "JIRA_API_TOKEN"},
+		{name: "unknown model check", mutate: func(c *Config) { c.ModelCheck = "strict" }, wantErr: "MODEL_CHECK"},
+		{name: "unknown severity emoji", mutate: func(c *Config) { c.SeverityEmoji = "critical=🔥" }, wantErr: "SEVERITY_EMOJI"},
+		{name: "severity emoji without emoji", mutate: func(c *Config) { c.SeverityEmoji = "error" }, wantErr: "SEVERITY_EMOJI"},
+		{name: "severity emoji removed", mutate: func(c *Config) { c.SeverityEmoji = "suggestion=,error=🛑" }},
+		{name: "unknown review persona", mutate: func(c *Config) { c.ReviewPersona = "pirate" }, wantErr: "REVIEW_PERSONA"},
+		{name: "digest hour out of range", mutate: func(c *Config) { c.DigestHour = 24 }, wantErr: "DIGEST_HOUR"},
+		{name: "negative review concurrency", mutate: func(c *Config) { c.ReviewConcurrency = -1 }, wantErr: "REVIEW_CONCURRENCY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.mutate(c)
+			err := c.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want mention of %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Package conflicts helps authors of pull requests that can no longer be
+// merged: it finds the files changed on both sides since the branches
+// diverged, has the LLM explain each conflict and, optionally, propose a
+// resolution, and posts the result as a PR comment.
+package conflicts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/logging"
+)
+
+const (
+	// maxFiles bounds the files explained in one comment
+	maxFiles = 5
+	// maxContentBytes skips the LLM for versions larger than this
+	maxContentBytes = 30 * 1024
+	// markerPrefix tags the comment for a head and merge base pair
+	markerPrefix = "<!-- prmate-conflicts:"
+)
+
+// GitHubClient is the GitHub access the helper needs
+type GitHubClient interface {
+	GetPullRequest(ctx context.Context, owner, repo string, prNumber int) (*ghclient.PullRequest, error)
+	CompareFiles(ctx context.Context, owner, repo, base, head string) (string, []string, error)
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
+	ListPRComments(ctx context.Context, owner, repo string, prNumber int) ([]string, error)
+	CreatePRComment(ctx context.Context, owner, repo string, prNumber int, body string) error
+}
+
+// LLMProvider explains conflicts and proposes resolutions
+type LLMProvider interface {
+	GenerateTextWithContext(ctx context.Context, prompt string) (string, error)
+}
+
+// Helper posts conflict explanations on unmergeable pull requests
+type Helper struct {
+	gh        GitHubClient
+	llm       LLMProvider
+	propose   bool
+	polls     int
+	pollEvery time.Duration
+}
+
+// NewHelper returns a helper; llm may be nil to only list the files, and
+// propose adds a suggested resolution diff for each file
+func NewHelper(gh GitHubClient, llm LLMProvider, propose bool) *Helper {
+	return &Helper{gh: gh, llm: llm, propose: propose, polls: 5, pollEvery: 3 * time.Second}
+}
+
+// Check looks at the mergeability of a pull request and, when it has
+// conflicts not yet commented on, posts the explanation. It reports
+// whether a comment was posted.
+func (h *Helper) Check(ctx context.Context, owner, repo string, prNumber int) (bool, error) {
+	pr, err := h.mergeability(ctx, owner, repo, prNumber)
+	if err != nil {
+		return false, err
+	}
+	if pr.MergeableState != "dirty" {
+		return false, nil
+	}
+
+	mergeBase, files, err := h.conflictingFiles(ctx, owner, repo, pr)
+	if err != nil {
+		return false, err
+	}
+
+	// One comment per head and merge base; later pushes to the base
+	// branch alone do not repeat it
+	marker := fmt.Sprintf("%s%s:%s -->", markerPrefix, pr.HeadSHA, mergeBase)
+	comments, err := h.gh.ListPRComments(ctx, owner, repo, prNumber)
+	if err != nil {
+		return false, fmt.Errorf("list pr comments: %w", err)
+	}
+	for _, c := range comments {
+		if strings.Contains(c, marker) {
+			return false, nil
+		}
+	}
+	logging.FromContext(ctx).Info("Pull request has merge conflicts", "base", pr.BaseRef, "files", len(files))
+
+	body := marker + "\n" + h.render(ctx, owner, repo, pr, mergeBase, files)
+	if err := h.gh.CreatePRComment(ctx, owner, repo, prNumber, body); err != nil {
+		return false, fmt.Errorf("post conflict comment: %w", err)
+	}
+	return true, nil
+}
+
+// mergeability fetches the pull request, waiting briefly while GitHub
+// computes whether it can be merged
+func (h *Helper) mergeability(ctx context.Context, owner, repo string, prNumber int) (*ghclient.PullRequest, error) {
+	for attempt := 0; ; attempt++ {
+		pr, err := h.gh.GetPullRequest(ctx, owner, repo, prNumber)
+		if err != nil {
+			return nil, fmt.Errorf("get pull request: %w", err)
+		}
+		if (pr.MergeableState != "" && pr.MergeableState != "unknown") || attempt+1 >= h.polls {
+			return pr, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(h.pollEvery):
+		}
+	}
+}
+
+// conflictingFiles returns the merge base and the files changed both on
+// the base branch and in the pull request since then
+func (h *Helper) conflictingFiles(ctx context.Context, owner, repo string, pr *ghclient.PullRequest) (string, []string, error) {
+	mergeBase, headChanged, err := h.gh.CompareFiles(ctx, owner, repo, pr.BaseRef, pr.HeadSHA)
+	if err != nil {
+		return "", nil, err
+	}
+	_, baseChanged, err := h.gh.CompareFiles(ctx, owner, repo, mergeBase, pr.BaseRef)
+	if err != nil {
+		return "", nil, err
+	}
+
+	onBase := make(map[string]bool, len(baseChanged))
+	for _, f := range baseChanged {
+		onBase[f] = true
+	}
+	var both []string
+	for _, f := range headChanged {
+		if onBase[f] {
+			both = append(both, f)
+		}
+	}
+	return mergeBase, both, nil
+}
+
+// render builds the comment listing and explaining each file
+func (h *Helper) render(ctx context.Context, owner, repo string, pr *ghclient.PullRequest, mergeBase string, files []string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## ⚔️ Merge conflicts with `%s`\n\n", pr.BaseRef)
+	if len(files) == 0 {
+		sb.WriteString("This pull request can no longer be merged cleanly, but no file was changed on both branches; the conflict may involve renamed or deleted files. ")
+		fmt.Fprintf(&sb, "Merge or rebase onto `%s` locally to see it.\n", pr.BaseRef)
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "This pull request can no longer be merged cleanly. These files were changed both on `%s` and in this pull request since they diverged:\n\n", pr.BaseRef)
+	for _, f := range files {
+		fmt.Fprintf(&sb, "- `%s`\n", f)
+	}
+	if h.llm == nil {
+		return sb.String()
+	}
+
+	explained := files
+	if len(explained) > maxFiles {
+		explained = explained[:maxFiles]
+	}
+	for _, f := range explained {
+		fmt.Fprintf(&sb, "\n### `%s`\n\n", f)
+		text, err := h.explain(ctx, owner, repo, f, mergeBase, pr)
+		if err != nil {
+			logging.FromContext(ctx).Warn("could not explain conflict", "path", f, "error", err)
+			fmt.Fprintf(&sb, "_Could not explain this conflict: %v_\n", err)
+			continue
+		}
+		sb.WriteString(text + "\n")
+	}
+	if len(files) > len(explained) {
+		fmt.Fprintf(&sb, "\n_%d more file(s) not explained._\n", len(files)-len(explained))
+	}
+	return sb.String()
+}
+
+// explain asks the LLM what each side changed in path and, when
+// proposing, for a resolution
+func (h *Helper) explain(ctx context.Context, owner, repo, path, mergeBase string, pr *ghclient.PullRequest) (string, error) {
+	versions := make([]string, 3)
+	for i, ref := range []string{mergeBase, pr.BaseRef, pr.HeadSHA} {
+		content, err := h.gh.GetFileContent(ctx, owner, repo, path, ref)
+		if err != nil {
+			// Deleted on one side; the empty version says so
+			content = ""
+		}
+		if len(content) > maxContentBytes {
+			return "", fmt.Errorf("file too large to compare")
+		}
+		versions[i] = content
+	}
+
+	response, err := h.llm.GenerateTextWithContext(ctx, buildPrompt(path, pr.BaseRef, versions[0], versions[1], versions[2], h.propose))
+	if err != nil {
+		return "", err
+	}
+	explanation, diff := splitResolution(response)
+	if diff == "" {
+		return explanation, nil
+	}
+	return fmt.Sprintf("%s\n\n<details>\n<summary>Proposed resolution</summary>\n\n```diff\n%s\n```\n</details>", explanation, diff), nil
+}
+
+func buildPrompt(path, baseRef, ancestor, base, head string, propose bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "A pull request conflicts with its base branch `%s` in the file %s.\n\n", baseRef, path)
+	fmt.Fprintf(&sb, "## Common ancestor\n\n```\n%s\n```\n\n", ancestor)
+	fmt.Fprintf(&sb, "## Version on %s\n\n```\n%s\n```\n\n", baseRef, base)
+	fmt.Fprintf(&sb, "## Version in the pull request\n\n```\n%s\n```\n\n", head)
+	sb.WriteString("An empty version means the file does not exist on that side.\n\n")
+	sb.WriteString("Explain in 2-4 sentences of plain markdown what each side changed relative to the ancestor and why the changes collide. ")
+	sb.WriteString("Do not repeat the file contents.")
+	if propose {
+		sb.WriteString(" Then propose a resolution that keeps the intent of both sides as a unified diff against the pull request version, in a single ```diff fenced block at the end.")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// splitResolution separates the explanation from a trailing ```diff block
+func splitResolution(response string) (explanation, diff string) {
+	response = strings.TrimSpace(response)
+	start := strings.Index(response, "```diff")
+	if start < 0 {
+		return response, ""
+	}
+	rest := response[start+len("```diff"):]
+	end := strings.LastIndex(rest, "```")
+	if end < 0 {
+		return strings.TrimSpace(response[:start]), ""
+	}
+	return strings.TrimSpace(response[:start]), strings.Trim(rest[:end], "\n")
+}
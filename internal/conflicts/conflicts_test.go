@@ -0,0 +1,131 @@
+package conflicts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+type fakeGitHub struct {
+	states   []string // mergeable state per GetPullRequest call
+	calls    int
+	compares map[string][]string // "base...head" -> changed files
+	contents map[string]string   // "ref:path" -> content
+	comments []string
+}
+
+func (f *fakeGitHub) GetPullRequest(ctx context.Context, owner, repo string, prNumber int) (*ghclient.PullRequest, error) {
+	state := f.states[min(f.calls, len(f.states)-1)]
+	f.calls++
+	return &ghclient.PullRequest{Number: prNumber, HeadSHA: "head1", BaseRef: "main", MergeableState: state}, nil
+}
+
+func (f *fakeGitHub) CompareFiles(ctx context.Context, owner, repo, base, head string) (string, []string, error) {
+	return "base0", f.compares[base+"..."+head], nil
+}
+
+func (f *fakeGitHub) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	return f.contents[ref+":"+path], nil
+}
+
+func (f *fakeGitHub) ListPRComments(ctx context.Context, owner, repo string, prNumber int) ([]string, error) {
+	return f.comments, nil
+}
+
+func (f *fakeGitHub) CreatePRComment(ctx context.Context, owner, repo string, prNumber int, body string) error {
+	f.comments = append(f.comments, body)
+	return nil
+}
+
+type fakeLLM struct{ prompts []string }
+
+func (f *fakeLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	f.prompts = append(f.prompts, prompt)
+	return "Both sides renamed the timeout.\n\n```diff\n-a\n+b\n```", nil
+}
+
+func newFake(states ...string) *fakeGitHub {
+	return &fakeGitHub{
+		states: states,
+		compares: map[string][]string{
+			"main...head1": {"config.go", "README.md"},
+			"base0...main": {"config.go", "main.go"},
+		},
+		contents: map[string]string{
+			"base0:config.go": "timeout := 5",
+			"main:config.go":  "timeout := 10",
+			"head1:config.go": "deadline := 5",
+		},
+	}
+}
+
+func TestHelper_Check(t *testing.T) {
+	gh := newFake("unknown", "dirty")
+	llm := &fakeLLM{}
+	h := NewHelper(gh, llm, true)
+	h.pollEvery = 0
+
+	posted, err := h.Check(context.Background(), "acme", "api", 7)
+	if err != nil || !posted {
+		t.Fatalf("Check() = %v, %v", posted, err)
+	}
+	if gh.calls != 2 {
+		t.Errorf("GetPullRequest calls = %d, want 2 (polled past unknown)", gh.calls)
+	}
+	body := gh.comments[0]
+	for _, want := range []string{"<!-- prmate-conflicts:head1:base0 -->", "Merge conflicts with `main`", "- `config.go`", "### `config.go`", "Both sides renamed", "```diff\n-a\n+b\n```"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("comment missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "README.md") || strings.Contains(body, "main.go") {
+		t.Errorf("comment lists files changed on one side only:\n%s", body)
+	}
+	if len(llm.prompts) != 1 || !strings.Contains(llm.prompts[0], "timeout := 10") || !strings.Contains(llm.prompts[0], "deadline := 5") {
+		t.Errorf("prompts = %q", llm.prompts)
+	}
+
+	// The same head and merge base are not commented on twice
+	if posted, err := h.Check(context.Background(), "acme", "api", 7); err != nil || posted {
+		t.Errorf("second Check() = %v, %v, want no comment", posted, err)
+	}
+}
+
+func TestHelper_CheckWithoutConflicts(t *testing.T) {
+	for _, state := range []string{"clean", "blocked", "unknown"} {
+		gh := newFake(state)
+		h := NewHelper(gh, nil, false)
+		h.pollEvery = 0
+		if posted, err := h.Check(context.Background(), "acme", "api", 7); err != nil || posted || len(gh.comments) > 0 {
+			t.Errorf("state %s: Check() = %v, %v, comments %q", state, posted, err, gh.comments)
+		}
+	}
+}
+
+func TestHelper_CheckWithoutLLM(t *testing.T) {
+	gh := newFake("dirty")
+	if _, err := NewHelper(gh, nil, true).Check(context.Background(), "acme", "api", 7); err != nil {
+		t.Fatal(err)
+	}
+	if body := gh.comments[0]; !strings.Contains(body, "- `config.go`") || strings.Contains(body, "###") {
+		t.Errorf("comment = %s", body)
+	}
+}
+
+func TestSplitResolution(t *testing.T) {
+	tests := []struct {
+		response, explanation, diff string
+	}{
+		{response: "Just prose.", explanation: "Just prose."},
+		{response: "Prose.\n```diff\n-a\n+b\n```\n", explanation: "Prose.", diff: "-a\n+b"},
+		{response: "Prose.\n```diff\n-a", explanation: "Prose."},
+	}
+	for _, tt := range tests {
+		explanation, diff := splitResolution(tt.response)
+		if explanation != tt.explanation || diff != tt.diff {
+			t.Errorf("splitResolution(%q) = %q, %q", tt.response, explanation, diff)
+		}
+	}
+}
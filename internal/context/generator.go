@@ -40,6 +40,11 @@ func (g *Generator) Generate(result *scanner.MultiRepoResult) string {
 	// Test Conventions section
 	g.writeTestConventions(&sb, result.CurrentAnalysis)
 
+	// Infrastructure Conventions section
+	if !result.CurrentAnalysis.InfraConventions.Empty() {
+		g.writeInfraConventions(&sb, result.CurrentAnalysis.InfraConventions)
+	}
+
 	// Senior Developer Checklist section
 	g.writeSeniorDevChecklist(&sb)
 
@@ -54,8 +59,14 @@ func (g *Generator) Generate(result *scanner.MultiRepoResult) string {
 	return sb.String()
 }
 
+// writeHeading starts a section, marking what reviews take from it so that
+// they do not depend on the wording of its heading
+func (g *Generator) writeHeading(sb *strings.Builder, title, kinds string) {
+	sb.WriteString(fmt.Sprintf("## %s\n<!-- prmate-section: %s -->\n\n", title, kinds))
+}
+
 func (g *Generator) writeFolderStructure(sb *strings.Builder, ctx *scanner.CodebaseContext, analysis *scanner.AnalysisResult) {
-	sb.WriteString("## Folder Structure\n\n")
+	g.writeHeading(sb, "Folder Structure", "context")
 
 	if len(analysis.FolderConventions) > 0 {
 		for _, conv := range analysis.FolderConventions {
@@ -107,7 +118,7 @@ func (g *Generator) writeFolderStructure(sb *strings.Builder, ctx *scanner.Codeb
 }
 
 func (g *Generator) writeNamingConventions(sb *strings.Builder, analysis *scanner.AnalysisResult) {
-	sb.WriteString("## Naming Conventions\n\n")
+	g.writeHeading(sb, "Naming Conventions", "rules, context")
 
 	sb.WriteString(fmt.Sprintf("- **Folder naming**: %s\n", analysis.FolderNaming))
 	sb.WriteString(fmt.Sprintf("- **File naming**: %s\n", analysis.FileNaming))
@@ -130,7 +141,7 @@ func (g *Generator) writeNamingConventions(sb *strings.Builder, analysis *scanne
 }
 
 func (g *Generator) writeAbstractions(sb *strings.Builder, analysis *scanner.AnalysisResult) {
-	sb.WriteString("## Abstractions\n\n")
+	g.writeHeading(sb, "Abstractions", "context")
 
 	if len(analysis.Abstractions) == 0 {
 		sb.WriteString("*No specific abstraction patterns detected.*\n\n")
@@ -189,7 +200,7 @@ func (g *Generator) writeAbstractions(sb *strings.Builder, analysis *scanner.Ana
 }
 
 func (g *Generator) writeErrorHandling(sb *strings.Builder, analysis *scanner.AnalysisResult) {
-	sb.WriteString("## Error Handling\n\n")
+	g.writeHeading(sb, "Error Handling", "context")
 
 	if len(analysis.ErrorPatterns) == 0 {
 		sb.WriteString("*No specific error patterns detected.*\n\n")
@@ -209,7 +220,7 @@ func (g *Generator) writeErrorHandling(sb *strings.Builder, analysis *scanner.An
 }
 
 func (g *Generator) writeTestConventions(sb *strings.Builder, analysis *scanner.AnalysisResult) {
-	sb.WriteString("## Test Conventions\n\n")
+	g.writeHeading(sb, "Test Conventions", "rules")
 
 	conv := analysis.TestConventions
 
@@ -232,8 +243,48 @@ func (g *Generator) writeTestConventions(sb *strings.Builder, analysis *scanner.
 	sb.WriteString("\n")
 }
 
+// writeInfraConventions lists the container and CI conventions most of the
+// repository's infrastructure files already follow. Reviews enforce the
+// bullets in this section on changed Dockerfiles, compose files and
+// workflows.
+func (g *Generator) writeInfraConventions(sb *strings.Builder, conv scanner.InfraConventions) {
+	g.writeHeading(sb, "Infrastructure Conventions", "infra")
+
+	var files []string
+	files = append(files, conv.Dockerfiles...)
+	files = append(files, conv.ComposeFiles...)
+	files = append(files, conv.Workflows...)
+	if len(files) > 5 {
+		files = append(files[:5], fmt.Sprintf("and %d more", len(files)-5))
+	}
+	sb.WriteString(fmt.Sprintf("**Files:** `%s`\n\n", strings.Join(files, "`, `")))
+
+	rules := 0
+	if conv.BaseImages > 0 && conv.DigestImages*2 > conv.BaseImages {
+		sb.WriteString(fmt.Sprintf("- **Base images**: Pin base images to a digest (`image:tag@sha256:...`) (%d of %d images)\n", conv.DigestImages, conv.BaseImages))
+		rules++
+	} else if conv.BaseImages > 0 && conv.PinnedImages*2 > conv.BaseImages {
+		sb.WriteString(fmt.Sprintf("- **Base images**: Pin base images to a version tag or digest, never `latest` or an untagged image (%d of %d images)\n", conv.PinnedImages, conv.BaseImages))
+		rules++
+	}
+	if n := len(conv.Dockerfiles); n > 0 && conv.NonRootDockerfiles*2 > n {
+		sb.WriteString(fmt.Sprintf("- **Container user**: Run the final image stage as a non-root `USER` (%d of %d Dockerfiles)\n", conv.NonRootDockerfiles, n))
+		rules++
+	}
+	if conv.Actions > 0 && conv.PinnedActions*2 > conv.Actions {
+		sb.WriteString(fmt.Sprintf("- **Action pinning**: Pin actions from other repositories to a full commit SHA with the version in a comment (`uses: owner/action@<sha> # v4`), not a tag or branch (%d of %d actions)\n", conv.PinnedActions, conv.Actions))
+		rules++
+	}
+
+	if rules == 0 {
+		sb.WriteString("*No infrastructure conventions established yet.*\n")
+	}
+
+	sb.WriteString("\n")
+}
+
 func (g *Generator) writeSeniorDevChecklist(sb *strings.Builder) {
-	sb.WriteString("## Senior Developer Review Checklist\n\n")
+	g.writeHeading(sb, "Senior Developer Review Checklist", "checklist")
 
 	checklist := []string{
 		"**File locations**: New files placed in correct folders per conventions above",
@@ -256,7 +307,7 @@ func (g *Generator) writeSeniorDevChecklist(sb *strings.Builder) {
 }
 
 func (g *Generator) writeLearnedRules(sb *strings.Builder, rules []string) {
-	sb.WriteString("## Learned Rules\n\n")
+	g.writeHeading(sb, "Learned Rules", "rules")
 
 	// Deduplicate rules
 	seen := make(map[string]bool)
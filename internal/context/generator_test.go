@@ -59,8 +59,8 @@ func TestGenerator_Generate(t *testing.T) {
 		"## Abstractions",
 		"## Error Handling",
 		"## Test Conventions",
-		"## Senior Developer Review Checklist",
-		"## Learned Rules",
+		"## Senior Developer Review Checklist\n<!-- prmate-section: checklist -->",
+		"## Learned Rules\n<!-- prmate-section: rules -->",
 		"## Sources",
 	}
 
@@ -185,3 +185,40 @@ func TestGenerator_WriteLearnedRules_Deduplication(t *testing.T) {
 		t.Errorf("expected 1 occurrence of 'descriptive names', got %d", count)
 	}
 }
+
+func TestGenerator_WriteInfraConventions(t *testing.T) {
+	generator := NewGenerator()
+	var sb strings.Builder
+	generator.writeInfraConventions(&sb, scanner.InfraConventions{
+		Dockerfiles:        []string{"Dockerfile", "tools/Dockerfile"},
+		Workflows:          []string{".github/workflows/ci.yml"},
+		BaseImages:         4,
+		PinnedImages:       3,
+		NonRootDockerfiles: 1,
+		Actions:            5,
+		PinnedActions:      5,
+	})
+	content := sb.String()
+
+	for _, want := range []string{
+		"## Infrastructure Conventions",
+		"`Dockerfile`, `tools/Dockerfile`, `.github/workflows/ci.yml`",
+		"- **Base images**: Pin base images to a version tag or digest",
+		"(3 of 4 images)",
+		"- **Action pinning**:",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("missing %q:\n%s", want, content)
+		}
+	}
+	// Half the Dockerfiles is not a convention
+	if strings.Contains(content, "Container user") {
+		t.Errorf("unexpected container user convention:\n%s", content)
+	}
+
+	sb.Reset()
+	generator.writeInfraConventions(&sb, scanner.InfraConventions{Dockerfiles: []string{"Dockerfile"}, BaseImages: 1})
+	if !strings.Contains(sb.String(), "No infrastructure conventions established yet") {
+		t.Errorf("expected no conventions:\n%s", sb.String())
+	}
+}
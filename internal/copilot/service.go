@@ -2,30 +2,58 @@ package copilot
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	copilot "github.com/github/copilot-sdk/go"
+	"go.opentelemetry.io/otel/attribute"
+
+	"prmate/internal/llm"
+	"prmate/internal/tracing"
 )
 
+// defaultSendTimeout matches the SDK's own default wait for session.idle
+const defaultSendTimeout = 60 * time.Second
+
 // Service manages Copilot SDK client lifecycle
 type Service struct {
 	client  *copilot.Client
 	model   string
+	timeout time.Duration
 	mu      sync.Mutex
 	wg      sync.WaitGroup
 	started bool
+	ping    *pingCall // the ping in flight, if any
 }
 
+// pingCall is a ping of the Copilot CLI server, shared by the callers
+// waiting for it
+type pingCall struct {
+	done chan struct{} // closed once err is set
+	err  error
+}
+
+var _ llm.StreamingCompleter = (*Service)(nil)
+
 // NewService creates a new Copilot service
 func NewService(model string) *Service {
 	if model == "" {
 		model = "gpt-5-mini"
 	}
 	return &Service{
-		client: copilot.NewClient(nil),
-		model:  model,
+		client:  copilot.NewClient(nil),
+		model:   model,
+		timeout: defaultSendTimeout,
+	}
+}
+
+// SetTimeout overrides how long a single prompt may wait for a response
+func (s *Service) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		s.timeout = timeout
 	}
 }
 
@@ -65,11 +93,83 @@ func (s *Service) Stop() error {
 	return nil
 }
 
-func (s *Service) createSession() (*copilot.Session, error) {
-	session, err := s.client.CreateSession(&copilot.SessionConfig{
-		Model:     s.model,
+// Ping verifies the Copilot CLI server is connected and responding. The
+// SDK's ping cannot be cancelled, so callers giving up leave it running;
+// while it does, later callers wait for it rather than starting another.
+func (s *Service) Ping(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return fmt.Errorf("copilot service not started")
+	}
+	call := s.ping
+	if call == nil {
+		call = &pingCall{done: make(chan struct{})}
+		s.ping = call
+		go func() {
+			_, call.err = s.client.Ping("")
+			s.mu.Lock()
+			s.ping = nil
+			s.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("ping copilot: %w", ctx.Err())
+	case <-call.done:
+		if call.err != nil {
+			return fmt.Errorf("ping copilot: %w", call.err)
+		}
+		return nil
+	}
+}
+
+// ListModels returns the models the Copilot CLI server offers
+func (s *Service) ListModels(ctx context.Context) ([]llm.Model, error) {
+	s.mu.Lock()
+	started := s.started
+	s.mu.Unlock()
+	if !started {
+		return nil, fmt.Errorf("copilot service not started")
+	}
+
+	type listResult struct {
+		models []copilot.ModelInfo
+		err    error
+	}
+	resCh := make(chan listResult, 1)
+	go func() {
+		models, err := s.client.ListModels()
+		resCh <- listResult{models, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("list copilot models: %w", ctx.Err())
+	case res := <-resCh:
+		if res.err != nil {
+			return nil, fmt.Errorf("list copilot models: %w", res.err)
+		}
+		models := make([]llm.Model, len(res.models))
+		for i, m := range res.models {
+			models[i] = llm.Model{ID: m.ID, Name: m.Name}
+		}
+		return models, nil
+	}
+}
+
+func (s *Service) createSession(model, system string) (*copilot.Session, error) {
+	cfg := &copilot.SessionConfig{
+		Model:     model,
 		Streaming: true,
-	})
+	}
+	if system != "" {
+		cfg.SystemMessage = &copilot.SystemMessageConfig{Mode: "append", Content: system}
+	}
+	session, err := s.client.CreateSession(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
@@ -80,38 +180,169 @@ func (s *Service) createSession() (*copilot.Session, error) {
 // GenerateText generates text from a prompt.
 // This is the API HTTP handlers should use (no Copilot SDK types leak outside this package).
 func (s *Service) GenerateText(prompt string) (string, error) {
+	return s.GenerateTextWithContext(context.Background(), prompt)
+}
+
+// GenerateTextWithContext generates text, aborting the session when ctx is
+// cancelled or its deadline passes
+func (s *Service) GenerateTextWithContext(ctx context.Context, prompt string) (_ string, err error) {
+	model := llm.ModelFor(ctx, s.model)
+	ctx, span := tracing.Start(ctx, "llm.generate",
+		attribute.String("llm.provider", "copilot"),
+		attribute.String("llm.model", model),
+		attribute.Int("llm.prompt_bytes", len(prompt)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	return s.collect(ctx, model, "", prompt)
+}
+
+// Chat sends a conversation as one session: system messages extend the
+// session's system message and the other turns make up the prompt
+func (s *Service) Chat(ctx context.Context, messages []llm.Message) (_ string, err error) {
+	model := llm.ModelFor(ctx, s.model)
+	ctx, span := tracing.Start(ctx, "llm.chat",
+		attribute.String("llm.provider", "copilot"),
+		attribute.String("llm.model", model),
+		attribute.Int("llm.messages", len(messages)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	system, prompt := conversation(messages)
+	return s.collect(ctx, model, system, prompt)
+}
+
+// ChatStream is Chat, passing the reply to handler as it is generated
+func (s *Service) ChatStream(ctx context.Context, messages []llm.Message, handler llm.StreamHandler) (err error) {
+	model := llm.ModelFor(ctx, s.model)
+	ctx, span := tracing.Start(ctx, "llm.chat",
+		attribute.String("llm.provider", "copilot"),
+		attribute.String("llm.model", model),
+		attribute.Int("llm.messages", len(messages)),
+		attribute.Bool("llm.stream", true),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	system, prompt := conversation(messages)
+	return s.send(ctx, model, system, prompt, handler)
+}
+
+// collect sends prompt and returns the whole reply
+func (s *Service) collect(ctx context.Context, model, system, prompt string) (string, error) {
+	var responseBuffer bytes.Buffer
+	err := s.send(ctx, model, system, prompt, func(chunk string) error {
+		responseBuffer.WriteString(chunk)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(responseBuffer.String()), nil
+}
+
+// send sends prompt in a new session and passes each delta of the reply to
+// handler, one at a time. The session is aborted when ctx is cancelled or
+// its deadline passes, or when handler fails.
+func (s *Service) send(ctx context.Context, model, system, prompt string, handler llm.StreamHandler) error {
+	timeout, err := s.sendTimeout(ctx)
+	if err != nil {
+		return err
+	}
+
 	s.mu.Lock()
 	if !s.started {
 		s.mu.Unlock()
-		return "", fmt.Errorf("copilot service not started")
+		return fmt.Errorf("copilot service not started")
 	}
 	s.wg.Add(1)
 	s.mu.Unlock()
 	defer s.wg.Done()
 
-	session, err := s.createSession()
+	session, err := s.createSession(model, system)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	var responseMu sync.Mutex
-	var responseBuffer bytes.Buffer
+	var handlerMu sync.Mutex
+	var handlerErr error
 	session.On(func(event copilot.SessionEvent) {
-		if event.Type == "assistant.message_delta" && event.Data.DeltaContent != nil {
-			responseMu.Lock()
-			responseBuffer.WriteString(*event.Data.DeltaContent)
-			responseMu.Unlock()
+		if event.Type != "assistant.message_delta" || event.Data.DeltaContent == nil {
+			return
+		}
+		handlerMu.Lock()
+		defer handlerMu.Unlock()
+		if handlerErr != nil {
+			return
+		}
+		if handlerErr = handler(*event.Data.DeltaContent); handlerErr != nil {
+			_ = session.Abort()
 		}
 	})
 
-	_, err = session.SendAndWait(copilot.MessageOptions{Prompt: prompt}, 0)
+	stopAbort := context.AfterFunc(ctx, func() {
+		_ = session.Abort()
+	})
+	defer stopAbort()
+
+	_, err = session.SendAndWait(copilot.MessageOptions{Prompt: prompt}, timeout)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("failed to send prompt: %w", llm.ClassifyTimeout(ctxErr))
+	}
+	handlerMu.Lock()
+	defer handlerMu.Unlock()
+	if handlerErr != nil {
+		return handlerErr
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to send prompt: %w", err)
+		return fmt.Errorf("failed to send prompt: %w", err)
+	}
+	return nil
+}
+
+// conversation splits messages into the system message of a session and
+// its prompt. A single turn is sent as it is; earlier turns of a longer
+// conversation are quoted before the last.
+func conversation(messages []llm.Message) (system, prompt string) {
+	var systems []string
+	var turns []llm.Message
+	for _, m := range messages {
+		if m.Role == "system" {
+			systems = append(systems, m.Content)
+			continue
+		}
+		turns = append(turns, m)
+	}
+	system = strings.Join(systems, "\n\n")
+	if len(turns) == 1 {
+		return system, turns[0].Content
 	}
 
-	responseMu.Lock()
-	out := strings.TrimSpace(responseBuffer.String())
-	responseMu.Unlock()
+	var sb strings.Builder
+	for i, m := range turns {
+		if i == len(turns)-1 {
+			sb.WriteString(m.Content)
+			break
+		}
+		fmt.Fprintf(&sb, "%s: %s\n\n", m.Role, m.Content)
+	}
+	return system, sb.String()
+}
+
+// sendTimeout returns the configured timeout, shortened to ctx's deadline
+func (s *Service) sendTimeout(ctx context.Context) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("failed to send prompt: %w", llm.ClassifyTimeout(err))
+	}
+
+	timeout := s.timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < timeout {
+			timeout = remaining
+		}
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("failed to send prompt: %w", llm.ClassifyTimeout(context.DeadlineExceeded))
+	}
 
-	return out, nil
+	return timeout, nil
 }
@@ -0,0 +1,82 @@
+// Package correlation carries a per-webhook correlation ID through job
+// processing, logs, traces and the GitHub comments PRMate writes, so a
+// comment on a PR can be traced back to the logs that produced it.
+package correlation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+// Header is the HTTP header used to accept and echo correlation IDs
+const Header = "X-Correlation-ID"
+
+const (
+	markerPrefix = "<!-- prmate-correlation-id:"
+	markerSuffix = " -->"
+)
+
+var (
+	markerPattern = regexp.MustCompile(`<!-- prmate-correlation-id:([A-Za-z0-9._-]+) -->`)
+	idPattern     = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+)
+
+type ctxKey struct{}
+
+// NewID returns a random 16-byte hex ID, or fallback if randomness is unavailable
+func NewID(fallback string) string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fallback
+	}
+	return hex.EncodeToString(b)
+}
+
+// Valid reports whether id is safe to log and embed in a comment marker:
+// 1 to 64 letters, digits, dots, underscores or dashes
+func Valid(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// WithID returns a context carrying id
+func WithID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the correlation ID in ctx, or "" if none
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// Marker returns the hidden HTML comment embedding id in a GitHub comment
+func Marker(id string) string {
+	return fmt.Sprintf("%s%s%s", markerPrefix, id, markerSuffix)
+}
+
+// AppendMarker appends the hidden marker for ctx's correlation ID to body
+func AppendMarker(ctx context.Context, body string) string {
+	id := FromContext(ctx)
+	if id == "" || markerPattern.MatchString(body) {
+		return body
+	}
+	return body + "\n" + Marker(id)
+}
+
+// ParseMarker extracts a correlation ID from a comment body
+func ParseMarker(body string) (string, bool) {
+	m := markerPattern.FindStringSubmatch(body)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
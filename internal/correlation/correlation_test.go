@@ -0,0 +1,63 @@
+package correlation
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNewID(t *testing.T) {
+	a := NewID("fallback")
+	b := NewID("fallback")
+
+	if len(a) != 32 {
+		t.Errorf("len(NewID()) = %d, want 32", len(a))
+	}
+	if a == b {
+		t.Error("NewID() should return unique IDs")
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{id: "abc-123", want: true},
+		{id: "72d3162e-cc78-11e3-81ab-4c9367dc0958", want: true},
+		{id: "v1.2_x", want: true},
+		{id: "", want: false},
+		{id: strings.Repeat("a", 65), want: false},
+		{id: "abc -->", want: false},
+		{id: "abc\nforged=1", want: false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestAppendMarker_RoundTrip(t *testing.T) {
+	ctx := WithID(context.Background(), "abc-123")
+
+	body := AppendMarker(ctx, "✅ PRMate scan completed.")
+	if !strings.HasPrefix(body, "✅ PRMate scan completed.") {
+		t.Errorf("body should keep original text, got %q", body)
+	}
+
+	id, ok := ParseMarker(body)
+	if !ok || id != "abc-123" {
+		t.Errorf("ParseMarker() = %q, %v; want abc-123, true", id, ok)
+	}
+
+	if again := AppendMarker(ctx, body); again != body {
+		t.Error("AppendMarker should not add a second marker")
+	}
+}
+
+func TestAppendMarker_NoID(t *testing.T) {
+	if got := AppendMarker(context.Background(), "body"); got != "body" {
+		t.Errorf("AppendMarker() without id = %q, want unchanged", got)
+	}
+}
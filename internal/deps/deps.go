@@ -0,0 +1,309 @@
+// Package deps reviews the dependency changes of a pull request: for each
+// changed go.mod, package.json or requirements.txt it lists added, removed
+// and upgraded dependencies, flags major version bumps, deprecated,
+// unmaintained and duplicate dependencies, and notes license changes.
+package deps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/logging"
+)
+
+const (
+	// markerPrefix tags the comment with a hash of its content, so the same
+	// changes are not reported twice
+	markerPrefix = "<!-- prmate-deps:"
+	// unmaintainedAfter is how long without a release marks a package as
+	// unmaintained
+	unmaintainedAfter = 2 * 365 * 24 * time.Hour
+	// maxLookups bounds the registry requests for one pull request
+	maxLookups = 40
+)
+
+// GitHubClient is the GitHub access the reviewer needs
+type GitHubClient interface {
+	GetPullRequest(ctx context.Context, owner, repo string, prNumber int) (*ghclient.PullRequest, error)
+	GetPRFiles(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.PRFile, error)
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
+	ListPRComments(ctx context.Context, owner, repo string, prNumber int) ([]string, error)
+	CreatePRComment(ctx context.Context, owner, repo string, prNumber int, body string) error
+}
+
+// Registry looks up package metadata; DepsDev satisfies it
+type Registry interface {
+	Release(ctx context.Context, ecosystem, name, version string) (*Release, error)
+	Package(ctx context.Context, ecosystem, name string) (*Package, error)
+}
+
+// Finding is a dependency change with what the registry knows about it
+type Finding struct {
+	Change
+	Licenses     []string // of the new version
+	OldLicenses  []string // of the previous version, for upgrades
+	Deprecated   bool
+	LastRelease  time.Time // zero when unknown
+	Unmaintained bool
+}
+
+// LicenseChanged reports whether an upgrade changed the license
+func (f Finding) LicenseChanged() bool {
+	return f.OldLicenses != nil && f.Licenses != nil && strings.Join(f.OldLicenses, ",") != strings.Join(f.Licenses, ",")
+}
+
+// FileReport covers one changed manifest
+type FileReport struct {
+	Path       string
+	Findings   []Finding
+	Duplicates []string // duplicates this pull request introduced
+	Err        string   // why the manifest could not be read
+}
+
+// Reviewer comments on pull requests that change dependencies
+type Reviewer struct {
+	gh       GitHubClient
+	registry Registry
+	now      func() time.Time
+}
+
+// NewReviewer returns a reviewer; registry may be nil to skip license and
+// maintenance lookups
+func NewReviewer(gh GitHubClient, registry Registry) *Reviewer {
+	return &Reviewer{gh: gh, registry: registry, now: time.Now}
+}
+
+// Review reports the dependency changes of a pull request in a comment,
+// unless the same report was already posted. It reports whether a comment
+// was posted.
+func (r *Reviewer) Review(ctx context.Context, owner, repo string, prNumber int) (bool, error) {
+	pr, err := r.gh.GetPullRequest(ctx, owner, repo, prNumber)
+	if err != nil {
+		return false, fmt.Errorf("get pull request: %w", err)
+	}
+	files, err := r.gh.GetPRFiles(ctx, owner, repo, prNumber)
+	if err != nil {
+		return false, fmt.Errorf("get pr files: %w", err)
+	}
+
+	var reports []FileReport
+	lookups := maxLookups
+	for _, f := range files {
+		ecosystem := Ecosystem(f.Filename)
+		if ecosystem == "" {
+			continue
+		}
+		report, err := r.reviewFile(ctx, owner, repo, pr, f, ecosystem, &lookups)
+		if err != nil {
+			return false, err
+		}
+		if report.Err != "" || len(report.Findings) > 0 || len(report.Duplicates) > 0 {
+			reports = append(reports, *report)
+		}
+	}
+	if len(reports) == 0 {
+		return false, nil
+	}
+
+	body := Render(reports)
+	sum := sha256.Sum256([]byte(body))
+	marker := markerPrefix + hex.EncodeToString(sum[:6]) + " -->"
+	comments, err := r.gh.ListPRComments(ctx, owner, repo, prNumber)
+	if err != nil {
+		return false, fmt.Errorf("list pr comments: %w", err)
+	}
+	for _, c := range comments {
+		if strings.Contains(c, marker) {
+			return false, nil
+		}
+	}
+	logging.FromContext(ctx).Info("Reporting dependency changes", "manifests", len(reports))
+
+	if err := r.gh.CreatePRComment(ctx, owner, repo, prNumber, marker+"\n"+body); err != nil {
+		return false, fmt.Errorf("post dependency comment: %w", err)
+	}
+	return true, nil
+}
+
+// reviewFile diffs one manifest between the base and head of the pull
+// request, spending at most *lookups registry requests
+func (r *Reviewer) reviewFile(ctx context.Context, owner, repo string, pr *ghclient.PullRequest, f ghclient.PRFile, ecosystem string, lookups *int) (*FileReport, error) {
+	report := &FileReport{Path: f.Filename}
+
+	var before, after string
+	if f.Status != "added" {
+		content, err := r.gh.GetFileContent(ctx, owner, repo, f.Filename, pr.BaseSHA)
+		if err != nil {
+			return nil, fmt.Errorf("get %s at base: %w", f.Filename, err)
+		}
+		before = content
+	}
+	if f.Status != "removed" {
+		content, err := r.gh.GetFileContent(ctx, owner, repo, f.Filename, pr.HeadSHA)
+		if err != nil {
+			return nil, fmt.Errorf("get %s at head: %w", f.Filename, err)
+		}
+		after = content
+	}
+
+	old, err := Parse(ecosystem, before)
+	if err != nil {
+		// A broken base manifest is not this pull request's doing
+		old, _ = Parse(ecosystem, "")
+	}
+	cur, err := Parse(ecosystem, after)
+	if err != nil {
+		report.Err = err.Error()
+		return report, nil
+	}
+
+	known := make(map[string]bool, len(old.Duplicates))
+	for _, d := range old.Duplicates {
+		known[d] = true
+	}
+	for _, d := range cur.Duplicates {
+		if !known[d] {
+			report.Duplicates = append(report.Duplicates, d)
+		}
+	}
+
+	for _, c := range Diff(old, cur) {
+		finding := Finding{Change: c}
+		if c.Kind != Removed && r.registry != nil && *lookups > 0 {
+			r.lookUp(ctx, ecosystem, &finding, lookups)
+		}
+		report.Findings = append(report.Findings, finding)
+	}
+	return report, nil
+}
+
+// lookUp fills in registry data; failures only leave it unknown
+func (r *Reviewer) lookUp(ctx context.Context, ecosystem string, f *Finding, lookups *int) {
+	logger := logging.FromContext(ctx)
+
+	*lookups--
+	if pkg, err := r.registry.Package(ctx, ecosystem, f.Name); err != nil {
+		logger.Warn("dependency lookup failed", "dependency", f.Name, "error", err)
+	} else if !pkg.LatestRelease.IsZero() {
+		f.LastRelease = pkg.LatestRelease
+		f.Unmaintained = r.now().Sub(pkg.LatestRelease) > unmaintainedAfter
+	}
+
+	if f.To == "" || *lookups == 0 {
+		return
+	}
+	*lookups--
+	release, err := r.registry.Release(ctx, ecosystem, f.Name, f.To)
+	if err != nil {
+		logger.Warn("dependency lookup failed", "dependency", f.Name, "version", f.To, "error", err)
+		return
+	}
+	f.Licenses = nonNil(release.Licenses)
+	f.Deprecated = release.Deprecated
+
+	if f.Kind == Added || f.From == "" || *lookups == 0 {
+		return
+	}
+	*lookups--
+	if old, err := r.registry.Release(ctx, ecosystem, f.Name, f.From); err == nil {
+		f.OldLicenses = nonNil(old.Licenses)
+	}
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// Render builds the comment body for the reports
+func Render(reports []FileReport) string {
+	var sb strings.Builder
+	sb.WriteString("## 📦 Dependency changes\n")
+	for _, r := range reports {
+		fmt.Fprintf(&sb, "\n### `%s`\n\n", r.Path)
+		if r.Err != "" {
+			fmt.Fprintf(&sb, "_Could not read this manifest: %s_\n", r.Err)
+			continue
+		}
+		if len(r.Findings) > 0 {
+			sb.WriteString("| Dependency | Change | License | Notes |\n|---|---|---|---|\n")
+			for _, f := range r.Findings {
+				fmt.Fprintf(&sb, "| `%s` | %s | %s | %s |\n", f.Name, changeText(f.Change), licenseText(f), strings.Join(notes(f), "; "))
+			}
+		}
+		if len(r.Duplicates) > 0 {
+			fmt.Fprintf(&sb, "\n⚠️ Listed more than once or under several major versions: %s\n", codeList(r.Duplicates))
+		}
+	}
+	return sb.String()
+}
+
+func changeText(c Change) string {
+	switch c.Kind {
+	case Added:
+		return "added " + orAny(c.To)
+	case Removed:
+		return "removed"
+	}
+	return fmt.Sprintf("%s → %s", orAny(c.From), orAny(c.To))
+}
+
+func orAny(v string) string {
+	if v == "" {
+		return "(unpinned)"
+	}
+	return v
+}
+
+func licenseText(f Finding) string {
+	if f.Licenses == nil {
+		return ""
+	}
+	now := "unknown"
+	if len(f.Licenses) > 0 {
+		now = strings.Join(f.Licenses, ", ")
+	}
+	if f.LicenseChanged() {
+		was := "unknown"
+		if len(f.OldLicenses) > 0 {
+			was = strings.Join(f.OldLicenses, ", ")
+		}
+		return was + " → " + now
+	}
+	return now
+}
+
+func notes(f Finding) []string {
+	var out []string
+	if f.Major {
+		out = append(out, "⚠️ major version bump")
+	}
+	if f.Kind == Downgraded {
+		out = append(out, "downgrade")
+	}
+	if f.Deprecated {
+		out = append(out, "⚠️ deprecated")
+	}
+	if f.Unmaintained {
+		out = append(out, fmt.Sprintf("⚠️ no release since %s", f.LastRelease.Format("2006-01")))
+	}
+	if f.LicenseChanged() {
+		out = append(out, "⚠️ license changed")
+	}
+	return out
+}
+
+func codeList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = "`" + item + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
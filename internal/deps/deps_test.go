@@ -0,0 +1,174 @@
+package deps
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ghclient "prmate/internal/github"
+)
+
+type fakeGitHub struct {
+	files    []ghclient.PRFile
+	contents map[string]string // "ref:path" -> content
+	comments []string
+}
+
+func (f *fakeGitHub) GetPullRequest(ctx context.Context, owner, repo string, prNumber int) (*ghclient.PullRequest, error) {
+	return &ghclient.PullRequest{Number: prNumber, BaseSHA: "base", HeadSHA: "head"}, nil
+}
+
+func (f *fakeGitHub) GetPRFiles(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.PRFile, error) {
+	return f.files, nil
+}
+
+func (f *fakeGitHub) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	content, ok := f.contents[ref+":"+path]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return content, nil
+}
+
+func (f *fakeGitHub) ListPRComments(ctx context.Context, owner, repo string, prNumber int) ([]string, error) {
+	return f.comments, nil
+}
+
+func (f *fakeGitHub) CreatePRComment(ctx context.Context, owner, repo string, prNumber int, body string) error {
+	f.comments = append(f.comments, body)
+	return nil
+}
+
+type fakeRegistry struct {
+	releases map[string]Release   // "name@version"
+	packages map[string]time.Time // name -> latest release
+}
+
+func (f *fakeRegistry) Release(ctx context.Context, ecosystem, name, version string) (*Release, error) {
+	r, ok := f.releases[name+"@"+version]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &r, nil
+}
+
+func (f *fakeRegistry) Package(ctx context.Context, ecosystem, name string) (*Package, error) {
+	return &Package{LatestRelease: f.packages[name]}, nil
+}
+
+func TestReviewer_Review(t *testing.T) {
+	gh := &fakeGitHub{
+		files: []ghclient.PRFile{{Filename: "main.go", Status: "modified"}, {Filename: "web/package.json", Status: "modified"}},
+		contents: map[string]string{
+			"base:web/package.json": `{"dependencies": {"left-pad": "1.0.0", "lodash": "4.17.0", "moment": "2.29.0"}}`,
+			"head:web/package.json": `{"dependencies": {"left-pad": "2.0.0", "lodash": "4.17.21", "axios": "1.6.0"}, "devDependencies": {"lodash": "4.17.21"}}`,
+		},
+	}
+	registry := &fakeRegistry{
+		releases: map[string]Release{
+			"left-pad@2.0.0": {Licenses: []string{"WTFPL"}, Deprecated: true},
+			"left-pad@1.0.0": {Licenses: []string{"MIT"}},
+			"lodash@4.17.21": {Licenses: []string{"MIT"}},
+			"lodash@4.17.0":  {Licenses: []string{"MIT"}},
+			"axios@1.6.0":    {Licenses: []string{"MIT"}},
+		},
+		packages: map[string]time.Time{
+			"left-pad": time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+			"lodash":   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	r := NewReviewer(gh, registry)
+	r.now = func() time.Time { return time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC) }
+
+	posted, err := r.Review(context.Background(), "acme", "web", 3)
+	if err != nil || !posted {
+		t.Fatalf("Review() = %v, %v", posted, err)
+	}
+	body := gh.comments[0]
+	for _, want := range []string{
+		markerPrefix,
+		"### `web/package.json`",
+		"| `axios` | added 1.6.0 | MIT |",
+		"| `left-pad` | 1.0.0 → 2.0.0 | MIT → WTFPL | ⚠️ major version bump; ⚠️ deprecated; ⚠️ no release since 2019-03; ⚠️ license changed |",
+		"| `lodash` | 4.17.0 → 4.17.21 | MIT |  |",
+		"| `moment` | removed |  |  |",
+		"several major versions: `lodash`",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("comment missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "main.go") {
+		t.Errorf("comment mentions a file that is not a manifest:\n%s", body)
+	}
+
+	// The same changes are not reported again
+	if posted, err := r.Review(context.Background(), "acme", "web", 3); err != nil || posted {
+		t.Errorf("second Review() = %v, %v, want no new comment", posted, err)
+	}
+}
+
+func TestReviewer_Review_NoManifestChanges(t *testing.T) {
+	gh := &fakeGitHub{
+		files: []ghclient.PRFile{{Filename: "go.mod", Status: "modified"}},
+		contents: map[string]string{
+			"base:go.mod": "module a\n\ngo 1.21\n\nrequire github.com/a/b v1.0.0\n",
+			"head:go.mod": "module a\n\ngo 1.22\n\nrequire github.com/a/b v1.0.0\n",
+		},
+	}
+	posted, err := NewReviewer(gh, nil).Review(context.Background(), "acme", "api", 1)
+	if err != nil || posted {
+		t.Errorf("Review() = %v, %v, want no comment when no dependency changed", posted, err)
+	}
+}
+
+func TestReviewer_Review_AddedManifest(t *testing.T) {
+	gh := &fakeGitHub{
+		files:    []ghclient.PRFile{{Filename: "tools/requirements.txt", Status: "added"}},
+		contents: map[string]string{"head:tools/requirements.txt": "requests==2.31.0\n"},
+	}
+	posted, err := NewReviewer(gh, nil).Review(context.Background(), "acme", "api", 1)
+	if err != nil || !posted {
+		t.Fatalf("Review() = %v, %v", posted, err)
+	}
+	if !strings.Contains(gh.comments[0], "| `requests` | added 2.31.0 |  |  |") {
+		t.Errorf("comment = %s", gh.comments[0])
+	}
+}
+
+func TestDepsDev(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/v3/systems/NPM/packages/@scope%2Fui":
+			w.Write([]byte(`{"versions": [{"publishedAt": "2021-05-01T00:00:00Z"}, {"publishedAt": "2023-02-01T00:00:00Z"}]}`))
+		case "/v3/systems/NPM/packages/@scope%2Fui/versions/1.4.0":
+			w.Write([]byte(`{"licenses": ["MIT"], "isDeprecated": true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	d := NewDepsDev(srv.URL + "/")
+	pkg, err := d.Package(context.Background(), NPM, "@scope/ui")
+	if err != nil {
+		t.Fatalf("Package() error = %v", err)
+	}
+	if want := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC); !pkg.LatestRelease.Equal(want) {
+		t.Errorf("LatestRelease = %v, want %v", pkg.LatestRelease, want)
+	}
+	release, err := d.Release(context.Background(), NPM, "@scope/ui", "1.4.0")
+	if err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if len(release.Licenses) != 1 || release.Licenses[0] != "MIT" || !release.Deprecated {
+		t.Errorf("Release() = %+v", release)
+	}
+	if _, err := d.Release(context.Background(), NPM, "missing", "1.0.0"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("Release() error = %v, want a 404", err)
+	}
+}
@@ -0,0 +1,309 @@
+package deps
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Ecosystems, named as deps.dev names them
+const (
+	Go   = "GO"
+	NPM  = "NPM"
+	PyPI = "PYPI"
+)
+
+// Dependency is one entry of a manifest
+type Dependency struct {
+	Name    string
+	Version string // as written, without range operators; empty when unpinned
+}
+
+// Manifest is a parsed dependency file
+type Manifest struct {
+	Ecosystem  string
+	Deps       map[string]Dependency
+	Duplicates []string // names listed more than once, or under several major versions
+}
+
+// Ecosystem returns the ecosystem of a manifest file, or "" when the file
+// is not a manifest PRMate reads
+func Ecosystem(file string) string {
+	switch path.Base(file) {
+	case "go.mod":
+		return Go
+	case "package.json":
+		return NPM
+	case "requirements.txt":
+		return PyPI
+	}
+	return ""
+}
+
+// Parse reads the dependencies of a manifest of the given ecosystem; empty
+// content parses to an empty manifest
+func Parse(ecosystem, content string) (*Manifest, error) {
+	m := &Manifest{Ecosystem: ecosystem, Deps: make(map[string]Dependency)}
+	if strings.TrimSpace(content) == "" {
+		return m, nil
+	}
+	switch ecosystem {
+	case Go:
+		parseGoMod(m, content)
+	case NPM:
+		if err := parsePackageJSON(m, content); err != nil {
+			return nil, err
+		}
+	case PyPI:
+		parseRequirements(m, content)
+	default:
+		return nil, fmt.Errorf("unknown ecosystem %q", ecosystem)
+	}
+	sort.Strings(m.Duplicates)
+	return m, nil
+}
+
+func (m *Manifest) add(d Dependency) {
+	if _, ok := m.Deps[d.Name]; ok {
+		m.Duplicates = append(m.Duplicates, d.Name)
+	}
+	m.Deps[d.Name] = d
+}
+
+// goMajorSuffix matches the /vN suffix of a Go module path
+var goMajorSuffix = regexp.MustCompile(`/v[0-9]+$`)
+
+// parseGoMod reads the direct requirements of a go.mod file
+func parseGoMod(m *Manifest, content string) {
+	inBlock := false
+	bases := make(map[string]int)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.Contains(line, "// indirect") {
+			continue
+		}
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		var fields []string
+		switch {
+		case line == "require (":
+			inBlock = true
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case inBlock:
+			fields = strings.Fields(line)
+		case strings.HasPrefix(line, "require "):
+			fields = strings.Fields(strings.TrimPrefix(line, "require "))
+		}
+		if len(fields) != 2 {
+			continue
+		}
+
+		m.add(Dependency{Name: fields[0], Version: fields[1]})
+		base := goMajorSuffix.ReplaceAllString(fields[0], "")
+		if bases[base]++; bases[base] == 2 {
+			m.Duplicates = append(m.Duplicates, base)
+		}
+	}
+}
+
+// parsePackageJSON reads the runtime and development dependencies of a
+// package.json file
+func parsePackageJSON(m *Manifest, content string) error {
+	var pkg struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	if err := json.Unmarshal([]byte(content), &pkg); err != nil {
+		return fmt.Errorf("parse package.json: %w", err)
+	}
+	for _, group := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.OptionalDependencies} {
+		names := make([]string, 0, len(group))
+		for name := range group {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			m.add(Dependency{Name: name, Version: npmVersion(group[name])})
+		}
+	}
+	return nil
+}
+
+// npmVersion strips range operators from a simple npm version range;
+// anything else (URLs, tags, compound ranges) is left unpinned
+func npmVersion(spec string) string {
+	v := strings.TrimLeft(strings.TrimSpace(spec), "^~=v")
+	v = strings.TrimPrefix(v, ">=")
+	if _, ok := parseVersion(v); !ok {
+		return ""
+	}
+	return v
+}
+
+// requirementLine splits a requirements.txt entry into name and specifier
+var requirementLine = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(\[[^\]]*\])?\s*(.*)$`)
+
+// parseRequirements reads a pip requirements file; only == pins carry a
+// version
+func parseRequirements(m *Manifest, content string) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+		match := requirementLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		name := strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(match[1]))
+		version := ""
+		if spec := strings.TrimSpace(match[3]); strings.HasPrefix(spec, "==") && !strings.Contains(spec, ",") {
+			version = strings.TrimSpace(strings.TrimPrefix(spec, "=="))
+		}
+		m.add(Dependency{Name: name, Version: version})
+	}
+}
+
+// Change kinds
+const (
+	Added      = "added"
+	Removed    = "removed"
+	Upgraded   = "upgraded"
+	Downgraded = "downgraded"
+	Changed    = "changed" // versions that do not compare
+)
+
+// Change is one dependency that differs between two manifests
+type Change struct {
+	Name  string
+	Kind  string
+	From  string
+	To    string
+	Major bool // the major version changed (the minor one for 0.x versions)
+}
+
+// Diff lists the dependencies that differ from before to after, by name
+func Diff(before, after *Manifest) []Change {
+	var changes []Change
+	for name, dep := range after.Deps {
+		old, ok := before.Deps[name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Name: name, Kind: Added, To: dep.Version})
+		case old.Version != dep.Version:
+			changes = append(changes, versionChange(name, old.Version, dep.Version))
+		}
+	}
+	for name, dep := range before.Deps {
+		if _, ok := after.Deps[name]; !ok {
+			changes = append(changes, Change{Name: name, Kind: Removed, From: dep.Version})
+		}
+	}
+
+	// A Go module moving to a new major version changes its path
+	if after.Ecosystem == Go {
+		changes = foldGoMajors(changes)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+func versionChange(name, from, to string) Change {
+	c := Change{Name: name, Kind: Changed, From: from, To: to}
+	a, okA := parseVersion(from)
+	b, okB := parseVersion(to)
+	if !okA || !okB {
+		return c
+	}
+	if compareVersions(b, a) > 0 {
+		c.Kind = Upgraded
+	} else {
+		c.Kind = Downgraded
+	}
+	c.Major = a[0] != b[0] || (a[0] == 0 && a[1] != b[1])
+	return c
+}
+
+// foldGoMajors turns a removed module and an added /vN of it into a
+// single major upgrade
+func foldGoMajors(changes []Change) []Change {
+	removed := make(map[string]int)
+	for i, c := range changes {
+		if c.Kind == Removed {
+			removed[goMajorSuffix.ReplaceAllString(c.Name, "")] = i
+		}
+	}
+	drop := make(map[int]bool)
+	for i, c := range changes {
+		if c.Kind != Added {
+			continue
+		}
+		j, ok := removed[goMajorSuffix.ReplaceAllString(c.Name, "")]
+		if !ok || drop[j] {
+			continue
+		}
+		changes[i] = Change{Name: c.Name, Kind: Upgraded, From: changes[j].From, To: c.To, Major: true}
+		drop[j] = true
+	}
+	folded := changes[:0]
+	for i, c := range changes {
+		if !drop[i] {
+			folded = append(folded, c)
+		}
+	}
+	return folded
+}
+
+// parseVersion reads the numeric major, minor and patch of a version such
+// as v1.2.3, 1.2 or 1.2.3-rc.1
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if v == "" || len(parts) > 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
+
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] > b[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
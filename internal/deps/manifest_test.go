@@ -0,0 +1,106 @@
+package deps
+
+import (
+	"strings"
+	"testing"
+)
+
+const goMod = `module example.com/app
+
+go 1.22
+
+require github.com/pkg/errors v0.9.1
+
+require (
+	github.com/gin-gonic/gin v1.9.1
+	github.com/google/go-github/v60 v60.0.0
+	github.com/google/go-github/v82 v82.0.0 // latest API
+	golang.org/x/sys v0.15.0 // indirect
+)
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		ecosystem  string
+		content    string
+		want       map[string]string
+		duplicates string
+	}{
+		{
+			name:       "go.mod",
+			ecosystem:  Go,
+			content:    goMod,
+			want:       map[string]string{"github.com/pkg/errors": "v0.9.1", "github.com/gin-gonic/gin": "v1.9.1", "github.com/google/go-github/v60": "v60.0.0", "github.com/google/go-github/v82": "v82.0.0"},
+			duplicates: "github.com/google/go-github",
+		},
+		{
+			name:       "package.json",
+			ecosystem:  NPM,
+			content:    `{"dependencies": {"react": "^18.2.0", "@scope/ui": "~1.4", "local": "file:../local"}, "devDependencies": {"react": "18.2.0", "jest": ">=29.0.0"}}`,
+			want:       map[string]string{"react": "18.2.0", "@scope/ui": "1.4", "local": "", "jest": "29.0.0"},
+			duplicates: "react",
+		},
+		{
+			name:      "requirements.txt",
+			ecosystem: PyPI,
+			content:   "# pinned\nDjango==4.2.1\nrequests[socks]>=2.31  # any\nPyYAML == 6.0 ; python_version > '3'\n-r dev.txt\n",
+			want:      map[string]string{"django": "4.2.1", "requests": "", "pyyaml": "6.0"},
+		},
+		{name: "empty", ecosystem: NPM, want: map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(tt.ecosystem, tt.content)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(m.Deps) != len(tt.want) {
+				t.Errorf("Parse() deps = %+v, want %v", m.Deps, tt.want)
+			}
+			for name, version := range tt.want {
+				if got, ok := m.Deps[name]; !ok || got.Version != version {
+					t.Errorf("Deps[%q] = %+v, want version %q", name, got, version)
+				}
+			}
+			if got := strings.Join(m.Duplicates, ","); got != tt.duplicates {
+				t.Errorf("Duplicates = %q, want %q", got, tt.duplicates)
+			}
+		})
+	}
+}
+
+func TestParse_InvalidPackageJSON(t *testing.T) {
+	if _, err := Parse(NPM, "{"); err == nil {
+		t.Error("Parse() error = nil, want a parse error")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before, _ := Parse(Go, "require (\n\tgithub.com/a/lib v1.2.0\n\tgithub.com/b/old v0.3.0\n\tgithub.com/c/mod v1.5.0\n\tgithub.com/d/zero v0.4.0\n)\n")
+	after, _ := Parse(Go, "require (\n\tgithub.com/a/lib v1.3.0\n\tgithub.com/c/mod/v2 v2.0.1\n\tgithub.com/d/zero v0.5.0\n\tgithub.com/e/new v1.0.0\n)\n")
+
+	var got []string
+	for _, c := range Diff(before, after) {
+		got = append(got, strings.Join([]string{c.Name, c.Kind, c.From, c.To, map[bool]string{true: "major"}[c.Major]}, " "))
+	}
+	want := []string{
+		"github.com/a/lib upgraded v1.2.0 v1.3.0 ",
+		"github.com/b/old removed v0.3.0  ",
+		"github.com/c/mod/v2 upgraded v1.5.0 v2.0.1 major",
+		"github.com/d/zero upgraded v0.4.0 v0.5.0 major",
+		"github.com/e/new added  v1.0.0 ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Diff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestEcosystem(t *testing.T) {
+	for file, want := range map[string]string{"go.mod": Go, "web/package.json": NPM, "api/requirements.txt": PyPI, "go.sum": "", "package-lock.json": ""} {
+		if got := Ecosystem(file); got != want {
+			t.Errorf("Ecosystem(%q) = %q, want %q", file, got, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package deps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Release describes one published version of a package
+type Release struct {
+	Licenses   []string
+	Deprecated bool
+}
+
+// Package describes a package as a whole
+type Package struct {
+	LatestRelease time.Time // when the newest version was published
+}
+
+// DepsDev looks packages up on the deps.dev API (https://api.deps.dev),
+// which covers Go, npm and PyPI
+type DepsDev struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewDepsDev returns a deps.dev client for the API at baseURL
+func NewDepsDev(baseURL string) *DepsDev {
+	return &DepsDev{baseURL: strings.TrimSuffix(baseURL, "/"), client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Release looks up one version of a package
+func (d *DepsDev) Release(ctx context.Context, ecosystem, name, version string) (*Release, error) {
+	var resp struct {
+		Licenses     []string `json:"licenses"`
+		IsDeprecated bool     `json:"isDeprecated"`
+	}
+	if err := d.get(ctx, fmt.Sprintf("/v3/systems/%s/packages/%s/versions/%s", ecosystem, url.PathEscape(name), url.PathEscape(version)), &resp); err != nil {
+		return nil, fmt.Errorf("look up %s@%s: %w", name, version, err)
+	}
+	return &Release{Licenses: resp.Licenses, Deprecated: resp.IsDeprecated}, nil
+}
+
+// Package looks up a package
+func (d *DepsDev) Package(ctx context.Context, ecosystem, name string) (*Package, error) {
+	var resp struct {
+		Versions []struct {
+			PublishedAt time.Time `json:"publishedAt"`
+		} `json:"versions"`
+	}
+	if err := d.get(ctx, fmt.Sprintf("/v3/systems/%s/packages/%s", ecosystem, url.PathEscape(name)), &resp); err != nil {
+		return nil, fmt.Errorf("look up %s: %w", name, err)
+	}
+	p := &Package{}
+	for _, v := range resp.Versions {
+		if v.PublishedAt.After(p.LatestRelease) {
+			p.LatestRelease = v.PublishedAt
+		}
+	}
+	return p, nil
+}
+
+func (d *DepsDev) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+// Package digest emails scheduled summaries of review activity: how many
+// reviews ran, which rules were violated most, and which pull requests still
+// had findings at their last review. Each configured digest covers a set of
+// repositories and goes to its own recipients, so teams only see their repos.
+package digest
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"prmate/internal/config"
+	"prmate/internal/store"
+)
+
+// maxRules and maxOpen cap the lists in a report
+const (
+	maxRules = 10
+	maxOpen  = 20
+)
+
+// Report is the content of one digest email
+type Report struct {
+	Name       string
+	Since      time.Time
+	Until      time.Time
+	Stats      store.Stats
+	TopRules   []store.RuleTrend
+	OpenReview []OpenPR
+}
+
+// OpenPR is a pull request whose most recent review in the period still
+// had findings
+type OpenPR struct {
+	Repo       string
+	PRNumber   int
+	HeadSHA    string
+	Violations int
+	ReviewedAt time.Time
+}
+
+// Build summarises the records of the repos d covers that finished in
+// [since, until)
+func Build(d config.Digest, records []store.ReviewRecord, since, until time.Time) Report {
+	var selected []store.ReviewRecord
+	for _, r := range records {
+		if r.FinishedAt.Before(since) || !r.FinishedAt.Before(until) || !covers(d.Repos, r.Owner, r.Repo) {
+			continue
+		}
+		selected = append(selected, r)
+	}
+
+	report := Report{Name: d.Name, Since: since, Until: until, Stats: store.Summarize(selected)}
+	report.TopRules = report.Stats.Rules
+	if len(report.TopRules) > maxRules {
+		report.TopRules = report.TopRules[:maxRules]
+	}
+
+	// Only the latest review of each PR says whether its findings remain
+	latest := make(map[string]store.ReviewRecord)
+	for _, r := range selected {
+		if r.Error != "" {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s#%d", r.Owner, r.Repo, r.PRNumber)
+		if prev, ok := latest[key]; !ok || r.FinishedAt.After(prev.FinishedAt) {
+			latest[key] = r
+		}
+	}
+	for _, r := range latest {
+		if r.ViolationsFound == 0 {
+			continue
+		}
+		report.OpenReview = append(report.OpenReview, OpenPR{
+			Repo:       r.Owner + "/" + r.Repo,
+			PRNumber:   r.PRNumber,
+			HeadSHA:    r.HeadSHA,
+			Violations: r.ViolationsFound,
+			ReviewedAt: r.FinishedAt,
+		})
+	}
+	sort.Slice(report.OpenReview, func(i, j int) bool {
+		a, b := report.OpenReview[i], report.OpenReview[j]
+		if a.Violations != b.Violations {
+			return a.Violations > b.Violations
+		}
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.PRNumber < b.PRNumber
+	})
+
+	return report
+}
+
+// covers reports whether owner/repo matches one of the owner/repo or
+// owner/* patterns; no patterns match every repo
+func covers(patterns []string, owner, repo string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	name := strings.ToLower(owner + "/" + repo)
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToLower(p), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Render returns the subject and plain-text body of the digest email
+func Render(r Report) (subject, body string) {
+	const stamp = "2006-01-02 15:04"
+	subject = fmt.Sprintf("PRMate %s digest for %s: %d reviews, %d findings",
+		r.Name, r.Until.UTC().Format(time.DateOnly), r.Stats.Reviews, r.Stats.ViolationsFound)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "PRMate review activity for %s, %s to %s UTC\n\n", r.Name, r.Since.UTC().Format(stamp), r.Until.UTC().Format(stamp))
+	if r.Stats.Reviews == 0 {
+		b.WriteString("No reviews ran in this period.\n")
+		return subject, b.String()
+	}
+
+	fmt.Fprintf(&b, "Reviews:  %d (%d failed)\n", r.Stats.Reviews, r.Stats.Failed)
+	fmt.Fprintf(&b, "Findings: %d\n\n", r.Stats.ViolationsFound)
+
+	b.WriteString("By repository\n")
+	for _, rs := range r.Stats.Repos {
+		fmt.Fprintf(&b, "  %-40s %4d reviews %5d findings\n", rs.Repo, rs.Reviews, rs.ViolationsFound)
+	}
+
+	if len(r.TopRules) > 0 {
+		b.WriteString("\nMost violated rules\n")
+		for _, rt := range r.TopRules {
+			fmt.Fprintf(&b, "  %5d  %s\n", rt.Total, rt.Rule)
+		}
+	}
+
+	if len(r.OpenReview) > 0 {
+		b.WriteString("\nUnresolved: pull requests with findings at their last review\n")
+		for i, pr := range r.OpenReview {
+			if i == maxOpen {
+				fmt.Fprintf(&b, "  …and %d more\n", len(r.OpenReview)-maxOpen)
+				break
+			}
+			fmt.Fprintf(&b, "  %s#%d  %d findings at %s\n", pr.Repo, pr.PRNumber, pr.Violations, shortSHA(pr.HeadSHA))
+		}
+	}
+
+	return subject, b.String()
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,150 @@
+package digest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"prmate/internal/config"
+	"prmate/internal/store"
+)
+
+var day = time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC)
+
+func testRecords() []store.ReviewRecord {
+	return []store.ReviewRecord{
+		{Owner: "acme", Repo: "api", PRNumber: 1, HeadSHA: "aaaaaaa1", FinishedAt: day.Add(time.Hour), ViolationsFound: 3, RuleHits: map[string]int{"wrap-errors": 3}},
+		{Owner: "acme", Repo: "api", PRNumber: 1, HeadSHA: "aaaaaaa2", FinishedAt: day.Add(2 * time.Hour), ViolationsFound: 1, RuleHits: map[string]int{"wrap-errors": 1}},
+		{Owner: "acme", Repo: "api", PRNumber: 2, HeadSHA: "bbbbbbb1", FinishedAt: day.Add(3 * time.Hour), ViolationsFound: 2, RuleHits: map[string]int{"no-todo": 2}},
+		{Owner: "acme", Repo: "api", PRNumber: 2, HeadSHA: "bbbbbbb2", FinishedAt: day.Add(4 * time.Hour)},
+		{Owner: "acme", Repo: "web", PRNumber: 9, HeadSHA: "ccccccc1", FinishedAt: day.Add(5 * time.Hour), Error: "llm unavailable"},
+		{Owner: "other", Repo: "tool", PRNumber: 4, FinishedAt: day.Add(time.Hour), ViolationsFound: 5},
+		{Owner: "acme", Repo: "api", PRNumber: 3, FinishedAt: day.Add(-time.Hour), ViolationsFound: 7},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	d := config.Digest{Name: "platform", Repos: []string{"acme/*"}}
+	r := Build(d, testRecords(), day, day.Add(24*time.Hour))
+
+	if r.Stats.Reviews != 5 || r.Stats.Failed != 1 || r.Stats.ViolationsFound != 6 {
+		t.Errorf("stats = %+v, want 5 reviews, 1 failed, 6 findings", r.Stats)
+	}
+	if len(r.TopRules) != 2 || r.TopRules[0].Rule != "wrap-errors" || r.TopRules[0].Total != 4 {
+		t.Errorf("TopRules = %+v, want wrap-errors first with 4", r.TopRules)
+	}
+	// PR 2 was fixed at its last review; PR 1 still had one finding
+	if len(r.OpenReview) != 1 || r.OpenReview[0].PRNumber != 1 || r.OpenReview[0].Violations != 1 {
+		t.Errorf("OpenReview = %+v, want only acme/api#1 with 1 finding", r.OpenReview)
+	}
+}
+
+func TestCovers(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		want     bool
+	}{
+		{patterns: nil, want: true},
+		{patterns: []string{"acme/api"}, want: true},
+		{patterns: []string{"ACME/*"}, want: true},
+		{patterns: []string{"acme/web", "other/*"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := covers(tt.patterns, "acme", "api"); got != tt.want {
+			t.Errorf("covers(%v, acme/api) = %v, want %v", tt.patterns, got, tt.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	d := config.Digest{Name: "platform", Repos: []string{"acme/*"}}
+	subject, body := Render(Build(d, testRecords(), day, day.Add(24*time.Hour)))
+
+	if subject != "PRMate platform digest for 2026-10-15: 5 reviews, 6 findings" {
+		t.Errorf("subject = %q", subject)
+	}
+	for _, want := range []string{"Reviews:  5 (1 failed)", "acme/api", "wrap-errors", "acme/api#1  1 findings at aaaaaaa"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+
+	_, empty := Render(Build(d, nil, day, day.Add(24*time.Hour)))
+	if !strings.Contains(empty, "No reviews ran") {
+		t.Errorf("empty digest body = %q", empty)
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	// 2026-10-14 is a Wednesday
+	tests := []struct {
+		name     string
+		now      time.Time
+		schedule string
+		want     time.Time
+	}{
+		{name: "daily later today", now: day.Add(-time.Hour), schedule: "daily", want: day},
+		{name: "daily at due time", now: day, schedule: "daily", want: day.AddDate(0, 0, 1)},
+		{name: "daily past due", now: day.Add(time.Hour), schedule: "daily", want: day.AddDate(0, 0, 1)},
+		{name: "weekly", now: day.Add(time.Hour), schedule: "weekly", want: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)},
+		{name: "weekly on monday before hour", now: time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC), schedule: "weekly", want: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NextRun(tt.now, tt.schedule, 8); !got.Equal(tt.want) {
+				t.Errorf("NextRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeLister struct{ records []store.ReviewRecord }
+
+func (f *fakeLister) ListReviews(ctx context.Context, flt store.Filter) ([]store.ReviewRecord, error) {
+	return f.records, nil
+}
+
+type fakeMailer struct {
+	to      []string
+	subject string
+	body    string
+}
+
+func (f *fakeMailer) Send(ctx context.Context, to []string, subject, body string) error {
+	f.to, f.subject, f.body = to, subject, body
+	return nil
+}
+
+func TestScheduler_Send(t *testing.T) {
+	mailer := &fakeMailer{}
+	d := config.Digest{Name: "all", To: []string{"team@example.com"}, Schedule: "weekly"}
+	s := NewScheduler(&fakeLister{records: testRecords()}, mailer, []config.Digest{d}, 8)
+
+	if err := s.Send(context.Background(), d, day.Add(24*time.Hour)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(mailer.to) != 1 || mailer.to[0] != "team@example.com" {
+		t.Errorf("to = %v", mailer.to)
+	}
+	// A weekly digest also covers the review from before the last day
+	if !strings.Contains(mailer.subject, "7 reviews, 18 findings") {
+		t.Errorf("subject = %q, want the whole week", mailer.subject)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	msg := string(message("prmate@example.com", []string{"a@example.com", "b@example.com"}, "Digest – week 42", "line one\nline two\n", day))
+
+	for _, want := range []string{
+		"From: prmate@example.com\r\n",
+		"To: a@example.com, b@example.com\r\n",
+		"Subject: =?utf-8?q?",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		"\r\n\r\nline one\r\nline two\r\n",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message missing %q:\n%s", want, msg)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"prmate/internal/config"
+	"prmate/internal/store"
+)
+
+// sendTimeout bounds each digest delivery
+const sendTimeout = time.Minute
+
+// RecordLister reads the review history digests are built from
+type RecordLister interface {
+	ListReviews(ctx context.Context, f store.Filter) ([]store.ReviewRecord, error)
+}
+
+// Scheduler sends each configured digest on its schedule
+type Scheduler struct {
+	records RecordLister
+	mailer  Mailer
+	digests []config.Digest
+	hour    int // UTC hour digests are sent at
+}
+
+// NewScheduler returns a scheduler sending digests at hour (UTC)
+func NewScheduler(records RecordLister, mailer Mailer, digests []config.Digest, hour int) *Scheduler {
+	return &Scheduler{records: records, mailer: mailer, digests: digests, hour: hour}
+}
+
+// Start sends every digest at its next due time, and on each schedule
+// after that, until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, d := range s.digests {
+		go s.loop(ctx, d)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, d config.Digest) {
+	for {
+		due := NextRun(time.Now(), d.Schedule, s.hour)
+		timer := time.NewTimer(time.Until(due))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		if err := s.Send(sendCtx, d, due); err != nil {
+			slog.Warn("failed to send digest", "digest", d.Name, "error", err)
+		} else {
+			slog.Info("sent digest", "digest", d.Name, "recipients", len(d.To))
+		}
+		cancel()
+	}
+}
+
+// Send builds and mails digest d for the period ending at until
+func (s *Scheduler) Send(ctx context.Context, d config.Digest, until time.Time) error {
+	since := until.Add(-period(d.Schedule))
+	records, err := s.records.ListReviews(ctx, store.Filter{Since: since})
+	if err != nil {
+		return fmt.Errorf("list reviews: %w", err)
+	}
+
+	subject, body := Render(Build(d, records, since, until))
+	return s.mailer.Send(ctx, d.To, subject, body)
+}
+
+// NextRun returns the first time after now a digest with schedule is due:
+// hour:00 UTC every day, or on Mondays for weekly digests
+func NextRun(now time.Time, schedule string, hour int) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	if schedule == "weekly" {
+		for next.Weekday() != time.Monday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next
+}
+
+func period(schedule string) time.Duration {
+	if schedule == "weekly" {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
@@ -0,0 +1,78 @@
+// Package errclass defines the classes of errors callers branch on, so
+// that deciding whether to retry, abort or tell the user does not depend on
+// error strings. Packages tag their errors with a class using Wrap, and
+// callers test for it with errors.Is.
+package errclass
+
+import (
+	"errors"
+	"time"
+
+	"prmate/internal/breaker"
+)
+
+var (
+	// ErrRateLimited is matched by errors of calls refused by a rate limit
+	ErrRateLimited = errors.New("rate limited")
+	// ErrNotFound is matched by errors for a missing file, repository or
+	// pull request
+	ErrNotFound = errors.New("not found")
+	// ErrLLMTimeout is matched by errors of LLM calls cut short by their
+	// deadline
+	ErrLLMTimeout = errors.New("llm timed out")
+	// ErrQueueFull is matched by errors of work refused by a full queue
+	ErrQueueFull = errors.New("queue full")
+)
+
+// defaultRetryDelay is how long RetryAfter waits when the error does not
+// say when to retry
+const defaultRetryDelay = 30 * time.Second
+
+// Error tags an error with its class without changing its message
+type Error struct {
+	Err     error
+	Class   error
+	RetryAt time.Time // when the work may be retried; zero when unknown
+}
+
+// Wrap tags err with class; a nil err stays nil
+func Wrap(err, class error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Err: err, Class: class}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Err, e.Class}
+}
+
+// Retryable reports whether the work that failed with err may succeed when
+// it is retried later: a rate limit, an LLM timeout, a full queue or an open
+// circuit breaker
+func Retryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, ErrLLMTimeout) ||
+		errors.Is(err, ErrQueueFull) ||
+		errors.Is(err, breaker.ErrOpen)
+}
+
+// RetryAfter reports whether err is Retryable, and how long to wait before
+// retrying the work that failed
+func RetryAfter(err error) (time.Duration, bool) {
+	if !Retryable(err) {
+		return 0, false
+	}
+	if d, ok := breaker.RetryAfter(err); ok {
+		return d, true
+	}
+	var tagged *Error
+	if errors.As(err, &tagged) && !tagged.RetryAt.IsZero() {
+		return max(time.Until(tagged.RetryAt), 0), true
+	}
+	return defaultRetryDelay, true
+}
@@ -0,0 +1,51 @@
+package errclass
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"prmate/internal/breaker"
+)
+
+func TestRetryAfter(t *testing.T) {
+	base := errors.New("boom")
+	tests := []struct {
+		name      string
+		err       error
+		wantOK    bool
+		wantDelay time.Duration // at least
+	}{
+		{name: "nil", err: nil},
+		{name: "unclassified", err: base},
+		{name: "not found", err: Wrap(base, ErrNotFound)},
+		{name: "queue full", err: fmt.Errorf("submit: %w", Wrap(base, ErrQueueFull)), wantOK: true, wantDelay: defaultRetryDelay},
+		{name: "llm timeout", err: Wrap(base, ErrLLMTimeout), wantOK: true, wantDelay: defaultRetryDelay},
+		{name: "rate limit with reset", err: &Error{Err: base, Class: ErrRateLimited, RetryAt: time.Now().Add(time.Hour)}, wantOK: true, wantDelay: 59 * time.Minute},
+		{name: "open breaker", err: fmt.Errorf("review: %w", &breaker.OpenError{Name: "llm", RetryAt: time.Now().Add(-time.Second)}), wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ok := RetryAfter(tt.err)
+			if ok != tt.wantOK || d < tt.wantDelay {
+				t.Errorf("RetryAfter() = %v, %v, want at least %v, %v", d, ok, tt.wantDelay, tt.wantOK)
+			}
+			if Retryable(tt.err) != tt.wantOK {
+				t.Errorf("Retryable() = %v, want %v", !tt.wantOK, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWrap_KeepsMessage(t *testing.T) {
+	base := errors.New("get file content: 404 Not Found")
+	err := Wrap(base, ErrNotFound)
+	if err.Error() != base.Error() || !errors.Is(err, base) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("Wrap() = %v, want the original message matching both errors", err)
+	}
+	if Wrap(nil, ErrNotFound) != nil {
+		t.Error("Wrap(nil) != nil")
+	}
+}
@@ -0,0 +1,165 @@
+// Package errreport forwards processing failures and panics to an external
+// error tracker (Sentry, or any endpoint accepting a JSON POST) so failures
+// swallowed by background workers are still visible. Reporting is a no-op
+// until Setup is called with a destination.
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"prmate/internal/correlation"
+	"prmate/internal/logging"
+)
+
+// sendTimeout bounds each delivery to the error tracker
+const sendTimeout = 5 * time.Second
+
+// Event is a single reported failure
+type Event struct {
+	ID          string            `json:"event_id"`
+	Time        time.Time         `json:"timestamp"`
+	Message     string            `json:"message"`
+	Type        string            `json:"type"` // "error" or "panic"
+	Stack       string            `json:"stack,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+}
+
+// Sender delivers events to an error tracker
+type Sender interface {
+	Send(ctx context.Context, e Event) error
+}
+
+// Config selects where events are sent; with neither field set reporting is off
+type Config struct {
+	SentryDSN   string
+	WebhookURL  string // generic JSON POST of Event
+	Environment string
+	Release     string
+}
+
+type reporter struct {
+	sender      Sender
+	environment string
+	release     string
+	wg          sync.WaitGroup
+}
+
+var (
+	mu      sync.RWMutex
+	current *reporter
+)
+
+// Setup installs the process-wide reporter described by cfg
+func Setup(cfg Config) error {
+	var sender Sender
+	switch {
+	case cfg.SentryDSN != "":
+		s, err := newSentrySender(cfg.SentryDSN, &http.Client{Timeout: sendTimeout})
+		if err != nil {
+			return err
+		}
+		sender = s
+	case cfg.WebhookURL != "":
+		sender = &webhookSender{url: cfg.WebhookURL, client: &http.Client{Timeout: sendTimeout}}
+	}
+	SetSender(sender, cfg.Environment, cfg.Release)
+	return nil
+}
+
+// SetSender installs sender as the destination for reports; nil disables
+// reporting
+func SetSender(sender Sender, environment, release string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if sender == nil {
+		current = nil
+		return
+	}
+	current = &reporter{sender: sender, environment: environment, release: release}
+}
+
+// Capture reports err, tagged with the request-scoped logging attributes
+// (repo, PR, delivery ID, ...) and correlation ID carried by ctx
+func Capture(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	capture(ctx, Event{Type: "error", Message: err.Error()})
+}
+
+// CapturePanic reports a recovered panic value with the current stack. Call
+// it from the deferred function that called recover().
+func CapturePanic(ctx context.Context, recovered any) {
+	capture(ctx, Event{Type: "panic", Message: fmt.Sprint(recovered), Stack: string(debug.Stack())})
+}
+
+func capture(ctx context.Context, e Event) {
+	mu.RLock()
+	r := current
+	mu.RUnlock()
+	if r == nil {
+		return
+	}
+
+	e.ID = correlation.NewID(fmt.Sprintf("%d", time.Now().UnixNano()))
+	e.Time = time.Now().UTC()
+	e.Tags = tagsFrom(ctx)
+	e.Release = r.release
+	e.Environment = r.environment
+
+	// Deliver in the background so a slow tracker never stalls a worker
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		sendCtx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+		if err := r.sender.Send(sendCtx, e); err != nil {
+			slog.Warn("failed to send error report", "event_id", e.ID, "error", err)
+		}
+	}()
+}
+
+// Flush waits for in-flight reports to be delivered or ctx to expire
+func Flush(ctx context.Context) error {
+	mu.RLock()
+	r := current
+	mu.RUnlock()
+	if r == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.wg.Wait()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("flush error reports: %w", ctx.Err())
+	case <-done:
+		return nil
+	}
+}
+
+func tagsFrom(ctx context.Context) map[string]string {
+	tags := make(map[string]string)
+	attrs := logging.Attrs(ctx)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if key, ok := attrs[i].(string); ok {
+			tags[key] = fmt.Sprint(attrs[i+1])
+		}
+	}
+	if id := correlation.FromContext(ctx); id != "" {
+		tags["correlation_id"] = id
+	}
+	return tags
+}
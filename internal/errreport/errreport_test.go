@@ -0,0 +1,134 @@
+package errreport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"prmate/internal/correlation"
+	"prmate/internal/logging"
+)
+
+type fakeSender struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (f *fakeSender) Send(ctx context.Context, e Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, e)
+	return nil
+}
+
+func TestCapture_TagsFromContext(t *testing.T) {
+	sender := &fakeSender{}
+	SetSender(sender, "test", "v1")
+	defer SetSender(nil, "", "")
+
+	ctx := logging.With(context.Background(), "repo", "owner/repo", "pr", 7)
+	ctx = correlation.WithID(ctx, "corr-1")
+	Capture(ctx, errors.New("review failed"))
+
+	if err := Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(sender.events) != 1 {
+		t.Fatalf("sent %d events, want 1", len(sender.events))
+	}
+	e := sender.events[0]
+	if e.Message != "review failed" || e.Type != "error" || e.Release != "v1" || e.Environment != "test" {
+		t.Errorf("event = %+v", e)
+	}
+	want := map[string]string{"repo": "owner/repo", "pr": "7", "correlation_id": "corr-1"}
+	for k, v := range want {
+		if e.Tags[k] != v {
+			t.Errorf("tag %s = %q, want %q", k, e.Tags[k], v)
+		}
+	}
+}
+
+func TestCapturePanic(t *testing.T) {
+	sender := &fakeSender{}
+	SetSender(sender, "", "")
+	defer SetSender(nil, "", "")
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				CapturePanic(context.Background(), r)
+			}
+		}()
+		panic("boom")
+	}()
+	Flush(context.Background())
+
+	if len(sender.events) != 1 || sender.events[0].Type != "panic" || sender.events[0].Stack == "" {
+		t.Errorf("events = %+v, want one panic with stack", sender.events)
+	}
+}
+
+func TestCapture_Disabled(t *testing.T) {
+	SetSender(nil, "", "")
+	Capture(context.Background(), errors.New("ignored"))
+	if err := Flush(context.Background()); err != nil {
+		t.Errorf("Flush() error = %v", err)
+	}
+}
+
+func TestSentrySender(t *testing.T) {
+	var gotPath, gotAuth string
+	var lines []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("X-Sentry-Auth")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+	}))
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "http://", "http://publickey@", 1) + "/sub/42"
+	s, err := newSentrySender(dsn, srv.Client())
+	if err != nil {
+		t.Fatalf("newSentrySender() error = %v", err)
+	}
+
+	err = s.Send(context.Background(), Event{ID: "abc", Time: time.Now(), Type: "error", Message: "boom", Tags: map[string]string{"repo": "o/r"}})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if gotPath != "/sub/api/42/envelope/" {
+		t.Errorf("path = %q, want /sub/api/42/envelope/", gotPath)
+	}
+	if !strings.Contains(gotAuth, "sentry_key=publickey") {
+		t.Errorf("auth = %q, want sentry_key", gotAuth)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("envelope has %d lines, want 3", len(lines))
+	}
+	var event map[string]any
+	if err := json.Unmarshal([]byte(lines[2]), &event); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if event["event_id"] != "abc" {
+		t.Errorf("event_id = %v, want abc", event["event_id"])
+	}
+}
+
+func TestNewSentrySender_InvalidDSN(t *testing.T) {
+	for _, dsn := range []string{"not a url", "https://sentry.io/1", "https://key@sentry.io/"} {
+		if _, err := newSentrySender(dsn, http.DefaultClient); err == nil {
+			t.Errorf("newSentrySender(%q) expected error", dsn)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// webhookSender POSTs each Event as JSON to a URL
+type webhookSender struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookSender) Send(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	return post(ctx, s.client, s.url, "application/json", nil, body)
+}
+
+// sentrySender delivers events to Sentry's envelope endpoint
+type sentrySender struct {
+	dsn      string
+	endpoint string
+	auth     string
+	client   *http.Client
+}
+
+// newSentrySender parses a DSN of the form https://<key>@<host>[/<path>]/<project>
+func newSentrySender(dsn string, client *http.Client) (*sentrySender, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid sentry dsn")
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	idx := strings.LastIndex(path, "/")
+	if idx < 0 || path[idx+1:] == "" {
+		return nil, fmt.Errorf("invalid sentry dsn: missing project id")
+	}
+	prefix, project := path[:idx], path[idx+1:]
+
+	return &sentrySender{
+		dsn:      dsn,
+		endpoint: fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, project),
+		auth:     fmt.Sprintf("Sentry sentry_version=7, sentry_client=prmate, sentry_key=%s", u.User.Username()),
+		client:   client,
+	}, nil
+}
+
+func (s *sentrySender) Send(ctx context.Context, e Event) error {
+	event := map[string]any{
+		"event_id":    e.ID,
+		"timestamp":   e.Time.Format(time.RFC3339Nano),
+		"level":       "error",
+		"platform":    "go",
+		"logger":      "prmate",
+		"release":     e.Release,
+		"environment": e.Environment,
+		"tags":        e.Tags,
+		"exception": map[string]any{
+			"values": []map[string]any{{"type": e.Type, "value": e.Message}},
+		},
+	}
+	if e.Stack != "" {
+		event["extra"] = map[string]any{"stack": e.Stack}
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode sentry event: %w", err)
+	}
+	header, _ := json.Marshal(map[string]any{"event_id": e.ID, "dsn": s.dsn, "sent_at": time.Now().UTC().Format(time.RFC3339)})
+	item, _ := json.Marshal(map[string]any{"type": "event", "length": len(payload)})
+
+	var body bytes.Buffer
+	body.Write(header)
+	body.WriteByte('\n')
+	body.Write(item)
+	body.WriteByte('\n')
+	body.Write(payload)
+	body.WriteByte('\n')
+
+	return post(ctx, s.client, s.endpoint, "application/x-sentry-envelope",
+		map[string]string{"X-Sentry-Auth": s.auth}, body.Bytes())
+}
+
+func post(ctx context.Context, client *http.Client, target, contentType string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send report: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("error tracker returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+// Package eval measures review quality offline. It replays a corpus of
+// recorded pull requests through a review configuration and scores the
+// findings against human labels, so that two prompt or model configurations
+// can be compared before one is rolled out.
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/review"
+)
+
+// lineSlack is how many lines a finding may be away from a label and
+// still match it; models often point at a neighbouring line
+const lineSlack = 3
+
+// Case is one recorded pull request of the corpus
+type Case struct {
+	Name   string  `json:"name"`
+	Rules  string  `json:"rules"` // .prmate.md as of the recorded head
+	Files  []File  `json:"files"`
+	Labels []Label `json:"labels"`
+}
+
+// File is a changed file of a Case
+type File struct {
+	Path    string `json:"path"`
+	Status  string `json:"status,omitempty"` // added, modified, renamed; modified when empty
+	Patch   string `json:"patch"`
+	Content string `json:"content,omitempty"` // new content, when it was recorded
+}
+
+// Label is a human judgement of a finding. Accepted findings are real
+// problems a review should report; the rest should not be reported.
+type Label struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Rule     string `json:"rule,omitempty"` // empty matches any rule
+	Accepted bool   `json:"accepted"`
+}
+
+// Variant is one prompt and model configuration under evaluation
+type Variant struct {
+	Name         string `json:"name"`
+	Provider     string `json:"provider,omitempty"` // copilot, openai or ollama; empty keeps the configured provider
+	Model        string `json:"model,omitempty"`    // empty keeps the configured model
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// Score counts how the findings of a review compare to the labels
+type Score struct {
+	TruePositives  int `json:"true_positives"`  // findings matching an accepted label
+	FalsePositives int `json:"false_positives"` // findings matching a rejected label or none
+	Missed         int `json:"missed"`          // accepted labels no finding matched
+	Unlabeled      int `json:"unlabeled"`       // false positives that matched no label
+}
+
+// Precision is the share of findings that were accepted; 0 without findings
+func (s Score) Precision() float64 {
+	return ratio(s.TruePositives, s.TruePositives+s.FalsePositives)
+}
+
+// Recall is the share of accepted labels that were found; 0 without them
+func (s Score) Recall() float64 {
+	return ratio(s.TruePositives, s.TruePositives+s.Missed)
+}
+
+func (s *Score) add(o Score) {
+	s.TruePositives += o.TruePositives
+	s.FalsePositives += o.FalsePositives
+	s.Missed += o.Missed
+	s.Unlabeled += o.Unlabeled
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// CaseResult is the outcome of reviewing one Case
+type CaseResult struct {
+	Name     string `json:"name"`
+	Findings int    `json:"findings"`
+	Score    Score  `json:"score"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Result is the outcome of evaluating one Variant over a corpus. Failed
+// cases are not scored.
+type Result struct {
+	Variant         string        `json:"variant"`
+	Cases           []CaseResult  `json:"cases"`
+	Total           Score         `json:"total"`
+	Failed          int           `json:"failed"`
+	EstimatedTokens int           `json:"estimated_tokens"`
+	Duration        time.Duration `json:"duration"`
+}
+
+// LoadCases reads every *.json case in dir, sorted by file name
+func LoadCases(dir string) ([]Case, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("list cases: %w", err)
+	}
+	sort.Strings(paths)
+
+	cases := make([]Case, 0, len(paths))
+	for _, path := range paths {
+		var c Case
+		if err := readJSON(path, &c); err != nil {
+			return nil, err
+		}
+		if c.Name == "" {
+			c.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// LoadVariant reads a Variant from a JSON file, naming it after the file
+// when it has no name
+func LoadVariant(path string) (Variant, error) {
+	var v Variant
+	if err := readJSON(path, &v); err != nil {
+		return Variant{}, err
+	}
+	if v.Name == "" {
+		v.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return v, nil
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// Run reviews every case with llm and cfg, and scores the findings. cfg
+// should carry the variant's instructions.
+func Run(ctx context.Context, name string, cases []Case, llm review.LLMProvider, cfg review.Config) Result {
+	result := Result{Variant: name, Cases: make([]CaseResult, 0, len(cases))}
+	start := time.Now()
+
+	for _, c := range cases {
+		cr := CaseResult{Name: c.Name}
+		svc := review.NewLocalService(caseSource{c}, llm, cfg)
+		out, err := svc.ReviewFiles(ctx, review.ReviewRequest{}, c.prFiles())
+		if err != nil {
+			cr.Error = err.Error()
+			result.Failed++
+		} else {
+			cr.Findings = len(out.Violations)
+			cr.Score = score(out.Violations, c.Labels)
+			result.Total.add(cr.Score)
+			result.EstimatedTokens += out.EstimatedTokens
+		}
+		result.Cases = append(result.Cases, cr)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	result.Duration = time.Since(start)
+	return result
+}
+
+// score matches findings to labels. Each label matches at most one
+// finding; findings repeating a matched label count as false positives.
+func score(findings []review.FileViolation, labels []Label) Score {
+	var s Score
+	matched := make([]bool, len(labels))
+
+	for _, f := range findings {
+		i := matchLabel(f, labels, matched)
+		switch {
+		case i < 0:
+			s.FalsePositives++
+			s.Unlabeled++
+		case labels[i].Accepted:
+			matched[i] = true
+			s.TruePositives++
+		default:
+			matched[i] = true
+			s.FalsePositives++
+		}
+	}
+
+	for i, l := range labels {
+		if l.Accepted && !matched[i] {
+			s.Missed++
+		}
+	}
+	return s
+}
+
+// matchLabel returns the closest unmatched label f matches, or -1
+func matchLabel(f review.FileViolation, labels []Label, matched []bool) int {
+	best, bestDist := -1, lineSlack+1
+	for i, l := range labels {
+		if matched[i] || l.Path != f.Path || !sameRule(l.Rule, f.Rule) {
+			continue
+		}
+		if d := abs(l.Line - f.Line); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// sameRule compares rule names loosely: models shorten and rephrase the
+// names they are given, so either containing the other is a match
+func sameRule(label, finding string) bool {
+	if label == "" {
+		return true
+	}
+	l, f := strings.ToLower(label), strings.ToLower(finding)
+	return strings.Contains(l, f) || strings.Contains(f, l)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// prFiles turns the recorded files into the files of a pull request
+func (c Case) prFiles() []ghclient.PRFile {
+	files := make([]ghclient.PRFile, 0, len(c.Files))
+	for _, f := range c.Files {
+		pf := ghclient.PRFile{Filename: f.Path, Status: f.Status, Patch: f.Patch}
+		if pf.Status == "" {
+			pf.Status = "modified"
+		}
+		for _, line := range strings.Split(f.Patch, "\n") {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				pf.Additions++
+			case strings.HasPrefix(line, "-"):
+				pf.Deletions++
+			}
+		}
+		files = append(files, pf)
+	}
+	return files
+}
+
+// caseSource serves a Case's .prmate.md and recorded file contents to the
+// review service
+type caseSource struct {
+	c Case
+}
+
+func (s caseSource) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	if path == ".prmate.md" {
+		return s.c.Rules, nil
+	}
+	for _, f := range s.c.Files {
+		if f.Path == path && f.Content != "" {
+			return f.Content, nil
+		}
+	}
+	return "", fmt.Errorf("%s: %w", path, fs.ErrNotExist)
+}
+
+func (s caseSource) GetFileSize(ctx context.Context, owner, repo, path, ref string) (int64, error) {
+	content, err := s.GetFileContent(ctx, owner, repo, path, ref)
+	return int64(len(content)), err
+}
+
+// ListDirectory lists the recorded files directly in dir
+func (s caseSource) ListDirectory(ctx context.Context, owner, repo, dir, ref string) ([]string, error) {
+	var files []string
+	for _, f := range s.c.Files {
+		if f.Content != "" && path.Dir(f.Path) == path.Clean("./"+dir) {
+			files = append(files, f.Path)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,209 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+const rulesMD = "# Rules\n- Wrap errors with context\n- Never log secrets\n"
+
+const patch = "@@ -0,0 +1,4 @@\n+package main\n+\n+func run() error {\n+\treturn err\n"
+
+type fakeLLM struct {
+	response string
+	prompts  []string
+}
+
+func (f *fakeLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	f.prompts = append(f.prompts, prompt)
+	return f.response, nil
+}
+
+func TestScore(t *testing.T) {
+	labels := []Label{
+		{Path: "main.go", Line: 10, Rule: "Wrap errors with context", Accepted: true},
+		{Path: "main.go", Line: 20, Rule: "Never log secrets", Accepted: true},
+		{Path: "main.go", Line: 30, Accepted: false},
+	}
+
+	tests := []struct {
+		name     string
+		findings []review.FileViolation
+		want     Score
+	}{
+		{
+			name: "nothing found",
+			want: Score{Missed: 2},
+		},
+		{
+			name: "near line and shortened rule match",
+			findings: []review.FileViolation{
+				{Path: "main.go", Line: 12, Rule: "wrap errors"},
+				{Path: "main.go", Line: 20, Rule: "Never log secrets"},
+			},
+			want: Score{TruePositives: 2},
+		},
+		{
+			name: "rejected, unlabeled and repeated findings",
+			findings: []review.FileViolation{
+				{Path: "main.go", Line: 10, Rule: "Wrap errors with context"},
+				{Path: "main.go", Line: 10, Rule: "Wrap errors with context"},
+				{Path: "main.go", Line: 31, Rule: "Naming"},
+				{Path: "other.go", Line: 10, Rule: "Wrap errors with context"},
+			},
+			want: Score{TruePositives: 1, FalsePositives: 3, Missed: 1, Unlabeled: 2},
+		},
+		{
+			name:     "too far from the label",
+			findings: []review.FileViolation{{Path: "main.go", Line: 14, Rule: "Wrap errors with context"}},
+			want:     Score{FalsePositives: 1, Missed: 2, Unlabeled: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := score(tt.findings, labels); got != tt.want {
+				t.Errorf("score() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScore_Ratios(t *testing.T) {
+	s := Score{TruePositives: 3, FalsePositives: 1, Missed: 3}
+	if s.Precision() != 0.75 || s.Recall() != 0.5 {
+		t.Errorf("precision %v, recall %v; want 0.75, 0.5", s.Precision(), s.Recall())
+	}
+	if (Score{}).Precision() != 0 || (Score{}).Recall() != 0 {
+		t.Error("an empty score should have zero precision and recall")
+	}
+}
+
+func TestRun(t *testing.T) {
+	cases := []Case{
+		{
+			Name:   "wrap",
+			Rules:  rulesMD,
+			Files:  []File{{Path: "main.go", Status: "added", Patch: patch, Content: "package main\n"}},
+			Labels: []Label{{Path: "main.go", Line: 4, Rule: "Wrap errors with context", Accepted: true}},
+		},
+		{
+			Name:  "no rules",
+			Files: []File{{Path: "main.go", Patch: patch}},
+		},
+	}
+	llm := &fakeLLM{response: `{"violations": [{"line": 4, "rule": "Wrap errors with context", "message": "wrap it", "severity": "warning"}]}`}
+
+	r := Run(context.Background(), "candidate", cases, llm, review.Config{Instructions: "Only flag unwrapped returns."})
+
+	if r.Variant != "candidate" || len(r.Cases) != 2 {
+		t.Fatalf("Run() = %+v", r)
+	}
+	if r.Cases[0].Findings != 1 || r.Cases[0].Score != (Score{TruePositives: 1}) {
+		t.Errorf("wrap case = %+v, want one true positive", r.Cases[0])
+	}
+	if r.Cases[1].Findings != 0 || r.Cases[1].Error != "" {
+		t.Errorf("case without rules = %+v, want no findings", r.Cases[1])
+	}
+	if r.Total != (Score{TruePositives: 1}) || r.Failed != 0 || r.EstimatedTokens == 0 {
+		t.Errorf("totals = %+v, failed %d, tokens %d", r.Total, r.Failed, r.EstimatedTokens)
+	}
+	if len(llm.prompts) != 1 || !strings.Contains(llm.prompts[0], "Only flag unwrapped returns.") || !strings.Contains(llm.prompts[0], "package main\n") {
+		t.Errorf("prompt should carry the instructions and the recorded content:\n%v", llm.prompts)
+	}
+}
+
+type fakeSource struct {
+	files   []ghclient.PRFile
+	content map[string]string
+	threads []ghclient.ReviewThread
+}
+
+func (f *fakeSource) GetPRFiles(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.PRFile, error) {
+	return f.files, nil
+}
+
+func (f *fakeSource) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	if c, ok := f.content[ref+":"+path]; ok {
+		return c, nil
+	}
+	return "", errors.New("not found")
+}
+
+func (f *fakeSource) ListReviewThreads(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.ReviewThread, error) {
+	return f.threads, nil
+}
+
+func TestRecord(t *testing.T) {
+	src := &fakeSource{
+		files: []ghclient.PRFile{
+			{Filename: "main.go", Status: "modified", Patch: patch},
+			{Filename: "old.go", Status: "removed", Patch: "@@ -1 +0,0 @@\n-package old"},
+			{Filename: "logo.png", Status: "added"},
+		},
+		content: map[string]string{"abc:.prmate.md": rulesMD, "abc:main.go": "package main\n"},
+		threads: []ghclient.ReviewThread{
+			{Path: "main.go", Line: 4, Bot: true, Resolved: true, Body: "⚠️ **Wrap errors with context**: wrap it"},
+			{Path: "main.go", Line: 2, Bot: true, Body: "💡 **Never log secrets**: maybe"},
+			{Path: "main.go", Line: 3, Body: "**Nit**: human comment"},
+		},
+	}
+
+	c, err := Record(context.Background(), src, store.ReviewRecord{Owner: "acme", Repo: "api", PRNumber: 7, HeadSHA: "abc"})
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if c.Name != "acme/api#7" || c.Rules != rulesMD {
+		t.Errorf("name %q, rules %q", c.Name, c.Rules)
+	}
+	if len(c.Files) != 1 || c.Files[0].Path != "main.go" || c.Files[0].Content != "package main\n" {
+		t.Errorf("files = %+v, want only main.go with its content", c.Files)
+	}
+	want := []Label{
+		{Path: "main.go", Line: 4, Rule: "Wrap errors with context", Accepted: true},
+		{Path: "main.go", Line: 2, Rule: "Never log secrets"},
+	}
+	if len(c.Labels) != len(want) || c.Labels[0] != want[0] || c.Labels[1] != want[1] {
+		t.Errorf("labels = %+v, want %+v", c.Labels, want)
+	}
+
+	// A written case loads back, named after itself
+	dir := t.TempDir()
+	if err := WriteCase(filepath.Join(dir, "acme-api-7.json"), c); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadCases(dir)
+	if err != nil || len(loaded) != 1 || loaded[0].Name != "acme/api#7" || len(loaded[0].Labels) != 2 {
+		t.Errorf("LoadCases() = %+v, %v", loaded, err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	a := Result{Variant: "baseline", Total: Score{TruePositives: 1, FalsePositives: 1, Missed: 1}, Cases: []CaseResult{
+		{Name: "one", Score: Score{TruePositives: 1}},
+		{Name: "two", Score: Score{FalsePositives: 1, Missed: 1}},
+	}}
+	b := Result{Variant: "candidate", Total: Score{TruePositives: 2}, Failed: 1, Cases: []CaseResult{
+		{Name: "one", Score: Score{TruePositives: 1}},
+		{Name: "two", Error: "llm unavailable"},
+	}}
+
+	var out bytes.Buffer
+	Render(&out, a, b)
+	got := out.String()
+	for _, want := range []string{"baseline", "candidate", "0.50", "1.00 (+0.50)", "Cases scored differently", "two", "0/1/1", "failed: llm unavailable"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "one ") {
+		t.Errorf("case scored the same should not be listed:\n%s", got)
+	}
+}
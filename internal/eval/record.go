@@ -0,0 +1,86 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+// maxRecordedContent is the largest file content a recorded case keeps;
+// reviews only read the full content of small files anyway
+const maxRecordedContent = 100 << 10
+
+// Source reads a reviewed pull request back from GitHub
+type Source interface {
+	GetPRFiles(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.PRFile, error)
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
+	ListReviewThreads(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.ReviewThread, error)
+}
+
+// Record turns a review from the review store into a Case. The diff is the
+// pull request's current one, and file contents and .prmate.md are read at
+// the reviewed head. Labels are seeded from PRMate's review threads:
+// findings someone resolved are accepted, the rest are not. Check them by
+// hand before relying on the case.
+func Record(ctx context.Context, src Source, rec store.ReviewRecord) (Case, error) {
+	c := Case{Name: fmt.Sprintf("%s/%s#%d", rec.Owner, rec.Repo, rec.PRNumber)}
+
+	rules, err := src.GetFileContent(ctx, rec.Owner, rec.Repo, ".prmate.md", rec.HeadSHA)
+	if err != nil {
+		return Case{}, fmt.Errorf("get .prmate.md: %w", err)
+	}
+	c.Rules = rules
+
+	files, err := src.GetPRFiles(ctx, rec.Owner, rec.Repo, rec.PRNumber)
+	if err != nil {
+		return Case{}, fmt.Errorf("get PR files: %w", err)
+	}
+	for _, f := range files {
+		if f.Status == "removed" || f.Patch == "" {
+			continue
+		}
+		file := File{Path: f.Filename, Status: f.Status, Patch: f.Patch}
+		if content, err := src.GetFileContent(ctx, rec.Owner, rec.Repo, f.Filename, rec.HeadSHA); err == nil && len(content) <= maxRecordedContent {
+			file.Content = content
+		}
+		c.Files = append(c.Files, file)
+	}
+
+	threads, err := src.ListReviewThreads(ctx, rec.Owner, rec.Repo, rec.PRNumber)
+	if err != nil {
+		return Case{}, fmt.Errorf("list review threads: %w", err)
+	}
+	for _, t := range threads {
+		if !t.Bot {
+			continue
+		}
+		rule, _, ok := review.FindingComment(t.Body)
+		if !ok {
+			continue
+		}
+		c.Labels = append(c.Labels, Label{Path: t.Path, Line: t.Line, Rule: rule, Accepted: t.Resolved})
+	}
+
+	return c, nil
+}
+
+// WriteCase saves c as a JSON file at path, creating its directory
+func WriteCase(path string, c Case) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode case: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write case: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Render writes a comparison of two results over the same corpus: the
+// totals side by side, then the cases the variants scored differently on
+func Render(w io.Writer, a, b Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "\t%s\t%s\t\n", a.Variant, b.Variant)
+	fmt.Fprintf(tw, "Precision\t%.2f\t%.2f (%+.2f)\t\n", a.Total.Precision(), b.Total.Precision(), b.Total.Precision()-a.Total.Precision())
+	fmt.Fprintf(tw, "Recall\t%.2f\t%.2f (%+.2f)\t\n", a.Total.Recall(), b.Total.Recall(), b.Total.Recall()-a.Total.Recall())
+	fmt.Fprintf(tw, "True positives\t%d\t%d\t\n", a.Total.TruePositives, b.Total.TruePositives)
+	fmt.Fprintf(tw, "False positives\t%d\t%d\t\n", a.Total.FalsePositives, b.Total.FalsePositives)
+	fmt.Fprintf(tw, "  of them unlabeled\t%d\t%d\t\n", a.Total.Unlabeled, b.Total.Unlabeled)
+	fmt.Fprintf(tw, "Missed\t%d\t%d\t\n", a.Total.Missed, b.Total.Missed)
+	fmt.Fprintf(tw, "Failed cases\t%d\t%d\t\n", a.Failed, b.Failed)
+	fmt.Fprintf(tw, "Est. tokens\t%d\t%d\t\n", a.EstimatedTokens, b.EstimatedTokens)
+	fmt.Fprintf(tw, "Duration\t%s\t%s\t\n", a.Duration.Round(time.Second), b.Duration.Round(time.Second))
+	_ = tw.Flush()
+
+	var diffs [][2]CaseResult
+	for i := range a.Cases {
+		if i < len(b.Cases) && a.Cases[i] != b.Cases[i] {
+			diffs = append(diffs, [2]CaseResult{a.Cases[i], b.Cases[i]})
+		}
+	}
+	if len(diffs) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\nCases scored differently (true positives/false positives/missed):\n\n")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Case\t%s\t%s\t\n", a.Variant, b.Variant)
+	for _, d := range diffs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t\n", d[0].Name, caseCell(d[0]), caseCell(d[1]))
+	}
+	_ = tw.Flush()
+}
+
+func caseCell(c CaseResult) string {
+	if c.Error != "" {
+		return "failed: " + c.Error
+	}
+	return fmt.Sprintf("%d/%d/%d", c.Score.TruePositives, c.Score.FalsePositives, c.Score.Missed)
+}
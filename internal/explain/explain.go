@@ -0,0 +1,139 @@
+// Package explain answers "@prmate explain" comments: the LLM describes what
+// a range of lines in a pull request does and how it uses the repository
+// files it depends on, which are found the way reviews find them.
+package explain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"prmate/internal/review"
+)
+
+const (
+	// MaxLines bounds the lines one command can ask about
+	MaxLines = 300
+	// maxDependencies bounds the dependency files sent with the selection
+	maxDependencies = 5
+	// maxDependencyBytes of each dependency file are sent to the LLM
+	maxDependencyBytes = 3000
+	// contextLines around the selection are sent so that it can be read in
+	// its enclosing function
+	contextLines = 20
+)
+
+// ErrBadRange is returned when the requested lines are not in the file
+var ErrBadRange = errors.New("invalid line range")
+
+// LLMProvider writes the explanation
+type LLMProvider interface {
+	GenerateTextWithContext(ctx context.Context, prompt string) (string, error)
+}
+
+// Request names the lines to explain
+type Request struct {
+	Owner string
+	Repo  string
+	Ref   string // head commit of the pull request
+	Path  string
+	Start int // first line, 1-based
+	End   int // last line, inclusive
+}
+
+// Result is an explanation of the requested lines
+type Result struct {
+	Explanation  string
+	Dependencies []string // files sent to the LLM as context
+}
+
+// Explainer explains code in pull requests
+type Explainer struct {
+	content review.ContentSource
+	llm     LLMProvider
+}
+
+// NewExplainer creates an explainer reading files from content
+func NewExplainer(content review.ContentSource, llm LLMProvider) *Explainer {
+	return &Explainer{content: content, llm: llm}
+}
+
+// Explain reads req.Path at req.Ref and asks the LLM what lines req.Start
+// to req.End do, with the files they depend on as context
+func (e *Explainer) Explain(ctx context.Context, req Request) (*Result, error) {
+	content, err := e.content.GetFileContent(ctx, req.Owner, req.Repo, req.Path, req.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", req.Path, err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	switch {
+	case req.Start < 1 || req.End < req.Start:
+		return nil, fmt.Errorf("%w: lines %d-%d", ErrBadRange, req.Start, req.End)
+	case req.Start > len(lines):
+		return nil, fmt.Errorf("%w: %s has %d lines", ErrBadRange, req.Path, len(lines))
+	case req.End-req.Start+1 > MaxLines:
+		return nil, fmt.Errorf("%w: at most %d lines can be explained at once", ErrBadRange, MaxLines)
+	}
+	if req.End > len(lines) {
+		req.End = len(lines)
+	}
+
+	result := &Result{}
+	var deps strings.Builder
+	for _, path := range review.ResolveDependencies(ctx, e.content, req.Owner, req.Repo, req.Ref, req.Path, content) {
+		if len(result.Dependencies) == maxDependencies {
+			break
+		}
+		if path == req.Path {
+			continue
+		}
+		dep, err := e.content.GetFileContent(ctx, req.Owner, req.Repo, path, req.Ref)
+		if err != nil {
+			continue // not in the repository, or not a file
+		}
+		if len(dep) > maxDependencyBytes {
+			dep = dep[:maxDependencyBytes] + "\n... (truncated)"
+		}
+		fmt.Fprintf(&deps, "\n### %s\n```\n%s\n```\n", path, dep)
+		result.Dependencies = append(result.Dependencies, path)
+	}
+
+	response, err := e.llm.GenerateTextWithContext(ctx, buildPrompt(req, lines, deps.String()))
+	if err != nil {
+		return nil, fmt.Errorf("llm: %w", err)
+	}
+	result.Explanation = strings.TrimSpace(response)
+	return result, nil
+}
+
+func buildPrompt(req Request, lines []string, deps string) string {
+	var sb strings.Builder
+	sb.WriteString("You are helping a reviewer understand a change in a pull request.\n\n")
+
+	from := max(req.Start-contextLines, 1)
+	to := min(req.End+contextLines, len(lines))
+	fmt.Fprintf(&sb, "## File: %s (lines %d-%d, selection marked with >)\n```\n", req.Path, from, to)
+	for n := from; n <= to; n++ {
+		mark := " "
+		if n >= req.Start && n <= req.End {
+			mark = ">"
+		}
+		fmt.Fprintf(&sb, "%s%5d  %s\n", mark, n, lines[n-1])
+	}
+	sb.WriteString("```\n")
+
+	if deps != "" {
+		sb.WriteString("\n## Files It Depends On\n")
+		sb.WriteString(deps)
+	}
+
+	fmt.Fprintf(&sb, `
+## Task
+Explain what lines %d-%d of %s do. Describe the behaviour, not each statement: what goes in, what comes out, which errors and edge cases are handled. Then explain how the selection interacts with the files it depends on: which of their types and functions it calls, and what it relies on them to do.
+
+Keep it under 300 words of GitHub Markdown. Do not review the code or suggest changes unless something is clearly broken.
+`, req.Start, req.End, req.Path)
+	return sb.String()
+}
@@ -0,0 +1,120 @@
+package explain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type mockContent struct {
+	files map[string]string
+	reads []string
+}
+
+func (m *mockContent) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	m.reads = append(m.reads, path+"@"+ref)
+	if c, ok := m.files[path]; ok {
+		return c, nil
+	}
+	return "", errors.New("not found")
+}
+
+func (m *mockContent) GetFileSize(ctx context.Context, owner, repo, path, ref string) (int64, error) {
+	return int64(len(m.files[path])), nil
+}
+
+func (m *mockContent) ListDirectory(ctx context.Context, owner, repo, path, ref string) ([]string, error) {
+	return nil, errors.New("not a directory")
+}
+
+type mockLLM struct {
+	prompt string
+}
+
+func (m *mockLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	m.prompt = prompt
+	return "  It loads the user.\n", nil
+}
+
+func TestExplain(t *testing.T) {
+	var handler strings.Builder
+	handler.WriteString("package api\n\nimport (\n\t\"context\"\n\n\t\"acme/internal/store\"\n)\n\n")
+	for i := 9; i <= 60; i++ {
+		fmt.Fprintf(&handler, "// line %d\n", i)
+	}
+	content := &mockContent{files: map[string]string{
+		"api/handler.go":          handler.String(),
+		"internal/store/types.go": "package store\n\ntype User struct{}\n",
+	}}
+	llm := &mockLLM{}
+
+	result, err := NewExplainer(content, llm).Explain(context.Background(), Request{
+		Owner: "acme", Repo: "api", Ref: "abc123", Path: "api/handler.go", Start: 40, End: 42,
+	})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if result.Explanation != "It loads the user." {
+		t.Errorf("Explanation = %q", result.Explanation)
+	}
+	if strings.Join(result.Dependencies, ",") != "internal/store/types.go" {
+		t.Errorf("Dependencies = %v", result.Dependencies)
+	}
+	if content.reads[0] != "api/handler.go@abc123" {
+		t.Errorf("reads = %v, want the file at the head commit first", content.reads)
+	}
+
+	for _, want := range []string{
+		">   40  // line 40",
+		">   42  // line 42",
+		"    43  // line 43",
+		"    20  // line 20",
+		"### internal/store/types.go",
+		"type User struct{}",
+		"Explain what lines 40-42 of api/handler.go do",
+	} {
+		if !strings.Contains(llm.prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, llm.prompt)
+		}
+	}
+	if strings.Contains(llm.prompt, "// line 19\n") {
+		t.Error("prompt should only carry 20 lines of context around the selection")
+	}
+}
+
+func TestExplain_BadRange(t *testing.T) {
+	content := &mockContent{files: map[string]string{"a.go": "package a\n\nfunc A() {}\n"}}
+
+	tests := []struct {
+		name       string
+		start, end int
+	}{
+		{name: "reversed", start: 3, end: 2},
+		{name: "zero", start: 0, end: 2},
+		{name: "past the end", start: 4, end: 9},
+		{name: "too long", start: 1, end: MaxLines + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &mockLLM{}
+			_, err := NewExplainer(content, llm).Explain(context.Background(), Request{Path: "a.go", Start: tt.start, End: tt.end})
+			if !errors.Is(err, ErrBadRange) {
+				t.Errorf("Explain() error = %v, want ErrBadRange", err)
+			}
+			if llm.prompt != "" {
+				t.Error("LLM should not be asked about an invalid range")
+			}
+		})
+	}
+
+	// The end of a range past the end of the file is clamped
+	llm := &mockLLM{}
+	if _, err := NewExplainer(content, llm).Explain(context.Background(), Request{Path: "a.go", Start: 3, End: 10}); err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if !strings.Contains(llm.prompt, "lines 3-3 of a.go") {
+		t.Errorf("prompt does not clamp the range:\n%s", llm.prompt)
+	}
+}
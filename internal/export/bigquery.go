@@ -0,0 +1,287 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"prmate/internal/store"
+)
+
+const (
+	bigQueryEndpoint = "https://bigquery.googleapis.com/bigquery/v2"
+	reviewsTable     = "prmate_reviews"
+	violationsTable  = "prmate_violations"
+	// insertBatch stays well below the streaming insert request limits
+	insertBatch = 500
+)
+
+// tokenSource returns OAuth access tokens
+type tokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// BigQuery streams records into the prmate_reviews and prmate_violations
+// tables of a dataset, creating them on first use. It only sends records
+// finished after the newest one already in prmate_reviews.
+type BigQuery struct {
+	project  string
+	dataset  string
+	tokens   tokenSource
+	endpoint string
+	client   *http.Client
+
+	mu     sync.Mutex
+	ready  bool      // tables exist and cursor is loaded
+	cursor time.Time // finish time of the newest exported review
+}
+
+// NewBigQuery returns a sink for project.dataset
+func NewBigQuery(project, dataset string, tokens tokenSource) *BigQuery {
+	return &BigQuery{
+		project:  project,
+		dataset:  dataset,
+		tokens:   tokens,
+		endpoint: bigQueryEndpoint,
+		client:   &http.Client{Timeout: time.Minute},
+	}
+}
+
+func (b *BigQuery) Export(ctx context.Context, records []store.ReviewRecord) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.ready {
+		if err := b.createTables(ctx); err != nil {
+			return err
+		}
+		cursor, err := b.newestExported(ctx)
+		if err != nil {
+			return err
+		}
+		b.cursor, b.ready = cursor, true
+	}
+
+	var fresh []store.ReviewRecord
+	for _, r := range records {
+		// BigQuery keeps microseconds, so compare at that precision
+		if r.FinishedAt.Truncate(time.Microsecond).After(b.cursor) {
+			fresh = append(fresh, r)
+		}
+	}
+
+	for start := 0; start < len(fresh); start += insertBatch {
+		batch := fresh[start:min(start+insertBatch, len(fresh))]
+
+		var reviews, violations []insertRow
+		for _, r := range batch {
+			reviews = append(reviews, insertRow{InsertID: r.ID, JSON: rowJSON(reviewColumns, reviewRow(r))})
+			for _, v := range violationRows(r) {
+				violations = append(violations, insertRow{InsertID: r.ID + ":" + v[5].(string), JSON: rowJSON(violationColumns, v)})
+			}
+		}
+		// Violations go first so a failure never leaves reviews whose
+		// violations are skipped by the cursor on the next run
+		if err := b.insert(ctx, violationsTable, violations); err != nil {
+			return err
+		}
+		if err := b.insert(ctx, reviewsTable, reviews); err != nil {
+			return err
+		}
+		b.cursor = batch[len(batch)-1].FinishedAt.Truncate(time.Microsecond)
+	}
+	return nil
+}
+
+type insertRow struct {
+	InsertID string         `json:"insertId"`
+	JSON     map[string]any `json:"json"`
+}
+
+// rowJSON maps columns to values in the form BigQuery accepts
+func rowJSON(columns []string, values []any) map[string]any {
+	row := make(map[string]any, len(columns))
+	for i, c := range columns {
+		switch v := values[i].(type) {
+		case time.Time:
+			if !v.IsZero() {
+				row[c] = v.Format(time.RFC3339Nano)
+			}
+		case string:
+			if v != "" {
+				row[c] = v
+			}
+		default:
+			row[c] = v
+		}
+	}
+	return row
+}
+
+func (b *BigQuery) insert(ctx context.Context, table string, rows []insertRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	var resp struct {
+		InsertErrors []struct {
+			Index  int `json:"index"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"insertErrors"`
+	}
+	path := fmt.Sprintf("/projects/%s/datasets/%s/tables/%s/insertAll", b.project, b.dataset, table)
+	if err := b.call(ctx, path, map[string]any{"rows": rows}, &resp); err != nil {
+		return fmt.Errorf("insert into %s: %w", table, err)
+	}
+	if len(resp.InsertErrors) > 0 {
+		e := resp.InsertErrors[0]
+		msg := "unknown error"
+		if len(e.Errors) > 0 {
+			msg = e.Errors[0].Message
+		}
+		return fmt.Errorf("insert into %s: %d rows rejected, row %d: %s", table, len(resp.InsertErrors), e.Index, msg)
+	}
+	return nil
+}
+
+// createTables creates both tables, leaving existing ones alone
+func (b *BigQuery) createTables(ctx context.Context) error {
+	tables := map[string][]map[string]string{
+		reviewsTable: {
+			{"name": "id", "type": "STRING", "mode": "REQUIRED"},
+			{"name": "instance", "type": "STRING"},
+			{"name": "owner", "type": "STRING"},
+			{"name": "repo", "type": "STRING"},
+			{"name": "pr", "type": "INT64"},
+			{"name": "head_sha", "type": "STRING"},
+			{"name": "correlation_id", "type": "STRING"},
+			{"name": "started_at", "type": "TIMESTAMP"},
+			{"name": "finished_at", "type": "TIMESTAMP"},
+			{"name": "files_reviewed", "type": "INT64"},
+			{"name": "comments_posted", "type": "INT64"},
+			{"name": "violations_found", "type": "INT64"},
+			{"name": "estimated_tokens", "type": "INT64"},
+			{"name": "error", "type": "STRING"},
+		},
+		violationsTable: {
+			{"name": "review_id", "type": "STRING", "mode": "REQUIRED"},
+			{"name": "owner", "type": "STRING"},
+			{"name": "repo", "type": "STRING"},
+			{"name": "pr", "type": "INT64"},
+			{"name": "finished_at", "type": "TIMESTAMP"},
+			{"name": "rule", "type": "STRING"},
+			{"name": "count", "type": "INT64"},
+		},
+	}
+	for _, name := range []string{reviewsTable, violationsTable} {
+		body := map[string]any{
+			"tableReference": map[string]string{"projectId": b.project, "datasetId": b.dataset, "tableId": name},
+			"schema":         map[string]any{"fields": tables[name]},
+			"timePartitioning": map[string]string{
+				"type":  "DAY",
+				"field": "finished_at",
+			},
+		}
+		err := b.call(ctx, fmt.Sprintf("/projects/%s/datasets/%s/tables", b.project, b.dataset), body, nil)
+		if err != nil && !isStatus(err, http.StatusConflict) {
+			return fmt.Errorf("create table %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// newestExported returns the finish time of the newest review in the
+// reviews table, or the zero time when it is empty
+func (b *BigQuery) newestExported(ctx context.Context) (time.Time, error) {
+	query := fmt.Sprintf("SELECT UNIX_MICROS(MAX(finished_at)) FROM `%s.%s.%s`", b.project, b.dataset, reviewsTable)
+	var resp struct {
+		JobComplete bool `json:"jobComplete"`
+		Rows        []struct {
+			F []struct {
+				V *string `json:"v"`
+			} `json:"f"`
+		} `json:"rows"`
+	}
+	err := b.call(ctx, fmt.Sprintf("/projects/%s/queries", b.project),
+		map[string]any{"query": query, "useLegacySql": false, "timeoutMs": 60000}, &resp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query newest exported review: %w", err)
+	}
+	if !resp.JobComplete {
+		return time.Time{}, fmt.Errorf("query newest exported review: query did not complete in time")
+	}
+	if len(resp.Rows) == 0 || len(resp.Rows[0].F) == 0 || resp.Rows[0].F[0].V == nil {
+		return time.Time{}, nil
+	}
+	micros, err := strconv.ParseInt(*resp.Rows[0].F[0].V, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse newest exported review time: %w", err)
+	}
+	return time.UnixMicro(micros).UTC(), nil
+}
+
+// statusError is a non-2xx response from the BigQuery API
+type statusError struct {
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("bigquery returned %d: %s", e.status, e.body)
+}
+
+func isStatus(err error, status int) bool {
+	var se *statusError
+	return errors.As(err, &se) && se.status == status
+}
+
+// call POSTs in to the API path and decodes the response into out, if set
+func (b *BigQuery) call(ctx context.Context, path string, in, out any) error {
+	token, err := b.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		body := strings.TrimSpace(string(data))
+		if len(body) > 300 {
+			body = body[:300] + "…"
+		}
+		return &statusError{status: resp.StatusCode, body: body}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
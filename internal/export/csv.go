@@ -0,0 +1,89 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"prmate/internal/store"
+)
+
+// CSV rewrites reviews.csv and violations.csv in a directory on every
+// export, so the files always hold the full history
+type CSV struct {
+	dir string
+}
+
+// NewCSV returns a sink writing to dir
+func NewCSV(dir string) *CSV {
+	return &CSV{dir: dir}
+}
+
+func (c *CSV) Export(ctx context.Context, records []store.ReviewRecord) error {
+	_ = ctx
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("create export dir: %w", err)
+	}
+
+	reviews := [][]any{}
+	violations := [][]any{}
+	for _, r := range records {
+		reviews = append(reviews, reviewRow(r))
+		violations = append(violations, violationRows(r)...)
+	}
+
+	if err := c.write("reviews.csv", reviewColumns, reviews); err != nil {
+		return err
+	}
+	return c.write("violations.csv", violationColumns, violations)
+}
+
+// write replaces name atomically so readers never see a partial file
+func (c *CSV) write(name string, header []string, rows [][]any) error {
+	tmp, err := os.CreateTemp(c.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := csv.NewWriter(tmp)
+	_ = w.Write(header)
+	for _, row := range rows {
+		_ = w.Write(csvFields(row))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(c.dir, name)); err != nil {
+		return fmt.Errorf("replace %s: %w", name, err)
+	}
+	return nil
+}
+
+func csvFields(row []any) []string {
+	fields := make([]string, len(row))
+	for i, v := range row {
+		switch v := v.(type) {
+		case string:
+			fields[i] = v
+		case int:
+			fields[i] = strconv.Itoa(v)
+		case time.Time:
+			if !v.IsZero() {
+				fields[i] = v.Format(time.RFC3339)
+			}
+		default:
+			fields[i] = fmt.Sprint(v)
+		}
+	}
+	return fields
+}
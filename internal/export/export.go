@@ -0,0 +1,145 @@
+// Package export copies the review history to places the platform team can
+// query: CSV files on disk or BigQuery tables. Each review becomes one row
+// in a reviews table and one row per violated rule in a violations table.
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"prmate/internal/store"
+)
+
+// exportTimeout bounds one export run
+const exportTimeout = 5 * time.Minute
+
+// Sink receives review records
+type Sink interface {
+	// Export writes records, sorted by finish time. A sink may skip
+	// records it already holds from an earlier export.
+	Export(ctx context.Context, records []store.ReviewRecord) error
+}
+
+// Open returns the sink described by rawURL:
+//
+//	/var/lib/prmate/export or file:///var/lib/prmate/export
+//	bigquery://project/dataset
+//
+// credentialsFile is a Google service account key used for BigQuery; when
+// empty the GCE/GKE metadata server provides credentials.
+func Open(rawURL, credentialsFile string) (Sink, error) {
+	if rawURL == "" {
+		return nil, errors.New("export url is empty")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse export url: %w", err)
+	}
+
+	switch u.Scheme {
+	case "", "file":
+		dir := rawURL
+		if u.Scheme == "file" {
+			dir = u.Path
+		}
+		return NewCSV(dir), nil
+	case "bigquery":
+		dataset := strings.Trim(u.Path, "/")
+		if u.Host == "" || dataset == "" || strings.Contains(dataset, "/") {
+			return nil, fmt.Errorf("bigquery export url must be bigquery://project/dataset")
+		}
+		tokens, err := newGoogleTokens(credentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewBigQuery(u.Host, dataset, tokens), nil
+	default:
+		return nil, fmt.Errorf("unsupported export scheme %q", u.Scheme)
+	}
+}
+
+// RecordLister reads the review history
+type RecordLister interface {
+	ListReviews(ctx context.Context, f store.Filter) ([]store.ReviewRecord, error)
+}
+
+// Exporter periodically hands the review history to a sink
+type Exporter struct {
+	records  RecordLister
+	sink     Sink
+	interval time.Duration
+}
+
+// NewExporter returns an exporter writing records to sink every interval
+func NewExporter(records RecordLister, sink Sink, interval time.Duration) *Exporter {
+	return &Exporter{records: records, sink: sink, interval: interval}
+}
+
+// Start exports once right away and then every interval until ctx is
+// cancelled
+func (e *Exporter) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			runCtx, cancel := context.WithTimeout(ctx, exportTimeout)
+			if err := e.Run(runCtx); err != nil {
+				slog.Warn("review export failed", "error", err)
+			}
+			cancel()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Run exports the whole review history once
+func (e *Exporter) Run(ctx context.Context) error {
+	records, err := e.records.ListReviews(ctx, store.Filter{})
+	if err != nil {
+		return fmt.Errorf("list reviews: %w", err)
+	}
+	// ListReviews returns the newest first; sinks take them in order
+	sort.SliceStable(records, func(i, j int) bool { return records[i].FinishedAt.Before(records[j].FinishedAt) })
+	return e.sink.Export(ctx, records)
+}
+
+// reviewColumns and violationColumns name the fields of exported rows
+var (
+	reviewColumns = []string{"id", "instance", "owner", "repo", "pr", "head_sha", "correlation_id", "started_at", "finished_at",
+		"files_reviewed", "comments_posted", "violations_found", "estimated_tokens", "error"}
+	violationColumns = []string{"review_id", "owner", "repo", "pr", "finished_at", "rule", "count"}
+)
+
+// reviewRow returns r's values in reviewColumns order
+func reviewRow(r store.ReviewRecord) []any {
+	return []any{r.ID, r.Instance, r.Owner, r.Repo, r.PRNumber, r.HeadSHA, r.CorrelationID, r.StartedAt.UTC(), r.FinishedAt.UTC(),
+		r.FilesReviewed, r.CommentsPosted, r.ViolationsFound, r.EstimatedTokens, r.Error}
+}
+
+// violationRows returns one row per rule r violated, in violationColumns
+// order and sorted by rule
+func violationRows(r store.ReviewRecord) [][]any {
+	rules := make([]string, 0, len(r.RuleHits))
+	for rule := range r.RuleHits {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	rows := make([][]any, 0, len(rules))
+	for _, rule := range rules {
+		rows = append(rows, []any{r.ID, r.Owner, r.Repo, r.PRNumber, r.FinishedAt.UTC(), rule, r.RuleHits[rule]})
+	}
+	return rows
+}
@@ -0,0 +1,224 @@
+package export
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"prmate/internal/store"
+)
+
+var finished = time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC)
+
+func testRecords() []store.ReviewRecord {
+	return []store.ReviewRecord{
+		{ID: "r2", Owner: "acme", Repo: "api", PRNumber: 7, FinishedAt: finished.Add(time.Hour), ViolationsFound: 3,
+			RuleHits: map[string]int{"wrap-errors": 2, "no-todo": 1}},
+		{ID: "r1", Instance: "ghes", Owner: "acme", Repo: "web", PRNumber: 3, StartedAt: finished.Add(-time.Minute), FinishedAt: finished, Error: "llm unavailable"},
+	}
+}
+
+type fakeLister struct{ records []store.ReviewRecord }
+
+func (f *fakeLister) ListReviews(ctx context.Context, flt store.Filter) ([]store.ReviewRecord, error) {
+	return f.records, nil
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{url: "/var/lib/prmate/export", want: "*export.CSV"},
+		{url: "file:///var/lib/prmate/export", want: "*export.CSV"},
+		{url: "bigquery://my-project/prmate", want: "*export.BigQuery"},
+		{url: "bigquery://my-project", wantErr: true},
+		{url: "s3://bucket", wantErr: true},
+		{url: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		sink, err := Open(tt.url, "")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Open(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if err == nil && typeName(sink) != tt.want {
+			t.Errorf("Open(%q) = %s, want %s", tt.url, typeName(sink), tt.want)
+		}
+	}
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case *CSV:
+		return "*export.CSV"
+	case *BigQuery:
+		return "*export.BigQuery"
+	}
+	return "unknown"
+}
+
+func TestExporter_CSV(t *testing.T) {
+	dir := t.TempDir()
+	e := NewExporter(&fakeLister{records: testRecords()}, NewCSV(dir), time.Hour)
+	if err := e.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	reviews := readCSV(t, filepath.Join(dir, "reviews.csv"))
+	if len(reviews) != 3 || strings.Join(reviews[0], ",") != strings.Join(reviewColumns, ",") {
+		t.Fatalf("reviews.csv = %v", reviews)
+	}
+	// Oldest first; a zero start time is left empty
+	if reviews[1][0] != "r1" || reviews[1][1] != "ghes" || reviews[1][7] != "2026-10-14T09:29:00Z" || reviews[1][13] != "llm unavailable" {
+		t.Errorf("first review row = %v", reviews[1])
+	}
+	if reviews[2][0] != "r2" || reviews[2][7] != "" || reviews[2][11] != "3" {
+		t.Errorf("second review row = %v", reviews[2])
+	}
+
+	violations := readCSV(t, filepath.Join(dir, "violations.csv"))
+	if len(violations) != 3 || violations[1][5] != "no-todo" || violations[2][5] != "wrap-errors" || violations[2][6] != "2" {
+		t.Errorf("violations.csv = %v", violations)
+	}
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+type staticToken string
+
+func (s staticToken) Token(ctx context.Context) (string, error) { return string(s), nil }
+
+// fakeBigQuery records insertAll calls and answers the cursor query with
+// newest (microseconds since the epoch), or NULL when empty
+type fakeBigQuery struct {
+	mu       sync.Mutex
+	newest   string
+	created  int
+	inserted map[string][]string // table -> insertIds
+}
+
+func (f *fakeBigQuery) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/tables"):
+		f.created++
+		http.Error(w, `{"error":{"message":"Already Exists"}}`, http.StatusConflict)
+	case strings.HasSuffix(r.URL.Path, "/queries"):
+		v := "null"
+		if f.newest != "" {
+			v = `"` + f.newest + `"`
+		}
+		_, _ = w.Write([]byte(`{"jobComplete":true,"rows":[{"f":[{"v":` + v + `}]}]}`))
+	case strings.HasSuffix(r.URL.Path, "/insertAll"):
+		table := strings.Split(r.URL.Path, "/")[6]
+		var req struct {
+			Rows []insertRow `json:"rows"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		for _, row := range req.Rows {
+			f.inserted[table] = append(f.inserted[table], row.InsertID)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestBigQuery_Export(t *testing.T) {
+	fake := &fakeBigQuery{newest: strconv.FormatInt(finished.UnixMicro(), 10), inserted: map[string][]string{}} // r1 is exported
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	bq := NewBigQuery("proj", "prmate", staticToken("token"))
+	bq.endpoint = srv.URL
+	e := NewExporter(&fakeLister{records: testRecords()}, bq, time.Hour)
+
+	for range 2 {
+		if err := e.Run(context.Background()); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+
+	if fake.created != 2 {
+		t.Errorf("table creation attempts = %d, want 2 (once per table)", fake.created)
+	}
+	// r1 was already exported and the second run has nothing new
+	if got := strings.Join(fake.inserted[reviewsTable], ","); got != "r2" {
+		t.Errorf("reviews inserted = %s, want r2", got)
+	}
+	if got := strings.Join(fake.inserted[violationsTable], ","); got != "r2:no-todo,r2:wrap-errors" {
+		t.Errorf("violations inserted = %s", got)
+	}
+}
+
+func TestGoogleTokens_ServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+
+	var assertion string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		assertion = r.PostForm.Get("assertion")
+		_, _ = w.Write([]byte(`{"access_token":"ya29.token","expires_in":3600}`))
+	}))
+	defer srv.Close()
+
+	creds, _ := json.Marshal(map[string]string{
+		"client_email": "exporter@proj.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    srv.URL,
+	})
+	path := filepath.Join(t.TempDir(), "sa.json")
+	if err := os.WriteFile(path, creds, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := newGoogleTokens(path)
+	if err != nil {
+		t.Fatalf("newGoogleTokens() error = %v", err)
+	}
+	for range 2 {
+		tok, err := tokens.Token(context.Background())
+		if err != nil || tok != "ya29.token" {
+			t.Fatalf("Token() = %q, %v", tok, err)
+		}
+	}
+	if parts := strings.Split(assertion, "."); len(parts) != 3 {
+		t.Errorf("assertion = %q, want a signed JWT", assertion)
+	}
+}
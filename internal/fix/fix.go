@@ -0,0 +1,282 @@
+// Package fix applies PRMate's own findings to a pull request: the LLM
+// rewrites each affected file, and the result is committed in the PR
+// workspace and pushed to the PR branch.
+package fix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"prmate/internal/audit"
+	"prmate/internal/logging"
+	"prmate/internal/review"
+)
+
+const (
+	// MaxFiles bounds the files rewritten by a single fix command
+	MaxFiles = 10
+	// maxFileBytes skips files too large to send back through the LLM
+	maxFileBytes = 100 * 1024
+)
+
+// ErrNothingToFix is returned when no finding led to a change
+var ErrNothingToFix = errors.New("no changes to push")
+
+// LLMProvider generates the fixed file contents
+type LLMProvider interface {
+	GenerateTextWithContext(ctx context.Context, prompt string) (string, error)
+}
+
+// Repository is where fixes are pushed; the GitHub client satisfies it
+type Repository interface {
+	CloneURL(owner, repo string) string
+	DryRun(owner, repo string) bool
+	Audit(ctx context.Context, e audit.Event, writeErr error)
+}
+
+// Request asks for the findings on a pull request to be fixed
+type Request struct {
+	Owner     string
+	Repo      string
+	PRNumber  int
+	Branch    string // head branch the commit is pushed to
+	Dir       string // PR workspace checked out at the head commit
+	Requester string // GitHub login that asked for the fix
+	Findings  []review.OpenFinding
+}
+
+// Result describes the change made by a fix
+type Result struct {
+	Files     []string // files changed
+	Fixed     int      // findings in the changed files
+	Skipped   []string // files with findings that were left alone, with why
+	Diff      string
+	CommitSHA string // empty in dry-run mode
+}
+
+// Service turns findings into a pushed commit
+type Service struct {
+	llm  LLMProvider
+	repo Repository
+}
+
+// NewService creates a fix service
+func NewService(llm LLMProvider, repo Repository) *Service {
+	return &Service{llm: llm, repo: repo}
+}
+
+// Apply rewrites the files named by req.Findings, commits the changes and
+// pushes them to req.Branch. In dry-run mode the workspace is reset and the
+// diff is returned without pushing.
+func (s *Service) Apply(ctx context.Context, req Request) (*Result, error) {
+	byFile := make(map[string][]review.OpenFinding)
+	for _, f := range req.Findings {
+		byFile[f.Path] = append(byFile[f.Path], f)
+	}
+	paths := make([]string, 0, len(byFile))
+	for p := range byFile {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	result := &Result{}
+	if len(paths) > MaxFiles {
+		for _, p := range paths[MaxFiles:] {
+			result.Skipped = append(result.Skipped, p+": over the limit of files per fix")
+		}
+		paths = paths[:MaxFiles]
+	}
+
+	for _, path := range paths {
+		changed, err := s.fixFile(ctx, req.Dir, path, byFile[path])
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		if changed {
+			result.Files = append(result.Files, path)
+			result.Fixed += len(byFile[path])
+		}
+	}
+	if len(result.Files) == 0 {
+		return result, ErrNothingToFix
+	}
+
+	if out, err := git(ctx, req.Dir, append([]string{"add", "--"}, result.Files...)...); err != nil {
+		return nil, fmt.Errorf("git add: %s: %w", out, err)
+	}
+	diff, err := git(ctx, req.Dir, "diff", "--cached")
+	if err != nil {
+		return nil, fmt.Errorf("git diff: %s: %w", diff, err)
+	}
+	result.Diff = diff
+
+	event := audit.Event{
+		Action:   audit.ActionGitPush,
+		Owner:    req.Owner,
+		Repo:     req.Repo,
+		PRNumber: req.PRNumber,
+		Details:  map[string]string{"branch": req.Branch, "path": strings.Join(result.Files, ",")},
+	}
+	logger := logging.FromContext(ctx)
+	if s.repo.DryRun(req.Owner, req.Repo) {
+		logger.Info("dry run: would push fixes", "branch", req.Branch, "files", len(result.Files))
+		s.repo.Audit(ctx, event, nil)
+		if out, err := git(ctx, req.Dir, "reset", "--hard", "HEAD"); err != nil {
+			return nil, fmt.Errorf("reset workspace: %s: %w", out, err)
+		}
+		return result, nil
+	}
+
+	sha, err := s.commitAndPush(ctx, req, result)
+	s.repo.Audit(ctx, event, err)
+	if err != nil {
+		return nil, err
+	}
+	result.CommitSHA = sha
+	logger.Info("Pushed fixes", "branch", req.Branch, "commit", sha, "files", len(result.Files))
+	return result, nil
+}
+
+// fixFile asks the LLM for a corrected version of path and writes it,
+// reporting whether the file changed
+func (s *Service) fixFile(ctx context.Context, dir, path string, findings []review.OpenFinding) (bool, error) {
+	full := filepath.Join(dir, filepath.FromSlash(path))
+	if rel, err := filepath.Rel(dir, full); err != nil || strings.HasPrefix(rel, "..") {
+		return false, fmt.Errorf("path outside the workspace")
+	}
+	// A symlink committed in the PR could point anywhere on the server, so
+	// only regular files are fixed, and only where they really are
+	info, err := os.Lstat(full)
+	if err != nil {
+		return false, fmt.Errorf("file not found at the PR head")
+	}
+	if !info.Mode().IsRegular() {
+		return false, fmt.Errorf("not a regular file")
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false, fmt.Errorf("resolve workspace: %w", err)
+	}
+	full, err = filepath.EvalSymlinks(full)
+	if err != nil {
+		return false, fmt.Errorf("resolve file: %w", err)
+	}
+	if rel, err := filepath.Rel(root, full); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false, fmt.Errorf("path outside the workspace")
+	}
+	if info.Size() > maxFileBytes {
+		return false, fmt.Errorf("file too large to rewrite")
+	}
+	original, err := os.ReadFile(full)
+	if err != nil {
+		return false, fmt.Errorf("read file: %w", err)
+	}
+
+	response, err := s.llm.GenerateTextWithContext(ctx, buildPrompt(path, string(original), findings))
+	if err != nil {
+		return false, fmt.Errorf("generate fix: %w", err)
+	}
+	fixed, ok := extractFile(response)
+	if !ok {
+		return false, fmt.Errorf("the model did not return the file")
+	}
+	if strings.HasSuffix(string(original), "\n") && !strings.HasSuffix(fixed, "\n") {
+		fixed += "\n"
+	}
+	if fixed == string(original) {
+		return false, nil
+	}
+	if err := os.WriteFile(full, []byte(fixed), info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("write file: %w", err)
+	}
+	return true, nil
+}
+
+// buildPrompt asks for the complete corrected file, changed only where
+// the findings require
+func buildPrompt(path, content string, findings []review.OpenFinding) string {
+	var sb strings.Builder
+	sb.WriteString("You are fixing code review findings in a single file.\n\n")
+	fmt.Fprintf(&sb, "## File: %s\n\n```\n%s\n```\n\n", path, content)
+	sb.WriteString("## Findings to fix\n\n")
+	for _, f := range findings {
+		fmt.Fprintf(&sb, "- Line %d [%s] %s: %s\n", f.Line, f.Severity, f.Rule, f.Message)
+	}
+	sb.WriteString(`
+## Instructions
+
+Fix every finding above with the smallest change that addresses it. Do not
+reformat, reorder or otherwise change unrelated code. Line numbers refer to
+the file as shown and may be slightly off.
+
+Respond with the complete updated file in a single fenced code block and
+nothing else.
+`)
+	return sb.String()
+}
+
+// extractFile returns the contents of the first fenced code block in
+// response, which may contain fences of its own
+func extractFile(response string) (string, bool) {
+	lines := strings.Split(strings.TrimSpace(response), "\n")
+	start := -1
+	for i, l := range lines {
+		if strings.HasPrefix(strings.TrimSpace(l), "```") {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return "", false
+	}
+	end := -1
+	for i := len(lines) - 1; i > start; i-- {
+		if strings.TrimSpace(lines[i]) == "```" {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return "", false
+	}
+	return strings.Join(lines[start+1:end], "\n"), true
+}
+
+// commitAndPush commits the staged fixes and pushes them to the PR branch,
+// returning the new commit SHA. A failed push leaves the workspace at the
+// PR head.
+func (s *Service) commitAndPush(ctx context.Context, req Request, result *Result) (string, error) {
+	msg := fmt.Sprintf("Apply PRMate fixes for %d finding(s)", result.Fixed)
+	if req.Requester != "" {
+		msg += fmt.Sprintf("\n\nRequested by @%s in #%d.", req.Requester, req.PRNumber)
+	}
+	if out, err := git(ctx, req.Dir, "-c", "user.name=PRMate Bot", "-c", "user.email=prmate@github.com", "commit", "--quiet", "-m", msg); err != nil {
+		return "", fmt.Errorf("git commit: %s: %w", out, err)
+	}
+	sha, err := git(ctx, req.Dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse: %s: %w", sha, err)
+	}
+
+	url := s.repo.CloneURL(req.Owner, req.Repo)
+	if out, err := git(ctx, req.Dir, "push", "--quiet", url, "HEAD:refs/heads/"+req.Branch); err != nil {
+		_, _ = git(ctx, req.Dir, "reset", "--hard", "HEAD~1")
+		return "", fmt.Errorf("git push: %s: %w", strings.ReplaceAll(out, url, "<remote>"), err)
+	}
+	return sha, nil
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	out, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
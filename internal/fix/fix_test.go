@@ -0,0 +1,209 @@
+package fix
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"prmate/internal/audit"
+	"prmate/internal/review"
+)
+
+type fakeLLM struct {
+	response string
+	prompts  []string
+}
+
+func (f *fakeLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	f.prompts = append(f.prompts, prompt)
+	return f.response, nil
+}
+
+type fakeRepo struct {
+	url    string
+	dryRun bool
+	events []audit.Event
+}
+
+func (f *fakeRepo) CloneURL(owner, repo string) string { return f.url }
+func (f *fakeRepo) DryRun(owner, repo string) bool     { return f.dryRun }
+func (f *fakeRepo) Audit(ctx context.Context, e audit.Event, writeErr error) {
+	f.events = append(f.events, e)
+}
+
+// newWorkspace creates an upstream repository with a feature branch and a
+// detached checkout of it, as a PR workspace would be
+func newWorkspace(t *testing.T) (upstream, workspace string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	run := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	upstream = t.TempDir()
+	run(upstream, "init", "--quiet", "--bare")
+
+	workspace = t.TempDir()
+	run(workspace, "init", "--quiet")
+	if err := os.WriteFile(filepath.Join(workspace, "main.go"), []byte("package main\n\nfunc run() error {\n\treturn err\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(workspace, "add", "main.go")
+	run(workspace, "commit", "--quiet", "-m", "add main")
+	run(workspace, "push", "--quiet", upstream, "HEAD:refs/heads/feature")
+	run(workspace, "checkout", "--quiet", "--detach")
+	return upstream, workspace
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := git(context.Background(), dir, args...)
+	if err != nil {
+		t.Fatalf("git %v: %s: %v", args, out, err)
+	}
+	return out
+}
+
+const fixedFile = "```go\npackage main\n\nimport \"fmt\"\n\nfunc run() error {\n\treturn fmt.Errorf(\"run: %w\", err)\n}\n```"
+
+func testRequest(dir string) Request {
+	return Request{
+		Owner: "acme", Repo: "api", PRNumber: 7, Branch: "feature", Dir: dir, Requester: "octocat",
+		Findings: []review.OpenFinding{
+			{Path: "main.go", Line: 4, Rule: "wrap-errors", Severity: "error", Message: "wrap the error"},
+			{Path: "gone.go", Line: 1, Rule: "naming", Severity: "warning", Message: "rename"},
+		},
+	}
+}
+
+func TestService_Apply(t *testing.T) {
+	upstream, workspace := newWorkspace(t)
+	llm := &fakeLLM{response: "Here you go:\n" + fixedFile}
+	repo := &fakeRepo{url: upstream}
+
+	result, err := NewService(llm, repo).Apply(context.Background(), testRequest(workspace))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Fixed != 1 || len(result.Files) != 1 || len(result.Skipped) != 1 || !strings.HasPrefix(result.Skipped[0], "gone.go") {
+		t.Errorf("result = %+v", result)
+	}
+	if len(llm.prompts) != 1 || !strings.Contains(llm.prompts[0], "Line 4 [error] wrap-errors: wrap the error") {
+		t.Errorf("prompts = %q", llm.prompts)
+	}
+
+	if got := gitOutput(t, upstream, "rev-parse", "feature"); got != result.CommitSHA {
+		t.Errorf("feature = %s, want pushed commit %s", got, result.CommitSHA)
+	}
+	if got := gitOutput(t, upstream, "show", "feature:main.go"); !strings.Contains(got, `fmt.Errorf("run: %w", err)`) {
+		t.Errorf("pushed main.go = %s", got)
+	}
+	if msg := gitOutput(t, upstream, "log", "-1", "--format=%B", "feature"); !strings.Contains(msg, "Requested by @octocat in #7") {
+		t.Errorf("commit message = %q", msg)
+	}
+	if len(repo.events) != 1 || repo.events[0].Action != audit.ActionGitPush {
+		t.Errorf("audit events = %+v", repo.events)
+	}
+}
+
+func TestService_ApplyDryRun(t *testing.T) {
+	upstream, workspace := newWorkspace(t)
+	head := gitOutput(t, upstream, "rev-parse", "feature")
+	repo := &fakeRepo{url: upstream, dryRun: true}
+
+	result, err := NewService(&fakeLLM{response: fixedFile}, repo).Apply(context.Background(), testRequest(workspace))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.CommitSHA != "" || !strings.Contains(result.Diff, `+	return fmt.Errorf("run: %w", err)`) {
+		t.Errorf("result = %+v", result)
+	}
+	if got := gitOutput(t, upstream, "rev-parse", "feature"); got != head {
+		t.Error("dry run pushed a commit")
+	}
+	if status := gitOutput(t, workspace, "status", "--porcelain"); status != "" {
+		t.Errorf("workspace left dirty: %s", status)
+	}
+	if len(repo.events) != 1 {
+		t.Errorf("audit events = %+v", repo.events)
+	}
+}
+
+func TestService_ApplyNothingToFix(t *testing.T) {
+	_, workspace := newWorkspace(t)
+	original, _ := os.ReadFile(filepath.Join(workspace, "main.go"))
+	llm := &fakeLLM{response: "```go\n" + string(original) + "```"}
+
+	_, err := NewService(llm, &fakeRepo{}).Apply(context.Background(), testRequest(workspace))
+	if !errors.Is(err, ErrNothingToFix) {
+		t.Errorf("Apply() error = %v, want ErrNothingToFix", err)
+	}
+}
+
+func TestExtractFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+		ok       bool
+	}{
+		{name: "plain block", response: "```\na\nb\n```", want: "a\nb", ok: true},
+		{name: "with prose", response: "Sure:\n```go\na\n```\nDone.", want: "a", ok: true},
+		{name: "nested fences", response: "```markdown\n# Doc\n```sh\nls\n```\n```", want: "# Doc\n```sh\nls\n```", ok: true},
+		{name: "no block", response: "package main", ok: false},
+		{name: "unterminated", response: "```go\npackage main", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := extractFile(tt.response)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("extractFile() = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestService_ApplySkipsSymlinks(t *testing.T) {
+	_, workspace := newWorkspace(t)
+	outside := filepath.Join(t.TempDir(), "secret.go")
+	if err := os.WriteFile(outside, []byte("package secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(workspace, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Dir(outside), filepath.Join(workspace, "linked")); err != nil {
+		t.Fatal(err)
+	}
+
+	llm := &fakeLLM{response: fixedFile}
+	req := testRequest(workspace)
+	req.Findings = []review.OpenFinding{
+		{Path: "link.go", Line: 1, Rule: "naming", Severity: "warning", Message: "rename"},
+		{Path: "linked/secret.go", Line: 1, Rule: "naming", Severity: "warning", Message: "rename"},
+	}
+	result, err := NewService(llm, &fakeRepo{}).Apply(context.Background(), req)
+	if !errors.Is(err, ErrNothingToFix) {
+		t.Errorf("Apply() = %+v, %v; want ErrNothingToFix", result, err)
+	}
+	if len(llm.prompts) != 0 {
+		t.Errorf("the LLM was sent %d files through symlinks", len(llm.prompts))
+	}
+	if got, _ := os.ReadFile(outside); string(got) != "package secret\n" {
+		t.Errorf("file outside the workspace = %q, want it untouched", got)
+	}
+}
@@ -2,18 +2,54 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v82/github"
+	"go.opentelemetry.io/otel/attribute"
+
+	"prmate/internal/audit"
+	"prmate/internal/breaker"
+	"prmate/internal/correlation"
+	"prmate/internal/errclass"
+	"prmate/internal/logging"
+	"prmate/internal/tracing"
 )
 
+// DefaultHost is the host of the public GitHub instance
+const DefaultHost = "github.com"
+
 // Client provides GitHub API operations
 type Client struct {
-	client *github.Client
-	token  string
+	client    *github.Client
+	transport *tokenTransport
+	token     string
+	host      string
+	auditor   audit.Recorder
+	dryRun    dryRunPolicy
+
+	loginMu sync.Mutex
+	login   string // of the token's account, once looked up
+}
+
+// dryRunPolicy decides which repositories only log their writes
+type dryRunPolicy struct {
+	all   bool
+	repos map[string]bool // "owner/repo" or "owner/*", lower-cased
+}
+
+func (p dryRunPolicy) matches(owner, repo string) bool {
+	if p.all {
+		return true
+	}
+	owner, repo = strings.ToLower(owner), strings.ToLower(repo)
+	return p.repos[owner+"/"+repo] || p.repos[owner+"/*"]
 }
 
 // NewClient creates a new GitHub API client
@@ -22,23 +58,108 @@ func NewClient(token string) *Client {
 		token = os.Getenv("GITHUB_TOKEN")
 	}
 
+	transport := &tokenTransport{token: token}
 	httpClient := &http.Client{
-		Transport: &tokenTransport{token: token},
+		Transport: transport,
 	}
 
 	return &Client{
-		client: github.NewClient(httpClient),
-		token:  token,
+		client:    github.NewClient(httpClient),
+		transport: transport,
+		token:     token,
+		host:      DefaultHost,
 	}
 }
 
+// NewEnterpriseClient creates a GitHub API client for a GitHub Enterprise
+// Server instance reachable at apiURL (e.g. https://ghe.example.com/api/v3/)
+func NewEnterpriseClient(token, apiURL string) (*Client, error) {
+	parsed, err := url.Parse(apiURL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid enterprise api url %q", apiURL)
+	}
+
+	transport := &tokenTransport{token: token}
+	httpClient := &http.Client{
+		Transport: transport,
+	}
+
+	client, err := github.NewClient(httpClient).WithEnterpriseURLs(apiURL, apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("configure enterprise urls: %w", err)
+	}
+
+	return &Client{
+		client:    client,
+		transport: transport,
+		token:     token,
+		host:      parsed.Host,
+	}, nil
+}
+
+// SetBreaker guards every API call with b: network errors, 5xx and 429
+// responses count as failures, and calls fail at once while b is open
+func (c *Client) SetBreaker(b *breaker.Breaker) {
+	c.transport.breaker = b
+}
+
 type tokenTransport struct {
-	token string
+	token   string
+	breaker *breaker.Breaker
 }
 
 func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	ctx, span := tracing.Start(req.Context(), "github "+req.Method,
+		attribute.String("http.method", req.Method),
+		attribute.String("http.url_path", req.URL.Path),
+	)
+	req = req.WithContext(ctx)
+
 	req.Header.Set("Authorization", "token "+t.token)
-	return http.DefaultTransport.RoundTrip(req)
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if resp != nil {
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	}
+	tracing.End(span, err)
+
+	// A request its caller gave up on says nothing about GitHub
+	failed := err != nil && !errors.Is(err, context.Canceled)
+	if resp != nil && (resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests) {
+		failed = true
+	}
+	t.breaker.Done(failed)
+	return resp, err
+}
+
+// CheckAuth verifies the token by fetching the authenticated user
+func (c *Client) CheckAuth(ctx context.Context) error {
+	if c.token == "" {
+		return fmt.Errorf("github token not configured")
+	}
+	if _, _, err := c.client.Users.Get(ctx, ""); err != nil {
+		return fmt.Errorf("get authenticated user: %w", classify(err))
+	}
+	return nil
+}
+
+// Login returns the login of the account the token belongs to, looking it
+// up on first use
+func (c *Client) Login(ctx context.Context) (string, error) {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+	if c.login != "" {
+		return c.login, nil
+	}
+	user, _, err := c.client.Users.Get(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("get authenticated user: %w", classify(err))
+	}
+	c.login = user.GetLogin()
+	return c.login, nil
 }
 
 // GetToken returns the configured token (for repo cloning)
@@ -63,7 +184,7 @@ func (c *Client) GetPRFiles(ctx context.Context, owner, repo string, prNumber in
 	for {
 		files, resp, err := c.client.PullRequests.ListFiles(ctx, owner, repo, prNumber, opts)
 		if err != nil {
-			return nil, fmt.Errorf("list pr files: %w", err)
+			return nil, fmt.Errorf("list pr files: %w", classify(err))
 		}
 
 		for _, f := range files {
@@ -85,11 +206,25 @@ func (c *Client) GetPRFiles(ctx context.Context, owner, repo string, prNumber in
 	return allFiles, nil
 }
 
+// CompareFiles returns the merge base of base and head and the files
+// changed between them
+func (c *Client) CompareFiles(ctx context.Context, owner, repo, base, head string) (string, []string, error) {
+	cmp, _, err := c.client.Repositories.CompareCommits(ctx, owner, repo, base, head, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return "", nil, fmt.Errorf("compare commits: %w", classify(err))
+	}
+	files := make([]string, 0, len(cmp.Files))
+	for _, f := range cmp.Files {
+		files = append(files, f.GetFilename())
+	}
+	return cmp.GetMergeBaseCommit().GetSHA(), files, nil
+}
+
 // GetPRBranch returns the branch name of a PR
 func (c *Client) GetPRBranch(ctx context.Context, owner, repo string, prNumber int) (string, error) {
 	pr, _, err := c.client.PullRequests.Get(ctx, owner, repo, prNumber)
 	if err != nil {
-		return "", fmt.Errorf("get pr: %w", err)
+		return "", fmt.Errorf("get pr: %w", classify(err))
 	}
 	return pr.GetHead().GetRef(), nil
 }
@@ -100,11 +235,11 @@ func (c *Client) GetFileContent(ctx context.Context, owner, repo, path, ref stri
 		Ref: ref,
 	})
 	if err != nil {
-		return "", fmt.Errorf("get file content: %w", err)
+		return "", fmt.Errorf("get file content: %w", classify(err))
 	}
 
 	if content == nil {
-		return "", fmt.Errorf("file not found: %s", path)
+		return "", errclass.Wrap(fmt.Errorf("file not found: %s", path), errclass.ErrNotFound)
 	}
 
 	decoded, err := content.GetContent()
@@ -115,20 +250,172 @@ func (c *Client) GetFileContent(ctx context.Context, owner, repo, path, ref stri
 	return decoded, nil
 }
 
+// ListDirectory returns the paths of the files directly in a directory
+// of a repo, leaving out subdirectories
+func (c *Client) ListDirectory(ctx context.Context, owner, repo, path, ref string) ([]string, error) {
+	_, entries, _, err := c.client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{
+		Ref: ref,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list directory: %w", classify(err))
+	}
+
+	var files []string
+	for _, e := range entries {
+		if e.GetType() == "file" {
+			files = append(files, e.GetPath())
+		}
+	}
+	return files, nil
+}
+
+// GetFileSize returns the size in bytes of a file in a repo. Unlike
+// GetFileContent it works for files over the contents API's 1 MB limit.
+func (c *Client) GetFileSize(ctx context.Context, owner, repo, path, ref string) (int64, error) {
+	content, _, _, err := c.client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{
+		Ref: ref,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("get file content: %w", classify(err))
+	}
+
+	if content == nil {
+		return 0, errclass.Wrap(fmt.Errorf("file not found: %s", path), errclass.ErrNotFound)
+	}
+
+	return int64(content.GetSize()), nil
+}
+
+// SetDryRun makes writes log instead of calling GitHub, for every repository
+// when all is set, otherwise for the listed "owner/repo" or "owner/*" entries
+func (c *Client) SetDryRun(all bool, repos []string) {
+	c.dryRun = dryRunPolicy{all: all, repos: make(map[string]bool, len(repos))}
+	for _, r := range repos {
+		c.dryRun.repos[strings.ToLower(strings.TrimSpace(r))] = true
+	}
+}
+
+// DryRun reports whether writes to owner/repo are only logged
+func (c *Client) DryRun(owner, repo string) bool {
+	return c.dryRun.matches(owner, repo)
+}
+
+// SetAuditor records every write made through this client to a
+func (c *Client) SetAuditor(a audit.Recorder) {
+	c.auditor = a
+}
+
+// Audit records a write against this client's GitHub instance. Writes made
+// outside the API (such as git pushes) call it directly. Audit failures are
+// logged rather than failing the write.
+func (c *Client) Audit(ctx context.Context, e audit.Event, writeErr error) {
+	if c.auditor == nil {
+		return
+	}
+	if e.Host == "" {
+		e.Host = c.host
+	}
+	e.DryRun = c.DryRun(e.Owner, e.Repo)
+	if err := c.auditor.Record(ctx, audit.Complete(ctx, e, writeErr)); err != nil {
+		logging.FromContext(ctx).Warn("failed to record audit event", "action", e.Action, "error", err)
+	}
+}
+
 // CreatePRComment creates a comment on a PR
 func (c *Client) CreatePRComment(ctx context.Context, owner, repo string, prNumber int, body string) error {
-	_, _, err := c.client.Issues.CreateComment(ctx, owner, repo, prNumber, &github.IssueComment{
+	body = correlation.AppendMarker(ctx, body)
+	if c.DryRun(owner, repo) {
+		logging.FromContext(ctx).Info("dry run: would create PR comment", "repo", owner+"/"+repo, "pr", prNumber, "body", body)
+		c.Audit(ctx, audit.Event{Action: audit.ActionCommentCreate, Owner: owner, Repo: repo, PRNumber: prNumber,
+			Details: map[string]string{"body_bytes": fmt.Sprint(len(body))}}, nil)
+		return nil
+	}
+	comment, _, err := c.client.Issues.CreateComment(ctx, owner, repo, prNumber, &github.IssueComment{
 		Body: github.Ptr(body),
 	})
+	c.Audit(ctx, audit.Event{
+		Action:   audit.ActionCommentCreate,
+		Owner:    owner,
+		Repo:     repo,
+		PRNumber: prNumber,
+		Details:  map[string]string{"body_bytes": fmt.Sprint(len(body)), "comment_id": fmt.Sprint(comment.GetID())},
+	}, err)
 	if err != nil {
-		return fmt.Errorf("create pr comment: %w", err)
+		return fmt.Errorf("create pr comment: %w", classify(err))
 	}
 	return nil
 }
 
+// UpdatePRComment replaces the body of an issue-level comment on a PR
+func (c *Client) UpdatePRComment(ctx context.Context, owner, repo string, prNumber int, commentID int64, body string) error {
+	body = correlation.AppendMarker(ctx, body)
+	details := map[string]string{"body_bytes": fmt.Sprint(len(body)), "comment_id": fmt.Sprint(commentID)}
+	if c.DryRun(owner, repo) {
+		logging.FromContext(ctx).Info("dry run: would update PR comment", "repo", owner+"/"+repo, "comment_id", commentID, "body", body)
+		c.Audit(ctx, audit.Event{Action: audit.ActionCommentUpdate, Owner: owner, Repo: repo, PRNumber: prNumber, Details: details}, nil)
+		return nil
+	}
+	_, _, err := c.client.Issues.EditComment(ctx, owner, repo, commentID, &github.IssueComment{
+		Body: github.Ptr(body),
+	})
+	c.Audit(ctx, audit.Event{Action: audit.ActionCommentUpdate, Owner: owner, Repo: repo, PRNumber: prNumber, Details: details}, err)
+	if err != nil {
+		return fmt.Errorf("update pr comment: %w", classify(err))
+	}
+	return nil
+}
+
+// CreateIssue opens an issue and returns its URL; in dry-run mode it only
+// logs the issue and returns ""
+func (c *Client) CreateIssue(ctx context.Context, owner, repo, title, body string, labels []string) (string, error) {
+	if c.DryRun(owner, repo) {
+		logging.FromContext(ctx).Info("dry run: would create issue", "repo", owner+"/"+repo, "title", title, "body", body)
+		c.Audit(ctx, audit.Event{Action: audit.ActionIssueCreate, Owner: owner, Repo: repo,
+			Details: map[string]string{"title": title, "body_bytes": fmt.Sprint(len(body))}}, nil)
+		return "", nil
+	}
+	req := &github.IssueRequest{
+		Title: github.Ptr(title),
+		Body:  github.Ptr(body),
+	}
+	if len(labels) > 0 {
+		req.Labels = &labels
+	}
+	issue, _, err := c.client.Issues.Create(ctx, owner, repo, req)
+	c.Audit(ctx, audit.Event{
+		Action:  audit.ActionIssueCreate,
+		Owner:   owner,
+		Repo:    repo,
+		Details: map[string]string{"title": title, "body_bytes": fmt.Sprint(len(body)), "issue": fmt.Sprint(issue.GetNumber())},
+	}, err)
+	if err != nil {
+		return "", fmt.Errorf("create issue: %w", classify(err))
+	}
+	return issue.GetHTMLURL(), nil
+}
+
+// GetPermission returns user's permission on a repository: admin, write,
+// read or none
+func (c *Client) GetPermission(ctx context.Context, owner, repo, user string) (string, error) {
+	perm, _, err := c.client.Repositories.GetPermissionLevel(ctx, owner, repo, user)
+	if err != nil {
+		return "", fmt.Errorf("get permission level: %w", classify(err))
+	}
+	return perm.GetPermission(), nil
+}
+
+// GetHost returns the host of the GitHub instance this client talks to
+func (c *Client) GetHost() string {
+	return c.host
+}
+
 // CloneURL returns the authenticated clone URL for a repo
 func (c *Client) CloneURL(owner, repo string) string {
-	return fmt.Sprintf("https://%s@github.com/%s/%s.git", c.token, owner, repo)
+	host := c.host
+	if host == "" {
+		host = DefaultHost
+	}
+	return fmt.Sprintf("https://%s@%s/%s/%s.git", c.token, host, owner, repo)
 }
 
 // ParseRepoFullName splits "owner/repo" into parts
@@ -148,29 +435,283 @@ type PullRequest struct {
 	State     string
 	HeadSHA   string
 	HeadRef   string
+	HeadRepo  string // owner/repo the head branch lives in; differs for forks
+	BaseRepo  string // owner/repo the pull request is opened in
 	BaseSHA   string
 	BaseRef   string
 	Mergeable bool
+	// MergeableState is "dirty" when the PR has conflicts and "unknown"
+	// while GitHub is still computing it
+	MergeableState string
+	HTMLURL        string
+	Draft          bool
+	Author         string   // login of the PR author
+	Reviewers      []string // logins of the requested reviewers
+	Labels         []string
+	UpdatedAt      time.Time
+	MergedAt       time.Time // zero unless merged
+}
+
+// FromFork reports whether the head branch lives outside the repository
+// the pull request is opened in, including forks that were deleted since
+func (pr *PullRequest) FromFork() bool {
+	return pr.BaseRepo != "" && !strings.EqualFold(pr.HeadRepo, pr.BaseRepo)
+}
+
+// ContentRef returns the ref to read the head's files at through the base
+// repository: the head branch, or the head commit for forks, whose branch
+// the base repository does not have
+func (pr *PullRequest) ContentRef() string {
+	if pr.FromFork() {
+		return pr.HeadSHA
+	}
+	return pr.HeadRef
+}
+
+// ConfigRef returns the ref to read .prmate.md at: the head branch, or the
+// base branch for forks, so that pull requests from outside the repository
+// cannot change the rules they are reviewed by
+func (pr *PullRequest) ConfigRef() string {
+	if pr.FromFork() {
+		return pr.BaseRef
+	}
+	return pr.HeadRef
 }
 
 // GetPullRequest fetches full PR details
 func (c *Client) GetPullRequest(ctx context.Context, owner, repo string, prNumber int) (*PullRequest, error) {
 	pr, _, err := c.client.PullRequests.Get(ctx, owner, repo, prNumber)
 	if err != nil {
-		return nil, fmt.Errorf("get pull request: %w", err)
+		return nil, fmt.Errorf("get pull request: %w", classify(err))
+	}
+
+	return toPullRequest(pr), nil
+}
+
+// ListOpenPullRequests lists every open PR in a repository, oldest first
+func (c *Client) ListOpenPullRequests(ctx context.Context, owner, repo string) ([]PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		State:       "open",
+		Sort:        "created",
+		Direction:   "asc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var all []PullRequest
+
+	for {
+		prs, resp, err := c.client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list pull requests: %w", classify(err))
+		}
+
+		for _, pr := range prs {
+			all = append(all, *toPullRequest(pr))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return all, nil
+}
+
+// FindPullRequest returns the most recent PR, open or closed, whose head is
+// branch in the repository itself, or nil when there is none
+func (c *Client) FindPullRequest(ctx context.Context, owner, repo, branch string) (*PullRequest, error) {
+	prs, _, err := c.client.PullRequests.List(ctx, owner, repo, &github.PullRequestListOptions{
+		State:       "all",
+		Head:        owner + ":" + branch,
+		ListOptions: github.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list pull requests: %w", classify(err))
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	return toPullRequest(prs[0]), nil
+}
+
+// NewPullRequest describes a pull request to open
+type NewPullRequest struct {
+	Title  string
+	Body   string
+	Head   string // branch with the changes
+	Base   string // branch to merge into
+	Labels []string
+}
+
+// CreatePullRequest opens a pull request and labels it. In dry-run mode it
+// only logs the pull request and returns nil.
+func (c *Client) CreatePullRequest(ctx context.Context, owner, repo string, req NewPullRequest) (*PullRequest, error) {
+	details := map[string]string{"title": req.Title, "head": req.Head, "base": req.Base}
+	if c.DryRun(owner, repo) {
+		logging.FromContext(ctx).Info("dry run: would create pull request", "repo", owner+"/"+repo, "title", req.Title, "head", req.Head, "base", req.Base)
+		c.Audit(ctx, audit.Event{Action: audit.ActionPRCreate, Owner: owner, Repo: repo, Details: details}, nil)
+		return nil, nil
+	}
+	pr, _, err := c.client.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
+		Title: github.Ptr(req.Title),
+		Body:  github.Ptr(correlation.AppendMarker(ctx, req.Body)),
+		Head:  github.Ptr(req.Head),
+		Base:  github.Ptr(req.Base),
+	})
+	c.Audit(ctx, audit.Event{
+		Action:   audit.ActionPRCreate,
+		Owner:    owner,
+		Repo:     repo,
+		PRNumber: pr.GetNumber(),
+		Details:  details,
+	}, err)
+	if err != nil {
+		return nil, fmt.Errorf("create pull request: %w", classify(err))
+	}
+	if len(req.Labels) > 0 {
+		if _, _, err := c.client.Issues.AddLabelsToIssue(ctx, owner, repo, pr.GetNumber(), req.Labels); err != nil {
+			return nil, fmt.Errorf("label pull request: %w", classify(err))
+		}
+	}
+	return toPullRequest(pr), nil
+}
+
+// GetDefaultBranch returns the branch pull requests merge into by default
+func (c *Client) GetDefaultBranch(ctx context.Context, owner, repo string) (string, error) {
+	r, _, err := c.client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return "", fmt.Errorf("get repository: %w", classify(err))
+	}
+	return r.GetDefaultBranch(), nil
+}
+
+// ListLabels returns the names of the labels defined in a repository
+func (c *Client) ListLabels(ctx context.Context, owner, repo string) ([]string, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var names []string
+	for {
+		labels, resp, err := c.client.Issues.ListLabels(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list labels: %w", classify(err))
+		}
+		for _, l := range labels {
+			names = append(names, l.GetName())
+		}
+		if resp.NextPage == 0 {
+			return names, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// ListFiles returns the paths of every file in a repository at ref. GitHub
+// truncates the listing of very large repositories; the paths it returned
+// are kept.
+func (c *Client) ListFiles(ctx context.Context, owner, repo, ref string) ([]string, error) {
+	tree, _, err := c.client.Git.GetTree(ctx, owner, repo, ref, true)
+	if err != nil {
+		return nil, fmt.Errorf("get tree: %w", classify(err))
+	}
+	var files []string
+	for _, e := range tree.Entries {
+		if e.GetType() == "blob" {
+			files = append(files, e.GetPath())
+		}
 	}
+	return files, nil
+}
+
+// ListOrgRepos returns the names of the repositories of an organization,
+// leaving out archived ones
+func (c *Client) ListOrgRepos(ctx context.Context, org string) ([]string, error) {
+	opts := &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var names []string
+	for {
+		repos, resp, err := c.client.Repositories.ListByOrg(ctx, org, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list repositories: %w", classify(err))
+		}
+		for _, r := range repos {
+			if !r.GetArchived() {
+				names = append(names, r.GetName())
+			}
+		}
+		if resp.NextPage == 0 {
+			return names, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// maxClosedPages bounds how far ListMergedPullRequests pages back through
+// closed PRs that were not merged
+const maxClosedPages = 10
+
+// ListMergedPullRequests lists up to limit merged PRs of a repository, most
+// recently updated first
+func (c *Client) ListMergedPullRequests(ctx context.Context, owner, repo string, limit int) ([]PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		State:       "closed",
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var merged []PullRequest
+
+	for page := 0; page < maxClosedPages; page++ {
+		prs, resp, err := c.client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list pull requests: %w", classify(err))
+		}
+
+		for _, pr := range prs {
+			if pr.MergedAt == nil {
+				continue
+			}
+			merged = append(merged, *toPullRequest(pr))
+			if len(merged) == limit {
+				return merged, nil
+			}
+		}
 
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return merged, nil
+}
+
+func toPullRequest(pr *github.PullRequest) *PullRequest {
+	var reviewers, labels []string
+	for _, u := range pr.RequestedReviewers {
+		reviewers = append(reviewers, u.GetLogin())
+	}
+	for _, l := range pr.Labels {
+		labels = append(labels, l.GetName())
+	}
 	return &PullRequest{
-		Number:    pr.GetNumber(),
-		Title:     pr.GetTitle(),
-		Body:      pr.GetBody(),
-		State:     pr.GetState(),
-		HeadSHA:   pr.GetHead().GetSHA(),
-		HeadRef:   pr.GetHead().GetRef(),
-		BaseSHA:   pr.GetBase().GetSHA(),
-		BaseRef:   pr.GetBase().GetRef(),
-		Mergeable: pr.GetMergeable(),
-	}, nil
+		Number:         pr.GetNumber(),
+		Title:          pr.GetTitle(),
+		Body:           pr.GetBody(),
+		State:          pr.GetState(),
+		HeadSHA:        pr.GetHead().GetSHA(),
+		HeadRef:        pr.GetHead().GetRef(),
+		HeadRepo:       pr.GetHead().GetRepo().GetFullName(),
+		BaseRepo:       pr.GetBase().GetRepo().GetFullName(),
+		BaseSHA:        pr.GetBase().GetSHA(),
+		BaseRef:        pr.GetBase().GetRef(),
+		Mergeable:      pr.GetMergeable(),
+		MergeableState: pr.GetMergeableState(),
+		HTMLURL:        pr.GetHTMLURL(),
+		Draft:          pr.GetDraft(),
+		Author:         pr.GetUser().GetLogin(),
+		Reviewers:      reviewers,
+		Labels:         labels,
+		UpdatedAt:      pr.GetUpdatedAt().Time,
+		MergedAt:       pr.GetMergedAt().Time,
+	}
 }
 
 // Commit represents a commit in a PR
@@ -188,7 +729,7 @@ func (c *Client) ListPRCommits(ctx context.Context, owner, repo string, prNumber
 	for {
 		commits, resp, err := c.client.PullRequests.ListCommits(ctx, owner, repo, prNumber, opts)
 		if err != nil {
-			return nil, fmt.Errorf("list pr commits: %w", err)
+			return nil, fmt.Errorf("list pr commits: %w", classify(err))
 		}
 
 		for _, c := range commits {
@@ -217,6 +758,9 @@ type ReviewComment struct {
 	Body      string
 	CommitID  string
 	CreatedAt string
+	Author    string // login of the commenter
+	Bot       bool   // written by a GitHub App or bot account
+	DiffHunk  string // the diff shown with the comment, ending at its line
 }
 
 // ListReviewComments lists all review comments on a PR
@@ -229,7 +773,7 @@ func (c *Client) ListReviewComments(ctx context.Context, owner, repo string, prN
 	for {
 		comments, resp, err := c.client.PullRequests.ListComments(ctx, owner, repo, prNumber, opts)
 		if err != nil {
-			return nil, fmt.Errorf("list review comments: %w", err)
+			return nil, fmt.Errorf("list review comments: %w", classify(err))
 		}
 
 		for _, c := range comments {
@@ -241,6 +785,9 @@ func (c *Client) ListReviewComments(ctx context.Context, owner, repo string, prN
 				Body:      c.GetBody(),
 				CommitID:  c.GetCommitID(),
 				CreatedAt: c.GetCreatedAt().String(),
+				Author:    c.GetUser().GetLogin(),
+				Bot:       c.GetUser().GetType() == "Bot",
+				DiffHunk:  c.GetDiffHunk(),
 			})
 		}
 
@@ -253,12 +800,254 @@ func (c *Client) ListReviewComments(ctx context.Context, owner, repo string, prN
 	return allComments, nil
 }
 
+// GetReviewComment returns one review comment of a repository
+func (c *Client) GetReviewComment(ctx context.Context, owner, repo string, commentID int64) (ReviewComment, error) {
+	comment, _, err := c.client.PullRequests.GetComment(ctx, owner, repo, commentID)
+	if err != nil {
+		return ReviewComment{}, fmt.Errorf("get review comment: %w", classify(err))
+	}
+	return ReviewComment{
+		ID:        comment.GetID(),
+		Path:      comment.GetPath(),
+		Line:      comment.GetLine(),
+		Side:      comment.GetSide(),
+		Body:      comment.GetBody(),
+		CommitID:  comment.GetCommitID(),
+		CreatedAt: comment.GetCreatedAt().String(),
+		Author:    comment.GetUser().GetLogin(),
+		Bot:       comment.GetUser().GetType() == "Bot",
+		DiffHunk:  comment.GetDiffHunk(),
+	}, nil
+}
+
+// ReplyToReviewComment answers a review comment in its thread
+func (c *Client) ReplyToReviewComment(ctx context.Context, owner, repo string, prNumber int, commentID int64, body string) error {
+	body = correlation.AppendMarker(ctx, body)
+	details := map[string]string{"body_bytes": fmt.Sprint(len(body)), "in_reply_to": fmt.Sprint(commentID)}
+	if c.DryRun(owner, repo) {
+		logging.FromContext(ctx).Info("dry run: would reply to review comment", "repo", owner+"/"+repo, "pr", prNumber, "comment_id", commentID, "body", body)
+		c.Audit(ctx, audit.Event{Action: audit.ActionCommentCreate, Owner: owner, Repo: repo, PRNumber: prNumber, Details: details}, nil)
+		return nil
+	}
+	_, _, err := c.client.PullRequests.CreateCommentInReplyTo(ctx, owner, repo, prNumber, body, commentID)
+	c.Audit(ctx, audit.Event{Action: audit.ActionCommentCreate, Owner: owner, Repo: repo, PRNumber: prNumber, Details: details}, err)
+	if err != nil {
+		return fmt.Errorf("reply to review comment: %w", classify(err))
+	}
+	return nil
+}
+
+// PRReview is a review submitted on a PR
+type PRReview struct {
+	Author      string
+	Bot         bool   // submitted by a GitHub App or bot account
+	State       string // APPROVED, CHANGES_REQUESTED, COMMENTED or DISMISSED
+	SubmittedAt time.Time
+}
+
+// ListPRReviews lists the reviews submitted on a PR, oldest first
+func (c *Client) ListPRReviews(ctx context.Context, owner, repo string, prNumber int) ([]PRReview, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var all []PRReview
+
+	for {
+		reviews, resp, err := c.client.PullRequests.ListReviews(ctx, owner, repo, prNumber, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list pr reviews: %w", classify(err))
+		}
+
+		for _, r := range reviews {
+			all = append(all, PRReview{
+				Author:      r.GetUser().GetLogin(),
+				Bot:         r.GetUser().GetType() == "Bot",
+				State:       r.GetState(),
+				SubmittedAt: r.GetSubmittedAt().Time,
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return all, nil
+}
+
+// ReviewThread is a review conversation on a line of a PR
+type ReviewThread struct {
+	Path     string
+	Line     int // the line the thread started on when a later push outdated it
+	Resolved bool
+	Outdated bool   // a later push changed the lines the thread is about
+	Author   string // login of whoever started the thread
+	Bot      bool   // started by a GitHub App or by this client's own account
+	Body     string // the first comment
+	Replies  int    // comments after the first
+}
+
+const reviewThreadsQuery = `query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $cursor) {
+        nodes {
+          isResolved
+          isOutdated
+          path
+          line
+          originalLine
+          comments(first: 1) {
+            totalCount
+            nodes { viewerDidAuthor body author { login __typename } }
+          }
+        }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+
+// ListReviewThreads lists the review threads on a PR with their resolution
+// state, which only the GraphQL API exposes
+func (c *Client) ListReviewThreads(ctx context.Context, owner, repo string, prNumber int) ([]ReviewThread, error) {
+	var all []ReviewThread
+	var cursor *string
+
+	for {
+		var resp struct {
+			Data struct {
+				Repository struct {
+					PullRequest struct {
+						ReviewThreads struct {
+							Nodes []struct {
+								IsResolved   bool
+								IsOutdated   bool
+								Path         string
+								Line         int
+								OriginalLine int
+								Comments     struct {
+									TotalCount int
+									Nodes      []struct {
+										ViewerDidAuthor bool
+										Body            string
+										Author          struct {
+											Login    string
+											Typename string `json:"__typename"`
+										}
+									}
+								}
+							}
+							PageInfo struct {
+								HasNextPage bool
+								EndCursor   string
+							}
+						}
+					}
+				}
+			}
+			Errors []struct{ Message string }
+		}
+		vars := map[string]any{"owner": owner, "repo": repo, "number": prNumber, "cursor": cursor}
+		if err := c.graphQL(ctx, reviewThreadsQuery, vars, &resp); err != nil {
+			return nil, fmt.Errorf("list review threads: %w", classify(err))
+		}
+		if len(resp.Errors) > 0 {
+			return nil, fmt.Errorf("list review threads: %s", resp.Errors[0].Message)
+		}
+
+		threads := resp.Data.Repository.PullRequest.ReviewThreads
+		for _, t := range threads.Nodes {
+			thread := ReviewThread{Path: t.Path, Line: t.Line, Resolved: t.IsResolved, Outdated: t.IsOutdated}
+			if thread.Line == 0 {
+				thread.Line = t.OriginalLine
+			}
+			if len(t.Comments.Nodes) > 0 {
+				first := t.Comments.Nodes[0]
+				thread.Author = first.Author.Login
+				thread.Bot = first.ViewerDidAuthor || first.Author.Typename == "Bot"
+				thread.Body = first.Body
+				thread.Replies = max(t.Comments.TotalCount-1, 0)
+			}
+			all = append(all, thread)
+		}
+
+		if !threads.PageInfo.HasNextPage {
+			break
+		}
+		cursor = &threads.PageInfo.EndCursor
+	}
+
+	return all, nil
+}
+
+// graphQL posts a query to the GraphQL endpoint next to the REST API
+func (c *Client) graphQL(ctx context.Context, query string, vars map[string]any, out any) error {
+	endpoint := c.client.BaseURL.ResolveReference(&url.URL{Path: "graphql"})
+	if base := c.client.BaseURL.Path; strings.HasSuffix(base, "/api/v3/") {
+		// GitHub Enterprise Server serves GraphQL at /api/graphql
+		endpoint = c.client.BaseURL.ResolveReference(&url.URL{Path: strings.TrimSuffix(base, "v3/") + "graphql"})
+	}
+
+	req, err := c.client.NewRequest(http.MethodPost, endpoint.String(), map[string]any{"query": query, "variables": vars})
+	if err != nil {
+		return err
+	}
+	_, err = c.client.Do(ctx, req, out)
+	return classify(err)
+}
+
+// GetCIState combines the commit statuses and check runs on ref into
+// "success", "failure" or "pending"; it is empty when nothing reported
+func (c *Client) GetCIState(ctx context.Context, owner, repo, ref string) (string, error) {
+	status, _, err := c.client.Repositories.GetCombinedStatus(ctx, owner, repo, ref, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return "", fmt.Errorf("get combined status: %w", classify(err))
+	}
+	runs, _, err := c.client.Checks.ListCheckRunsForRef(ctx, owner, repo, ref, &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}})
+	if err != nil {
+		return "", fmt.Errorf("list check runs: %w", classify(err))
+	}
+
+	var failed, pending, passed bool
+	if status.GetTotalCount() > 0 {
+		switch status.GetState() {
+		case "success":
+			passed = true
+		case "pending":
+			pending = true
+		default:
+			failed = true
+		}
+	}
+	for _, run := range runs.CheckRuns {
+		switch {
+		case run.GetStatus() != "completed":
+			pending = true
+		case run.GetConclusion() == "failure" || run.GetConclusion() == "timed_out" || run.GetConclusion() == "cancelled" || run.GetConclusion() == "action_required":
+			failed = true
+		default:
+			passed = true
+		}
+	}
+
+	switch {
+	case failed:
+		return "failure", nil
+	case pending:
+		return "pending", nil
+	case passed:
+		return "success", nil
+	}
+	return "", nil
+}
+
 // DraftReviewComment represents a comment to be added in a review
 type DraftReviewComment struct {
-	Path string
-	Line int
-	Side string // LEFT or RIGHT (default RIGHT for new file)
-	Body string
+	Path      string
+	Line      int
+	StartLine int    // first line of a comment on several lines; 0 for one line
+	Side      string // LEFT or RIGHT (default RIGHT for new file)
+	Body      string
 }
 
 // CreatePullRequestReview creates a review with inline comments
@@ -275,18 +1064,45 @@ func (c *Client) CreatePullRequestReview(ctx context.Context, owner, repo string
 			Side: github.Ptr(side),
 			Body: github.Ptr(c.Body),
 		}
+		if c.StartLine > 0 && c.StartLine < c.Line {
+			reviewComments[i].StartLine = github.Ptr(c.StartLine)
+			reviewComments[i].StartSide = github.Ptr(side)
+		}
 	}
 
 	review := &github.PullRequestReviewRequest{
 		CommitID: github.Ptr(commitID),
-		Body:     github.Ptr(body),
+		Body:     github.Ptr(correlation.AppendMarker(ctx, body)),
 		Event:    github.Ptr(event), // APPROVE, REQUEST_CHANGES, COMMENT
 		Comments: reviewComments,
 	}
 
-	_, _, err := c.client.PullRequests.CreateReview(ctx, owner, repo, prNumber, review)
+	if c.DryRun(owner, repo) {
+		logger := logging.FromContext(ctx)
+		logger.Info("dry run: would create PR review", "repo", owner+"/"+repo, "pr", prNumber, "commit_id", commitID, "event", event, "body", review.GetBody())
+		for _, rc := range comments {
+			logger.Info("dry run: would add review comment", "path", rc.Path, "line", rc.Line, "body", rc.Body)
+		}
+		c.Audit(ctx, audit.Event{Action: audit.ActionReviewCreate, Owner: owner, Repo: repo, PRNumber: prNumber,
+			Details: map[string]string{"commit_id": commitID, "event": event, "comments": fmt.Sprint(len(comments))}}, nil)
+		return nil
+	}
+
+	created, _, err := c.client.PullRequests.CreateReview(ctx, owner, repo, prNumber, review)
+	c.Audit(ctx, audit.Event{
+		Action:   audit.ActionReviewCreate,
+		Owner:    owner,
+		Repo:     repo,
+		PRNumber: prNumber,
+		Details: map[string]string{
+			"commit_id": commitID,
+			"event":     event,
+			"comments":  fmt.Sprint(len(comments)),
+			"review_id": fmt.Sprint(created.GetID()),
+		},
+	}, err)
 	if err != nil {
-		return fmt.Errorf("create pull request review: %w", err)
+		return fmt.Errorf("create pull request review: %w", classify(err))
 	}
 
 	return nil
@@ -294,20 +1110,51 @@ func (c *Client) CreatePullRequestReview(ctx context.Context, owner, repo string
 
 // ListPRComments lists all issue-level comments on a PR
 func (c *Client) ListPRComments(ctx context.Context, owner, repo string, prNumber int) ([]string, error) {
+	comments, err := c.listPRComments(ctx, owner, repo, prNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	bodies := make([]string, 0, len(comments))
+	for _, c := range comments {
+		bodies = append(bodies, c.GetBody())
+	}
+	return bodies, nil
+}
+
+// FindPRComment returns the ID and body of the latest issue-level comment
+// on a PR containing marker, or 0 when there is none. Only comments the
+// token's account wrote count: anyone can paste a marker into a comment.
+func (c *Client) FindPRComment(ctx context.Context, owner, repo string, prNumber int, marker string) (int64, string, error) {
+	login, err := c.Login(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	comments, err := c.listPRComments(ctx, owner, repo, prNumber)
+	if err != nil {
+		return 0, "", err
+	}
+
+	for i := len(comments) - 1; i >= 0; i-- {
+		if strings.Contains(comments[i].GetBody(), marker) && strings.EqualFold(comments[i].GetUser().GetLogin(), login) {
+			return comments[i].GetID(), comments[i].GetBody(), nil
+		}
+	}
+	return 0, "", nil
+}
+
+func (c *Client) listPRComments(ctx context.Context, owner, repo string, prNumber int) ([]*github.IssueComment, error) {
 	opts := &github.IssueListCommentsOptions{
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
-	var bodies []string
+	var all []*github.IssueComment
 
 	for {
 		comments, resp, err := c.client.Issues.ListComments(ctx, owner, repo, prNumber, opts)
 		if err != nil {
-			return nil, fmt.Errorf("list pr comments: %w", err)
-		}
-
-		for _, c := range comments {
-			bodies = append(bodies, c.GetBody())
+			return nil, fmt.Errorf("list pr comments: %w", classify(err))
 		}
+		all = append(all, comments...)
 
 		if resp.NextPage == 0 {
 			break
@@ -315,7 +1162,7 @@ func (c *Client) ListPRComments(ctx context.Context, owner, repo string, prNumbe
 		opts.Page = resp.NextPage
 	}
 
-	return bodies, nil
+	return all, nil
 }
 
 // ParsePatchHunks parses a patch to extract line number mappings
@@ -1,7 +1,18 @@
 package github
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
+
+	"prmate/internal/audit"
+	"prmate/internal/breaker"
+	"prmate/internal/errclass"
 )
 
 func TestParseRepoFullName(t *testing.T) {
@@ -128,3 +139,506 @@ func TestPRFile_Fields(t *testing.T) {
 		t.Errorf("Patch = %q, want @@ -1,5 +1,10 @@", f.Patch)
 	}
 }
+
+func TestNewEnterpriseClient(t *testing.T) {
+	client, err := NewEnterpriseClient("ghe-token", "https://ghe.example.com/api/v3/")
+	if err != nil {
+		t.Fatalf("NewEnterpriseClient() error = %v", err)
+	}
+
+	if got := client.GetHost(); got != "ghe.example.com" {
+		t.Errorf("GetHost() = %q, want ghe.example.com", got)
+	}
+
+	url := client.CloneURL("org", "repo")
+	expected := "https://ghe-token@ghe.example.com/org/repo.git"
+	if url != expected {
+		t.Errorf("CloneURL() = %q, want %q", url, expected)
+	}
+}
+
+func TestNewEnterpriseClient_InvalidURL(t *testing.T) {
+	if _, err := NewEnterpriseClient("token", "not a url"); err == nil {
+		t.Error("NewEnterpriseClient() expected error for invalid url")
+	}
+}
+
+type recordingAuditor struct {
+	events []audit.Event
+}
+
+func (r *recordingAuditor) Record(ctx context.Context, e audit.Event) error {
+	r.events = append(r.events, e)
+	return nil
+}
+
+func TestClient_CreatePRComment_Audited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v3/repos/org/repo/issues/7/comments" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 42}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewEnterpriseClient("token", srv.URL+"/api/v3/")
+	if err != nil {
+		t.Fatalf("NewEnterpriseClient() error = %v", err)
+	}
+	auditor := &recordingAuditor{}
+	client.SetAuditor(auditor)
+
+	ctx := audit.WithReason(audit.WithActor(context.Background(), "github:octocat"), "pull_request.opened")
+	if err := client.CreatePRComment(ctx, "org", "repo", 7, "hello"); err != nil {
+		t.Fatalf("CreatePRComment() error = %v", err)
+	}
+
+	if len(auditor.events) != 1 {
+		t.Fatalf("recorded %d events, want 1", len(auditor.events))
+	}
+	e := auditor.events[0]
+	if e.Action != audit.ActionCommentCreate || e.Owner != "org" || e.Repo != "repo" || e.PRNumber != 7 {
+		t.Errorf("event = %+v, want comment.create on org/repo#7", e)
+	}
+	if e.Actor != "github:octocat" || e.Reason != "pull_request.opened" {
+		t.Errorf("event actor/reason = %q/%q", e.Actor, e.Reason)
+	}
+	if e.Details["comment_id"] != "42" || e.Error != "" {
+		t.Errorf("event details = %v, error = %q", e.Details, e.Error)
+	}
+}
+
+func TestClient_FindAndUpdatePRComment(t *testing.T) {
+	var edited string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v3/user":
+			w.Write([]byte(`{"login": "prmate-bot"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v3/repos/org/repo/issues/7/comments":
+			w.Write([]byte(`[{"id": 1, "body": "<!-- marker --> old", "user": {"login": "prmate-bot"}}, {"id": 2, "body": "thanks", "user": {"login": "alice"}}, {"id": 3, "body": "<!-- marker --> new", "user": {"login": "PRMate-Bot"}}, {"id": 4, "body": "<!-- marker --> forged", "user": {"login": "mallory"}}]`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/api/v3/repos/org/repo/issues/comments/3":
+			var c struct {
+				Body string `json:"body"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&c)
+			edited = c.Body
+			w.Write([]byte(`{"id": 3}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewEnterpriseClient("token", srv.URL+"/api/v3/")
+	if err != nil {
+		t.Fatalf("NewEnterpriseClient() error = %v", err)
+	}
+	auditor := &recordingAuditor{}
+	client.SetAuditor(auditor)
+	ctx := context.Background()
+
+	id, body, err := client.FindPRComment(ctx, "org", "repo", 7, "<!-- marker -->")
+	if err != nil || id != 3 || body != "<!-- marker --> new" {
+		t.Fatalf("FindPRComment() = %d, %q, %v; want the latest match by the bot, 3", id, body, err)
+	}
+	if id, _, err := client.FindPRComment(ctx, "org", "repo", 7, "<!-- other -->"); err != nil || id != 0 {
+		t.Errorf("FindPRComment() without match = %d, %v; want 0", id, err)
+	}
+
+	if err := client.UpdatePRComment(ctx, "org", "repo", 7, id, "<!-- marker --> newer"); err != nil {
+		t.Fatalf("UpdatePRComment() error = %v", err)
+	}
+	if edited != "<!-- marker --> newer" {
+		t.Errorf("edited body = %q", edited)
+	}
+	if len(auditor.events) != 1 || auditor.events[0].Action != audit.ActionCommentUpdate || auditor.events[0].PRNumber != 7 || auditor.events[0].Details["comment_id"] != "3" {
+		t.Errorf("events = %+v, want one comment.update of comment 3 on #7", auditor.events)
+	}
+}
+
+func TestClient_DryRun(t *testing.T) {
+	tests := []struct {
+		name  string
+		all   bool
+		repos []string
+		owner string
+		repo  string
+		want  bool
+	}{
+		{name: "off", owner: "org", repo: "repo", want: false},
+		{name: "global", all: true, owner: "org", repo: "repo", want: true},
+		{name: "exact repo", repos: []string{"Org/Repo"}, owner: "org", repo: "repo", want: true},
+		{name: "other repo", repos: []string{"org/repo"}, owner: "org", repo: "other", want: false},
+		{name: "owner wildcard", repos: []string{"org/*"}, owner: "org", repo: "other", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("token")
+			client.SetDryRun(tt.all, tt.repos)
+			if got := client.DryRun(tt.owner, tt.repo); got != tt.want {
+				t.Errorf("DryRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_CreatePRComment_DryRun(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	client, err := NewEnterpriseClient("token", srv.URL+"/api/v3/")
+	if err != nil {
+		t.Fatalf("NewEnterpriseClient() error = %v", err)
+	}
+	auditor := &recordingAuditor{}
+	client.SetAuditor(auditor)
+	client.SetDryRun(true, nil)
+
+	if err := client.CreatePRComment(context.Background(), "org", "repo", 7, "hello"); err != nil {
+		t.Fatalf("CreatePRComment() error = %v", err)
+	}
+	if called {
+		t.Error("dry run should not call the GitHub API")
+	}
+	if len(auditor.events) != 1 || !auditor.events[0].DryRun {
+		t.Errorf("events = %+v, want one dry-run event", auditor.events)
+	}
+}
+
+func TestClient_CreatePullRequest(t *testing.T) {
+	var created map[string]string
+	var labels []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v3/repos/org/repo/pulls":
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"number": 9, "html_url": "https://github.com/org/repo/pull/9"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v3/repos/org/repo/issues/9/labels":
+			_ = json.NewDecoder(r.Body).Decode(&labels)
+			w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewEnterpriseClient("token", srv.URL+"/api/v3/")
+	if err != nil {
+		t.Fatalf("NewEnterpriseClient() error = %v", err)
+	}
+	auditor := &recordingAuditor{}
+	client.SetAuditor(auditor)
+
+	pr, err := client.CreatePullRequest(context.Background(), "org", "repo", NewPullRequest{
+		Title: "Add .prmate.md", Body: "Welcome", Head: "prmate/onboarding", Base: "main", Labels: []string{"prmate:onboarding"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if pr.Number != 9 || pr.HTMLURL != "https://github.com/org/repo/pull/9" {
+		t.Errorf("CreatePullRequest() = %+v", pr)
+	}
+	if created["head"] != "prmate/onboarding" || created["base"] != "main" || created["title"] != "Add .prmate.md" {
+		t.Errorf("created = %v", created)
+	}
+	if len(labels) != 1 || labels[0] != "prmate:onboarding" {
+		t.Errorf("labels = %v, want [prmate:onboarding]", labels)
+	}
+	if len(auditor.events) != 1 || auditor.events[0].Action != audit.ActionPRCreate || auditor.events[0].PRNumber != 9 {
+		t.Errorf("events = %+v, want one pr.create on #9", auditor.events)
+	}
+}
+
+func TestPullRequest_FromFork(t *testing.T) {
+	tests := []struct {
+		name       string
+		pr         PullRequest
+		wantFork   bool
+		wantConfig string
+		wantFiles  string
+	}{
+		{name: "same repository", pr: PullRequest{HeadRepo: "org/repo", BaseRepo: "Org/Repo"}, wantConfig: "feature", wantFiles: "feature"},
+		{name: "fork", pr: PullRequest{HeadRepo: "someone/repo", BaseRepo: "org/repo"}, wantFork: true, wantConfig: "main", wantFiles: "abc123"},
+		{name: "deleted fork", pr: PullRequest{BaseRepo: "org/repo"}, wantFork: true, wantConfig: "main", wantFiles: "abc123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := tt.pr
+			pr.HeadRef, pr.HeadSHA, pr.BaseRef = "feature", "abc123", "main"
+			if got := pr.FromFork(); got != tt.wantFork {
+				t.Errorf("FromFork() = %v, want %v", got, tt.wantFork)
+			}
+			if got := pr.ConfigRef(); got != tt.wantConfig {
+				t.Errorf("ConfigRef() = %q, want %q", got, tt.wantConfig)
+			}
+			if got := pr.ContentRef(); got != tt.wantFiles {
+				t.Errorf("ContentRef() = %q, want %q", got, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestClient_ListReviewThreads(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/graphql" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"data": {"repository": {"pullRequest": {"reviewThreads": {
+			"nodes": [
+				{"isResolved": false, "path": "a.go", "line": 3, "comments": {"nodes": [{"viewerDidAuthor": false, "author": {"login": "alice", "__typename": "User"}}]}},
+				{"isResolved": true, "isOutdated": true, "path": "b.go", "line": null, "originalLine": 9, "comments": {"totalCount": 3, "nodes": [{"viewerDidAuthor": true, "body": "**Rule**: message", "author": {"login": "prmate", "__typename": "User"}}]}}
+			],
+			"pageInfo": {"hasNextPage": false}
+		}}}}}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewEnterpriseClient("token", srv.URL+"/api/v3/")
+	if err != nil {
+		t.Fatalf("NewEnterpriseClient() error = %v", err)
+	}
+
+	threads, err := client.ListReviewThreads(context.Background(), "org", "repo", 7)
+	if err != nil {
+		t.Fatalf("ListReviewThreads() error = %v", err)
+	}
+	if len(threads) != 2 {
+		t.Fatalf("got %d threads, want 2", len(threads))
+	}
+	if got := threads[0]; got.Path != "a.go" || got.Line != 3 || got.Resolved || got.Author != "alice" || got.Bot {
+		t.Errorf("threads[0] = %+v", got)
+	}
+	if got := threads[1]; !got.Resolved || !got.Bot || !got.Outdated || got.Line != 9 || got.Replies != 2 || got.Body != "**Rule**: message" {
+		t.Errorf("threads[1] = %+v, want resolved, outdated and authored by this client", got)
+	}
+}
+
+func TestClient_ListMergedPullRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/org/repo/pulls" || r.URL.Query().Get("state") != "closed" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[
+			{"number": 3, "merged_at": "2026-01-03T00:00:00Z"},
+			{"number": 2},
+			{"number": 1, "merged_at": "2026-01-01T00:00:00Z"},
+			{"number": 0, "merged_at": "2025-12-01T00:00:00Z"}
+		]`))
+	}))
+	defer srv.Close()
+
+	client, err := NewEnterpriseClient("token", srv.URL+"/api/v3/")
+	if err != nil {
+		t.Fatalf("NewEnterpriseClient() error = %v", err)
+	}
+
+	prs, err := client.ListMergedPullRequests(context.Background(), "org", "repo", 2)
+	if err != nil {
+		t.Fatalf("ListMergedPullRequests() error = %v", err)
+	}
+	if len(prs) != 2 || prs[0].Number != 3 || prs[1].Number != 1 || prs[1].MergedAt.IsZero() {
+		t.Errorf("ListMergedPullRequests() = %+v, want PRs 3 and 1", prs)
+	}
+}
+
+func TestClient_ListDirectory(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/org/repo/contents/internal/auth" || r.URL.Query().Get("ref") != "main" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[
+			{"type": "file", "path": "internal/auth/token.go"},
+			{"type": "dir", "path": "internal/auth/testdata"},
+			{"type": "file", "path": "internal/auth/token_test.go"}
+		]`))
+	}))
+	defer srv.Close()
+
+	client, err := NewEnterpriseClient("token", srv.URL+"/api/v3/")
+	if err != nil {
+		t.Fatalf("NewEnterpriseClient() error = %v", err)
+	}
+
+	files, err := client.ListDirectory(context.Background(), "org", "repo", "internal/auth", "main")
+	if err != nil {
+		t.Fatalf("ListDirectory() error = %v", err)
+	}
+	if len(files) != 2 || files[0] != "internal/auth/token.go" || files[1] != "internal/auth/token_test.go" {
+		t.Errorf("ListDirectory() = %v, want the two files", files)
+	}
+}
+
+func TestClient_ListPRReviews(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/org/repo/pulls/7/reviews" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[
+			{"user": {"login": "alice", "type": "User"}, "state": "CHANGES_REQUESTED", "submitted_at": "2026-01-02T10:00:00Z"},
+			{"user": {"login": "ci-bot", "type": "Bot"}, "state": "COMMENTED", "submitted_at": "2026-01-02T11:00:00Z"}
+		]`))
+	}))
+	defer srv.Close()
+
+	client, err := NewEnterpriseClient("token", srv.URL+"/api/v3/")
+	if err != nil {
+		t.Fatalf("NewEnterpriseClient() error = %v", err)
+	}
+
+	reviews, err := client.ListPRReviews(context.Background(), "org", "repo", 7)
+	if err != nil {
+		t.Fatalf("ListPRReviews() error = %v", err)
+	}
+	if len(reviews) != 2 || reviews[0].Author != "alice" || reviews[0].State != "CHANGES_REQUESTED" || reviews[0].Bot || !reviews[1].Bot {
+		t.Errorf("ListPRReviews() = %+v", reviews)
+	}
+	if want := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC); !reviews[0].SubmittedAt.Equal(want) {
+		t.Errorf("SubmittedAt = %v, want %v", reviews[0].SubmittedAt, want)
+	}
+}
+
+func TestClient_GetCIState(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		runs   string
+		want   string
+	}{
+		{name: "nothing reported", status: `{"state": "pending", "total_count": 0}`, runs: `{"check_runs": []}`, want: ""},
+		{name: "all passing", status: `{"state": "success", "total_count": 1}`, runs: `{"check_runs": [{"status": "completed", "conclusion": "success"}]}`, want: "success"},
+		{name: "check running", status: `{"state": "success", "total_count": 1}`, runs: `{"check_runs": [{"status": "in_progress"}]}`, want: "pending"},
+		{name: "check failed", status: `{"state": "pending", "total_count": 0}`, runs: `{"check_runs": [{"status": "completed", "conclusion": "failure"}, {"status": "queued"}]}`, want: "failure"},
+		{name: "status failed", status: `{"state": "failure", "total_count": 2}`, runs: `{"check_runs": []}`, want: "failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v3/repos/org/repo/commits/abc/status":
+					w.Write([]byte(tt.status))
+				case "/api/v3/repos/org/repo/commits/abc/check-runs":
+					w.Write([]byte(tt.runs))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			client, err := NewEnterpriseClient("token", srv.URL+"/api/v3/")
+			if err != nil {
+				t.Fatalf("NewEnterpriseClient() error = %v", err)
+			}
+			got, err := client.GetCIState(context.Background(), "org", "repo", "abc")
+			if err != nil {
+				t.Fatalf("GetCIState() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetCIState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Breaker(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/api/v3/repos/o/r/issues/1/comments" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c, err := NewEnterpriseClient("token", srv.URL+"/api/v3/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := breaker.New("github", breaker.Config{Failures: 2, CoolDown: time.Minute})
+	c.SetBreaker(b)
+	ctx := context.Background()
+
+	// Client errors are the caller's problem and do not count
+	for range 3 {
+		if _, err := c.ListPRComments(ctx, "o", "r", 1); err == nil {
+			t.Fatal("expected a 404 error")
+		}
+	}
+	for range 2 {
+		if err := c.CheckAuth(ctx); err == nil || errors.Is(err, breaker.ErrOpen) {
+			t.Fatalf("CheckAuth() = %v, want the 502 error", err)
+		}
+	}
+	if err := c.CheckAuth(ctx); !errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("CheckAuth() = %v, want ErrOpen after 2 server errors", err)
+	}
+	if calls != 5 {
+		t.Errorf("server saw %d calls, want 5: none while the breaker is open", calls)
+	}
+}
+
+func TestClient_ErrorClasses(t *testing.T) {
+	reset := time.Now().Add(time.Hour)
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		want      error
+		wantRetry time.Duration // at least; 0 when the error is not retryable
+	}{
+		{
+			name:    "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) },
+			want:    errclass.ErrNotFound,
+		},
+		{
+			name: "primary rate limit retries at reset",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message":"API rate limit exceeded"}`))
+			},
+			want:      errclass.ErrRateLimited,
+			wantRetry: 50 * time.Minute,
+		},
+		{
+			name:      "too many requests",
+			handler:   func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTooManyRequests) },
+			want:      errclass.ErrRateLimited,
+			wantRetry: time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+			c, err := NewEnterpriseClient("token", srv.URL+"/api/v3/")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = c.GetFileContent(context.Background(), "o", "r", "a.go", "main")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("GetFileContent() = %v, want %v", err, tt.want)
+			}
+			d, ok := errclass.RetryAfter(err)
+			if ok != (tt.wantRetry > 0) || d < tt.wantRetry {
+				t.Errorf("RetryAfter() = %v, %v, want at least %v", d, ok, tt.wantRetry)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v82/github"
+
+	"prmate/internal/errclass"
+)
+
+// classify tags a go-github error with its errclass: rate limits as
+// ErrRateLimited, with the time the limit resets, and 404s as ErrNotFound.
+// Other errors are returned as they are.
+func classify(err error) error {
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var respErr *github.ErrorResponse
+	switch {
+	case errors.As(err, &rateErr):
+		return &errclass.Error{Err: err, Class: errclass.ErrRateLimited, RetryAt: rateErr.Rate.Reset.Time}
+	case errors.As(err, &abuseErr):
+		tagged := &errclass.Error{Err: err, Class: errclass.ErrRateLimited}
+		if abuseErr.RetryAfter != nil {
+			tagged.RetryAt = time.Now().Add(*abuseErr.RetryAfter)
+		}
+		return tagged
+	case errors.As(err, &respErr) && respErr.Response != nil:
+		switch respErr.Response.StatusCode {
+		case http.StatusNotFound:
+			return errclass.Wrap(err, errclass.ErrNotFound)
+		case http.StatusTooManyRequests:
+			return errclass.Wrap(err, errclass.ErrRateLimited)
+		}
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/google/go-github/v82/github"
+)
+
+// PingEvent is delivered when a webhook is created
+type PingEvent struct{}
+
+// PullRequestEvent is delivered when a pull request is opened, updated or
+// closed
+type PullRequestEvent struct {
+	Action      string
+	Repo        string // owner/repo
+	Sender      string // login of the user who triggered the event
+	PullRequest PullRequest
+	Merged      bool // the pull request was closed by merging it
+}
+
+// IssueCommentEvent is delivered for comments on issues and pull requests
+type IssueCommentEvent struct {
+	Action        string
+	Repo          string // owner/repo
+	Sender        string // login of the user who triggered the event
+	Number        int    // issue or pull request number
+	OnPullRequest bool   // the comment is on a pull request, not an issue
+	Body          string
+	Author        string // login of the comment author
+}
+
+// ReviewCommentEvent is delivered for inline review comments on pull
+// requests, replies in their threads included
+type ReviewCommentEvent struct {
+	Action    string
+	Repo      string // owner/repo
+	Sender    string // login of the user who triggered the event
+	Number    int    // pull request number
+	ID        int64
+	InReplyTo int64 // the first comment of the thread; 0 when the comment starts one
+	Body      string
+	Author    string // login of the comment author
+}
+
+// IssuesEvent is delivered when an issue is opened, edited, labeled or
+// closed
+type IssuesEvent struct {
+	Action string
+	Repo   string // owner/repo
+	Sender string // login of the user who triggered the event
+	Number int
+	Title  string
+	Body   string
+	Author string   // login of the issue author
+	Labels []string // labels the issue carries
+}
+
+// PushEvent is delivered when commits are pushed to a ref or it is deleted
+type PushEvent struct {
+	Ref     string // e.g. refs/heads/main
+	Repo    string // owner/repo
+	Deleted bool
+	Forced  bool
+	Files   []string // paths the pushed commits add, modify or remove; a forced push may change others
+}
+
+// ParseEvent decodes a webhook payload of the X-GitHub-Event type
+// eventType into one of the event types above. Events PRMate does not act
+// on return nil; unknown event types return an error.
+func ParseEvent(eventType string, payload []byte) (any, error) {
+	event, err := github.ParseWebHook(eventType, payload)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s event: %w", eventType, err)
+	}
+
+	switch e := event.(type) {
+	case *github.PingEvent:
+		return &PingEvent{}, nil
+	case *github.PullRequestEvent:
+		pr := e.GetPullRequest()
+		if pr == nil {
+			pr = &github.PullRequest{}
+		}
+		return &PullRequestEvent{
+			Action:      e.GetAction(),
+			Repo:        e.GetRepo().GetFullName(),
+			Sender:      e.GetSender().GetLogin(),
+			PullRequest: *toPullRequest(pr),
+			Merged:      pr.GetMerged(),
+		}, nil
+	case *github.IssueCommentEvent:
+		return &IssueCommentEvent{
+			Action:        e.GetAction(),
+			Repo:          e.GetRepo().GetFullName(),
+			Sender:        e.GetSender().GetLogin(),
+			Number:        e.GetIssue().GetNumber(),
+			OnPullRequest: e.GetIssue().GetPullRequestLinks() != nil,
+			Body:          e.GetComment().GetBody(),
+			Author:        e.GetComment().GetUser().GetLogin(),
+		}, nil
+	case *github.PullRequestReviewCommentEvent:
+		return &ReviewCommentEvent{
+			Action:    e.GetAction(),
+			Repo:      e.GetRepo().GetFullName(),
+			Sender:    e.GetSender().GetLogin(),
+			Number:    e.GetPullRequest().GetNumber(),
+			ID:        e.GetComment().GetID(),
+			InReplyTo: e.GetComment().GetInReplyTo(),
+			Body:      e.GetComment().GetBody(),
+			Author:    e.GetComment().GetUser().GetLogin(),
+		}, nil
+	case *github.IssuesEvent:
+		issue := e.GetIssue()
+		var labels []string
+		for _, l := range issue.Labels {
+			labels = append(labels, l.GetName())
+		}
+		return &IssuesEvent{
+			Action: e.GetAction(),
+			Repo:   e.GetRepo().GetFullName(),
+			Sender: e.GetSender().GetLogin(),
+			Number: issue.GetNumber(),
+			Title:  issue.GetTitle(),
+			Body:   issue.GetBody(),
+			Author: issue.GetUser().GetLogin(),
+			Labels: labels,
+		}, nil
+	case *github.PushEvent:
+		var files []string
+		for _, c := range e.Commits {
+			for _, paths := range [][]string{c.Added, c.Modified, c.Removed} {
+				for _, path := range paths {
+					if !slices.Contains(files, path) {
+						files = append(files, path)
+					}
+				}
+			}
+		}
+		return &PushEvent{
+			Ref:     e.GetRef(),
+			Repo:    e.GetRepo().GetFullName(),
+			Deleted: e.GetDeleted(),
+			Forced:  e.GetForced(),
+			Files:   files,
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// ValidatePayload reads the body of a webhook delivery and, when secret is
+// set, checks its X-Hub-Signature-256 against it. Form-encoded deliveries
+// are unwrapped to their JSON payload.
+func ValidatePayload(req *http.Request, secret []byte) ([]byte, error) {
+	return github.ValidatePayload(req, secret)
+}
@@ -0,0 +1,127 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		payload   string
+		want      any
+		wantErr   bool
+	}{
+		{
+			name:      "ping",
+			eventType: "ping",
+			payload:   `{"zen":"Keep it logically awesome."}`,
+			want:      &PingEvent{},
+		},
+		{
+			name:      "merged pull request",
+			eventType: "pull_request",
+			payload:   `{"action":"closed","repository":{"full_name":"acme/api"},"sender":{"login":"octocat"},"pull_request":{"number":7,"title":"Fix","merged":true,"html_url":"https://github.com/acme/api/pull/7","head":{"ref":"fix","sha":"abc"},"base":{"ref":"main"}}}`,
+			want: &PullRequestEvent{
+				Action:      "closed",
+				Repo:        "acme/api",
+				Sender:      "octocat",
+				PullRequest: PullRequest{Number: 7, Title: "Fix", HTMLURL: "https://github.com/acme/api/pull/7", HeadRef: "fix", HeadSHA: "abc", BaseRef: "main"},
+				Merged:      true,
+			},
+		},
+		{
+			name:      "comment on pull request",
+			eventType: "issue_comment",
+			payload:   `{"action":"created","repository":{"full_name":"acme/api"},"sender":{"login":"octocat"},"issue":{"number":7,"pull_request":{"url":"x"}},"comment":{"body":"@prmate fix","user":{"login":"hubot"}}}`,
+			want: &IssueCommentEvent{
+				Action:        "created",
+				Repo:          "acme/api",
+				Sender:        "octocat",
+				Number:        7,
+				OnPullRequest: true,
+				Body:          "@prmate fix",
+				Author:        "hubot",
+			},
+		},
+		{
+			name:      "comment on issue",
+			eventType: "issue_comment",
+			payload:   `{"action":"created","repository":{"full_name":"acme/api"},"issue":{"number":3},"comment":{"body":"hi"}}`,
+			want:      &IssueCommentEvent{Action: "created", Repo: "acme/api", Number: 3, Body: "hi"},
+		},
+		{
+			name:      "reply to review comment",
+			eventType: "pull_request_review_comment",
+			payload:   `{"action":"created","repository":{"full_name":"acme/api"},"sender":{"login":"octocat"},"pull_request":{"number":7},"comment":{"id":12,"in_reply_to_id":10,"body":"@prmate ignore","user":{"login":"octocat"}}}`,
+			want: &ReviewCommentEvent{
+				Action:    "created",
+				Repo:      "acme/api",
+				Sender:    "octocat",
+				Number:    7,
+				ID:        12,
+				InReplyTo: 10,
+				Body:      "@prmate ignore",
+				Author:    "octocat",
+			},
+		},
+		{
+			name:      "issue opened",
+			eventType: "issues",
+			payload:   `{"action":"opened","repository":{"full_name":"acme/api"},"sender":{"login":"octocat"},"issue":{"number":9,"title":"Crash on login","body":"Stack trace","user":{"login":"octocat"},"labels":[{"name":"needs-triage"}]}}`,
+			want: &IssuesEvent{
+				Action: "opened",
+				Repo:   "acme/api",
+				Sender: "octocat",
+				Number: 9,
+				Title:  "Crash on login",
+				Body:   "Stack trace",
+				Author: "octocat",
+				Labels: []string{"needs-triage"},
+			},
+		},
+		{
+			name:      "branch deleted",
+			eventType: "push",
+			payload:   `{"ref":"refs/heads/fix","deleted":true,"repository":{"full_name":"acme/api"}}`,
+			want:      &PushEvent{Ref: "refs/heads/fix", Repo: "acme/api", Deleted: true},
+		},
+		{
+			name:      "push changing files",
+			eventType: "push",
+			payload:   `{"ref":"refs/heads/main","forced":true,"commits":[{"added":["a.go"],"modified":[".prmate.md"]},{"modified":["a.go"],"removed":["b.go"]}],"repository":{"full_name":"acme/api"}}`,
+			want:      &PushEvent{Ref: "refs/heads/main", Repo: "acme/api", Forced: true, Files: []string{"a.go", ".prmate.md", "b.go"}},
+		},
+		{
+			name:      "event not acted on",
+			eventType: "star",
+			payload:   `{"action":"created"}`,
+			want:      nil,
+		},
+		{
+			name:      "unknown event type",
+			eventType: "no_such_event",
+			payload:   `{}`,
+			wantErr:   true,
+		},
+		{
+			name:      "malformed payload",
+			eventType: "push",
+			payload:   `{`,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEvent(tt.eventType, []byte(tt.payload))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseEvent() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
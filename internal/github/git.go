@@ -1,7 +1,7 @@
 package github
 
 import (
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -37,13 +37,15 @@ func (s *Service) Clone(repoName string) {
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
-		log.Fatalf("Command execution failed: %s", err)
+		slog.Error("Command execution failed", "error", err)
+		os.Exit(1)
 	}
 
 	// After cloning, list files in the cloned repo
 	files, err := os.ReadDir(filepath.Join(workspace, repoName))
 	if err != nil {
-		log.Fatalf("Failed to list files: %s", err)
+		slog.Error("Failed to list files", "error", err)
+		os.Exit(1)
 	}
 	for _, file := range files {
 		s.files = append(s.files, file.Name())
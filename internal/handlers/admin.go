@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"prmate/internal/audit"
+	"prmate/internal/config"
+	"prmate/internal/jobs"
+	"prmate/internal/learn"
+	"prmate/internal/llm"
+	"prmate/internal/review"
+	"prmate/internal/scan"
+	"prmate/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PRReviewer runs reviews of pull requests
+type PRReviewer interface {
+	ReviewPullRequest(ctx context.Context, owner, repo string, prNumber int) (*review.ReviewResult, error)
+	PlanBatchReview(ctx context.Context, owner, repo string) ([]webhook.BatchCandidate, error)
+}
+
+// CodebaseScanner generates .prmate.md content for a repository
+type CodebaseScanner interface {
+	ProcessScan(ctx context.Context, req scan.ScanRequest) (*scan.ScanResult, error)
+}
+
+// RuleLearner proposes rules from a repository's merged pull requests
+type RuleLearner interface {
+	Learn(ctx context.Context, owner, repo string, limit int) (*learn.Proposal, error)
+}
+
+// JobQueue runs operator-triggered work in the background
+type JobQueue interface {
+	Submit(ctx context.Context, kind string, fn jobs.Func) (string, error)
+	Get(id string) (jobs.Job, bool)
+}
+
+// defaultInstance is the SCM instance used when a request does not name one
+const defaultInstance = "default"
+
+// AdminHandler serves operator-facing endpoints mounted behind admin auth
+type AdminHandler struct {
+	config    *config.Config
+	jobs      JobQueue
+	reviewers map[string]PRReviewer
+	scanners  map[string]CodebaseScanner
+	learners  map[string]RuleLearner
+	rules     map[string]RulesReader
+	models    llm.ModelLister
+	prompter  Prompter
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(cfg *config.Config, jobQueue JobQueue) *AdminHandler {
+	return &AdminHandler{
+		config:    cfg,
+		jobs:      jobQueue,
+		reviewers: make(map[string]PRReviewer),
+		scanners:  make(map[string]CodebaseScanner),
+		learners:  make(map[string]RuleLearner),
+		rules:     make(map[string]RulesReader),
+	}
+}
+
+// AddReviewer registers the reviewer for the SCM instance with the given
+// name; the github.com instance is named "default"
+func (h *AdminHandler) AddReviewer(instance string, reviewer PRReviewer) {
+	h.reviewers[strings.ToLower(instance)] = reviewer
+}
+
+// AddScanner registers the scanner for the SCM instance with the given name
+func (h *AdminHandler) AddScanner(instance string, scanner CodebaseScanner) {
+	h.scanners[strings.ToLower(instance)] = scanner
+}
+
+// AddLearner registers the rule learner for the SCM instance with the given
+// name
+func (h *AdminHandler) AddLearner(instance string, learner RuleLearner) {
+	h.learners[strings.ToLower(instance)] = learner
+}
+
+// SetModelLister enables the models endpoint for the running LLM provider
+func (h *AdminHandler) SetModelLister(models llm.ModelLister) {
+	h.models = models
+}
+
+// Models lists the models the LLM provider offers and whether the
+// configured one is among them
+func (h *AdminHandler) Models(c *gin.Context) {
+	if h.models == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "the LLM provider cannot list models"})
+		return
+	}
+	model := h.config.LLMModel()
+	models, err := llm.CheckModel(c.Request.Context(), h.models, model)
+	if err != nil && !errors.Is(err, llm.ErrUnknownModel) {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to list models", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"provider":  h.config.LLMProvider,
+		"model":     model,
+		"available": err == nil,
+		"models":    models,
+	})
+}
+
+// Config returns the effective configuration with secrets masked
+func (h *AdminHandler) Config(c *gin.Context) {
+	instances, err := h.config.RedactedSCMInstances()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid scm configuration", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"config":        h.config.Redacted(),
+		"scm_instances": instances,
+	})
+}
+
+// TriggerReviewRequest is the body of POST /api/reviews
+type TriggerReviewRequest struct {
+	Owner    string `json:"owner" binding:"required"`
+	Repo     string `json:"repo" binding:"required"`
+	PR       int    `json:"pr" binding:"required,min=1"`
+	Instance string `json:"instance"`
+}
+
+// TriggerReview enqueues a review of the given pull request and returns the job ID
+func (h *AdminHandler) TriggerReview(c *gin.Context) {
+	var req TriggerReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	reviewer, ok := h.reviewers[instanceKey(req.Instance)]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown scm instance"})
+		return
+	}
+
+	ctx := audit.WithReason(c.Request.Context(), "manual review")
+	id, err := h.jobs.Submit(ctx, "review", func(ctx context.Context) (any, error) {
+		return reviewer.ReviewPullRequest(ctx, req.Owner, req.Repo, req.PR)
+	})
+	if err != nil {
+		h.submitFailed(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"job_id": id})
+}
+
+// BatchReviewRequest is the body of POST /api/reviews/batch
+type BatchReviewRequest struct {
+	Owner    string `json:"owner" binding:"required"`
+	Repo     string `json:"repo" binding:"required"`
+	Instance string `json:"instance"`
+}
+
+// BatchReviewEntry reports what a batch review did with one open PR
+type BatchReviewEntry struct {
+	webhook.BatchCandidate
+	JobID string `json:"job_id,omitempty"`
+}
+
+// TriggerBatchReview enqueues a review job for every open pull request in
+// the repository that has not been reviewed at its current head
+func (h *AdminHandler) TriggerBatchReview(c *gin.Context) {
+	var req BatchReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	reviewer, ok := h.reviewers[instanceKey(req.Instance)]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown scm instance"})
+		return
+	}
+
+	candidates, err := reviewer.PlanBatchReview(c.Request.Context(), req.Owner, req.Repo)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to list pull requests", "details": err.Error()})
+		return
+	}
+
+	ctx := audit.WithReason(c.Request.Context(), "batch review")
+	entries := make([]BatchReviewEntry, 0, len(candidates))
+	queued := 0
+	for _, candidate := range candidates {
+		entry := BatchReviewEntry{BatchCandidate: candidate}
+		if entry.Skip == "" {
+			prNumber := candidate.PRNumber
+			id, err := h.jobs.Submit(ctx, "review", func(ctx context.Context) (any, error) {
+				return reviewer.ReviewPullRequest(ctx, req.Owner, req.Repo, prNumber)
+			})
+			if err != nil {
+				entry.Skip = err.Error()
+			} else {
+				entry.JobID = id
+				queued++
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"queued": queued, "pull_requests": entries})
+}
+
+// TriggerScanRequest is the body of POST /api/scans
+type TriggerScanRequest struct {
+	Owner         string   `json:"owner" binding:"required"`
+	Repo          string   `json:"repo" binding:"required"`
+	Ref           string   `json:"ref" binding:"required"`
+	ExternalRepos []string `json:"external_repos"`
+	Commit        bool     `json:"commit"` // push the generated .prmate.md to ref
+	Instance      string   `json:"instance"`
+}
+
+// ScanJobResult is the result of a manually triggered scan job
+type ScanJobResult struct {
+	Content   string `json:"content"`
+	Committed bool   `json:"committed"`
+}
+
+// TriggerScan enqueues a codebase scan and returns the job ID; the generated
+// .prmate.md content is available from the job once it completes
+func (h *AdminHandler) TriggerScan(c *gin.Context) {
+	var req TriggerScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	scanner, ok := h.scanners[instanceKey(req.Instance)]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown scm instance"})
+		return
+	}
+
+	scanReq := scan.ScanRequest{
+		Owner:         req.Owner,
+		Repo:          req.Repo,
+		Branch:        req.Ref,
+		ExternalRepos: req.ExternalRepos,
+		GenerateOnly:  !req.Commit,
+	}
+
+	ctx := audit.WithReason(c.Request.Context(), "manual scan")
+	id, err := h.jobs.Submit(ctx, "scan", func(ctx context.Context) (any, error) {
+		result, err := scanner.ProcessScan(ctx, scanReq)
+		if err != nil {
+			return nil, err
+		}
+		return ScanJobResult{Content: result.PRMateContent, Committed: req.Commit}, nil
+	})
+	if err != nil {
+		h.submitFailed(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"job_id": id})
+}
+
+// LearnRequest is the body of POST /api/learn
+type LearnRequest struct {
+	Owner    string `json:"owner" binding:"required"`
+	Repo     string `json:"repo" binding:"required"`
+	PRs      int    `json:"prs"` // merged pull requests to learn from; default 50
+	Instance string `json:"instance"`
+}
+
+// LearnJobResult is the result of a learn job
+type LearnJobResult struct {
+	*learn.Proposal
+	Content string `json:"content"` // the proposed Learned Rules section
+}
+
+// TriggerLearn enqueues a job proposing a Learned Rules section from the
+// review feedback and reverts of the repository's merged pull requests
+func (h *AdminHandler) TriggerLearn(c *gin.Context) {
+	var req LearnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	if req.PRs <= 0 {
+		req.PRs = 50
+	}
+
+	learner, ok := h.learners[instanceKey(req.Instance)]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown scm instance or LLM disabled"})
+		return
+	}
+
+	id, err := h.jobs.Submit(c.Request.Context(), "learn", func(ctx context.Context) (any, error) {
+		proposal, err := learner.Learn(ctx, req.Owner, req.Repo, req.PRs)
+		if err != nil {
+			return nil, err
+		}
+		return LearnJobResult{Proposal: proposal, Content: proposal.Markdown()}, nil
+	})
+	if err != nil {
+		h.submitFailed(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"job_id": id})
+}
+
+// GetJob returns the status and result of a previously triggered job
+func (h *AdminHandler) GetJob(c *gin.Context) {
+	job, ok := h.jobs.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// instanceKey normalises a requested SCM instance name for lookup
+func instanceKey(instance string) string {
+	if instance == "" {
+		return defaultInstance
+	}
+	return strings.ToLower(instance)
+}
+
+func (h *AdminHandler) submitFailed(c *gin.Context, err error) {
+	if errors.Is(err, jobs.ErrQueueFull) || errors.Is(err, jobs.ErrClosed) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enqueue job", "details": err.Error()})
+}
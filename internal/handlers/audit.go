@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"prmate/internal/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLister queries the audit log of GitHub writes
+type AuditLister interface {
+	List(ctx context.Context, f audit.Filter) ([]audit.Event, error)
+}
+
+// AuditHandler exposes the audit log
+type AuditHandler struct {
+	log AuditLister
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(log AuditLister) *AuditHandler {
+	return &AuditHandler{log: log}
+}
+
+// List returns audit events, newest first, filtered by the owner, repo,
+// action, actor, days and limit query parameters
+func (h *AuditHandler) List(c *gin.Context) {
+	f := audit.Filter{
+		Owner:  c.Query("owner"),
+		Repo:   c.Query("repo"),
+		Action: c.Query("action"),
+		Actor:  c.Query("actor"),
+		Limit:  queryInt(c, "limit", 100),
+	}
+	if days := queryInt(c, "days", 0); days > 0 {
+		f.Since = time.Now().UTC().AddDate(0, 0, -days)
+	}
+
+	events, err := h.log.List(c.Request.Context(), f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query audit log", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"prmate/internal/errclass"
+	"prmate/internal/quality"
+	"prmate/internal/review"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// complianceReaders bounds the .prmate.md files read at once for one
+	// compliance summary
+	complianceReaders = 8
+	// complianceMaxRepos caps the repositories one compliance summary covers
+	complianceMaxRepos = 500
+	// complianceRulesTTL is how long the .prmate.md read for a compliance
+	// summary is reused by the next ones
+	complianceRulesTTL = 10 * time.Minute
+)
+
+// cachedRules is the .prmate.md of one repository as read for a compliance
+// summary
+type cachedRules struct {
+	rules  quality.RepoRules
+	readAt time.Time
+}
+
+// Compliance summarizes, across the repositories of the :org organization,
+// which rules their .prmate.md enforces, how often reviewed pull requests
+// violated them over the last `days` days (default 30), and which
+// repositories have no .prmate.md. The instance query parameter names the
+// SCM instance, and may be left out when there is only one. Past
+// complianceMaxRepos repositories the summary is partial.
+func (h *DashboardHandler) Compliance(c *gin.Context) {
+	org := c.Param("org")
+	instance := c.Query("instance")
+	if instance == "" && len(h.repos) == 1 {
+		for name := range h.repos {
+			instance = name
+		}
+	}
+	lister, ok := h.repos[instance]
+	src, hasRules := h.rules[instance]
+	if !ok || !hasRules {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown instance", "instance": instance})
+		return
+	}
+
+	ctx := c.Request.Context()
+	names, err := lister.ListOrgRepos(ctx, org)
+	if errors.Is(err, errclass.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization not found", "org": org})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to list repositories", "details": err.Error()})
+		return
+	}
+
+	f := dashboardFilter(c, 30)
+	f.Instance, f.Owner, f.Repo = instance, org, ""
+	records, err := h.reviews.ListReviews(ctx, f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list reviews", "details": err.Error()})
+		return
+	}
+
+	omitted := max(len(names)-complianceMaxRepos, 0)
+	names = names[:len(names)-omitted]
+
+	summary := quality.BuildCompliance(org, h.readRepoRules(ctx, instance, src, org, names), records, f.Since)
+	summary.Omitted = omitted
+	c.JSON(http.StatusOK, summary)
+}
+
+// readRepoRules reads the .prmate.md of each repository of org on its
+// default branch, a few at a time. Rules read in the last
+// complianceRulesTTL are reused; failed reads are tried again.
+func (h *DashboardHandler) readRepoRules(ctx context.Context, instance string, src RulesReader, org string, names []string) []quality.RepoRules {
+	h.dropExpiredRules()
+	repos := make([]quality.RepoRules, len(names))
+	sem := make(chan struct{}, complianceReaders)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		key := instance + "/" + org + "/" + name
+		if cached, ok := h.cachedRules(key); ok {
+			repos[i] = cached
+			continue
+		}
+		wg.Go(func() {
+			defer func() {
+				if repos[i].Err == nil {
+					h.cacheRules(key, repos[i])
+				}
+			}()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			repos[i].Repo = name
+			content, err := src.GetFileContent(ctx, org, name, ".prmate.md", "")
+			switch {
+			case errors.Is(err, errclass.ErrNotFound):
+				return
+			case err != nil:
+				repos[i].Err = err
+				return
+			}
+			rules, err := review.ParseRules(content)
+			if err != nil {
+				repos[i].Err = err
+				return
+			}
+			repos[i].Rules = &rules
+		})
+	}
+	wg.Wait()
+	return repos
+}
+
+// cachedRules returns the rules read for key, unless they have expired
+func (h *DashboardHandler) cachedRules(key string) (quality.RepoRules, bool) {
+	h.complianceMu.Lock()
+	defer h.complianceMu.Unlock()
+	e, ok := h.complianceRules[key]
+	if !ok || time.Since(e.readAt) >= complianceRulesTTL {
+		return quality.RepoRules{}, false
+	}
+	return e.rules, true
+}
+
+// cacheRules keeps the rules read for key
+func (h *DashboardHandler) cacheRules(key string, rules quality.RepoRules) {
+	h.complianceMu.Lock()
+	defer h.complianceMu.Unlock()
+	h.complianceRules[key] = cachedRules{rules: rules, readAt: time.Now()}
+}
+
+// dropExpiredRules forgets the rules past complianceRulesTTL, so those of
+// repositories no longer asked about do not pile up
+func (h *DashboardHandler) dropExpiredRules() {
+	h.complianceMu.Lock()
+	defer h.complianceMu.Unlock()
+	for key, e := range h.complianceRules {
+		if time.Since(e.readAt) >= complianceRulesTTL {
+			delete(h.complianceRules, key)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	_ "embed"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"prmate/internal/quality"
+	"prmate/internal/review"
+	"prmate/internal/shadow"
+	"prmate/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed dashboard.html
+var dashboardPage []byte
+
+// ReviewLister reads review records from the review store
+type ReviewLister interface {
+	ListReviews(ctx context.Context, f store.Filter) ([]store.ReviewRecord, error)
+}
+
+// QueueStatter reports how full a work queue is
+type QueueStatter interface {
+	QueueStats() (depth, capacity int)
+}
+
+// RulesReader reads a repository's .prmate.md from one SCM instance
+type RulesReader interface {
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
+}
+
+// RepoLister lists the repositories of an organization on one SCM instance
+type RepoLister interface {
+	ListOrgRepos(ctx context.Context, org string) ([]string, error)
+}
+
+// QueueStatus is the fill level of one named queue
+type QueueStatus struct {
+	Name     string `json:"name"`
+	Depth    int    `json:"depth"`
+	Capacity int    `json:"capacity"`
+}
+
+// DashboardHandler serves the review activity dashboard and its JSON API
+type DashboardHandler struct {
+	reviews ReviewLister
+	queues  map[string]QueueStatter
+	rules   map[string]RulesReader         // by instance name
+	repos   map[string]RepoLister          // by instance name
+	humans  map[string]shadow.GitHubClient // by instance name
+
+	complianceMu    sync.Mutex
+	complianceRules map[string]cachedRules // by instance/org/repo
+}
+
+// NewDashboardHandler creates a dashboard backed by the review store
+func NewDashboardHandler(reviews ReviewLister) *DashboardHandler {
+	return &DashboardHandler{
+		reviews: reviews,
+		queues:  make(map[string]QueueStatter),
+		rules:   make(map[string]RulesReader),
+		repos:   make(map[string]RepoLister),
+		humans:  make(map[string]shadow.GitHubClient),
+
+		complianceRules: make(map[string]cachedRules),
+	}
+}
+
+// AddQueue includes the named queue in the dashboard's queue status
+func (h *DashboardHandler) AddQueue(name string, q QueueStatter) {
+	h.queues[name] = q
+}
+
+// AddRules lets the dashboard read .prmate.md from the named instance, so
+// that stats filtered to one repository can flag rules that never fire
+func (h *DashboardHandler) AddRules(instance string, r RulesReader) {
+	h.rules[instance] = r
+}
+
+// AddRepos lets the compliance API list the repositories of organizations
+// on the named instance
+func (h *DashboardHandler) AddRepos(instance string, r RepoLister) {
+	h.repos[instance] = r
+}
+
+// Page serves the embedded dashboard UI; data is loaded from the JSON API
+func (h *DashboardHandler) Page(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", dashboardPage)
+}
+
+// Reviews lists recent reviews, filtered by the instance, owner, repo, days
+// and limit query parameters
+func (h *DashboardHandler) Reviews(c *gin.Context) {
+	f := dashboardFilter(c, 0)
+	f.Limit = queryInt(c, "limit", 50)
+
+	records, err := h.reviews.ListReviews(c.Request.Context(), f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list reviews", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reviews": records})
+}
+
+// Stats returns per-repo stats, per-rule violation trends, token spend and
+// queue status over the last `days` days (default 30). Rules violated in
+// almost every pull request are flagged as noisy; when the request names
+// one repository, its .prmate.md rules that never fired are flagged as
+// stale.
+func (h *DashboardHandler) Stats(c *gin.Context) {
+	f := dashboardFilter(c, 30)
+
+	records, err := h.reviews.ListReviews(c.Request.Context(), f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list reviews", "details": err.Error()})
+		return
+	}
+
+	queues := make([]QueueStatus, 0, len(h.queues))
+	for name, q := range h.queues {
+		depth, capacity := q.QueueStats()
+		queues = append(queues, QueueStatus{Name: name, Depth: depth, Capacity: capacity})
+	}
+	sort.Slice(queues, func(i, j int) bool { return queues[i].Name < queues[j].Name })
+
+	stats := store.Summarize(records)
+	if f.Owner != "" && f.Repo != "" {
+		stats.Advice = append(stats.Advice, h.staleRules(c.Request.Context(), f, records)...)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":  f.Since,
+		"stats":  stats,
+		"queues": queues,
+	})
+}
+
+// staleRules flags the rules in the repository's .prmate.md that no review
+// in records found violated. Rules are not judged until the repository has
+// store.MinAdvicePRs reviewed pull requests, nor when .prmate.md cannot be
+// read.
+func (h *DashboardHandler) staleRules(ctx context.Context, f store.Filter, records []store.ReviewRecord) []store.RuleAdvice {
+	prs := make(map[int]bool)
+	instance := ""
+	for _, rec := range records {
+		if rec.Error == "" {
+			prs[rec.PRNumber] = true
+			instance = rec.Instance
+		}
+	}
+	src, ok := h.rules[instance]
+	if len(prs) < store.MinAdvicePRs || !ok {
+		return nil
+	}
+
+	content, err := src.GetFileContent(ctx, f.Owner, f.Repo, ".prmate.md", "")
+	if err != nil {
+		return nil
+	}
+	rules, err := review.ParseRules(content)
+	if err != nil {
+		return nil
+	}
+
+	var advice []store.RuleAdvice
+	for _, rule := range quality.StaleRules(rules, records) {
+		advice = append(advice, store.RuleAdvice{Repo: f.Owner + "/" + f.Repo, Rule: rule, Kind: store.AdviceStale, Of: len(prs)})
+	}
+	return advice
+}
+
+func dashboardFilter(c *gin.Context, defaultDays int) store.Filter {
+	f := store.Filter{Instance: c.Query("instance"), Owner: c.Query("owner"), Repo: c.Query("repo")}
+	if days := queryInt(c, "days", defaultDays); days > 0 {
+		f.Since = time.Now().UTC().AddDate(0, 0, -days)
+	}
+	return f
+}
+
+func queryInt(c *gin.Context, key string, fallback int) int {
+	n, err := strconv.Atoi(c.Query(key))
+	if err != nil || n < 0 {
+		return fallback
+	}
+	return n
+}
@@ -1,17 +1,25 @@
 package handlers
 
 import (
-	"log"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
+
+	"prmate/internal/github"
+	"prmate/internal/logging"
+	"prmate/internal/tracing"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/go-github/v82/github"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (h *Handler) GitHubWebhook(c *gin.Context) {
 	req := c.Request
 	// GitHub provides the event name in the X-GitHub-Event header.
-	// go-github's github.WebHookType(req) reads the same header.
 	eventType := c.GetHeader("X-GitHub-Event")
 	deliveryID := c.GetHeader("X-GitHub-Delivery")
 	if eventType == "" {
@@ -19,7 +27,35 @@ func (h *Handler) GitHubWebhook(c *gin.Context) {
 		return
 	}
 
-	secret := []byte(h.webhookSecret)
+	// Every span of the delivery, down to the LLM calls, carries its ID
+	attrs := []attribute.KeyValue{
+		attribute.String("github.event", eventType),
+		attribute.String("github.delivery_id", deliveryID),
+	}
+	trace.SpanFromContext(req.Context()).SetAttributes(attrs...)
+	ctx := tracing.WithAttributes(req.Context(), attrs...)
+
+	// The body is read ahead of signature validation to find the owning
+	// organization; its route's secret then validates it
+	body, err := io.ReadAll(req.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "webhook payload too large"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "read webhook payload", "details": err.Error()})
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	route, ok := h.webhookRouteFor(c.GetHeader("X-GitHub-Hook-Installation-Target-ID"), c.GetHeader("X-GitHub-Enterprise-Host"), payloadOwner(c.ContentType(), body))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown scm instance"})
+		return
+	}
+
+	secret := []byte(route.secret)
 	if len(secret) == 0 {
 		secret = nil
 	}
@@ -30,16 +66,45 @@ func (h *Handler) GitHubWebhook(c *gin.Context) {
 		return
 	}
 
-	if h.webhookProc == nil {
+	if route.proc == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "webhook processor not configured"})
 		return
 	}
 
-	if err := h.webhookProc.Enqueue(req.Context(), eventType, payload, deliveryID); err != nil {
-		log.Printf("webhook enqueue failed event=%s delivery=%s err=%v", eventType, deliveryID, err)
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "webhook queue full"})
+	if err := route.proc.Enqueue(ctx, eventType, payload, deliveryID); err != nil {
+		logging.FromContext(req.Context()).Error("webhook enqueue failed", "event", eventType, "delivery_id", deliveryID, "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.Status(http.StatusAccepted)
 }
+
+// payloadOwner returns the login of the organization or user owning the
+// repository a delivery is about, or "" when the payload names none
+func payloadOwner(contentType string, body []byte) string {
+	if contentType == "application/x-www-form-urlencoded" {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return ""
+		}
+		body = []byte(form.Get("payload"))
+	}
+	var p struct {
+		Repository struct {
+			Owner struct {
+				Login string `json:"login"`
+			} `json:"owner"`
+		} `json:"repository"`
+		Organization struct {
+			Login string `json:"login"`
+		} `json:"organization"`
+	}
+	if err := json.Unmarshal(body, &p); err != nil {
+		return ""
+	}
+	if p.Repository.Owner.Login != "" {
+		return p.Repository.Owner.Login
+	}
+	return p.Organization.Login
+}
@@ -2,35 +2,62 @@ package handlers
 
 import (
 	"context"
-	"prmate/internal/weather"
+	"strings"
 )
 
-type JokeGenerator interface {
-	GenerateText(prompt string) (string, error)
+type WebhookProcessor interface {
+	Enqueue(ctx context.Context, eventType string, payload []byte, deliveryID string) error
 }
 
-type WeatherGetter interface {
-	GetWeather(city string) (weather.Result, error)
-}
+// defaultWebhookHost is the route key used for deliveries from github.com
+const defaultWebhookHost = "github.com"
 
-type WebhookProcessor interface {
-	Enqueue(ctx context.Context, eventType string, payload []byte, deliveryID string) error
+// webhookRoute pairs a webhook secret with the processor for one SCM instance
+type webhookRoute struct {
+	secret string
+	proc   WebhookProcessor
 }
 
 // Handler manages HTTP request handlers
 type Handler struct {
-	copilotService JokeGenerator
-	weatherService WeatherGetter
-	webhookProc    WebhookProcessor
-	webhookSecret  string
+	routes map[string]webhookRoute
 }
 
 // NewHandler creates a new handler instance
-func NewHandler(copilotSvc JokeGenerator, weatherSvc WeatherGetter, webhookProc WebhookProcessor, webhookSecret string) *Handler {
-	return &Handler{
-		copilotService: copilotSvc,
-		weatherService: weatherSvc,
-		webhookProc:    webhookProc,
-		webhookSecret:  webhookSecret,
+func NewHandler(webhookProc WebhookProcessor, webhookSecret string) *Handler {
+	h := &Handler{
+		routes: make(map[string]webhookRoute),
+	}
+	h.AddWebhookRoute(defaultWebhookHost, webhookSecret, webhookProc)
+	return h
+}
+
+// AddWebhookRoute registers the processor and secret for webhooks delivered
+// from host (GitHub Enterprise host), installation target ID, or host/org
+// pair for repositories of one organization on a host
+func (h *Handler) AddWebhookRoute(key, webhookSecret string, webhookProc WebhookProcessor) {
+	h.routes[strings.ToLower(key)] = webhookRoute{secret: webhookSecret, proc: webhookProc}
+}
+
+// webhookRouteFor resolves the route for a delivery, preferring an explicit
+// installation target match, then the organization owning the repository,
+// over the enterprise host header
+func (h *Handler) webhookRouteFor(targetID, enterpriseHost, owner string) (webhookRoute, bool) {
+	if targetID != "" {
+		if route, ok := h.routes[strings.ToLower(targetID)]; ok {
+			return route, true
+		}
+	}
+
+	host := strings.ToLower(enterpriseHost)
+	if host == "" {
+		host = defaultWebhookHost
+	}
+	if owner != "" {
+		if route, ok := h.routes[host+"/"+strings.ToLower(owner)]; ok {
+			return route, true
+		}
 	}
+	route, ok := h.routes[host]
+	return route, ok
 }
@@ -1,14 +1,41 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 
+	"prmate/internal/health"
+
 	"github.com/gin-gonic/gin"
 )
 
+// ReadinessChecker reports the status of external dependencies
+type ReadinessChecker interface {
+	Check(ctx context.Context) health.Report
+}
+
+// Health is kept as an alias of Livez for existing probes
 func (h *Handler) Health(c *gin.Context) {
+	h.Livez(c)
+}
+
+// Livez reports that the process is up; it never checks dependencies
+func (h *Handler) Livez(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "healthy",
 		"service": "prmate",
 	})
 }
+
+// Readyz returns per-dependency readiness, with 503 when any check fails
+func Readyz(checker ReadinessChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := checker.Check(c.Request.Context())
+
+		status := http.StatusOK
+		if !report.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
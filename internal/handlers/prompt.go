@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"prmate/internal/errclass"
+	"prmate/internal/review"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Prompter sends prompts to the LLM provider
+type Prompter interface {
+	GenerateTextWithContext(ctx context.Context, prompt string) (string, error)
+}
+
+// promptPlaceholders are filled from the .prmate.md of the repository a
+// prompt names
+var promptPlaceholders = []string{"{rules}", "{checklist}", "{context}"}
+
+// PromptRequest is the body of POST /api/prompt. When Owner and Repo are
+// set, their .prmate.md at Ref, the default branch when empty, fills the
+// {rules}, {checklist} and {context} placeholders of Prompt, or comes
+// before it when it has none.
+type PromptRequest struct {
+	Prompt   string `json:"prompt" binding:"required"`
+	Owner    string `json:"owner"`
+	Repo     string `json:"repo"`
+	Ref      string `json:"ref"`
+	Instance string `json:"instance"`
+}
+
+// PromptResponse is the result of the job POST /api/prompt enqueues
+type PromptResponse struct {
+	Provider  string `json:"provider"`
+	Model     string `json:"model"`
+	Prompt    string `json:"prompt"` // as sent to the LLM
+	Output    string `json:"output"`
+	ElapsedMS int64  `json:"elapsed_ms"`
+}
+
+// SetPrompter enables the prompt playground
+func (h *AdminHandler) SetPrompter(p Prompter) {
+	h.prompter = p
+}
+
+// AddRules lets the prompt playground read .prmate.md from the named
+// instance
+func (h *AdminHandler) AddRules(instance string, r RulesReader) {
+	h.rules[strings.ToLower(instance)] = r
+}
+
+// Prompt enqueues a job sending a prompt to the LLM provider, whose result
+// holds its output, so that operators can try the provider configuration
+// and prompt templates on the running service
+func (h *AdminHandler) Prompt(c *gin.Context) {
+	var req PromptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	if (req.Owner == "") != (req.Repo == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "owner and repo must be set together"})
+		return
+	}
+	if h.prompter == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "the LLM is disabled"})
+		return
+	}
+
+	prompt := req.Prompt
+	if req.Owner != "" {
+		rules, ok := h.rules[instanceKey(req.Instance)]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown scm instance"})
+			return
+		}
+		content, err := rules.GetFileContent(c.Request.Context(), req.Owner, req.Repo, ".prmate.md", req.Ref)
+		if err != nil {
+			status := http.StatusBadGateway
+			if errors.Is(err, errclass.ErrNotFound) {
+				status = http.StatusNotFound
+			}
+			c.JSON(status, gin.H{"error": "failed to read .prmate.md", "details": err.Error()})
+			return
+		}
+		prompt, err = withRepoContext(prompt, content)
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid .prmate.md", "details": err.Error()})
+			return
+		}
+	}
+
+	// A completion can take longer than the listener's write timeout, so it
+	// runs as a job like the other admin POSTs
+	id, err := h.jobs.Submit(c.Request.Context(), "prompt", func(ctx context.Context) (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, h.config.LLMTimeout)
+		defer cancel()
+		started := time.Now()
+		output, err := h.prompter.GenerateTextWithContext(ctx, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("LLM call failed: %w", err)
+		}
+		return PromptResponse{
+			Provider:  h.config.LLMProvider,
+			Model:     h.config.LLMModel(),
+			Prompt:    prompt,
+			Output:    output,
+			ElapsedMS: time.Since(started).Milliseconds(),
+		}, nil
+	})
+	if err != nil {
+		h.submitFailed(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"job_id": id})
+}
+
+// withRepoContext fills the placeholders of prompt from the .prmate.md
+// content, or puts the content before prompt when it has none
+func withRepoContext(prompt, content string) (string, error) {
+	templated := false
+	for _, p := range promptPlaceholders {
+		templated = templated || strings.Contains(prompt, p)
+	}
+	if !templated {
+		return "Repository context from .prmate.md:\n\n" + strings.TrimSpace(content) + "\n\n" + prompt, nil
+	}
+
+	rules, err := review.ParseRules(content)
+	if err != nil {
+		return "", err
+	}
+	return strings.NewReplacer(
+		"{rules}", bulletList(rules.Rules),
+		"{checklist}", bulletList(rules.Checklist),
+		"{context}", strings.TrimSpace(rules.CodebaseInfo),
+	).Replace(prompt), nil
+}
+
+func bulletList(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return "- " + strings.Join(items, "\n- ")
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"prmate/internal/shadow"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddHumanReviews lets the shadow report read the human reviews of pull
+// requests on the named instance
+func (h *DashboardHandler) AddHumanReviews(instance string, gh shadow.GitHubClient) {
+	h.humans[instance] = gh
+}
+
+// ShadowReport compares the shadow reviews of the :owner/:repo repository
+// over the last `days` days (default 30) with the human reviews of the same
+// pull requests. The instance query parameter names the SCM instance, and
+// may be left out when there is only one.
+func (h *DashboardHandler) ShadowReport(c *gin.Context) {
+	instance := c.Query("instance")
+	if instance == "" && len(h.humans) == 1 {
+		for name := range h.humans {
+			instance = name
+		}
+	}
+	gh, ok := h.humans[instance]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown instance", "instance": instance})
+		return
+	}
+
+	ctx := c.Request.Context()
+	f := dashboardFilter(c, 30)
+	f.Instance, f.Owner, f.Repo = instance, c.Param("owner"), c.Param("repo")
+	records, err := h.reviews.ListReviews(ctx, f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list reviews", "details": err.Error()})
+		return
+	}
+
+	report, err := shadow.Build(ctx, gh, f.Owner, f.Repo, records, f.Since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build shadow report", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
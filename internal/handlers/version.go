@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"net/http"
+
+	"prmate/internal/version"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version returns the build metadata of the running binary
+func Version(c *gin.Context) {
+	c.JSON(http.StatusOK, version.Get())
+}
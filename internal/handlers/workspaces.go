@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"prmate/internal/logging"
+	"prmate/internal/prworkspace"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceManager lists and removes PR workspaces on disk
+type WorkspaceManager interface {
+	List(ctx context.Context) ([]prworkspace.Workspace, error)
+	DeletePRDir(ctx context.Context, repoFullName string, prNumber int) error
+	CleanupStale(ctx context.Context, ttl time.Duration) (int, error)
+}
+
+// WorkspaceHandler lets operators see which PR workspaces consume disk and
+// remove them
+type WorkspaceHandler struct {
+	managers map[string]WorkspaceManager
+	names    []string
+}
+
+// NewWorkspaceHandler creates a new workspace handler
+func NewWorkspaceHandler() *WorkspaceHandler {
+	return &WorkspaceHandler{managers: make(map[string]WorkspaceManager)}
+}
+
+// AddManager registers the workspace manager for the SCM instance with the
+// given name; the github.com instance is named "default"
+func (h *WorkspaceHandler) AddManager(instance string, m WorkspaceManager) {
+	key := strings.ToLower(instance)
+	if _, ok := h.managers[key]; !ok {
+		h.names = append(h.names, key)
+	}
+	h.managers[key] = m
+}
+
+// WorkspaceEntry is a workspace as reported by GET /api/workspaces
+type WorkspaceEntry struct {
+	Instance string `json:"instance"`
+	prworkspace.Workspace
+	AgeSeconds  int64 `json:"age_seconds"`
+	IdleSeconds int64 `json:"idle_seconds"`
+}
+
+// List returns PR workspaces, largest first, optionally limited to the
+// instance query parameter
+func (h *WorkspaceHandler) List(c *gin.Context) {
+	names := h.names
+	if instance := c.Query("instance"); instance != "" {
+		if _, ok := h.managers[instanceKey(instance)]; !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown scm instance"})
+			return
+		}
+		names = []string{instanceKey(instance)}
+	}
+
+	now := time.Now()
+	entries := []WorkspaceEntry{}
+	var total int64
+	for _, name := range names {
+		workspaces, err := h.managers[name].List(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list workspaces", "details": err.Error()})
+			return
+		}
+		for _, ws := range workspaces {
+			entries = append(entries, WorkspaceEntry{
+				Instance:    name,
+				Workspace:   ws,
+				AgeSeconds:  int64(now.Sub(ws.CreatedAt).Seconds()),
+				IdleSeconds: int64(now.Sub(ws.LastUsedAt).Seconds()),
+			})
+			total += ws.SizeBytes
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].SizeBytes > entries[j].SizeBytes })
+
+	c.JSON(http.StatusOK, gin.H{"workspaces": entries, "total_bytes": total})
+}
+
+// Delete removes the workspace of a single pull request
+func (h *WorkspaceHandler) Delete(c *gin.Context) {
+	m, ok := h.managers[instanceKey(c.Query("instance"))]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown scm instance"})
+		return
+	}
+	prNumber, err := strconv.Atoi(c.Param("pr"))
+	if err != nil || prNumber <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pr number"})
+		return
+	}
+
+	repo := c.Param("owner") + "/" + c.Param("repo")
+	if err := m.DeletePRDir(c.Request.Context(), repo, prNumber); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete workspace", "details": err.Error()})
+		return
+	}
+	logging.FromContext(c.Request.Context()).Info("deleted pr workspace on request", "repo", repo, "pr", prNumber)
+	c.Status(http.StatusNoContent)
+}
+
+// CleanupRequest is the body of POST /api/workspaces/cleanup
+type CleanupRequest struct {
+	OlderThan string `json:"older_than" binding:"required"` // e.g. "24h"
+	Instance  string `json:"instance"`
+}
+
+// Cleanup deletes every workspace of an instance left unused for longer
+// than older_than
+func (h *WorkspaceHandler) Cleanup(c *gin.Context) {
+	var req CleanupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	ttl, err := time.ParseDuration(req.OlderThan)
+	if err != nil || ttl <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "older_than must be a positive duration"})
+		return
+	}
+	m, ok := h.managers[instanceKey(req.Instance)]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown scm instance"})
+		return
+	}
+
+	removed, err := m.CleanupStale(c.Request.Context(), ttl)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "cleanup failed", "removed": removed, "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"removed": removed})
+}
@@ -0,0 +1,129 @@
+// Package health runs readiness checks against PRMate's dependencies and
+// caches the results so frequent probes don't hammer GitHub or the LLM.
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CheckFunc reports whether a dependency is usable; nil means healthy
+type CheckFunc func(ctx context.Context) error
+
+// Status is the outcome of a single dependency check
+type Status struct {
+	Name      string    `json:"name"`
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	Duration  string    `json:"duration"`
+}
+
+// Report aggregates all dependency statuses
+type Report struct {
+	Ready  bool     `json:"ready"`
+	Checks []Status `json:"checks"`
+}
+
+// Checker runs registered checks concurrently with a per-check timeout
+type Checker struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu       sync.Mutex
+	checks   map[string]CheckFunc
+	cached   *Report
+	cachedAt time.Time
+}
+
+// NewChecker creates a checker; cacheTTL of 0 disables caching
+func NewChecker(timeout, cacheTTL time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &Checker{
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+		checks:   make(map[string]CheckFunc),
+	}
+}
+
+// Register adds a named check, replacing any previous check with that name
+func (c *Checker) Register(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+	c.cached = nil
+}
+
+// Check runs all checks (or returns a recent cached report)
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.Lock()
+	if c.cached != nil && time.Since(c.cachedAt) < c.cacheTTL {
+		report := *c.cached
+		c.mu.Unlock()
+		return report
+	}
+	checks := make(map[string]CheckFunc, len(c.checks))
+	for name, fn := range c.checks {
+		checks[name] = fn
+	}
+	c.mu.Unlock()
+
+	report := c.run(ctx, checks)
+
+	c.mu.Lock()
+	c.cached = &report
+	c.cachedAt = time.Now()
+	c.mu.Unlock()
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, checks map[string]CheckFunc) Report {
+	statuses := make([]Status, 0, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, fn := range checks {
+		wg.Add(1)
+		go func(name string, fn CheckFunc) {
+			defer wg.Done()
+			status := c.runOne(ctx, name, fn)
+			mu.Lock()
+			statuses = append(statuses, status)
+			mu.Unlock()
+		}(name, fn)
+	}
+	wg.Wait()
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	report := Report{Ready: true, Checks: statuses}
+	for _, s := range statuses {
+		if !s.Healthy {
+			report.Ready = false
+		}
+	}
+	return report
+}
+
+func (c *Checker) runOne(ctx context.Context, name string, fn CheckFunc) Status {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	status := Status{
+		Name:      name,
+		Healthy:   err == nil,
+		CheckedAt: start,
+		Duration:  time.Since(start).String(),
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChecker_Check(t *testing.T) {
+	c := NewChecker(time.Second, 0)
+	c.Register("ok", func(ctx context.Context) error { return nil })
+	c.Register("broken", func(ctx context.Context) error { return errors.New("unreachable") })
+
+	report := c.Check(context.Background())
+
+	if report.Ready {
+		t.Error("report should not be ready when a check fails")
+	}
+	if len(report.Checks) != 2 {
+		t.Fatalf("len(Checks) = %d, want 2", len(report.Checks))
+	}
+	if report.Checks[0].Name != "broken" || report.Checks[0].Error != "unreachable" {
+		t.Errorf("Checks[0] = %+v, want sorted failing check first", report.Checks[0])
+	}
+	if !report.Checks[1].Healthy {
+		t.Errorf("Checks[1] = %+v, want healthy", report.Checks[1])
+	}
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	c := NewChecker(10*time.Millisecond, 0)
+	c.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := c.Check(context.Background())
+	if report.Ready {
+		t.Error("slow check should fail after timeout")
+	}
+}
+
+func TestChecker_Cache(t *testing.T) {
+	var calls atomic.Int32
+	c := NewChecker(time.Second, time.Minute)
+	c.Register("counted", func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	c.Check(context.Background())
+	c.Check(context.Background())
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("check ran %d times, want 1 (cached)", got)
+	}
+}
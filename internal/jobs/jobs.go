@@ -0,0 +1,313 @@
+// Package jobs runs operator-triggered work (manual reviews, scans) on a
+// bounded worker pool and keeps recent results so callers can poll them by ID.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"prmate/internal/audit"
+	"prmate/internal/correlation"
+	"prmate/internal/errclass"
+	"prmate/internal/errreport"
+	"prmate/internal/logging"
+	"prmate/internal/notify"
+	"prmate/internal/tracing"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusRetrying  Status = "retrying" // waiting to run again after a retryable error
+)
+
+// maxRetries bounds how often a job that failed with a retryable error, such
+// as a rate limit or an open circuit breaker, is queued again
+const maxRetries = 3
+
+// ErrQueueFull is returned by Submit when no more jobs can be accepted. It
+// matches errclass.ErrQueueFull.
+var ErrQueueFull = errclass.Wrap(errors.New("job queue full"), errclass.ErrQueueFull)
+
+// ErrClosed is returned by Submit once Stop has been called
+var ErrClosed = errors.New("job queue closed")
+
+// Func is the work performed by a job; its result is exposed via Get
+type Func func(ctx context.Context) (any, error)
+
+// Job is a snapshot of a submitted job
+type Job struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Status     Status    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Result     any       `json:"result,omitempty"`
+	Attempts   int       `json:"attempts,omitempty"` // retries after retryable errors
+	CreatedAt  time.Time `json:"created_at"`
+	StartedAt  time.Time `json:"started_at,omitzero"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+}
+
+// Config controls queue sizing and how many finished jobs are retained
+type Config struct {
+	QueueSize int
+	Workers   int
+	Retain    int
+}
+
+type task struct {
+	id       string
+	baseCtx  context.Context
+	fn       Func
+	attempts int
+}
+
+// EventEmitter receives a job.failed event for every failed job
+type EventEmitter interface {
+	Emit(eventType string, data any)
+}
+
+// Queue is a bounded in-memory job queue
+type Queue struct {
+	tasks  chan task
+	retain int
+	events EventEmitter
+
+	mu       sync.Mutex
+	jobs     map[string]*Job
+	finished []string // IDs in completion order, oldest first
+	closed   bool
+
+	ctx    context.Context // cancelled when Stop gives up on draining
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewQueue starts cfg.Workers workers draining a queue of cfg.QueueSize
+func NewQueue(cfg Config) *Queue {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.Retain <= 0 {
+		cfg.Retain = 1000
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q := &Queue{
+		tasks:  make(chan task, cfg.QueueSize),
+		retain: cfg.Retain,
+		jobs:   make(map[string]*Job),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	for i := 0; i < cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+
+	return q
+}
+
+// SetEventEmitter reports every failed job to e. Call it before submitting
+// jobs.
+func (q *Queue) SetEventEmitter(e EventEmitter) {
+	q.events = e
+}
+
+// Submit enqueues fn and returns the new job's ID. The job runs detached
+// from ctx's cancellation but keeps its trace, correlation ID and audit
+// attribution.
+func (q *Queue) Submit(ctx context.Context, kind string, fn Func) (string, error) {
+	id := correlation.NewID(fmt.Sprintf("%s-%d", kind, time.Now().UnixNano()))
+
+	correlationID := correlation.FromContext(ctx)
+	if correlationID == "" {
+		correlationID = id
+	}
+	baseCtx := correlation.WithID(tracing.Detach(ctx), correlationID)
+	baseCtx = audit.WithReason(audit.WithActor(baseCtx, audit.ActorFrom(ctx)), audit.ReasonFrom(ctx))
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return "", ErrClosed
+	}
+
+	select {
+	case q.tasks <- task{id: id, baseCtx: baseCtx, fn: fn}:
+	default:
+		return "", ErrQueueFull
+	}
+
+	q.jobs[id] = &Job{ID: id, Kind: kind, Status: StatusQueued, CreatedAt: time.Now().UTC()}
+	return id, nil
+}
+
+// Get returns a snapshot of the job with the given ID
+func (q *Queue) Get(id string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *j, true
+}
+
+// QueueStats returns the number of queued jobs and the queue capacity
+func (q *Queue) QueueStats() (depth, capacity int) {
+	return len(q.tasks), cap(q.tasks)
+}
+
+// Stop refuses new jobs and waits for workers to drain the queue; jobs still
+// queued or running when ctx expires are cancelled
+func (q *Queue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.tasks)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.wg.Wait()
+	}()
+
+	select {
+	case <-ctx.Done():
+		q.cancel()
+		return fmt.Errorf("drain job queue (%d jobs left): %w", len(q.tasks), ctx.Err())
+	case <-done:
+		q.cancel()
+		return nil
+	}
+}
+
+func (q *Queue) worker() {
+	defer q.wg.Done()
+	for t := range q.tasks {
+		q.run(q.ctx, t)
+	}
+}
+
+func (q *Queue) run(workerCtx context.Context, t task) {
+	ctx, cancel := context.WithCancel(t.baseCtx)
+	defer cancel()
+	stop := context.AfterFunc(workerCtx, cancel)
+	defer stop()
+
+	q.update(t.id, func(j *Job) {
+		j.Status = StatusRunning
+		j.StartedAt = time.Now().UTC()
+	})
+
+	ctx = logging.With(ctx, "job_id", t.id)
+	result, err := call(ctx, t.fn)
+	if delay, ok := errclass.RetryAfter(err); ok && t.attempts < maxRetries {
+		logging.FromContext(ctx).Warn("job failed with a retryable error, retrying", "retry_in", delay, "error", err)
+		q.retry(t, delay, err)
+		return
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("job failed", "error", err)
+		errreport.Capture(ctx, err)
+	}
+	q.finish(t.id, result, err)
+}
+
+// retry marks the job as retrying and queues it again after delay; it
+// fails if the queue has been stopped or is full by then
+func (q *Queue) retry(t task, delay time.Duration, cause error) {
+	t.attempts++
+	q.update(t.id, func(j *Job) {
+		j.Status = StatusRetrying
+		j.Error = cause.Error()
+		j.Attempts = t.attempts
+	})
+	time.AfterFunc(delay, func() {
+		q.mu.Lock()
+		var err error
+		if q.closed {
+			err = fmt.Errorf("retry after %w: %w", cause, ErrClosed)
+		} else {
+			select {
+			case q.tasks <- t:
+				if j, ok := q.jobs[t.id]; ok {
+					j.Status = StatusQueued
+				}
+			default:
+				err = fmt.Errorf("retry after %w: %w", cause, ErrQueueFull)
+			}
+		}
+		q.mu.Unlock()
+		if err != nil {
+			q.finish(t.id, nil, err)
+		}
+	})
+}
+
+// finish records the outcome of a job and forgets the oldest finished jobs
+// beyond the retention limit
+func (q *Queue) finish(id string, result any, err error) {
+
+	q.update(id, func(j *Job) {
+		j.FinishedAt = time.Now().UTC()
+		if err != nil {
+			j.Status = StatusFailed
+			j.Error = err.Error()
+			return
+		}
+		j.Status = StatusSucceeded
+		j.Error = ""
+		j.Result = result
+	})
+	if err != nil && q.events != nil {
+		if j, ok := q.Get(id); ok {
+			q.events.Emit(notify.EventJobFailed, j)
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.finished = append(q.finished, id)
+	for len(q.finished) > q.retain {
+		delete(q.jobs, q.finished[0])
+		q.finished = q.finished[1:]
+	}
+}
+
+// call runs fn, converting a panic into an error so one bad job cannot take
+// down the worker pool
+func call(ctx context.Context, fn Func) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			errreport.CapturePanic(ctx, r)
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+func (q *Queue) update(id string, fn func(*Job)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if j, ok := q.jobs[id]; ok {
+		fn(j)
+	}
+}
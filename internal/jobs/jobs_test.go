@@ -0,0 +1,270 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"prmate/internal/breaker"
+	"prmate/internal/correlation"
+)
+
+func waitFor(t *testing.T, q *Queue, id string, want Status) Job {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if j, ok := q.Get(id); ok && j.Status == want {
+			return j
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	j, _ := q.Get(id)
+	t.Fatalf("job %s status = %q, want %q", id, j.Status, want)
+	return j
+}
+
+func TestQueue_Submit(t *testing.T) {
+	tests := []struct {
+		name       string
+		fn         Func
+		wantStatus Status
+		wantResult any
+		wantError  string
+	}{
+		{
+			name:       "success",
+			fn:         func(ctx context.Context) (any, error) { return "done", nil },
+			wantStatus: StatusSucceeded,
+			wantResult: "done",
+		},
+		{
+			name:       "failure",
+			fn:         func(ctx context.Context) (any, error) { return nil, errors.New("boom") },
+			wantStatus: StatusFailed,
+			wantError:  "boom",
+		},
+		{
+			name:       "panic",
+			fn:         func(ctx context.Context) (any, error) { panic("boom") },
+			wantStatus: StatusFailed,
+			wantError:  "job panicked: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewQueue(Config{})
+			defer q.Stop(context.Background())
+
+			id, err := q.Submit(context.Background(), "test", tt.fn)
+			if err != nil {
+				t.Fatalf("Submit() error = %v", err)
+			}
+
+			j := waitFor(t, q, id, tt.wantStatus)
+			if j.Kind != "test" {
+				t.Errorf("Kind = %q, want test", j.Kind)
+			}
+			if j.Result != tt.wantResult {
+				t.Errorf("Result = %v, want %v", j.Result, tt.wantResult)
+			}
+			if j.Error != tt.wantError {
+				t.Errorf("Error = %q, want %q", j.Error, tt.wantError)
+			}
+			if j.FinishedAt.IsZero() {
+				t.Error("FinishedAt should be set")
+			}
+		})
+	}
+}
+
+func TestQueue_DetachesCancellationKeepsCorrelation(t *testing.T) {
+	q := NewQueue(Config{})
+	defer q.Stop(context.Background())
+
+	ctx, cancel := context.WithCancel(correlation.WithID(context.Background(), "corr-1"))
+	got := make(chan string, 1)
+	id, err := q.Submit(ctx, "test", func(ctx context.Context) (any, error) {
+		got <- correlation.FromContext(ctx)
+		return nil, ctx.Err()
+	})
+	cancel()
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	waitFor(t, q, id, StatusSucceeded)
+	if id := <-got; id != "corr-1" {
+		t.Errorf("correlation ID = %q, want corr-1", id)
+	}
+}
+
+func TestQueue_Full(t *testing.T) {
+	q := NewQueue(Config{QueueSize: 1, Workers: 1})
+	defer q.Stop(context.Background())
+
+	block := make(chan struct{})
+	defer close(block)
+
+	blocking := func(ctx context.Context) (any, error) {
+		<-block
+		return nil, nil
+	}
+
+	first, err := q.Submit(context.Background(), "test", blocking)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	waitFor(t, q, first, StatusRunning)
+
+	if _, err := q.Submit(context.Background(), "test", blocking); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if _, err := q.Submit(context.Background(), "test", blocking); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Submit() error = %v, want ErrQueueFull", err)
+	}
+}
+
+func TestQueue_Retain(t *testing.T) {
+	q := NewQueue(Config{Retain: 1})
+	defer q.Stop(context.Background())
+
+	noop := func(ctx context.Context) (any, error) { return nil, nil }
+	first, _ := q.Submit(context.Background(), "test", noop)
+	waitFor(t, q, first, StatusSucceeded)
+	second, _ := q.Submit(context.Background(), "test", noop)
+	waitFor(t, q, second, StatusSucceeded)
+
+	if _, ok := q.Get(first); ok {
+		t.Error("oldest finished job should be evicted")
+	}
+}
+
+func TestQueue_StopDrains(t *testing.T) {
+	q := NewQueue(Config{Workers: 1})
+
+	release := make(chan struct{})
+	first, err := q.Submit(context.Background(), "test", func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	waitFor(t, q, first, StatusRunning)
+
+	queued, err := q.Submit(context.Background(), "test", func(ctx context.Context) (any, error) { return "drained", nil })
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- q.Stop(context.Background()) }()
+
+	// Stop closes intake before the queue has drained
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		_, err := q.Submit(context.Background(), "test", func(ctx context.Context) (any, error) { return nil, nil })
+		if errors.Is(err, ErrClosed) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Submit() error = %v, want ErrClosed", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	close(release)
+	if err := <-stopped; err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if j, _ := q.Get(queued); j.Status != StatusSucceeded || j.Result != "drained" {
+		t.Errorf("queued job = %+v, want drained before exit", j)
+	}
+}
+
+func TestQueue_StopTimeoutCancelsRunning(t *testing.T) {
+	q := NewQueue(Config{Workers: 1})
+
+	id, err := q.Submit(context.Background(), "test", func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	waitFor(t, q, id, StatusRunning)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop() error = %v, want DeadlineExceeded", err)
+	}
+	waitFor(t, q, id, StatusFailed)
+}
+
+type recordingEmitter struct {
+	mu     sync.Mutex
+	events []string
+	jobs   []Job
+}
+
+func (r *recordingEmitter) Emit(eventType string, data any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, eventType)
+	r.jobs = append(r.jobs, data.(Job))
+}
+
+func TestQueue_EmitsJobFailed(t *testing.T) {
+	emitter := &recordingEmitter{}
+	q := NewQueue(Config{})
+	q.SetEventEmitter(emitter)
+
+	if _, err := q.Submit(context.Background(), "scan", func(ctx context.Context) (any, error) { return "ok", nil }); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	failed, err := q.Submit(context.Background(), "review", func(ctx context.Context) (any, error) { return nil, errors.New("boom") })
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if err := q.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if len(emitter.events) != 1 || emitter.events[0] != "job.failed" {
+		t.Fatalf("events = %v, want one job.failed", emitter.events)
+	}
+	if j := emitter.jobs[0]; j.ID != failed || j.Kind != "review" || j.Status != StatusFailed || j.Error != "boom" {
+		t.Errorf("job = %+v", j)
+	}
+}
+
+func TestQueue_RetriesOpenBreaker(t *testing.T) {
+	q := NewQueue(Config{})
+	defer q.Stop(context.Background())
+
+	var mu sync.Mutex
+	calls := 0
+	id, err := q.Submit(context.Background(), "review", func(ctx context.Context) (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			return nil, &breaker.OpenError{Name: "llm", RetryAt: time.Now().Add(50 * time.Millisecond)}
+		}
+		return "reviewed", nil
+	})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	if j := waitFor(t, q, id, StatusRetrying); j.Attempts != 1 || j.Error == "" {
+		t.Errorf("retrying job = %+v, want 1 attempt and the breaker error", j)
+	}
+	if j := waitFor(t, q, id, StatusSucceeded); j.Result != "reviewed" || j.Error != "" || j.Attempts != 1 {
+		t.Errorf("job = %+v, want succeeded on the retry", j)
+	}
+}
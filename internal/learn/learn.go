@@ -0,0 +1,282 @@
+// Package learn proposes an initial Learned Rules section for .prmate.md
+// from a repository's history: the feedback human reviewers left on its
+// recently merged pull requests, and the changes it had to revert. The LLM
+// picks out the feedback that recurs, so a repository new to PRMate starts
+// with rules its team already enforces.
+package learn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/logging"
+	"prmate/internal/review"
+)
+
+const (
+	// MinPRs is how many pull requests feedback must recur on to become a
+	// rule; a rule explaining a revert needs only that one
+	MinPRs = 2
+	// maxRules caps the proposed section
+	maxRules = 15
+	// minCommentChars drops comments such as "nit" or "LGTM", which carry
+	// no rule
+	minCommentChars = 15
+	// maxCommentChars of each comment are sent to the LLM
+	maxCommentChars = 400
+	// maxPromptBytes bounds the feedback and reverts sent to the LLM
+	maxPromptBytes = 48 * 1024
+)
+
+// GitHubClient is the GitHub access the learner needs
+type GitHubClient interface {
+	ListMergedPullRequests(ctx context.Context, owner, repo string, limit int) ([]ghclient.PullRequest, error)
+	ListReviewComments(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.ReviewComment, error)
+}
+
+// LLMProvider distills rules from review history
+type LLMProvider interface {
+	GenerateTextWithContext(ctx context.Context, prompt string) (string, error)
+}
+
+// Rule is a proposed rule and the pull requests it was learned from
+type Rule struct {
+	Rule string `json:"rule"`
+	PRs  []int  `json:"prs"`
+}
+
+// Proposal is the outcome of learning from a repository's history
+type Proposal struct {
+	PRsScanned int    `json:"prs_scanned"`
+	Feedback   int    `json:"feedback"` // human review comments considered
+	Reverts    int    `json:"reverts"`
+	Rules      []Rule `json:"rules"`
+}
+
+// feedback is a human review comment on a merged pull request
+type feedback struct {
+	pr   int
+	path string
+	body string
+}
+
+// revert is a merged pull request that undid an earlier change
+type revert struct {
+	pr     int
+	title  string
+	reason string
+}
+
+// Learner proposes rules from the history of a repository
+type Learner struct {
+	gh  GitHubClient
+	llm LLMProvider
+}
+
+// NewLearner creates a learner
+func NewLearner(gh GitHubClient, llm LLMProvider) *Learner {
+	return &Learner{gh: gh, llm: llm}
+}
+
+// Learn reads up to limit recently merged pull requests of owner/repo and
+// proposes the rules their review feedback and reverts point to
+func (l *Learner) Learn(ctx context.Context, owner, repo string, limit int) (*Proposal, error) {
+	logger := logging.FromContext(ctx)
+
+	prs, err := l.gh.ListMergedPullRequests(ctx, owner, repo, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list merged pull requests: %w", err)
+	}
+
+	var comments []feedback
+	var reverts []revert
+	for _, pr := range prs {
+		if r, ok := asRevert(pr); ok {
+			reverts = append(reverts, r)
+		}
+
+		reviewComments, err := l.gh.ListReviewComments(ctx, owner, repo, pr.Number)
+		if err != nil {
+			logger.Warn("skipping review comments of pull request", "pr", pr.Number, "error", err)
+			continue
+		}
+		for _, c := range reviewComments {
+			if humanFeedback(c, pr.Author) {
+				comments = append(comments, feedback{pr: pr.Number, path: c.Path, body: strings.TrimSpace(c.Body)})
+			}
+		}
+	}
+
+	proposal := &Proposal{PRsScanned: len(prs), Feedback: len(comments), Reverts: len(reverts), Rules: []Rule{}}
+	if len(comments) == 0 && len(reverts) == 0 {
+		return proposal, nil
+	}
+
+	response, err := l.llm.GenerateTextWithContext(ctx, buildPrompt(comments, reverts))
+	if err != nil {
+		return nil, fmt.Errorf("llm: %w", err)
+	}
+	rules, err := parseRules(response, comments, reverts)
+	if err != nil {
+		return nil, err
+	}
+	proposal.Rules = rules
+	return proposal, nil
+}
+
+// humanFeedback reports whether c is a reviewer's comment worth learning
+// from: not the author answering, not a bot, and not a PRMate finding
+func humanFeedback(c ghclient.ReviewComment, author string) bool {
+	if c.Bot || c.Author == author || len(strings.TrimSpace(c.Body)) < minCommentChars {
+		return false
+	}
+	_, _, finding := review.FindingComment(c.Body)
+	return !finding
+}
+
+// asRevert recognizes pull requests created with GitHub's revert button or
+// describing a git revert
+func asRevert(pr ghclient.PullRequest) (revert, bool) {
+	if !strings.HasPrefix(pr.Title, `Revert "`) && !strings.Contains(pr.Body, "This reverts commit") {
+		return revert{}, false
+	}
+	reason := strings.TrimSpace(pr.Body)
+	if strings.HasPrefix(reason, "Reverts ") {
+		// GitHub's default body names the reverted PR and nothing more
+		_, reason, _ = strings.Cut(reason, "\n")
+	}
+	return revert{pr: pr.Number, title: pr.Title, reason: truncate(strings.TrimSpace(reason), maxCommentChars)}, true
+}
+
+func buildPrompt(comments []feedback, reverts []revert) string {
+	var sb strings.Builder
+	sb.WriteString("You are helping a team write down the coding rules they already enforce in code review. ")
+	sb.WriteString("Below is the feedback human reviewers left on recently merged pull requests, and the changes the team had to revert.\n\n")
+
+	budget := maxPromptBytes
+	if len(reverts) > 0 {
+		sb.WriteString("## Reverted changes\n\n")
+		for _, r := range reverts {
+			entry := fmt.Sprintf("- PR #%d: %s\n", r.pr, r.title)
+			if r.reason != "" {
+				entry += fmt.Sprintf("  Reason: %s\n", oneLine(r.reason))
+			}
+			if len(entry) > budget {
+				break
+			}
+			sb.WriteString(entry)
+			budget -= len(entry)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## Review feedback\n\n")
+	for _, c := range comments {
+		entry := fmt.Sprintf("- PR #%d, %s: %s\n", c.pr, c.path, oneLine(truncate(c.body, maxCommentChars)))
+		if len(entry) > budget {
+			break
+		}
+		sb.WriteString(entry)
+		budget -= len(entry)
+	}
+
+	fmt.Fprintf(&sb, `
+## Task
+Find the feedback that recurs: the same expectation raised on at least %d different pull requests, or a mistake that caused a revert. Write each as one short, imperative coding rule a reviewer can check in a diff, such as "Wrap returned errors with context using fmt.Errorf and %%w".
+
+Ignore one-off remarks, questions, praise and feedback specific to a single change. Propose at most %d rules, most frequent first.
+
+Respond with a JSON object listing each rule with the numbers of the pull requests it is based on:
+{"rules": [{"rule": "...", "prs": [12, 40]}]}
+
+If nothing recurs, return {"rules": []}. Respond with ONLY the JSON, no additional text.
+`, MinPRs, maxRules)
+	return sb.String()
+}
+
+// parseRules reads the LLM's answer, keeping the rules backed by enough of
+// the pull requests it was shown
+func parseRules(response string, comments []feedback, reverts []revert) ([]Rule, error) {
+	var parsed struct {
+		Rules []Rule `json:"rules"`
+	}
+	if err := json.Unmarshal([]byte(stripFence(response)), &parsed); err != nil {
+		return nil, fmt.Errorf("parse llm response: %w", err)
+	}
+
+	known := make(map[int]bool)
+	for _, c := range comments {
+		known[c.pr] = true
+	}
+	reverted := make(map[int]bool)
+	for _, r := range reverts {
+		known[r.pr] = true
+		reverted[r.pr] = true
+	}
+
+	rules := []Rule{}
+	seen := make(map[string]bool)
+	for _, r := range parsed.Rules {
+		text := strings.TrimSpace(strings.TrimPrefix(oneLine(r.Rule), "- "))
+		key := strings.ToLower(text)
+		if text == "" || seen[key] {
+			continue
+		}
+
+		var prs []int
+		fromRevert := false
+		for _, n := range r.PRs {
+			if known[n] && !slices.Contains(prs, n) {
+				prs = append(prs, n)
+				fromRevert = fromRevert || reverted[n]
+			}
+		}
+		if len(prs) < MinPRs && !fromRevert {
+			continue
+		}
+		slices.Sort(prs)
+		seen[key] = true
+		rules = append(rules, Rule{Rule: text, PRs: prs})
+		if len(rules) == maxRules {
+			break
+		}
+	}
+	return rules, nil
+}
+
+// Markdown renders the proposal as a Learned Rules section of .prmate.md
+func (p *Proposal) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("## Learned Rules\n\n")
+	for _, r := range p.Rules {
+		fmt.Fprintf(&sb, "- %s\n", r.Rule)
+	}
+	return sb.String()
+}
+
+// stripFence removes a code fence the LLM wrapped its answer in
+func stripFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+	if i := strings.Index(s, "\n"); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "```"))
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
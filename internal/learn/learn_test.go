@@ -0,0 +1,126 @@
+package learn
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+type mockGitHub struct {
+	prs      []ghclient.PullRequest
+	comments map[int][]ghclient.ReviewComment
+	limit    int
+}
+
+func (m *mockGitHub) ListMergedPullRequests(ctx context.Context, owner, repo string, limit int) ([]ghclient.PullRequest, error) {
+	m.limit = limit
+	return m.prs, nil
+}
+
+func (m *mockGitHub) ListReviewComments(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.ReviewComment, error) {
+	if prNumber == 99 {
+		return nil, errors.New("boom")
+	}
+	return m.comments[prNumber], nil
+}
+
+type mockLLM struct {
+	response string
+	prompt   string
+}
+
+func (m *mockLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	m.prompt = prompt
+	return m.response, nil
+}
+
+func TestLearn(t *testing.T) {
+	gh := &mockGitHub{
+		prs: []ghclient.PullRequest{
+			{Number: 10, Author: "alice"},
+			{Number: 11, Author: "bob"},
+			{Number: 12, Author: "carol", Title: `Revert "Cache sessions in memory"`, Body: "Reverts acme/api#8\n\nSessions were lost on every deploy."},
+			{Number: 99, Author: "dave"},
+		},
+		comments: map[int][]ghclient.ReviewComment{
+			10: {
+				{Author: "bob", Path: "store/load.go", Body: "Please wrap this error with context."},
+				{Author: "alice", Path: "store/load.go", Body: "Done, wrapped it with fmt.Errorf."},
+				{Author: "bob", Path: "store/load.go", Body: "nit"},
+			},
+			11: {
+				{Author: "alice", Path: "api/handler.go", Body: "Errors should be wrapped so we know where they came from."},
+				{Author: "prmate[bot]", Bot: true, Path: "api/handler.go", Body: "⚠️ **Error handling**: not wrapped"},
+				{Author: "carol", Path: "api/handler.go", Body: "⚠️ **Error handling**: a finding posted with a token"},
+			},
+		},
+	}
+	llm := &mockLLM{response: "```json\n" + `{"rules": [
+		{"rule": "Wrap returned errors with context", "prs": [10, 11]},
+		{"rule": "- Keep session state out of process memory", "prs": [12]},
+		{"rule": "Name variables well", "prs": [10]},
+		{"rule": "Use the logger", "prs": [10, 500]},
+		{"rule": "wrap returned errors with context", "prs": [10, 11]}
+	]}` + "\n```"}
+
+	p, err := NewLearner(gh, llm).Learn(context.Background(), "acme", "api", 50)
+	if err != nil {
+		t.Fatalf("Learn() error = %v", err)
+	}
+	if gh.limit != 50 {
+		t.Errorf("listed %d PRs, want 50", gh.limit)
+	}
+	if p.PRsScanned != 4 || p.Feedback != 2 || p.Reverts != 1 {
+		t.Errorf("proposal counts = %+v, want 4 PRs, 2 comments, 1 revert", p)
+	}
+
+	for _, want := range []string{
+		"PR #10, store/load.go: Please wrap this error with context.",
+		"PR #11, api/handler.go: Errors should be wrapped",
+		`PR #12: Revert "Cache sessions in memory"`,
+		"Reason: Sessions were lost on every deploy.",
+	} {
+		if !strings.Contains(llm.prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, llm.prompt)
+		}
+	}
+	for _, unwanted := range []string{"Done, wrapped", "nit", "Error handling"} {
+		if strings.Contains(llm.prompt, unwanted) {
+			t.Errorf("prompt should not contain %q", unwanted)
+		}
+	}
+
+	// Single-PR and unknown-PR rules and duplicates are dropped
+	want := "## Learned Rules\n\n- Wrap returned errors with context\n- Keep session state out of process memory\n"
+	if got := p.Markdown(); got != want {
+		t.Errorf("Markdown() = %q, want %q", got, want)
+	}
+	if len(p.Rules[0].PRs) != 2 || p.Rules[1].PRs[0] != 12 {
+		t.Errorf("rules = %+v", p.Rules)
+	}
+}
+
+func TestLearn_NoHistory(t *testing.T) {
+	llm := &mockLLM{}
+	p, err := NewLearner(&mockGitHub{prs: []ghclient.PullRequest{{Number: 1}}}, llm).Learn(context.Background(), "acme", "api", 10)
+	if err != nil {
+		t.Fatalf("Learn() error = %v", err)
+	}
+	if llm.prompt != "" || len(p.Rules) != 0 || p.PRsScanned != 1 {
+		t.Errorf("without feedback the LLM should not be asked; got %+v", p)
+	}
+}
+
+func TestLearn_BadResponse(t *testing.T) {
+	gh := &mockGitHub{
+		prs:      []ghclient.PullRequest{{Number: 1, Author: "alice"}},
+		comments: map[int][]ghclient.ReviewComment{1: {{Author: "bob", Body: "Please add a test for this case."}}},
+	}
+	_, err := NewLearner(gh, &mockLLM{response: "Here are some rules"}).Learn(context.Background(), "acme", "api", 10)
+	if err == nil || !strings.Contains(err.Error(), "parse llm response") {
+		t.Errorf("Learn() error = %v, want a parse error", err)
+	}
+}
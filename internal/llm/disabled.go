@@ -0,0 +1,32 @@
+package llm
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrDisabled is returned for every generation request in offline mode
+var ErrDisabled = errors.New("llm is disabled in offline mode")
+
+// Disabled stands in for a provider in offline mode: it starts, stops and
+// pings successfully but refuses to generate text
+type Disabled struct{}
+
+// GenerateText always fails with ErrDisabled
+func (Disabled) GenerateText(prompt string) (string, error) {
+	return "", ErrDisabled
+}
+
+// GenerateTextWithContext always fails with ErrDisabled
+func (Disabled) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	return "", ErrDisabled
+}
+
+// Ping reports the provider as healthy so readiness does not depend on an LLM
+func (Disabled) Ping(ctx context.Context) error { return nil }
+
+// Start is a no-op
+func (Disabled) Start() error { return nil }
+
+// Stop is a no-op
+func (Disabled) Stop() error { return nil }
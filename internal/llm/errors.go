@@ -0,0 +1,29 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"prmate/internal/errclass"
+)
+
+// ClassifyTimeout tags err with errclass.ErrLLMTimeout when a deadline cut
+// the LLM call short
+func ClassifyTimeout(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errclass.Wrap(err, errclass.ErrLLMTimeout)
+	}
+	return err
+}
+
+// apiError reports an error answer of an OpenAI-compatible API, tagged with
+// errclass.ErrRateLimited when the request was throttled
+func apiError(status int, message string) error {
+	err := fmt.Errorf("api error: %s", message)
+	if status == http.StatusTooManyRequests {
+		return errclass.Wrap(err, errclass.ErrRateLimited)
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownModel is returned by CheckModel when the configured model is
+// not offered by the provider
+var ErrUnknownModel = errors.New("model not available")
+
+// Model describes a model a provider can serve
+type Model struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// ModelLister is implemented by providers that can list their models
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]Model, error)
+}
+
+// CheckModel lists the models of lister and reports an error wrapping
+// ErrUnknownModel when model is not among them
+func CheckModel(ctx context.Context, lister ModelLister, model string) ([]Model, error) {
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(models))
+	for i, m := range models {
+		if strings.EqualFold(m.ID, model) {
+			return models, nil
+		}
+		ids[i] = m.ID
+	}
+	return models, fmt.Errorf("%w: %q (available: %s)", ErrUnknownModel, model, strings.Join(ids, ", "))
+}
+
+type modelKey struct{}
+
+// WithModel asks providers to answer calls made with ctx using model
+// instead of their configured one
+func WithModel(ctx context.Context, model string) context.Context {
+	return context.WithValue(ctx, modelKey{}, model)
+}
+
+// ModelFor returns the model requested through ctx, or fallback
+func ModelFor(ctx context.Context, fallback string) string {
+	if model, ok := ctx.Value(modelKey{}).(string); ok && model != "" {
+		return model
+	}
+	return fallback
+}
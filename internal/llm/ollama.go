@@ -0,0 +1,229 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"prmate/internal/tracing"
+)
+
+// DefaultOllamaTimeout is how long a call to a local model may take when
+// no timeout is configured. Local inference of a large prompt on modest
+// hardware takes minutes, and the first call also loads the model.
+const DefaultOllamaTimeout = 10 * time.Minute
+
+// OllamaProvider implements ChatCompleter for a local Ollama server, so
+// PRMate can run without any hosted LLM
+type OllamaProvider struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+var _ ChatCompleter = (*OllamaProvider)(nil)
+
+// OllamaConfig holds configuration for the Ollama provider
+type OllamaConfig struct {
+	BaseURL string        // If empty, uses http://localhost:11434
+	Model   string        // e.g. qwen2.5-coder:14b
+	Timeout time.Duration // If zero, uses DefaultOllamaTimeout
+}
+
+// NewOllamaProvider creates a provider for the Ollama server at cfg.BaseURL
+func NewOllamaProvider(cfg OllamaConfig) *OllamaProvider {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = DefaultOllamaTimeout
+	}
+
+	return &OllamaProvider{
+		baseURL: baseURL,
+		model:   cfg.Model,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  ollamaOptions   `json:"options"`
+}
+
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+}
+
+type ollamaResponse struct {
+	Message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"message"`
+	Done  bool   `json:"done"`
+	Error string `json:"error,omitempty"`
+}
+
+// GenerateText sends a prompt to the Ollama server and returns the response
+func (p *OllamaProvider) GenerateText(prompt string) (string, error) {
+	return p.GenerateTextWithContext(context.Background(), prompt)
+}
+
+// GenerateTextWithContext sends a prompt with context support
+func (p *OllamaProvider) GenerateTextWithContext(ctx context.Context, prompt string) (_ string, err error) {
+	model := ModelFor(ctx, p.model)
+	ctx, span := tracing.Start(ctx, "llm.generate",
+		attribute.String("llm.provider", "ollama"),
+		attribute.String("llm.model", model),
+		attribute.Int("llm.prompt_bytes", len(prompt)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	return p.chat(ctx, model, []openAIMessage{{Role: "user", Content: prompt}})
+}
+
+// Chat sends multiple messages for a conversation
+func (p *OllamaProvider) Chat(ctx context.Context, messages []Message) (_ string, err error) {
+	model := ModelFor(ctx, p.model)
+	ctx, span := tracing.Start(ctx, "llm.chat",
+		attribute.String("llm.provider", "ollama"),
+		attribute.String("llm.model", model),
+		attribute.Int("llm.messages", len(messages)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	apiMessages := make([]openAIMessage, len(messages))
+	for i, m := range messages {
+		apiMessages[i] = openAIMessage{
+			Role:    m.Role,
+			Content: m.Content,
+		}
+	}
+	return p.chat(ctx, model, apiMessages)
+}
+
+// chat asks the server for one complete answer to messages; streaming is
+// disabled so the answer arrives as a single JSON object
+func (p *OllamaProvider) chat(ctx context.Context, model string, messages []openAIMessage) (string, error) {
+	jsonBody, err := json.Marshal(ollamaRequest{
+		Model:    model,
+		Messages: messages,
+		Stream:   false,
+		Options:  ollamaOptions{Temperature: 0.3, NumPredict: 2000},
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/api/chat", bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("send request: %w", ClassifyTimeout(err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", ClassifyTimeout(err))
+	}
+
+	var result ollamaResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", apiError(resp.StatusCode, fmt.Sprintf("unexpected status %d", resp.StatusCode))
+		}
+		return "", fmt.Errorf("parse response: %w", err)
+	}
+	if result.Error != "" {
+		return "", apiError(resp.StatusCode, result.Error)
+	}
+	if !result.Done {
+		return "", fmt.Errorf("incomplete response")
+	}
+
+	return result.Message.Content, nil
+}
+
+// Ping verifies the Ollama server is reachable
+func (p *OllamaProvider) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/api/version", nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get version: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ListModels returns the models pulled on the Ollama server. Models tagged
+// latest are listed without their tag, as Ollama resolves them either way.
+func (p *OllamaProvider) ListModels(ctx context.Context) ([]Model, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("list models: unexpected status %d", resp.StatusCode)
+	}
+	var result struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("parse models: %w", err)
+	}
+
+	models := make([]Model, len(result.Models))
+	for i, m := range result.Models {
+		models[i] = Model{ID: strings.TrimSuffix(m.Name, ":latest"), Name: m.Name}
+	}
+	return models, nil
+}
+
+// Start is a no-op for Ollama (no persistent connection)
+func (p *OllamaProvider) Start() error {
+	return nil
+}
+
+// Stop is a no-op for Ollama (no persistent connection)
+func (p *OllamaProvider) Stop() error {
+	return nil
+}
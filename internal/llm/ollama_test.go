@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"prmate/internal/errclass"
+)
+
+func TestOllamaProvider_Chat(t *testing.T) {
+	var got ollamaRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/chat" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"model":"llama3.1","message":{"role":"assistant","content":"LGTM"},"done":true}`))
+	}))
+	defer srv.Close()
+
+	p := NewOllamaProvider(OllamaConfig{BaseURL: srv.URL + "/", Model: "llama3.1"})
+	answer, err := p.Chat(context.Background(), []Message{{Role: "system", Content: "Review"}, {Role: "user", Content: "diff"}})
+	if err != nil || answer != "LGTM" {
+		t.Fatalf("Chat() = %q, %v", answer, err)
+	}
+	if got.Model != "llama3.1" || got.Stream || len(got.Messages) != 2 || got.Messages[1].Content != "diff" {
+		t.Errorf("request = %+v, want both messages without streaming", got)
+	}
+
+	if _, err := p.GenerateTextWithContext(WithModel(context.Background(), "qwen2.5-coder"), "diff"); err != nil {
+		t.Fatalf("GenerateTextWithContext() error = %v", err)
+	}
+	if got.Model != "qwen2.5-coder" || len(got.Messages) != 1 || got.Messages[0].Role != "user" {
+		t.Errorf("request = %+v, want the prompt as a user message to the requested model", got)
+	}
+}
+
+func TestOllamaProvider_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantLimited bool
+	}{
+		{name: "missing model", status: http.StatusNotFound, body: `{"error":"model \"llama3.1\" not found, try pulling it first"}`},
+		{name: "busy", status: http.StatusTooManyRequests, body: `{"error":"server busy"}`, wantLimited: true},
+		{name: "proxy error", status: http.StatusBadGateway, body: `<html>bad gateway</html>`},
+		{name: "incomplete", status: http.StatusOK, body: `{"message":{"content":"LG"},"done":false}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := NewOllamaProvider(OllamaConfig{BaseURL: srv.URL, Model: "llama3.1"}).GenerateText("diff")
+			if err == nil {
+				t.Fatal("GenerateText() error = nil")
+			}
+			if limited := errors.Is(err, errclass.ErrRateLimited); limited != tt.wantLimited {
+				t.Errorf("GenerateText() error = %v, rate limited %v, want %v", err, limited, tt.wantLimited)
+			}
+		})
+	}
+}
+
+func TestOllamaProvider_ListModels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"models":[{"name":"llama3.1:latest"},{"name":"qwen2.5-coder:14b"}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewOllamaProvider(OllamaConfig{BaseURL: srv.URL})
+	for _, model := range []string{"llama3.1", "qwen2.5-coder:14b"} {
+		if _, err := CheckModel(context.Background(), p, model); err != nil {
+			t.Errorf("CheckModel(%q) error = %v", model, err)
+		}
+	}
+	if _, err := CheckModel(context.Background(), p, "mistral"); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("CheckModel(mistral) error = %v, want ErrUnknownModel", err)
+	}
+}
@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -8,10 +9,15 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"prmate/internal/tracing"
 )
 
-// OpenAIProvider implements TextGenerator for OpenAI-compatible APIs
+// OpenAIProvider implements StreamingCompleter for OpenAI-compatible APIs
 type OpenAIProvider struct {
 	apiKey     string
 	baseURL    string
@@ -19,6 +25,8 @@ type OpenAIProvider struct {
 	httpClient *http.Client
 }
 
+var _ StreamingCompleter = (*OpenAIProvider)(nil)
+
 // OpenAIConfig holds configuration for the OpenAI provider
 type OpenAIConfig struct {
 	APIKey  string // If empty, uses OPENAI_API_KEY env var
@@ -64,6 +72,7 @@ type openAIRequest struct {
 	Messages    []openAIMessage `json:"messages"`
 	Temperature float64         `json:"temperature,omitempty"`
 	MaxTokens   int             `json:"max_tokens,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
 }
 
 type openAIMessage struct {
@@ -92,9 +101,17 @@ func (p *OpenAIProvider) GenerateText(prompt string) (string, error) {
 }
 
 // GenerateTextWithContext sends a prompt with context support
-func (p *OpenAIProvider) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+func (p *OpenAIProvider) GenerateTextWithContext(ctx context.Context, prompt string) (_ string, err error) {
+	model := ModelFor(ctx, p.model)
+	ctx, span := tracing.Start(ctx, "llm.generate",
+		attribute.String("llm.provider", "openai"),
+		attribute.String("llm.model", model),
+		attribute.Int("llm.prompt_bytes", len(prompt)),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	reqBody := openAIRequest{
-		Model: p.model,
+		Model: model,
 		Messages: []openAIMessage{
 			{Role: "user", Content: prompt},
 		},
@@ -117,13 +134,13 @@ func (p *OpenAIProvider) GenerateTextWithContext(ctx context.Context, prompt str
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("send request: %w", err)
+		return "", fmt.Errorf("send request: %w", ClassifyTimeout(err))
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("read response: %w", err)
+		return "", fmt.Errorf("read response: %w", ClassifyTimeout(err))
 	}
 
 	var result openAIResponse
@@ -132,7 +149,7 @@ func (p *OpenAIProvider) GenerateTextWithContext(ctx context.Context, prompt str
 	}
 
 	if result.Error != nil {
-		return "", fmt.Errorf("api error: %s", result.Error.Message)
+		return "", apiError(resp.StatusCode, result.Error.Message)
 	}
 
 	if len(result.Choices) == 0 {
@@ -143,7 +160,15 @@ func (p *OpenAIProvider) GenerateTextWithContext(ctx context.Context, prompt str
 }
 
 // Chat sends multiple messages for a conversation
-func (p *OpenAIProvider) Chat(ctx context.Context, messages []Message) (string, error) {
+func (p *OpenAIProvider) Chat(ctx context.Context, messages []Message) (_ string, err error) {
+	model := ModelFor(ctx, p.model)
+	ctx, span := tracing.Start(ctx, "llm.chat",
+		attribute.String("llm.provider", "openai"),
+		attribute.String("llm.model", model),
+		attribute.Int("llm.messages", len(messages)),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	apiMessages := make([]openAIMessage, len(messages))
 	for i, m := range messages {
 		apiMessages[i] = openAIMessage{
@@ -153,7 +178,7 @@ func (p *OpenAIProvider) Chat(ctx context.Context, messages []Message) (string,
 	}
 
 	reqBody := openAIRequest{
-		Model:       p.model,
+		Model:       model,
 		Messages:    apiMessages,
 		Temperature: 0.3,
 		MaxTokens:   2000,
@@ -174,13 +199,13 @@ func (p *OpenAIProvider) Chat(ctx context.Context, messages []Message) (string,
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("send request: %w", err)
+		return "", fmt.Errorf("send request: %w", ClassifyTimeout(err))
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("read response: %w", err)
+		return "", fmt.Errorf("read response: %w", ClassifyTimeout(err))
 	}
 
 	var result openAIResponse
@@ -189,7 +214,7 @@ func (p *OpenAIProvider) Chat(ctx context.Context, messages []Message) (string,
 	}
 
 	if result.Error != nil {
-		return "", fmt.Errorf("api error: %s", result.Error.Message)
+		return "", apiError(resp.StatusCode, result.Error.Message)
 	}
 
 	if len(result.Choices) == 0 {
@@ -199,6 +224,164 @@ func (p *OpenAIProvider) Chat(ctx context.Context, messages []Message) (string,
 	return result.Choices[0].Message.Content, nil
 }
 
+// openAIStreamChunk is one server-sent event of a streamed chat completion
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// ChatStream sends multiple messages for a conversation and passes the
+// reply to handler as the API streams it
+func (p *OpenAIProvider) ChatStream(ctx context.Context, messages []Message, handler StreamHandler) (err error) {
+	model := ModelFor(ctx, p.model)
+	ctx, span := tracing.Start(ctx, "llm.chat",
+		attribute.String("llm.provider", "openai"),
+		attribute.String("llm.model", model),
+		attribute.Int("llm.messages", len(messages)),
+		attribute.Bool("llm.stream", true),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	apiMessages := make([]openAIMessage, len(messages))
+	for i, m := range messages {
+		apiMessages[i] = openAIMessage{
+			Role:    m.Role,
+			Content: m.Content,
+		}
+	}
+
+	reqBody := openAIRequest{
+		Model:       model,
+		Messages:    apiMessages,
+		Temperature: 0.3,
+		MaxTokens:   2000,
+		Stream:      true,
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/chat/completions", bytes.NewReader(jsonBody))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", ClassifyTimeout(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var result openAIResponse
+		if err := json.Unmarshal(body, &result); err == nil && result.Error != nil {
+			return apiError(resp.StatusCode, result.Error.Message)
+		}
+		return apiError(resp.StatusCode, fmt.Sprintf("unexpected status %d", resp.StatusCode))
+	}
+
+	// Each event is a "data:" line holding a chunk, and the stream ends
+	// with "data: [DONE]"
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return nil
+		}
+
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("parse stream: %w", err)
+		}
+		if chunk.Error != nil {
+			return apiError(resp.StatusCode, chunk.Error.Message)
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		if err := handler(chunk.Choices[0].Delta.Content); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("read stream: %w", ClassifyTimeout(err))
+	}
+	return fmt.Errorf("stream ended before [DONE]")
+}
+
+// Ping verifies the API is reachable and the key is accepted by listing models
+func (p *OpenAIProvider) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("list models: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ListModels returns the models the API key may use
+func (p *OpenAIProvider) ListModels(ctx context.Context) ([]Model, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("list models: unexpected status %d", resp.StatusCode)
+	}
+	var result struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("parse models: %w", err)
+	}
+
+	models := make([]Model, len(result.Data))
+	for i, m := range result.Data {
+		models[i] = Model{ID: m.ID}
+	}
+	return models, nil
+}
+
 // Start is a no-op for OpenAI (no persistent connection)
 func (p *OpenAIProvider) Start() error {
 	return nil
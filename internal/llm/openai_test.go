@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"prmate/internal/errclass"
+)
+
+func TestOpenAIProvider_ChatStream(t *testing.T) {
+	var got openAIRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(": keep-alive\n\n" +
+			`data: {"choices":[{"delta":{"role":"assistant"}}]}` + "\n\n" +
+			`data: {"choices":[{"delta":{"content":"LG"}}]}` + "\n\n" +
+			`data: {"choices":[{"delta":{"content":"TM"}}]}` + "\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	p := NewOpenAIProvider(OpenAIConfig{APIKey: "sk-test", BaseURL: srv.URL, Model: "gpt-4o"})
+	var chunks []string
+	err := p.ChatStream(context.Background(), []Message{{Role: "user", Content: "diff"}}, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if strings.Join(chunks, "|") != "LG|TM" {
+		t.Errorf("chunks = %q, want LG and TM", chunks)
+	}
+	if !got.Stream || got.Model != "gpt-4o" || len(got.Messages) != 1 {
+		t.Errorf("request = %+v, want a streamed request of the message", got)
+	}
+}
+
+func TestOpenAIProvider_ChatStreamErrors(t *testing.T) {
+	stop := errors.New("stop")
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		handlerErr  error
+		wantErr     string
+		wantLimited bool
+	}{
+		{name: "rate limited", status: http.StatusTooManyRequests, body: `{"error":{"message":"slow down"}}`, wantErr: "slow down", wantLimited: true},
+		{name: "proxy error", status: http.StatusBadGateway, body: `<html>bad gateway</html>`, wantErr: "unexpected status 502"},
+		{name: "error event", status: http.StatusOK, body: `data: {"error":{"message":"overloaded"}}` + "\n\n", wantErr: "overloaded"},
+		{name: "cut short", status: http.StatusOK, body: `data: {"choices":[{"delta":{"content":"LG"}}]}` + "\n\n", wantErr: "before [DONE]"},
+		{name: "handler fails", status: http.StatusOK, body: `data: {"choices":[{"delta":{"content":"LG"}}]}` + "\n\ndata: [DONE]\n\n", handlerErr: stop, wantErr: "stop"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			p := NewOpenAIProvider(OpenAIConfig{APIKey: "sk-test", BaseURL: srv.URL})
+			err := p.ChatStream(context.Background(), []Message{{Role: "user", Content: "diff"}}, func(string) error {
+				return tt.handlerErr
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ChatStream() error = %v, want %q", err, tt.wantErr)
+			}
+			if limited := errors.Is(err, errclass.ErrRateLimited); limited != tt.wantLimited {
+				t.Errorf("ChatStream() error = %v, rate limited %v, want %v", err, limited, tt.wantLimited)
+			}
+		})
+	}
+}
@@ -2,8 +2,9 @@
 // This abstraction allows switching between different LLM backends
 // (Copilot, OpenAI, etc.) without changing consumer code.
 //
-// Current implementation: internal/copilot uses the Copilot SDK directly.
-// Future: implement these interfaces for additional providers.
+// Implementations: internal/copilot streams through the Copilot SDK,
+// OpenAIProvider streams over server-sent events, and OllamaProvider chats
+// without streaming.
 package llm
 
 import "context"
@@ -11,7 +12,7 @@ import "context"
 // TextGenerator provides basic text generation capability.
 // This is the minimal interface that any LLM provider must implement.
 type TextGenerator interface {
-	GenerateText(ctx context.Context, prompt string) (string, error)
+	GenerateTextWithContext(ctx context.Context, prompt string) (string, error)
 }
 
 // Message represents a chat message for multi-turn conversations
@@ -26,7 +27,8 @@ type ChatCompleter interface {
 	Chat(ctx context.Context, messages []Message) (string, error)
 }
 
-// StreamHandler receives streaming chunks from the LLM
+// StreamHandler receives streaming chunks from the LLM. An error stops the
+// stream and is returned by ChatStream.
 type StreamHandler func(chunk string) error
 
 // StreamingCompleter adds streaming support to ChatCompleter
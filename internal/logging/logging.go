@@ -0,0 +1,64 @@
+// Package logging configures the process-wide slog logger and carries
+// request-scoped attributes (repo, PR, delivery ID) through contexts.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+type ctxKey struct{}
+type attrsKey struct{}
+
+// Setup installs a default slog logger writing to w. format is "text" or
+// "json"; level is one of debug, info, warn, error.
+func Setup(w io.Writer, level, format string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("parse log level %q: %w", level, err)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", "text":
+		handler = slog.NewTextHandler(w, opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// With returns a context whose logger includes args as attributes
+func With(ctx context.Context, args ...any) context.Context {
+	attrs := append(append([]any(nil), Attrs(ctx)...), args...)
+	ctx = context.WithValue(ctx, attrsKey{}, attrs)
+	return context.WithValue(ctx, ctxKey{}, FromContext(ctx).With(args...))
+}
+
+// Attrs returns the key/value pairs added to ctx with With, oldest first
+func Attrs(ctx context.Context) []any {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]any)
+	return attrs
+}
+
+// FromContext returns the logger stored in ctx, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestSetup_JSONWithContextAttrs(t *testing.T) {
+	prev := slog.Default()
+	defer slog.SetDefault(prev)
+
+	var buf bytes.Buffer
+	if err := Setup(&buf, "info", "json"); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	ctx := With(context.Background(), "repo", "owner/repo", "pr", 42)
+	FromContext(ctx).Info("review started")
+	FromContext(ctx).Debug("filtered out by level")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a single JSON line, got %q: %v", buf.String(), err)
+	}
+
+	if entry["repo"] != "owner/repo" {
+		t.Errorf("repo = %v, want owner/repo", entry["repo"])
+	}
+	if entry["pr"] != float64(42) {
+		t.Errorf("pr = %v, want 42", entry["pr"])
+	}
+}
+
+func TestSetup_InvalidInput(t *testing.T) {
+	prev := slog.Default()
+	defer slog.SetDefault(prev)
+
+	if err := Setup(&bytes.Buffer{}, "loud", "text"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+	if err := Setup(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestFromContext_Default(t *testing.T) {
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("FromContext without attrs should return the default logger")
+	}
+}
+
+func TestAttrs(t *testing.T) {
+	parent := With(context.Background(), "repo", "owner/repo")
+	child := With(parent, "pr", 42)
+
+	if got := Attrs(child); len(got) != 4 || got[0] != "repo" || got[3] != 42 {
+		t.Errorf("Attrs(child) = %v, want [repo owner/repo pr 42]", got)
+	}
+	if got := Attrs(parent); len(got) != 2 {
+		t.Errorf("Attrs(parent) = %v, should not see child attrs", got)
+	}
+}
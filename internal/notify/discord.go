@@ -0,0 +1,172 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Embed colors, as Discord's decimal RGB values
+const (
+	discordGreen = 0x2EA043
+	discordAmber = 0xD29922
+	discordRed   = 0xCF222E
+)
+
+// discordFieldLimit is the most characters Discord accepts in an embed
+// field value
+const discordFieldLimit = 1024
+
+// DiscordOptions shapes the messages a Discord webhook receives
+type DiscordOptions struct {
+	Username  string // overrides the webhook's configured name
+	AvatarURL string // overrides the webhook's configured avatar
+	Findings  bool   // list the top findings of a review
+	Scans     bool   // also post scan.completed events
+}
+
+// Discord posts review summaries, and optionally scan completions, as
+// embeds to a Discord channel webhook
+type Discord struct {
+	url    string
+	opts   DiscordOptions
+	client *http.Client
+}
+
+// NewDiscord returns a notifier posting to webhookURL
+func NewDiscord(webhookURL string, opts DiscordOptions) *Discord {
+	return &Discord{url: webhookURL, opts: opts, client: &http.Client{Timeout: sendTimeout}}
+}
+
+func (d *Discord) Notify(ctx context.Context, s Summary) error {
+	return d.post(ctx, d.reviewEmbed(s))
+}
+
+// Wants reports whether d posts events of eventType; review summaries
+// arrive through Notify instead
+func (d *Discord) Wants(eventType string) bool {
+	return d.opts.Scans && eventType == EventScanCompleted
+}
+
+func (d *Discord) Deliver(ctx context.Context, e Event) error {
+	scan, ok := e.Data.(ScanEvent)
+	if !ok {
+		return nil
+	}
+	return d.post(ctx, scanEmbed(scan, e.CreatedAt))
+}
+
+func (d *Discord) post(ctx context.Context, embed map[string]any) error {
+	msg := map[string]any{
+		"embeds": []map[string]any{embed},
+		// Never ping anyone from repository-controlled text
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	}
+	if d.opts.Username != "" {
+		msg["username"] = d.opts.Username
+	}
+	if d.opts.AvatarURL != "" {
+		msg["avatar_url"] = d.opts.AvatarURL
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encode discord message: %w", err)
+	}
+	return post(ctx, d.client, d.url, "application/json", nil, body)
+}
+
+func (d *Discord) reviewEmbed(s Summary) map[string]any {
+	color := discordGreen
+	if s.Error != "" || s.BySeverity["error"] > 0 {
+		color = discordRed
+	} else if s.ViolationsFound > 0 {
+		color = discordAmber
+	}
+
+	embed := map[string]any{
+		"title":     truncateRunes(s.Headline(), 256),
+		"color":     color,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"footer":    map[string]string{"text": "PRMate"},
+	}
+	if s.URL != "" {
+		embed["url"] = s.URL
+	}
+	if s.Title != "" {
+		embed["description"] = truncateRunes(s.Title, 4096)
+	}
+
+	if s.Error != "" {
+		embed["fields"] = []map[string]any{{"name": "Error", "value": truncateRunes(s.Error, discordFieldLimit)}}
+		return embed
+	}
+
+	fields := []map[string]any{
+		{"name": "Files reviewed", "value": fmt.Sprint(s.FilesReviewed), "inline": true},
+		{"name": "Findings", "value": fmt.Sprint(s.ViolationsFound), "inline": true},
+	}
+	var bySeverity []string
+	for _, sev := range []string{"error", "warning", "suggestion"} {
+		if n := s.BySeverity[sev]; n > 0 {
+			bySeverity = append(bySeverity, fmt.Sprintf("%d %ss", n, sev))
+		}
+	}
+	if len(bySeverity) > 0 {
+		fields = append(fields, map[string]any{"name": "Severity", "value": strings.Join(bySeverity, ", "), "inline": true})
+	}
+	if s.HeadSHA != "" {
+		fields = append(fields, map[string]any{"name": "Commit", "value": "`" + shortSHA(s.HeadSHA) + "`", "inline": true})
+	}
+
+	if d.opts.Findings && len(s.TopFindings) > 0 {
+		var b strings.Builder
+		for _, v := range s.TopFindings {
+			fmt.Fprintf(&b, "**%s** `%s:%d` %s\n", v.Severity, v.Path, v.Line, v.Message)
+		}
+		if more := s.ViolationsFound - len(s.TopFindings); more > 0 {
+			fmt.Fprintf(&b, "…and %d more", more)
+		}
+		fields = append(fields, map[string]any{"name": "Top findings", "value": truncateRunes(strings.TrimSpace(b.String()), discordFieldLimit)})
+	}
+	embed["fields"] = fields
+	return embed
+}
+
+func scanEmbed(e ScanEvent, at time.Time) map[string]any {
+	color, verb := discordGreen, "finished"
+	if e.Status != "succeeded" {
+		color, verb = discordRed, "failed"
+	}
+	title := fmt.Sprintf("Scan of %s %s", e.Repository, verb)
+
+	fields := []map[string]any{{"name": "Branch", "value": "`" + e.Branch + "`", "inline": true}}
+	if e.PRNumber > 0 {
+		fields = append(fields, map[string]any{"name": "Pull request", "value": fmt.Sprintf("#%d", e.PRNumber), "inline": true})
+	}
+	if len(e.ExternalRepos) > 0 {
+		fields = append(fields, map[string]any{"name": "External repos", "value": truncateRunes(strings.Join(e.ExternalRepos, ", "), discordFieldLimit)})
+	}
+	if e.Error != "" {
+		fields = append(fields, map[string]any{"name": "Error", "value": truncateRunes(e.Error, discordFieldLimit)})
+	}
+
+	return map[string]any{
+		"title":     truncateRunes(title, 256),
+		"color":     color,
+		"timestamp": at.UTC().Format(time.RFC3339),
+		"footer":    map[string]string{"text": "PRMate"},
+		"fields":    fields,
+	}
+}
+
+// truncateRunes shortens s to at most n characters, as Discord counts them
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"prmate/internal/correlation"
+)
+
+// Event types emitted to outbound webhooks
+const (
+	EventReviewCompleted = "review.completed"
+	EventScanCompleted   = "scan.completed"
+	EventJobFailed       = "job.failed"
+)
+
+// EventTypes lists every event type, for validating subscriptions
+var EventTypes = []string{EventReviewCompleted, EventScanCompleted, EventJobFailed}
+
+// Event is the JSON body POSTed to outbound webhooks
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// EventSink delivers events to one destination
+type EventSink interface {
+	Wants(eventType string) bool
+	Deliver(ctx context.Context, e Event) error
+}
+
+// Signature headers follow GitHub's webhook conventions so receivers can
+// reuse their verification code
+const (
+	HeaderEvent     = "X-PRMate-Event"
+	HeaderDelivery  = "X-PRMate-Delivery"
+	HeaderSignature = "X-PRMate-Signature-256"
+)
+
+// Webhook POSTs events as JSON to a URL, signed with HMAC-SHA256 when a
+// secret is set
+type Webhook struct {
+	url    string
+	secret string
+	types  []string // event types delivered; empty means all
+	client *http.Client
+}
+
+// NewWebhook returns a sink posting the given event types to url; no types
+// means every event
+func NewWebhook(url, secret string, types []string) *Webhook {
+	return &Webhook{url: url, secret: secret, types: types, client: &http.Client{Timeout: sendTimeout}}
+}
+
+// Wants reports whether the webhook subscribes to eventType
+func (w *Webhook) Wants(eventType string) bool {
+	return len(w.types) == 0 || slices.Contains(w.types, eventType)
+}
+
+func (w *Webhook) Deliver(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	headers := map[string]string{HeaderEvent: e.Type, HeaderDelivery: e.ID}
+	if w.secret != "" {
+		headers[HeaderSignature] = Sign(w.secret, body)
+	}
+	return post(ctx, w.client, w.url, "application/json", headers, body)
+}
+
+// Sign returns the X-PRMate-Signature-256 value for body: "sha256=" and the
+// hex HMAC-SHA256 of body keyed with secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newEvent stamps data with a fresh delivery ID and time
+func newEvent(eventType string, data any) Event {
+	now := time.Now().UTC()
+	return Event{
+		ID:        correlation.NewID(fmt.Sprintf("%s-%d", eventType, now.UnixNano())),
+		Type:      eventType,
+		CreatedAt: now,
+		Data:      data,
+	}
+}
+
+// ReviewEvent is the data of a review.completed event
+type ReviewEvent struct {
+	Instance        string         `json:"instance,omitempty"`
+	Repository      string         `json:"repository"`
+	PRNumber        int            `json:"pr_number"`
+	Title           string         `json:"title,omitempty"`
+	URL             string         `json:"url,omitempty"`
+	HeadSHA         string         `json:"head_sha"`
+	Status          string         `json:"status"` // "succeeded" or "failed"
+	Error           string         `json:"error,omitempty"`
+	FilesReviewed   int            `json:"files_reviewed"`
+	CommentsPosted  int            `json:"comments_posted"`
+	ViolationsFound int            `json:"violations_found"`
+	BySeverity      map[string]int `json:"by_severity,omitempty"`
+	TopFindings     []EventFinding `json:"top_findings,omitempty"`
+}
+
+// EventFinding is one finding in a review.completed event
+type EventFinding struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// reviewEvent converts s to review.completed data
+func reviewEvent(s Summary) ReviewEvent {
+	e := ReviewEvent{
+		Instance:        s.Instance,
+		Repository:      s.Repository(),
+		PRNumber:        s.PRNumber,
+		Title:           s.Title,
+		URL:             s.URL,
+		HeadSHA:         s.HeadSHA,
+		Status:          "succeeded",
+		Error:           s.Error,
+		FilesReviewed:   s.FilesReviewed,
+		CommentsPosted:  s.CommentsPosted,
+		ViolationsFound: s.ViolationsFound,
+		BySeverity:      s.BySeverity,
+	}
+	if s.Error != "" {
+		e.Status = "failed"
+	}
+	for _, v := range s.TopFindings {
+		e.TopFindings = append(e.TopFindings, EventFinding{Path: v.Path, Line: v.Line, Rule: v.Rule, Severity: v.Severity, Message: v.Message})
+	}
+	return e
+}
+
+// ScanEvent is the data of a scan.completed event
+type ScanEvent struct {
+	Repository       string   `json:"repository"`
+	PRNumber         int      `json:"pr_number,omitempty"`
+	Branch           string   `json:"branch"`
+	ExternalRepos    []string `json:"external_repos,omitempty"`
+	Status           string   `json:"status"` // "succeeded" or "failed"
+	Error            string   `json:"error,omitempty"`
+	ArtifactLocation string   `json:"artifact_location,omitempty"`
+}
@@ -0,0 +1,233 @@
+// Package notify tells chat channels and other outside systems about
+// finished reviews, and emits signed JSON events (see Event) for reviews,
+// scans and failed jobs. Delivery runs in the background and failures are
+// only logged: a notification must never hold up or fail a review.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"prmate/internal/review"
+)
+
+// sendTimeout bounds each delivery to a notifier
+const sendTimeout = 10 * time.Second
+
+// maxFindings caps how many findings a summary carries
+const maxFindings = 5
+
+// Summary describes one finished review
+type Summary struct {
+	Instance        string
+	Owner           string
+	Repo            string
+	PRNumber        int
+	Title           string
+	URL             string
+	HeadSHA         string
+	FilesReviewed   int
+	CommentsPosted  int
+	ViolationsFound int
+	BySeverity      map[string]int         // "error", "warning", "suggestion"
+	TopFindings     []review.FileViolation // most severe first, at most maxFindings
+	Error           string                 // set when the review failed
+	Channels        []string               // channels of the .prmate.md routes the pull request changes
+}
+
+// NewSummary builds the summary of a review; err is the review's failure,
+// if any
+func NewSummary(req review.ReviewRequest, title, url string, result *review.ReviewResult, err error) Summary {
+	s := Summary{
+		Owner:    req.Owner,
+		Repo:     req.Repo,
+		PRNumber: req.PRNumber,
+		Title:    title,
+		URL:      url,
+		HeadSHA:  req.HeadSHA,
+	}
+	if err != nil {
+		s.Error = err.Error()
+	}
+	if result == nil {
+		return s
+	}
+
+	s.FilesReviewed = result.FilesReviewed
+	s.Channels = result.Channels
+	s.CommentsPosted = result.CommentsPosted
+	s.ViolationsFound = result.ViolationsFound
+	s.BySeverity = make(map[string]int)
+	for _, v := range result.Violations {
+		s.BySeverity[v.Severity]++
+	}
+
+	findings := append([]review.FileViolation(nil), result.Violations...)
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank[findings[i].Severity] > severityRank[findings[j].Severity]
+	})
+	if len(findings) > maxFindings {
+		findings = findings[:maxFindings]
+	}
+	s.TopFindings = findings
+	return s
+}
+
+// Repository returns owner/repo
+func (s Summary) Repository() string {
+	return s.Owner + "/" + s.Repo
+}
+
+// Headline is a one-line description of the outcome
+func (s Summary) Headline() string {
+	switch {
+	case s.Error != "":
+		return fmt.Sprintf("Review of %s#%d failed", s.Repository(), s.PRNumber)
+	case s.ViolationsFound == 0:
+		return fmt.Sprintf("%s#%d passed review", s.Repository(), s.PRNumber)
+	default:
+		return fmt.Sprintf("%s#%d: %d findings", s.Repository(), s.PRNumber, s.ViolationsFound)
+	}
+}
+
+var severityRank = map[string]int{"suggestion": 1, "warning": 2, "error": 3}
+
+// Notifier delivers review summaries to one destination
+type Notifier interface {
+	Notify(ctx context.Context, s Summary) error
+}
+
+// Dispatcher fans summaries out to every configured notifier and events
+// out to every subscribed sink
+type Dispatcher struct {
+	notifiers []Notifier
+	names     []string
+	channels  []string // channel of each notifier; empty for those hearing every review
+	sinks     []EventSink
+	sinkNames []string
+	wg        sync.WaitGroup
+}
+
+// NewDispatcher returns a dispatcher without notifiers
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{}
+}
+
+// Add registers a notifier under name, which is used in logs
+func (d *Dispatcher) Add(name string, n Notifier) {
+	d.AddChannel("", name, n)
+}
+
+// AddChannel registers a notifier that only hears about reviews of pull
+// requests changing a route of channel; see Summary.Channels
+func (d *Dispatcher) AddChannel(channel, name string, n Notifier) {
+	d.names = append(d.names, name)
+	d.notifiers = append(d.notifiers, n)
+	d.channels = append(d.channels, channel)
+}
+
+// AddSink registers an event sink under name, which is used in logs
+func (d *Dispatcher) AddSink(name string, sink EventSink) {
+	d.sinkNames = append(d.sinkNames, name)
+	d.sinks = append(d.sinks, sink)
+}
+
+// Empty reports whether no notifier or sink is configured
+func (d *Dispatcher) Empty() bool {
+	return d == nil || len(d.notifiers)+len(d.sinks) == 0
+}
+
+// Send delivers s to every notifier hearing every review or one of its
+// channels, and emits it as a review.completed event, in the background
+func (d *Dispatcher) Send(s Summary) {
+	if d.Empty() {
+		return
+	}
+	d.Emit(EventReviewCompleted, reviewEvent(s))
+	for i, n := range d.notifiers {
+		if d.channels[i] != "" && !slices.Contains(s.Channels, d.channels[i]) {
+			continue
+		}
+		d.wg.Go(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			defer cancel()
+			if err := n.Notify(ctx, s); err != nil {
+				slog.Warn("failed to send review notification", "notifier", d.names[i],
+					"repo", s.Repository(), "pr", s.PRNumber, "error", err)
+			}
+		})
+	}
+}
+
+// Emit delivers an event of eventType carrying data to every sink
+// subscribed to it, in the background
+func (d *Dispatcher) Emit(eventType string, data any) {
+	if d == nil || len(d.sinks) == 0 {
+		return
+	}
+	e := newEvent(eventType, data)
+	for i, sink := range d.sinks {
+		if !sink.Wants(eventType) {
+			continue
+		}
+		d.wg.Go(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			defer cancel()
+			if err := sink.Deliver(ctx, e); err != nil {
+				slog.Warn("failed to deliver event", "sink", d.sinkNames[i],
+					"event", e.Type, "event_id", e.ID, "error", err)
+			}
+		})
+	}
+}
+
+// Flush waits for in-flight notifications to be delivered or ctx to expire
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	if d.Empty() {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.wg.Wait()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("flush notifications: %w", ctx.Err())
+	case <-done:
+		return nil
+	}
+}
+
+func post(ctx context.Context, client *http.Client, target, contentType string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send notification: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification endpoint returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"prmate/internal/review"
+)
+
+type fakeNotifier struct {
+	mu        sync.Mutex
+	summaries []Summary
+	err       error
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, s Summary) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.summaries = append(f.summaries, s)
+	return f.err
+}
+
+func TestNewSummary(t *testing.T) {
+	req := review.ReviewRequest{Owner: "acme", Repo: "widgets", PRNumber: 7, HeadSHA: "abc1234def"}
+	result := &review.ReviewResult{FilesReviewed: 3, ViolationsFound: 7}
+	for i := 0; i < 6; i++ {
+		result.Violations = append(result.Violations, review.FileViolation{Path: "a.go", Line: i, Severity: "suggestion"})
+	}
+	result.Violations = append(result.Violations, review.FileViolation{Path: "b.go", Line: 9, Severity: "error"})
+
+	s := NewSummary(req, "Add widgets", "https://github.com/acme/widgets/pull/7", result, nil)
+
+	if s.Repository() != "acme/widgets" || s.PRNumber != 7 || s.FilesReviewed != 3 {
+		t.Errorf("summary = %+v", s)
+	}
+	if s.BySeverity["error"] != 1 || s.BySeverity["suggestion"] != 6 {
+		t.Errorf("BySeverity = %v", s.BySeverity)
+	}
+	if len(s.TopFindings) != maxFindings || s.TopFindings[0].Path != "b.go" {
+		t.Errorf("TopFindings = %+v, want %d findings with the error first", s.TopFindings, maxFindings)
+	}
+	if s.Headline() != "acme/widgets#7: 7 findings" {
+		t.Errorf("Headline() = %q", s.Headline())
+	}
+
+	failed := NewSummary(req, "", "", nil, errors.New("llm unavailable"))
+	if failed.Error != "llm unavailable" || failed.Headline() != "Review of acme/widgets#7 failed" {
+		t.Errorf("failed summary = %+v, headline %q", failed, failed.Headline())
+	}
+}
+
+func TestTeams_Notify(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("invalid JSON body: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	s := Summary{
+		Owner: "acme", Repo: "widgets", PRNumber: 7, Title: "Add widgets",
+		URL: "https://github.com/acme/widgets/pull/7", HeadSHA: "abc1234def",
+		FilesReviewed: 2, ViolationsFound: 1, BySeverity: map[string]int{"error": 1},
+		TopFindings: []review.FileViolation{{Path: "main.go", Line: 4, Severity: "error", Message: "Unchecked error"}},
+	}
+	if err := NewTeams(srv.URL).Notify(context.Background(), s); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	attachments, _ := got["attachments"].([]any)
+	if got["type"] != "message" || len(attachments) != 1 {
+		t.Fatalf("message = %v, want one attachment", got)
+	}
+	attachment := attachments[0].(map[string]any)
+	if attachment["contentType"] != "application/vnd.microsoft.card.adaptive" {
+		t.Errorf("contentType = %v", attachment["contentType"])
+	}
+	card, _ := json.Marshal(attachment["content"])
+	for _, want := range []string{`"AdaptiveCard"`, "acme/widgets#7: 1 findings", "Attention", "`main.go:4` Unchecked error", "abc1234", `"Action.OpenUrl"`} {
+		if !strings.Contains(string(card), want) {
+			t.Errorf("card missing %q: %s", want, card)
+		}
+	}
+}
+
+func TestTeams_NotifyRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	if err := NewTeams(srv.URL).Notify(context.Background(), Summary{}); err == nil {
+		t.Error("Notify() error = nil, want error for 400 response")
+	}
+}
+
+func TestDiscord(t *testing.T) {
+	got := make(chan map[string]any, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("invalid JSON body: %v", err)
+		}
+		got <- msg
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	s := Summary{
+		Owner: "acme", Repo: "widgets", PRNumber: 7, Title: "Add widgets", HeadSHA: "abc1234def",
+		FilesReviewed: 2, ViolationsFound: 3, BySeverity: map[string]int{"warning": 3},
+		TopFindings: []review.FileViolation{{Path: "main.go", Line: 4, Severity: "warning", Message: "Long function"}},
+	}
+
+	tests := []struct {
+		name     string
+		opts     DiscordOptions
+		wantText []string
+		skipText []string
+	}{
+		{name: "with findings", opts: DiscordOptions{Username: "Review Bot", Findings: true},
+			wantText: []string{`"username":"Review Bot"`, `"color":13801762`, "acme/widgets#7: 3 findings", "`main.go:4` Long function", "…and 2 more", "abc1234"}},
+		{name: "counts only", opts: DiscordOptions{},
+			wantText: []string{`"Findings"`}, skipText: []string{"Long function", `"username"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewDiscord(srv.URL, tt.opts).Notify(context.Background(), s); err != nil {
+				t.Fatalf("Notify() error = %v", err)
+			}
+			msg, _ := json.Marshal(<-got)
+			for _, want := range tt.wantText {
+				if !strings.Contains(string(msg), want) {
+					t.Errorf("message missing %q: %s", want, msg)
+				}
+			}
+			for _, skip := range tt.skipText {
+				if strings.Contains(string(msg), skip) {
+					t.Errorf("message should not contain %q: %s", skip, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestDiscord_ScanEvents(t *testing.T) {
+	got := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		got <- msg
+	}))
+	defer srv.Close()
+
+	if NewDiscord(srv.URL, DiscordOptions{}).Wants(EventScanCompleted) {
+		t.Error("Wants(scan.completed) = true without Scans")
+	}
+	dc := NewDiscord(srv.URL, DiscordOptions{Scans: true})
+	if !dc.Wants(EventScanCompleted) || dc.Wants(EventReviewCompleted) {
+		t.Error("with Scans, Discord should want only scan.completed events")
+	}
+
+	d := NewDispatcher()
+	d.AddSink("discord", dc)
+	d.Emit(EventScanCompleted, ScanEvent{Repository: "acme/widgets", Branch: "main", Status: "failed", Error: "clone timed out"})
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	msg, _ := json.Marshal(<-got)
+	for _, want := range []string{"Scan of acme/widgets failed", "clone timed out", `"color":13574702`} {
+		if !strings.Contains(string(msg), want) {
+			t.Errorf("message missing %q: %s", want, msg)
+		}
+	}
+}
+
+func TestDispatcher_SendsToEveryNotifier(t *testing.T) {
+	ok := &fakeNotifier{}
+	failing := &fakeNotifier{err: errors.New("down")}
+	d := NewDispatcher()
+	d.Add("failing", failing)
+	d.Add("ok", ok)
+
+	d.Send(Summary{Owner: "acme", Repo: "widgets", PRNumber: 7})
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if len(ok.summaries) != 1 || len(failing.summaries) != 1 {
+		t.Errorf("deliveries = %d and %d, want one each", len(ok.summaries), len(failing.summaries))
+	}
+}
+
+func TestDispatcher_SendsToChannelsOfSummary(t *testing.T) {
+	all, payments, web := &fakeNotifier{}, &fakeNotifier{}, &fakeNotifier{}
+	d := NewDispatcher()
+	d.Add("all", all)
+	d.AddChannel("payments", "teams:payments", payments)
+	d.AddChannel("web", "teams:web", web)
+
+	d.Send(Summary{Owner: "acme", Repo: "mono", PRNumber: 7, Channels: []string{"payments"}})
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if len(all.summaries) != 1 || len(payments.summaries) != 1 || len(web.summaries) != 0 {
+		t.Errorf("deliveries = %d, %d and %d, want the channel-less and payments notifiers only", len(all.summaries), len(payments.summaries), len(web.summaries))
+	}
+}
+
+func TestWebhook_DeliverSigned(t *testing.T) {
+	type delivery struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan delivery, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- delivery{header: r.Header, body: body}
+	}))
+	defer srv.Close()
+
+	d := NewDispatcher()
+	d.AddSink("test", NewWebhook(srv.URL, "s3cret", nil))
+	d.Emit(EventScanCompleted, ScanEvent{Repository: "acme/widgets", Branch: "main", Status: "succeeded"})
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	dl := <-got
+	if dl.header.Get(HeaderEvent) != EventScanCompleted || dl.header.Get(HeaderDelivery) == "" {
+		t.Errorf("headers = %v", dl.header)
+	}
+	if sig := dl.header.Get(HeaderSignature); sig != Sign("s3cret", dl.body) {
+		t.Errorf("signature = %q, want %q", sig, Sign("s3cret", dl.body))
+	}
+	var e struct {
+		ID   string    `json:"id"`
+		Type string    `json:"type"`
+		Data ScanEvent `json:"data"`
+	}
+	if err := json.Unmarshal(dl.body, &e); err != nil {
+		t.Fatalf("invalid event body: %v", err)
+	}
+	if e.ID != dl.header.Get(HeaderDelivery) || e.Type != EventScanCompleted || e.Data.Repository != "acme/widgets" {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"a":1}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=aa9e2e3575f5d7098b6caccd790888c36d5fdb63342a73bada2d6a51747a8494"
+	if got := Sign("secret", []byte(`{"a":1}`)); got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+}
+
+type recordingSink struct {
+	mu     sync.Mutex
+	types  []string
+	events []Event
+}
+
+func (r *recordingSink) Wants(eventType string) bool {
+	return len(r.types) == 0 || slices.Contains(r.types, eventType)
+}
+
+func (r *recordingSink) Deliver(ctx context.Context, e Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+	return nil
+}
+
+func TestDispatcher_EmitFiltersByType(t *testing.T) {
+	all := &recordingSink{}
+	jobsOnly := &recordingSink{types: []string{EventJobFailed}}
+	d := NewDispatcher()
+	d.AddSink("all", all)
+	d.AddSink("jobs", jobsOnly)
+
+	d.Send(Summary{Owner: "acme", Repo: "widgets", PRNumber: 7, Error: "boom"})
+	d.Emit(EventJobFailed, map[string]string{"id": "job-1"})
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if len(all.events) != 2 || len(jobsOnly.events) != 1 || jobsOnly.events[0].Type != EventJobFailed {
+		t.Fatalf("all = %+v, jobsOnly = %+v", all.events, jobsOnly.events)
+	}
+	for _, e := range all.events {
+		if e.Type != EventReviewCompleted {
+			continue
+		}
+		if data := e.Data.(ReviewEvent); data.Status != "failed" || data.Repository != "acme/widgets" {
+			t.Errorf("review event data = %+v", data)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Teams posts review summaries as Adaptive Cards to a Microsoft Teams
+// incoming webhook or Workflows (Power Automate) webhook URL
+type Teams struct {
+	url    string
+	client *http.Client
+}
+
+// NewTeams returns a notifier posting to webhookURL
+func NewTeams(webhookURL string) *Teams {
+	return &Teams{url: webhookURL, client: &http.Client{Timeout: sendTimeout}}
+}
+
+func (t *Teams) Notify(ctx context.Context, s Summary) error {
+	body, err := json.Marshal(map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     adaptiveCard(s),
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("encode teams message: %w", err)
+	}
+	return post(ctx, t.client, t.url, "application/json", nil, body)
+}
+
+// adaptiveCard renders s as an Adaptive Card 1.4, the newest version Teams
+// renders everywhere
+func adaptiveCard(s Summary) map[string]any {
+	color := "Good"
+	if s.Error != "" || s.BySeverity["error"] > 0 {
+		color = "Attention"
+	} else if s.ViolationsFound > 0 {
+		color = "Warning"
+	}
+
+	body := []map[string]any{
+		{"type": "TextBlock", "text": s.Headline(), "weight": "Bolder", "size": "Medium", "color": color, "wrap": true},
+	}
+	if s.Title != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": s.Title, "isSubtle": true, "spacing": "None", "wrap": true})
+	}
+
+	if s.Error != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": s.Error, "color": "Attention", "wrap": true})
+	} else {
+		facts := []map[string]string{
+			{"title": "Files reviewed", "value": fmt.Sprint(s.FilesReviewed)},
+			{"title": "Findings", "value": fmt.Sprint(s.ViolationsFound)},
+		}
+		for _, sev := range []string{"error", "warning", "suggestion"} {
+			if n := s.BySeverity[sev]; n > 0 {
+				facts = append(facts, map[string]string{"title": "  " + sev + "s", "value": fmt.Sprint(n)})
+			}
+		}
+		if s.HeadSHA != "" {
+			facts = append(facts, map[string]string{"title": "Commit", "value": shortSHA(s.HeadSHA)})
+		}
+		body = append(body, map[string]any{"type": "FactSet", "facts": facts})
+	}
+
+	for _, v := range s.TopFindings {
+		body = append(body, map[string]any{
+			"type": "TextBlock",
+			"text": fmt.Sprintf("**%s** `%s:%d` %s", v.Severity, v.Path, v.Line, v.Message),
+			"wrap": true,
+		})
+	}
+	if more := s.ViolationsFound - len(s.TopFindings); more > 0 && len(s.TopFindings) > 0 {
+		body = append(body, map[string]any{"type": "TextBlock", "text": fmt.Sprintf("…and %d more", more), "isSubtle": true})
+	}
+
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if s.URL != "" {
+		card["actions"] = []map[string]any{{"type": "Action.OpenUrl", "title": "View pull request", "url": s.URL}}
+	}
+	return card
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,107 @@
+package prworkspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CleanupStale deletes PR workspaces whose sentinel file has not been touched
+// for longer than ttl. It catches directories left behind when a "closed"
+// webhook was missed. Only directories carrying the sentinel are removed.
+func (m *Manager) CleanupStale(ctx context.Context, ttl time.Duration) (int, error) {
+	if ttl <= 0 {
+		return 0, nil
+	}
+
+	baseDir, err := normalizeBaseDir(m.baseDir)
+	if err != nil {
+		return 0, err
+	}
+
+	prDirs, err := filepath.Glob(filepath.Join(baseDir, "*", "*", "pr-*"))
+	if err != nil {
+		return 0, fmt.Errorf("list pr workspaces: %w", err)
+	}
+
+	cutoff := time.Now().Add(-ttl)
+	removed := 0
+	var errs []error
+	for _, prDir := range prDirs {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+
+		ok, err := m.removeIfStale(prDir, baseDir, cutoff)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			removed++
+		}
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+// StartJanitor runs CleanupStale immediately and then every interval until
+// ctx is cancelled
+func (m *Manager) StartJanitor(ctx context.Context, ttl, interval time.Duration) {
+	if ttl <= 0 || interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			removed, err := m.CleanupStale(ctx, ttl)
+			if err != nil && ctx.Err() == nil {
+				slog.Warn("workspace cleanup failed", "base_dir", m.baseDir, "removed", removed, "error", err)
+			} else if removed > 0 {
+				slog.Info("removed stale pr workspaces", "base_dir", m.baseDir, "removed", removed, "ttl", ttl)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (m *Manager) removeIfStale(prDir, baseDir string, cutoff time.Time) (bool, error) {
+	repo, prNumber, ok := parsePRDir(baseDir, prDir)
+	if !ok {
+		return false, nil
+	}
+
+	defer m.locks.Lock(fmt.Sprintf("%s#%d", repo, prNumber))()
+
+	// Re-check under the lock so a concurrent EnsurePRDir keeps the workspace
+	info, err := os.Stat(filepath.Join(prDir, sentinelFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("stat sentinel: %w", err)
+	}
+	if info.ModTime().After(cutoff) {
+		return false, nil
+	}
+
+	if err := m.validateSafeDelete(prDir); err != nil {
+		return false, err
+	}
+	if err := os.RemoveAll(prDir); err != nil {
+		return false, fmt.Errorf("delete stale pr workspace %q: %w", prDir, err)
+	}
+	m.releaseWorktree(prDir, prNumber)
+	return true, nil
+}
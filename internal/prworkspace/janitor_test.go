@@ -0,0 +1,110 @@
+package prworkspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManager_CleanupStale(t *testing.T) {
+	base := t.TempDir()
+	m := NewManager(base)
+	ctx := context.Background()
+
+	stale, err := m.EnsurePRDir(ctx, "owner/repo", 1)
+	if err != nil {
+		t.Fatalf("EnsurePRDir() error = %v", err)
+	}
+	fresh, err := m.EnsurePRDir(ctx, "owner/repo", 2)
+	if err != nil {
+		t.Fatalf("EnsurePRDir() error = %v", err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(filepath.Join(stale, sentinelFileName), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// Directories without the sentinel are never touched
+	foreign := filepath.Join(base, "owner", "repo", "pr-3")
+	if err := os.MkdirAll(foreign, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(foreign, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := m.CleanupStale(ctx, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("CleanupStale() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed = %d, want 1", removed)
+	}
+
+	tests := []struct {
+		dir      string
+		wantGone bool
+	}{
+		{dir: stale, wantGone: true},
+		{dir: fresh},
+		{dir: foreign},
+	}
+	for _, tt := range tests {
+		_, err := os.Stat(tt.dir)
+		if gone := os.IsNotExist(err); gone != tt.wantGone {
+			t.Errorf("%s gone = %v, want %v", tt.dir, gone, tt.wantGone)
+		}
+	}
+}
+
+func TestManager_EnsurePRDirRefreshesSentinel(t *testing.T) {
+	m := NewManager(t.TempDir())
+	ctx := context.Background()
+
+	dir, err := m.EnsurePRDir(ctx, "owner/repo", 1)
+	if err != nil {
+		t.Fatalf("EnsurePRDir() error = %v", err)
+	}
+	sentinel := filepath.Join(dir, sentinelFileName)
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(sentinel, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.EnsurePRDir(ctx, "owner/repo", 1); err != nil {
+		t.Fatalf("EnsurePRDir() error = %v", err)
+	}
+	if removed, _ := m.CleanupStale(ctx, 24*time.Hour); removed != 0 {
+		t.Errorf("removed = %d, want 0 for a workspace in use", removed)
+	}
+}
+
+func TestManager_List(t *testing.T) {
+	m := NewManager(t.TempDir())
+	ctx := context.Background()
+
+	for _, pr := range []int{10, 2} {
+		if _, err := m.EnsurePRDir(ctx, "owner/repo", pr); err != nil {
+			t.Fatalf("EnsurePRDir() error = %v", err)
+		}
+	}
+	before := time.Now().Add(-time.Minute)
+
+	got, err := m.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != 2 || got[0].PRNumber != 2 || got[1].PRNumber != 10 {
+		t.Fatalf("List() = %+v, want PRs 2 and 10 in order", got)
+	}
+	for _, ws := range got {
+		if ws.Repo != "owner/repo" || ws.SizeBytes == 0 {
+			t.Errorf("workspace = %+v, want repo owner/repo with sentinel size", ws)
+		}
+		if ws.CreatedAt.Before(before) || ws.LastUsedAt.Before(before) {
+			t.Errorf("workspace times = %v / %v, want recent", ws.CreatedAt, ws.LastUsedAt)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package prworkspace
+
+import "sync"
+
+// keyedMutex hands out one mutex per key and forgets a key as soon as nobody
+// holds or waits for it, so the map stays as small as the set of busy keys
+type keyedMutex struct {
+	mu      sync.Mutex
+	entries map[string]*keyedEntry
+}
+
+type keyedEntry struct {
+	mu   sync.Mutex
+	refs int // holders plus waiters
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{entries: make(map[string]*keyedEntry)}
+}
+
+// Lock blocks until key is free and returns the function that releases it
+func (k *keyedMutex) Lock(key string) (unlock func()) {
+	k.mu.Lock()
+	e, ok := k.entries[key]
+	if !ok {
+		e = &keyedEntry{}
+		k.entries[key] = e
+	}
+	e.refs++
+	k.mu.Unlock()
+
+	e.mu.Lock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.mu.Unlock()
+
+			k.mu.Lock()
+			defer k.mu.Unlock()
+			e.refs--
+			if e.refs == 0 {
+				delete(k.entries, key)
+			}
+		})
+	}
+}
+
+// size reports how many keys are currently tracked
+func (k *keyedMutex) size() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.entries)
+}
@@ -0,0 +1,78 @@
+package prworkspace
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestKeyedMutex_ExclusiveAndForgetsKeys(t *testing.T) {
+	k := newKeyedMutex()
+
+	var wg sync.WaitGroup
+	counters := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("k%d", i%4)
+		wg.Go(func() {
+			unlock := k.Lock(key)
+			defer unlock()
+			counters[key]++ // raced without per-key exclusion
+		})
+	}
+	wg.Wait()
+
+	for key, n := range counters {
+		if n != 50 {
+			t.Errorf("counter %s = %d, want 50", key, n)
+		}
+	}
+	if n := k.size(); n != 0 {
+		t.Errorf("size() = %d after all locks released, want 0", n)
+	}
+}
+
+func TestKeyedMutex_UnlockTwiceIsHarmless(t *testing.T) {
+	k := newKeyedMutex()
+	unlock := k.Lock("a")
+	unlock()
+	unlock()
+
+	// The key must still be lockable and tracked once
+	other := k.Lock("a")
+	if n := k.size(); n != 1 {
+		t.Errorf("size() = %d, want 1", n)
+	}
+	other()
+}
+
+func TestManager_ConcurrentEnsureDelete(t *testing.T) {
+	m := NewManager(t.TempDir())
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 400)
+	for i := 0; i < 100; i++ {
+		repo := fmt.Sprintf("owner/repo%d", i%5)
+		pr := i%3 + 1
+		wg.Go(func() {
+			if _, err := m.EnsurePRDir(ctx, repo, pr); err != nil {
+				errs <- err
+			}
+		})
+		wg.Go(func() {
+			if err := m.DeletePRDir(ctx, repo, pr); err != nil {
+				errs <- err
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent Ensure/Delete error = %v", err)
+	}
+	if n := m.locks.size(); n != 0 {
+		t.Errorf("lock map holds %d keys after all operations finished, want 0", n)
+	}
+}
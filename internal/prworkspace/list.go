@@ -0,0 +1,88 @@
+package prworkspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Workspace describes an existing PR workspace on disk
+type Workspace struct {
+	Repo       string    `json:"repo"`
+	PRNumber   int       `json:"pr"`
+	Path       string    `json:"path"`
+	SizeBytes  int64     `json:"size_bytes"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// List returns every sentinel-marked PR workspace under the base dir, ordered
+// by repo and PR number
+func (m *Manager) List(ctx context.Context) ([]Workspace, error) {
+	baseDir, err := normalizeBaseDir(m.baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	prDirs, err := filepath.Glob(filepath.Join(baseDir, "*", "*", "pr-*"))
+	if err != nil {
+		return nil, fmt.Errorf("list pr workspaces: %w", err)
+	}
+
+	workspaces := make([]Workspace, 0, len(prDirs))
+	for _, prDir := range prDirs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		repo, prNumber, ok := parsePRDir(baseDir, prDir)
+		if !ok {
+			continue
+		}
+		sentinel := filepath.Join(prDir, sentinelFileName)
+		info, err := os.Stat(sentinel)
+		if err != nil {
+			continue // not ours, or deleted meanwhile
+		}
+		size, err := dirSize(prDir)
+		if err != nil {
+			return nil, err
+		}
+
+		workspaces = append(workspaces, Workspace{
+			Repo:       repo,
+			PRNumber:   prNumber,
+			Path:       prDir,
+			SizeBytes:  size,
+			CreatedAt:  createdAt(sentinel, prDir),
+			LastUsedAt: info.ModTime().UTC(),
+		})
+	}
+
+	// Glob orders pr-10 before pr-2; sort numerically within a repo
+	sort.Slice(workspaces, func(i, j int) bool {
+		if workspaces[i].Repo != workspaces[j].Repo {
+			return workspaces[i].Repo < workspaces[j].Repo
+		}
+		return workspaces[i].PRNumber < workspaces[j].PRNumber
+	})
+	return workspaces, nil
+}
+
+// createdAt reads the creation time recorded in the sentinel, falling back to
+// the directory's mtime for sentinels written before it was recorded
+func createdAt(sentinel, prDir string) time.Time {
+	if data, err := os.ReadFile(sentinel); err == nil {
+		if t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data))); err == nil {
+			return t.UTC()
+		}
+	}
+	if info, err := os.Stat(prDir); err == nil {
+		return info.ModTime().UTC()
+	}
+	return time.Time{}
+}
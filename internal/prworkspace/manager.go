@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 const sentinelFileName = ".prmate-workdir"
@@ -15,16 +17,23 @@ const sentinelFileName = ".prmate-workdir"
 type Manager struct {
 	baseDir string
 
-	mu    sync.Mutex
-	locks map[string]*sync.Mutex
+	locks *keyedMutex // per PR and per repository
+
+	mu         sync.Mutex // guards the settings below
+	quotaBytes int64
+	quotaGlobs []string
+	usedBytes  int64     // disk usage last measured
+	usedAt     time.Time // when usedBytes was measured; zero when unknown
+
+	remote       Remote
+	fetchTimeout time.Duration
 }
 
 func NewManager(baseDir string) *Manager {
-	return &Manager{baseDir: baseDir, locks: make(map[string]*sync.Mutex)}
+	return &Manager{baseDir: baseDir, locks: newKeyedMutex()}
 }
 
 func (m *Manager) EnsurePRDir(ctx context.Context, repoFullName string, prNumber int) (string, error) {
-	_ = ctx
 	if prNumber <= 0 {
 		return "", fmt.Errorf("invalid pr number: %d", prNumber)
 	}
@@ -34,11 +43,22 @@ func (m *Manager) EnsurePRDir(ctx context.Context, repoFullName string, prNumber
 		return "", err
 	}
 
-	lock := m.lockFor(key)
-	lock.Lock()
-	defer lock.Unlock()
+	// Make room before taking the lock; eviction locks each victim in turn
+	if err := m.ensureSpace(ctx, prDir); err != nil {
+		return "", err
+	}
+
+	defer m.locks.Lock(key)()
+
+	m.mu.Lock()
+	remote := m.remote
+	m.mu.Unlock()
 
-	if err := os.MkdirAll(prDir, 0o755); err != nil {
+	if remote != nil {
+		if err := m.checkoutPR(ctx, remote, repoFullName, prNumber, prDir); err != nil {
+			return "", err
+		}
+	} else if err := os.MkdirAll(prDir, 0o755); err != nil {
 		return "", fmt.Errorf("create pr workspace dir: %w", err)
 	}
 
@@ -47,6 +67,12 @@ func (m *Manager) EnsurePRDir(ctx context.Context, repoFullName string, prNumber
 		return "", err
 	}
 
+	// The sentinel's mtime marks last use for the stale-workspace janitor
+	now := time.Now()
+	if err := os.Chtimes(sentinelPath, now, now); err != nil {
+		return "", fmt.Errorf("touch sentinel file: %w", err)
+	}
+
 	return prDir, nil
 }
 
@@ -61,9 +87,7 @@ func (m *Manager) DeletePRDir(ctx context.Context, repoFullName string, prNumber
 		return err
 	}
 
-	lock := m.lockFor(key)
-	lock.Lock()
-	defer lock.Unlock()
+	defer m.locks.Lock(key)()
 
 	if _, err := os.Stat(prDir); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -87,21 +111,36 @@ func (m *Manager) DeletePRDir(ctx context.Context, repoFullName string, prNumber
 	if err := os.RemoveAll(prDir); err != nil {
 		return fmt.Errorf("delete pr workspace dir: %w", err)
 	}
+	m.releaseWorktree(prDir, prNumber)
+	m.forgetUsage()
 
 	return nil
 }
 
-func (m *Manager) lockFor(key string) *sync.Mutex {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// CheckWritable verifies the work base dir exists (creating it if needed)
+// and that files can be written to it
+func (m *Manager) CheckWritable(ctx context.Context) error {
+	_ = ctx
+	baseDir, err := normalizeBaseDir(m.baseDir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return fmt.Errorf("create work base dir: %w", err)
+	}
 
-	lock, ok := m.locks[key]
-	if !ok {
-		lock = &sync.Mutex{}
-		m.locks[key] = lock
+	f, err := os.CreateTemp(baseDir, ".prmate-probe-*")
+	if err != nil {
+		return fmt.Errorf("write to work base dir: %w", err)
+	}
+	name := f.Name()
+	_ = f.Close()
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("remove probe file: %w", err)
 	}
 
-	return lock
+	return nil
 }
 
 func (m *Manager) prDirPath(repoFullName string, prNumber int) (dir string, key string, err error) {
@@ -120,6 +159,24 @@ func (m *Manager) prDirPath(repoFullName string, prNumber int) (dir string, key
 	return dir, key, nil
 }
 
+// parsePRDir extracts the repo and PR number from a workspace path of the
+// form <base>/<owner>/<repo>/pr-<n>
+func parsePRDir(baseDir, prDir string) (repo string, prNumber int, ok bool) {
+	rel, err := filepath.Rel(baseDir, prDir)
+	if err != nil {
+		return "", 0, false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) != 3 {
+		return "", 0, false
+	}
+	prNumber, err = strconv.Atoi(strings.TrimPrefix(parts[2], "pr-"))
+	if err != nil || prNumber <= 0 {
+		return "", 0, false
+	}
+	return parts[0] + "/" + parts[1], prNumber, true
+}
+
 func normalizeBaseDir(baseDir string) (string, error) {
 	if strings.TrimSpace(baseDir) == "" {
 		return "", errors.New("work base dir is empty")
@@ -217,10 +274,18 @@ func (m *Manager) validateSafeDelete(targetDir string) error {
 	return nil
 }
 
+// writeSentinelIfMissing creates the sentinel recording the workspace's
+// creation time
 func writeSentinelIfMissing(path string) error {
 	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
 	if err == nil {
-		_ = f.Close()
+		_, err = f.WriteString(time.Now().UTC().Format(time.RFC3339) + "\n")
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("write sentinel file: %w", err)
+		}
 		return nil
 	}
 	if errors.Is(err, os.ErrExist) {
@@ -0,0 +1,200 @@
+package prworkspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ErrDiskFull is returned when the disk quota is exhausted and evicting old
+// workspaces could not free enough space
+var ErrDiskFull = errors.New("workspace disk quota exceeded")
+
+// usageTTL is how long a measured disk usage is reused: measuring walks
+// every file of every workspace
+const usageTTL = 30 * time.Second
+
+// SetQuota caps the bytes used by the manager's PR workspaces plus any
+// directories matching extraGlobs (e.g. scan clones). Zero disables the
+// quota.
+func (m *Manager) SetQuota(maxBytes int64, extraGlobs ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.quotaBytes = maxBytes
+	m.quotaGlobs = extraGlobs
+	m.usedAt = time.Time{}
+}
+
+// Usage returns the bytes used by the manager's own PR workspaces, their
+// bare clones and any extra quota directories. Other files under the base
+// dir, such as the workspaces of other instances nested in it, do not
+// count. A usage measured in the last usageTTL is reused.
+func (m *Manager) Usage(ctx context.Context) (int64, error) {
+	baseDir, err := normalizeBaseDir(m.baseDir)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	globs := m.quotaGlobs
+	if !m.usedAt.IsZero() && time.Since(m.usedAt) < usageTTL {
+		used := m.usedBytes
+		m.mu.Unlock()
+		return used, nil
+	}
+	m.mu.Unlock()
+
+	dirs, err := ownDirs(baseDir)
+	if err != nil {
+		return 0, err
+	}
+	for _, pattern := range globs {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return 0, fmt.Errorf("match quota dirs %q: %w", pattern, err)
+		}
+		dirs = append(dirs, matches...)
+	}
+
+	var total int64
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		size, err := dirSize(dir)
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+	m.setUsage(total)
+	return total, nil
+}
+
+// setUsage records used as the disk usage just measured
+func (m *Manager) setUsage(used int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usedBytes = used
+	m.usedAt = time.Now()
+}
+
+// forgetUsage has the next Usage call measure again
+func (m *Manager) forgetUsage() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usedAt = time.Time{}
+}
+
+// ownDirs returns the manager's PR workspaces under baseDir and the bare
+// clones next to them
+func ownDirs(baseDir string) ([]string, error) {
+	prDirs, err := filepath.Glob(filepath.Join(baseDir, "*", "*", "pr-*"))
+	if err != nil {
+		return nil, fmt.Errorf("list pr workspaces: %w", err)
+	}
+
+	var dirs []string
+	repoDirs := make(map[string]bool)
+	for _, prDir := range prDirs {
+		if _, _, ok := parsePRDir(baseDir, prDir); !ok {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(prDir, sentinelFileName)); err != nil {
+			continue // not ours, or deleted meanwhile
+		}
+		dirs = append(dirs, prDir)
+		if repoDir := filepath.Dir(prDir); !repoDirs[repoDir] {
+			repoDirs[repoDir] = true
+			dirs = append(dirs, filepath.Join(repoDir, bareDirName))
+		}
+	}
+	return dirs, nil
+}
+
+// EnsureSpace checks usage against the quota, evicting least recently used
+// PR workspaces until usage drops below it. It returns ErrDiskFull when that
+// is not enough, so callers can refuse to clone.
+func (m *Manager) EnsureSpace(ctx context.Context) error {
+	return m.ensureSpace(ctx, "")
+}
+
+func (m *Manager) ensureSpace(ctx context.Context, keep string) error {
+	m.mu.Lock()
+	quota := m.quotaBytes
+	m.mu.Unlock()
+	if quota <= 0 {
+		return nil
+	}
+
+	used, err := m.Usage(ctx)
+	if err != nil {
+		return fmt.Errorf("measure disk usage: %w", err)
+	}
+	if used < quota {
+		return nil
+	}
+
+	workspaces, err := m.List(ctx)
+	if err != nil {
+		return err
+	}
+	// Least recently used first
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].LastUsedAt.Before(workspaces[j].LastUsedAt) })
+
+	for _, ws := range workspaces {
+		if used < quota {
+			break
+		}
+		if ws.Path == keep {
+			continue
+		}
+		if err := m.DeletePRDir(ctx, ws.Repo, ws.PRNumber); err != nil {
+			slog.Warn("failed to evict pr workspace", "dir", ws.Path, "error", err)
+			continue
+		}
+		slog.Info("evicted pr workspace to free disk", "repo", ws.Repo, "pr", ws.PRNumber, "bytes", ws.SizeBytes)
+		used -= ws.SizeBytes
+	}
+	m.setUsage(used)
+
+	if used >= quota {
+		return fmt.Errorf("%w: %d of %d bytes used", ErrDiskFull, used, quota)
+	}
+	return nil
+}
+
+// dirSize sums the sizes of regular files under dir; a missing dir is empty
+func dirSize(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("measure %q: %w", dir, err)
+	}
+	return total, nil
+}
@@ -0,0 +1,107 @@
+package prworkspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fillWorkspace creates a PR workspace holding size bytes, last used at lastUsed
+func fillWorkspace(t *testing.T, m *Manager, prNumber int, size int, lastUsed time.Time) string {
+	t.Helper()
+	dir, err := m.EnsurePRDir(context.Background(), "owner/repo", prNumber)
+	if err != nil {
+		t.Fatalf("EnsurePRDir() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "blob"), make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, sentinelFileName), lastUsed, lastUsed); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestManager_EnsureSpaceEvictsLRU(t *testing.T) {
+	m := NewManager(t.TempDir())
+	now := time.Now()
+	oldest := fillWorkspace(t, m, 1, 400, now.Add(-3*time.Hour))
+	middle := fillWorkspace(t, m, 2, 400, now.Add(-2*time.Hour))
+	newest := fillWorkspace(t, m, 3, 400, now.Add(-time.Hour))
+
+	m.SetQuota(1000)
+	if err := m.EnsureSpace(context.Background()); err != nil {
+		t.Fatalf("EnsureSpace() error = %v", err)
+	}
+
+	tests := []struct {
+		dir      string
+		wantGone bool
+	}{
+		{dir: oldest, wantGone: true},
+		{dir: middle},
+		{dir: newest},
+	}
+	for _, tt := range tests {
+		_, err := os.Stat(tt.dir)
+		if gone := os.IsNotExist(err); gone != tt.wantGone {
+			t.Errorf("%s gone = %v, want %v", tt.dir, gone, tt.wantGone)
+		}
+	}
+}
+
+func TestManager_EnsureSpaceCountsExtraDirs(t *testing.T) {
+	m := NewManager(t.TempDir())
+	scratch := t.TempDir()
+	if err := os.WriteFile(filepath.Join(scratch, "clone"), make([]byte, 2000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m.SetQuota(1000, scratch)
+
+	// Nothing evictable can bring usage under the quota
+	if err := m.EnsureSpace(context.Background()); !errors.Is(err, ErrDiskFull) {
+		t.Errorf("EnsureSpace() error = %v, want ErrDiskFull", err)
+	}
+	if _, err := m.EnsurePRDir(context.Background(), "owner/repo", 1); !errors.Is(err, ErrDiskFull) {
+		t.Errorf("EnsurePRDir() error = %v, want ErrDiskFull", err)
+	}
+
+	m.SetQuota(0)
+	if err := m.EnsureSpace(context.Background()); err != nil {
+		t.Errorf("EnsureSpace() with quota disabled error = %v", err)
+	}
+}
+
+func TestManager_UsageCountsOwnWorkspacesOnly(t *testing.T) {
+	base := t.TempDir()
+	m := NewManager(base)
+	first, _ := dirSize(fillWorkspace(t, m, 1, 300, time.Now()))
+
+	// Another instance nested under the base dir, and files that are not
+	// workspaces, belong to someone else
+	other := NewManager(filepath.Join(base, "ghe.example.com@team"))
+	fillWorkspace(t, other, 1, 5000, time.Now())
+	if err := os.WriteFile(filepath.Join(base, "reviews.jsonl"), make([]byte, 7000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	used, err := m.Usage(context.Background())
+	if err != nil || used != first {
+		t.Fatalf("Usage() = %d, %v; want the %d bytes of its own workspace", used, err, first)
+	}
+
+	// Measured usage is reused until a workspace is deleted
+	second, _ := dirSize(fillWorkspace(t, m, 2, 200, time.Now()))
+	if used, _ := m.Usage(context.Background()); used != first {
+		t.Errorf("Usage() = %d, want the cached %d", used, first)
+	}
+	if err := m.DeletePRDir(context.Background(), "owner/repo", 1); err != nil {
+		t.Fatal(err)
+	}
+	if used, _ := m.Usage(context.Background()); used != second {
+		t.Errorf("Usage() after delete = %d, want %d", used, second)
+	}
+}
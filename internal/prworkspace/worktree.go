@@ -0,0 +1,151 @@
+package prworkspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// bareDirName is the shared bare clone kept next to a repository's PR
+// worktrees
+const bareDirName = ".prmate-bare.git"
+
+// Remote supplies the authenticated URL PR heads are fetched from
+type Remote interface {
+	CloneURL(owner, repo string) string
+}
+
+// SetRemote makes EnsurePRDir check each PR out as a git worktree of a single
+// bare clone per repository, fetching only the PR's head ref. Without a
+// remote, workspaces are plain empty directories.
+func (m *Manager) SetRemote(remote Remote, fetchTimeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remote = remote
+	m.fetchTimeout = fetchTimeout
+}
+
+// checkoutPR fetches the PR head into the repository's bare clone and checks
+// it out at prDir, creating the worktree on first use. The caller holds the
+// PR's lock.
+func (m *Manager) checkoutPR(ctx context.Context, remote Remote, repoFullName string, prNumber int, prDir string) error {
+	owner, repo, _ := strings.Cut(strings.TrimSpace(repoFullName), "/")
+	repoDir := filepath.Dir(prDir)
+	bareDir := filepath.Join(repoDir, bareDirName)
+	ref := fmt.Sprintf("refs/prmate/pr-%d", prNumber)
+
+	defer m.locks.Lock(repoLockKey(repoDir))()
+
+	if err := initBare(ctx, bareDir); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	timeout := m.fetchTimeout
+	m.mu.Unlock()
+	fetchCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		fetchCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	url := remote.CloneURL(owner, repo)
+	refspec := fmt.Sprintf("+refs/pull/%d/head:%s", prNumber, ref)
+	if out, err := git(fetchCtx, "", "--git-dir", bareDir, "fetch", "--depth=1", "--no-tags", url, refspec); err != nil {
+		return fmt.Errorf("fetch pr head: %s: %w", redact(out, url), err)
+	}
+
+	if _, err := os.Stat(filepath.Join(prDir, ".git")); err == nil {
+		if out, err := git(ctx, prDir, "checkout", "--force", "--detach", ref); err != nil {
+			return fmt.Errorf("checkout pr head: %s: %w", out, err)
+		}
+		return nil
+	}
+
+	// A plain directory from before worktrees were used is replaced
+	if _, err := os.Stat(prDir); err == nil {
+		if err := m.validateSafeDelete(prDir); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(prDir); err != nil {
+			return fmt.Errorf("replace pr workspace dir: %w", err)
+		}
+	}
+
+	_, _ = git(ctx, "", "--git-dir", bareDir, "worktree", "prune")
+	if out, err := git(ctx, "", "--git-dir", bareDir, "worktree", "add", "--force", "--detach", prDir, ref); err != nil {
+		return fmt.Errorf("add pr worktree: %s: %w", out, err)
+	}
+	return nil
+}
+
+// releaseWorktree tidies the bare clone after a PR workspace was removed,
+// deleting the clone once no PR of the repository is left. The caller holds
+// the PR's lock.
+func (m *Manager) releaseWorktree(prDir string, prNumber int) {
+	repoDir := filepath.Dir(prDir)
+	bareDir := filepath.Join(repoDir, bareDirName)
+	if _, err := os.Stat(bareDir); err != nil {
+		return
+	}
+
+	defer m.locks.Lock(repoLockKey(repoDir))()
+
+	ctx := context.Background()
+	_, _ = git(ctx, "", "--git-dir", bareDir, "worktree", "prune")
+	_, _ = git(ctx, "", "--git-dir", bareDir, "update-ref", "-d", fmt.Sprintf("refs/prmate/pr-%d", prNumber))
+
+	remaining, _ := filepath.Glob(filepath.Join(repoDir, "pr-*"))
+	if len(remaining) > 0 {
+		return
+	}
+	if err := os.RemoveAll(bareDir); err != nil {
+		slog.Warn("failed to remove bare clone", "dir", bareDir, "error", err)
+	}
+}
+
+func initBare(ctx context.Context, bareDir string) error {
+	if _, err := os.Stat(bareDir); err == nil {
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("stat bare clone: %w", err)
+	}
+
+	if out, err := git(ctx, "", "init", "--bare", "--quiet", bareDir); err != nil {
+		return fmt.Errorf("init bare clone: %s: %w", out, err)
+	}
+	// Keep the sentinel out of `git status` in every worktree
+	exclude := filepath.Join(bareDir, "info", "exclude")
+	if err := os.MkdirAll(filepath.Dir(exclude), 0o755); err != nil {
+		return fmt.Errorf("create bare clone info dir: %w", err)
+	}
+	if err := os.WriteFile(exclude, []byte("/"+sentinelFileName+"\n"), 0o644); err != nil {
+		return fmt.Errorf("write bare clone excludes: %w", err)
+	}
+	return nil
+}
+
+// repoLockKey serialises git operations on a repository's bare clone
+func repoLockKey(repoDir string) string {
+	return "repo:" + repoDir
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	out, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// redact keeps the credentials embedded in url out of error messages
+func redact(out, url string) string {
+	return strings.ReplaceAll(out, url, "<remote>")
+}
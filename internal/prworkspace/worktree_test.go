@@ -0,0 +1,97 @@
+package prworkspace
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// localRemote serves PR heads from a repository on disk
+type localRemote struct {
+	dir string
+}
+
+func (r localRemote) CloneURL(owner, repo string) string {
+	return r.dir
+}
+
+// newUpstream creates a repository with one commit per PR head ref
+func newUpstream(t *testing.T, prs ...int) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+	}
+
+	run("init", "--quiet")
+	for _, pr := range prs {
+		name := filepath.Join(dir, "file.txt")
+		if err := os.WriteFile(name, []byte(strconv.Itoa(pr)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", "file.txt")
+		run("commit", "--quiet", "-m", "change")
+		run("update-ref", "refs/pull/"+strconv.Itoa(pr)+"/head", "HEAD")
+	}
+	return dir
+}
+
+func TestManager_EnsurePRDirWorktree(t *testing.T) {
+	upstream := newUpstream(t, 1, 2)
+	base := t.TempDir()
+	m := NewManager(base)
+	m.SetRemote(localRemote{dir: upstream}, 0)
+	ctx := context.Background()
+
+	first, err := m.EnsurePRDir(ctx, "owner/repo", 1)
+	if err != nil {
+		t.Fatalf("EnsurePRDir(1) error = %v", err)
+	}
+	second, err := m.EnsurePRDir(ctx, "owner/repo", 2)
+	if err != nil {
+		t.Fatalf("EnsurePRDir(2) error = %v", err)
+	}
+
+	for dir, want := range map[string]string{first: "1", second: "2"} {
+		got, err := os.ReadFile(filepath.Join(dir, "file.txt"))
+		if err != nil || string(got) != want {
+			t.Errorf("%s/file.txt = %q (%v), want %q", dir, got, err, want)
+		}
+	}
+
+	bare := filepath.Join(base, "owner", "repo", bareDirName)
+	if _, err := os.Stat(bare); err != nil {
+		t.Fatalf("bare clone missing: %v", err)
+	}
+
+	// Ensuring again updates the existing worktree in place
+	if _, err := m.EnsurePRDir(ctx, "owner/repo", 1); err != nil {
+		t.Fatalf("EnsurePRDir(1) again error = %v", err)
+	}
+
+	if err := m.DeletePRDir(ctx, "owner/repo", 1); err != nil {
+		t.Fatalf("DeletePRDir(1) error = %v", err)
+	}
+	if _, err := os.Stat(bare); err != nil {
+		t.Errorf("bare clone removed while PR 2 still uses it: %v", err)
+	}
+	if err := m.DeletePRDir(ctx, "owner/repo", 2); err != nil {
+		t.Fatalf("DeletePRDir(2) error = %v", err)
+	}
+	if _, err := os.Stat(bare); !os.IsNotExist(err) {
+		t.Errorf("bare clone still present after last PR was deleted: %v", err)
+	}
+}
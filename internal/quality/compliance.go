@@ -0,0 +1,154 @@
+package quality
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+// RepoRules is the .prmate.md of one repository of an organization
+type RepoRules struct {
+	Repo  string
+	Rules *review.Rules // nil when the repository has no .prmate.md or it could not be read
+	Err   error         // why .prmate.md could not be read; nil when it is missing
+}
+
+// Compliance summarizes how the repositories of an organization follow
+// their review rules
+type Compliance struct {
+	Org      string           `json:"org"`
+	Since    time.Time        `json:"since"`
+	Repos    []RepoCompliance `json:"repos"`
+	Rules    []RuleCompliance `json:"rules"`
+	Missing  []string         `json:"missing"`           // repositories without .prmate.md
+	Coverage float64          `json:"coverage"`          // share of repositories with .prmate.md
+	Omitted  int              `json:"omitted,omitempty"` // repositories left out past the per-request cap
+}
+
+// RepoCompliance is how one repository follows its rules
+type RepoCompliance struct {
+	Repo          string  `json:"repo"`
+	HasRules      bool    `json:"has_prmate_md"`
+	Rules         int     `json:"rules"`          // rules, checklist items and checks .prmate.md enforces
+	PRs           int     `json:"prs"`            // pull requests reviewed successfully
+	Violating     int     `json:"violating"`      // of PRs, those with at least one finding
+	ViolationRate float64 `json:"violation_rate"` // Violating / PRs
+	Error         string  `json:"error,omitempty"`
+}
+
+// RuleCompliance is how one rule is enforced across the organization
+type RuleCompliance struct {
+	Rule          string   `json:"rule"`
+	Repos         []string `json:"repos"`          // repositories enforcing the rule
+	PRs           int      `json:"prs"`            // their reviewed pull requests that violated it
+	Of            int      `json:"of"`             // their reviewed pull requests
+	ViolationRate float64  `json:"violation_rate"` // PRs / Of
+}
+
+// BuildCompliance summarizes the rules of repos and their findings in
+// records, the reviews of the organization since since. Rules are told
+// apart by their exact wording in .prmate.md, and are matched to findings
+// as loosely as stale rules are.
+func BuildCompliance(org string, repos []RepoRules, records []store.ReviewRecord, since time.Time) Compliance {
+	type reviewed struct {
+		prs  map[int]bool
+		hits map[int]map[string]bool // rules found violated, by PR
+	}
+	byRepo := make(map[string]*reviewed)
+	for _, rec := range records {
+		if rec.Owner != org || rec.Error != "" || rec.FinishedAt.Before(since) {
+			continue
+		}
+		r, ok := byRepo[rec.Repo]
+		if !ok {
+			r = &reviewed{prs: make(map[int]bool), hits: make(map[int]map[string]bool)}
+			byRepo[rec.Repo] = r
+		}
+		r.prs[rec.PRNumber] = true
+		for rule, n := range rec.RuleHits {
+			if n == 0 {
+				continue
+			}
+			if r.hits[rec.PRNumber] == nil {
+				r.hits[rec.PRNumber] = make(map[string]bool)
+			}
+			r.hits[rec.PRNumber][rule] = true
+		}
+	}
+
+	c := Compliance{Org: org, Since: since, Repos: []RepoCompliance{}, Rules: []RuleCompliance{}, Missing: []string{}}
+	rules := make(map[string]*RuleCompliance)
+	withRules := 0
+	for _, repo := range repos {
+		rc := RepoCompliance{Repo: repo.Repo}
+		r := byRepo[repo.Repo]
+		if r != nil {
+			rc.PRs, rc.Violating = len(r.prs), len(r.hits)
+			rc.ViolationRate = rate(rc.Violating, rc.PRs)
+		}
+		switch {
+		case repo.Err != nil:
+			rc.Error = repo.Err.Error()
+		case repo.Rules == nil:
+			c.Missing = append(c.Missing, repo.Repo)
+		default:
+			withRules++
+			rc.HasRules = true
+			for _, rule := range configured(*repo.Rules) {
+				rc.Rules++
+				agg, ok := rules[rule]
+				if !ok {
+					agg = &RuleCompliance{Rule: rule}
+					rules[rule] = agg
+				}
+				agg.Repos = append(agg.Repos, repo.Repo)
+				if r == nil {
+					continue
+				}
+				agg.Of += len(r.prs)
+				for _, hit := range r.hits {
+					if violated(rule, hit) {
+						agg.PRs++
+					}
+				}
+			}
+		}
+		c.Repos = append(c.Repos, rc)
+	}
+	c.Coverage = rate(withRules, len(repos))
+
+	for _, agg := range rules {
+		agg.ViolationRate = rate(agg.PRs, agg.Of)
+		c.Rules = append(c.Rules, *agg)
+	}
+	sort.Slice(c.Repos, func(i, j int) bool { return c.Repos[i].Repo < c.Repos[j].Repo })
+	sort.Strings(c.Missing)
+	sort.Slice(c.Rules, func(i, j int) bool {
+		if len(c.Rules[i].Repos) != len(c.Rules[j].Repos) {
+			return len(c.Rules[i].Repos) > len(c.Rules[j].Repos)
+		}
+		return c.Rules[i].Rule < c.Rules[j].Rule
+	})
+	return c
+}
+
+// violated reports whether any of hits names rule
+func violated(rule string, hits map[string]bool) bool {
+	name := strings.ToLower(rule)
+	for h := range hits {
+		if matchesHit(name, strings.ToLower(h)) {
+			return true
+		}
+	}
+	return false
+}
+
+func rate(n, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return float64(n) / float64(of)
+}
@@ -0,0 +1,69 @@
+package quality
+
+import (
+	"errors"
+	"testing"
+
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+func TestBuildCompliance(t *testing.T) {
+	parse := func(content string) *review.Rules {
+		rules, err := review.ParseRules(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &rules
+	}
+	since := until.AddDate(0, 0, -30)
+	repos := []RepoRules{
+		{Repo: "web", Rules: parse("## Learned Rules\n- Wrap errors with context\n")},
+		{Repo: "api", Rules: parse(prmateMD)},
+		{Repo: "docs"},
+		{Repo: "infra", Err: errors.New("repository is empty")},
+	}
+	records := []store.ReviewRecord{
+		{Owner: "acme", Repo: "api", PRNumber: 1, FinishedAt: until.AddDate(0, 0, -5), ViolationsFound: 2, RuleHits: map[string]int{"Wrap errors": 2}},
+		{Owner: "acme", Repo: "api", PRNumber: 1, FinishedAt: until.AddDate(0, 0, -4), ViolationsFound: 1, RuleHits: map[string]int{"wrap errors with context": 1}},
+		{Owner: "acme", Repo: "api", PRNumber: 2, FinishedAt: until.AddDate(0, 0, -3)},
+		{Owner: "acme", Repo: "api", PRNumber: 3, FinishedAt: until.AddDate(0, 0, -2), Error: "llm unavailable"},
+		{Owner: "acme", Repo: "api", PRNumber: 4, FinishedAt: until.AddDate(0, 0, -40), ViolationsFound: 1, RuleHits: map[string]int{"Never log secrets": 1}},
+		{Owner: "acme", Repo: "web", PRNumber: 7, FinishedAt: until.AddDate(0, 0, -1), ViolationsFound: 1, RuleHits: map[string]int{"Wrap errors with context": 1}},
+		{Owner: "other", Repo: "web", PRNumber: 8, FinishedAt: until.AddDate(0, 0, -1), ViolationsFound: 1, RuleHits: map[string]int{"Wrap errors with context": 1}},
+	}
+
+	c := BuildCompliance("acme", repos, records, since)
+
+	if c.Coverage != 0.5 {
+		t.Errorf("Coverage = %v, want 0.5", c.Coverage)
+	}
+	if len(c.Missing) != 1 || c.Missing[0] != "docs" {
+		t.Errorf("Missing = %v, want [docs]", c.Missing)
+	}
+
+	wantRepos := []RepoCompliance{
+		{Repo: "api", HasRules: true, Rules: 3, PRs: 2, Violating: 1, ViolationRate: 0.5},
+		{Repo: "docs"},
+		{Repo: "infra", Error: "repository is empty"},
+		{Repo: "web", HasRules: true, Rules: 1, PRs: 1, Violating: 1, ViolationRate: 1},
+	}
+	if len(c.Repos) != len(wantRepos) {
+		t.Fatalf("Repos = %+v, want %d", c.Repos, len(wantRepos))
+	}
+	for i, want := range wantRepos {
+		if c.Repos[i] != want {
+			t.Errorf("Repos[%d] = %+v, want %+v", i, c.Repos[i], want)
+		}
+	}
+
+	if len(c.Rules) != 3 {
+		t.Fatalf("Rules = %+v, want 3", c.Rules)
+	}
+	if r := c.Rules[0]; r.Rule != "Wrap errors with context" || len(r.Repos) != 2 || r.PRs != 2 || r.Of != 3 {
+		t.Errorf("Rules[0] = %+v, want wrap errors in 2 of 3 PRs of both repos", r)
+	}
+	if r := c.Rules[1]; r.Rule != "Never log secrets" || r.PRs != 0 || r.Of != 2 || r.ViolationRate != 0 {
+		t.Errorf("Rules[1] = %+v, want never log secrets unviolated since %s", r, since)
+	}
+}
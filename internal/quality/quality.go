@@ -0,0 +1,258 @@
+// Package quality opens a weekly GitHub issue per repository reporting how
+// its review rules are doing: findings per week, the most violated rules,
+// rules that never fire and may be stale, and gaps in .prmate.md. Reports
+// are built from the persistent review store.
+package quality
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+// weeks is how far back a report looks
+const weeks = 4
+
+// maxRules caps the most violated rules in a report
+const maxRules = 10
+
+// Report is the content of one quality report issue
+type Report struct {
+	Owner    string
+	Repo     string
+	Until    time.Time
+	Weeks    []Week // oldest first
+	TopRules []RuleCount
+	Noisy    []store.RuleAdvice // rules violated in almost every pull request
+	Stale    []string           // .prmate.md rules without findings in any week
+	Gaps     []string
+}
+
+// Week is the review activity of one week
+type Week struct {
+	Start      time.Time
+	Reviews    int
+	Failed     int
+	Violations int
+}
+
+// RuleCount is how often a rule was violated over the whole report and in
+// its last week
+type RuleCount struct {
+	Rule     string
+	Total    int
+	LastWeek int
+}
+
+// Build reports on the records of owner/repo that finished in the weeks
+// before until. rules is the repository's parsed .prmate.md, or nil when
+// it could not be read; rulesErr then says why.
+func Build(owner, repo string, records []store.ReviewRecord, rules *review.Rules, rulesErr error, until time.Time) Report {
+	r := Report{Owner: owner, Repo: repo, Until: until}
+	since := until.AddDate(0, 0, -7*weeks)
+	for i := range weeks {
+		r.Weeks = append(r.Weeks, Week{Start: since.AddDate(0, 0, 7*i)})
+	}
+	lastWeek := until.AddDate(0, 0, -7)
+
+	totals := make(map[string]*RuleCount)
+	reviews := 0
+	var window []store.ReviewRecord
+	for _, rec := range records {
+		if rec.Owner != owner || rec.Repo != repo || rec.FinishedAt.Before(since) || !rec.FinishedAt.Before(until) {
+			continue
+		}
+		window = append(window, rec)
+		reviews++
+		w := &r.Weeks[int(rec.FinishedAt.Sub(since)/(7*24*time.Hour))]
+		w.Reviews++
+		if rec.Error != "" {
+			w.Failed++
+			continue
+		}
+		w.Violations += rec.ViolationsFound
+		for rule, n := range rec.RuleHits {
+			c, ok := totals[rule]
+			if !ok {
+				c = &RuleCount{Rule: rule}
+				totals[rule] = c
+			}
+			c.Total += n
+			if !rec.FinishedAt.Before(lastWeek) {
+				c.LastWeek += n
+			}
+		}
+	}
+
+	for _, c := range totals {
+		r.TopRules = append(r.TopRules, *c)
+	}
+	sort.Slice(r.TopRules, func(i, j int) bool {
+		if r.TopRules[i].Total != r.TopRules[j].Total {
+			return r.TopRules[i].Total > r.TopRules[j].Total
+		}
+		return r.TopRules[i].Rule < r.TopRules[j].Rule
+	})
+	if len(r.TopRules) > maxRules {
+		r.TopRules = r.TopRules[:maxRules]
+	}
+	r.Noisy = store.NoisyRules(window)
+
+	if rules == nil {
+		r.Gaps = append(r.Gaps, fmt.Sprintf("`.prmate.md` could not be read from the default branch (%v), so pull requests are not reviewed.", rulesErr))
+		return r
+	}
+	r.Gaps = gaps(*rules)
+	// Without reviews there is nothing to call a rule stale against
+	if reviews > 0 {
+		r.Stale = stale(*rules, totals)
+	}
+	return r
+}
+
+// gaps lists the parts of .prmate.md that give reviews nothing to work with
+func gaps(rules review.Rules) []string {
+	var out []string
+	if len(rules.Rules) == 0 {
+		out = append(out, "No learned rules: add a section with \"Rules\" or \"Conventions\" in its title.")
+	}
+	if len(rules.Checklist) == 0 {
+		out = append(out, "No review checklist: add a \"Review Checklist\" section of `- [ ]` items.")
+	}
+	if rules.Checks == nil || len(rules.Checks.Checks) == 0 {
+		out = append(out, "No deterministic checks: a `prmate-checks` block catches known patterns without the LLM.")
+	}
+	if strings.TrimSpace(rules.CodebaseInfo) == "" {
+		out = append(out, "No codebase notes: sections on structure, naming or error handling give reviews context.")
+	}
+	return out
+}
+
+// StaleRules returns the rules configured in rules that none of the
+// successful reviews in records attributed a finding to
+func StaleRules(rules review.Rules, records []store.ReviewRecord) []string {
+	hits := make(map[string]*RuleCount)
+	for _, rec := range records {
+		if rec.Error != "" {
+			continue
+		}
+		for rule, n := range rec.RuleHits {
+			if n > 0 {
+				hits[rule] = &RuleCount{Rule: rule, Total: n}
+			}
+		}
+	}
+	return stale(rules, hits)
+}
+
+// stale returns the configured rules no finding was attributed to
+func stale(rules review.Rules, hits map[string]*RuleCount) []string {
+	var out []string
+	for _, rule := range configured(rules) {
+		name := strings.ToLower(rule)
+		hit := false
+		for h := range hits {
+			if matchesHit(name, strings.ToLower(h)) {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			out = append(out, rule)
+		}
+	}
+	return out
+}
+
+// configured returns the checks, rules and checklist items of rules
+func configured(rules review.Rules) []string {
+	var out []string
+	if rules.Checks != nil {
+		for _, c := range rules.Checks.Checks {
+			out = append(out, c.ID)
+		}
+	}
+	out = append(out, rules.Rules...)
+	return append(out, rules.Checklist...)
+}
+
+// matchesHit reports whether a finding attributed to hit is one of rule,
+// both lowercased. LLM findings name rules loosely, so a rule counts as
+// hit when either name contains the other.
+func matchesHit(rule, hit string) bool {
+	return strings.Contains(hit, rule) || strings.Contains(rule, hit)
+}
+
+// Render returns the issue title and Markdown body for r
+func Render(r Report) (title, body string) {
+	title = fmt.Sprintf("PRMate quality report for %s/%s, week of %s", r.Owner, r.Repo, r.Until.AddDate(0, 0, -7).Format("2006-01-02"))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Review activity in %s/%s over the %d weeks to %s (UTC).\n\n", r.Owner, r.Repo, weeks, r.Until.Format("2006-01-02"))
+
+	b.WriteString("## Findings per week\n\n")
+	b.WriteString("| Week of | Reviews | Failed | Findings | Per review |\n|---|---:|---:|---:|---:|\n")
+	for _, w := range r.Weeks {
+		perReview := "–"
+		if ok := w.Reviews - w.Failed; ok > 0 {
+			perReview = fmt.Sprintf("%.1f", float64(w.Violations)/float64(ok))
+		}
+		fmt.Fprintf(&b, "| %s | %d | %d | %d | %s |\n", w.Start.Format("2006-01-02"), w.Reviews, w.Failed, w.Violations, perReview)
+	}
+
+	b.WriteString("\n## Most violated rules\n\n")
+	if len(r.TopRules) == 0 {
+		b.WriteString("No findings.\n")
+	} else {
+		b.WriteString("| Rule | Last week | 4 weeks |\n|---|---:|---:|\n")
+		for _, c := range r.TopRules {
+			fmt.Fprintf(&b, "| %s | %d | %d |\n", escapeCell(c.Rule), c.LastWeek, c.Total)
+		}
+	}
+
+	if len(r.Noisy) > 0 {
+		b.WriteString("\n## Noisy rules\n\n")
+		fmt.Fprintf(&b, "These rules were violated in more than %.0f%% of the reviewed pull requests. A rule almost every change breaks is usually too broad, too strictly worded, or no longer the team's practice. Narrow it with `paths` or `exclude`, reword it, or remove it from `.prmate.md`:\n\n", store.NoisyShare*100)
+		for _, n := range r.Noisy {
+			fmt.Fprintf(&b, "- %s: %d of %d pull requests\n", n.Rule, n.PRs, n.Of)
+		}
+	}
+
+	if len(r.Stale) > 0 {
+		b.WriteString("\n## Possibly stale rules\n\n")
+		b.WriteString("These `.prmate.md` rules had no findings in any review. They may be obsolete, already followed everywhere, or worded too vaguely to apply:\n\n")
+		for _, s := range r.Stale {
+			fmt.Fprintf(&b, "- %s\n", s)
+		}
+	}
+
+	if len(r.Gaps) > 0 {
+		b.WriteString("\n## Coverage gaps\n\n")
+		for _, g := range r.Gaps {
+			fmt.Fprintf(&b, "- %s\n", g)
+		}
+	}
+
+	return title, b.String()
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// covers reports whether owner/repo matches one of patterns (owner/repo,
+// owner/* or any path.Match glob), case-insensitively
+func covers(patterns []string, owner, repo string) bool {
+	name := strings.ToLower(owner + "/" + repo)
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToLower(p), name); ok {
+			return true
+		}
+	}
+	return false
+}
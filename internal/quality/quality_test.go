@@ -0,0 +1,151 @@
+package quality
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+// until is a Monday; the report covers the four weeks from 2026-09-14
+var until = time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC)
+
+const prmateMD = "# Rules\n- Wrap errors with context\n- Never log secrets\n\n## Review Checklist\n- [ ] Tests updated\n"
+
+func testRecords() []store.ReviewRecord {
+	return []store.ReviewRecord{
+		{Instance: "github", Owner: "acme", Repo: "api", FinishedAt: until.AddDate(0, 0, -25), ViolationsFound: 3, RuleHits: map[string]int{"wrap errors with context": 3}},
+		{Instance: "github", Owner: "acme", Repo: "api", FinishedAt: until.AddDate(0, 0, -10), ViolationsFound: 1, RuleHits: map[string]int{"Wrap errors": 1}},
+		{Instance: "github", Owner: "acme", Repo: "api", FinishedAt: until.AddDate(0, 0, -2), ViolationsFound: 2, RuleHits: map[string]int{"Wrap errors with context": 1, "tests updated": 1}},
+		{Instance: "github", Owner: "acme", Repo: "api", FinishedAt: until.AddDate(0, 0, -1), Error: "llm unavailable"},
+		{Instance: "github", Owner: "acme", Repo: "web", FinishedAt: until.AddDate(0, 0, -3), ViolationsFound: 4},
+		{Instance: "github", Owner: "acme", Repo: "api", FinishedAt: until.AddDate(0, 0, -40), ViolationsFound: 9},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	rules, err := review.ParseRules(prmateMD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Build("acme", "api", testRecords(), &rules, nil, until)
+
+	wantWeeks := []Week{
+		{Start: until.AddDate(0, 0, -28), Reviews: 1, Violations: 3},
+		{Start: until.AddDate(0, 0, -21)},
+		{Start: until.AddDate(0, 0, -14), Reviews: 1, Violations: 1},
+		{Start: until.AddDate(0, 0, -7), Reviews: 2, Failed: 1, Violations: 2},
+	}
+	for i, w := range wantWeeks {
+		if r.Weeks[i] != w {
+			t.Errorf("Weeks[%d] = %+v, want %+v", i, r.Weeks[i], w)
+		}
+	}
+	if len(r.TopRules) != 4 || r.TopRules[0] != (RuleCount{Rule: "wrap errors with context", Total: 3}) {
+		t.Errorf("TopRules = %+v", r.TopRules)
+	}
+	if len(r.Stale) != 1 || r.Stale[0] != "Never log secrets" {
+		t.Errorf("Stale = %v, want only the secrets rule", r.Stale)
+	}
+	// Rules and checklist exist; checks and codebase notes do not
+	if len(r.Gaps) != 2 {
+		t.Errorf("Gaps = %v, want 2", r.Gaps)
+	}
+}
+
+func TestBuild_MissingPRMateFile(t *testing.T) {
+	r := Build("acme", "api", testRecords(), nil, errors.New("404 Not Found"), until)
+	if len(r.Stale) != 0 || len(r.Gaps) != 1 || !strings.Contains(r.Gaps[0], "404 Not Found") {
+		t.Errorf("Stale = %v, Gaps = %v", r.Stale, r.Gaps)
+	}
+}
+
+func TestBuild_NoisyRules(t *testing.T) {
+	rules, _ := review.ParseRules(prmateMD)
+	var records []store.ReviewRecord
+	for pr := 1; pr <= 5; pr++ {
+		records = append(records, store.ReviewRecord{Owner: "acme", Repo: "api", PRNumber: pr, FinishedAt: until.AddDate(0, 0, -pr),
+			RuleHits: map[string]int{"Wrap errors with context": 1}})
+	}
+
+	r := Build("acme", "api", records, &rules, nil, until)
+	if len(r.Noisy) != 1 || r.Noisy[0].Rule != "Wrap errors with context" || r.Noisy[0].PRs != 5 || r.Noisy[0].Of != 5 {
+		t.Fatalf("Noisy = %+v", r.Noisy)
+	}
+	_, body := Render(r)
+	for _, want := range []string{"## Noisy rules", "more than 80% of the reviewed pull requests", "- Wrap errors with context: 5 of 5 pull requests"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestStaleRules(t *testing.T) {
+	rules, _ := review.ParseRules(prmateMD)
+	got := StaleRules(rules, testRecords())
+	if strings.Join(got, ",") != "Never log secrets" {
+		t.Errorf("StaleRules() = %v, want only the secrets rule", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	rules, _ := review.ParseRules(prmateMD)
+	title, body := Render(Build("acme", "api", testRecords(), &rules, nil, until))
+
+	if title != "PRMate quality report for acme/api, week of 2026-10-05" {
+		t.Errorf("title = %q", title)
+	}
+	for _, want := range []string{
+		"| 2026-10-05 | 2 | 1 | 2 | 2.0 |",
+		"| 2026-09-21 | 0 | 0 | 0 | – |",
+		"| wrap errors with context | 0 | 3 |",
+		"## Possibly stale rules",
+		"- Never log secrets",
+		"## Coverage gaps",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+}
+
+type fakeLister struct{ records []store.ReviewRecord }
+
+func (f *fakeLister) ListReviews(ctx context.Context, flt store.Filter) ([]store.ReviewRecord, error) {
+	return f.records, nil
+}
+
+type fakeRepository struct {
+	issues []string // owner/repo: title
+	labels []string
+}
+
+func (f *fakeRepository) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	return prmateMD, nil
+}
+
+func (f *fakeRepository) CreateIssue(ctx context.Context, owner, repo, title, body string, labels []string) (string, error) {
+	f.issues = append(f.issues, owner+"/"+repo+": "+title)
+	f.labels = labels
+	return "", nil
+}
+
+func TestScheduler_Publish(t *testing.T) {
+	repo := &fakeRepository{}
+	s := NewScheduler(&fakeLister{records: testRecords()}, []string{"acme/*"}, 8)
+	s.AddInstance("github", repo)
+
+	if err := s.Publish(context.Background(), until); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if len(repo.issues) != 2 || !strings.HasPrefix(repo.issues[0], "acme/api: ") || !strings.HasPrefix(repo.issues[1], "acme/web: ") {
+		t.Errorf("issues = %v, want one each for acme/api and acme/web", repo.issues)
+	}
+	if len(repo.labels) != 1 || repo.labels[0] != Label {
+		t.Errorf("labels = %v", repo.labels)
+	}
+}
@@ -0,0 +1,130 @@
+package quality
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"prmate/internal/digest"
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+// publishTimeout bounds one round of reports
+const publishTimeout = 5 * time.Minute
+
+// Label marks quality report issues
+const Label = "prmate-report"
+
+// RecordLister reads the review history reports are built from
+type RecordLister interface {
+	ListReviews(ctx context.Context, f store.Filter) ([]store.ReviewRecord, error)
+}
+
+// Repository reads .prmate.md and opens issues on one SCM instance
+type Repository interface {
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
+	CreateIssue(ctx context.Context, owner, repo, title, body string, labels []string) (string, error)
+}
+
+// Scheduler opens a quality report issue in every covered repository that
+// was reviewed in the last weeks, each Monday
+type Scheduler struct {
+	records   RecordLister
+	repos     []string // owner/repo or owner/* entries
+	hour      int      // UTC hour reports are published at
+	instances map[string]Repository
+}
+
+// NewScheduler returns a scheduler reporting on repos at hour (UTC)
+func NewScheduler(records RecordLister, repos []string, hour int) *Scheduler {
+	return &Scheduler{records: records, repos: repos, hour: hour, instances: make(map[string]Repository)}
+}
+
+// AddInstance publishes reports for repositories reviewed on the named SCM
+// instance through repo
+func (s *Scheduler) AddInstance(name string, repo Repository) {
+	s.instances[name] = repo
+}
+
+// Start publishes reports every Monday until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		for {
+			due := digest.NextRun(time.Now(), "weekly", s.hour)
+			timer := time.NewTimer(time.Until(due))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+			if err := s.Publish(publishCtx, due); err != nil {
+				slog.Warn("failed to publish quality reports", "error", err)
+			}
+			cancel()
+		}
+	}()
+}
+
+// Publish opens a report issue for each covered repository reviewed in the
+// weeks before until
+func (s *Scheduler) Publish(ctx context.Context, until time.Time) error {
+	records, err := s.records.ListReviews(ctx, store.Filter{Since: until.AddDate(0, 0, -7*weeks)})
+	if err != nil {
+		return fmt.Errorf("list reviews: %w", err)
+	}
+
+	type target struct{ instance, owner, repo string }
+	seen := make(map[target]bool)
+	var targets []target
+	for _, r := range records {
+		t := target{r.Instance, r.Owner, r.Repo}
+		if !seen[t] && covers(s.repos, r.Owner, r.Repo) {
+			seen[t] = true
+			targets = append(targets, t)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].owner+"/"+targets[i].repo < targets[j].owner+"/"+targets[j].repo
+	})
+
+	var errs []error
+	for _, t := range targets {
+		client, ok := s.instances[t.instance]
+		if !ok {
+			slog.Warn("skipping quality report for unknown SCM instance", "instance", t.instance, "repo", t.owner+"/"+t.repo)
+			continue
+		}
+		if err := s.publish(ctx, client, t.owner, t.repo, records, until); err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", t.owner, t.repo, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Scheduler) publish(ctx context.Context, client Repository, owner, repo string, records []store.ReviewRecord, until time.Time) error {
+	var rules *review.Rules
+	content, rulesErr := client.GetFileContent(ctx, owner, repo, ".prmate.md", "")
+	if rulesErr == nil {
+		parsed, err := review.ParseRules(content)
+		if err != nil {
+			rulesErr = err
+		} else {
+			rules = &parsed
+		}
+	}
+
+	title, body := Render(Build(owner, repo, records, rules, rulesErr, until))
+	url, err := client.CreateIssue(ctx, owner, repo, title, body, []string{Label})
+	if err != nil {
+		return err
+	}
+	slog.Info("published quality report", "repo", owner+"/"+repo, "url", url)
+	return nil
+}
@@ -0,0 +1,81 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Branding controls the wording and emoji of the comments PRMate posts,
+// so organizations can brand the bot or keep it plain. The hidden
+// prmate markers are not affected.
+type Branding struct {
+	Emoji         bool              // decorate file statuses in the summary
+	SeverityEmoji map[string]string // severity -> emoji before inline comments
+	CommentPrefix string            // prepended to every inline comment
+	ReviewHeader  string            // review body; {count} is the number of findings
+	SummaryHeader string            // heading of the summary comment
+	Footer        string            // summary footer; {version} is the PRMate version, empty drops it
+}
+
+// DefaultBranding returns PRMate's own wording, with or without emoji
+func DefaultBranding(emoji bool) Branding {
+	if !emoji {
+		return Branding{
+			SeverityEmoji: map[string]string{},
+			ReviewHeader:  "**PRMate Review** - Found {count} issue(s) to address.",
+			SummaryHeader: "PRMate Review Summary",
+			Footer:        "Reviewed by PRMate {version}",
+		}
+	}
+	return Branding{
+		Emoji:         true,
+		SeverityEmoji: map[string]string{"error": "❌", "warning": "⚠️", "suggestion": "💡"},
+		ReviewHeader:  "🔍 **PRMate Review** - Found {count} issue(s) to address.",
+		SummaryHeader: "📊 PRMate Review Summary",
+		Footer:        "Reviewed by PRMate {version}",
+	}
+}
+
+// inlineComment formats a finding as an inline review comment
+func (b Branding) inlineComment(v FileViolation) string {
+	emoji, ok := b.SeverityEmoji[v.Severity]
+	if !ok {
+		emoji = b.SeverityEmoji["warning"]
+	}
+	parts := make([]string, 0, 3)
+	for _, p := range []string{b.CommentPrefix, emoji, fmt.Sprintf("**%s**: %s", v.Rule, v.Message)} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	// After the first line, which follow-ups parse the finding from
+	body := strings.Join(parts, " ")
+	if v.Suggestion != "" {
+		body += "\n\n```suggestion\n" + v.Suggestion + "\n```"
+	}
+	if v.Why != "" {
+		body += "\n\n" + v.Why
+	}
+	return body
+}
+
+func (b Branding) reviewBody(count int) string {
+	return strings.ReplaceAll(b.ReviewHeader, "{count}", fmt.Sprint(count))
+}
+
+// fileStatus describes a reviewed file in the summary comment
+func (b Branding) fileStatus(violations int) string {
+	switch {
+	case violations == 0 && b.Emoji:
+		return "✅"
+	case violations == 0:
+		return "no issues"
+	case b.Emoji:
+		return fmt.Sprintf("⚠️ %d issue(s)", violations)
+	}
+	return fmt.Sprintf("%d issue(s)", violations)
+}
+
+func (b Branding) footer(version string) string {
+	return strings.ReplaceAll(b.Footer, "{version}", version)
+}
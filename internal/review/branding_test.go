@@ -0,0 +1,87 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestBranding_Comments(t *testing.T) {
+	violations := []FileViolation{
+		{Path: "main.go", Line: 3, Rule: "wrap-errors", Message: "wrap the error", Severity: "error"},
+		{Path: "main.go", Line: 9, Rule: "naming", Message: "rename", Severity: "suggestion"},
+	}
+	summary := ReviewSummary{
+		HeadSHA:         "abc123def456",
+		FilesScanned:    []FileReviewStatus{{Path: "main.go", Violations: 2}, {Path: "util.go"}},
+		ViolationsFound: 2,
+	}
+
+	plain := DefaultBranding(false)
+	plain.CommentPrefix = "[Acme]"
+	plain.SeverityEmoji["error"] = "(!)"
+	plain.Footer = ""
+
+	tests := []struct {
+		name        string
+		branding    *Branding
+		wantInline  []string
+		wantReview  string
+		wantSummary []string
+		notSummary  []string
+	}{
+		{
+			name:        "default",
+			wantInline:  []string{"❌ **wrap-errors**: wrap the error", "💡 **naming**: rename"},
+			wantReview:  "🔍 **PRMate Review** - Found 2 issue(s) to address.",
+			wantSummary: []string{"## 📊 PRMate Review Summary", "`main.go` ⚠️ 2 issue(s)", "`util.go` ✅", "<sub>Reviewed by PRMate "},
+		},
+		{
+			name:        "plain and branded",
+			branding:    &plain,
+			wantInline:  []string{"[Acme] (!) **wrap-errors**: wrap the error", "[Acme] **naming**: rename"},
+			wantReview:  "**PRMate Review** - Found 2 issue(s) to address.",
+			wantSummary: []string{"## PRMate Review Summary\n", "`main.go` 2 issue(s)", "`util.go` no issues", "<!-- prmate-data:"},
+			notSummary:  []string{"<sub>", "✅"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gh := &mockGitHubClient{}
+			svc := NewService(gh, &mockLLMProvider{}, Config{Branding: tt.branding})
+			req := ReviewRequest{Owner: "acme", Repo: "api", PRNumber: 1, HeadSHA: summary.HeadSHA}
+
+			if _, err := svc.postReviewComments(context.Background(), req, violations, nil); err != nil {
+				t.Fatalf("postReviewComments() error = %v", err)
+			}
+			posted := gh.postedReviews[0]
+			if posted.body != tt.wantReview {
+				t.Errorf("review body = %q, want %q", posted.body, tt.wantReview)
+			}
+			for i, want := range tt.wantInline {
+				if posted.comments[i].Body != want {
+					t.Errorf("comment %d = %q, want %q", i, posted.comments[i].Body, want)
+				}
+			}
+
+			if err := svc.postSummary(context.Background(), req, summary, nil); err != nil {
+				t.Fatalf("postSummary() error = %v", err)
+			}
+			body := gh.postedComments[0]
+			for _, want := range tt.wantSummary {
+				if !strings.Contains(body, want) {
+					t.Errorf("summary missing %q:\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tt.notSummary {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("summary contains %q:\n%s", unwanted, body)
+				}
+			}
+			if _, err := parseSummaryFromComment(body); err != nil {
+				t.Errorf("parseSummaryFromComment() error = %v", err)
+			}
+		})
+	}
+}
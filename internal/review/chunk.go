@@ -0,0 +1,129 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+
+	ghclient "prmate/internal/github"
+)
+
+const (
+	// maxChunkLines bounds the diff lines of a large change analyzed in
+	// one LLM call
+	maxChunkLines = 150
+	// chunkOverlap is how many diff lines a piece of a hunk too long for
+	// one chunk repeats from the end of the previous piece, so code split
+	// between chunks is seen whole in one of them
+	chunkOverlap = 10
+	// chunkContextLines is how many lines of the file above and below its
+	// changes are sent with a chunk
+	chunkContextLines = 30
+)
+
+// diffChunk is part of a large patch, analyzed in one LLM call
+type diffChunk struct {
+	patch string
+	// first and last are the new-file lines the chunk spans
+	first, last int
+}
+
+// chunkPatch splits patch into chunks of whole hunks of at most
+// maxChunkLines diff lines together. A longer hunk is cut into overlapping
+// pieces, each with a header of its own so its line numbers stay right.
+func chunkPatch(patch string) []diffChunk {
+	var pieces []diffChunk
+	for _, hunk := range ghclient.ParsePatch(patch) {
+		pieces = append(pieces, splitHunk(hunk)...)
+	}
+
+	var chunks []diffChunk
+	lines := 0
+	for _, p := range pieces {
+		n := strings.Count(p.patch, "\n") + 1
+		if len(chunks) > 0 && lines+n <= maxChunkLines {
+			c := &chunks[len(chunks)-1]
+			c.patch += "\n" + p.patch
+			c.last = max(c.last, p.last)
+			lines += n
+			continue
+		}
+		chunks = append(chunks, p)
+		lines = n
+	}
+	return chunks
+}
+
+// splitHunk renders hunk as pieces of at most maxChunkLines diff lines,
+// consecutive pieces sharing chunkOverlap lines
+func splitHunk(hunk ghclient.PatchHunk) []diffChunk {
+	// The old and new line each diff line starts at
+	olds := make([]int, len(hunk.Lines))
+	news := make([]int, len(hunk.Lines))
+	oldLine, newLine := hunk.OldStart, hunk.NewStart
+	for i, l := range hunk.Lines {
+		olds[i], news[i] = oldLine, newLine
+		switch l.Type {
+		case "add":
+			newLine++
+		case "remove":
+			oldLine++
+		default:
+			oldLine++
+			newLine++
+		}
+	}
+
+	size := maxChunkLines - 1 // a line for the header
+	var pieces []diffChunk
+	for start := 0; start < len(hunk.Lines); start += size - chunkOverlap {
+		end := min(start+size, len(hunk.Lines))
+		oldCount, newCount := 0, 0
+		body := make([]string, 0, end-start)
+		for _, l := range hunk.Lines[start:end] {
+			switch l.Type {
+			case "add":
+				newCount++
+			case "remove":
+				oldCount++
+			default:
+				oldCount++
+				newCount++
+			}
+			body = append(body, l.Content)
+		}
+		header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", olds[start], oldCount, news[start], newCount)
+		pieces = append(pieces, diffChunk{
+			patch: header + "\n" + strings.Join(body, "\n"),
+			first: news[start],
+			last:  news[start] + max(newCount-1, 0),
+		})
+		if end == len(hunk.Lines) {
+			break
+		}
+	}
+	return pieces
+}
+
+// excerpt returns the lines of content the chunk spans, with
+// chunkContextLines more on either side, noting the lines left out
+func (c diffChunk) excerpt(content string) string {
+	if content == "" {
+		return ""
+	}
+	lines := strings.Split(content, "\n")
+	from := max(c.first-chunkContextLines, 1)
+	to := min(c.last+chunkContextLines, len(lines))
+	if from > to {
+		return ""
+	}
+
+	var sb strings.Builder
+	if from > 1 {
+		sb.WriteString(fmt.Sprintf("... (lines 1-%d omitted)\n", from-1))
+	}
+	sb.WriteString(strings.Join(lines[from-1:to], "\n"))
+	if to < len(lines) {
+		sb.WriteString(fmt.Sprintf("\n... (lines %d-%d omitted)", to+1, len(lines)))
+	}
+	return sb.String()
+}
@@ -0,0 +1,115 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+// addedPatch returns a hunk adding n lines from line start of a new file
+func addedPatch(start, n int) string {
+	lines := []string{fmt.Sprintf("@@ -%d,0 +%d,%d @@", start-1, start, n)}
+	for i := range n {
+		lines = append(lines, fmt.Sprintf("+line %d", start+i))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestChunkPatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		patch     string
+		wantSpans [][2]int // first and last new line of each chunk
+	}{
+		{name: "small patch", patch: addedPatch(1, 20), wantSpans: [][2]int{{1, 20}}},
+		{
+			name:      "hunks grouped",
+			patch:     addedPatch(1, 50) + "\n" + addedPatch(100, 50) + "\n" + addedPatch(200, 50),
+			wantSpans: [][2]int{{1, 149}, {200, 249}},
+		},
+		{
+			name:      "long hunk cut with overlap",
+			patch:     addedPatch(1, 300),
+			wantSpans: [][2]int{{1, 149}, {140, 288}, {279, 300}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := chunkPatch(tt.patch)
+			if len(chunks) != len(tt.wantSpans) {
+				t.Fatalf("chunks = %d, want %d", len(chunks), len(tt.wantSpans))
+			}
+			covered := make(map[int]bool)
+			for i, c := range chunks {
+				if c.first != tt.wantSpans[i][0] || c.last != tt.wantSpans[i][1] {
+					t.Errorf("chunk %d spans %d-%d, want %d-%d", i, c.first, c.last, tt.wantSpans[i][0], tt.wantSpans[i][1])
+				}
+				if n := strings.Count(c.patch, "\n") + 1; n > maxChunkLines {
+					t.Errorf("chunk %d has %d diff lines, want at most %d", i, n, maxChunkLines)
+				}
+				// Every added line keeps its line number and its content
+				for _, h := range ghclient.ParsePatch(c.patch) {
+					for _, l := range h.Lines {
+						if l.Content != fmt.Sprintf("+line %d", l.NewLineNo) {
+							t.Errorf("chunk %d: line %d is %q", i, l.NewLineNo, l.Content)
+						}
+						covered[l.NewLineNo] = true
+					}
+				}
+			}
+			if want := len(ghclient.GetNewLineNumbers(tt.patch)); len(covered) != want {
+				t.Errorf("chunks cover %d added lines, want %d", len(covered), want)
+			}
+		})
+	}
+}
+
+func TestDiffChunk_Excerpt(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 200; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	content := strings.Join(lines, "\n")
+
+	got := diffChunk{first: 100, last: 110}.excerpt(content)
+	if !strings.HasPrefix(got, "... (lines 1-69 omitted)\nline 70\n") || !strings.HasSuffix(got, "line 140\n... (lines 141-200 omitted)") {
+		t.Errorf("excerpt() = %q", got)
+	}
+	if got := (diffChunk{first: 1, last: 10}).excerpt(content); !strings.HasPrefix(got, "line 1\n") {
+		t.Errorf("excerpt() at the start = %q", got[:20])
+	}
+}
+
+func TestReviewPR_ChunksLargeChanges(t *testing.T) {
+	var content []string
+	for i := 1; i <= 600; i++ {
+		content = append(content, fmt.Sprintf("line %d", i))
+	}
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+			"big.go":     strings.Join(content, "\n"),
+		},
+		prFiles: []ghclient.PRFile{{Filename: "big.go", Status: "added", Additions: 600, Patch: addedPatch(1, 600)}},
+	}
+	// Line 145 is in the overlap of the first two chunks
+	llmMock := &mockLLMProvider{response: `{"violations": [{"line": 145, "rule": "Error Handling", "message": "Wrap the error", "severity": "warning"}]}`}
+	svc := NewService(ghMock, llmMock, Config{})
+
+	result, err := svc.ReviewPR(context.Background(), ReviewRequest{Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(llmMock.prompts) != len(chunkPatch(addedPatch(1, 600))) || len(llmMock.prompts) < 4 {
+		t.Errorf("LLM calls = %d, want one per chunk", len(llmMock.prompts))
+	}
+	if result.ViolationsFound != 1 {
+		t.Errorf("violations = %d, want the finding of both chunks once", result.ViolationsFound)
+	}
+	if !strings.Contains(llmMock.prompts[1], "... (lines 1-109 omitted)\nline 110\n") {
+		t.Errorf("second prompt lacks the file around its chunk")
+	}
+}
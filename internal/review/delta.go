@@ -0,0 +1,122 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+
+	ghclient "prmate/internal/github"
+)
+
+const (
+	// maxTrackedFindings bounds the findings a summary carries for the next
+	// review to compare against
+	maxTrackedFindings = 100
+	// maxDeltaRows bounds the table of new and fixed findings in a summary
+	maxDeltaRows = 20
+	// deltaVersion is the first summary version carrying every finding;
+	// older summaries cannot be compared against
+	deltaVersion = "1.1"
+)
+
+// Delta counts how the findings of a PR changed since its previous review
+type Delta struct {
+	BaseSHA    string `json:"base_sha"` // head commit of the previous review
+	New        int    `json:"new"`
+	Fixed      int    `json:"fixed"`
+	Persisting int    `json:"persisting"`
+
+	added, fixed []OpenFinding // shown in the summary, not stored
+}
+
+// trackFindings returns every finding of the PR as of this review: the
+// previous review's findings in files that were neither reviewed again nor
+// dropped from the PR, and this review's findings. Messages are shortened
+// to keep the summary data small.
+func trackFindings(previous *ReviewSummary, files []ghclient.PRFile, reviewed []FileReviewStatus, violations []FileViolation) []OpenFinding {
+	var earlier []OpenFinding
+	if previous != nil {
+		earlier = previous.Findings
+	}
+	tracked := carryFindings(earlier, files, reviewed, violations, func(FileViolation) bool { return true })
+	for i := range tracked {
+		tracked[i].Message = truncate(tracked[i].Message, 120)
+	}
+	if len(tracked) > maxTrackedFindings {
+		tracked = tracked[:maxTrackedFindings]
+	}
+	return tracked
+}
+
+// diffFindings compares the findings of the previous review with those of
+// the current one. Findings match by path and rule, like review threads,
+// since lines move and the LLM words the same finding differently from one
+// push to the next.
+func diffFindings(previous *ReviewSummary, current []OpenFinding) *Delta {
+	delta := &Delta{BaseSHA: previous.HeadSHA}
+	type key struct{ path, rule string }
+	remaining := make(map[key]int, len(previous.Findings))
+	for _, f := range previous.Findings {
+		remaining[key{f.Path, f.Rule}]++
+	}
+	for _, f := range current {
+		k := key{f.Path, f.Rule}
+		if remaining[k] > 0 {
+			remaining[k]--
+			delta.Persisting++
+			continue
+		}
+		delta.added = append(delta.added, f)
+	}
+	for _, f := range previous.Findings {
+		k := key{f.Path, f.Rule}
+		if remaining[k] > 0 {
+			remaining[k]--
+			delta.fixed = append(delta.fixed, f)
+		}
+	}
+	delta.New, delta.Fixed = len(delta.added), len(delta.fixed)
+	return delta
+}
+
+// tracksFindings reports whether a summary carries the findings a delta is
+// computed from
+func (s *ReviewSummary) tracksFindings() bool {
+	return s != nil && s.Version >= deltaVersion
+}
+
+// writeDelta adds what changed since the previous review to a summary
+func writeDelta(sb *strings.Builder, delta Delta) {
+	sb.WriteString("\n### Since Last Review\n\n")
+	sb.WriteString(fmt.Sprintf("%d new, %d fixed, %d persisting since `%s`\n", delta.New, delta.Fixed, delta.Persisting, shortSHA(delta.BaseSHA)))
+	if delta.New+delta.Fixed == 0 {
+		return
+	}
+
+	sb.WriteString("\n| | Finding | Where |\n|---|---------|-------|\n")
+	rows := 0
+	for _, group := range []struct {
+		label    string
+		findings []OpenFinding
+	}{{"New", delta.added}, {"Fixed", delta.fixed}} {
+		for _, f := range group.findings {
+			if rows == maxDeltaRows {
+				sb.WriteString(fmt.Sprintf("\n…and %d more.\n", delta.New+delta.Fixed-maxDeltaRows))
+				return
+			}
+			sb.WriteString(fmt.Sprintf("| %s | **%s**: %s | %s |\n", group.label, tableCell(f.Rule), tableCell(truncate(f.Message, 120)), location(f.Path, f.Line)))
+			rows++
+		}
+	}
+}
+
+// location tells where a finding is in summary tables. Findings about the
+// whole pull request, such as a failed smoke check, have no path.
+func location(path string, line int) string {
+	switch {
+	case path == "":
+		return "—"
+	case line == 0:
+		return fmt.Sprintf("`%s`", path)
+	}
+	return fmt.Sprintf("`%s:%d`", path, line)
+}
@@ -0,0 +1,107 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+func TestDiffFindings(t *testing.T) {
+	previous := &ReviewSummary{HeadSHA: "prev123", Findings: []OpenFinding{
+		{Path: "a.go", Line: 3, Rule: "Error Handling"},
+		{Path: "a.go", Line: 9, Rule: "Error Handling"},
+		{Path: "b.go", Line: 1, Rule: "Naming"},
+	}}
+	current := []OpenFinding{
+		{Path: "a.go", Line: 4, Rule: "Error Handling", Message: "worded differently"},
+		{Path: "c.go", Line: 2, Rule: "Naming"},
+	}
+
+	delta := diffFindings(previous, current)
+	if delta.BaseSHA != "prev123" || delta.New != 1 || delta.Fixed != 2 || delta.Persisting != 1 {
+		t.Errorf("diffFindings() = %+v, want 1 new, 2 fixed and 1 persisting since prev123", delta)
+	}
+	if len(delta.added) != 1 || delta.added[0].Path != "c.go" {
+		t.Errorf("added = %+v, want the finding in c.go", delta.added)
+	}
+	if len(delta.fixed) != 2 || delta.fixed[0].Line != 3 || delta.fixed[1].Path != "b.go" {
+		t.Errorf("fixed = %+v, want a.go:3 and b.go:1", delta.fixed)
+	}
+}
+
+func TestReviewPR_Delta(t *testing.T) {
+	previous, _ := json.Marshal(ReviewSummary{
+		Version:      deltaVersion,
+		HeadSHA:      "prev123",
+		FilesScanned: []FileReviewStatus{{Path: "util.go", LastSHA: "abc123def456789", Violations: 1}},
+		Findings: []OpenFinding{
+			{Path: "handler.go", Line: 4, Rule: "Error Handling", Severity: "error", Message: "Error not wrapped"},
+			{Path: "handler.go", Line: 8, Rule: "Naming", Severity: "warning", Message: "Use camelCase"},
+			{Path: "util.go", Line: 2, Rule: "Docs", Severity: "suggestion", Message: "Document this"},
+			{Path: "gone.go", Line: 1, Rule: "Naming", Severity: "warning", Message: "Use camelCase"},
+		},
+	})
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+		},
+		prFiles: []ghclient.PRFile{
+			{Filename: "handler.go", Status: "modified", Patch: "@@ -3,0 +4,2 @@\n+\treturn err\n+\tx := 1"},
+			{Filename: "util.go", Status: "modified"},
+		},
+		prComments: []string{summaryMarkerPrefix + "prev123" + summaryMarkerSuffix + "\n<!-- prmate-data:" + string(previous) + " -->"},
+	}
+	llmMock := &mockLLMProvider{response: `{"violations": [
+		{"line": 4, "rule": "Error Handling", "message": "Wrap the error", "severity": "error"},
+		{"line": 5, "rule": "Shadowing", "message": "x shadows a package name", "severity": "warning"}
+	]}`}
+
+	svc := NewService(ghMock, llmMock, Config{})
+	if _, err := svc.ReviewPR(context.Background(), ReviewRequest{
+		Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(ghMock.postedComments) != 0 || len(ghMock.updatedComments) != 1 {
+		t.Fatalf("expected the previous summary to be edited, got %d new and %d edited comments", len(ghMock.postedComments), len(ghMock.updatedComments))
+	}
+	body := ghMock.updatedComments[1]
+	// util.go is unchanged since it was reviewed and keeps its finding;
+	// gone.go left the PR
+	for _, want := range []string{
+		"### Since Last Review",
+		"1 new, 2 fixed, 2 persisting since `prev123`",
+		"| New | **Shadowing**: x shadows a package name | `handler.go:5` |",
+		"| Fixed | **Naming**: Use camelCase | `handler.go:8` |",
+		"| Fixed | **Naming**: Use camelCase | `gone.go:1` |",
+		`"delta":{"base_sha":"prev123","new":1,"fixed":2,"persisting":2}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("summary missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestReviewPR_NoDeltaWithoutComparableSummary(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+		},
+		prFiles:    []ghclient.PRFile{{Filename: "handler.go", Status: "modified", Patch: "@@ -3,0 +4 @@\n+\treturn err"}},
+		prComments: []string{summaryMarkerPrefix + "old" + summaryMarkerSuffix + "\n<!-- prmate-data:{\"version\":\"1.0\",\"head_sha\":\"old\"} -->"},
+	}
+
+	svc := NewService(ghMock, &mockLLMProvider{response: `{"violations": []}`}, Config{})
+	if _, err := svc.ReviewPR(context.Background(), ReviewRequest{
+		Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body := ghMock.updatedComments[1]; strings.Contains(body, "Since Last Review") {
+		t.Errorf("a summary from before findings were tracked should not be compared against:\n%s", body)
+	}
+}
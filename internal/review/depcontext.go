@@ -0,0 +1,123 @@
+package review
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// maxDependencyBytes bounds what one dependency adds to the prompt
+const maxDependencyBytes = 3000
+
+// referencedNames returns the identifiers a Go file uses, including the
+// names it selects from imported packages, or nil when the file is not Go
+// or cannot be parsed
+func referencedNames(filePath, content string) map[string]bool {
+	if getFileExtension(filePath) != ".go" {
+		return nil
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), filePath, content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	used := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			used[id.Name] = true
+		}
+		return true
+	})
+	return used
+}
+
+// dependencyExcerpt returns what the prompt shows of a dependency. For a Go
+// file whose names used are known, that is only the declarations of those
+// names, functions without their bodies, and ok is false when it declares
+// none of them. Other dependencies are shown from the start.
+func dependencyExcerpt(depPath, content string, used map[string]bool) (excerpt string, ok bool) {
+	if used != nil && getFileExtension(depPath) == ".go" {
+		if excerpt, ok, parsed := goDeclarations(depPath, content, used); parsed {
+			return excerpt, ok
+		}
+	}
+	if len(content) > maxDependencyBytes {
+		content = content[:maxDependencyBytes] + "\n// ... (truncated)"
+	}
+	return content, true
+}
+
+// goDeclarations slices the declarations of used out of a Go file, in
+// source order and with their doc comments. A const, var or type group is
+// kept whole, since its specs can depend on each other, as with iota.
+// parsed is false when the file is not valid Go.
+func goDeclarations(path, content string, used map[string]bool) (excerpt string, ok, parsed bool) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, content, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return "", false, false
+	}
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+
+	var decls []string
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT || !declaresUsed(d, used) {
+				continue
+			}
+			start := d.Pos()
+			if d.Doc != nil {
+				start = d.Doc.Pos()
+			}
+			decls = append(decls, content[offset(start):offset(d.End())])
+		case *ast.FuncDecl:
+			if !used[d.Name.Name] {
+				continue
+			}
+			start := d.Pos()
+			if d.Doc != nil {
+				start = d.Doc.Pos()
+			}
+			if d.Body == nil {
+				decls = append(decls, content[offset(start):offset(d.End())])
+				continue
+			}
+			decls = append(decls, strings.TrimSpace(content[offset(start):offset(d.Body.Lbrace)])+" { ... }")
+		}
+	}
+	if len(decls) == 0 {
+		return "", false, true
+	}
+
+	var sb strings.Builder
+	sb.WriteString("package " + f.Name.Name + "\n")
+	for i, d := range decls {
+		if sb.Len()+len(d) > maxDependencyBytes {
+			sb.WriteString(fmt.Sprintf("\n// ... (%d more declarations)", len(decls)-i))
+			break
+		}
+		sb.WriteString("\n" + d + "\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), true, true
+}
+
+// declaresUsed reports whether any spec of d declares a name in used
+func declaresUsed(d *ast.GenDecl, used map[string]bool) bool {
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			if used[s.Name.Name] {
+				return true
+			}
+		case *ast.ValueSpec:
+			for _, name := range s.Names {
+				if used[name.Name] {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
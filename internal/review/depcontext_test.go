@@ -0,0 +1,133 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const depStore = `package store
+
+import "context"
+
+// Store persists review records
+type Store interface {
+	Save(ctx context.Context, r Record) error
+}
+
+// Record is one finished review
+type Record struct {
+	ID string
+}
+
+type unrelated struct{}
+
+const (
+	KindA Kind = iota
+	KindB
+)
+
+// Open opens the store at path
+func Open(path string) (*FileStore, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return &FileStore{}, nil
+}
+
+func helper() {}
+`
+
+func TestDependencyExcerpt(t *testing.T) {
+	used := referencedNames("internal/handlers/h.go", `package handlers
+
+import "example.com/app/internal/store"
+
+func handle(s store.Store) {
+	f, _ := store.Open("x")
+	_ = store.KindB
+	_ = f
+}
+`)
+
+	got, ok := dependencyExcerpt("internal/store/store.go", depStore, used)
+	if !ok {
+		t.Fatal("dependencyExcerpt() ok = false")
+	}
+	want := `package store
+
+// Store persists review records
+type Store interface {
+	Save(ctx context.Context, r Record) error
+}
+
+const (
+	KindA Kind = iota
+	KindB
+)
+
+// Open opens the store at path
+func Open(path string) (*FileStore, error) { ... }`
+	if got != want {
+		t.Errorf("dependencyExcerpt() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, ok := dependencyExcerpt("internal/store/store.go", depStore, map[string]bool{"Other": true}); ok {
+		t.Error("a dependency declaring nothing the file uses should be left out")
+	}
+}
+
+func TestDependencyExcerpt_Fallback(t *testing.T) {
+	long := strings.Repeat("x", maxDependencyBytes+10)
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		used    map[string]bool
+	}{
+		{name: "not go", path: "src/util.ts", content: long, used: map[string]bool{"x": true}},
+		{name: "changed file not parsed", path: "internal/store/store.go", content: long},
+		{name: "invalid go", path: "internal/store/store.go", content: long, used: map[string]bool{"x": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := dependencyExcerpt(tt.path, tt.content, tt.used)
+			if !ok || got != long[:maxDependencyBytes]+"\n// ... (truncated)" {
+				t.Errorf("dependencyExcerpt() = %d bytes, %v; want the truncated file", len(got), ok)
+			}
+		})
+	}
+}
+
+func TestDependencyExcerpt_Budget(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("package big\n")
+	used := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		name := "Func" + strings.Repeat("x", i%7) + string(rune('A'+i%26)) + strings.Repeat("y", i/26)
+		used[name] = true
+		sb.WriteString("\n// " + name + " does something worth documenting at some length\nfunc " + name + "(a, b int) int { return a + b }\n")
+	}
+
+	got, ok := dependencyExcerpt("internal/big/big.go", sb.String(), used)
+	if !ok || len(got) > maxDependencyBytes+50 || !strings.HasSuffix(got, "more declarations)") {
+		t.Errorf("dependencyExcerpt() = %d bytes ending %q, want declarations cut at the budget", len(got), got[len(got)-30:])
+	}
+}
+
+func TestGatherDependencyContext(t *testing.T) {
+	ghMock := &mockGitHubClient{fileContents: map[string]string{
+		"internal/store.go":       depStore,
+		"internal/store/types.go": "package store\n\ntype Unused struct{}\n",
+	}}
+	svc := NewService(ghMock, &mockLLMProvider{}, Config{})
+
+	got := svc.gatherDependencyContext(context.Background(), ReviewRequest{}, "cmd/main.go",
+		"package main\n\nimport (\n\t\"example.com/app/internal/store\"\n)\n\nfunc main() { store.Open(\"db\") }\n")
+	if !strings.Contains(got, "### internal/store.go") || !strings.Contains(got, "func Open(path string) (*FileStore, error) { ... }") {
+		t.Errorf("context = %q, want the declaration main uses", got)
+	}
+	if strings.Contains(got, "internal/store/types.go") || strings.Contains(got, "return &FileStore") {
+		t.Errorf("context = %q, want neither unused files nor function bodies", got)
+	}
+}
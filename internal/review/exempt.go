@@ -0,0 +1,53 @@
+package review
+
+import (
+	"strings"
+
+	"prmate/internal/checks"
+)
+
+// Exemption suppresses the findings of one rule, in every file or in the
+// files matching Path
+type Exemption struct {
+	Rule string
+	Path string // glob; empty exempts every file
+}
+
+// covers reports whether e exempts v
+func (e Exemption) covers(v FileViolation) bool {
+	return strings.EqualFold(e.Rule, v.Rule) && (e.Path == "" || checks.Match(e.Path, v.Path))
+}
+
+// exempt drops the findings an exemption covers, uncounting them in the
+// statuses of their files, and returns the rest and how many were dropped
+func exempt(violations []FileViolation, statuses []FileReviewStatus, exemptions []Exemption) ([]FileViolation, int) {
+	if len(exemptions) == 0 {
+		return violations, 0
+	}
+	return drop(violations, statuses, func(v FileViolation) bool {
+		for _, e := range exemptions {
+			if e.covers(v) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// drop drops the findings covered reports, uncounting them in the statuses
+// of their files, and returns the rest and how many were dropped
+func drop(violations []FileViolation, statuses []FileReviewStatus, covered func(FileViolation) bool) ([]FileViolation, int) {
+	kept := violations[:0]
+	for _, v := range violations {
+		if !covered(v) {
+			kept = append(kept, v)
+			continue
+		}
+		for i := range statuses {
+			if statuses[i].Path == v.Path && statuses[i].Violations > 0 {
+				statuses[i].Violations--
+			}
+		}
+	}
+	return kept, len(violations) - len(kept)
+}
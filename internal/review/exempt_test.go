@@ -0,0 +1,61 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+func TestExempt(t *testing.T) {
+	violations := []FileViolation{
+		{Path: "legacy/old.go", Rule: "no-println"},
+		{Path: "legacy/old.go", Rule: "Error Handling"},
+		{Path: "api/new.go", Rule: "no-println"},
+		{Path: "api/new.go", Rule: "small-files"},
+	}
+	statuses := []FileReviewStatus{{Path: "legacy/old.go", Violations: 2}, {Path: "api/new.go", Violations: 2}}
+
+	kept, n := exempt(violations, statuses, []Exemption{
+		{Rule: "no-println", Path: "legacy/**"},
+		{Rule: "small-files"},
+	})
+
+	if n != 2 {
+		t.Errorf("exempted %d findings, want 2", n)
+	}
+	var got []string
+	for _, v := range kept {
+		got = append(got, v.Path+" "+v.Rule)
+	}
+	if want := "legacy/old.go Error Handling,api/new.go no-println"; strings.Join(got, ",") != want {
+		t.Errorf("kept %q, want %q", got, want)
+	}
+	if statuses[0].Violations != 1 || statuses[1].Violations != 1 {
+		t.Errorf("statuses = %+v, want one finding left in each file", statuses)
+	}
+}
+
+func TestReviewPR_Exemptions(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{".prmate.md": "## Learned Rules\n- Handlers log with slog\n"},
+		prFiles:      []ghclient.PRFile{{Filename: "main.go", Status: "modified", Additions: 1, Patch: "@@ -3,0 +4 @@\n+\tfmt.Println(x)"}},
+	}
+	llmMock := &mockLLMProvider{response: `{"violations": [{"line": 4, "rule": "Logging", "message": "Use slog", "severity": "error"}]}`}
+
+	result, err := NewService(ghMock, llmMock, Config{}).ReviewPR(context.Background(), ReviewRequest{
+		Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+		Exemptions: []Exemption{{Rule: "logging"}},
+	})
+	if err != nil {
+		t.Fatalf("ReviewPR() error = %v", err)
+	}
+
+	if result.ViolationsFound != 0 || len(ghMock.postedReviews) != 0 {
+		t.Errorf("exempted finding reported: %d found, %d reviews posted", result.ViolationsFound, len(ghMock.postedReviews))
+	}
+	if len(ghMock.postedComments) != 1 || !strings.Contains(ghMock.postedComments[0], "| Exempted | 1 |") {
+		t.Errorf("summary = %q, want the exempted finding counted", ghMock.postedComments)
+	}
+}
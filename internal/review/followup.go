@@ -0,0 +1,151 @@
+package review
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	ghclient "prmate/internal/github"
+)
+
+// maxOutstandingRows bounds the Outstanding Findings table in a summary
+const maxOutstandingRows = 25
+
+// FollowUp counts what became of the findings PRMate has posted on a PR
+type FollowUp struct {
+	Open     int `json:"open"`
+	Replied  int `json:"replied"` // open findings someone has answered
+	Resolved int `json:"resolved"`
+}
+
+// Outstanding is a PRMate finding nobody has resolved yet
+type Outstanding struct {
+	Path     string
+	Line     int
+	Rule     string
+	Message  string
+	Replies  int
+	Outdated bool // a later push changed the lines it is about
+	Thread   bool // false for findings with no review thread, such as file-level ones
+}
+
+// findingComment picks the rule and message out of an inline comment
+// written by Branding.inlineComment
+var findingComment = regexp.MustCompile(`\*\*(.+?)\*\*: (.*)`)
+
+// followUp matches the review threads on a PR against the findings PRMate
+// posted. Threads PRMate started and nobody resolved are outstanding, as are
+// open findings without a thread. Open findings whose thread was resolved
+// are dropped from open, and the rest pick up their thread's reply count.
+func followUp(threads []ghclient.ReviewThread, open []OpenFinding) ([]Outstanding, FollowUp, []OpenFinding) {
+	type key struct{ path, rule string }
+	var outstanding []Outstanding
+	var counts FollowUp
+	unresolved := make(map[key]int) // replies on the unresolved threads per finding
+	resolved := make(map[key]bool)
+
+	for _, t := range threads {
+		if !t.Bot {
+			continue
+		}
+		rule, message, ok := FindingComment(t.Body)
+		if !ok {
+			continue
+		}
+		k := key{t.Path, rule}
+		if t.Resolved {
+			counts.Resolved++
+			resolved[k] = true
+			continue
+		}
+		unresolved[k] += t.Replies
+		outstanding = append(outstanding, Outstanding{
+			Path: t.Path, Line: t.Line, Rule: rule, Message: message,
+			Replies: t.Replies, Outdated: t.Outdated, Thread: true,
+		})
+	}
+
+	kept := make([]OpenFinding, 0, len(open))
+	for _, f := range open {
+		k := key{f.Path, f.Rule}
+		replies, hasThread := unresolved[k]
+		if !hasThread && resolved[k] {
+			continue
+		}
+		f.Replies = replies
+		kept = append(kept, f)
+		if !hasThread {
+			outstanding = append(outstanding, Outstanding{Path: f.Path, Line: f.Line, Rule: f.Rule, Message: f.Message})
+		}
+	}
+
+	counts.Open = len(outstanding)
+	for _, o := range outstanding {
+		if o.Replies > 0 {
+			counts.Replied++
+		}
+	}
+	return outstanding, counts, kept
+}
+
+// FindingComment returns the rule and message of an inline comment PRMate
+// posted, or ok false for any other comment
+func FindingComment(body string) (rule, message string, ok bool) {
+	m := findingComment.FindStringSubmatch(firstLine(body))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// writeOutstanding renders the Outstanding Findings section of a summary
+func writeOutstanding(sb *strings.Builder, outstanding []Outstanding, counts FollowUp) {
+	if counts.Open == 0 && counts.Resolved == 0 {
+		return
+	}
+
+	sb.WriteString("\n### Outstanding Findings\n\n")
+	sb.WriteString(fmt.Sprintf("%d open (%d answered), %d resolved\n", counts.Open, counts.Replied, counts.Resolved))
+	if len(outstanding) == 0 {
+		return
+	}
+
+	sb.WriteString("\n| Finding | Where | Follow-up |\n|---------|-------|-----------|\n")
+	for i, o := range outstanding {
+		if i == maxOutstandingRows {
+			sb.WriteString(fmt.Sprintf("\n…and %d more.\n", len(outstanding)-maxOutstandingRows))
+			break
+		}
+		where := location(o.Path, o.Line)
+		if o.Outdated {
+			where += " (outdated)"
+		}
+		status := "no reply"
+		switch {
+		case !o.Thread:
+			status = "not commented inline"
+		case o.Replies == 1:
+			status = "1 reply"
+		case o.Replies > 1:
+			status = fmt.Sprintf("%d replies", o.Replies)
+		}
+		sb.WriteString(fmt.Sprintf("| **%s**: %s | %s | %s |\n", tableCell(o.Rule), tableCell(truncate(o.Message, 120)), where, status))
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// tableCell keeps text from breaking out of a markdown table cell
+func tableCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
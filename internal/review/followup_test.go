@@ -0,0 +1,78 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+func TestFollowUp(t *testing.T) {
+	threads := []ghclient.ReviewThread{
+		{Path: "a.go", Line: 4, Bot: true, Body: "🔴 **Error Handling**: Error not wrapped", Replies: 2},
+		{Path: "a.go", Line: 9, Bot: true, Outdated: true, Body: "🟡 **Naming**: Use camelCase"},
+		{Path: "b.go", Line: 7, Bot: true, Resolved: true, Body: "🔴 **SQL**: Query built from user input"},
+		{Path: "c.go", Line: 1, Body: "**Nit**: a human's comment"},
+		{Path: "d.go", Line: 2, Bot: true, Body: "Another bot without a rule"},
+	}
+	open := []OpenFinding{
+		{Path: "a.go", Line: 4, Rule: "Error Handling", Severity: "error", Message: "Error not wrapped"},
+		{Path: "b.go", Line: 7, Rule: "SQL", Severity: "error", Message: "Query built from user input"},
+		{Path: "assets/logo.png", Rule: "binary-size", Severity: "error", Message: "Binary file is 3.0MB"},
+	}
+
+	outstanding, counts, kept := followUp(threads, open)
+
+	var got []string
+	for _, o := range outstanding {
+		got = append(got, o.Path+" "+o.Rule)
+	}
+	if want := "a.go Error Handling,a.go Naming,assets/logo.png binary-size"; strings.Join(got, ",") != want {
+		t.Errorf("outstanding = %v, want %s", got, want)
+	}
+	if counts != (FollowUp{Open: 3, Replied: 1, Resolved: 1}) {
+		t.Errorf("counts = %+v", counts)
+	}
+	if len(kept) != 2 || kept[0].Rule != "Error Handling" || kept[0].Replies != 2 || kept[1].Rule != "binary-size" {
+		t.Errorf("open findings = %+v, want the resolved SQL finding dropped", kept)
+	}
+}
+
+func TestReviewPR_OutstandingFindings(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+		},
+		prFiles: []ghclient.PRFile{
+			{Filename: "handler.go", Status: "modified", Patch: "@@ -3,0 +4 @@\n+\tx := 1"},
+		},
+		reviewThreads: []ghclient.ReviewThread{
+			{Path: "old.go", Line: 12, Bot: true, Body: "🔴 **Error Handling**: Error | not wrapped", Replies: 1},
+			{Path: "old.go", Line: 30, Bot: true, Resolved: true, Body: "🟡 **Naming**: Use camelCase"},
+		},
+	}
+	llmMock := &mockLLMProvider{response: `{"violations": []}`}
+
+	svc := NewService(ghMock, llmMock, Config{})
+	if _, err := svc.ReviewPR(context.Background(), ReviewRequest{
+		Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(ghMock.postedComments) != 1 {
+		t.Fatalf("expected 1 summary comment, got %d", len(ghMock.postedComments))
+	}
+	body := ghMock.postedComments[0]
+	for _, want := range []string{
+		"### Outstanding Findings",
+		"1 open (1 answered), 1 resolved",
+		"| **Error Handling**: Error \\| not wrapped | `old.go:12` | 1 reply |",
+		`"follow_up":{"open":1,"replied":1,"resolved":1}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("summary missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package review
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ResolveDependencies lists the repository files that the file at filePath
+// depends on. The imports of a Go file from its own module are resolved,
+// through the module path in the nearest go.mod, to the files of the
+// imported packages, followed by the rest of its own package. Other files,
+// and Go files outside a module, fall back to Dependencies.
+func ResolveDependencies(ctx context.Context, src ContentSource, owner, repo, ref, filePath, fileContent string) []string {
+	if getFileExtension(filePath) == ".go" {
+		if deps, ok := goPackageFiles(ctx, src, owner, repo, ref, filePath, fileContent); ok {
+			return deps
+		}
+	}
+	return Dependencies(filePath, fileContent)
+}
+
+// moduleCache keeps the module roots and package listings a review has
+// looked up, so that its files do not read the same go.mod and list the
+// same packages again
+type moduleCache struct {
+	mu       sync.Mutex
+	modules  map[string]goModule   // by owner/repo@ref:dir looked up from
+	listings map[string]dirListing // by owner/repo@ref:dir
+}
+
+type goModule struct {
+	root, path string
+	ok         bool
+}
+
+type dirListing struct {
+	files []string
+	err   error
+}
+
+type moduleCacheKey struct{}
+
+// withModuleCache has the ResolveDependencies calls made with the returned
+// context share their go.mod lookups and package listings
+func withModuleCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, moduleCacheKey{}, &moduleCache{
+		modules:  make(map[string]goModule),
+		listings: make(map[string]dirListing),
+	})
+}
+
+func cacheKey(owner, repo, ref, dir string) string {
+	return owner + "/" + repo + "@" + ref + ":" + dir
+}
+
+// cachedGoModule is findGoModule, answered from the context's cache when
+// it has one
+func cachedGoModule(ctx context.Context, src ContentSource, owner, repo, ref, dir string) (root, module string, ok bool) {
+	cache, _ := ctx.Value(moduleCacheKey{}).(*moduleCache)
+	if cache == nil {
+		return findGoModule(ctx, src, owner, repo, ref, dir)
+	}
+	key := cacheKey(owner, repo, ref, dir)
+	cache.mu.Lock()
+	m, found := cache.modules[key]
+	cache.mu.Unlock()
+	if !found {
+		m.root, m.path, m.ok = findGoModule(ctx, src, owner, repo, ref, dir)
+		cache.mu.Lock()
+		cache.modules[key] = m
+		cache.mu.Unlock()
+	}
+	return m.root, m.path, m.ok
+}
+
+// cachedListing is src.ListDirectory, answered from the context's cache
+// when it has one
+func cachedListing(ctx context.Context, src ContentSource, owner, repo, ref, dir string) ([]string, error) {
+	cache, _ := ctx.Value(moduleCacheKey{}).(*moduleCache)
+	if cache == nil {
+		return src.ListDirectory(ctx, owner, repo, dir, ref)
+	}
+	key := cacheKey(owner, repo, ref, dir)
+	cache.mu.Lock()
+	l, found := cache.listings[key]
+	cache.mu.Unlock()
+	if !found {
+		l.files, l.err = src.ListDirectory(ctx, owner, repo, dir, ref)
+		cache.mu.Lock()
+		cache.listings[key] = l
+		cache.mu.Unlock()
+	}
+	return l.files, l.err
+}
+
+// goPackageFiles lists the non-test Go files of the packages a Go file
+// imports from its module, then those of its own package. ok is false when
+// the file is in no module or cannot be parsed.
+func goPackageFiles(ctx context.Context, src ContentSource, owner, repo, ref, filePath, fileContent string) (deps []string, ok bool) {
+	root, module, ok := cachedGoModule(ctx, src, owner, repo, ref, getDirectory(filePath))
+	if !ok {
+		return nil, false
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), filePath, fileContent, parser.ImportsOnly)
+	if err != nil {
+		return nil, false
+	}
+
+	var dirs []string
+	for _, imp := range f.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		rel, inModule := strings.CutPrefix(importPath, module)
+		if !inModule || (rel != "" && rel[0] != '/') {
+			continue
+		}
+		dirs = append(dirs, path.Join(root, strings.TrimPrefix(rel, "/")))
+	}
+	dirs = append(dirs, getDirectory(filePath))
+
+	seen := map[string]bool{filePath: true}
+	for _, dir := range dirs {
+		files, err := cachedListing(ctx, src, owner, repo, ref, dir)
+		if err != nil {
+			continue // vendored, generated or otherwise missing
+		}
+		files = slices.Sorted(slices.Values(files))
+		for _, file := range files {
+			if seen[file] || getFileExtension(file) != ".go" || strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			seen[file] = true
+			deps = append(deps, file)
+		}
+	}
+	return deps, true
+}
+
+// findGoModule reads the go.mod nearest to dir, looking up to the root of
+// the repository, and returns its directory and module path
+func findGoModule(ctx context.Context, src ContentSource, owner, repo, ref, dir string) (root, module string, ok bool) {
+	for {
+		content, err := src.GetFileContent(ctx, owner, repo, path.Join(dir, "go.mod"), ref)
+		if err == nil {
+			if module := modulePath(content); module != "" {
+				return dir, module, true
+			}
+		}
+		if dir == "" {
+			return "", "", false
+		}
+		dir = getDirectory(dir)
+	}
+}
+
+// modulePath returns the path the module directive of a go.mod declares
+func modulePath(goMod string) string {
+	for _, line := range strings.Split(goMod, "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(fields[1]); err == nil {
+			return unquoted
+		}
+		return fields[1]
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestModulePath(t *testing.T) {
+	tests := []struct {
+		name  string
+		goMod string
+		want  string
+	}{
+		{name: "plain", goMod: "module example.com/app\n\ngo 1.22\n", want: "example.com/app"},
+		{name: "quoted with comment", goMod: "// app\nmodule \"example.com/app\" // the app\n", want: "example.com/app"},
+		{name: "none", goMod: "go 1.22\n", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := modulePath(tt.goMod); got != tt.want {
+				t.Errorf("modulePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveDependencies_Go(t *testing.T) {
+	handler := `package api
+
+import (
+	"fmt"
+
+	"example.com/app/internal/auth"
+	"github.com/other/lib"
+)
+`
+	tests := []struct {
+		name  string
+		path  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "module at the root",
+			path: "internal/api/handler.go",
+			files: map[string]string{
+				"go.mod":                       "module example.com/app\n",
+				"internal/auth/token.go":       "package auth",
+				"internal/auth/token_test.go":  "package auth",
+				"internal/auth/README.md":      "# auth",
+				"internal/auth/jwt/jwt.go":     "package jwt",
+				"internal/api/handler.go":      handler,
+				"internal/api/routes.go":       "package api",
+				"internal/api/handler_test.go": "package api",
+			},
+			want: []string{"internal/auth/token.go", "internal/api/routes.go"},
+		},
+		{
+			name: "nested module",
+			path: "services/app/internal/api/handler.go",
+			files: map[string]string{
+				"go.mod":                                "module example.com/tools\n",
+				"services/app/go.mod":                   "module example.com/app\n",
+				"services/app/internal/auth/session.go": "package auth",
+			},
+			want: []string{"services/app/internal/auth/session.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &mockGitHubClient{fileContents: tt.files}
+			got := ResolveDependencies(context.Background(), src, "o", "r", "main", tt.path, handler)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ResolveDependencies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveDependencies_WithoutModule(t *testing.T) {
+	content := "package api\n\nimport (\n\t\"example.com/app/internal/auth\"\n)\n"
+	src := &mockGitHubClient{fileContents: map[string]string{"internal/auth/token.go": "package auth"}}
+
+	got := ResolveDependencies(context.Background(), src, "o", "r", "main", "internal/api/handler.go", content)
+	if want := Dependencies("internal/api/handler.go", content); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ResolveDependencies() = %v, want the guessed %v", got, want)
+	}
+}
+
+// countingSource counts the GitHub calls made through it
+type countingSource struct {
+	ContentSource
+	contents, listings atomic.Int32
+}
+
+func (c *countingSource) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	c.contents.Add(1)
+	return c.ContentSource.GetFileContent(ctx, owner, repo, path, ref)
+}
+
+func (c *countingSource) ListDirectory(ctx context.Context, owner, repo, dir, ref string) ([]string, error) {
+	c.listings.Add(1)
+	return c.ContentSource.ListDirectory(ctx, owner, repo, dir, ref)
+}
+
+func TestResolveDependencies_SharesLookupsWithinReview(t *testing.T) {
+	content := "package api\n\nimport (\n\t\"example.com/app/internal/auth\"\n)\n"
+	src := &countingSource{ContentSource: &mockGitHubClient{fileContents: map[string]string{
+		"go.mod":                  "module example.com/app\n",
+		"internal/auth/token.go":  "package auth",
+		"internal/api/handler.go": content,
+		"internal/api/routes.go":  content,
+	}}}
+
+	ctx := withModuleCache(context.Background())
+	for _, path := range []string{"internal/api/handler.go", "internal/api/routes.go"} {
+		ResolveDependencies(ctx, src, "o", "r", "main", path, content)
+	}
+	// go.mod is looked for in internal/api, internal and the root once, and
+	// the two packages are listed once
+	if got := src.contents.Load(); got != 3 {
+		t.Errorf("file reads = %d, want 3", got)
+	}
+	if got := src.listings.Load(); got != 2 {
+		t.Errorf("directory listings = %d, want 2", got)
+	}
+}
@@ -0,0 +1,236 @@
+package review
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ghclient "prmate/internal/github"
+)
+
+// Refs understood by LocalRepo.GetFileContent besides git revisions
+const (
+	WorkingTreeRef = ""  // files as they are on disk
+	IndexRef       = ":" // staged content
+)
+
+// LocalRepo reads files and diffs from a local git checkout so reviews can
+// run before anything is pushed
+type LocalRepo struct {
+	dir string
+}
+
+// NewLocalRepo creates a LocalRepo for the checkout at dir
+func NewLocalRepo(dir string) *LocalRepo {
+	return &LocalRepo{dir: dir}
+}
+
+// GetFileContent returns path at ref; owner and repo are ignored
+func (l *LocalRepo) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	if ref == WorkingTreeRef {
+		data, err := os.ReadFile(filepath.Join(l.dir, filepath.FromSlash(path)))
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", path, err)
+		}
+		return string(data), nil
+	}
+
+	spec := ref + ":" + path
+	if ref == IndexRef {
+		spec = ":" + path
+	}
+	return l.git(ctx, "show", spec)
+}
+
+// GetFileSize returns the size of path at ref; owner and repo are ignored
+func (l *LocalRepo) GetFileSize(ctx context.Context, owner, repo, path, ref string) (int64, error) {
+	if ref == WorkingTreeRef {
+		info, err := os.Stat(filepath.Join(l.dir, filepath.FromSlash(path)))
+		if err != nil {
+			return 0, fmt.Errorf("stat %s: %w", path, err)
+		}
+		return info.Size(), nil
+	}
+
+	spec := ref + ":" + path
+	if ref == IndexRef {
+		spec = ":" + path
+	}
+	out, err := l.git(ctx, "cat-file", "-s", spec)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+}
+
+// ListDirectory returns the files directly in dir at ref; owner and repo
+// are ignored
+func (l *LocalRepo) ListDirectory(ctx context.Context, owner, repo, dir, ref string) ([]string, error) {
+	dir = strings.Trim(dir, "/")
+	if ref == WorkingTreeRef {
+		entries, err := os.ReadDir(filepath.Join(l.dir, filepath.FromSlash(dir)))
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", dir, err)
+		}
+		var files []string
+		for _, e := range entries {
+			if e.Type().IsRegular() {
+				files = append(files, path.Join(dir, e.Name()))
+			}
+		}
+		return files, nil
+	}
+
+	var files []string
+	if ref == IndexRef {
+		args := []string{"ls-files"}
+		if dir != "" {
+			args = append(args, "--", dir)
+		}
+		out, err := l.git(ctx, args...)
+		if err != nil {
+			return nil, err
+		}
+		parent := dir
+		if parent == "" {
+			parent = "."
+		}
+		// ls-files lists subdirectories' files too
+		for _, f := range strings.Split(strings.TrimSpace(out), "\n") {
+			if f != "" && path.Dir(f) == parent {
+				files = append(files, f)
+			}
+		}
+		return files, nil
+	}
+
+	args := []string{"ls-tree", ref}
+	if dir != "" {
+		args = append(args, "--", dir+"/")
+	}
+	out, err := l.git(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		// <mode> <type> <object>\t<path>
+		meta, f, ok := strings.Cut(line, "\t")
+		if ok && strings.Contains(meta, " blob ") {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// Diff returns the files changed by diffRange (e.g. "HEAD~1..HEAD"), or the
+// staged changes when staged is set, along with the ref their new content
+// should be read at
+func (l *LocalRepo) Diff(ctx context.Context, diffRange string, staged bool) ([]ghclient.PRFile, string, error) {
+	var args []string
+	headRef := WorkingTreeRef
+	switch {
+	case staged:
+		args = []string{"--cached"}
+		headRef = IndexRef
+	case diffRange != "":
+		args = []string{diffRange}
+		if i := strings.LastIndex(diffRange, ".."); i >= 0 {
+			headRef = strings.TrimPrefix(diffRange[i+2:], ".")
+		}
+	default:
+		return nil, "", errors.New("either a diff range or staged changes must be selected")
+	}
+
+	out, err := l.git(ctx, append([]string{"diff", "--no-color", "-M", "--name-status"}, args...)...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var files []ghclient.PRFile
+	lines := bufio.NewScanner(strings.NewReader(out))
+	for lines.Scan() {
+		fields := strings.Split(lines.Text(), "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		paths := fields[1:]
+		file := ghclient.PRFile{Filename: paths[len(paths)-1], Status: fileStatus(fields[0])}
+
+		patchArgs := append([]string{"diff", "--no-color", "-M"}, args...)
+		patch, err := l.git(ctx, append(append(patchArgs, "--"), paths...)...)
+		if err != nil {
+			return nil, "", err
+		}
+		file.Patch, file.Additions, file.Deletions = hunks(patch)
+		files = append(files, file)
+	}
+
+	return files, headRef, nil
+}
+
+// fileStatus maps a git name-status letter to GitHub's file status
+func fileStatus(code string) string {
+	switch {
+	case strings.HasPrefix(code, "A"):
+		return "added"
+	case strings.HasPrefix(code, "D"):
+		return "removed"
+	case strings.HasPrefix(code, "R"):
+		return "renamed"
+	default:
+		return "modified"
+	}
+}
+
+// hunks strips the diff header so the patch matches what GitHub returns
+// for a PR file, and counts added and deleted lines
+func hunks(diff string) (patch string, additions, deletions int) {
+	start := strings.Index(diff, "@@")
+	if start < 0 {
+		return "", 0, 0
+	}
+	patch = strings.TrimRight(diff[start:], "\n")
+
+	for _, line := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			additions++
+		case strings.HasPrefix(line, "-"):
+			deletions++
+		}
+	}
+	return patch, additions, deletions
+}
+
+// HooksDir returns the directory git runs hooks from for this checkout,
+// honouring core.hooksPath and worktrees
+func (l *LocalRepo) HooksDir(ctx context.Context) (string, error) {
+	out, err := l.git(ctx, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(out)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(l.dir, dir)
+	}
+	return dir, nil
+}
+
+func (l *LocalRepo) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = l.dir
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s: %w", args[0], strings.TrimSpace(stderr.String()), err)
+	}
+	return string(out), nil
+}
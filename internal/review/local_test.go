@@ -0,0 +1,181 @@
+package review
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+func gitRepo(t *testing.T) (dir string, run func(args ...string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir = t.TempDir()
+	run = func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+	}
+	run("init", "--quiet")
+	return dir, run
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocalRepo_Diff(t *testing.T) {
+	dir, run := gitRepo(t)
+	writeFile(t, dir, "main.go", "package main\n")
+	writeFile(t, dir, "old.go", "package old\n")
+	run("add", ".")
+	run("commit", "--quiet", "-m", "base")
+
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	run("rm", "--quiet", "old.go")
+	run("add", ".")
+	run("commit", "--quiet", "-m", "change")
+
+	writeFile(t, dir, "new.go", "package new\n")
+	run("add", "new.go")
+
+	repo := NewLocalRepo(dir)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		diffRange string
+		staged    bool
+		wantRef   string
+		want      map[string]string // filename -> status
+	}{
+		{name: "range", diffRange: "HEAD~1..HEAD", wantRef: "HEAD", want: map[string]string{"main.go": "modified", "old.go": "removed"}},
+		{name: "staged", staged: true, wantRef: IndexRef, want: map[string]string{"new.go": "added"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, ref, err := repo.Diff(ctx, tt.diffRange, tt.staged)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if ref != tt.wantRef {
+				t.Errorf("ref = %q, want %q", ref, tt.wantRef)
+			}
+			if len(files) != len(tt.want) {
+				t.Fatalf("files = %+v, want %v", files, tt.want)
+			}
+			for _, f := range files {
+				if tt.want[f.Filename] != f.Status {
+					t.Errorf("%s status = %q, want %q", f.Filename, f.Status, tt.want[f.Filename])
+				}
+				if f.Patch == "" || f.Patch[:2] != "@@" {
+					t.Errorf("%s patch = %q, want hunks only", f.Filename, f.Patch)
+				}
+			}
+		})
+	}
+
+	content, err := repo.GetFileContent(ctx, "", "", "new.go", IndexRef)
+	if err != nil || content != "package new\n" {
+		t.Errorf("GetFileContent(index) = %q, %v", content, err)
+	}
+	content, err = repo.GetFileContent(ctx, "", "", "main.go", "HEAD~1")
+	if err != nil || content != "package main\n" {
+		t.Errorf("GetFileContent(HEAD~1) = %q, %v", content, err)
+	}
+	for _, ref := range []string{IndexRef, WorkingTreeRef} {
+		if size, err := repo.GetFileSize(ctx, "", "", "new.go", ref); err != nil || size != 12 {
+			t.Errorf("GetFileSize(%q) = %d, %v, want 12", ref, size, err)
+		}
+	}
+}
+
+func TestLocalRepo_ListDirectory(t *testing.T) {
+	dir, run := gitRepo(t)
+	if err := os.MkdirAll(filepath.Join(dir, "pkg", "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "main.go", "package main\n")
+	writeFile(t, dir, "pkg/a.go", "package pkg\n")
+	writeFile(t, dir, "pkg/sub/b.go", "package sub\n")
+	run("add", ".")
+	run("commit", "--quiet", "-m", "base")
+	writeFile(t, dir, "pkg/c.go", "package pkg\n")
+	run("add", "pkg/c.go")
+	writeFile(t, dir, "pkg/d.go", "package pkg\n")
+
+	repo := NewLocalRepo(dir)
+	tests := []struct {
+		name string
+		dir  string
+		ref  string
+		want []string
+	}{
+		{name: "commit", dir: "pkg", ref: "HEAD", want: []string{"pkg/a.go"}},
+		{name: "index", dir: "pkg", ref: IndexRef, want: []string{"pkg/a.go", "pkg/c.go"}},
+		{name: "working tree", dir: "pkg", ref: WorkingTreeRef, want: []string{"pkg/a.go", "pkg/c.go", "pkg/d.go"}},
+		{name: "root", dir: "", ref: "HEAD", want: []string{"main.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.ListDirectory(context.Background(), "", "", tt.dir, tt.ref)
+			if err != nil {
+				t.Fatalf("ListDirectory() error = %v", err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ListDirectory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHunks(t *testing.T) {
+	diff := "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n-old\n+new\n+more\n ctx\n"
+	patch, add, del := hunks(diff)
+	if patch != "@@ -1,2 +1,2 @@\n-old\n+new\n+more\n ctx" || add != 2 || del != 1 {
+		t.Errorf("hunks() = %q, %d, %d", patch, add, del)
+	}
+}
+
+func TestReviewFiles_PostsNothing(t *testing.T) {
+	src := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "## Learned Rules\n- Wrap errors\n",
+			"handler.go": "package main\n\nfunc foo() error {\n\treturn err\n}",
+		},
+	}
+	llmMock := &mockLLMProvider{
+		response: `{"violations": [{"line": 4, "rule": "Wrap errors", "message": "not wrapped", "severity": "error"}]}`,
+	}
+
+	svc := NewLocalService(src, llmMock, Config{})
+	result, err := svc.ReviewFiles(context.Background(), ReviewRequest{HeadRef: IndexRef}, []ghclient.PRFile{
+		{Filename: "handler.go", Status: "modified", Additions: 1, Patch: "@@ -3,0 +4 @@\n+\treturn err"},
+		{Filename: "gone.go", Status: "removed"},
+	})
+	if err != nil {
+		t.Fatalf("ReviewFiles() error = %v", err)
+	}
+
+	if result.FilesReviewed != 1 || result.ViolationsFound != 1 || result.SummaryPosted {
+		t.Errorf("result = %+v, want one file with one finding and nothing posted", result)
+	}
+	if len(src.postedReviews) != 0 || len(src.postedComments) != 0 {
+		t.Error("ReviewFiles must not post to GitHub")
+	}
+}
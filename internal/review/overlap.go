@@ -0,0 +1,72 @@
+package review
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	ghclient "prmate/internal/github"
+)
+
+// maxOverlapPaths bounds the paths listed for one overlapping pull request
+const maxOverlapPaths = 5
+
+// Overlap is another open pull request changing files this one changes
+type Overlap struct {
+	PRNumber int      `json:"pr"`
+	Paths    []string `json:"paths"`
+}
+
+// overlaps returns the pull requests among others that change any of files,
+// by number, so their authors can coordinate before they conflict
+func overlaps(others []OpenPR, files []ghclient.PRFile) []Overlap {
+	changed := make(map[string]bool, len(files))
+	for _, f := range files {
+		changed[f.Filename] = true
+	}
+
+	var found []Overlap
+	for _, pr := range others {
+		var paths []string
+		for _, path := range pr.Files {
+			if changed[path] {
+				paths = append(paths, path)
+			}
+		}
+		if len(paths) > 0 {
+			sort.Strings(paths)
+			found = append(found, Overlap{PRNumber: pr.Number, Paths: paths})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].PRNumber < found[j].PRNumber })
+	return found
+}
+
+// writeOverlaps lists the other open pull requests changing the same files
+func writeOverlaps(sb *strings.Builder, overlaps []Overlap) {
+	sb.WriteString("\n### Overlapping Pull Requests\n\n")
+	for _, o := range overlaps {
+		shown := o.Paths
+		if len(shown) > maxOverlapPaths {
+			shown = shown[:maxOverlapPaths]
+		}
+		quoted := make([]string, len(shown))
+		for i, p := range shown {
+			quoted[i] = "`" + p + "`"
+		}
+		line := fmt.Sprintf("- PR #%d also changes %s", o.PRNumber, strings.Join(quoted, ", "))
+		if more := len(o.Paths) - len(shown); more > 0 {
+			line += fmt.Sprintf(" and %d more files", more)
+		}
+		sb.WriteString(line + "\n")
+	}
+}
+
+// filenames returns the paths of files
+func filenames(files []ghclient.PRFile) []string {
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.Filename
+	}
+	return names
+}
@@ -0,0 +1,70 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+func TestOverlaps(t *testing.T) {
+	files := []ghclient.PRFile{{Filename: "a.go"}, {Filename: "b.go"}, {Filename: "c.go"}}
+	others := []OpenPR{
+		{Number: 14, Files: []string{"z.go"}},
+		{Number: 12, Files: []string{"c.go", "a.go", "y.go"}},
+		{Number: 9, Files: []string{"b.go"}},
+	}
+
+	got := overlaps(others, files)
+	want := []Overlap{{PRNumber: 9, Paths: []string{"b.go"}}, {PRNumber: 12, Paths: []string{"a.go", "c.go"}}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("overlaps() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteOverlaps(t *testing.T) {
+	var sb strings.Builder
+	writeOverlaps(&sb, []Overlap{
+		{PRNumber: 12, Paths: []string{"internal/auth/token.go"}},
+		{PRNumber: 15, Paths: []string{"1.go", "2.go", "3.go", "4.go", "5.go", "6.go", "7.go"}},
+	})
+	for _, want := range []string{
+		"### Overlapping Pull Requests",
+		"- PR #12 also changes `internal/auth/token.go`\n",
+		"- PR #15 also changes `1.go`, `2.go`, `3.go`, `4.go`, `5.go` and 2 more files\n",
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("overlaps section = %q, want %q", sb.String(), want)
+		}
+	}
+}
+
+func TestReviewPR_NotesOverlaps(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+		},
+		prFiles: []ghclient.PRFile{{Filename: "handler.go", Status: "modified", Patch: "@@ -1,0 +1 @@\n+package api"}},
+	}
+	svc := NewService(ghMock, &mockLLMProvider{response: `{"violations": []}`}, Config{})
+
+	result, err := svc.ReviewPR(context.Background(), ReviewRequest{
+		Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123",
+		OtherPRs: []OpenPR{{Number: 12, Files: []string{"handler.go", "other.go"}}, {Number: 13, Files: []string{"other.go"}}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0] != "handler.go" {
+		t.Errorf("Files = %v, want handler.go", result.Files)
+	}
+	if len(ghMock.postedComments) != 1 {
+		t.Fatalf("expected a summary comment, got %d", len(ghMock.postedComments))
+	}
+	body := ghMock.postedComments[0]
+	if !strings.Contains(body, "- PR #12 also changes `handler.go`") || strings.Contains(body, "#13") {
+		t.Errorf("summary = %q, want only PR #12 noted", body)
+	}
+}
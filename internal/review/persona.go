@@ -0,0 +1,96 @@
+package review
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// persona is a reviewer style: who the LLM reviews as, and how it words
+// and explains its findings. Personas change tone and verbosity only; the
+// rules, the response format and the severity of findings stay the same.
+type persona struct {
+	role  string   // opens the analysis prompt, replacing the default reviewer
+	style []string // added to the prompt's instructions
+}
+
+// personas are the reviewer styles a repository can choose from
+var personas = map[string]persona{
+	"security": {
+		role: "You are a strict application security reviewer. Analyze the following code changes and identify any violations of the project's coding standards, paying closest attention to security.",
+		style: []string{
+			"Look hardest for injection, broken authentication or authorization, leaked secrets, unsafe deserialization and unvalidated input",
+			"Keep messages factual and direct, and say in one sentence what an attacker could do with each security flaw",
+		},
+	},
+	"mentor": {
+		role: "You are a friendly senior engineer mentoring a junior developer. Analyze the following code changes and identify any violations of the project's coding standards.",
+		style: []string{
+			"Write messages in a warm, encouraging tone, addressed to the author",
+			"Explain in two or three sentences why each issue matters and the idea behind the better approach, so the author learns the convention rather than only the fix",
+		},
+	},
+	"terse": {
+		role: "You are a terse senior engineer. Analyze the following code changes and identify any violations of the project's coding standards.",
+		style: []string{
+			"Keep each message to one short sentence, without preamble, praise or hedging",
+			"Give the fix as code only, without explanation",
+		},
+	},
+}
+
+// PersonaNames returns the names of the reviewer personas, sorted
+func PersonaNames() []string {
+	return slices.Sorted(maps.Keys(personas))
+}
+
+// parsePersona reads the persona a .prmate.md chooses in its front matter:
+//
+//	---
+//	persona: mentor
+//	---
+//
+// and returns the content without the front matter. A file without front
+// matter chooses none.
+func parsePersona(content string) (string, string, error) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(content, "\ufeff"), "---\n")
+	if !ok {
+		return "", content, nil
+	}
+	front, body, ok := strings.Cut(rest, "\n---\n")
+	if !ok {
+		if front, ok = strings.CutSuffix(rest, "\n---"); !ok {
+			return "", content, nil
+		}
+	}
+
+	var name string
+	for _, line := range strings.Split(front, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) != "persona" {
+			continue
+		}
+		name = strings.ToLower(strings.Trim(strings.TrimSpace(value), `"'`))
+		if _, known := personas[name]; !known && name != "" {
+			return "", content, fmt.Errorf("persona %q must be one of %s", name, strings.Join(PersonaNames(), ", "))
+		}
+	}
+	return name, body, nil
+}
+
+// persona returns the persona reviews by rules use: the one .prmate.md
+// chooses, else the configured one, else none
+func (s *Service) persona(rules Rules) persona {
+	if p, ok := personas[rules.Persona]; ok {
+		return p
+	}
+	return personas[s.config.Persona]
+}
+
+// writeStyle adds the persona's style to the instructions of a prompt
+func (p persona) writeStyle(sb *strings.Builder) {
+	for _, line := range p.style {
+		sb.WriteString("- " + line + "\n")
+	}
+}
@@ -0,0 +1,80 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+func TestParsePersona(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		want     string
+		wantBody string
+		wantErr  string
+	}{
+		{name: "no front matter", content: "# Context\n", wantBody: "# Context\n"},
+		{name: "persona", content: "---\npersona: Mentor\nowner: web\n---\n# Context\n", want: "mentor", wantBody: "# Context\n"},
+		{name: "quoted", content: "---\npersona: \"terse\"\n---\n", want: "terse", wantBody: ""},
+		{name: "front matter only", content: "---\npersona: security\n---", want: "security", wantBody: ""},
+		{name: "unclosed", content: "---\npersona: mentor\n# Context\n", wantBody: "---\npersona: mentor\n# Context\n"},
+		{name: "unknown", content: "---\npersona: pirate\n---\n", wantErr: `persona "pirate" must be one of mentor, security, terse`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, body, err := parsePersona(tt.content)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("parsePersona() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePersona() error = %v", err)
+			}
+			if got != tt.want || body != tt.wantBody {
+				t.Errorf("parsePersona() = %q, %q, want %q, %q", got, body, tt.want, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestReviewPR_Persona(t *testing.T) {
+	rules := "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n"
+	tests := []struct {
+		name       string
+		prmateMD   string
+		configured string
+		want       string
+	}{
+		{name: "default", prmateMD: rules, want: "You are a senior code reviewer."},
+		{name: "configured", prmateMD: rules, configured: "terse", want: "Keep each message to one short sentence"},
+		{name: "front matter wins", prmateMD: "---\npersona: mentor\n---\n" + rules, configured: "terse", want: "You are a friendly senior engineer mentoring a junior developer."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ghMock := &mockGitHubClient{
+				fileContents: map[string]string{".prmate.md": tt.prmateMD},
+				prFiles:      []ghclient.PRFile{{Filename: "handler.go", Status: "modified", Patch: "@@ -3,0 +4 @@\n+\treturn err"}},
+			}
+			llmMock := &mockLLMProvider{response: `{"violations": []}`}
+
+			svc := NewService(ghMock, llmMock, Config{Persona: tt.configured})
+			if _, err := svc.ReviewPR(context.Background(), ReviewRequest{
+				Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+			}); err != nil {
+				t.Fatalf("ReviewPR() error = %v", err)
+			}
+			prompt := llmMock.prompts[0]
+			if !strings.Contains(prompt, tt.want) {
+				t.Errorf("prompt does not contain %q:\n%s", tt.want, prompt)
+			}
+			if !strings.Contains(prompt, "Use fmt.Errorf with %w for error wrapping") {
+				t.Error("the persona should not change the rules")
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preferences are how the author of a pull request wants to hear about its
+// findings. They decide what is commented on, never what is found: the
+// summary counts every finding and the review still requests changes when
+// a finding reaches the gate.
+type Preferences struct {
+	SummaryOnly bool     // no inline comments; findings are listed in the summary
+	Muted       []string // rules whose findings are not commented on
+}
+
+// mutes reports whether the author muted the rule of v
+func (p Preferences) mutes(v FileViolation) bool {
+	for _, rule := range p.Muted {
+		if strings.EqualFold(rule, v.Rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// commented returns the violations the author wants comments on, and how
+// many of the rest were muted
+func (p Preferences) commented(violations []FileViolation) ([]FileViolation, int) {
+	if p.SummaryOnly {
+		return nil, 0
+	}
+	if len(p.Muted) == 0 {
+		return violations, 0
+	}
+	kept := make([]FileViolation, 0, len(violations))
+	for _, v := range violations {
+		if !p.mutes(v) {
+			kept = append(kept, v)
+		}
+	}
+	return kept, len(violations) - len(kept)
+}
+
+// writeFindingList lists the findings of summary in the summary comment,
+// for authors who turned inline comments off
+func writeFindingList(sb *strings.Builder, findings []OpenFinding) {
+	if len(findings) == 0 {
+		return
+	}
+	sb.WriteString("\n<details>\n<summary>Findings</summary>\n\n")
+	for _, f := range findings {
+		location := fmt.Sprintf("`%s`", f.Path)
+		if f.Line > 0 {
+			location = fmt.Sprintf("`%s:%d`", f.Path, f.Line)
+		}
+		sb.WriteString(fmt.Sprintf("- %s %s **%s**: %s\n", location, f.Severity, f.Rule, f.Message))
+	}
+	sb.WriteString("</details>\n")
+}
@@ -0,0 +1,101 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+func TestReviewPR_Preferences(t *testing.T) {
+	response := `{"violations": [
+		{"line": 2, "rule": "Error Handling", "message": "Wrap the error", "severity": "error"},
+		{"line": 3, "rule": "Naming", "message": "Rename x", "severity": "warning"}
+	]}`
+	tests := []struct {
+		name        string
+		prefs       Preferences
+		wantRules   []string // rules commented on
+		wantEvent   string
+		wantSummary []string
+		notSummary  []string
+	}{
+		{name: "default", wantRules: []string{"Error Handling", "Naming"}, wantEvent: "REQUEST_CHANGES",
+			notSummary: []string{"| Muted |", "<summary>Findings</summary>"}},
+		{name: "muted rule", prefs: Preferences{Muted: []string{"naming"}}, wantRules: []string{"Error Handling"}, wantEvent: "REQUEST_CHANGES",
+			wantSummary: []string{"| Issues Found | 2 |", "| Muted | 1 |"}},
+		{name: "muted error still blocks", prefs: Preferences{Muted: []string{"Error Handling"}}, wantRules: []string{"Naming"}, wantEvent: "REQUEST_CHANGES"},
+		{name: "summary only", prefs: Preferences{SummaryOnly: true}, wantEvent: "REQUEST_CHANGES",
+			wantSummary: []string{"<summary>Findings</summary>", "- `main.go:2` error **Error Handling**: Wrap the error", "- `main.go:3` warning **Naming**: Rename x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ghMock := &mockGitHubClient{
+				fileContents: map[string]string{
+					".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Wrap errors\n",
+				},
+				prFiles: []ghclient.PRFile{{Filename: "main.go", Status: "modified", Patch: "@@ -1,1 +1,3 @@\n package main\n+x := load()\n+return err"}},
+			}
+			svc := NewService(ghMock, &mockLLMProvider{response: response}, Config{})
+
+			req := ReviewRequest{Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123", Preferences: tt.prefs}
+			result, err := svc.ReviewPR(context.Background(), req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.ViolationsFound != 2 || !result.Blocking {
+				t.Errorf("result = %+v, want both findings and blocking", result)
+			}
+			if len(ghMock.postedReviews) != 1 {
+				t.Fatalf("posted %d reviews, want 1", len(ghMock.postedReviews))
+			}
+			posted := ghMock.postedReviews[0]
+			if posted.event != tt.wantEvent {
+				t.Errorf("event = %q, want %q", posted.event, tt.wantEvent)
+			}
+			var rules []string
+			for _, c := range posted.comments {
+				rules = append(rules, strings.SplitN(c.Body, "**", 3)[1])
+			}
+			if strings.Join(rules, ",") != strings.Join(tt.wantRules, ",") {
+				t.Errorf("commented rules = %q, want %q", rules, tt.wantRules)
+			}
+			if held := 2 - len(tt.wantRules); held > 0 && !strings.Contains(posted.body, "not commented on") {
+				t.Errorf("review body = %q, want the findings not commented on counted", posted.body)
+			}
+
+			summary := ghMock.postedComments[len(ghMock.postedComments)-1]
+			for _, want := range tt.wantSummary {
+				if !strings.Contains(summary, want) {
+					t.Errorf("summary missing %q:\n%s", want, summary)
+				}
+			}
+			for _, notWant := range tt.notSummary {
+				if strings.Contains(summary, notWant) {
+					t.Errorf("summary has %q:\n%s", notWant, summary)
+				}
+			}
+		})
+	}
+}
+
+func TestReviewPR_PreferencesPostNothingWithoutGate(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Name things well\n",
+		},
+		prFiles: []ghclient.PRFile{{Filename: "main.go", Status: "modified", Patch: "@@ -1,1 +1,2 @@\n package main\n+x := load()"}},
+	}
+	llmMock := &mockLLMProvider{response: `{"violations": [{"line": 2, "rule": "Naming", "message": "Rename x", "severity": "warning"}]}`}
+	svc := NewService(ghMock, llmMock, Config{})
+
+	req := ReviewRequest{Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123", Preferences: Preferences{SummaryOnly: true}}
+	result, err := svc.ReviewPR(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ghMock.postedReviews) != 0 || result.CommentsPosted != 0 {
+		t.Errorf("posted reviews = %+v, want none", ghMock.postedReviews)
+	}
+}
@@ -0,0 +1,190 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/logging"
+	"prmate/internal/state"
+)
+
+// progressMarker ends the line showing a review under way on the summary
+// comment, so the next update replaces it
+const progressMarker = "<!-- prmate-progress -->"
+
+// checkpoint is what the state store keeps of a file a review finished
+type checkpoint struct {
+	Violations []FileViolation `json:"violations,omitempty"`
+	ReviewedAt time.Time       `json:"reviewed_at"`
+}
+
+// progress follows a review of a pull request file by file. It checkpoints
+// each file analyzed to the state store, hands back the files an earlier
+// review of the same commit finished, and once the review has run for
+// Config.ProgressInterval, shows how far it got on the summary comment.
+type progress struct {
+	s       *Service
+	req     ReviewRequest
+	total   int
+	started time.Time
+	resumed map[string]fileReview
+
+	mu     sync.Mutex
+	done   int
+	posted time.Time // when progress was last shown; zero until then
+}
+
+// startProgress starts following a review of files, loading the
+// checkpoints an earlier review of req.HeadSHA left
+func (s *Service) startProgress(ctx context.Context, req ReviewRequest, files []ghclient.PRFile) *progress {
+	p := &progress{s: s, req: req, started: time.Now(), resumed: make(map[string]fileReview)}
+	for _, f := range files {
+		if f.Status != "removed" {
+			p.total++
+		}
+	}
+	if !s.checkpointing(req) {
+		return p
+	}
+
+	logger := logging.FromContext(ctx)
+	saved, err := s.state.Checkpoints(ctx, stateKey(req), req.HeadSHA)
+	if err != nil {
+		logger.Warn("could not load review checkpoints", "error", err)
+		return p
+	}
+	for path, data := range saved {
+		var c checkpoint
+		if err := json.Unmarshal(data, &c); err != nil {
+			logger.Warn("skipping unreadable review checkpoint", "path", path, "error", err)
+			continue
+		}
+		p.resumed[path] = fileReview{violations: c.Violations, reviewed: true, reviewedAt: c.ReviewedAt}
+	}
+	if len(p.resumed) > 0 {
+		logger.Info("Resuming review from checkpoints", "files", len(p.resumed))
+	}
+	return p
+}
+
+// checkpointing reports whether reviews of req checkpoint their files
+func (s *Service) checkpointing(req ReviewRequest) bool {
+	return s.state != nil && !s.githubClient.DryRun(req.Owner, req.Repo)
+}
+
+// stateKey identifies the pull request of req in the state store
+func stateKey(req ReviewRequest) state.Key {
+	return state.Key{Owner: req.Owner, Repo: req.Repo, PRNumber: req.PRNumber}
+}
+
+// resume returns the outcome an earlier review recorded for path, if any
+func (p *progress) resume(path string) (fileReview, bool) {
+	if p == nil {
+		return fileReview{}, false
+	}
+	r, ok := p.resumed[path]
+	if ok {
+		p.mu.Lock()
+		p.done++
+		p.mu.Unlock()
+	}
+	return r, ok
+}
+
+// finished checkpoints the outcome of reviewing path and shows the progress
+// of the review when it is due. Files whose analysis failed are not
+// checkpointed, so a resumed review tries them again.
+func (p *progress) finished(ctx context.Context, path string, r fileReview) {
+	if p == nil {
+		return
+	}
+	logger := logging.FromContext(ctx)
+	if r.reviewed && p.s.checkpointing(p.req) {
+		data, err := json.Marshal(checkpoint{Violations: r.violations, ReviewedAt: r.reviewedAt})
+		if err == nil {
+			// Kept even when another file's failure has just cancelled
+			// the review
+			err = p.s.state.Checkpoint(context.WithoutCancel(ctx), stateKey(p.req), p.req.HeadSHA, path, data)
+		}
+		if err != nil {
+			logger.Warn("could not checkpoint review", "path", path, "error", err)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	interval := p.s.config.ProgressInterval
+	now := time.Now()
+	if interval <= 0 || p.done == p.total || now.Sub(p.started) < interval || now.Sub(p.posted) < interval {
+		return
+	}
+	p.posted = now
+	line := fmt.Sprintf("Reviewing `%s`: %d/%d files…", shortSHA(p.req.HeadSHA), p.done, p.total)
+	if p.s.branding().Emoji {
+		line = "⏳ " + line
+	}
+	p.show(ctx, line)
+}
+
+// stop shows that the review stopped before finishing, if its progress was
+// shown
+func (p *progress) stop(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.posted.IsZero() {
+		return
+	}
+	line := fmt.Sprintf("The review of `%s` stopped after %d/%d files.", shortSHA(p.req.HeadSHA), p.done, p.total)
+	if p.s.checkpointing(p.req) {
+		line += " The next review of this commit resumes from there."
+	}
+	// The review is usually stopped by its context ending
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	p.show(ctx, line)
+}
+
+// show puts line on the summary comment, in place of the progress shown
+// before, or posts a summary comment holding only line
+func (p *progress) show(ctx context.Context, line string) {
+	logger := logging.FromContext(ctx)
+	req := p.req
+	commentID, body, err := p.s.summaryComment(ctx, req)
+	if err != nil {
+		logger.Warn("could not find summary comment to show progress on", "error", err)
+		return
+	}
+	line += " " + progressMarker
+	if commentID == 0 {
+		err = p.s.githubClient.CreatePRComment(ctx, req.Owner, req.Repo, req.PRNumber,
+			fmt.Sprintf("%s%s%s\n%s\n", summaryMarkerPrefix, req.HeadSHA, summaryMarkerSuffix, line))
+	} else {
+		err = p.s.githubClient.UpdatePRComment(ctx, req.Owner, req.Repo, req.PRNumber, commentID, withProgress(body, line))
+	}
+	if err != nil {
+		logger.Warn("could not show review progress", "error", err)
+	}
+}
+
+// withProgress puts line after the hidden marker on the first line of the
+// summary comment body, dropping the progress line shown before
+func withProgress(body, line string) string {
+	lines := strings.Split(body, "\n")
+	kept := []string{lines[0], line, ""}
+	for i := 1; i < len(lines); i++ {
+		if strings.Contains(lines[i], progressMarker) {
+			if i+1 < len(lines) && lines[i+1] == "" {
+				i++ // and the blank line after it
+			}
+			continue
+		}
+		kept = append(kept, lines[i])
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,121 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"prmate/internal/errclass"
+	ghclient "prmate/internal/github"
+	"prmate/internal/state"
+)
+
+// flakyLLM is rate limited from its answer number failAt on
+type flakyLLM struct {
+	mockLLMProvider
+	failAt int
+}
+
+func (f *flakyLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	if len(f.prompts)+1 >= f.failAt && f.failAt > 0 {
+		f.prompts = append(f.prompts, prompt)
+		return "", errclass.ErrRateLimited
+	}
+	return f.mockLLMProvider.GenerateTextWithContext(ctx, prompt)
+}
+
+func TestReviewPR_ResumesFromCheckpoints(t *testing.T) {
+	previous, _ := json.Marshal(ReviewSummary{Version: deltaVersion, HeadSHA: "prev123"})
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+		},
+		prFiles: []ghclient.PRFile{
+			{Filename: "a.go", Status: "modified", Patch: "@@ -0,0 +1 @@\n+package a"},
+			{Filename: "b.go", Status: "modified", Patch: "@@ -0,0 +1 @@\n+package b"},
+			{Filename: "c.go", Status: "modified", Patch: "@@ -0,0 +1 @@\n+package c"},
+		},
+		prComments: []string{summaryMarkerPrefix + "prev123" + summaryMarkerSuffix + "\n## PRMate Review Summary\n\n" + summaryDataPrefix + string(previous) + summaryMarkerSuffix},
+	}
+	llm := &flakyLLM{
+		mockLLMProvider: mockLLMProvider{response: `{"violations": [{"line": 1, "rule": "Error Handling", "message": "Wrap the error", "severity": "error"}]}`},
+		failAt:          2,
+	}
+	st := newMemoryState()
+	svc := NewService(ghMock, llm, Config{ProgressInterval: time.Nanosecond})
+	svc.SetStateStore(st)
+	req := ReviewRequest{Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123"}
+
+	// The review is cut short after a.go; the summary comment says so
+	if _, err := svc.ReviewPR(context.Background(), req); err == nil {
+		t.Fatal("expected the rate limited review to stop")
+	}
+	body := ghMock.prComments[0]
+	for _, want := range []string{"The review of `abc123` stopped after 1/3 files. The next review of this commit resumes from there.", "## PRMate Review Summary", summaryDataPrefix} {
+		if !strings.Contains(body, want) {
+			t.Errorf("summary comment missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "Reviewing `abc123`") {
+		t.Errorf("summary comment still shows the review under way:\n%s", body)
+	}
+	saved, _ := st.Checkpoints(context.Background(), state.Key{Owner: "test", Repo: "repo", PRNumber: 1}, "abc123")
+	if len(saved) != 1 || saved["a.go"] == nil {
+		t.Fatalf("checkpoints = %s, want a.go", saved)
+	}
+
+	// The next review analyzes b.go and c.go only, and keeps the finding in a.go
+	llm.failAt = 0
+	llm.prompts = nil
+	result, err := svc.ReviewPR(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(llm.prompts) != 2 || strings.Contains(llm.prompts[0], "a.go") {
+		t.Errorf("expected b.go and c.go analyzed, got %d prompts", len(llm.prompts))
+	}
+	if result.ViolationsFound != 3 || result.Violations[0].Path != "a.go" {
+		t.Errorf("violations = %+v, want one per file starting with a.go", result.Violations)
+	}
+	if strings.Contains(ghMock.prComments[0], progressMarker) {
+		t.Errorf("summary comment still shows progress:\n%s", ghMock.prComments[0])
+	}
+	if saved, _ := st.Checkpoints(context.Background(), state.Key{Owner: "test", Repo: "repo", PRNumber: 1}, "abc123"); len(saved) != 0 {
+		t.Errorf("checkpoints = %s, want none once the review finished", saved)
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	marker := summaryMarkerPrefix + "abc" + summaryMarkerSuffix
+	line := "Reviewing 2/3 files " + progressMarker
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "summary",
+			body: marker + "\n## Summary\n",
+			want: marker + "\n" + line + "\n\n## Summary\n",
+		},
+		{
+			name: "earlier progress",
+			body: marker + "\nReviewing 1/3 files " + progressMarker + "\n\n## Summary\n",
+			want: marker + "\n" + line + "\n\n## Summary\n",
+		},
+		{
+			name: "progress only",
+			body: marker + "\nReviewing 1/3 files " + progressMarker + "\n",
+			want: marker + "\n" + line + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withProgress(tt.body, line); got != tt.want {
+				t.Errorf("withProgress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package review
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// junitTestSuites is the root of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the violations as a JUnit XML report with one failing
+// test case per violation. A clean review yields a single passing case so CI
+// test panels still show that the review ran.
+func WriteJUnit(w io.Writer, result *ReviewResult) error {
+	suite := junitSuite{Name: "prmate"}
+	for _, v := range result.Violations {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			ClassName: v.Path,
+			Name:      fmt.Sprintf("%s (line %d)", v.Rule, v.Line),
+			Failure: &junitFailure{
+				Message: v.Message,
+				Type:    v.Severity,
+				Text:    fmt.Sprintf("%s:%d: [%s] %s: %s", v.Path, v.Line, v.Severity, v.Rule, v.Message),
+			},
+		})
+	}
+	suite.Failures = len(suite.TestCases)
+	if len(suite.TestCases) == 0 {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			ClassName: "prmate",
+			Name:      fmt.Sprintf("review (%d files)", result.FilesReviewed),
+		})
+	}
+	suite.Tests = len(suite.TestCases)
+
+	report := junitTestSuites{Tests: suite.Tests, Failures: suite.Failures, Suites: []junitSuite{suite}}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("encode junit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// codeQualityIssue is one entry of a GitLab code quality report
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+// codeQualitySeverity maps PRMate severities onto GitLab's scale
+var codeQualitySeverity = map[string]string{
+	"error":      "major",
+	"warning":    "minor",
+	"suggestion": "info",
+}
+
+// WriteCodeQuality writes the violations as a GitLab code quality (Code
+// Climate) JSON report
+func WriteCodeQuality(w io.Writer, result *ReviewResult) error {
+	issues := make([]codeQualityIssue, 0, len(result.Violations))
+	for _, v := range result.Violations {
+		severity, ok := codeQualitySeverity[v.Severity]
+		if !ok {
+			severity = "info"
+		}
+		line := v.Line
+		if line < 1 {
+			line = 1
+		}
+		issues = append(issues, codeQualityIssue{
+			Description: v.Message,
+			CheckName:   v.Rule,
+			Fingerprint: fingerprint(v),
+			Severity:    severity,
+			Location:    codeQualityLocation{Path: v.Path, Lines: codeQualityLines{Begin: line}},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(issues); err != nil {
+		return fmt.Errorf("encode code quality report: %w", err)
+	}
+	return nil
+}
+
+// fingerprint identifies a violation across runs. The line is left out so
+// unrelated edits above a finding don't make it look new.
+func fingerprint(v FileViolation) string {
+	sum := sha256.Sum256([]byte(v.Path + "\x00" + v.Rule + "\x00" + v.Message))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,99 @@
+package review
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	tests := []struct {
+		name         string
+		result       *ReviewResult
+		wantTests    int
+		wantFailures int
+	}{
+		{
+			name: "violations",
+			result: &ReviewResult{FilesReviewed: 2, Violations: []FileViolation{
+				{Path: "a.go", Line: 3, Rule: "no-panic", Message: "avoid panic", Severity: "error"},
+				{Path: "b.go", Line: 9, Rule: "naming", Message: "use camelCase", Severity: "warning"},
+			}},
+			wantTests:    2,
+			wantFailures: 2,
+		},
+		{
+			name:         "clean review",
+			result:       &ReviewResult{FilesReviewed: 4},
+			wantTests:    1,
+			wantFailures: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteJUnit(&buf, tt.result); err != nil {
+				t.Fatalf("WriteJUnit() error = %v", err)
+			}
+
+			var got junitTestSuites
+			if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+			}
+			if got.Tests != tt.wantTests || got.Failures != tt.wantFailures {
+				t.Errorf("tests=%d failures=%d, want %d/%d", got.Tests, got.Failures, tt.wantTests, tt.wantFailures)
+			}
+			if len(got.Suites) != 1 || len(got.Suites[0].TestCases) != tt.wantTests {
+				t.Fatalf("unexpected suites: %+v", got.Suites)
+			}
+		})
+	}
+}
+
+func TestWriteCodeQuality(t *testing.T) {
+	result := &ReviewResult{Violations: []FileViolation{
+		{Path: "a.go", Line: 3, Rule: "no-panic", Message: "avoid panic", Severity: "error"},
+		{Path: "a.go", Line: 0, Rule: "docs", Message: "add a comment", Severity: "suggestion"},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteCodeQuality(&buf, result); err != nil {
+		t.Fatalf("WriteCodeQuality() error = %v", err)
+	}
+
+	var issues []codeQualityIssue
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("got %d issues, want 2", len(issues))
+	}
+	if issues[0].Severity != "major" || issues[1].Severity != "info" {
+		t.Errorf("severities = %q, %q, want major, info", issues[0].Severity, issues[1].Severity)
+	}
+	if issues[1].Location.Lines.Begin != 1 {
+		t.Errorf("line 0 should be reported as 1, got %d", issues[1].Location.Lines.Begin)
+	}
+	if issues[0].Fingerprint == issues[1].Fingerprint {
+		t.Error("distinct violations share a fingerprint")
+	}
+
+	moved := result.Violations[0]
+	moved.Line = 30
+	if fingerprint(moved) != issues[0].Fingerprint {
+		t.Error("fingerprint should not depend on the line number")
+	}
+}
+
+func TestWriteCodeQuality_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCodeQuality(&buf, &ReviewResult{}); err != nil {
+		t.Fatalf("WriteCodeQuality() error = %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("empty report = %q, want []", buf.String())
+	}
+}
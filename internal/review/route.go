@@ -0,0 +1,80 @@
+package review
+
+import (
+	"context"
+	"slices"
+
+	"prmate/internal/checks"
+	ghclient "prmate/internal/github"
+	"prmate/internal/llm"
+	"prmate/internal/logging"
+)
+
+// loadRouteRules reads the rules file of every route in rules that names
+// one. A route whose file cannot be read or parsed keeps the rules of
+// .prmate.md, so one team's mistake does not stop every review.
+func (s *Service) loadRouteRules(ctx context.Context, owner, repo, ref string, rules *Rules) {
+	if rules.Checks == nil {
+		return
+	}
+	logger := logging.FromContext(ctx)
+	for _, route := range rules.Checks.Routes {
+		if route.Rules == "" {
+			continue
+		}
+		content, err := s.content.GetFileContent(ctx, owner, repo, route.Rules, ref)
+		if err != nil {
+			logger.Warn("could not read route rules, using .prmate.md", "route", route.Prefix, "rules", route.Rules, "error", err)
+			continue
+		}
+		team, err := ParseRules(content)
+		if err != nil {
+			logger.Warn("could not parse route rules, using .prmate.md", "route", route.Prefix, "rules", route.Rules, "error", err)
+			continue
+		}
+		if rules.Routes == nil {
+			rules.Routes = make(map[string]Rules)
+		}
+		rules.Routes[route.Prefix] = team
+	}
+}
+
+// forFile returns the rules the LLM reviews path by: the rules, rule docs,
+// checklist and codebase sections of its route's rules file when it has one.
+// Checks, infrastructure conventions and the language always come from
+// .prmate.md.
+func (r Rules) forFile(path string) Rules {
+	route := r.Checks.RouteFor(path)
+	if route == nil {
+		return r
+	}
+	team, ok := r.Routes[route.Prefix]
+	if !ok {
+		return r
+	}
+	out := r
+	out.Rules, out.Checklist, out.CodebaseInfo, out.Docs = team.Rules, team.Checklist, team.CodebaseInfo, team.Docs
+	return out
+}
+
+// withRouteModel asks for the model of path's route, if it names one, in
+// the LLM calls made with the returned context
+func withRouteModel(ctx context.Context, set *checks.Set, path string) context.Context {
+	if route := set.RouteFor(path); route != nil && route.Model != "" {
+		return llm.WithModel(ctx, route.Model)
+	}
+	return ctx
+}
+
+// routeChannels returns the notification channels of the routes holding
+// any of files, sorted
+func routeChannels(set *checks.Set, files []ghclient.PRFile) []string {
+	var channels []string
+	for _, f := range files {
+		if route := set.RouteFor(f.Filename); route != nil && route.Notify != "" && !slices.Contains(channels, route.Notify) {
+			channels = append(channels, route.Notify)
+		}
+	}
+	slices.Sort(channels)
+	return channels
+}
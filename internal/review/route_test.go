@@ -0,0 +1,72 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/llm"
+)
+
+// modelLLM answers every call with one warning and records the model each
+// prompt asked for
+type modelLLM struct {
+	prompts map[string]string // prompt by requested model
+}
+
+func (m *modelLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	m.prompts[llm.ModelFor(ctx, "default")] = prompt
+	return `{"violations": [{"line": 4, "rule": "Style", "message": "Consider this", "severity": "warning"}]}`, nil
+}
+
+func TestReviewPR_Routes(t *testing.T) {
+	prmateMD := "# PRMate Context\n\n## Learned Rules\n- Handlers log with slog\n\n" +
+		"```prmate-checks\nroute: services/payments\nrules: services/payments/PRMATE.md\ngate: warning\nnotify: payments\nmodel: gpt-4o\n```\n"
+	payments := ghclient.PRFile{Filename: "services/payments/charge.go", Status: "modified", Additions: 1, Patch: "@@ -3,0 +4 @@\n+\treturn err"}
+	web := ghclient.PRFile{Filename: "web/app.go", Status: "modified", Additions: 1, Patch: "@@ -3,0 +4 @@\n+\treturn err"}
+
+	tests := []struct {
+		name         string
+		files        []ghclient.PRFile
+		wantEvent    string
+		wantChannels string
+	}{
+		{name: "routed directory", files: []ghclient.PRFile{payments, web}, wantEvent: "REQUEST_CHANGES", wantChannels: "payments"},
+		{name: "elsewhere", files: []ghclient.PRFile{web}, wantEvent: "COMMENT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ghMock := &mockGitHubClient{
+				fileContents: map[string]string{
+					".prmate.md":                  prmateMD,
+					"services/payments/PRMATE.md": "## Learned Rules\n- Amounts are integer cents\n",
+				},
+				prFiles: tt.files,
+			}
+			llmMock := &modelLLM{prompts: map[string]string{}}
+
+			result, err := NewService(ghMock, llmMock, Config{}).ReviewPR(context.Background(), ReviewRequest{
+				Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+			})
+			if err != nil {
+				t.Fatalf("ReviewPR() error = %v", err)
+			}
+
+			if got := ghMock.postedReviews[0].event; got != tt.wantEvent {
+				t.Errorf("event = %s, want %s", got, tt.wantEvent)
+			}
+			if got := strings.Join(result.Channels, ","); got != tt.wantChannels {
+				t.Errorf("Channels = %q, want %q", got, tt.wantChannels)
+			}
+			if p := llmMock.prompts["default"]; !strings.Contains(p, "slog") || strings.Contains(p, "integer cents") {
+				t.Error("files outside the route should be reviewed by the rules of .prmate.md")
+			}
+			if len(tt.files) == 2 {
+				if p := llmMock.prompts["gpt-4o"]; !strings.Contains(p, "integer cents") || strings.Contains(p, "slog") {
+					t.Error("files under the route should be reviewed by its rules file with its model")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package review
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"prmate/internal/logging"
+)
+
+// maxCachedRules bounds the rules kept in memory; refs past it push out the
+// oldest
+const maxCachedRules = 1000
+
+// rulesCache keeps the rules loaded from .prmate.md by repository and ref,
+// so the events of a pull request do not each fetch and parse them again.
+// Refs are branches, which move, so entries expire after a TTL and are
+// dropped sooner when a push changes a file they were read from.
+type rulesCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]rulesEntry // by owner/repo@ref
+}
+
+// rulesEntry is the outcome of loading the rules at one ref: the rules, or
+// the error telling the ref has no .prmate.md
+type rulesEntry struct {
+	rules    Rules
+	err      error
+	files    []string // files the rules were read from
+	loadedAt time.Time
+}
+
+func newRulesCache(ttl time.Duration) *rulesCache {
+	return &rulesCache{ttl: ttl, entries: make(map[string]rulesEntry)}
+}
+
+func rulesKey(owner, repo, ref string) string {
+	return owner + "/" + repo + "@" + ref
+}
+
+// get returns the entry cached for ref, if it has not expired
+func (c *rulesCache) get(owner, repo, ref string) (rulesEntry, bool) {
+	if c == nil {
+		return rulesEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[rulesKey(owner, repo, ref)]
+	if !ok || time.Since(e.loadedAt) >= c.ttl {
+		return rulesEntry{}, false
+	}
+	return e, true
+}
+
+// put caches e for ref
+func (c *rulesCache) put(owner, repo, ref string, e rulesEntry) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCachedRules {
+		oldest := ""
+		for key, cached := range c.entries {
+			if oldest == "" || cached.loadedAt.Before(c.entries[oldest].loadedAt) {
+				oldest = key
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	e.loadedAt = time.Now()
+	c.entries[rulesKey(owner, repo, ref)] = e
+}
+
+// forget drops the entry of ref when paths include a file it was read from,
+// or whatever paths are when they are nil
+func (c *rulesCache) forget(owner, repo, ref string, paths []string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := rulesKey(owner, repo, ref)
+	e, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	if paths != nil && !slices.ContainsFunc(e.files, func(f string) bool { return slices.Contains(paths, f) }) {
+		return false
+	}
+	delete(c.entries, key)
+	return true
+}
+
+// SetRulesCacheTTL keeps the rules loaded from .prmate.md for ttl, by
+// repository and ref. ForgetRules drops them sooner.
+func (s *Service) SetRulesCacheTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		s.rulesCache = nil
+		return
+	}
+	s.rulesCache = newRulesCache(ttl)
+}
+
+// ForgetRules drops the rules cached for ref when paths, the files a push
+// to it changed, include a file they were read from. Nil paths drop them
+// whatever the push changed, as after a forced push.
+func (s *Service) ForgetRules(ctx context.Context, owner, repo, ref string, paths []string) {
+	if s.rulesCache.forget(owner, repo, ref, paths) {
+		logging.FromContext(ctx).Debug("Dropped cached rules", "ref", ref)
+	}
+}
+
+// rulesFiles returns the files rules were read from: .prmate.md and the
+// rules files of its routes
+func rulesFiles(rules Rules) []string {
+	files := []string{".prmate.md"}
+	if rules.Checks != nil {
+		for _, route := range rules.Checks.Routes {
+			if route.Rules != "" {
+				files = append(files, route.Rules)
+			}
+		}
+	}
+	return files
+}
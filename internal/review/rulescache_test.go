@@ -0,0 +1,97 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"prmate/internal/errclass"
+)
+
+// countingGitHub counts the files fetched and has no files at the ref "bare"
+type countingGitHub struct {
+	*mockGitHubClient
+	fetched []string
+}
+
+func (c *countingGitHub) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	c.fetched = append(c.fetched, path+"@"+ref)
+	if ref == "bare" {
+		return "", errclass.ErrNotFound
+	}
+	return c.mockGitHubClient.GetFileContent(ctx, owner, repo, path, ref)
+}
+
+func TestService_RulesCache(t *testing.T) {
+	gh := &countingGitHub{mockGitHubClient: &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md":             "# PRMate Context\n\n## Learned Rules\n- Wrap errors\n\n```prmate-checks\nroute: services/pay\nrules: services/pay/PRMATE.md\n```\n",
+			"services/pay/PRMATE.md": "## Learned Rules\n- Use decimal amounts\n",
+		},
+	}}
+	svc := NewService(gh, &mockLLMProvider{}, Config{})
+	svc.SetRulesCacheTTL(time.Hour)
+	ctx := context.Background()
+	fetches := func(want string) {
+		t.Helper()
+		if got := strings.Join(gh.fetched, " "); got != want {
+			t.Errorf("fetched %q, want %q", got, want)
+		}
+		gh.fetched = nil
+	}
+
+	for range 2 {
+		rules, err := svc.loadRules(ctx, "o", "r", "main")
+		if err != nil || len(rules.Routes["services/pay"].Rules) != 1 {
+			t.Fatalf("loadRules() = %+v, %v; want the route rules", rules, err)
+		}
+	}
+	if !svc.HasPRMateFile(ctx, "o", "r", "main") {
+		t.Error("HasPRMateFile(main) = false")
+	}
+	fetches(".prmate.md@main services/pay/PRMATE.md@main")
+
+	// Pushes drop the rules when they change a file the rules come from
+	svc.ForgetRules(ctx, "o", "r", "main", []string{"main.go"})
+	svc.ForgetRules(ctx, "o", "r", "feature", nil)
+	svc.loadRules(ctx, "o", "r", "main")
+	fetches("")
+	svc.ForgetRules(ctx, "o", "r", "main", []string{"services/pay/PRMATE.md"})
+	svc.loadRules(ctx, "o", "r", "main")
+	fetches(".prmate.md@main services/pay/PRMATE.md@main")
+
+	// A missing .prmate.md is remembered too
+	for range 2 {
+		if svc.HasPRMateFile(ctx, "o", "r", "bare") {
+			t.Error("HasPRMateFile(bare) = true")
+		}
+	}
+	if _, err := svc.loadRules(ctx, "o", "r", "bare"); err == nil {
+		t.Error("loadRules(bare) error = nil")
+	}
+	fetches(".prmate.md@bare")
+	svc.ForgetRules(ctx, "o", "r", "bare", nil)
+	svc.HasPRMateFile(ctx, "o", "r", "bare")
+	fetches(".prmate.md@bare")
+
+	// Without a TTL nothing is cached
+	svc.SetRulesCacheTTL(0)
+	svc.loadRules(ctx, "o", "r", "main")
+	svc.loadRules(ctx, "o", "r", "main")
+	fetches(".prmate.md@main services/pay/PRMATE.md@main .prmate.md@main services/pay/PRMATE.md@main")
+}
+
+func TestRulesCache_Expires(t *testing.T) {
+	c := newRulesCache(time.Minute)
+	c.put("o", "r", "main", rulesEntry{files: []string{".prmate.md"}})
+	if _, ok := c.get("o", "r", "main"); !ok {
+		t.Fatal("get() found nothing right after put()")
+	}
+	e := c.entries[rulesKey("o", "r", "main")]
+	e.loadedAt = time.Now().Add(-time.Hour)
+	c.entries[rulesKey("o", "r", "main")] = e
+	if _, ok := c.get("o", "r", "main"); ok {
+		t.Error("get() found an expired entry")
+	}
+}
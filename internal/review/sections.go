@@ -0,0 +1,96 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sectionMarkerPrefix starts the marker declaring what a section of
+// .prmate.md holds, whatever its heading says:
+//
+//	## Payments
+//	<!-- prmate-section: rules -->
+//
+// Kinds are rules, checklist, infra and context, several separated by
+// commas. Sections without a marker are recognized by heading keywords.
+const sectionMarkerPrefix = "<!-- prmate-section:"
+
+// sectionKinds says what a review takes from a section
+type sectionKinds struct {
+	rules     bool // bullet points are rules
+	checklist bool // checkbox items are checklist items
+	infra     bool // bullet points are infrastructure conventions
+	context   bool // the section is codebase context for the LLM
+}
+
+// kindsOf returns what section holds, from its marker or else its heading,
+// and its content without the marker
+func kindsOf(section markdownSection) (sectionKinds, string, error) {
+	var kinds sectionKinds
+	var kept []string
+	marked := false
+	for _, line := range strings.Split(section.Content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, sectionMarkerPrefix) || !strings.HasSuffix(trimmed, "-->") {
+			kept = append(kept, line)
+			continue
+		}
+		marked = true
+		list := strings.TrimSuffix(strings.TrimPrefix(trimmed, sectionMarkerPrefix), "-->")
+		for _, kind := range strings.Split(list, ",") {
+			switch strings.ToLower(strings.TrimSpace(kind)) {
+			case "rules":
+				kinds.rules = true
+			case "checklist":
+				kinds.checklist = true
+			case "infra":
+				kinds.infra = true
+			case "context":
+				kinds.context = true
+			default:
+				return sectionKinds{}, "", fmt.Errorf("section %q: unknown prmate-section kind %q: use rules, checklist, infra or context", section.Title, strings.TrimSpace(kind))
+			}
+		}
+	}
+	content := strings.TrimSpace(strings.Join(kept, "\n"))
+	if marked {
+		return kinds, content, nil
+	}
+
+	title := strings.ToLower(section.Title)
+	kinds.checklist = strings.Contains(title, "checklist") || strings.Contains(title, "review")
+	// Infrastructure conventions only apply to infrastructure files
+	if strings.Contains(title, "infrastructure") {
+		kinds.infra = true
+		return kinds, content, nil
+	}
+	kinds.rules = strings.Contains(title, "rule") || strings.Contains(title, "convention")
+	kinds.context = strings.Contains(title, "structure") ||
+		strings.Contains(title, "abstraction") ||
+		strings.Contains(title, "naming") ||
+		strings.Contains(title, "error")
+	return kinds, content, nil
+}
+
+// unrecognized returns the warning for .prmate.md content that is not
+// blank yet gives a review nothing to check, naming the sections with
+// bullet points that were skipped
+func unrecognized(content string, rules Rules, skipped []string) string {
+	if strings.TrimSpace(content) == "" || rules.count() > 0 || (rules.Checks != nil && len(rules.Checks.Routes) > 0) {
+		return ""
+	}
+	warning := "No rules, checklist items or checks were recognized in `.prmate.md`."
+	if len(skipped) > 0 {
+		warning += fmt.Sprintf(" Skipped sections: %s.", quoteAll(skipped))
+	}
+	return warning + " Put rules under a heading naming them, such as `## Rules`, or mark their section with `" + sectionMarkerPrefix + " rules -->`."
+}
+
+// quoteAll lists titles as inline code, separated by commas
+func quoteAll(titles []string) string {
+	quoted := make([]string, len(titles))
+	for i, t := range titles {
+		quoted[i] = "`" + t + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,110 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+func TestParseRules_Sections(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantRules     []string
+		wantChecklist []string
+		wantInfra     []string
+		wantContext   string
+		wantWarning   string
+		wantErr       string
+	}{
+		{
+			name:      "heading keywords",
+			content:   "## Learned Rules\n- Wrap returned errors\n\n## Review Checklist\n- [ ] Tests cover the change\n",
+			wantRules: []string{"Wrap returned errors"}, wantChecklist: []string{"Tests cover the change"},
+		},
+		{
+			name:      "marker under any heading",
+			content:   "## Payments\n<!-- prmate-section: rules -->\n\n- Use decimal amounts\n\n## Before merging\n<!-- prmate-section: checklist -->\n- [ ] Migrations are reversible\n",
+			wantRules: []string{"Use decimal amounts"}, wantChecklist: []string{"Migrations are reversible"},
+		},
+		{
+			name:        "marker overrides keywords",
+			content:     "## Naming Conventions\n<!-- prmate-section: context -->\n- Handlers end in Handler\n\n## Payments\n<!-- prmate-section: rules -->\n- Use decimal amounts\n",
+			wantRules:   []string{"Use decimal amounts"},
+			wantContext: "\n## Naming Conventions\n- Handlers end in Handler\n",
+		},
+		{
+			name:      "several kinds",
+			content:   "## Containers\n<!-- prmate-section: infra, context -->\n- Run images as a non-root user\n",
+			wantInfra: []string{"Run images as a non-root user"}, wantContext: "\n## Containers\n- Run images as a non-root user\n",
+		},
+		{
+			name:        "nothing recognized",
+			content:     "# PRMate Context\n\n## Guidelines\n- Wrap returned errors\n\n## Style\n- Keep functions short\n",
+			wantWarning: "Skipped sections: `Guidelines`, `Style`.",
+		},
+		{
+			name:    "blank",
+			content: "\n",
+		},
+		{
+			name:    "unknown kind",
+			content: "## Payments\n<!-- prmate-section: rulez -->\n- Use decimal amounts\n",
+			wantErr: `unknown prmate-section kind "rulez"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseRules(tt.content)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseRules() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRules() error = %v", err)
+			}
+			if strings.Join(rules.Rules, "|") != strings.Join(tt.wantRules, "|") {
+				t.Errorf("Rules = %q, want %q", rules.Rules, tt.wantRules)
+			}
+			if strings.Join(rules.Checklist, "|") != strings.Join(tt.wantChecklist, "|") {
+				t.Errorf("Checklist = %q, want %q", rules.Checklist, tt.wantChecklist)
+			}
+			if strings.Join(rules.Infra, "|") != strings.Join(tt.wantInfra, "|") {
+				t.Errorf("Infra = %q, want %q", rules.Infra, tt.wantInfra)
+			}
+			if rules.CodebaseInfo != tt.wantContext {
+				t.Errorf("CodebaseInfo = %q, want %q", rules.CodebaseInfo, tt.wantContext)
+			}
+			if tt.wantWarning == "" && rules.Warning != "" || !strings.Contains(rules.Warning, tt.wantWarning) {
+				t.Errorf("Warning = %q, want %q", rules.Warning, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestReviewPR_WarnsOfUnrecognizedRules(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Guidelines\n- Wrap returned errors with context\n",
+		},
+		prFiles: []ghclient.PRFile{
+			{Filename: "main.go", Status: "modified", Patch: "@@ -0,0 +1 @@\n+package main"},
+		},
+	}
+	svc := NewService(ghMock, &mockLLMProvider{response: `{"violations": []}`}, Config{SecretScan: true})
+	if _, err := svc.ReviewPR(context.Background(), ReviewRequest{Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ghMock.postedComments) != 1 {
+		t.Fatalf("expected a summary comment, got %d comments", len(ghMock.postedComments))
+	}
+	for _, want := range []string{"No rules, checklist items or checks were recognized in `.prmate.md`.", "Skipped sections: `Guidelines`."} {
+		if !strings.Contains(ghMock.postedComments[0], want) {
+			t.Errorf("summary comment missing %q:\n%s", want, ghMock.postedComments[0])
+		}
+	}
+}
@@ -3,20 +3,36 @@ package review
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"regexp"
+	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	"prmate/internal/breaker"
+	"prmate/internal/checks"
+	"prmate/internal/errclass"
+	"prmate/internal/errreport"
 	ghclient "prmate/internal/github"
+	"prmate/internal/logging"
 	"prmate/internal/scanner"
+	"prmate/internal/secrets"
+	"prmate/internal/state"
+	"prmate/internal/tracing"
+	"prmate/internal/version"
 )
 
 const (
 	summaryMarkerPrefix = "<!-- prmate-review-summary:"
 	summaryMarkerSuffix = " -->"
-	summaryVersion      = "1.0"
+	summaryDataPrefix   = "<!-- prmate-data:" // the summary as JSON follows, up to summaryMarkerSuffix
+	summaryVersion      = deltaVersion
+	maxOpenFindings     = 50 // keeps the hidden summary data well within GitHub's comment size limit
 )
 
 // GitHubClient defines the GitHub operations needed for reviews
@@ -24,15 +40,34 @@ type GitHubClient interface {
 	GetPullRequest(ctx context.Context, owner, repo string, prNumber int) (*ghclient.PullRequest, error)
 	GetPRFiles(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.PRFile, error)
 	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
-	ListPRComments(ctx context.Context, owner, repo string, prNumber int) ([]string, error)
+	GetFileSize(ctx context.Context, owner, repo, path, ref string) (int64, error)
+	ListDirectory(ctx context.Context, owner, repo, path, ref string) ([]string, error)
 	ListReviewComments(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.ReviewComment, error)
+	ListReviewThreads(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.ReviewThread, error)
 	CreatePullRequestReview(ctx context.Context, owner, repo string, prNumber int, commitID string, event string, body string, comments []ghclient.DraftReviewComment) error
 	CreatePRComment(ctx context.Context, owner, repo string, prNumber int, body string) error
+	FindPRComment(ctx context.Context, owner, repo string, prNumber int, marker string) (int64, string, error)
+	UpdatePRComment(ctx context.Context, owner, repo string, prNumber int, commentID int64, body string) error
+	DryRun(owner, repo string) bool
+}
+
+// ContentSource reads repository files at a ref; GitHubClient satisfies it,
+// as does LocalRepo for reviews of a local checkout
+type ContentSource interface {
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
+	GetFileSize(ctx context.Context, owner, repo, path, ref string) (int64, error)
+	ListDirectory(ctx context.Context, owner, repo, path, ref string) ([]string, error)
 }
 
 // LLMProvider defines the LLM operations needed for analysis
 type LLMProvider interface {
-	GenerateText(prompt string) (string, error)
+	GenerateTextWithContext(ctx context.Context, prompt string) (string, error)
+}
+
+// Load reports whether the server is under enough load for reviews to
+// trade fidelity for speed
+type Load interface {
+	Degraded() bool
 }
 
 // InstructionsReader defines the interface for reading instruction files
@@ -41,43 +76,102 @@ type InstructionsReader interface {
 	ExtractRulesFromInstructions(instructions []scanner.InstructionFile) []string
 }
 
+// Config holds tunables for the review service
+type Config struct {
+	LLMTimeout       time.Duration // per file analysis call; 0 means no limit
+	ReviewTimeout    time.Duration // whole ReviewPR call; 0 means no limit
+	Offline          bool          // run deterministic checks only; the LLM is never called
+	Branding         *Branding     // comment wording; nil uses DefaultBranding(true)
+	SecretScan       bool          // flag credentials on added lines and keep them out of LLM prompts
+	WorkflowScan     bool          // check changed GitHub Actions workflows for security problems
+	Instructions     string        // extra reviewer instructions added to every analysis prompt
+	Persona          string        // reviewer persona for repositories not choosing one; empty is the default reviewer
+	Concurrency      int           // files analyzed at once; 0 analyzes one at a time
+	ProgressInterval time.Duration // how often a long review shows its progress on the summary comment; 0 never
+}
+
 // Service performs PR reviews based on .prmate.md rules
 type Service struct {
 	githubClient GitHubClient
+	content      ContentSource
 	llmProvider  LLMProvider
 	instReader   *scanner.InstructionsReader
+	config       Config
+	load         Load
+	state        state.Store
+	rulesCache   *rulesCache
+
+	explainMu sync.Mutex
+	explained map[string]string // rule explanations by explanationKey
+
+	foundMu sync.Mutex
+	found   func(FileViolation) // see SetFindingHandler
 }
 
 // NewService creates a new review service
-func NewService(gh GitHubClient, llm LLMProvider) *Service {
+func NewService(gh GitHubClient, llm LLMProvider, cfg Config) *Service {
 	return &Service{
 		githubClient: gh,
+		content:      gh,
 		llmProvider:  llm,
 		instReader:   scanner.NewInstructionsReader(),
+		config:       cfg,
 	}
 }
 
+// NewLocalService creates a service that reads files from src and never
+// talks to GitHub; only ReviewFiles may be used on it
+func NewLocalService(src ContentSource, llm LLMProvider, cfg Config) *Service {
+	return &Service{
+		content:     src,
+		llmProvider: llm,
+		instReader:  scanner.NewInstructionsReader(),
+		config:      cfg,
+	}
+}
+
+// SetLoad skips dependency context for low-risk files while l reports the
+// server degraded
+func (s *Service) SetLoad(l Load) {
+	s.load = l
+}
+
 // ReviewPR performs a complete review of a pull request
-func (s *Service) ReviewPR(ctx context.Context, req ReviewRequest) (*ReviewResult, error) {
-	log.Printf("Starting review for %s/%s PR #%d (commit: %s)", req.Owner, req.Repo, req.PRNumber, req.HeadSHA[:7])
+func (s *Service) ReviewPR(ctx context.Context, req ReviewRequest) (result *ReviewResult, err error) {
+	ctx, span := tracing.Start(ctx, "review.pr",
+		attribute.String("github.repo", req.Owner+"/"+req.Repo),
+		attribute.Int("github.pr", req.PRNumber),
+		attribute.String("github.head_sha", req.HeadSHA),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := withOptionalTimeout(ctx, s.config.ReviewTimeout)
+	defer cancel()
+
+	ctx = logging.With(ctx, "head_sha", req.HeadSHA)
+	logger := logging.FromContext(ctx)
+	logger.Info("Starting review")
 
 	// 1. Load rules from .prmate.md
-	rules, checklist, codebaseInfo, err := s.loadRules(ctx, req.Owner, req.Repo, req.HeadRef)
+	rules, err := s.loadRules(ctx, req.Owner, req.Repo, req.configRef())
 	if err != nil {
 		return nil, fmt.Errorf("load rules: %w", err)
 	}
 
-	if len(rules) == 0 && len(checklist) == 0 {
-		log.Printf("No rules found in .prmate.md, skipping review")
+	if rules.Warning != "" {
+		logger.Warn("no rules recognized in .prmate.md", "warning", rules.Warning)
+	}
+	if !s.hasWork(req, rules) {
+		logger.Info("No rules found in .prmate.md, skipping review")
 		return &ReviewResult{ReviewedCommit: req.HeadSHA}, nil
 	}
 
-	log.Printf("Loaded %d rules and %d checklist items", len(rules), len(checklist))
+	logger.Info("Loaded rules", "rules", len(rules.Rules), "checklist_items", len(rules.Checklist), "checks", len(rules.Checks.Checks), "offline", s.offline(req))
 
 	// 2. Get previous review summary to identify already-reviewed files
 	previousSummary, err := s.getPreviousSummary(ctx, req.Owner, req.Repo, req.PRNumber)
 	if err != nil {
-		log.Printf("Warning: could not get previous summary: %v", err)
+		logger.Warn("could not get previous summary", "error", err)
 	}
 
 	// 3. Get changed files
@@ -87,55 +181,97 @@ func (s *Service) ReviewPR(ctx context.Context, req ReviewRequest) (*ReviewResul
 	}
 
 	// 4. Filter files to review (skip already reviewed unchanged files)
-	filesToReview := s.filterFilesToReview(files, previousSummary, req.HeadSHA)
-	log.Printf("Reviewing %d of %d changed files", len(filesToReview), len(files))
-
-	// 5. Analyze each file
-	var allViolations []FileViolation
-	fileStatuses := make([]FileReviewStatus, 0, len(filesToReview))
-
-	for _, file := range filesToReview {
-		if file.Status == "removed" {
-			continue // Skip deleted files
-		}
-
-		violations, err := s.analyzeFile(ctx, req, file, rules, checklist, codebaseInfo)
-		if err != nil {
-			log.Printf("Warning: failed to analyze %s: %v", file.Filename, err)
-			continue
-		}
+	filesToReview := files
+	if !req.Full {
+		filesToReview = s.filterFilesToReview(files, previousSummary, req.HeadSHA)
+	}
+	filesToReview = withoutIgnored(filesToReview, rules.Checks)
+	logger.Info("Reviewing changed files", "to_review", len(filesToReview), "changed", len(files))
 
-		allViolations = append(allViolations, violations...)
-		fileStatuses = append(fileStatuses, FileReviewStatus{
-			Path:       file.Filename,
-			LastSHA:    req.HeadSHA,
-			Violations: len(violations),
-			ReviewedAt: time.Now().Format(time.RFC3339),
-		})
+	// 5. Analyze each file, resuming after those an earlier review of this
+	// commit finished
+	prog := s.startProgress(ctx, req, filesToReview)
+	allViolations, fileStatuses, tokensUsed, err := s.analyzeFiles(ctx, req, filesToReview, rules, prog)
+	if err != nil {
+		prog.stop(ctx)
+		return nil, err
+	}
+	allViolations = append(allViolations, docDrift(rules.Checks, filesToReview, files, fileStatuses)...)
+	allViolations = append(allViolations, smokeViolations(req.Smoke, withoutIgnored(files, rules.Checks), fileStatuses)...)
+	escalateProtected(rules.Checks, allViolations)
+	allViolations, exempted := exempt(allViolations, fileStatuses, req.Exemptions)
+	if exempted > 0 {
+		logger.Info("Suppressed exempted findings", "exempted", exempted, "exemptions", len(req.Exemptions))
+	}
+	var ignored []Suppression
+	if previousSummary != nil {
+		ignored = previousSummary.Suppressions
+	}
+	allViolations, suppressed := suppress(allViolations, fileStatuses, files, rules.Checks, ignored)
+	if suppressed > 0 {
+		logger.Info("Suppressed findings", "suppressed", suppressed, "ignored", len(ignored))
 	}
 
-	// 6. Post review with comments
+	// 6. Post review with comments, explaining the rules that have an ID
 	var commentsPosted int
+	_, muted := req.Preferences.commented(allViolations)
 	if len(allViolations) > 0 {
-		commentsPosted, err = s.postReviewComments(ctx, req, allViolations)
+		tokensUsed += s.explainFindings(ctx, req, rules, allViolations)
+		commentsPosted, err = s.postReviewComments(ctx, req, allViolations, rules.Checks)
 		if err != nil {
-			log.Printf("Warning: failed to post review comments: %v", err)
+			logger.Warn("failed to post review comments", "error", err)
 		}
 	}
 
-	// 7. Post summary
+	// 7. Follow up on earlier findings: replies, resolved threads and
+	// findings still open across pushes
+	openFindings := carryOpenFindings(previousSummary, files, fileStatuses, allViolations)
+	var outstanding []Outstanding
+	var followUpCounts *FollowUp
+	threads, err := s.githubClient.ListReviewThreads(ctx, req.Owner, req.Repo, req.PRNumber)
+	if err != nil {
+		logger.Warn("could not list review threads", "error", err)
+	} else {
+		var counts FollowUp
+		settleIgnored(threads, ignored)
+		outstanding, counts, openFindings = followUp(threads, openFindings)
+		followUpCounts = &counts
+	}
+
+	// 8. Post summary, with what changed since the previous one
+	findings := trackFindings(previousSummary, files, fileStatuses, allViolations)
+	var delta *Delta
+	if previousSummary.tracksFindings() {
+		delta = diffFindings(previousSummary, findings)
+	}
 	summary := ReviewSummary{
 		Version:         summaryVersion,
 		LastReviewedAt:  time.Now(),
 		HeadSHA:         req.HeadSHA,
 		FilesScanned:    fileStatuses,
-		RulesApplied:    len(rules) + len(checklist),
+		RulesApplied:    rules.count(),
 		ViolationsFound: len(allViolations),
+		OpenFindings:    openFindings,
+		FollowUp:        followUpCounts,
+		Findings:        findings,
+		Delta:           delta,
+		Exempted:        exempted,
+		Suppressed:      suppressed,
+		Suppressions:    ignored,
+		Muted:           muted,
+		Overlaps:        overlaps(req.OtherPRs, files),
+		RulesWarning:    rules.Warning,
 	}
 
-	if err := s.postSummary(ctx, req, summary); err != nil {
-		log.Printf("Warning: failed to post summary: %v", err)
+	if err := s.postSummary(ctx, req, summary, outstanding); err != nil {
+		logger.Warn("failed to post summary", "error", err)
 	}
+	s.saveState(ctx, req, summary)
+	span.SetAttributes(
+		attribute.Int("review.files", len(filesToReview)),
+		attribute.Int("review.violations", len(allViolations)),
+		attribute.Int("review.tokens", tokensUsed),
+	)
 
 	return &ReviewResult{
 		FilesReviewed:   len(filesToReview),
@@ -143,59 +279,480 @@ func (s *Service) ReviewPR(ctx context.Context, req ReviewRequest) (*ReviewResul
 		ViolationsFound: len(allViolations),
 		SummaryPosted:   true,
 		ReviewedCommit:  req.HeadSHA,
+		Violations:      allViolations,
+		EstimatedTokens: tokensUsed,
+		Channels:        routeChannels(rules.Checks, files),
+		Files:           filenames(files),
+		Blocking:        blocks(rules.Checks, allViolations),
 	}, nil
 }
 
-// loadRules fetches and parses .prmate.md from the repository
-func (s *Service) loadRules(ctx context.Context, owner, repo, ref string) (rules []string, checklist []string, codebaseInfo string, err error) {
-	content, err := s.githubClient.GetFileContent(ctx, owner, repo, ".prmate.md", ref)
+// ReviewFiles runs the analysis pipeline over files without reading or
+// writing anything on GitHub. Rules come from .prmate.md at req.HeadRef,
+// or req.ConfigRef when set.
+func (s *Service) ReviewFiles(ctx context.Context, req ReviewRequest, files []ghclient.PRFile) (*ReviewResult, error) {
+	rules, err := s.loadRules(ctx, req.Owner, req.Repo, req.configRef())
+	if err != nil {
+		return nil, fmt.Errorf("load rules: %w", err)
+	}
+	if !s.hasWork(req, rules) {
+		return &ReviewResult{ReviewedCommit: req.HeadSHA}, nil
+	}
+
+	reviewed := withoutIgnored(files, rules.Checks)
+	violations, statuses, tokens, err := s.analyzeFiles(ctx, req, reviewed, rules, nil)
+	if err != nil {
+		return nil, err
+	}
+	drift := docDrift(rules.Checks, reviewed, files, statuses)
+	for _, v := range drift {
+		s.emit(rules.Checks, v)
+	}
+	violations = append(violations, drift...)
+	escalateProtected(rules.Checks, violations)
+
+	return &ReviewResult{
+		FilesReviewed:   len(statuses),
+		ViolationsFound: len(violations),
+		ReviewedCommit:  req.HeadSHA,
+		Violations:      violations,
+		EstimatedTokens: tokens,
+	}, nil
+}
+
+// offline reports whether req is reviewed without the LLM
+func (s *Service) offline(req ReviewRequest) bool {
+	return s.config.Offline || req.ChecksOnly
+}
+
+// hasWork reports whether rules give the review anything to check
+func (s *Service) hasWork(req ReviewRequest, rules Rules) bool {
+	if s.config.SecretScan || s.config.WorkflowScan || len(req.Smoke) > 0 {
+		return true
+	}
+	if s.offline(req) {
+		return !rules.Checks.Empty()
+	}
+	return rules.count() > 0
+}
+
+// analyzeFiles runs the deterministic checks and, unless offline, the LLM
+// analysis on each changed file, skipping deleted files and files whose
+// analysis fails. Up to Config.Concurrency files are analyzed at once; the
+// findings keep the order of files. prog, when not nil, checkpoints each
+// file and supplies the files an earlier review finished.
+func (s *Service) analyzeFiles(ctx context.Context, req ReviewRequest, files []ghclient.PRFile, rules Rules, prog *progress) (_ []FileViolation, _ []FileReviewStatus, _ int, err error) {
+	ctx, span := tracing.Start(ctx, "review.analyze_files",
+		attribute.Int("review.files", len(files)),
+		attribute.Int("review.concurrency", max(s.config.Concurrency, 1)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// The files of a pull request mostly share their module and imports
+	ctx = withModuleCache(ctx)
+
+	results := make([]fileReview, len(files))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		abortErr error
+	)
+	abort := func(err error) {
+		mu.Lock()
+		if abortErr == nil {
+			abortErr = err
+		}
+		mu.Unlock()
+		// The files still under analysis stop too
+		cancel()
+	}
+	workers := make(chan struct{}, max(s.config.Concurrency, 1))
+	for i, file := range files {
+		if file.Status == "removed" {
+			continue // Skip deleted files
+		}
+		if r, ok := prog.resume(file.Filename); ok {
+			results[i] = r
+			continue
+		}
+		select {
+		case workers <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+			// A panic aborts the review rather than the whole process
+			defer func() {
+				if r := recover(); r != nil {
+					logging.FromContext(ctx).Error("file analysis panicked", "path", file.Filename, "panic", r, "stack", string(debug.Stack()))
+					errreport.CapturePanic(ctx, r)
+					abort(fmt.Errorf("analyze %s: panic: %v", file.Filename, r))
+				}
+			}()
+			result, err := s.reviewFile(ctx, req, file, rules)
+			results[i] = result
+			if err != nil {
+				abort(err)
+				return
+			}
+			prog.finished(ctx, file.Filename, result)
+		}()
+	}
+	wg.Wait()
+	if abortErr == nil {
+		abortErr = ctx.Err()
+	}
+
+	var allViolations []FileViolation
+	var tokensUsed int
+	fileStatuses := make([]FileReviewStatus, 0, len(files))
+	for i, r := range results {
+		tokensUsed += r.tokens
+		if !r.reviewed {
+			continue
+		}
+		allViolations = append(allViolations, r.violations...)
+		fileStatuses = append(fileStatuses, FileReviewStatus{
+			Path:       files[i].Filename,
+			LastSHA:    req.HeadSHA,
+			Violations: len(r.violations),
+			ReviewedAt: r.reviewedAt.Format(time.RFC3339),
+		})
+	}
+	if abortErr != nil {
+		return nil, nil, tokensUsed, fmt.Errorf("review aborted after %d files: %w", len(fileStatuses), abortErr)
+	}
+
+	return allViolations, fileStatuses, tokensUsed, nil
+}
+
+// fileReview is the outcome of analyzing one file
+type fileReview struct {
+	violations []FileViolation
+	tokens     int
+	reviewed   bool // false when the analysis failed and the file is skipped
+	reviewedAt time.Time
+}
+
+// reviewFile runs the deterministic checks and, unless offline, the LLM
+// analysis on file. It returns an error when the whole review must stop:
+// reviewing the rest without the LLM would pass them as clean.
+func (s *Service) reviewFile(ctx context.Context, req ReviewRequest, file ghclient.PRFile, rules Rules) (fileReview, error) {
+	logger := logging.FromContext(ctx)
+	var result fileReview
+
+	violations := checkFile(rules.Checks, file)
+	assetViolations, err := s.checkAsset(ctx, req, rules.Checks, file)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("get .prmate.md: %w", err)
+		logger.Warn("failed to apply asset policy", "path", file.Filename, "error", err)
+	}
+	violations = append(violations, assetViolations...)
+	if s.config.SecretScan {
+		violations = append(violations, secretViolations(file)...)
+	}
+	workflowViolations := s.workflowViolations(ctx, req, file)
+	violations = append(violations, workflowViolations...)
+	for _, v := range violations {
+		s.emit(rules.Checks, v)
+	}
+	var found func(FileViolation)
+	if s.streaming() {
+		found = func(v FileViolation) {
+			if len(withoutRepeats([]FileViolation{v}, workflowViolations)) > 0 {
+				s.emit(rules.Checks, v)
+			}
+		}
 	}
+	// A binary has no diff to show the LLM
+	fileRules := rules.forFile(file.Filename)
+	infra := s.infraKind(rules, file.Filename) != ""
+	if !s.offline(req) && !checks.Binary(file) && (infra || len(fileRules.Rules)+len(fileRules.Checklist) > 0) {
+		llmViolations, tokens, err := s.analyzeFile(withRouteModel(ctx, rules.Checks, file.Filename), req, file, fileRules, found)
+		result.tokens = tokens
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return result, ctxErr
+		}
+		if errors.Is(err, breaker.ErrOpen) || errors.Is(err, errclass.ErrRateLimited) {
+			return result, err
+		}
+		if err != nil {
+			logger.Warn("failed to analyze file", "path", file.Filename, "error", err)
+			return result, nil
+		}
+		violations = append(violations, withoutRepeats(llmViolations, workflowViolations)...)
+	}
+
+	result.violations = violations
+	result.reviewed = true
+	result.reviewedAt = time.Now()
+	return result, nil
+}
+
+// loadRules returns the rules of .prmate.md at ref, from the rules cache
+// when it has them. A missing .prmate.md is cached too.
+func (s *Service) loadRules(ctx context.Context, owner, repo, ref string) (_ Rules, err error) {
+	ctx, span := tracing.Start(ctx, "review.load_rules", attribute.String("git.ref", ref))
+	defer func() { tracing.End(span, err) }()
 
-	// Parse the content manually since we have the raw content
+	if e, ok := s.rulesCache.get(owner, repo, ref); ok {
+		span.SetAttributes(attribute.Bool("review.rules_cached", true))
+		return e.rules, e.err
+	}
+	rules, err := s.fetchRules(ctx, owner, repo, ref)
+	if err == nil || errors.Is(err, errclass.ErrNotFound) {
+		s.rulesCache.put(owner, repo, ref, rulesEntry{rules: rules, err: err, files: rulesFiles(rules)})
+	}
+	return rules, err
+}
+
+// fetchRules fetches and parses .prmate.md from the repository
+func (s *Service) fetchRules(ctx context.Context, owner, repo, ref string) (Rules, error) {
+	content, err := s.content.GetFileContent(ctx, owner, repo, ".prmate.md", ref)
+	if err != nil {
+		return Rules{}, fmt.Errorf("get .prmate.md: %w", err)
+	}
+
+	rules, err := ParseRules(content)
+	if err != nil {
+		return Rules{}, err
+	}
+	s.loadRouteRules(ctx, owner, repo, ref, &rules)
+	return rules, nil
+}
+
+// withoutIgnored drops the files the .prmate.md ignore list excludes
+func withoutIgnored(files []ghclient.PRFile, set *checks.Set) []ghclient.PRFile {
+	kept := make([]ghclient.PRFile, 0, len(files))
+	for _, f := range files {
+		if !set.Ignored(f.Filename) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// checkFile runs the deterministic checks on the lines file adds
+func checkFile(set *checks.Set, file ghclient.PRFile) []FileViolation {
+	var violations []FileViolation
+	for _, f := range set.Run(file.Filename, file.Patch) {
+		violations = append(violations, FileViolation{
+			Path:     file.Filename,
+			Line:     f.Line,
+			Rule:     f.Rule,
+			Message:  f.Message,
+			Severity: f.Severity,
+		})
+	}
+	return violations
+}
+
+// docDrift reports the interface changes in files made without updating the
+// documentation, counting them in the statuses of their files. all holds
+// every file of the change.
+func docDrift(set *checks.Set, files, all []ghclient.PRFile, statuses []FileReviewStatus) []FileViolation {
+	var violations []FileViolation
+	for _, f := range set.DocDrift(files, all) {
+		violations = append(violations, FileViolation{
+			Path:     f.Path,
+			Line:     f.Line,
+			Rule:     f.Rule,
+			Message:  f.Message,
+			Severity: f.Severity,
+		})
+		for i := range statuses {
+			if statuses[i].Path == f.Path {
+				statuses[i].Violations++
+			}
+		}
+	}
+	return violations
+}
+
+// escalateProtected makes every finding in a protected path an error, so
+// that the review requests changes
+func escalateProtected(set *checks.Set, violations []FileViolation) {
+	for i := range violations {
+		if set.Protected(violations[i].Path) {
+			violations[i].Severity = "error"
+		}
+	}
+}
+
+// checkAsset applies the .prmate.md asset policy to file. The findings
+// concern the whole file and have no line.
+func (s *Service) checkAsset(ctx context.Context, req ReviewRequest, set *checks.Set, file ghclient.PRFile) ([]FileViolation, error) {
+	findings, err := set.CheckAsset(file, func() (int64, error) {
+		return s.content.GetFileSize(ctx, req.Owner, req.Repo, file.Filename, req.HeadRef)
+	})
+	var violations []FileViolation
+	for _, f := range findings {
+		violations = append(violations, FileViolation{
+			Path:     file.Filename,
+			Rule:     f.Rule,
+			Message:  f.Message,
+			Severity: f.Severity,
+		})
+	}
+	return violations, err
+}
+
+// secretViolations reports the credentials file adds, without their values
+func secretViolations(file ghclient.PRFile) []FileViolation {
+	var violations []FileViolation
+	for _, f := range secrets.ScanPatch(file.Patch) {
+		violations = append(violations, FileViolation{
+			Path:     file.Filename,
+			Line:     f.Line,
+			Rule:     "Hardcoded secret",
+			Message:  fmt.Sprintf("This line appears to add a %s. Remove it and rotate the credential: it stays in the git history even after the line is deleted.", f.Kind),
+			Severity: "error",
+		})
+	}
+	return violations
+}
+
+// ParseRules extracts the learned rules, checklist items, codebase notes and
+// deterministic checks a review uses from the content of a .prmate.md file.
+// Sections are recognized by their prmate-section marker or else heading
+// keywords. It fails only when a prmate-checks block or a marker is
+// malformed.
+func ParseRules(content string) (Rules, error) {
+	persona, content, err := parsePersona(content)
+	if err != nil {
+		return Rules{}, err
+	}
+	set, err := checks.Parse(content)
+	if err != nil {
+		return Rules{}, err
+	}
+
+	parsed := Rules{Checks: set, Persona: persona}
 	sections := parseMarkdownSections(content)
 
+	var skipped []string
 	for _, section := range sections {
-		titleLower := strings.ToLower(section.Title)
+		kinds, body, err := kindsOf(section)
+		if err != nil {
+			return Rules{}, err
+		}
 
 		// Extract checklist items
-		if strings.Contains(titleLower, "checklist") || strings.Contains(titleLower, "review") {
-			checklist = append(checklist, extractChecklistItems(section.Content)...)
+		if kinds.checklist {
+			parsed.Checklist = append(parsed.Checklist, extractChecklistItems(body)...)
+		}
+
+		if kinds.infra {
+			parsed.Infra = append(parsed.Infra, parsed.identify(extractBulletPoints(body))...)
 		}
 
 		// Extract learned rules
-		if strings.Contains(titleLower, "rule") || strings.Contains(titleLower, "convention") {
-			rules = append(rules, extractBulletPoints(section.Content)...)
+		if kinds.rules {
+			parsed.Rules = append(parsed.Rules, parsed.identify(extractBulletPoints(body))...)
 		}
 
 		// Collect codebase info sections
-		if strings.Contains(titleLower, "structure") ||
-			strings.Contains(titleLower, "abstraction") ||
-			strings.Contains(titleLower, "naming") ||
-			strings.Contains(titleLower, "error") {
-			codebaseInfo += fmt.Sprintf("\n## %s\n%s\n", section.Title, section.Content)
+		if kinds.context {
+			parsed.CodebaseInfo += fmt.Sprintf("\n## %s\n%s\n", section.Title, body)
+		}
+
+		if kinds == (sectionKinds{}) && len(extractBulletPoints(body)) > 0 {
+			skipped = append(skipped, section.Title)
 		}
 	}
+	parsed.Warning = unrecognized(content, parsed, skipped)
 
-	return rules, checklist, codebaseInfo, nil
+	return parsed, nil
 }
 
-// getPreviousSummary retrieves the last review summary from PR comments
+// getPreviousSummary retrieves the last review summary from the state
+// store, falling back to the PR comments
 func (s *Service) getPreviousSummary(ctx context.Context, owner, repo string, prNumber int) (*ReviewSummary, error) {
-	comments, err := s.githubClient.ListPRComments(ctx, owner, repo, prNumber)
-	if err != nil {
+	if s.state != nil {
+		summary, err := s.loadState(ctx, owner, repo, prNumber)
+		if err != nil {
+			logging.FromContext(ctx).Warn("could not load review state, reading the summary comment", "error", err)
+		} else if summary != nil {
+			return summary, nil
+		}
+	}
+
+	// Find the latest prmate summary comment; a summary pasted by someone
+	// else never counts
+	commentID, body, err := s.githubClient.FindPRComment(ctx, owner, repo, prNumber, summaryMarkerPrefix)
+	if err != nil || commentID == 0 {
 		return nil, err
 	}
+	return parseSummaryFromComment(body)
+}
 
-	// Find the latest prmate summary comment
-	for i := len(comments) - 1; i >= 0; i-- {
-		if strings.Contains(comments[i], summaryMarkerPrefix) {
-			return parseSummaryFromComment(comments[i])
-		}
+// LastReviewedSHA returns the head commit of the latest review summary
+// posted on the pull request, or "" if it has never been reviewed
+func (s *Service) LastReviewedSHA(ctx context.Context, owner, repo string, prNumber int) (string, error) {
+	summary, err := s.getPreviousSummary(ctx, owner, repo, prNumber)
+	if err != nil || summary == nil {
+		return "", err
 	}
+	return summary.HeadSHA, nil
+}
 
-	return nil, nil
+// OpenFindings returns the error-severity findings the latest review
+// summary on the pull request still carries
+func (s *Service) OpenFindings(ctx context.Context, owner, repo string, prNumber int) ([]OpenFinding, error) {
+	summary, err := s.getPreviousSummary(ctx, owner, repo, prNumber)
+	if err != nil || summary == nil {
+		return nil, err
+	}
+	return summary.OpenFindings, nil
+}
+
+// carryOpenFindings keeps the previous open findings of files that were
+// neither reviewed again nor dropped from the PR, and adds this review's
+// error-severity findings
+func carryOpenFindings(previous *ReviewSummary, files []ghclient.PRFile, reviewed []FileReviewStatus, violations []FileViolation) []OpenFinding {
+	var earlier []OpenFinding
+	if previous != nil {
+		earlier = previous.OpenFindings
+	}
+	open := carryFindings(earlier, files, reviewed, violations, func(v FileViolation) bool { return v.Severity == "error" })
+	if len(open) > maxOpenFindings {
+		open = open[:maxOpenFindings]
+	}
+	return open
+}
+
+// carryFindings keeps the earlier findings of files that were neither
+// reviewed again nor dropped from the PR, and adds the violations keep
+// accepts
+func carryFindings(earlier []OpenFinding, files []ghclient.PRFile, reviewed []FileReviewStatus, violations []FileViolation, keep func(FileViolation) bool) []OpenFinding {
+	inPR := make(map[string]bool, len(files))
+	for _, f := range files {
+		inPR[f.Filename] = f.Status != "removed"
+	}
+	rereviewed := make(map[string]bool, len(reviewed))
+	for _, f := range reviewed {
+		rereviewed[f.Path] = true
+	}
+
+	var carried []OpenFinding
+	for _, f := range earlier {
+		// Smoke checks run on every review and report their findings anew
+		if inPR[f.Path] && !rereviewed[f.Path] && f.Rule != smokeRule {
+			carried = append(carried, f)
+		}
+	}
+	for _, v := range violations {
+		if keep(v) {
+			carried = append(carried, OpenFinding{Path: v.Path, Line: v.Line, Rule: v.Rule, Severity: v.Severity, Message: v.Message})
+		}
+	}
+	return carried
 }
 
 // filterFilesToReview returns files that need review (new or changed since last review)
@@ -223,75 +780,166 @@ func (s *Service) filterFilesToReview(files []ghclient.PRFile, previousSummary *
 	return toReview
 }
 
-// analyzeFile uses LLM to analyze a single file against rules
-func (s *Service) analyzeFile(ctx context.Context, req ReviewRequest, file ghclient.PRFile, rules, checklist []string, codebaseInfo string) ([]FileViolation, error) {
-	// Get full file content for context (if not too large)
+const (
+	// maxContentChanges is the size of a change, in lines added and
+	// removed, up to which the whole file is read for context
+	maxContentChanges = 500
+	// maxPromptContent bounds the file content sent along with the diff
+	maxPromptContent = 10000
+	// lowRiskChanges is the size of a change below which an unprotected
+	// file is reviewed without dependency context under load
+	lowRiskChanges = 100
+)
+
+// analyzeFile uses LLM to analyze a single file against rules and reports
+// the estimated tokens spent on the calls. A large change is analyzed in
+// chunks of its diff, one call each, with the part of the file around each
+// chunk; a finding reported by two overlapping chunks is kept once. found,
+// when not nil, gets each finding as soon as the LLM reports it.
+func (s *Service) analyzeFile(ctx context.Context, req ReviewRequest, file ghclient.PRFile, rules Rules, found func(FileViolation)) (violations []FileViolation, tokens int, err error) {
+	ctx, span := tracing.Start(ctx, "review.analyze_file", attribute.String("file.path", file.Filename))
+	defer func() { tracing.End(span, err) }()
+
+	// Get full file content for context (if not too large). Protected
+	// files are always reviewed with their whole content, and in one call.
+	protected := rules.Checks.Protected(file.Filename)
+	large := !protected && file.Additions+file.Deletions >= maxContentChanges
+	var chunks []diffChunk
+	if large {
+		chunks = chunkPatch(file.Patch)
+	}
 	var fileContent string
-	if file.Additions+file.Deletions < 500 {
-		content, err := s.githubClient.GetFileContent(ctx, req.Owner, req.Repo, file.Filename, req.HeadRef)
+	if !large || len(chunks) > 1 {
+		content, err := s.content.GetFileContent(ctx, req.Owner, req.Repo, file.Filename, req.HeadRef)
 		if err == nil {
 			fileContent = content
 		}
 	}
+	if len(chunks) <= 1 {
+		chunks = []diffChunk{{patch: file.Patch}}
+	}
 
-	// Get dependency context - files that this file imports/references
-	dependencyContext := s.gatherDependencyContext(ctx, req, file.Filename, fileContent)
+	kind := s.infraKind(rules, file.Filename)
+	infra := kind != ""
+	// Get dependency context - files that this file imports/references.
+	// Under load, small changes to unprotected files go without it.
+	var dependencyContext string
+	lowRisk := !protected && file.Additions+file.Deletions < lowRiskChanges
+	if !infra && (s.load == nil || !lowRisk || !s.load.Degraded()) {
+		dependencyContext = s.gatherDependencyContext(ctx, req, file.Filename, fileContent)
+	}
 
-	// Build the analysis prompt with dependency context
-	prompt := s.buildAnalysisPrompt(file.Filename, fileContent, file.Patch, rules, checklist, codebaseInfo, dependencyContext)
+	seen := make(map[string]bool)
+	for _, chunk := range chunks {
+		promptContent := fileContent
+		if len(chunks) > 1 {
+			promptContent = chunk.excerpt(fileContent)
+		}
+		if !protected && len(promptContent) >= maxPromptContent {
+			promptContent = ""
+		}
 
-	// Call LLM
-	response, err := s.llmProvider.GenerateText(prompt)
-	if err != nil {
-		return nil, fmt.Errorf("llm analysis: %w", err)
+		var prompt string
+		if infra {
+			// Infrastructure files are held to the infrastructure conventions
+			prompt = s.buildInfraPrompt(kind, file.Filename, promptContent, chunk.patch, rules.Infra, rules.language(), s.persona(rules))
+		} else {
+			// Build the analysis prompt with dependency context
+			prompt = s.buildAnalysisPrompt(file.Filename, promptContent, chunk.patch, rules.Rules, rules.Checklist, rules.CodebaseInfo, dependencyContext, rules.language(), s.persona(rules))
+		}
+		if s.config.SecretScan {
+			// Secrets are reported by secretViolations; the LLM never sees them
+			prompt = secrets.Redact(prompt)
+		}
+
+		keep := func(v FileViolation) bool {
+			key := fmt.Sprintf("%d:%s", v.Line, v.Rule)
+			if len(chunks) > 1 && seen[key] {
+				return false
+			}
+			seen[key] = true
+			return true
+		}
+		if found != nil {
+			// Findings are passed on as the response streams in
+			response, err := s.streamViolations(ctx, prompt, file.Filename, chunk.patch, func(v FileViolation) {
+				if keep(v) {
+					violations = append(violations, v)
+					found(v)
+				}
+			})
+			tokens += estimateTokens(prompt) + estimateTokens(response)
+			if err != nil {
+				return nil, tokens, fmt.Errorf("llm analysis: %w", err)
+			}
+			continue
+		}
+
+		response, err := s.generate(ctx, prompt)
+		tokens += estimateTokens(prompt) + estimateTokens(response)
+		if err != nil {
+			return nil, tokens, fmt.Errorf("llm analysis: %w", err)
+		}
+
+		// Parse LLM response
+		for _, v := range s.parseLLMResponse(response, file.Filename, chunk.patch) {
+			if keep(v) {
+				violations = append(violations, v)
+			}
+		}
 	}
+	span.SetAttributes(attribute.Int("review.violations", len(violations)), attribute.Int("review.chunks", len(chunks)))
+
+	return violations, tokens, nil
+}
 
-	// Parse LLM response
-	violations := s.parseLLMResponse(response, file.Filename, file.Patch)
+// generate sends one prompt to the LLM, within the per-call timeout
+func (s *Service) generate(ctx context.Context, prompt string) (string, error) {
+	ctx, cancel := withOptionalTimeout(ctx, s.config.LLMTimeout)
+	defer cancel()
+	return s.llmProvider.GenerateTextWithContext(ctx, prompt)
+}
 
-	return violations, nil
+// estimateTokens approximates the token count of text. Providers do not all
+// report usage, so spend is tracked with the common ~4 chars/token heuristic.
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
 }
 
-// gatherDependencyContext fetches content from files that the changed file depends on
+// gatherDependencyContext fetches content from files that the changed file
+// depends on. Of a Go dependency the prompt sees only what the changed file
+// refers to, and a Go dependency declaring none of it is left out.
 func (s *Service) gatherDependencyContext(ctx context.Context, req ReviewRequest, filePath, fileContent string) string {
 	if fileContent == "" {
 		return ""
 	}
+	ctx, span := tracing.Start(ctx, "review.dependencies")
+	defer span.End()
 
-	var dependencies []string
-
-	// Detect language and extract imports
-	ext := getFileExtension(filePath)
-	switch ext {
-	case ".go":
-		dependencies = extractGoImports(fileContent, filePath)
-	case ".ts", ".tsx", ".js", ".jsx":
-		dependencies = extractJSImports(fileContent, filePath)
-	case ".py":
-		dependencies = extractPythonImports(fileContent, filePath)
-	}
-
+	dependencies := ResolveDependencies(ctx, s.content, req.Owner, req.Repo, req.HeadRef, filePath, fileContent)
 	if len(dependencies) == 0 {
 		return ""
 	}
+	used := referencedNames(filePath, fileContent)
 
 	var sb strings.Builder
 	fetchedCount := 0
-	maxDeps := 5 // Limit to avoid token explosion
+	maxDeps := 5   // Limit to avoid token explosion
+	maxReads := 20 // and the files read looking for them
 
-	for _, depPath := range dependencies {
-		if fetchedCount >= maxDeps {
+	for i, depPath := range dependencies {
+		if fetchedCount >= maxDeps || i >= maxReads {
 			break
 		}
 
-		content, err := s.githubClient.GetFileContent(ctx, req.Owner, req.Repo, depPath, req.HeadRef)
+		content, err := s.content.GetFileContent(ctx, req.Owner, req.Repo, depPath, req.HeadRef)
 		if err != nil {
 			continue // File might not exist or be external
 		}
 
-		// Truncate large files to keep prompt reasonable
-		if len(content) > 3000 {
-			content = content[:3000] + "\n// ... (truncated)"
+		content, ok := dependencyExcerpt(depPath, content, used)
+		if !ok {
+			continue
 		}
 
 		sb.WriteString(fmt.Sprintf("\n### %s\n```\n%s\n```\n", depPath, content))
@@ -301,6 +949,21 @@ func (s *Service) gatherDependencyContext(ctx context.Context, req ReviewRequest
 	return sb.String()
 }
 
+// Dependencies lists the repository files that the file at filePath likely
+// depends on, judged from its local imports. Candidates that do not exist
+// are included; callers skip what they cannot read.
+func Dependencies(filePath, fileContent string) []string {
+	switch getFileExtension(filePath) {
+	case ".go":
+		return extractGoImports(fileContent, filePath)
+	case ".ts", ".tsx", ".js", ".jsx":
+		return extractJSImports(fileContent, filePath)
+	case ".py":
+		return extractPythonImports(fileContent, filePath)
+	}
+	return nil
+}
+
 // getFileExtension returns the file extension including the dot
 func getFileExtension(path string) string {
 	for i := len(path) - 1; i >= 0; i-- {
@@ -471,10 +1134,14 @@ func resolveRelativePath(baseDir, relativePath string) string {
 }
 
 // buildAnalysisPrompt constructs the prompt for LLM analysis
-func (s *Service) buildAnalysisPrompt(filePath, fileContent, patch string, rules, checklist []string, codebaseInfo string, dependencyContext string) string {
+func (s *Service) buildAnalysisPrompt(filePath, fileContent, patch string, rules, checklist []string, codebaseInfo string, dependencyContext string, language string, persona persona) string {
 	var sb strings.Builder
 
-	sb.WriteString("You are a senior code reviewer. Analyze the following code changes and identify any violations of the project's coding standards.\n\n")
+	role := persona.role
+	if role == "" {
+		role = "You are a senior code reviewer. Analyze the following code changes and identify any violations of the project's coding standards."
+	}
+	sb.WriteString(role + "\n\n")
 
 	sb.WriteString("## Project Rules and Conventions\n")
 	for i, rule := range rules {
@@ -499,6 +1166,8 @@ func (s *Service) buildAnalysisPrompt(filePath, fileContent, patch string, rules
 		sb.WriteString(dependencyContext)
 	}
 
+	s.writeInstructions(&sb)
+
 	sb.WriteString(fmt.Sprintf("\n## File Being Reviewed: %s\n", filePath))
 
 	if patch != "" {
@@ -507,7 +1176,7 @@ func (s *Service) buildAnalysisPrompt(filePath, fileContent, patch string, rules
 		sb.WriteString("\n```\n")
 	}
 
-	if fileContent != "" && len(fileContent) < 10000 {
+	if fileContent != "" {
 		sb.WriteString("\n### Full File Content\n```\n")
 		sb.WriteString(fileContent)
 		sb.WriteString("\n```\n")
@@ -519,7 +1188,7 @@ Respond with a JSON object containing violations found. Only report violations f
 If no violations are found, return {"violations": []}.
 
 Example response:
-{"violations": [{"line": 42, "rule": "Error Handling", "message": "Error not wrapped with context", "severity": "warning", "fix": "Use fmt.Errorf(\"context: %w\", err)"}]}
+{"violations": [{"line": 42, "rule": "Error Handling", "message": "Error not wrapped with context", "severity": "warning", "fix": "\t\treturn fmt.Errorf(\"load config: %w\", err)"}]}
 
 Important:
 - Only flag clear violations, not style preferences
@@ -527,13 +1196,124 @@ Important:
 - Be specific about what rule is violated and how to fix it
 - Severity: "error" for breaking issues, "warning" for best practices, "suggestion" for improvements
 - Check that the code correctly implements interfaces and follows patterns from the dependency context
+`)
+	sb.WriteString(fixInstructions)
+	if namesRules(rules) {
+		sb.WriteString("- For a rule starting with an ID in square brackets, use the ID without the brackets as the \"rule\"\n")
+	}
+	persona.writeStyle(&sb)
+
+	if language != "" {
+		sb.WriteString(fmt.Sprintf("- Write the \"message\" values in %s. Keep the JSON keys, the \"fix\" code, the \"severity\" values and the \"rule\" names exactly as written above, untranslated\n", languageName(language)))
+	}
+
+	sb.WriteString("\nRespond with ONLY the JSON, no additional text.\n")
+
+	return sb.String()
+}
+
+// infraFocus tells the model what to look for in each kind of
+// infrastructure file
+var infraFocus = map[string]string{
+	scanner.InfraDockerfile: "a Dockerfile. Check base image pinning (FROM), the user the final stage runs as (USER), and secrets passed through ARG or ENV",
+	scanner.InfraCompose:    "a Docker Compose file. Check image pinning (image:), privileged containers, and secrets written inline instead of referenced",
+	scanner.InfraWorkflow:   "a GitHub Actions workflow. Check how actions are pinned (uses:), the permissions granted to GITHUB_TOKEN, and untrusted input such as ${{ github.event.* }} interpolated into run: scripts",
+}
+
+// buildInfraPrompt constructs the prompt for a Dockerfile, compose file or
+// workflow, which is reviewed against the infrastructure conventions instead
+// of the code rules
+func (s *Service) buildInfraPrompt(kind, filePath, fileContent, patch string, conventions []string, language string, persona persona) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are a senior platform engineer reviewing changes to container and CI configuration.\n")
+	sb.WriteString(fmt.Sprintf("The file being reviewed is %s.\n\n", infraFocus[kind]))
+
+	if len(conventions) > 0 {
+		sb.WriteString("## Infrastructure Conventions\n")
+		sb.WriteString("The repository's existing infrastructure files follow these conventions. Changed lines must follow them too:\n")
+		for i, convention := range conventions {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, convention))
+		}
+	}
+	if kind == scanner.InfraWorkflow && s.config.WorkflowScan {
+		writeWorkflowSecurity(&sb)
+	}
 
-Respond with ONLY the JSON, no additional text.
+	s.writeInstructions(&sb)
+
+	sb.WriteString(fmt.Sprintf("\n## File Being Reviewed: %s\n", filePath))
+
+	if patch != "" {
+		sb.WriteString("\n### Changes (Diff)\n```diff\n")
+		sb.WriteString(patch)
+		sb.WriteString("\n```\n")
+	}
+
+	if fileContent != "" {
+		sb.WriteString("\n### Full File Content\n```\n")
+		sb.WriteString(fileContent)
+		sb.WriteString("\n```\n")
+	}
+
+	sb.WriteString(`
+## Response Format
+Respond with a JSON object containing violations found. Only report violations for ADDED or MODIFIED lines (lines starting with + in the diff).
+If no violations are found, return {"violations": []}.
+
+Example response:
+{"violations": [{"line": 12, "rule": "Action pinning", "message": "actions/checkout is pinned to a tag, which can be moved", "severity": "warning", "fix": "uses: actions/checkout@<full commit sha> # v4"}]}
+
+Important:
+- Use the convention name (the bold text) as the "rule"
+- Report a convention violation as "warning"; use "error" only for a security problem such as a leaked secret or script injection
+- Line numbers should reference the NEW file line numbers (from lines starting with +)
+- Do not report issues in lines the change does not touch
 `)
+	sb.WriteString(fixInstructions)
+	if namesRules(conventions) {
+		sb.WriteString("- For a convention starting with an ID in square brackets, use the ID without the brackets as the \"rule\"\n")
+	}
+	persona.writeStyle(&sb)
+
+	if language != "" {
+		sb.WriteString(fmt.Sprintf("- Write the \"message\" values in %s. Keep the JSON keys, the \"fix\" code, the \"severity\" values and the \"rule\" names exactly as written above, untranslated\n", languageName(language)))
+	}
+
+	sb.WriteString("\nRespond with ONLY the JSON, no additional text.\n")
 
 	return sb.String()
 }
 
+// writeInstructions adds the configured reviewer instructions to a prompt
+func (s *Service) writeInstructions(sb *strings.Builder) {
+	if s.config.Instructions == "" {
+		return
+	}
+	sb.WriteString("\n## Additional Instructions\n")
+	sb.WriteString(strings.TrimSpace(s.config.Instructions))
+	sb.WriteString("\n")
+}
+
+// languageNames spells out common language codes for the prompt; models
+// follow "Swedish" more reliably than "sv"
+var languageNames = map[string]string{
+	"da": "Danish", "de": "German", "es": "Spanish", "fi": "Finnish", "fr": "French",
+	"it": "Italian", "ja": "Japanese", "ko": "Korean", "nb": "Norwegian Bokmål", "nl": "Dutch",
+	"no": "Norwegian", "pl": "Polish", "pt": "Portuguese", "sv": "Swedish", "tr": "Turkish",
+	"uk": "Ukrainian", "zh": "Chinese",
+}
+
+// languageName returns the English name of a language code such as sv or
+// pt-BR, followed by the code itself
+func languageName(code string) string {
+	base, _, _ := strings.Cut(strings.ToLower(code), "-")
+	if name, ok := languageNames[base]; ok {
+		return fmt.Sprintf("%s (%s)", name, code)
+	}
+	return "the language with code " + code
+}
+
 // parseLLMResponse extracts violations from LLM response
 func (s *Service) parseLLMResponse(response, filePath, patch string) []FileViolation {
 	// Clean up the response - remove markdown code blocks if present
@@ -545,82 +1325,155 @@ func (s *Service) parseLLMResponse(response, filePath, patch string) []FileViola
 
 	var llmResp LLMAnalysisResponse
 	if err := json.Unmarshal([]byte(response), &llmResp); err != nil {
-		log.Printf("Warning: failed to parse LLM response: %v", err)
+		slog.Warn("failed to parse LLM response", "path", filePath, "error", err)
 		return nil
 	}
 
+	lines := newDiffLines(patch)
+	violations := make([]FileViolation, 0, len(llmResp.Violations))
+	for _, v := range llmResp.Violations {
+		if violation, ok := lines.violation(filePath, v); ok {
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations
+}
+
+// diffLines are the lines of a patch the LLM may report violations on
+type diffLines struct {
+	valid map[int]bool
+	added map[int]string
+}
+
+func newDiffLines(patch string) diffLines {
 	// Get valid line numbers from the patch
 	validLines := make(map[int]bool)
 	for _, lineNo := range ghclient.GetNewLineNumbers(patch) {
 		validLines[lineNo] = true
 	}
+	return diffLines{valid: validLines, added: addedLines(patch)}
+}
 
-	violations := make([]FileViolation, 0, len(llmResp.Violations))
-	for _, v := range llmResp.Violations {
-		// Validate that the line number is in the patch
-		if !validLines[v.Line] && len(validLines) > 0 {
-			continue // Skip violations on lines not in the diff
-		}
+// violation returns the finding for v in filePath, or false when v is on
+// a line the patch does not touch
+func (d diffLines) violation(filePath string, v LLMViolation) (FileViolation, bool) {
+	// Validate that the line number is in the patch
+	if !d.valid[v.Line] && len(d.valid) > 0 {
+		return FileViolation{}, false // Skip violations on lines not in the diff
+	}
 
-		violations = append(violations, FileViolation{
-			Path:     filePath,
-			Line:     v.Line,
-			Rule:     v.Rule,
-			Message:  v.Message,
-			Severity: v.Severity,
-		})
+	violation := FileViolation{
+		Path:     filePath,
+		Line:     v.Line,
+		Rule:     v.Rule,
+		Message:  v.Message,
+		Severity: v.Severity,
+	}
+	// A fix replacing the flagged lines is posted as a suggested change
+	last := max(v.EndLine, v.Line)
+	if violation.Suggestion = suggestion(v.Fix, v.Line, last, d.added); violation.Suggestion != "" && last > v.Line {
+		violation.EndLine = last
 	}
+	return violation, true
+}
 
-	return violations
+// branding returns the configured comment wording
+func (s *Service) branding() Branding {
+	if s.config.Branding != nil {
+		return *s.config.Branding
+	}
+	return DefaultBranding(true)
 }
 
-// postReviewComments creates a GitHub review with inline comments
-func (s *Service) postReviewComments(ctx context.Context, req ReviewRequest, violations []FileViolation) (int, error) {
-	if len(violations) == 0 {
+// postReviewComments creates a GitHub review with inline comments. It
+// requests changes when a finding reaches the gate of its route in set.
+func (s *Service) postReviewComments(ctx context.Context, req ReviewRequest, violations []FileViolation, set *checks.Set) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "review.post_comments", attribute.Int("review.violations", len(violations)))
+	defer func() { tracing.End(span, err) }()
+
+	// Only the findings the author wants to hear about are commented on,
+	// but a review without comments is still posted to request changes, so
+	// that preferences never lift the gate
+	commented, _ := req.Preferences.commented(violations)
+	blocking := blocks(set, violations)
+	if len(commented) == 0 && !blocking {
 		return 0, nil
 	}
 
-	comments := make([]ghclient.DraftReviewComment, 0, len(violations))
+	comments := make([]ghclient.DraftReviewComment, 0, len(commented))
+	var fileFindings []string
 
-	for _, v := range violations {
-		emoji := "⚠️"
-		if v.Severity == "error" {
-			emoji = "❌"
-		} else if v.Severity == "suggestion" {
-			emoji = "💡"
+	for _, v := range commented {
+		if v.Path == "" {
+			// Findings about the whole pull request, such as a failed
+			// smoke check, keep their explanation below the list item
+			fileFindings = append(fileFindings, "- "+s.branding().inlineComment(v))
+			continue
+		}
+		if v.Line == 0 {
+			// Findings about a whole file, such as a binary, have no line
+			// to comment on and go in the review body instead, as one
+			// list item without their explanation
+			v.Why = ""
+			fileFindings = append(fileFindings, fmt.Sprintf("- `%s`: %s", v.Path, s.branding().inlineComment(v)))
+			continue
 		}
 
-		body := fmt.Sprintf("%s **%s**: %s", emoji, v.Rule, v.Message)
-
-		comments = append(comments, ghclient.DraftReviewComment{
+		comment := ghclient.DraftReviewComment{
 			Path: v.Path,
 			Line: v.Line,
 			Side: "RIGHT",
-			Body: body,
-		})
+			Body: s.branding().inlineComment(v),
+		}
+		if v.EndLine > v.Line {
+			// A suggestion replacing several lines spans them all
+			comment.StartLine, comment.Line = v.Line, v.EndLine
+		}
+		comments = append(comments, comment)
 	}
 
-	reviewBody := fmt.Sprintf("🔍 **PRMate Review** - Found %d issue(s) to address.", len(violations))
+	reviewBody := s.branding().reviewBody(len(violations))
+	if held := len(violations) - len(commented); held > 0 {
+		reviewBody += fmt.Sprintf("\n\n%d of them are not commented on, as the author of this pull request chose. The summary comment counts them.", held)
+	}
+	if len(fileFindings) > 0 {
+		reviewBody += "\n\n" + strings.Join(fileFindings, "\n")
+	}
 
-	// Determine review event based on severity
+	// Determine review event based on severity and the gate of the
+	// finding's route
 	event := "COMMENT"
-	for _, v := range violations {
-		if v.Severity == "error" {
-			event = "REQUEST_CHANGES"
-			break
-		}
+	if blocking {
+		event = "REQUEST_CHANGES"
 	}
 
-	err := s.githubClient.CreatePullRequestReview(ctx, req.Owner, req.Repo, req.PRNumber, req.HeadSHA, event, reviewBody, comments)
+	err = s.githubClient.CreatePullRequestReview(ctx, req.Owner, req.Repo, req.PRNumber, req.HeadSHA, event, reviewBody, comments)
 	if err != nil {
 		return 0, err
 	}
 
-	return len(comments), nil
+	return len(comments) + len(fileFindings), nil
 }
 
-// postSummary creates a PR comment with the review summary
-func (s *Service) postSummary(ctx context.Context, req ReviewRequest, summary ReviewSummary) error {
+// blocks reports whether a finding reaches the gate of its route in set,
+// so that the review requests changes
+func blocks(set *checks.Set, violations []FileViolation) bool {
+	for _, v := range violations {
+		if set.Blocks(v.Path, v.Severity) {
+			return true
+		}
+	}
+	return false
+}
+
+// postSummary edits the summary comment of the previous review in place,
+// so long-lived pull requests carry a single summary, or creates one when
+// there is none or it cannot be edited
+func (s *Service) postSummary(ctx context.Context, req ReviewRequest, summary ReviewSummary, outstanding []Outstanding) (err error) {
+	ctx, span := tracing.Start(ctx, "review.post_summary")
+	defer func() { tracing.End(span, err) }()
+
 	summaryJSON, err := json.Marshal(summary)
 	if err != nil {
 		return fmt.Errorf("marshal summary: %w", err)
@@ -632,35 +1485,80 @@ func (s *Service) postSummary(ctx context.Context, req ReviewRequest, summary Re
 	sb.WriteString(fmt.Sprintf("%s%s%s\n", summaryMarkerPrefix, req.HeadSHA, summaryMarkerSuffix))
 
 	// Human-readable summary
-	sb.WriteString("## 📊 PRMate Review Summary\n\n")
+	brand := s.branding()
+	sb.WriteString(fmt.Sprintf("## %s\n\n", brand.SummaryHeader))
 	sb.WriteString(fmt.Sprintf("| Metric | Value |\n|--------|-------|\n"))
 	sb.WriteString(fmt.Sprintf("| Files Reviewed | %d |\n", len(summary.FilesScanned)))
 	sb.WriteString(fmt.Sprintf("| Rules Applied | %d |\n", summary.RulesApplied))
 	sb.WriteString(fmt.Sprintf("| Issues Found | %d |\n", summary.ViolationsFound))
-	sb.WriteString(fmt.Sprintf("| Commit | `%s` |\n", summary.HeadSHA[:7]))
+	if summary.Exempted > 0 {
+		sb.WriteString(fmt.Sprintf("| Exempted | %d |\n", summary.Exempted))
+	}
+	if summary.Suppressed > 0 {
+		sb.WriteString(fmt.Sprintf("| Suppressed | %d |\n", summary.Suppressed))
+	}
+	if summary.Muted > 0 {
+		sb.WriteString(fmt.Sprintf("| Muted | %d |\n", summary.Muted))
+	}
+	sb.WriteString(fmt.Sprintf("| Commit | `%s` |\n", shortSHA(summary.HeadSHA)))
+
+	if summary.RulesWarning != "" {
+		warning := summary.RulesWarning
+		if brand.Emoji {
+			warning = "⚠️ " + warning
+		}
+		sb.WriteString(fmt.Sprintf("\n> %s\n", warning))
+	}
+
+	if summary.Delta != nil {
+		writeDelta(&sb, *summary.Delta)
+	}
+
+	if len(summary.Overlaps) > 0 {
+		writeOverlaps(&sb, summary.Overlaps)
+	}
+
+	if req.Preferences.SummaryOnly {
+		writeFindingList(&sb, summary.Findings)
+	}
 
 	if len(summary.FilesScanned) > 0 {
 		sb.WriteString("\n<details>\n<summary>Files Reviewed</summary>\n\n")
 		for _, f := range summary.FilesScanned {
-			status := "✅"
-			if f.Violations > 0 {
-				status = fmt.Sprintf("⚠️ %d issue(s)", f.Violations)
-			}
-			sb.WriteString(fmt.Sprintf("- `%s` %s\n", f.Path, status))
+			sb.WriteString(fmt.Sprintf("- `%s` %s\n", f.Path, brand.fileStatus(f.Violations)))
 		}
 		sb.WriteString("</details>\n")
 	}
 
+	if summary.FollowUp != nil {
+		writeOutstanding(&sb, outstanding, *summary.FollowUp)
+	}
+
+	if footer := brand.footer(version.Get().String()); footer != "" {
+		sb.WriteString(fmt.Sprintf("\n<sub>%s</sub>\n", footer))
+	}
+
 	// Hidden JSON data for future parsing
-	sb.WriteString(fmt.Sprintf("\n<!-- prmate-data:%s -->", string(summaryJSON)))
+	sb.WriteString(fmt.Sprintf("\n%s%s%s", summaryDataPrefix, string(summaryJSON), summaryMarkerSuffix))
 
+	logger := logging.FromContext(ctx)
+	commentID, _, err := s.githubClient.FindPRComment(ctx, req.Owner, req.Repo, req.PRNumber, summaryMarkerPrefix)
+	if err != nil {
+		logger.Warn("could not find previous summary comment", "error", err)
+	} else if commentID != 0 {
+		err := s.githubClient.UpdatePRComment(ctx, req.Owner, req.Repo, req.PRNumber, commentID, sb.String())
+		if err == nil {
+			return nil
+		}
+		logger.Warn("could not update previous summary comment, posting a new one", "comment_id", commentID, "error", err)
+	}
 	return s.githubClient.CreatePRComment(ctx, req.Owner, req.Repo, req.PRNumber, sb.String())
 }
 
 // parseSummaryFromComment extracts ReviewSummary from a comment body
 func parseSummaryFromComment(comment string) (*ReviewSummary, error) {
 	// Find the JSON data marker
-	marker := "<!-- prmate-data:"
+	marker := summaryDataPrefix
 	idx := strings.Index(comment, marker)
 	if idx == -1 {
 		return nil, fmt.Errorf("no summary data found")
@@ -772,8 +1670,43 @@ func extractChecklistItems(content string) []string {
 	return items
 }
 
+// PostingWindow returns the posting window .prmate.md at ref declares, or
+// nil when it declares none or the repository has no .prmate.md
+func (s *Service) PostingWindow(ctx context.Context, owner, repo, ref string) (*checks.Window, error) {
+	rules, err := s.loadRules(ctx, owner, repo, ref)
+	if errors.Is(err, errclass.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rules.Checks.Window, nil
+}
+
+// shortSHA abbreviates a commit SHA to seven characters
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
 // HasPRMateFile checks if a .prmate.md file exists in the repository
 func (s *Service) HasPRMateFile(ctx context.Context, owner, repo, ref string) bool {
+	if e, ok := s.rulesCache.get(owner, repo, ref); ok {
+		return e.err == nil
+	}
 	_, err := s.githubClient.GetFileContent(ctx, owner, repo, ".prmate.md", ref)
+	if errors.Is(err, errclass.ErrNotFound) {
+		s.rulesCache.put(owner, repo, ref, rulesEntry{err: fmt.Errorf("get .prmate.md: %w", err), files: rulesFiles(Rules{})})
+	}
 	return err == nil
 }
+
+// withOptionalTimeout applies timeout to ctx unless it is zero
+func withOptionalTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
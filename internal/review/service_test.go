@@ -3,9 +3,14 @@ package review
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"prmate/internal/breaker"
 	ghclient "prmate/internal/github"
 )
 
@@ -15,10 +20,15 @@ type mockGitHubClient struct {
 	pullRequest     *ghclient.PullRequest
 	prFiles         []ghclient.PRFile
 	fileContents    map[string]string
+	fileSizes       map[string]int64
 	prComments      []string
 	reviewComments  []ghclient.ReviewComment
+	reviewThreads   []ghclient.ReviewThread
 	postedReviews   []mockPostedReview
 	postedComments  []string
+	updatedComments map[int64]string
+	updateErr       error
+	dryRun          bool
 }
 
 type mockPostedReview struct {
@@ -43,6 +53,21 @@ func (m *mockGitHubClient) GetFileContent(ctx context.Context, owner, repo, path
 	return "", nil
 }
 
+func (m *mockGitHubClient) GetFileSize(ctx context.Context, owner, repo, path, ref string) (int64, error) {
+	return m.fileSizes[path], nil
+}
+
+// ListDirectory lists the fileContents directly in dir
+func (m *mockGitHubClient) ListDirectory(ctx context.Context, owner, repo, dir, ref string) ([]string, error) {
+	var files []string
+	for p := range m.fileContents {
+		if path.Dir(p) == path.Clean("./"+dir) {
+			files = append(files, p)
+		}
+	}
+	return files, nil
+}
+
 func (m *mockGitHubClient) ListPRComments(ctx context.Context, owner, repo string, prNumber int) ([]string, error) {
 	return m.prComments, nil
 }
@@ -51,6 +76,10 @@ func (m *mockGitHubClient) ListReviewComments(ctx context.Context, owner, repo s
 	return m.reviewComments, nil
 }
 
+func (m *mockGitHubClient) ListReviewThreads(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.ReviewThread, error) {
+	return m.reviewThreads, nil
+}
+
 func (m *mockGitHubClient) CreatePullRequestReview(ctx context.Context, owner, repo string, prNumber int, commitID string, event string, body string, comments []ghclient.DraftReviewComment) error {
 	m.postedReviews = append(m.postedReviews, mockPostedReview{
 		commitID: commitID,
@@ -66,11 +95,44 @@ func (m *mockGitHubClient) CreatePRComment(ctx context.Context, owner, repo stri
 	return nil
 }
 
+// FindPRComment numbers prComments from 1
+func (m *mockGitHubClient) FindPRComment(ctx context.Context, owner, repo string, prNumber int, marker string) (int64, string, error) {
+	for i := len(m.prComments) - 1; i >= 0; i-- {
+		if strings.Contains(m.prComments[i], marker) {
+			return int64(i + 1), m.prComments[i], nil
+		}
+	}
+	return 0, "", nil
+}
+
+func (m *mockGitHubClient) UpdatePRComment(ctx context.Context, owner, repo string, prNumber int, commentID int64, body string) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	if m.updatedComments == nil {
+		m.updatedComments = make(map[int64]string)
+	}
+	m.updatedComments[commentID] = body
+	m.prComments[commentID-1] = body
+	return nil
+}
+
+func (m *mockGitHubClient) DryRun(owner, repo string) bool {
+	return m.dryRun
+}
+
 type mockLLMProvider struct {
 	response string
+	block    bool // wait for ctx to be done before returning
+	prompts  []string
 }
 
-func (m *mockLLMProvider) GenerateText(prompt string) (string, error) {
+func (m *mockLLMProvider) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	m.prompts = append(m.prompts, prompt)
+	if m.block {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
 	return m.response, nil
 }
 
@@ -230,6 +292,29 @@ func TestFilterFilesToReview(t *testing.T) {
 	}
 }
 
+func TestCarryOpenFindings(t *testing.T) {
+	previous := &ReviewSummary{OpenFindings: []OpenFinding{
+		{Path: "kept.go", Line: 1, Rule: "r", Severity: "error"},
+		{Path: "rereviewed.go", Line: 2, Rule: "r", Severity: "error"},
+		{Path: "removed.go", Line: 3, Rule: "r", Severity: "error"},
+	}}
+	files := []ghclient.PRFile{
+		{Filename: "kept.go", Status: "modified"},
+		{Filename: "rereviewed.go", Status: "modified"},
+		{Filename: "removed.go", Status: "removed"},
+	}
+	reviewed := []FileReviewStatus{{Path: "rereviewed.go"}}
+	violations := []FileViolation{
+		{Path: "rereviewed.go", Line: 9, Rule: "r", Severity: "error"},
+		{Path: "rereviewed.go", Line: 10, Rule: "r", Severity: "warning"},
+	}
+
+	open := carryOpenFindings(previous, files, reviewed, violations)
+	if len(open) != 2 || open[0].Path != "kept.go" || open[1].Line != 9 {
+		t.Errorf("carryOpenFindings() = %+v, want kept.go:1 and rereviewed.go:9", open)
+	}
+}
+
 func TestReviewPR_NoRules(t *testing.T) {
 	ghMock := &mockGitHubClient{
 		fileContents: map[string]string{
@@ -244,7 +329,7 @@ func TestReviewPR_NoRules(t *testing.T) {
 		response: `{"violations": []}`,
 	}
 
-	svc := NewService(ghMock, llmMock)
+	svc := NewService(ghMock, llmMock, Config{})
 
 	result, err := svc.ReviewPR(context.Background(), ReviewRequest{
 		Owner:    "test",
@@ -287,7 +372,7 @@ func TestReviewPR_WithViolations(t *testing.T) {
 		response: `{"violations": [{"line": 4, "rule": "Error Handling", "message": "Error not wrapped with context", "severity": "warning"}]}`,
 	}
 
-	svc := NewService(ghMock, llmMock)
+	svc := NewService(ghMock, llmMock, Config{})
 
 	result, err := svc.ReviewPR(context.Background(), ReviewRequest{
 		Owner:    "test",
@@ -309,6 +394,14 @@ func TestReviewPR_WithViolations(t *testing.T) {
 		t.Errorf("expected 1 comment posted, got %d", result.CommentsPosted)
 	}
 
+	if len(result.Violations) != 1 {
+		t.Errorf("expected 1 violation in result, got %d", len(result.Violations))
+	}
+
+	if result.EstimatedTokens <= 0 {
+		t.Errorf("expected estimated tokens to be tracked, got %d", result.EstimatedTokens)
+	}
+
 	if len(ghMock.postedReviews) != 1 {
 		t.Fatalf("expected 1 review posted, got %d", len(ghMock.postedReviews))
 	}
@@ -323,6 +416,481 @@ func TestReviewPR_WithViolations(t *testing.T) {
 	}
 }
 
+func TestReviewPR_EditsPreviousSummary(t *testing.T) {
+	previous := summaryMarkerPrefix + "prev123" + summaryMarkerSuffix + "\n## PRMate Review Summary"
+	tests := []struct {
+		name      string
+		comments  []string
+		updateErr error
+		wantNew   bool
+	}{
+		{name: "first review", comments: []string{"LGTM"}, wantNew: true},
+		{name: "later review", comments: []string{previous, "LGTM"}},
+		{name: "edit fails", comments: []string{previous}, updateErr: errors.New("forbidden"), wantNew: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ghMock := &mockGitHubClient{
+				fileContents: map[string]string{".prmate.md": "## Learned Rules\n- Use fmt.Errorf with %w\n"},
+				prFiles:      []ghclient.PRFile{{Filename: "main.go", Status: "modified", Patch: "@@ -1,0 +1 @@\n+x"}},
+				prComments:   tt.comments,
+				updateErr:    tt.updateErr,
+			}
+			svc := NewService(ghMock, &mockLLMProvider{response: `{"violations": []}`}, Config{})
+			if _, err := svc.ReviewPR(context.Background(), ReviewRequest{
+				Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+			}); err != nil {
+				t.Fatalf("ReviewPR() error = %v", err)
+			}
+
+			if tt.wantNew {
+				if len(ghMock.postedComments) != 1 || len(ghMock.updatedComments) != 0 {
+					t.Fatalf("got %d new and %d edited comments, want a new summary", len(ghMock.postedComments), len(ghMock.updatedComments))
+				}
+				return
+			}
+			if len(ghMock.postedComments) != 0 || len(ghMock.updatedComments) != 1 {
+				t.Fatalf("got %d new and %d edited comments, want the previous summary edited", len(ghMock.postedComments), len(ghMock.updatedComments))
+			}
+			if !strings.Contains(ghMock.updatedComments[1], summaryMarkerPrefix+"abc123def456789") {
+				t.Errorf("edited summary = %q, want the new head", ghMock.updatedComments[1])
+			}
+		})
+	}
+}
+
+func TestReviewPR_OfflineRunsOnlyChecks(t *testing.T) {
+	prmateMD := "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n\n" +
+		"```prmate-checks\nid: no-todo\npattern: TODO\nseverity: error\n\nignore: vendor/**\n```\n"
+
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{".prmate.md": prmateMD},
+		prFiles: []ghclient.PRFile{
+			{Filename: "handler.go", Status: "modified", Additions: 1, Patch: "@@ -3,0 +4 @@\n+\t// TODO: wrap"},
+			{Filename: "vendor/lib/lib.go", Status: "added", Additions: 1, Patch: "@@ -0,0 +1 @@\n+// TODO"},
+		},
+	}
+	llmMock := &mockLLMProvider{
+		response: `{"violations": [{"line": 4, "rule": "Error Handling", "message": "from the LLM", "severity": "warning"}]}`,
+	}
+
+	tests := []struct {
+		name       string
+		offline    bool
+		checksOnly bool
+		want       int
+	}{
+		{name: "offline", offline: true, want: 1},
+		{name: "checks-only request", checksOnly: true, want: 1},
+		{name: "online adds LLM findings", offline: false, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewService(ghMock, llmMock, Config{Offline: tt.offline})
+			result, err := svc.ReviewPR(context.Background(), ReviewRequest{
+				Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch", ChecksOnly: tt.checksOnly,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.ViolationsFound != tt.want {
+				t.Fatalf("expected %d violations, got %+v", tt.want, result.Violations)
+			}
+			if v := result.Violations[0]; v.Rule != "no-todo" || v.Line != 4 || v.Severity != "error" {
+				t.Errorf("unexpected check violation: %+v", v)
+			}
+			if result.FilesReviewed != 1 {
+				t.Errorf("ignored file was reviewed: %d files", result.FilesReviewed)
+			}
+			if tt.want == 1 && result.EstimatedTokens != 0 {
+				t.Errorf("offline review spent %d tokens", result.EstimatedTokens)
+			}
+		})
+	}
+}
+
+func TestReviewPR_SecretScan(t *testing.T) {
+	// Assembled at run time so this file does not trip secret scanners
+	key := "AKIA" + "IOSFODNN7" + "EXAMPLE"
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+			"config.go":  "package config\n\nconst awsKey = \"" + key + "\"\n",
+		},
+		prFiles: []ghclient.PRFile{
+			{Filename: "config.go", Status: "modified", Additions: 1, Patch: "@@ -2,0 +3 @@\n+const awsKey = \"" + key + "\""},
+		},
+	}
+	llmMock := &mockLLMProvider{response: `{"violations": []}`}
+
+	svc := NewService(ghMock, llmMock, Config{SecretScan: true})
+	result, err := svc.ReviewPR(context.Background(), ReviewRequest{
+		Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ViolationsFound != 1 {
+		t.Fatalf("expected 1 violation, got %+v", result.Violations)
+	}
+	if v := result.Violations[0]; v.Rule != "Hardcoded secret" || v.Line != 3 || v.Severity != "error" || !strings.Contains(v.Message, "AWS access key ID") {
+		t.Errorf("unexpected secret violation: %+v", v)
+	}
+	if len(llmMock.prompts) != 1 || strings.Contains(llmMock.prompts[0], key) || !strings.Contains(llmMock.prompts[0], "[REDACTED]") {
+		t.Errorf("the secret was not redacted from the LLM prompt")
+	}
+	for _, r := range ghMock.postedReviews {
+		for _, c := range r.comments {
+			if strings.Contains(c.Body, key) {
+				t.Errorf("review comment echoes the secret: %s", c.Body)
+			}
+		}
+	}
+}
+
+func TestReviewPR_InfraConventions(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n\n" +
+				"## Infrastructure Conventions\n\n- **Action pinning**: Pin actions from other repositories to a full commit SHA\n",
+		},
+		prFiles: []ghclient.PRFile{
+			{Filename: ".github/workflows/ci.yml", Status: "modified", Patch: "@@ -7,0 +8 @@\n+      - uses: actions/checkout@v4"},
+			{Filename: "handler.go", Status: "modified", Patch: "@@ -3,0 +4 @@\n+\treturn err"},
+		},
+	}
+	llmMock := &mockLLMProvider{response: `{"violations": []}`}
+
+	svc := NewService(ghMock, llmMock, Config{})
+	if _, err := svc.ReviewPR(context.Background(), ReviewRequest{
+		Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(llmMock.prompts) != 2 {
+		t.Fatalf("expected 2 LLM calls, got %d", len(llmMock.prompts))
+	}
+	workflow, code := llmMock.prompts[0], llmMock.prompts[1]
+	if !strings.Contains(workflow, "GitHub Actions workflow") || !strings.Contains(workflow, "**Action pinning**") || strings.Contains(workflow, "fmt.Errorf") {
+		t.Errorf("workflow prompt does not use the infrastructure conventions:\n%s", workflow)
+	}
+	if strings.Contains(code, "Action pinning") || !strings.Contains(code, "fmt.Errorf with %w") {
+		t.Errorf("code prompt should use the code rules only:\n%s", code)
+	}
+}
+
+func TestReviewPR_AssetPolicy(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n\n" +
+				"```prmate-checks\nmax-binary-size: 1MB\nforbidden-extensions: .exe\nseverity: error\n```\n",
+		},
+		fileSizes: map[string]int64{"assets/hero.png": 3 << 20, "assets/icon.png": 2048},
+		prFiles: []ghclient.PRFile{
+			{Filename: "assets/hero.png", Status: "added"},
+			{Filename: "assets/icon.png", Status: "added"},
+			{Filename: "tools/setup.exe", Status: "added"},
+		},
+	}
+	llmMock := &mockLLMProvider{response: `{"violations": []}`}
+
+	svc := NewService(ghMock, llmMock, Config{})
+	result, err := svc.ReviewPR(context.Background(), ReviewRequest{
+		Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(llmMock.prompts) != 0 {
+		t.Errorf("binaries were sent to the LLM: %d calls", len(llmMock.prompts))
+	}
+	var got []string
+	for _, v := range result.Violations {
+		got = append(got, v.Path+" "+v.Rule)
+	}
+	if want := "assets/hero.png binary-size,tools/setup.exe forbidden-extension"; strings.Join(got, ",") != want {
+		t.Errorf("violations = %v, want %s", got, want)
+	}
+	if len(ghMock.postedReviews) != 1 {
+		t.Fatalf("expected 1 review, got %d", len(ghMock.postedReviews))
+	}
+	review := ghMock.postedReviews[0]
+	if len(review.comments) != 0 || review.event != "REQUEST_CHANGES" || !strings.Contains(review.body, "- `assets/hero.png`: ") || !strings.Contains(review.body, "3.0MB, over the 1.0MB limit") {
+		t.Errorf("file findings should be listed in the review body: %+v", review)
+	}
+}
+
+func TestReviewPR_DocDrift(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n```prmate-checks\ndocs: README.md, docs/**\n```\n",
+		},
+		prFiles: []ghclient.PRFile{
+			{Filename: "client.go", Status: "modified", Additions: 1, Patch: "@@ -4,0 +5,1 @@\n+func NewClient(url string) *Client {"},
+		},
+	}
+	llmMock := &mockLLMProvider{response: `{"violations": []}`}
+
+	svc := NewService(ghMock, llmMock, Config{Offline: true})
+	req := ReviewRequest{Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch"}
+	result, err := svc.ReviewPR(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Violations) != 1 {
+		t.Fatalf("violations = %+v, want one doc-drift finding", result.Violations)
+	}
+	v := result.Violations[0]
+	if v.Rule != "doc-drift" || v.Line != 5 || v.Severity != "suggestion" || !strings.Contains(v.Message, "`NewClient`") {
+		t.Errorf("violation = %+v", v)
+	}
+	if review := ghMock.postedReviews[0]; review.event != "COMMENT" || len(review.comments) != 1 {
+		t.Errorf("drift should be an inline suggestion: %+v", review)
+	}
+
+	// Updating the README along with the client settles it
+	ghMock.prFiles = append(ghMock.prFiles, ghclient.PRFile{Filename: "README.md", Status: "modified", Additions: 1, Patch: "@@ -1,0 +2,1 @@\n+NewClient takes the server URL."})
+	result, err = NewService(ghMock, llmMock, Config{Offline: true}).ReviewPR(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Violations) != 0 {
+		t.Errorf("violations = %+v, want none once the docs change", result.Violations)
+	}
+}
+
+func TestReviewPR_ProtectedPaths(t *testing.T) {
+	large := "package auth\n\n" + strings.Repeat("// padding\n", 1200)
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md":           "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n\n```prmate-checks\nprotected: internal/auth/**\n```\n",
+			"internal/auth/jwt.go": large,
+			"api/handler.go":       large,
+		},
+		prFiles: []ghclient.PRFile{
+			{Filename: "internal/auth/jwt.go", Status: "modified", Additions: 600, Patch: "@@ -3,0 +4 @@\n+\treturn err"},
+			{Filename: "api/handler.go", Status: "modified", Additions: 1, Patch: "@@ -3,0 +4 @@\n+\treturn err"},
+		},
+	}
+	llmMock := &mockLLMProvider{
+		response: `{"violations": [{"line": 4, "rule": "Error Handling", "message": "Error not wrapped", "severity": "suggestion"}]}`,
+	}
+
+	svc := NewService(ghMock, llmMock, Config{})
+	result, err := svc.ReviewPR(context.Background(), ReviewRequest{
+		Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	severities := map[string]string{}
+	for _, v := range result.Violations {
+		severities[v.Path] = v.Severity
+	}
+	if severities["internal/auth/jwt.go"] != "error" || severities["api/handler.go"] != "suggestion" {
+		t.Errorf("severities = %v, want findings in protected paths escalated to error", severities)
+	}
+	if review := ghMock.postedReviews[0]; review.event != "REQUEST_CHANGES" {
+		t.Errorf("event = %s, want REQUEST_CHANGES", review.event)
+	}
+
+	// The protected file is sent whole despite its size; the other one,
+	// over the content limit, only as a diff
+	if len(llmMock.prompts) != 2 {
+		t.Fatalf("expected 2 LLM calls, got %d", len(llmMock.prompts))
+	}
+	if !strings.Contains(llmMock.prompts[0], "### Full File Content") {
+		t.Error("protected file should be reviewed with its full content")
+	}
+	if strings.Contains(llmMock.prompts[1], "### Full File Content") {
+		t.Error("large unprotected file should be reviewed without its full content")
+	}
+}
+
+type fakeLoad bool
+
+func (f fakeLoad) Degraded() bool { return bool(f) }
+
+func TestReviewPR_LoadSkipsDependencyContext(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md":      "# PRMate Context\n\n## Learned Rules\n- Validate input\n\n```prmate-checks\nprotected: src/auth/**\n```\n",
+			"src/app.ts":      "import { load } from './util'\n",
+			"src/auth/jwt.ts": "import { load } from '../util'\n",
+			"src/util.ts":     "export function load() {}\n",
+		},
+		prFiles: []ghclient.PRFile{
+			{Filename: "src/app.ts", Status: "modified", Additions: 1, Patch: "@@ -1,0 +2 @@\n+load()"},
+			{Filename: "src/auth/jwt.ts", Status: "modified", Additions: 1, Patch: "@@ -1,0 +2 @@\n+load()"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		degraded bool
+		want     []bool // dependency context per prompt
+	}{
+		{name: "normal load", want: []bool{true, true}},
+		{name: "degraded keeps it for protected files", degraded: true, want: []bool{false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llmMock := &mockLLMProvider{response: `{"violations": []}`}
+			svc := NewService(ghMock, llmMock, Config{})
+			svc.SetLoad(fakeLoad(tt.degraded))
+			if _, err := svc.ReviewPR(context.Background(), ReviewRequest{
+				Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(llmMock.prompts) != len(tt.want) {
+				t.Fatalf("expected %d LLM calls, got %d", len(tt.want), len(llmMock.prompts))
+			}
+			for i, want := range tt.want {
+				if got := strings.Contains(llmMock.prompts[i], "### src/util.ts"); got != want {
+					t.Errorf("prompt %d has dependency context = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReviewPR_ReviewTimeout(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+		},
+		prFiles: []ghclient.PRFile{
+			{Filename: "handler.go", Status: "modified", Patch: "@@ -3,0 +4 @@\n+\treturn err"},
+		},
+	}
+
+	llmMock := &mockLLMProvider{block: true}
+
+	svc := NewService(ghMock, llmMock, Config{ReviewTimeout: 20 * time.Millisecond})
+
+	_, err := svc.ReviewPR(context.Background(), ReviewRequest{
+		Owner:    "test",
+		Repo:     "repo",
+		PRNumber: 1,
+		HeadSHA:  "abc123def456789",
+		HeadRef:  "feature-branch",
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded error, got %v", err)
+	}
+
+	if len(ghMock.postedComments) != 0 {
+		t.Errorf("expected no summary after timeout, got %d comments", len(ghMock.postedComments))
+	}
+}
+
+// concurrentLLM holds each call until limit calls are in flight, recording
+// the most it saw at once
+type concurrentLLM struct {
+	limit int
+	fail  string // calls whose prompt contains it fail with breaker.ErrOpen
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	full        chan struct{}
+	fullOnce    sync.Once
+}
+
+func (c *concurrentLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.maxInFlight = max(c.maxInFlight, c.inFlight)
+	if c.inFlight == c.limit {
+		c.fullOnce.Do(func() { close(c.full) })
+	}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.inFlight--
+		c.mu.Unlock()
+	}()
+
+	if c.fail != "" && strings.Contains(prompt, c.fail) {
+		return "", breaker.ErrOpen
+	}
+	select {
+	case <-c.full:
+	case <-time.After(time.Second):
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	return `{"violations": [{"line": 1, "rule": "Error Handling", "message": "Wrap the error", "severity": "warning"}]}`, nil
+}
+
+func TestReviewFiles_Concurrency(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+		},
+	}
+	var files []ghclient.PRFile
+	for _, name := range []string{"a.go", "b.go", "c.go", "d.go", "e.go"} {
+		files = append(files, ghclient.PRFile{Filename: name, Status: "added", Patch: "@@ -0,0 +1 @@\n+package x"})
+	}
+
+	llmMock := &concurrentLLM{limit: 3, full: make(chan struct{})}
+	svc := NewService(ghMock, llmMock, Config{Concurrency: 3})
+	result, err := svc.ReviewFiles(context.Background(), ReviewRequest{HeadSHA: "abc123"}, files)
+	if err != nil {
+		t.Fatalf("ReviewFiles() error = %v", err)
+	}
+	if llmMock.maxInFlight != 3 {
+		t.Errorf("max files analyzed at once = %d, want 3", llmMock.maxInFlight)
+	}
+	var paths []string
+	for _, v := range result.Violations {
+		paths = append(paths, v.Path)
+	}
+	if strings.Join(paths, ",") != "a.go,b.go,c.go,d.go,e.go" {
+		t.Errorf("violations in %v, want one per file in file order", paths)
+	}
+
+	// An open breaker stops the files still under analysis
+	llmMock = &concurrentLLM{limit: 3, fail: "c.go", full: make(chan struct{})}
+	svc = NewService(ghMock, llmMock, Config{Concurrency: 3})
+	if _, err := svc.ReviewFiles(context.Background(), ReviewRequest{HeadSHA: "abc123"}, files); !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("ReviewFiles() error = %v, want the open breaker", err)
+	}
+}
+
+// panickingLLM panics analyzing any file
+type panickingLLM struct{}
+
+func (panickingLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	panic("llm exploded")
+}
+
+func TestReviewFiles_PanicAbortsReview(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+		},
+	}
+	files := []ghclient.PRFile{
+		{Filename: "a.go", Status: "added", Patch: "@@ -0,0 +1 @@\n+package x"},
+		{Filename: "b.go", Status: "added", Patch: "@@ -0,0 +1 @@\n+package x"},
+	}
+
+	svc := NewService(ghMock, panickingLLM{}, Config{Concurrency: 2})
+	_, err := svc.ReviewFiles(context.Background(), ReviewRequest{HeadSHA: "abc123"}, files)
+	if err == nil || !strings.Contains(err.Error(), "panic: llm exploded") {
+		t.Errorf("ReviewFiles() error = %v, want the panic as an error", err)
+	}
+}
+
 func TestBuildAnalysisPrompt(t *testing.T) {
 	svc := &Service{}
 
@@ -334,6 +902,8 @@ func TestBuildAnalysisPrompt(t *testing.T) {
 		[]string{"Check naming conventions"},
 		"## Structure\nClean architecture",
 		"### internal/types.go\n```go\ntype Service interface {}\n```",
+		"",
+		persona{},
 	)
 
 	// Check key elements are in the prompt
@@ -355,6 +925,37 @@ func TestBuildAnalysisPrompt(t *testing.T) {
 	if !contains(prompt, "JSON") {
 		t.Error("prompt should request JSON response")
 	}
+	if contains(prompt, "untranslated") {
+		t.Error("prompt should not ask for a language when none is set")
+	}
+}
+
+func TestBuildAnalysisPrompt_Language(t *testing.T) {
+	svc := &Service{}
+
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "sv", want: "in Swedish (sv)"},
+		{code: "pt-BR", want: "in Portuguese (pt-BR)"},
+		{code: "eo", want: "in the language with code eo"},
+	}
+	for _, tt := range tests {
+		prompt := svc.buildAnalysisPrompt("main.go", "", "+x", []string{"Wrap errors"}, nil, "", "", tt.code, persona{})
+		if !contains(prompt, tt.want) || !contains(prompt, "\"rule\" names exactly as written above") {
+			t.Errorf("prompt for %s does not ask for %q with stable rule names", tt.code, tt.want)
+		}
+	}
+}
+
+func TestBuildAnalysisPrompt_Instructions(t *testing.T) {
+	svc := &Service{config: Config{Instructions: "Ignore generated files.\n"}}
+
+	prompt := svc.buildAnalysisPrompt("main.go", "", "+x", []string{"Wrap errors"}, nil, "", "", "", persona{})
+	if !contains(prompt, "## Additional Instructions\nIgnore generated files.\n\n## File Being Reviewed") {
+		t.Errorf("prompt does not carry the instructions before the file:\n%s", prompt)
+	}
 }
 
 func contains(s, substr string) bool {
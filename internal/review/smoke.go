@@ -0,0 +1,66 @@
+package review
+
+import (
+	"fmt"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/smoke"
+)
+
+// smokeRule names the findings of smoke checks
+const smokeRule = "smoke-check"
+
+// smokeViolations turns the smoke check failures of a review into findings,
+// counting them in the statuses of their files. A problem on a line of the
+// diff is commented inline and one elsewhere in a changed file concerns the
+// whole file. A failure reporting no problem in the changed files is a
+// finding about the pull request, explained by the end of its output.
+func smokeViolations(failures []smoke.Failure, files []ghclient.PRFile, statuses []FileReviewStatus) []FileViolation {
+	if len(failures) == 0 {
+		return nil
+	}
+	diffLines := make(map[string]map[int]bool, len(files))
+	for _, f := range files {
+		if f.Status == "removed" {
+			continue
+		}
+		lines := make(map[int]bool)
+		for _, n := range ghclient.GetNewLineNumbers(f.Patch) {
+			lines[n] = true
+		}
+		diffLines[f.Filename] = lines
+	}
+
+	var violations []FileViolation
+	for _, failure := range failures {
+		located := false
+		for _, p := range failure.Problems {
+			lines, ok := diffLines[p.Path]
+			if !ok {
+				continue
+			}
+			located = true
+			v := FileViolation{Path: p.Path, Line: p.Line, Rule: smokeRule, Severity: "error",
+				Message: fmt.Sprintf("`%s`: %s", failure.Command, p.Message)}
+			if !lines[p.Line] {
+				v.Line = 0
+				v.Message = fmt.Sprintf("`%s`, line %d: %s", failure.Command, p.Line, p.Message)
+			}
+			violations = append(violations, v)
+			for i := range statuses {
+				if statuses[i].Path == p.Path {
+					statuses[i].Violations++
+				}
+			}
+		}
+		if !located {
+			violations = append(violations, FileViolation{
+				Rule:     smokeRule,
+				Severity: "error",
+				Message:  fmt.Sprintf("`%s` failed", failure.Command),
+				Why:      "<details><summary>Output</summary>\n\n````\n" + failure.Output + "\n````\n</details>",
+			})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,70 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/smoke"
+)
+
+func TestReviewPR_Smoke(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{".prmate.md": "## Learned Rules\n- Use fmt.Errorf with %w\n"},
+		prFiles: []ghclient.PRFile{
+			{Filename: "main.go", Status: "modified", Patch: "@@ -3,0 +4,2 @@\n+a\n+b"},
+		},
+	}
+	svc := NewService(ghMock, &mockLLMProvider{response: `{"violations": []}`}, Config{})
+
+	result, err := svc.ReviewPR(context.Background(), ReviewRequest{
+		Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+		Smoke: []smoke.Failure{
+			{Command: "go vet ./...", Problems: []smoke.Problem{
+				{Path: "main.go", Line: 5, Message: "unreachable code"},
+				{Path: "main.go", Line: 20, Message: "printf verb %d of wrong type"},
+				{Path: "other.go", Line: 1, Message: "not in this pull request"},
+			}},
+			{Command: "npm run typecheck", Output: "sh: npm: not found"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ReviewPR() error = %v", err)
+	}
+	if result.ViolationsFound != 3 {
+		t.Fatalf("ViolationsFound = %d, want 3: %+v", result.ViolationsFound, result.Violations)
+	}
+
+	review := ghMock.postedReviews[0]
+	if review.event != "REQUEST_CHANGES" {
+		t.Errorf("event = %q, want failed smoke checks to request changes", review.event)
+	}
+	if len(review.comments) != 1 || review.comments[0].Line != 5 || !strings.Contains(review.comments[0].Body, "**smoke-check**: `go vet ./...`: unreachable code") {
+		t.Errorf("inline comments = %+v, want the problem on a diff line", review.comments)
+	}
+	for _, want := range []string{
+		"- `main.go`: ❌ **smoke-check**: `go vet ./...`, line 20: printf verb %d of wrong type",
+		"- ❌ **smoke-check**: `npm run typecheck` failed\n\n<details><summary>Output</summary>\n\n````\nsh: npm: not found\n````\n</details>",
+	} {
+		if !strings.Contains(review.body, want) {
+			t.Errorf("review body missing %q:\n%s", want, review.body)
+		}
+	}
+	if summary := ghMock.postedComments[0]; !strings.Contains(summary, "- `main.go` ") || strings.Contains(summary, "other.go") {
+		t.Errorf("summary = %q", summary)
+	}
+}
+
+func TestCarryFindings_Smoke(t *testing.T) {
+	earlier := []OpenFinding{
+		{Path: "main.go", Line: 4, Rule: smokeRule, Severity: "error", Message: "`go vet ./...`: unreachable code"},
+		{Path: "main.go", Line: 4, Rule: "Naming", Severity: "error", Message: "Use camelCase"},
+	}
+	files := []ghclient.PRFile{{Filename: "main.go", Status: "modified"}}
+
+	carried := carryFindings(earlier, files, nil, nil, func(FileViolation) bool { return true })
+	if len(carried) != 1 || carried[0].Rule != "Naming" {
+		t.Errorf("carried = %+v, want smoke findings found anew rather than carried", carried)
+	}
+}
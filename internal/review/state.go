@@ -0,0 +1,83 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"prmate/internal/logging"
+	"prmate/internal/state"
+)
+
+// SetStateStore saves the state of every review to st as well as to the
+// summary comment, and reads the previous review from st first, so
+// incremental review survives the summary comment being deleted
+func (s *Service) SetStateStore(st state.Store) {
+	s.state = st
+}
+
+// loadState returns the summary of the previous review saved to the state
+// store, or nil if there is none
+func (s *Service) loadState(ctx context.Context, owner, repo string, prNumber int) (*ReviewSummary, error) {
+	pr, err := s.state.Load(ctx, state.Key{Owner: owner, Repo: repo, PRNumber: prNumber})
+	if err != nil || pr == nil {
+		return nil, err
+	}
+
+	var summary ReviewSummary
+	if len(pr.Summary) > 0 {
+		if err := json.Unmarshal(pr.Summary, &summary); err != nil {
+			return nil, fmt.Errorf("parse saved summary: %w", err)
+		}
+	}
+	summary.HeadSHA = pr.HeadSHA
+	summary.LastReviewedAt = pr.ReviewedAt
+	summary.FilesScanned = make([]FileReviewStatus, len(pr.Files))
+	for i, f := range pr.Files {
+		summary.FilesScanned[i] = FileReviewStatus{
+			Path:       f.Path,
+			LastSHA:    f.SHA,
+			Violations: f.Violations,
+			ReviewedAt: f.ReviewedAt.Format(time.RFC3339),
+		}
+	}
+	return &summary, nil
+}
+
+// saveState saves summary to the state store, unless writes to the
+// repository are dry runs. Failures are logged, as the summary comment
+// still carries the state.
+func (s *Service) saveState(ctx context.Context, req ReviewRequest, summary ReviewSummary) {
+	if s.state == nil || s.githubClient.DryRun(req.Owner, req.Repo) {
+		return
+	}
+	logger := logging.FromContext(ctx)
+
+	files := make([]state.File, len(summary.FilesScanned))
+	for i, f := range summary.FilesScanned {
+		reviewedAt, err := time.Parse(time.RFC3339, f.ReviewedAt)
+		if err != nil {
+			reviewedAt = summary.LastReviewedAt
+		}
+		files[i] = state.File{Path: f.Path, SHA: f.LastSHA, Violations: f.Violations, ReviewedAt: reviewedAt}
+	}
+	// The store keeps the files in columns of their own
+	rest := summary
+	rest.FilesScanned = nil
+	data, err := json.Marshal(rest)
+	if err != nil {
+		logger.Warn("could not marshal review state", "error", err)
+		return
+	}
+
+	err = s.state.Save(ctx, state.Key{Owner: req.Owner, Repo: req.Repo, PRNumber: req.PRNumber}, state.PR{
+		HeadSHA:    summary.HeadSHA,
+		ReviewedAt: summary.LastReviewedAt,
+		Files:      files,
+		Summary:    data,
+	})
+	if err != nil {
+		logger.Warn("could not save review state", "error", err)
+	}
+}
@@ -0,0 +1,135 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/state"
+)
+
+// memoryState is a state.Store kept in memory
+type memoryState struct {
+	prs            map[state.Key]state.PR
+	checkpoints    map[state.Key]map[string]json.RawMessage // of checkpointHead
+	checkpointHead string
+}
+
+func newMemoryState() *memoryState {
+	return &memoryState{prs: make(map[state.Key]state.PR), checkpoints: make(map[state.Key]map[string]json.RawMessage)}
+}
+
+func (m *memoryState) Load(ctx context.Context, key state.Key) (*state.PR, error) {
+	pr, ok := m.prs[key]
+	if !ok {
+		return nil, nil
+	}
+	return &pr, nil
+}
+
+func (m *memoryState) Save(ctx context.Context, key state.Key, pr state.PR) error {
+	m.prs[key] = pr
+	delete(m.checkpoints, key)
+	return nil
+}
+
+func (m *memoryState) Checkpoint(ctx context.Context, key state.Key, headSHA, path string, result json.RawMessage) error {
+	if m.checkpoints[key] == nil || m.checkpointHead != headSHA {
+		m.checkpoints[key] = make(map[string]json.RawMessage)
+		m.checkpointHead = headSHA
+	}
+	m.checkpoints[key][path] = result
+	return nil
+}
+
+func (m *memoryState) Checkpoints(ctx context.Context, key state.Key, headSHA string) (map[string]json.RawMessage, error) {
+	if headSHA != m.checkpointHead {
+		return nil, nil
+	}
+	return m.checkpoints[key], nil
+}
+
+func TestReviewPR_StateSurvivesSummaryDeletion(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+			"util.go":    "package util\n",
+		},
+		prFiles: []ghclient.PRFile{{Filename: "util.go", Status: "modified", Patch: "@@ -0,0 +1 @@\n+package util"}},
+	}
+	llmMock := &mockLLMProvider{response: `{"violations": [{"line": 1, "rule": "Error Handling", "message": "Wrap the error", "severity": "error"}]}`}
+	st := newMemoryState()
+	svc := NewService(ghMock, llmMock, Config{})
+	svc.SetStateStore(st)
+
+	req := ReviewRequest{Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123", HeadRef: "feature-branch"}
+	if _, err := svc.ReviewPR(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	saved, ok := st.prs[state.Key{Owner: "test", Repo: "repo", PRNumber: 1}]
+	if !ok {
+		t.Fatal("expected the review state to be saved")
+	}
+	if saved.HeadSHA != "abc123" || len(saved.Files) != 1 || saved.Files[0].SHA != "abc123" || saved.Files[0].Violations != 1 {
+		t.Errorf("saved state = %+v, want util.go reviewed at abc123 with 1 violation", saved)
+	}
+	var rest ReviewSummary
+	if err := json.Unmarshal(saved.Summary, &rest); err != nil || len(rest.FilesScanned) != 0 || len(rest.OpenFindings) != 1 {
+		t.Errorf("saved summary = %s, want the open finding without the files", saved.Summary)
+	}
+
+	// The summary comment is deleted; the state store still knows util.go
+	// was reviewed at this commit, whose diff GitHub now leaves out
+	ghMock.prComments = nil
+	ghMock.prFiles[0].Patch = ""
+	prompts := len(llmMock.prompts)
+	if _, err := svc.ReviewPR(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(llmMock.prompts) != prompts {
+		t.Errorf("expected util.go not to be reviewed again, got %d more prompts", len(llmMock.prompts)-prompts)
+	}
+	open, err := svc.OpenFindings(context.Background(), "test", "repo", 1)
+	if err != nil || len(open) != 1 {
+		t.Errorf("OpenFindings() = %+v, %v; want the finding in util.go", open, err)
+	}
+}
+
+func TestReviewPR_StateFallsBackToComment(t *testing.T) {
+	previous, _ := json.Marshal(ReviewSummary{
+		HeadSHA:      "prev123",
+		FilesScanned: []FileReviewStatus{{Path: "util.go", LastSHA: "prev123"}},
+	})
+	ghMock := &mockGitHubClient{
+		prComments: []string{summaryMarkerPrefix + "prev123" + summaryMarkerSuffix + "\n<!-- prmate-data:" + string(previous) + " -->"},
+	}
+	svc := NewService(ghMock, &mockLLMProvider{}, Config{})
+	svc.SetStateStore(newMemoryState())
+
+	sha, err := svc.LastReviewedSHA(context.Background(), "test", "repo", 1)
+	if err != nil || sha != "prev123" {
+		t.Errorf("LastReviewedSHA() = %q, %v; want prev123 from the summary comment", sha, err)
+	}
+}
+
+func TestReviewPR_DryRunSavesNoState(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+		},
+		prFiles: []ghclient.PRFile{{Filename: "util.go", Status: "modified"}},
+		dryRun:  true,
+	}
+	st := newMemoryState()
+	svc := NewService(ghMock, &mockLLMProvider{response: `{"violations": []}`}, Config{})
+	svc.SetStateStore(st)
+
+	if _, err := svc.ReviewPR(context.Background(), ReviewRequest{Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(st.prs) != 0 {
+		t.Errorf("expected no state saved in a dry run, got %+v", st.prs)
+	}
+}
@@ -0,0 +1,132 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+
+	"prmate/internal/checks"
+	"prmate/internal/llm"
+)
+
+// SetFindingHandler has fn called with each finding as soon as it is
+// found, rather than once the review is done; the prmate review command
+// prints findings as they arrive with it. Findings of the LLM arrive while
+// its response streams in when the provider implements
+// llm.StreamingCompleter, and once its response is complete otherwise.
+// Calls to fn are never concurrent. Findings of a file whose analysis then
+// fails are not in the result, and ReviewPR may still drop suppressed ones.
+func (s *Service) SetFindingHandler(fn func(FileViolation)) {
+	s.foundMu.Lock()
+	defer s.foundMu.Unlock()
+	s.found = fn
+}
+
+// streaming reports whether a finding handler is set
+func (s *Service) streaming() bool {
+	s.foundMu.Lock()
+	defer s.foundMu.Unlock()
+	return s.found != nil
+}
+
+// emit passes v, as the result will have it, to the finding handler
+func (s *Service) emit(set *checks.Set, v FileViolation) {
+	if set.Protected(v.Path) {
+		v.Severity = "error"
+	}
+	s.foundMu.Lock()
+	defer s.foundMu.Unlock()
+	if s.found != nil {
+		s.found(v)
+	}
+}
+
+// streamViolations sends one prompt to the LLM, within the per-call
+// timeout, and passes found each violation of the response as soon as it
+// is complete. It returns the whole response.
+func (s *Service) streamViolations(ctx context.Context, prompt, filePath, patch string, found func(FileViolation)) (string, error) {
+	ctx, cancel := withOptionalTimeout(ctx, s.config.LLMTimeout)
+	defer cancel()
+
+	lines := newDiffLines(patch)
+	var sc violationScanner
+	var response strings.Builder
+	handle := func(chunk string) error {
+		response.WriteString(chunk)
+		for _, object := range sc.write(chunk) {
+			var v LLMViolation
+			if err := json.Unmarshal([]byte(object), &v); err != nil {
+				slog.Warn("failed to parse LLM violation", "path", filePath, "error", err)
+				continue
+			}
+			if violation, ok := lines.violation(filePath, v); ok {
+				found(violation)
+			}
+		}
+		return nil
+	}
+
+	streamer, ok := s.llmProvider.(llm.StreamingCompleter)
+	if !ok {
+		out, err := s.llmProvider.GenerateTextWithContext(ctx, prompt)
+		if err != nil {
+			return out, err
+		}
+		return out, handle(out)
+	}
+	err := streamer.ChatStream(ctx, []llm.Message{{Role: "user", Content: prompt}}, handle)
+	return response.String(), err
+}
+
+// violationScanner picks the objects of the violations array out of an
+// analysis response as it arrives, chunk by chunk. Text around the JSON,
+// such as a markdown code fence, is skipped.
+type violationScanner struct {
+	depth    int // braces and brackets open
+	inString bool
+	escaped  bool
+	object   strings.Builder // the violation read so far
+}
+
+// write scans chunk and returns the violation objects it completes
+func (sc *violationScanner) write(chunk string) []string {
+	var objects []string
+	for i := 0; i < len(chunk); i++ {
+		c := chunk[i]
+		// Violations are the objects two levels down: {"violations": [{...}]}
+		if sc.depth >= 3 {
+			sc.object.WriteByte(c)
+		}
+		if sc.inString {
+			switch {
+			case sc.escaped:
+				sc.escaped = false
+			case c == '\\':
+				sc.escaped = true
+			case c == '"':
+				sc.inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			sc.inString = sc.depth > 0
+		case '{', '[':
+			sc.depth++
+			if sc.depth == 3 && c == '{' {
+				sc.object.Reset()
+				sc.object.WriteByte(c)
+			}
+		case '}', ']':
+			if sc.depth == 0 {
+				continue
+			}
+			sc.depth--
+			if sc.depth == 2 && c == '}' {
+				objects = append(objects, sc.object.String())
+			}
+		}
+	}
+	return objects
+}
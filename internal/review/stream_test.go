@@ -0,0 +1,95 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/llm"
+)
+
+// streamingLLM streams its response in chunks, recording how many findings
+// the review had passed on before each chunk
+type streamingLLM struct {
+	chunks []string
+	found  *[]FileViolation
+	before []int
+}
+
+func (m *streamingLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	return strings.Join(m.chunks, ""), nil
+}
+
+func (m *streamingLLM) Chat(ctx context.Context, messages []llm.Message) (string, error) {
+	return strings.Join(m.chunks, ""), nil
+}
+
+func (m *streamingLLM) ChatStream(ctx context.Context, messages []llm.Message, handler llm.StreamHandler) error {
+	for _, chunk := range m.chunks {
+		m.before = append(m.before, len(*m.found))
+		if err := handler(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestReviewFiles_StreamsFindings(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Wrap returned errors\n\n```prmate-checks\nprotected: main.go\n```\n",
+		},
+	}
+	var found []FileViolation
+	llmMock := &streamingLLM{
+		chunks: []string{
+			"```json\n{\"violations\": [{\"line\": 1, \"rule\": \"Error Handling\", \"message\": \"Wrap {the} error\", \"sev",
+			"erity\": \"warning\"}, {\"line\": 2, \"rule\": \"Naming\",",
+			" \"message\": \"Say \\\"handler\\\"\", \"severity\": \"info\"}, {\"line\": 9, \"rule\": \"Naming\", \"message\": \"Off the diff\", \"severity\": \"info\"}",
+			"], \"summary\": \"Two problems\"}\n```",
+		},
+		found: &found,
+	}
+	svc := NewLocalService(ghMock, llmMock, Config{})
+	svc.SetFindingHandler(func(v FileViolation) {
+		found = append(found, v)
+	})
+
+	result, err := svc.ReviewFiles(context.Background(), ReviewRequest{HeadSHA: "abc123"}, []ghclient.PRFile{
+		{Filename: "main.go", Status: "added", Patch: "@@ -0,0 +1,2 @@\n+package main\n+func h() {}"},
+	})
+	if err != nil {
+		t.Fatalf("ReviewFiles() error = %v", err)
+	}
+	if fmt.Sprint(llmMock.before) != "[0 0 1 2]" {
+		t.Errorf("findings passed on before each chunk = %v, want each as soon as it is complete", llmMock.before)
+	}
+	if len(found) != 2 || found[0].Message != "Wrap {the} error" || found[1].Message != `Say "handler"` {
+		t.Fatalf("found = %+v, want the two findings on the diff", found)
+	}
+	for _, v := range found {
+		if v.Severity != "error" {
+			t.Errorf("finding %q severity = %q, want error in a protected file", v.Rule, v.Severity)
+		}
+	}
+	if result.ViolationsFound != 2 || result.Violations[1].Rule != "Naming" {
+		t.Errorf("result = %+v, want the findings passed on", result.Violations)
+	}
+}
+
+func TestViolationScanner(t *testing.T) {
+	response := `Here you go: {"violations": [{"line": 3, "rule": "a", "fix": "x := []int{1}"}, {"line": 4, "rule": "b\"}"}], "summary": "{}"}`
+	for _, size := range []int{1, 7, len(response)} {
+		var sc violationScanner
+		var objects []string
+		for i := 0; i < len(response); i += size {
+			objects = append(objects, sc.write(response[i:min(i+size, len(response))])...)
+		}
+		want := []string{`{"line": 3, "rule": "a", "fix": "x := []int{1}"}`, `{"line": 4, "rule": "b\"}"}`}
+		if strings.Join(objects, "|") != strings.Join(want, "|") {
+			t.Errorf("chunks of %d: objects = %q, want %q", size, objects, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package review
+
+import (
+	"regexp"
+	"strings"
+
+	ghclient "prmate/internal/github"
+)
+
+// fixInstructions asks the LLM for fixes that can be posted as suggested
+// changes
+const fixInstructions = `- "fix" is optional: the corrected code replacing the flagged line, complete and indented as in the file, so it can be committed as is. When it replaces several lines, add "end_line" with the last of them. Leave "fix" out when the correction is not a replacement of those lines
+`
+
+var (
+	// proseFix matches a fix describing a change instead of making it
+	proseFix = regexp.MustCompile(`(?i)^(use|consider|replace|add|remove|wrap|change|rename|move|avoid|prefer|ensure|check|handle|pass|extract|validate|instead|should|you|this|the)\s`)
+	// placeholder matches stand-ins that cannot be committed: <full commit
+	// sha>, redacted secrets and lines left out as ...
+	placeholder = regexp.MustCompile(`<[a-zA-Z]+(?: [a-zA-Z]+)+>|\[REDACTED\]|(?m:^\s*(?://|#)?\s*\.\.\.\s*$)`)
+)
+
+// addedLines maps the new line numbers of the lines patch adds to their
+// content
+func addedLines(patch string) map[int]string {
+	added := make(map[int]string)
+	for _, hunk := range ghclient.ParsePatch(patch) {
+		for _, line := range hunk.Lines {
+			if line.Type == "add" {
+				added[line.NewLineNo] = strings.TrimPrefix(line.Content, "+")
+			}
+		}
+	}
+	return added
+}
+
+// suggestion returns fix as the replacement of lines first to last, or ""
+// when it is not one: when it describes the change in words, holds a
+// placeholder, changes nothing, or the lines are not all added by the
+// change. A fix written without the indentation of the lines gets it.
+func suggestion(fix string, first, last int, added map[int]string) string {
+	fix = strings.TrimRight(fix, "\n")
+	if strings.TrimSpace(fix) == "" || strings.Contains(fix, "```") || placeholder.MatchString(fix) || last < first {
+		return ""
+	}
+	original := make([]string, 0, last-first+1)
+	for n := first; n <= last; n++ {
+		line, ok := added[n]
+		if !ok {
+			return ""
+		}
+		original = append(original, line)
+	}
+
+	trimmed := strings.TrimSpace(fix)
+	if m := proseFix.FindString(trimmed); m != "" && !strings.HasPrefix(strings.TrimSpace(original[0]), m) {
+		return ""
+	}
+	if !strings.Contains(trimmed, "\n") && strings.HasSuffix(trimmed, ".") && !strings.ContainsAny(trimmed, "(){}[];=") {
+		return ""
+	}
+
+	indent := original[0][:len(original[0])-len(strings.TrimLeft(original[0], " \t"))]
+	lines := strings.Split(fix, "\n")
+	if indent != "" && !strings.HasPrefix(lines[0], indent) && strings.TrimLeft(lines[0], " \t") == lines[0] {
+		for i, l := range lines {
+			if l != "" {
+				lines[i] = indent + l
+			}
+		}
+	}
+	replacement := strings.Join(lines, "\n")
+	if replacement == strings.Join(original, "\n") {
+		return ""
+	}
+	return replacement
+}
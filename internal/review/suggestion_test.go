@@ -0,0 +1,80 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+func TestSuggestion(t *testing.T) {
+	added := map[int]string{
+		5: "\t\treturn err",
+		6: "\t}",
+		7: "\tlist := List<String>()",
+	}
+	tests := []struct {
+		name        string
+		fix         string
+		first, last int
+		want        string
+	}{
+		{name: "code keeps its indentation", fix: "\t\treturn fmt.Errorf(\"stat: %w\", err)", first: 5, last: 5, want: "\t\treturn fmt.Errorf(\"stat: %w\", err)"},
+		{name: "code gets the line's indentation", fix: "return fmt.Errorf(\"stat: %w\", err)", first: 5, last: 5, want: "\t\treturn fmt.Errorf(\"stat: %w\", err)"},
+		{name: "several lines", fix: "return nil\n}", first: 5, last: 6, want: "\t\treturn nil\n\t\t}"},
+		{name: "generics are code", fix: "var list = List<String>()", first: 7, last: 7, want: "\tvar list = List<String>()"},
+		{name: "prose", fix: "Use fmt.Errorf(\"context: %w\", err)", first: 5, last: 5},
+		{name: "sentence", fix: "Wrapping the error would give callers context.", first: 5, last: 5},
+		{name: "placeholder", fix: "uses: actions/checkout@<full commit sha>", first: 5, last: 5},
+		{name: "redacted secret", fix: "token := \"[REDACTED]\"", first: 5, last: 5},
+		{name: "elided lines", fix: "return err\n// ...", first: 5, last: 5},
+		{name: "unchanged", fix: "return err", first: 5, last: 5},
+		{name: "line not added", fix: "return nil", first: 4, last: 5},
+		{name: "empty", fix: "", first: 5, last: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestion(tt.fix, tt.first, tt.last, added); got != tt.want {
+				t.Errorf("suggestion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewPR_PostsSuggestedChanges(t *testing.T) {
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+		},
+		prFiles: []ghclient.PRFile{{
+			Filename: "load.go",
+			Status:   "modified",
+			Patch:    "@@ -1,2 +1,5 @@\n package store\n+func Load() error {\n+\tif err := open(); err != nil {\n+\t\treturn err\n+\t}",
+		}},
+	}
+	llmMock := &mockLLMProvider{response: `{"violations": [
+		{"line": 4, "rule": "Error Handling", "message": "Wrap the error", "severity": "warning", "fix": "return fmt.Errorf(\"open: %w\", err)"},
+		{"line": 3, "rule": "Style", "message": "Return early", "severity": "suggestion", "fix": "if err := open(); err != nil {\n\treturn fmt.Errorf(\"open: %w\", err)", "end_line": 4},
+		{"line": 2, "rule": "Docs", "message": "Document Load", "severity": "suggestion", "fix": "Add a doc comment"}
+	]}`}
+	svc := NewService(ghMock, llmMock, Config{})
+
+	if _, err := svc.ReviewPR(context.Background(), ReviewRequest{Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ghMock.postedReviews) != 1 || len(ghMock.postedReviews[0].comments) != 3 {
+		t.Fatalf("posted reviews = %+v, want one with 3 comments", ghMock.postedReviews)
+	}
+	comments := ghMock.postedReviews[0].comments
+
+	if want := "```suggestion\n\t\treturn fmt.Errorf(\"open: %w\", err)\n```"; !strings.Contains(comments[0].Body, want) || comments[0].StartLine != 0 {
+		t.Errorf("single-line comment = %+v, want suggestion %q", comments[0], want)
+	}
+	if c := comments[1]; c.StartLine != 3 || c.Line != 4 || !strings.Contains(c.Body, "```suggestion\n\tif err := open(); err != nil {\n\t\treturn") {
+		t.Errorf("multi-line comment = %+v, want lines 3-4 with the suggestion", c)
+	}
+	if strings.Contains(comments[2].Body, "```suggestion") {
+		t.Errorf("prose fix posted as a suggestion: %q", comments[2].Body)
+	}
+}
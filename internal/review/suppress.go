@@ -0,0 +1,181 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"prmate/internal/checks"
+	ghclient "prmate/internal/github"
+	"prmate/internal/logging"
+)
+
+// ErrNotReviewed is returned when a finding is ignored on a pull request
+// without a review summary to record it in
+var ErrNotReviewed = errors.New("pull request has not been reviewed")
+
+// Suppression is a finding someone asked PRMate to stop reporting on a pull
+// request, by replying "@prmate ignore" to its comment. It is kept in the
+// review state and carried from summary to summary.
+type Suppression struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Rule string `json:"rule"`
+	// Code is the content of the line, trimmed, which finds the finding
+	// again once later pushes move it
+	Code string `json:"code,omitempty"`
+	By   string `json:"by,omitempty"`
+}
+
+// NewSuppression suppresses the finding of rule on line of path, reading
+// the line's content from the diff hunk GitHub shows with a review comment,
+// which ends at the commented line
+func NewSuppression(path string, line int, rule, diffHunk, by string) Suppression {
+	x := Suppression{Path: path, Line: line, Rule: rule, By: by}
+	lines := strings.Split(strings.TrimRight(diffHunk, "\n"), "\n")
+	if last := lines[len(lines)-1]; !strings.HasPrefix(last, "@@") && !strings.HasPrefix(last, "-") {
+		x.Code = trimmedCode(last)
+	}
+	return x
+}
+
+// covers reports whether x suppresses v, whose line holds code. A finding
+// on a line with other content is a new one, even on the same line.
+func (x Suppression) covers(v FileViolation, code string) bool {
+	if x.Path != v.Path || !strings.EqualFold(x.Rule, v.Rule) {
+		return false
+	}
+	if x.Code != "" {
+		return code == x.Code
+	}
+	return x.Line == v.Line
+}
+
+// trimmedCode returns the content of a patch line without its +, - or
+// space prefix and surrounding whitespace
+func trimmedCode(line string) string {
+	if line != "" {
+		line = line[1:]
+	}
+	return strings.TrimSpace(line)
+}
+
+// suppress drops the findings .prmate.md suppresses and those ignored on
+// the pull request, uncounting them in the statuses of their files, and
+// returns the rest and how many were dropped
+func suppress(violations []FileViolation, statuses []FileReviewStatus, files []ghclient.PRFile, set *checks.Set, ignored []Suppression) ([]FileViolation, int) {
+	if len(set.Suppress) == 0 && len(ignored) == 0 {
+		return violations, 0
+	}
+	patches := make(map[string]string, len(files))
+	for _, f := range files {
+		patches[f.Filename] = f.Patch
+	}
+	lines := make(map[string]map[int]string) // content of the lines of each patch, by new line number
+	code := func(v FileViolation) string {
+		if _, ok := lines[v.Path]; !ok {
+			lines[v.Path] = make(map[int]string)
+			for _, hunk := range ghclient.ParsePatch(patches[v.Path]) {
+				for _, l := range hunk.Lines {
+					if l.Type != "remove" {
+						lines[v.Path][l.NewLineNo] = trimmedCode(l.Content)
+					}
+				}
+			}
+		}
+		return lines[v.Path][v.Line]
+	}
+
+	return drop(violations, statuses, func(v FileViolation) bool {
+		if set.Suppressed(v.Path, v.Rule) {
+			return true
+		}
+		for _, x := range ignored {
+			if x.covers(v, code(v)) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// settleIgnored counts the threads of ignored findings as resolved, so
+// they are no longer outstanding
+func settleIgnored(threads []ghclient.ReviewThread, ignored []Suppression) {
+	for i, t := range threads {
+		rule, _, ok := FindingComment(t.Body)
+		if !t.Bot || !ok {
+			continue
+		}
+		for _, x := range ignored {
+			if x.Path == t.Path && x.Line == t.Line && strings.EqualFold(x.Rule, rule) {
+				threads[i].Resolved = true
+				break
+			}
+		}
+	}
+}
+
+// Suppress records x in the review state of a pull request, so later
+// reviews no longer report the finding. It returns ErrNotReviewed when the
+// pull request has no review to record it in.
+func (s *Service) Suppress(ctx context.Context, owner, repo string, prNumber int, x Suppression) error {
+	summary, err := s.getPreviousSummary(ctx, owner, repo, prNumber)
+	if err != nil {
+		return fmt.Errorf("get previous summary: %w", err)
+	}
+	if summary == nil {
+		return ErrNotReviewed
+	}
+	for _, existing := range summary.Suppressions {
+		if existing.Path == x.Path && existing.Line == x.Line && strings.EqualFold(existing.Rule, x.Rule) && existing.Code == x.Code {
+			return nil
+		}
+	}
+	summary.Suppressions = append(summary.Suppressions, x)
+
+	req := ReviewRequest{Owner: owner, Repo: repo, PRNumber: prNumber, HeadSHA: summary.HeadSHA}
+	s.saveState(ctx, req, *summary)
+	return s.updateSummaryData(ctx, req, *summary)
+}
+
+// updateSummaryData replaces the hidden data of the summary comment with
+// summary, leaving what it shows alone. A pull request whose summary
+// comment is gone keeps the state store's copy only.
+func (s *Service) updateSummaryData(ctx context.Context, req ReviewRequest, summary ReviewSummary) error {
+	commentID, body, err := s.summaryComment(ctx, req)
+	if err != nil {
+		return err
+	}
+	start := strings.Index(body, summaryDataPrefix)
+	if commentID == 0 || start == -1 {
+		logging.FromContext(ctx).Warn("no summary comment to record the suppression in")
+		return nil
+	}
+	end := strings.Index(body[start:], summaryMarkerSuffix)
+	if end == -1 {
+		return fmt.Errorf("malformed summary data")
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("marshal summary: %w", err)
+	}
+	body = body[:start] + summaryDataPrefix + string(data) + body[start+end:]
+	if err := s.githubClient.UpdatePRComment(ctx, req.Owner, req.Repo, req.PRNumber, commentID, body); err != nil {
+		return fmt.Errorf("update summary comment: %w", err)
+	}
+	return nil
+}
+
+// summaryComment returns the ID and body of the summary comment on the pull
+// request, or 0 when it has none
+func (s *Service) summaryComment(ctx context.Context, req ReviewRequest) (int64, string, error) {
+	commentID, body, err := s.githubClient.FindPRComment(ctx, req.Owner, req.Repo, req.PRNumber, summaryMarkerPrefix)
+	if err != nil {
+		return 0, "", fmt.Errorf("find summary comment: %w", err)
+	}
+	return commentID, body, nil
+}
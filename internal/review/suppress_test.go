@@ -0,0 +1,111 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+func TestNewSuppression(t *testing.T) {
+	tests := []struct {
+		name string
+		hunk string
+		want string
+	}{
+		{name: "added line", hunk: "@@ -1,2 +1,3 @@\n package main\n+\treturn err\n", want: "return err"},
+		{name: "context line", hunk: "@@ -1,2 +1,2 @@\n func f() {\n \tx := 1", want: "x := 1"},
+		{name: "removed line", hunk: "@@ -1,2 +1,1 @@\n package main\n-\treturn err", want: ""},
+		{name: "no hunk", hunk: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewSuppression("main.go", 2, "Error Handling", tt.hunk, "octocat")
+			if got.Code != tt.want || got.Path != "main.go" || got.Line != 2 || got.By != "octocat" {
+				t.Errorf("NewSuppression() = %+v, want code %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewPR_Suppressions(t *testing.T) {
+	// The ignored finding was on line 4, which a later push moved to line 6
+	previous, _ := json.Marshal(ReviewSummary{
+		Version: deltaVersion,
+		HeadSHA: "prev123",
+		Suppressions: []Suppression{
+			{Path: "handler.go", Line: 4, Rule: "Error Handling", Code: "return err", By: "octocat"},
+		},
+	})
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md": "# PRMate Context\n\n## Learned Rules\n- Wrap errors\n\n```prmate-checks\nsuppress: Naming\npaths: legacy/**\n```\n",
+		},
+		prFiles: []ghclient.PRFile{
+			{Filename: "handler.go", Status: "modified", Patch: "@@ -1,0 +4,4 @@\n+\tif err != nil {\n+\t\tlog(err)\n+\t\treturn err\n+\t}"},
+			{Filename: "legacy/old.go", Status: "modified", Patch: "@@ -1,0 +1 @@\n+var X_y = 1"},
+		},
+		prComments: []string{summaryMarkerPrefix + "prev123" + summaryMarkerSuffix + "\n" + summaryDataPrefix + string(previous) + summaryMarkerSuffix},
+	}
+	llmMock := &mockLLMProvider{response: `{"violations": [
+		{"line": 6, "rule": "Error Handling", "message": "Wrap the error", "severity": "error"},
+		{"line": 5, "rule": "Error Handling", "message": "Handle the error", "severity": "error"},
+		{"line": 1, "rule": "Naming", "message": "Use camelCase", "severity": "warning"}
+	]}`}
+
+	svc := NewService(ghMock, llmMock, Config{})
+	result, err := svc.ReviewPR(context.Background(), ReviewRequest{Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "head456"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Findings outside the diff are dropped first, leaving the ignored one on
+	// line 6 and the suppressed naming finding in legacy/old.go
+	if result.ViolationsFound != 1 {
+		t.Errorf("ViolationsFound = %d, want only the finding on line 5", result.ViolationsFound)
+	}
+	for _, v := range result.Violations {
+		if v.Line != 5 || v.Rule != "Error Handling" {
+			t.Errorf("reported %+v, want only the finding on line 5", v)
+		}
+	}
+	body := ghMock.updatedComments[1]
+	for _, want := range []string{"| Suppressed | 2 |", `"suppressions":[{"path":"handler.go","line":4,"rule":"Error Handling","code":"return err","by":"octocat"}]`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("summary missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestService_Suppress(t *testing.T) {
+	previous, _ := json.Marshal(ReviewSummary{Version: deltaVersion, HeadSHA: "prev123"})
+	shown := summaryMarkerPrefix + "prev123" + summaryMarkerSuffix + "\n## PRMate Review Summary\n"
+	ghMock := &mockGitHubClient{
+		prComments: []string{"LGTM", shown + "\n" + summaryDataPrefix + string(previous) + summaryMarkerSuffix},
+	}
+	svc := NewService(ghMock, &mockLLMProvider{}, Config{})
+
+	x := Suppression{Path: "handler.go", Line: 4, Rule: "Error Handling", Code: "return err", By: "octocat"}
+	if err := svc.Suppress(context.Background(), "test", "repo", 1, x); err != nil {
+		t.Fatalf("Suppress() error = %v", err)
+	}
+	body, ok := ghMock.updatedComments[2]
+	if !ok || !strings.HasPrefix(body, shown) {
+		t.Fatalf("updated comments = %v, want the summary with what it shows unchanged", ghMock.updatedComments)
+	}
+	summary, err := parseSummaryFromComment(body)
+	if err != nil {
+		t.Fatalf("parse updated summary: %v", err)
+	}
+	if len(summary.Suppressions) != 1 || summary.Suppressions[0] != x || summary.HeadSHA != "prev123" {
+		t.Errorf("summary = %+v, want the suppression recorded", summary)
+	}
+
+	empty := &mockGitHubClient{}
+	if err := NewService(empty, &mockLLMProvider{}, Config{}).Suppress(context.Background(), "test", "repo", 1, x); !errors.Is(err, ErrNotReviewed) {
+		t.Errorf("Suppress() without a review error = %v, want ErrNotReviewed", err)
+	}
+}
@@ -1,6 +1,11 @@
 package review
 
-import "time"
+import (
+	"time"
+
+	"prmate/internal/checks"
+	"prmate/internal/smoke"
+)
 
 // ReviewRequest contains parameters for reviewing a PR
 type ReviewRequest struct {
@@ -10,6 +15,40 @@ type ReviewRequest struct {
 	HeadSHA  string
 	HeadRef  string
 	BaseSHA  string
+	// ConfigRef is where .prmate.md is read; HeadRef when empty. Pull
+	// requests from forks use their base branch.
+	ConfigRef string
+	// Full reviews every changed file, including those an earlier review
+	// already looked at at this head, as when someone asks for a review
+	Full bool
+	// ChecksOnly runs only the deterministic checks for this review, as
+	// when the repository has spent its LLM token budget
+	ChecksOnly bool
+	// Exemptions are the rules maintainers let this pull request break
+	Exemptions []Exemption
+	// Preferences are how the pull request's author wants to hear about
+	// its findings
+	Preferences Preferences
+	// Smoke are the smoke check commands that failed in the pull
+	// request's workspace
+	Smoke []smoke.Failure
+	// OtherPRs are the other open pull requests of the repository, with
+	// the files they changed as of their latest review
+	OtherPRs []OpenPR
+}
+
+// OpenPR is another open pull request of the repository under review
+type OpenPR struct {
+	Number int
+	Files  []string
+}
+
+// configRef returns the ref .prmate.md is read at
+func (r ReviewRequest) configRef() string {
+	if r.ConfigRef != "" {
+		return r.ConfigRef
+	}
+	return r.HeadRef
 }
 
 // ReviewResult contains the outcome of a PR review
@@ -19,6 +58,11 @@ type ReviewResult struct {
 	ViolationsFound int
 	SummaryPosted   bool
 	ReviewedCommit  string
+	Violations      []FileViolation
+	EstimatedTokens int // prompt + response, see estimateTokens
+	Channels        []string // notification channels of the routes the pull request changes
+	Files           []string // paths of the files the pull request changes
+	Blocking        bool     // the review requests changes, see blocks
 }
 
 // FileViolation represents a rule violation found in a file
@@ -29,6 +73,48 @@ type FileViolation struct {
 	Message      string
 	Severity     string // "error", "warning", "suggestion"
 	CodeSnippet  string
+	Why          string // Markdown telling why the rule exists, see explainFindings
+	Suggestion   string // code replacing lines Line to EndLine, posted as a suggested change
+	EndLine      int    // last line Suggestion replaces; 0 when it replaces Line alone
+}
+
+// Rules is what a .prmate.md file contributes to a review
+type Rules struct {
+	Rules        []string
+	Checklist    []string
+	CodebaseInfo string
+	Checks       *checks.Set // deterministic checks and path filters
+	Infra        []string    // conventions for Dockerfiles, compose files and workflows
+	Routes       map[string]Rules // rules files of the routes declaring one, by route prefix
+	Persona      string           // reviewer persona chosen in the front matter; empty uses the configured one
+	Docs         map[string]RuleDoc // docs of the rules and conventions with an ID, by ID
+	Warning      string             // why a .prmate.md that is not blank gives reviews nothing to check
+}
+
+// count returns how many rules, checklist items and checks apply
+func (r Rules) count() int {
+	n := len(r.Rules) + len(r.Checklist) + len(r.Infra)
+	for _, team := range r.Routes {
+		n += len(team.Rules) + len(team.Checklist)
+	}
+	if r.Checks != nil {
+		n += len(r.Checks.Checks)
+		if r.Checks.Assets != nil {
+			n++
+		}
+		if r.Checks.Docs != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// language returns the language LLM findings are written in, "" for English
+func (r Rules) language() string {
+	if r.Checks == nil {
+		return ""
+	}
+	return r.Checks.Language
 }
 
 // ReviewSummary is the tracking data stored in PR comments
@@ -39,6 +125,28 @@ type ReviewSummary struct {
 	FilesScanned    []FileReviewStatus  `json:"files_scanned"`
 	RulesApplied    int                 `json:"rules_applied"`
 	ViolationsFound int                 `json:"violations_found"`
+	OpenFindings    []OpenFinding       `json:"open_findings,omitempty"`
+	FollowUp        *FollowUp           `json:"follow_up,omitempty"`
+	Findings        []OpenFinding       `json:"findings,omitempty"`     // every finding of the PR as of this review, of any severity
+	Delta           *Delta              `json:"delta,omitempty"`        // change since the previous review
+	Exempted        int                 `json:"exempted,omitempty"`     // findings suppressed by exemptions
+	Muted           int                 `json:"muted,omitempty"`        // findings not commented on, muted by the author
+	Suppressed      int                 `json:"suppressed,omitempty"`   // findings suppressed in .prmate.md or ignored on the PR
+	Suppressions    []Suppression       `json:"suppressions,omitempty"` // findings ignored on the PR, carried to every later review
+	Overlaps        []Overlap           `json:"overlaps,omitempty"`     // other open pull requests changing the same files
+	RulesWarning    string              `json:"rules_warning,omitempty"` // why .prmate.md gave the review nothing to check
+}
+
+// OpenFinding is an error-severity finding no later review has cleared. It
+// is carried from summary to summary until its file is reviewed again or
+// its review thread is resolved.
+type OpenFinding struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Replies  int    `json:"replies,omitempty"` // answers on its review thread
 }
 
 // FileReviewStatus tracks review state per file
@@ -72,4 +180,5 @@ type LLMViolation struct {
 	Message  string `json:"message"`
 	Severity string `json:"severity"`
 	Fix      string `json:"fix,omitempty"`
+	EndLine  int    `json:"end_line,omitempty"` // last line fix replaces, when it replaces several
 }
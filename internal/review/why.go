@@ -0,0 +1,171 @@
+package review
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"prmate/internal/logging"
+	"prmate/internal/tracing"
+)
+
+// maxExplanations bounds the rule explanations the LLM writes for one
+// review; findings of the remaining rules go without one until a later
+// review
+const maxExplanations = 5
+
+// ruleID matches a rule that names itself, optionally linking to its
+// documentation:
+//
+//   - [wrap-errors] Wrap errors with context using fmt.Errorf
+//   - [wrap-errors](https://wiki.example.com/errors) Wrap errors with context
+var ruleID = regexp.MustCompile(`^\[([A-Za-z0-9][A-Za-z0-9_.-]+)\](?:\((https?://[^)\s]+)\))?\s+(.+)$`)
+
+// RuleDoc tells developers why a rule with an ID exists
+type RuleDoc struct {
+	Text string // the rule as written
+	URL  string // documentation of the rule; without it the LLM explains the rule
+}
+
+// identify records the docs of the rules naming themselves and returns the
+// rules as the LLM sees them: with their IDs, without their URLs
+func (r *Rules) identify(rules []string) []string {
+	for i, rule := range rules {
+		m := ruleID.FindStringSubmatch(rule)
+		if m == nil {
+			continue
+		}
+		if r.Docs == nil {
+			r.Docs = make(map[string]RuleDoc)
+		}
+		r.Docs[m[1]] = RuleDoc{Text: m[3], URL: m[2]}
+		rules[i] = fmt.Sprintf("[%s] %s", m[1], m[3])
+	}
+	return rules
+}
+
+// namesRules reports whether any of rules has an ID the LLM should report
+// it by
+func namesRules(rules []string) bool {
+	return slices.ContainsFunc(rules, ruleID.MatchString)
+}
+
+// doc returns the doc of the rule with id as the file at path sees it: its
+// route's rules first, then .prmate.md and its checks
+func (r Rules) doc(path, id string) (RuleDoc, bool) {
+	if d, ok := r.forFile(path).Docs[id]; ok {
+		return d, true
+	}
+	if d, ok := r.Docs[id]; ok {
+		return d, true
+	}
+	if r.Checks != nil {
+		for _, c := range r.Checks.Checks {
+			if c.ID == id {
+				return RuleDoc{Text: c.Message, URL: c.URL}, true
+			}
+		}
+	}
+	return RuleDoc{}, false
+}
+
+// explainFindings gives the findings of rules with an ID their "Why": a
+// link to the rule's documentation, or else an explanation the LLM writes
+// once per wording of the rule and the service keeps for later reviews. It
+// returns the tokens spent on new explanations.
+func (s *Service) explainFindings(ctx context.Context, req ReviewRequest, rules Rules, violations []FileViolation) int {
+	ctx, span := tracing.Start(ctx, "review.explain")
+	defer span.End()
+
+	logger := logging.FromContext(ctx)
+	tokens, written := 0, 0
+	for i := range violations {
+		v := &violations[i]
+		doc, ok := rules.doc(v.Path, v.Rule)
+		if !ok {
+			continue
+		}
+		if doc.URL != "" {
+			v.Why = fmt.Sprintf("Why: [%s](%s)", v.Rule, doc.URL)
+			continue
+		}
+
+		key := explanationKey(v.Rule, doc.Text, rules.language())
+		explanation, cached := s.explanation(key)
+		if !cached {
+			if s.offline(req) || written == maxExplanations {
+				continue
+			}
+			var n int
+			var err error
+			explanation, n, err = s.explainRule(ctx, doc.Text, rules.language())
+			tokens += n
+			written++
+			if err != nil {
+				logger.Warn("could not explain rule", "rule", v.Rule, "error", err)
+				continue
+			}
+			s.keepExplanation(key, explanation)
+		}
+		v.Why = "<details><summary>Why?</summary>\n\n" + explanation + "\n</details>"
+	}
+	span.SetAttributes(attribute.Int("review.explanations_written", written))
+	return tokens
+}
+
+// explanationKey identifies an explanation by what it explains, so editing
+// a rule in .prmate.md has it explained again
+func explanationKey(id, text, language string) string {
+	sum := sha256.Sum256([]byte(id + "\n" + text + "\n" + language))
+	return hex.EncodeToString(sum[:])
+}
+
+// explanation returns the cached explanation under key
+func (s *Service) explanation(key string) (string, bool) {
+	s.explainMu.Lock()
+	defer s.explainMu.Unlock()
+	e, ok := s.explained[key]
+	return e, ok
+}
+
+// keepExplanation caches an explanation under key
+func (s *Service) keepExplanation(key, explanation string) {
+	s.explainMu.Lock()
+	defer s.explainMu.Unlock()
+	if s.explained == nil {
+		s.explained = make(map[string]string)
+	}
+	s.explained[key] = explanation
+}
+
+// explainRule has the LLM explain why a project follows rule
+func (s *Service) explainRule(ctx context.Context, rule, language string) (string, int, error) {
+	var sb strings.Builder
+	sb.WriteString("Explain to a developer, in two or three sentences, why a software project follows this code review rule: the problem it prevents and what the codebase gains from it. Do not restate the rule and do not refer to any particular change.\n\n")
+	sb.WriteString("Rule: " + rule + "\n\n")
+	if language != "" {
+		sb.WriteString(fmt.Sprintf("Write the explanation in %s.\n", languageName(language)))
+	}
+	sb.WriteString("Respond with ONLY the explanation, as plain Markdown without headings.\n")
+	prompt := sb.String()
+
+	llmCtx, cancel := withOptionalTimeout(ctx, s.config.LLMTimeout)
+	defer cancel()
+
+	response, err := s.llmProvider.GenerateTextWithContext(llmCtx, prompt)
+	tokens := estimateTokens(prompt) + estimateTokens(response)
+	if err != nil {
+		return "", tokens, fmt.Errorf("llm explanation: %w", err)
+	}
+	response = strings.TrimSpace(response)
+	if response == "" {
+		return "", tokens, fmt.Errorf("llm explanation: empty response")
+	}
+	return response, tokens, nil
+}
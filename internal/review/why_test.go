@@ -0,0 +1,121 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+// whyLLM reports findings of the rules with an ID and explains rules,
+// counting the explanations it writes
+type whyLLM struct {
+	explained int
+}
+
+func (m *whyLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	if strings.HasPrefix(prompt, "Explain to a developer") {
+		m.explained++
+		return "Short functions are easier to test and review.", nil
+	}
+	return `{"violations": [` +
+		`{"line": 4, "rule": "wrap-errors", "message": "Wrap this error", "severity": "warning"},` +
+		`{"line": 5, "rule": "small-funcs", "message": "Split this function", "severity": "warning"},` +
+		`{"line": 6, "rule": "small-funcs", "message": "Split this one too", "severity": "warning"},` +
+		`{"line": 7, "rule": "Style", "message": "Consider this", "severity": "suggestion"}]}`, nil
+}
+
+func TestParseRules_IDs(t *testing.T) {
+	rules, err := ParseRules("## Learned Rules\n" +
+		"- [wrap-errors](https://wiki.example.com/errors) Wrap errors with context\n" +
+		"- [small-funcs] Keep functions under 50 lines\n" +
+		"- Use context.Context as first parameter\n")
+	if err != nil {
+		t.Fatalf("ParseRules() error = %v", err)
+	}
+
+	want := []string{
+		"[wrap-errors] Wrap errors with context",
+		"[small-funcs] Keep functions under 50 lines",
+		"Use context.Context as first parameter",
+	}
+	if strings.Join(rules.Rules, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Rules = %q, want %q", rules.Rules, want)
+	}
+	if d := rules.Docs["wrap-errors"]; d.URL != "https://wiki.example.com/errors" || d.Text != "Wrap errors with context" {
+		t.Errorf("Docs[wrap-errors] = %+v", d)
+	}
+	if d, ok := rules.Docs["small-funcs"]; !ok || d.URL != "" {
+		t.Errorf("Docs[small-funcs] = %+v, %v; want a doc without URL", d, ok)
+	}
+	if len(rules.Docs) != 2 {
+		t.Errorf("Docs = %v, want the two rules with an ID", rules.Docs)
+	}
+}
+
+func TestReviewPR_Why(t *testing.T) {
+	prmateMD := "## Learned Rules\n" +
+		"- [wrap-errors](https://wiki.example.com/errors) Wrap errors with context\n" +
+		"- [small-funcs] Keep functions under 50 lines\n"
+	file := ghclient.PRFile{Filename: "main.go", Status: "modified", Additions: 4, Patch: "@@ -3,0 +4,4 @@\n+a\n+b\n+c\n+d"}
+	llmMock := &whyLLM{}
+	svc := NewService(nil, llmMock, Config{})
+
+	for review := 1; review <= 2; review++ {
+		ghMock := &mockGitHubClient{
+			fileContents: map[string]string{".prmate.md": prmateMD},
+			prFiles:      []ghclient.PRFile{file},
+		}
+		svc.githubClient, svc.content = ghMock, ghMock
+		if _, err := svc.ReviewPR(context.Background(), ReviewRequest{
+			Owner: "test", Repo: "repo", PRNumber: review, HeadSHA: "abc123def456789", HeadRef: "feature-branch",
+		}); err != nil {
+			t.Fatalf("ReviewPR() error = %v", err)
+		}
+
+		comments := ghMock.postedReviews[0].comments
+		if len(comments) != 4 {
+			t.Fatalf("got %d comments, want 4", len(comments))
+		}
+		if body := comments[0].Body; !strings.HasSuffix(body, "\n\nWhy: [wrap-errors](https://wiki.example.com/errors)") {
+			t.Errorf("comment of a rule with a URL = %q, want a link", body)
+		}
+		for _, c := range comments[1:3] {
+			if !strings.Contains(c.Body, "<details><summary>Why?</summary>\n\nShort functions are easier to test and review.\n</details>") {
+				t.Errorf("comment of a rule without URL = %q, want the explanation", c.Body)
+			}
+			if rule, _, ok := FindingComment(c.Body); !ok || rule != "small-funcs" {
+				t.Errorf("FindingComment() = %q, %v; the explanation should not hide the finding", rule, ok)
+			}
+		}
+		if body := comments[3].Body; strings.Contains(body, "Why") {
+			t.Errorf("comment of a rule without an ID = %q, want no Why", body)
+		}
+	}
+	if llmMock.explained != 1 {
+		t.Errorf("rule explained %d times, want once", llmMock.explained)
+	}
+}
+
+func TestExplainFindings_Offline(t *testing.T) {
+	rules, err := ParseRules("## Learned Rules\n- [small-funcs] Keep functions under 50 lines\n\n" +
+		"```prmate-checks\nid: no-println\npattern: fmt\\.Println\nurl: https://wiki.example.com/logging\n```\n")
+	if err != nil {
+		t.Fatalf("ParseRules() error = %v", err)
+	}
+	llmMock := &whyLLM{}
+	violations := []FileViolation{
+		{Path: "main.go", Line: 4, Rule: "no-println", Message: "Use the logger"},
+		{Path: "main.go", Line: 5, Rule: "small-funcs", Message: "Split this function"},
+	}
+
+	NewService(nil, llmMock, Config{Offline: true}).explainFindings(context.Background(), ReviewRequest{}, rules, violations)
+
+	if violations[0].Why != "Why: [no-println](https://wiki.example.com/logging)" {
+		t.Errorf("Why of a check with a URL = %q", violations[0].Why)
+	}
+	if violations[1].Why != "" || llmMock.explained != 0 {
+		t.Errorf("offline reviews should not explain rules, got %q", violations[1].Why)
+	}
+}
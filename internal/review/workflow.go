@@ -0,0 +1,88 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/logging"
+	"prmate/internal/scanner"
+	"prmate/internal/workflow"
+)
+
+// infraKind returns the kind of infrastructure file path is when it is
+// reviewed with the infrastructure prompt, or "". Workflows always are
+// while the workflow scan is on; other files need infrastructure
+// conventions to be held to.
+func (s *Service) infraKind(rules Rules, path string) string {
+	kind := scanner.InfraKind(path)
+	if len(rules.Infra) > 0 || (kind == scanner.InfraWorkflow && s.config.WorkflowScan) {
+		return kind
+	}
+	return ""
+}
+
+// workflowViolations reports the security problems a changed GitHub
+// Actions workflow has on the lines it adds. The whole workflow is read:
+// whether a line is a problem depends on the triggers and steps around it.
+func (s *Service) workflowViolations(ctx context.Context, req ReviewRequest, file ghclient.PRFile) []FileViolation {
+	if !s.config.WorkflowScan || scanner.InfraKind(file.Filename) != scanner.InfraWorkflow {
+		return nil
+	}
+	content, err := s.content.GetFileContent(ctx, req.Owner, req.Repo, file.Filename, req.HeadRef)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to read workflow", "path", file.Filename, "error", err)
+		return nil
+	}
+
+	added := make(map[int]bool)
+	for _, line := range ghclient.GetNewLineNumbers(file.Patch) {
+		added[line] = true
+	}
+	var violations []FileViolation
+	for _, f := range workflow.Scan(content) {
+		if !added[f.Line] {
+			continue
+		}
+		violations = append(violations, FileViolation{
+			Path:     file.Filename,
+			Line:     f.Line,
+			Rule:     f.Rule,
+			Message:  f.Message,
+			Severity: f.Severity,
+		})
+	}
+	return violations
+}
+
+// withoutRepeats drops the LLM findings that repeat a deterministic finding
+// on the same line under the same rule
+func withoutRepeats(llmViolations, found []FileViolation) []FileViolation {
+	if len(found) == 0 {
+		return llmViolations
+	}
+	seen := make(map[string]bool, len(found))
+	for _, v := range found {
+		seen[fmt.Sprintf("%d:%s", v.Line, v.Rule)] = true
+	}
+	kept := make([]FileViolation, 0, len(llmViolations))
+	for _, v := range llmViolations {
+		if !seen[fmt.Sprintf("%d:%s", v.Line, v.Rule)] {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// writeWorkflowSecurity adds the security problems to look for in a
+// workflow to its review prompt
+func writeWorkflowSecurity(sb *strings.Builder) {
+	sb.WriteString("## Workflow Security\n")
+	sb.WriteString("Report these problems on changed lines, using the name in bold as the \"rule\":\n")
+	sb.WriteString(fmt.Sprintf("1. **%s**: a pull_request_target or workflow_run job that checks out, builds or runs code from the pull request, which a fork controls, while holding secrets or a write token\n", workflow.RuleUntrustedCheckout))
+	sb.WriteString(fmt.Sprintf("2. **%s**: secrets, or a GITHUB_TOKEN with write access, reachable by code from a fork, including through secrets: inherit, artifacts or caches written by untrusted code\n", workflow.RuleForkSecrets))
+	sb.WriteString(fmt.Sprintf("3. **%s**: a third-party action or reusable workflow referenced by a tag or branch instead of a full commit SHA\n", workflow.RuleUnpinnedAction))
+	sb.WriteString(fmt.Sprintf("4. **%s**: permissions: granting write-all, or write scopes to jobs that do not need them\n", workflow.RuleBroadPermissions))
+	sb.WriteString("Also report untrusted input such as ${{ github.event.pull_request.title }} interpolated into run: scripts as an error.\n")
+}
@@ -0,0 +1,93 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+func TestReviewPR_WorkflowScan(t *testing.T) {
+	workflow := `on: pull_request_target
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ref: ${{ github.event.pull_request.head.sha }}
+      - uses: golangci/golangci-lint-action@v6
+      - run: echo "${{ github.event.pull_request.title }}"
+`
+	file := ghclient.PRFile{
+		Filename: ".github/workflows/test.yml",
+		Status:   "modified",
+		// Only the lint step and the run step are new
+		Patch: "@@ -8,0 +9,2 @@\n+      - uses: golangci/golangci-lint-action@v6\n+      - run: echo \"${{ github.event.pull_request.title }}\"",
+	}
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{
+			".prmate.md":  "# PRMate Context\n\n## Learned Rules\n- Use fmt.Errorf with %w for error wrapping\n",
+			file.Filename: workflow,
+		},
+		prFiles: []ghclient.PRFile{file},
+	}
+	llmMock := &mockLLMProvider{response: `{"violations": [
+		{"line": 9, "rule": "Unpinned action", "message": "Pin it", "severity": "warning"},
+		{"line": 10, "rule": "Script injection", "message": "The title is attacker controlled", "severity": "error"}
+	]}`}
+	svc := NewService(ghMock, llmMock, Config{WorkflowScan: true})
+
+	result, err := svc.ReviewPR(context.Background(), ReviewRequest{Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, v := range result.Violations {
+		got = append(got, fmt.Sprintf("%d:%s", v.Line, v.Rule))
+	}
+	// The untrusted checkout on line 8 is not part of the change
+	want := []string{"9:Unpinned action", "10:Script injection"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+	if len(llmMock.prompts) != 1 {
+		t.Fatalf("LLM calls = %d, want 1", len(llmMock.prompts))
+	}
+	prompt := llmMock.prompts[0]
+	if !strings.Contains(prompt, "## Workflow Security") || strings.Contains(prompt, "## Infrastructure Conventions") || strings.Contains(prompt, "## Learned Rules") {
+		t.Errorf("prompt is not the workflow security prompt:\n%s", prompt)
+	}
+}
+
+func TestReviewPR_WorkflowScanOff(t *testing.T) {
+	file := ghclient.PRFile{
+		Filename: ".github/workflows/test.yml",
+		Status:   "added",
+		Patch:    "@@ -0,0 +1,2 @@\n+on: push\n+jobs: {build: {runs-on: ubuntu-latest, steps: [{uses: acme/build@main}]}}",
+	}
+	ghMock := &mockGitHubClient{
+		fileContents: map[string]string{file.Filename: "on: push\njobs: {build: {runs-on: ubuntu-latest, steps: [{uses: acme/build@main}]}}\n"},
+		prFiles:      []ghclient.PRFile{file},
+	}
+	svc := NewService(ghMock, &mockLLMProvider{response: `{"violations": []}`}, Config{Offline: true, WorkflowScan: true})
+	result, err := svc.ReviewPR(context.Background(), ReviewRequest{Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Violations) != 1 || result.Violations[0].Rule != "Unpinned action" {
+		t.Errorf("offline violations = %+v, want the unpinned action", result.Violations)
+	}
+
+	svc = NewService(ghMock, &mockLLMProvider{response: `{"violations": []}`}, Config{Offline: true})
+	result, err = svc.ReviewPR(context.Background(), ReviewRequest{Owner: "test", Repo: "repo", PRNumber: 1, HeadSHA: "abc123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ViolationsFound != 0 {
+		t.Errorf("violations with the scan off = %d, want 0", result.ViolationsFound)
+	}
+}
@@ -0,0 +1,91 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"prmate/internal/errclass"
+	"prmate/internal/github"
+	"prmate/internal/logging"
+)
+
+const (
+	// OnboardingBranch is the branch onboarding pull requests are opened from
+	OnboardingBranch = "prmate/onboarding"
+	// OnboardingLabel marks onboarding pull requests
+	OnboardingLabel = "prmate:onboarding"
+)
+
+// onboardingBody introduces PRMate in the onboarding pull request
+const onboardingBody = `## Welcome to PRMate
+
+PRMate reviews pull requests in this repository once it has a ` + "`.prmate.md`" + ` file. This pull request adds one, generated from a scan of the default branch: the folder structure, naming, abstractions, error handling and test conventions PRMate found, and a checklist it reviews against.
+
+### How PRMate works
+
+- Every pull request that is opened, reopened or pushed to is reviewed against ` + "`.prmate.md`" + `. Findings are posted as inline comments with a summary comment.
+- Edit ` + "`.prmate.md`" + ` to correct the generated context, add review rules, or declare deterministic checks in ` + "`prmate-checks`" + ` blocks.
+- Add an ` + "`@scan`" + ` block to ` + "`.prmate.md`" + ` to regenerate the context, and comment ` + "`@prmate`" + ` on a pull request to run it.
+
+Merge this pull request to start reviews. If you close it instead, PRMate leaves this repository alone and does not open it again.
+`
+
+// Onboarding is the onboarding pull request of a repository
+type Onboarding struct {
+	PRNumber int
+	URL      string
+	Opened   bool // false when an earlier onboarding pull request was found
+}
+
+// Onboard scans the default branch of a repository without .prmate.md and
+// opens a pull request adding the generated file, labeled OnboardingLabel.
+// A repository is onboarded once: when an onboarding pull request exists,
+// open or closed, it is returned instead. Onboard returns nil when the
+// default branch already has .prmate.md, and in dry-run mode.
+func (s *Service) Onboard(ctx context.Context, owner, repo string) (*Onboarding, error) {
+	existing, err := s.githubClient.FindPullRequest(ctx, owner, repo, OnboardingBranch)
+	if err != nil {
+		return nil, fmt.Errorf("find onboarding pull request: %w", err)
+	}
+	if existing != nil {
+		return &Onboarding{PRNumber: existing.Number, URL: existing.HTMLURL}, nil
+	}
+
+	base, err := s.githubClient.GetDefaultBranch(ctx, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("get default branch: %w", err)
+	}
+	_, err = s.githubClient.GetFileContent(ctx, owner, repo, ".prmate.md", base)
+	switch {
+	case err == nil:
+		// Only the pull request's branch predates .prmate.md
+		return nil, nil
+	case !errors.Is(err, errclass.ErrNotFound):
+		return nil, fmt.Errorf("get .prmate.md: %w", err)
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.Info("Onboarding repository", "base", base)
+	if _, err := s.ProcessScan(ctx, ScanRequest{
+		Owner:      owner,
+		Repo:       repo,
+		Branch:     base,
+		PushBranch: OnboardingBranch,
+	}); err != nil {
+		return nil, err
+	}
+
+	pr, err := s.githubClient.CreatePullRequest(ctx, owner, repo, github.NewPullRequest{
+		Title:  "Add .prmate.md to set up PRMate reviews",
+		Body:   onboardingBody,
+		Head:   OnboardingBranch,
+		Base:   base,
+		Labels: []string{OnboardingLabel},
+	})
+	if err != nil || pr == nil {
+		return nil, err
+	}
+	logger.Info("Opened onboarding pull request", "onboarding_pr", pr.Number)
+	return &Onboarding{PRNumber: pr.Number, URL: pr.HTMLURL, Opened: true}, nil
+}
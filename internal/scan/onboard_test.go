@@ -0,0 +1,64 @@
+package scan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"prmate/internal/github"
+)
+
+func TestService_Onboard_SkipsOnboardedRepositories(t *testing.T) {
+	tests := []struct {
+		name  string
+		pulls string
+		want  *Onboarding
+	}{
+		{
+			name:  "earlier onboarding pull request",
+			pulls: `[{"number": 3, "state": "closed", "html_url": "https://github.com/org/repo/pull/3"}]`,
+			want:  &Onboarding{PRNumber: 3, URL: "https://github.com/org/repo/pull/3"},
+		},
+		{
+			name:  ".prmate.md on the default branch",
+			pulls: `[]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v3/repos/org/repo/pulls":
+					if q := r.URL.Query(); q.Get("state") != "all" || q.Get("head") != "org:"+OnboardingBranch {
+						t.Errorf("pulls query = %v", q)
+					}
+					w.Write([]byte(tt.pulls))
+				case "/api/v3/repos/org/repo":
+					w.Write([]byte(`{"default_branch": "main"}`))
+				case "/api/v3/repos/org/repo/contents/.prmate.md":
+					if ref := r.URL.Query().Get("ref"); ref != "main" {
+						t.Errorf("ref = %q, want main", ref)
+					}
+					w.Write([]byte(`{"type": "file", "encoding": "base64", "content": "IyBQUk1hdGU="}`))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+			client, err := github.NewEnterpriseClient("token", srv.URL+"/api/v3/")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := NewService(client, Config{}).Onboard(context.Background(), "org", "repo")
+			if err != nil {
+				t.Fatalf("Onboard() error = %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("Onboard() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,49 +3,145 @@ package scan
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"time"
 
+	"prmate/internal/artifacts"
+	"prmate/internal/audit"
 	prcontext "prmate/internal/context"
 	"prmate/internal/github"
+	"prmate/internal/logging"
+	"prmate/internal/notify"
 	"prmate/internal/scanner"
+	"prmate/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// Config holds tunables for the scan service
+type Config struct {
+	CloneTimeout time.Duration // per git clone; 0 means no limit
+	ScanTimeout  time.Duration // whole ProcessScan call; 0 means no limit
+}
+
+// SpaceChecker reports whether there is disk space for another clone
+type SpaceChecker interface {
+	EnsureSpace(ctx context.Context) error
+}
+
 // Service orchestrates codebase scanning and .prmate.md generation
 type Service struct {
 	githubClient *github.Client
 	generator    *prcontext.Generator
+	config       Config
+	space        SpaceChecker
+	artifacts    artifacts.Store
+	events       EventEmitter
 }
 
 // NewService creates a new scan service
-func NewService(githubClient *github.Client) *Service {
+func NewService(githubClient *github.Client, cfg Config) *Service {
 	return &Service{
 		githubClient: githubClient,
 		generator:    prcontext.NewGenerator(),
+		config:       cfg,
 	}
 }
 
+// SetSpaceChecker makes scans refuse to clone when checker reports the disk
+// quota is exhausted
+func (s *Service) SetSpaceChecker(checker SpaceChecker) {
+	s.space = checker
+}
+
+// SetArtifactStore stores each generated .prmate.md in store instead of a
+// local temp file
+func (s *Service) SetArtifactStore(store artifacts.Store) {
+	s.artifacts = store
+}
+
+// EventEmitter receives a scan.completed event for every finished scan
+type EventEmitter interface {
+	Emit(eventType string, data any)
+}
+
+// SetEventEmitter reports every finished scan, successful or not, to e
+func (s *Service) SetEventEmitter(e EventEmitter) {
+	s.events = e
+}
+
+// emitCompleted sends the scan.completed event for req
+func (s *Service) emitCompleted(req ScanRequest, result *ScanResult, err error) {
+	if s.events == nil {
+		return
+	}
+	e := notify.ScanEvent{
+		Repository:    req.Owner + "/" + req.Repo,
+		PRNumber:      req.PRNumber,
+		Branch:        req.Branch,
+		ExternalRepos: req.ExternalRepos,
+		Status:        "succeeded",
+	}
+	if err != nil {
+		e.Status = "failed"
+		e.Error = err.Error()
+	} else if result != nil {
+		e.ArtifactLocation = result.ArtifactLocation
+	}
+	s.events.Emit(notify.EventScanCompleted, e)
+}
+
+// WorkDirGlob matches every directory scans clone repositories into, for
+// disk usage accounting
+func WorkDirGlob() string {
+	return filepath.Join(os.TempDir(), "prmate-scan*")
+}
+
 // ScanRequest contains parameters for a scan operation
 type ScanRequest struct {
 	Owner         string
 	Repo          string
 	PRNumber      int
-	Branch        string
+	Branch        string   // branch or tag to scan
 	ExternalRepos []string // repos from @scan directive
+	GenerateOnly  bool     // generate .prmate.md without committing it to Branch
+	PushBranch    string   // commit to this branch, replacing it, instead of Branch
 }
 
 // ScanResult contains the results of a scan operation
 type ScanResult struct {
-	PRMateContent string
-	TempFilePath  string
-	Error         error
+	PRMateContent    string
+	TempFilePath     string // set when no artifact store is configured
+	ArtifactLocation string // where the artifact store kept the content
+	Error            error
 }
 
-// ProcessScan runs the full scan flow: clone, scan, generate .prmate.md, commit and push
-func (s *Service) ProcessScan(ctx context.Context, req ScanRequest) (*ScanResult, error) {
-	result := &ScanResult{}
+// ProcessScan runs the full scan flow: clone, scan, generate .prmate.md, commit
+// and push. With GenerateOnly set it stops after generating the content.
+func (s *Service) ProcessScan(ctx context.Context, req ScanRequest) (result *ScanResult, err error) {
+	ctx, span := tracing.Start(ctx, "scan.process",
+		attribute.String("github.repo", req.Owner+"/"+req.Repo),
+		attribute.Int("github.pr", req.PRNumber),
+		attribute.Int("scan.external_repos", len(req.ExternalRepos)),
+	)
+	defer func() { tracing.End(span, err) }()
+	defer func() { s.emitCompleted(req, result, err) }()
+
+	if s.config.ScanTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.ScanTimeout)
+		defer cancel()
+	}
+
+	result = &ScanResult{}
+
+	if s.space != nil {
+		if err := s.space.EnsureSpace(ctx); err != nil {
+			return nil, fmt.Errorf("refusing to clone: %w", err)
+		}
+	}
 
 	// Create temp directory for cloning
 	workDir, err := os.MkdirTemp("", "prmate-scan-*")
@@ -60,33 +156,50 @@ func (s *Service) ProcessScan(ctx context.Context, req ScanRequest) (*ScanResult
 		return nil, fmt.Errorf("clone repo: %w", err)
 	}
 
-	log.Printf("Cloned %s/%s to %s", req.Owner, req.Repo, repoPath)
+	logger := logging.FromContext(ctx)
+	logger.Info("Cloned repository", "path", repoPath)
 
 	// Create multi-repo scanner
-	multiScanner, err := scanner.NewMultiRepoScanner(s.githubClient.GetToken())
+	multiScanner, err := scanner.NewMultiRepoScanner(s.githubClient.GetToken(), s.config.CloneTimeout)
 	if err != nil {
 		return nil, fmt.Errorf("create multi-repo scanner: %w", err)
 	}
 	defer multiScanner.Cleanup()
 
 	// Scan current repo and externals
-	scanResult, err := multiScanner.ScanWithExternals(ctx, repoPath, req.ExternalRepos)
+	scanCtx, scanSpan := tracing.Start(ctx, "scan.analyze")
+	scanResult, err := multiScanner.ScanWithExternals(scanCtx, repoPath, req.ExternalRepos)
+	tracing.End(scanSpan, err)
 	if err != nil {
 		return nil, fmt.Errorf("scan repos: %w", err)
 	}
 
-	log.Printf("Scanned %s with %d external repos", req.Repo, len(req.ExternalRepos))
+	logger.Info("Scanned repository", "external_repos", len(req.ExternalRepos))
 
 	// Generate .prmate.md content
 	content := s.generator.Generate(scanResult)
 	result.PRMateContent = content
 
-	// Write to temp file for reference
-	tempPath, err := s.generator.WriteToTemp(content)
-	if err != nil {
-		return nil, fmt.Errorf("write temp file: %w", err)
+	// Keep a copy for reference
+	if s.artifacts != nil {
+		key := fmt.Sprintf("scans/%s/%s/%s.prmate.md", req.Owner, req.Repo, time.Now().UTC().Format("20060102T150405Z"))
+		location, err := s.artifacts.Put(ctx, key, []byte(content), "text/markdown")
+		if err != nil {
+			return nil, fmt.Errorf("store .prmate.md artifact: %w", err)
+		}
+		result.ArtifactLocation = location
+	} else {
+		tempPath, err := s.generator.WriteToTemp(content)
+		if err != nil {
+			return nil, fmt.Errorf("write temp file: %w", err)
+		}
+		result.TempFilePath = tempPath
+	}
+
+	if req.GenerateOnly {
+		logger.Info("Generated .prmate.md without committing", "ref", req.Branch)
+		return result, nil
 	}
-	result.TempFilePath = tempPath
 
 	// Write .prmate.md to cloned repo and commit+push using git
 	prmatePath := filepath.Join(repoPath, ".prmate.md")
@@ -94,18 +207,80 @@ func (s *Service) ProcessScan(ctx context.Context, req ScanRequest) (*ScanResult
 		return nil, fmt.Errorf("write .prmate.md: %w", err)
 	}
 
+	branch := req.Branch
+	if req.PushBranch != "" {
+		branch = req.PushBranch
+	}
+	if s.githubClient.DryRun(req.Owner, req.Repo) {
+		logger.Info("dry run: would commit and push .prmate.md", "branch", branch)
+		s.githubClient.Audit(ctx, audit.Event{
+			Action:   audit.ActionGitPush,
+			Owner:    req.Owner,
+			Repo:     req.Repo,
+			PRNumber: req.PRNumber,
+			Details:  map[string]string{"branch": branch, "path": ".prmate.md"},
+		}, nil)
+		return result, nil
+	}
+
 	// Commit and push using git
-	if err := s.commitAndPush(ctx, repoPath, req.Branch); err != nil {
+	pushed, err := s.commitAndPush(ctx, repoPath, branch, req.PushBranch != "")
+	if pushed || err != nil {
+		s.githubClient.Audit(ctx, audit.Event{
+			Action:   audit.ActionGitPush,
+			Owner:    req.Owner,
+			Repo:     req.Repo,
+			PRNumber: req.PRNumber,
+			Details:  map[string]string{"branch": branch, "path": ".prmate.md"},
+		}, err)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("commit and push: %w", err)
 	}
 
-	log.Printf("Updated .prmate.md in %s/%s branch %s via git push", req.Owner, req.Repo, req.Branch)
+	logger.Info("Updated .prmate.md via git push", "branch", branch)
 
 	return result, nil
 }
 
+// Generate scans target plus externalRepos and returns .prmate.md content
+// without committing it anywhere. target is a local checkout, or a remote
+// repository address (owner/repo or github.com/owner/repo) which is cloned
+// for the duration of the call.
+func Generate(ctx context.Context, target string, externalRepos []string, githubToken string, cloneTimeout time.Duration) (string, error) {
+	multiScanner, err := scanner.NewMultiRepoScanner(githubToken, cloneTimeout)
+	if err != nil {
+		return "", fmt.Errorf("create multi-repo scanner: %w", err)
+	}
+	defer multiScanner.Cleanup()
+
+	repoPath := target
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		repoPath, err = multiScanner.Clone(ctx, target)
+		if err != nil {
+			return "", fmt.Errorf("clone %s: %w", target, err)
+		}
+	}
+
+	scanResult, err := multiScanner.ScanWithExternals(ctx, repoPath, externalRepos)
+	if err != nil {
+		return "", fmt.Errorf("scan repos: %w", err)
+	}
+
+	return prcontext.NewGenerator().Generate(scanResult), nil
+}
+
 // cloneRepo clones a specific branch of a repo
-func (s *Service) cloneRepo(ctx context.Context, owner, repo, branch, destPath string) error {
+func (s *Service) cloneRepo(ctx context.Context, owner, repo, branch, destPath string) (err error) {
+	ctx, span := tracing.Start(ctx, "scan.clone", attribute.String("git.branch", branch))
+	defer func() { tracing.End(span, err) }()
+
+	if s.config.CloneTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.CloneTimeout)
+		defer cancel()
+	}
+
 	cloneURL := s.githubClient.CloneURL(owner, repo)
 
 	args := []string{"clone", "--depth=1", "--branch", branch, cloneURL, destPath}
@@ -119,19 +294,24 @@ func (s *Service) cloneRepo(ctx context.Context, owner, repo, branch, destPath s
 	return nil
 }
 
-// commitAndPush stages .prmate.md, commits, and pushes to the branch
-func (s *Service) commitAndPush(ctx context.Context, repoPath, branch string) error {
+// commitAndPush stages .prmate.md, commits, and pushes to the branch. With
+// replace set the branch is overwritten with the commit on top of the
+// cloned one. It reports whether a commit was pushed.
+func (s *Service) commitAndPush(ctx context.Context, repoPath, branch string, replace bool) (_ bool, err error) {
+	ctx, span := tracing.Start(ctx, "scan.push", attribute.String("git.branch", branch))
+	defer func() { tracing.End(span, err) }()
+
 	// Configure git user for the commit
 	if err := s.runGit(ctx, repoPath, "config", "user.email", "prmate@github.com"); err != nil {
-		return fmt.Errorf("git config email: %w", err)
+		return false, fmt.Errorf("git config email: %w", err)
 	}
 	if err := s.runGit(ctx, repoPath, "config", "user.name", "PRMate Bot"); err != nil {
-		return fmt.Errorf("git config name: %w", err)
+		return false, fmt.Errorf("git config name: %w", err)
 	}
 
 	// Stage .prmate.md
 	if err := s.runGit(ctx, repoPath, "add", ".prmate.md"); err != nil {
-		return fmt.Errorf("git add: %w", err)
+		return false, fmt.Errorf("git add: %w", err)
 	}
 
 	// Check if there are changes to commit
@@ -139,21 +319,25 @@ func (s *Service) commitAndPush(ctx context.Context, repoPath, branch string) er
 	cmd.Dir = repoPath
 	if err := cmd.Run(); err == nil {
 		// No changes to commit
-		log.Printf("No changes to .prmate.md, skipping commit")
-		return nil
+		logging.FromContext(ctx).Info("No changes to .prmate.md, skipping commit")
+		return false, nil
 	}
 
 	// Commit
 	if err := s.runGit(ctx, repoPath, "commit", "-m", "Update .prmate.md context (auto-generated by PRMate)"); err != nil {
-		return fmt.Errorf("git commit: %w", err)
+		return false, fmt.Errorf("git commit: %w", err)
 	}
 
 	// Push
-	if err := s.runGit(ctx, repoPath, "push", "origin", branch); err != nil {
-		return fmt.Errorf("git push: %w", err)
+	push := []string{"push", "origin", branch}
+	if replace {
+		push = []string{"push", "--force", "origin", "HEAD:refs/heads/" + branch}
+	}
+	if err := s.runGit(ctx, repoPath, push...); err != nil {
+		return false, fmt.Errorf("git push: %w", err)
 	}
 
-	return nil
+	return true, nil
 }
 
 // runGit executes a git command in the given directory
@@ -1,6 +1,10 @@
 package scan
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -123,3 +127,24 @@ func TestScanResult_Fields(t *testing.T) {
 		t.Errorf("Error = %v, want nil", result.Error)
 	}
 }
+
+func TestGenerate_LocalCheckout(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "internal", "store"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "internal", "store", "store.go"), []byte("package store\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := Generate(context.Background(), dir, nil, "", 0)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(content, "# PRMate Context") {
+		t.Errorf("Generate() content missing header:\n%s", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".prmate.md")); !os.IsNotExist(err) {
+		t.Error("Generate() should not write .prmate.md into the checkout")
+	}
+}
@@ -59,6 +59,7 @@ type AnalysisResult struct {
 	ErrorPatterns     []ErrorPattern
 	TestConventions   TestConvention
 	ImportPatterns    []string
+	InfraConventions  InfraConventions
 }
 
 // TestConvention describes how tests are organized
@@ -108,6 +109,9 @@ func (a *Analyzer) Analyze(ctx *CodebaseContext) (*AnalysisResult, error) {
 	// Detect test conventions
 	result.TestConventions = a.detectTestConventions(ctx)
 
+	// Detect Dockerfile, compose and workflow conventions
+	result.InfraConventions = a.detectInfraConventions(ctx)
+
 	return result, nil
 }
 
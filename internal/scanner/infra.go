@@ -0,0 +1,187 @@
+package scanner
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Infrastructure file kinds returned by InfraKind
+const (
+	InfraDockerfile = "dockerfile"
+	InfraCompose    = "compose"
+	InfraWorkflow   = "workflow"
+)
+
+// InfraConventions describes how a repository writes its container and CI
+// configuration
+type InfraConventions struct {
+	Dockerfiles  []string // paths relative to the repository root
+	ComposeFiles []string
+	Workflows    []string
+
+	BaseImages   int // FROM lines and compose image: entries
+	PinnedImages int // images with a version tag or digest, not "latest"
+	DigestImages int // images pinned to a digest
+
+	NonRootDockerfiles int // Dockerfiles whose final stage runs as a non-root USER
+
+	Actions       int // uses: references to actions in other repositories
+	PinnedActions int // actions pinned to a full commit SHA
+}
+
+// Empty reports whether the repository has no infrastructure files
+func (c InfraConventions) Empty() bool {
+	return len(c.Dockerfiles)+len(c.ComposeFiles)+len(c.Workflows) == 0
+}
+
+// InfraKind classifies a repository path as a Dockerfile, a compose file or
+// a GitHub Actions workflow, returning "" for anything else
+func InfraKind(p string) string {
+	p = filepath.ToSlash(p)
+	name := strings.ToLower(path.Base(p))
+	ext := path.Ext(name)
+
+	switch {
+	case name == "dockerfile" || name == "containerfile" ||
+		strings.HasPrefix(name, "dockerfile.") || ext == ".dockerfile":
+		return InfraDockerfile
+	case (ext == ".yml" || ext == ".yaml") &&
+		(strings.HasPrefix(name, "docker-compose") || strings.HasPrefix(name, "compose.")):
+		return InfraCompose
+	case (ext == ".yml" || ext == ".yaml") && strings.Contains("/"+path.Dir(p)+"/", "/.github/workflows/"):
+		return InfraWorkflow
+	}
+	return ""
+}
+
+var (
+	fromRegex     = regexp.MustCompile(`(?i)^\s*FROM\s+(?:--\S+\s+)*(\S+)(?:\s+AS\s+(\S+))?`)
+	userRegex     = regexp.MustCompile(`(?i)^\s*USER\s+(\S+)`)
+	imageRegex    = regexp.MustCompile(`^\s*image:\s*["']?([^"'\s#]+)`)
+	usesRegex     = regexp.MustCompile(`^\s*(?:-\s*)?uses:\s*["']?([^"'\s#]+)`)
+	shaRefRegex   = regexp.MustCompile(`@[0-9a-f]{40}$`)
+	rootUserRegex = regexp.MustCompile(`^(?:root|0)(?::(?:root|0))?$`)
+)
+
+// ImagePinned reports whether an image reference names a fixed version: a
+// tag other than latest, or a digest
+func ImagePinned(ref string) (pinned, digest bool) {
+	if strings.Contains(ref, "@sha256:") {
+		return true, true
+	}
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	_, tag, ok := strings.Cut(name, ":")
+	return ok && tag != "" && tag != "latest", false
+}
+
+// ActionPinned reports whether a workflow uses: reference is pinned to a
+// full commit SHA
+func ActionPinned(ref string) bool {
+	return shaRefRegex.MatchString(ref)
+}
+
+// externalAction reports whether a uses: reference points at an action in
+// another repository, rather than a local action or a container image
+func externalAction(ref string) bool {
+	return !strings.HasPrefix(ref, "./") && !strings.HasPrefix(ref, "docker://")
+}
+
+func (a *Analyzer) detectInfraConventions(ctx *CodebaseContext) InfraConventions {
+	var conv InfraConventions
+
+	for _, file := range ctx.Files {
+		rel, err := filepath.Rel(ctx.RootPath, file.Path)
+		if err != nil {
+			rel = file.Path
+		}
+		rel = filepath.ToSlash(rel)
+
+		kind := InfraKind(rel)
+		if kind == "" {
+			continue
+		}
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			continue
+		}
+		lines := strings.Split(string(content), "\n")
+
+		switch kind {
+		case InfraDockerfile:
+			conv.Dockerfiles = append(conv.Dockerfiles, rel)
+			if scanDockerfile(lines, &conv) {
+				conv.NonRootDockerfiles++
+			}
+		case InfraCompose:
+			conv.ComposeFiles = append(conv.ComposeFiles, rel)
+			for _, line := range lines {
+				if m := imageRegex.FindStringSubmatch(line); m != nil {
+					countImage(m[1], &conv)
+				}
+			}
+		case InfraWorkflow:
+			conv.Workflows = append(conv.Workflows, rel)
+			for _, line := range lines {
+				m := usesRegex.FindStringSubmatch(line)
+				if m == nil || !externalAction(m[1]) {
+					continue
+				}
+				conv.Actions++
+				if ActionPinned(m[1]) {
+					conv.PinnedActions++
+				}
+			}
+		}
+	}
+
+	return conv
+}
+
+// scanDockerfile counts the base images in a Dockerfile and reports whether
+// its final stage runs as a non-root user. Images built by an earlier stage
+// and images chosen through build arguments are not counted.
+func scanDockerfile(lines []string, conv *InfraConventions) bool {
+	stages := make(map[string]bool) // stage name -> runs as non-root
+	stage := ""
+	nonRoot := false
+
+	for _, line := range lines {
+		if m := fromRegex.FindStringSubmatch(line); m != nil {
+			image := strings.ToLower(m[1])
+			parentNonRoot, isStage := stages[image]
+			if !isStage && image != "scratch" && !strings.Contains(image, "$") {
+				countImage(m[1], conv)
+			}
+			// A stage inherits the user of the stage it builds on; images
+			// start as root
+			nonRoot = isStage && parentNonRoot
+			stage = strings.ToLower(m[2])
+			if stage != "" {
+				stages[stage] = nonRoot
+			}
+			continue
+		}
+		if m := userRegex.FindStringSubmatch(line); m != nil {
+			nonRoot = !rootUserRegex.MatchString(m[1])
+			if stage != "" {
+				stages[stage] = nonRoot
+			}
+		}
+	}
+
+	return nonRoot
+}
+
+func countImage(ref string, conv *InfraConventions) {
+	conv.BaseImages++
+	pinned, digest := ImagePinned(ref)
+	if pinned {
+		conv.PinnedImages++
+	}
+	if digest {
+		conv.DigestImages++
+	}
+}
@@ -0,0 +1,114 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInfraKind(t *testing.T) {
+	tests := map[string]string{
+		"Dockerfile":                      InfraDockerfile,
+		"build/Dockerfile.dev":            InfraDockerfile,
+		"api.Dockerfile":                  InfraDockerfile,
+		"Containerfile":                   InfraDockerfile,
+		"docker-compose.yml":              InfraCompose,
+		"deploy/docker-compose.prod.yaml": InfraCompose,
+		"compose.yaml":                    InfraCompose,
+		".github/workflows/ci.yml":        InfraWorkflow,
+		".github/workflows/sub/x.yaml":    InfraWorkflow,
+		".github/dependabot.yml":          "",
+		"config.yml":                      "",
+		"dockerfile_test.go":              "",
+	}
+
+	for path, want := range tests {
+		if got := InfraKind(path); got != want {
+			t.Errorf("InfraKind(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestImagePinned(t *testing.T) {
+	tests := []struct {
+		ref            string
+		pinned, digest bool
+	}{
+		{"golang:1.25-alpine", true, false},
+		{"ghcr.io/acme/base:2.1", true, false},
+		{"localhost:5000/app", false, false},
+		{"node", false, false},
+		{"node:latest", false, false},
+		{"alpine@sha256:4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1", true, true},
+	}
+
+	for _, tt := range tests {
+		pinned, digest := ImagePinned(tt.ref)
+		if pinned != tt.pinned || digest != tt.digest {
+			t.Errorf("ImagePinned(%q) = %v, %v, want %v, %v", tt.ref, pinned, digest, tt.pinned, tt.digest)
+		}
+	}
+}
+
+func TestAnalyzer_DetectInfraConventions(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"Dockerfile": `FROM golang:1.25 AS build
+RUN go build -o /app .
+
+FROM gcr.io/distroless/static:nonroot
+COPY --from=build /app /app
+USER nonroot:nonroot
+`,
+		"tools/Dockerfile": `FROM python:latest AS base
+USER app
+
+FROM base
+RUN pip install tool
+`,
+		"worker/Dockerfile": `ARG BASE=alpine:3.20
+FROM ${BASE}
+USER root
+`,
+		"docker-compose.yml": "services:\n  db:\n    image: postgres:16\n  cache:\n    image: redis\n",
+		".github/workflows/ci.yml": `jobs:
+  test:
+    steps:
+      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683 # v4
+      - uses: actions/setup-go@v5
+      - uses: ./.github/actions/lint
+      - name: Cache
+        uses: "actions/cache@5a3ec84eff668545956fd18022155c47e93e2684"
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	ctx, err := NewScanner().Scan(tmpDir)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	conv := NewAnalyzer().detectInfraConventions(ctx)
+
+	if len(conv.Dockerfiles) != 3 || len(conv.ComposeFiles) != 1 || len(conv.Workflows) != 1 {
+		t.Errorf("files = %v %v %v", conv.Dockerfiles, conv.ComposeFiles, conv.Workflows)
+	}
+	// golang:1.25, distroless:nonroot, python:latest, postgres:16, redis
+	if conv.BaseImages != 5 || conv.PinnedImages != 3 || conv.DigestImages != 0 {
+		t.Errorf("images = %d, pinned %d, digest %d; want 5, 3, 0", conv.BaseImages, conv.PinnedImages, conv.DigestImages)
+	}
+	// The root Dockerfile sets USER; tools/ inherits it from the base stage
+	if conv.NonRootDockerfiles != 2 {
+		t.Errorf("NonRootDockerfiles = %d, want 2", conv.NonRootDockerfiles)
+	}
+	if conv.Actions != 3 || conv.PinnedActions != 2 {
+		t.Errorf("actions = %d, pinned %d; want 3, 2", conv.Actions, conv.PinnedActions)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // RepoSource represents a repository to scan
@@ -41,10 +42,12 @@ type MultiRepoScanner struct {
 	instructions *InstructionsReader
 	workDir      string
 	githubToken  string
+	cloneTimeout time.Duration
 }
 
-// NewMultiRepoScanner creates a new multi-repo scanner
-func NewMultiRepoScanner(githubToken string) (*MultiRepoScanner, error) {
+// NewMultiRepoScanner creates a new multi-repo scanner.
+// cloneTimeout bounds each external clone; 0 means no limit.
+func NewMultiRepoScanner(githubToken string, cloneTimeout time.Duration) (*MultiRepoScanner, error) {
 	workDir := filepath.Join(os.TempDir(), "prmate-scan")
 	if err := os.MkdirAll(workDir, 0755); err != nil {
 		return nil, fmt.Errorf("create work directory: %w", err)
@@ -56,6 +59,7 @@ func NewMultiRepoScanner(githubToken string) (*MultiRepoScanner, error) {
 		instructions: NewInstructionsReader(),
 		workDir:      workDir,
 		githubToken:  githubToken,
+		cloneTimeout: cloneTimeout,
 	}, nil
 }
 
@@ -154,12 +158,33 @@ func (m *MultiRepoScanner) scanExternalRepo(ctx context.Context, repoAddr string
 	return data
 }
 
+// Clone shallow-clones repoAddr (owner/repo, github.com/owner/repo or an
+// https URL) into the scanner's work directory and returns the local path.
+// The clone is removed by Cleanup.
+func (m *MultiRepoScanner) Clone(ctx context.Context, repoAddr string) (string, error) {
+	repoAddr = normalizeRepoAddress(repoAddr)
+	localPath := filepath.Join(m.workDir, extractRepoName(repoAddr))
+	if err := m.cloneRepo(ctx, repoAddr, localPath); err != nil {
+		return "", err
+	}
+	return localPath, nil
+}
+
 func (m *MultiRepoScanner) cloneRepo(ctx context.Context, repoAddr, localPath string) error {
 	// Remove existing directory if present
 	_ = os.RemoveAll(localPath)
 
-	// Build clone URL with token
-	cloneURL := fmt.Sprintf("https://%s@%s.git", m.githubToken, repoAddr)
+	if m.cloneTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.cloneTimeout)
+		defer cancel()
+	}
+
+	// Build clone URL with token; public repos can be cloned without one
+	cloneURL := fmt.Sprintf("https://%s.git", repoAddr)
+	if m.githubToken != "" {
+		cloneURL = fmt.Sprintf("https://%s@%s.git", m.githubToken, repoAddr)
+	}
 
 	// Use git clone (more reliable than gh for this use case)
 	cmd := exec.CommandContext(ctx, "git", "clone", "--depth=1", cloneURL, localPath)
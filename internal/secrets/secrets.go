@@ -0,0 +1,132 @@
+// Package secrets finds credentials in the lines a change adds: well-known
+// token formats, private key blocks, and high-entropy values assigned to
+// names such as password or api_key. Findings never carry the secret
+// itself, and Redact strips secrets from text before it leaves PRMate, for
+// example in an LLM prompt.
+package secrets
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	ghclient "prmate/internal/github"
+)
+
+// Finding is a probable secret on one added line
+type Finding struct {
+	Line int
+	Kind string // what the secret looks like, e.g. "AWS access key ID"
+}
+
+// detector recognizes one kind of secret; group selects the secret within
+// the match, 0 for the whole match
+type detector struct {
+	kind    string
+	pattern *regexp.Regexp
+	group   int
+	entropy bool // the secret must also look random
+}
+
+var detectors = []detector{
+	{kind: "private key", pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{kind: "AWS access key ID", pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[0-9A-Z]{16}\b`)},
+	{kind: "AWS secret access key", pattern: regexp.MustCompile(`(?i)aws.{0,20}(?:secret|key).{0,20}?['"=:\s]([A-Za-z0-9/+=]{40})\b`), group: 1, entropy: true},
+	{kind: "GitHub token", pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`)},
+	{kind: "GitLab token", pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`)},
+	{kind: "Slack token", pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{kind: "Stripe secret key", pattern: regexp.MustCompile(`\b[sr]k_live_[0-9a-zA-Z]{24,}\b`)},
+	{kind: "Google API key", pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{kind: "OpenAI API key", pattern: regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_-]{32,}\b`), entropy: true},
+	{kind: "hardcoded credential", pattern: regexp.MustCompile(`(?i)(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|auth[_-]?key|client[_-]?secret|private[_-]?key)["']?\s*(?::=|=>|[:=])\s*["']([^"'\s]{12,})["']`), group: 1, entropy: true},
+}
+
+// minEntropy is the Shannon entropy, in bits per character, above which a
+// value is considered random rather than a word or placeholder
+const minEntropy = 3.5
+
+// ScanPatch looks for secrets on the lines patch adds
+func ScanPatch(patch string) []Finding {
+	var findings []Finding
+	for _, hunk := range ghclient.ParsePatch(patch) {
+		for _, line := range hunk.Lines {
+			if line.Type != "add" {
+				continue
+			}
+			if kind := Detect(strings.TrimPrefix(line.Content, "+")); kind != "" {
+				findings = append(findings, Finding{Line: line.NewLineNo, Kind: kind})
+			}
+		}
+	}
+	return findings
+}
+
+// Detect returns the kind of the first secret in line, or ""
+func Detect(line string) string {
+	for _, d := range detectors {
+		for _, m := range d.pattern.FindAllStringSubmatch(line, -1) {
+			if d.accepts(m[d.group]) {
+				return d.kind
+			}
+		}
+	}
+	return ""
+}
+
+// Redact replaces every secret in text with [REDACTED]
+func Redact(text string) string {
+	for _, d := range detectors {
+		text = d.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			sub := d.pattern.FindStringSubmatch(match)
+			if sub == nil || !d.accepts(sub[d.group]) {
+				return match
+			}
+			return strings.Replace(match, sub[d.group], "[REDACTED]", 1)
+		})
+	}
+	return text
+}
+
+func (d detector) accepts(secret string) bool {
+	if !d.entropy {
+		return true
+	}
+	if placeholder(secret) {
+		return false
+	}
+	return Entropy(secret) >= minEntropy
+}
+
+// placeholder reports values that stand in for a secret rather than being
+// one: variable references and obviously fake values
+func placeholder(v string) bool {
+	lower := strings.ToLower(v)
+	if strings.ContainsAny(v, "${}<>") || strings.HasPrefix(lower, "env.") || strings.HasPrefix(lower, "process.env") || strings.HasPrefix(lower, "os.getenv") {
+		return true
+	}
+	for _, fake := range []string{"example", "changeme", "placeholder", "redacted", "xxxx", "your_", "your-", "dummy", "test"} {
+		if strings.Contains(lower, fake) {
+			return true
+		}
+	}
+	return false
+}
+
+// Entropy returns the Shannon entropy of s in bits per character
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test secrets are assembled at run time so this file does not trip
+// secret scanners itself
+var (
+	awsKeyID    = "AKIA" + "IOSFODNN7" + "EXAMPLE"
+	awsSecret   = "wJalrXUtnFEMI/K7MDENG/" + "bPxRfiCYq2Lw8RzT4n"
+	githubToken = "ghp_" + "Ab3dE5gH7jK9mN1pQ3sT5vW7yZ9bC1dF3hJ5"
+	randomValue = "q8Zr2LxV" + "w4Tn7PbK"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{name: "aws key id", line: `key := "` + awsKeyID + `"`, want: "AWS access key ID"},
+		{name: "aws secret", line: `aws_secret_access_key = ` + awsSecret, want: "AWS secret access key"},
+		{name: "github token", line: `GITHUB_TOKEN=` + githubToken, want: "GitHub token"},
+		{name: "private key", line: "-----BEGIN RSA " + "PRIVATE KEY-----", want: "private key"},
+		{name: "openssh key", line: "-----BEGIN OPENSSH " + "PRIVATE KEY-----", want: "private key"},
+		{name: "random password", line: `password: "` + randomValue + `"`, want: "hardcoded credential"},
+		{name: "go assignment", line: `apiKey := "` + randomValue + `"`, want: "hardcoded credential"},
+		{name: "word password", line: `password = "correcthorse"`, want: ""},
+		{name: "placeholder", line: `api_key = "your_api_key_here_1234"`, want: ""},
+		{name: "env reference", line: `token: "${{ secrets.DEPLOY_TOKEN }}"`, want: ""},
+		{name: "plain code", line: `return fmt.Errorf("read token: %w", err)`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.line); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanPatch(t *testing.T) {
+	patch := "@@ -1,2 +1,4 @@\n package main\n+const id = \"" + awsKeyID + "\"\n-const old = \"" + githubToken + "\"\n+const name = \"prmate\"\n+var pw = \"" + randomValue + "\" // password\n"
+	findings := ScanPatch(patch)
+	if len(findings) != 1 || findings[0].Line != 2 || findings[0].Kind != "AWS access key ID" {
+		t.Errorf("ScanPatch() = %+v, want the AWS key on line 2 only", findings)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	text := "id=" + awsKeyID + "\nclient_secret: \"" + randomValue + "\"\npassword = \"correcthorse\"\n"
+	got := Redact(text)
+	for _, secret := range []string{awsKeyID, randomValue} {
+		if strings.Contains(got, secret) {
+			t.Errorf("Redact() kept %q:\n%s", secret, got)
+		}
+	}
+	for _, want := range []string{"id=[REDACTED]", `client_secret: "[REDACTED]"`, `password = "correcthorse"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Redact() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	if got := Entropy("aaaa"); got != 0 {
+		t.Errorf("Entropy(aaaa) = %v, want 0", got)
+	}
+	if got := Entropy("abcd"); got != 2 {
+		t.Errorf("Entropy(abcd) = %v, want 2", got)
+	}
+}
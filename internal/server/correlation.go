@@ -0,0 +1,33 @@
+package server
+
+import (
+	"prmate/internal/correlation"
+	"prmate/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Correlation assigns every request a correlation ID, honouring a valid one
+// sent by the caller and otherwise falling back to the GitHub delivery ID or
+// a new ID
+func Correlation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(correlation.Header)
+		if !correlation.Valid(id) {
+			id = c.GetHeader("X-GitHub-Delivery")
+		}
+		if !correlation.Valid(id) {
+			id = correlation.NewID("")
+		}
+
+		ctx := correlation.WithID(c.Request.Context(), id)
+		ctx = logging.With(ctx, "correlation_id", id)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("prmate.correlation_id", id))
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(correlation.Header, id)
+		c.Next()
+	}
+}
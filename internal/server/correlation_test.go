@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"prmate/internal/correlation"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCorrelation(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		delivery string
+		want     string // "" for a new ID
+	}{
+		{name: "caller's id", header: "abc-123", delivery: "d-1", want: "abc-123"},
+		{name: "delivery id", delivery: "d-1", want: "d-1"},
+		{name: "marker injection falls back to delivery id", header: "x --> <b>forged</b>", delivery: "d-1", want: "d-1"},
+		{name: "too long", header: strings.Repeat("a", 65)},
+		{name: "neither"},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			router := gin.New()
+			router.Use(Correlation())
+			router.GET("/", func(c *gin.Context) {
+				got = correlation.FromContext(c.Request.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(correlation.Header, tt.header)
+			}
+			if tt.delivery != "" {
+				req.Header.Set("X-GitHub-Delivery", tt.delivery)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if tt.want != "" && got != tt.want {
+				t.Errorf("correlation ID = %q, want %q", got, tt.want)
+			}
+			if tt.want == "" && (len(got) != 32 || got == tt.header) {
+				t.Errorf("correlation ID = %q, want a new ID", got)
+			}
+			if echoed := w.Header().Get(correlation.Header); echoed != got {
+				t.Errorf("echoed %s = %q, want %q", correlation.Header, echoed, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"expvar"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var publishRuntimeOnce sync.Once
+
+// RegisterDebugRoutes mounts net/http/pprof and expvar on group, which must
+// be rooted at /debug because pprof.Index resolves profiles by that prefix
+func RegisterDebugRoutes(group *gin.RouterGroup) {
+	publishRuntimeOnce.Do(publishRuntimeStats)
+
+	group.GET("/vars", gin.WrapH(expvar.Handler()))
+
+	group.GET("/pprof/", gin.WrapF(pprof.Index))
+	group.GET("/pprof/cmdline", gin.WrapF(pprof.Cmdline))
+	group.GET("/pprof/profile", gin.WrapF(pprof.Profile))
+	group.POST("/pprof/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/pprof/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/pprof/trace", gin.WrapF(pprof.Trace))
+	for _, profile := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		group.GET("/pprof/"+profile, gin.WrapH(pprof.Handler(profile)))
+	}
+}
+
+// publishRuntimeStats exposes a compact runtime summary under "runtime" in /debug/vars
+func publishRuntimeStats() {
+	started := time.Now()
+	expvar.Publish("runtime", expvar.Func(func() any {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return map[string]any{
+			"uptime_seconds": int64(time.Since(started).Seconds()),
+			"goroutines":     runtime.NumGoroutine(),
+			"heap_alloc":     m.HeapAlloc,
+			"heap_inuse":     m.HeapInuse,
+			"heap_objects":   m.HeapObjects,
+			"sys":            m.Sys,
+			"num_gc":         m.NumGC,
+			"pause_total_ns": m.PauseTotalNs,
+			"gomaxprocs":     runtime.GOMAXPROCS(0),
+			"go_version":     runtime.Version(),
+		}
+	}))
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"prmate/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(1, 2)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("1.2.3.4"); !ok {
+			t.Fatalf("request %d within burst should be allowed", i)
+		}
+	}
+	ok, wait := l.Allow("1.2.3.4")
+	if ok {
+		t.Fatal("request beyond burst should be limited")
+	}
+	if wait <= 0 || wait > time.Second {
+		t.Errorf("wait = %v, want (0, 1s]", wait)
+	}
+	if ok, _ := l.Allow("5.6.7.8"); !ok {
+		t.Error("other clients should have their own bucket")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := l.Allow("1.2.3.4"); !ok {
+		t.Error("bucket should refill over time")
+	}
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	l := NewRateLimiter(0, 0)
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.Allow("x"); !ok {
+			t.Fatal("disabled limiter should allow everything")
+		}
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		chunked    bool
+		wantStatus int
+	}{
+		{name: "under limit", body: "small", wantStatus: http.StatusOK},
+		{name: "declared over limit", body: strings.Repeat("x", 32), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "undeclared over limit", body: strings.Repeat("x", 32), chunked: true, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/", MaxBodySize(16), func(c *gin.Context) {
+				if _, err := c.GetRawData(); err != nil {
+					c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+					return
+				}
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_IgnoresForwardedForFromUntrustedClients(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		proxies    []string
+		wantStatus int
+	}{
+		{name: "no trusted proxies", wantStatus: http.StatusTooManyRequests},
+		{name: "trusted proxy", proxies: []string{"192.0.2.0/24"}, wantStatus: http.StatusOK},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := newEngine(&config.Config{TrustedProxies: strings.Join(tt.proxies, ",")})
+			router.GET("/api/x", NewRateLimiter(1, 1).Middleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			var status int
+			for _, ip := range []string{"203.0.113.1", "203.0.113.2"} {
+				req := httptest.NewRequest(http.MethodGet, "/api/x", nil)
+				req.RemoteAddr = "192.0.2.10:4000"
+				req.Header.Set("X-Forwarded-For", ip)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				status = w.Code
+			}
+			if status != tt.wantStatus {
+				t.Errorf("second client status = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"prmate/internal/audit"
+	"prmate/internal/errreport"
+	"prmate/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Role is the access level granted to an API caller. Higher roles include
+// every permission of the lower ones.
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleReadOnly
+	RoleAdmin
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleReadOnly:
+		return "read-only"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// principalKey is the gin context key holding the authenticated Principal
+const principalKey = "prmate.principal"
+
+// Principal is an authenticated API caller
+type Principal struct {
+	Name string
+	Role Role
+}
+
+// Authenticator resolves the caller of an HTTP request. Implementations
+// return false when the request carries no valid credentials.
+type Authenticator interface {
+	Authenticate(r *http.Request) (Principal, bool)
+	// Enabled reports whether any credentials are configured at all
+	Enabled() bool
+}
+
+type apiKey struct {
+	key       []byte
+	principal Principal
+}
+
+// APIKeyAuthenticator authenticates requests presenting a static API key
+// either as a bearer token or in the X-API-Key header
+type APIKeyAuthenticator struct {
+	keys []apiKey
+}
+
+// NewAPIKeyAuthenticator grants RoleAdmin to adminKey and RoleReadOnly to
+// each of readOnlyKeys; empty keys are ignored
+func NewAPIKeyAuthenticator(adminKey string, readOnlyKeys []string) *APIKeyAuthenticator {
+	a := &APIKeyAuthenticator{}
+	if adminKey != "" {
+		a.keys = append(a.keys, apiKey{key: []byte(adminKey), principal: Principal{Name: "admin-key", Role: RoleAdmin}})
+	}
+	for _, k := range readOnlyKeys {
+		if k != "" {
+			a.keys = append(a.keys, apiKey{key: []byte(k), principal: Principal{Name: "read-only-key", Role: RoleReadOnly}})
+		}
+	}
+	return a
+}
+
+// Enabled reports whether any API key is configured
+func (a *APIKeyAuthenticator) Enabled() bool {
+	return len(a.keys) > 0
+}
+
+// Authenticate matches the presented key against every configured key in
+// constant time
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (Principal, bool) {
+	presented := r.Header.Get("X-API-Key")
+	if presented == "" {
+		presented = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if presented == "" {
+		return Principal{}, false
+	}
+
+	var (
+		match Principal
+		found bool
+	)
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(presented), k.key) == 1 && !found {
+			match, found = k.principal, true
+		}
+	}
+	return match, found
+}
+
+// RequireRole rejects requests whose caller does not hold at least role.
+// When auth has no credentials configured the protected routes are disabled.
+func RequireRole(auth Authenticator, role Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.Enabled() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin api disabled"})
+			return
+		}
+
+		principal, ok := auth.Authenticate(c.Request)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		if principal.Role < role {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient role", "required": role.String()})
+			return
+		}
+
+		c.Set(principalKey, principal)
+		ctx := logging.With(c.Request.Context(), "principal", principal.Name)
+		c.Request = c.Request.WithContext(audit.WithActor(ctx, "api:"+principal.Name))
+		c.Next()
+	}
+}
+
+// ReportPanics sends handler panics to the error reporter, then re-panics so
+// gin.Recovery still turns them into a 500
+func ReportPanics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				ctx := logging.With(c.Request.Context(), "method", c.Request.Method, "route", c.FullPath())
+				errreport.CapturePanic(ctx, r)
+				panic(r)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// PrincipalFrom returns the caller authenticated by RequireRole, if any
+func PrincipalFrom(c *gin.Context) (Principal, bool) {
+	v, ok := c.Get(principalKey)
+	if !ok {
+		return Principal{}, false
+	}
+	p, ok := v.(Principal)
+	return p, ok
+}
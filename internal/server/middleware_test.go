@@ -0,0 +1,100 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter serves the debug routes to the admin role, as the server does
+func newTestRouter(apiKey string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterDebugRoutes(router.Group("/debug", RequireRole(NewAPIKeyAuthenticator(apiKey, nil), RoleAdmin)))
+	return router
+}
+
+func TestRequireRole_AdminKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiKey     string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{name: "disabled when no key configured", apiKey: "", header: "X-API-Key", value: "anything", wantStatus: http.StatusForbidden},
+		{name: "missing key", apiKey: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "wrong key", apiKey: "secret", header: "X-API-Key", value: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "x-api-key header", apiKey: "secret", header: "X-API-Key", value: "secret", wantStatus: http.StatusOK},
+		{name: "bearer token", apiKey: "secret", header: "Authorization", value: "Bearer secret", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(tt.apiKey)
+
+			req := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRegisterDebugRoutes_PprofIndex(t *testing.T) {
+	router := newTestRouter("secret")
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+	req.Header.Set("X-API-Key", "secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	auth := NewAPIKeyAuthenticator("admin-secret", []string{"ro-1", "ro-2"})
+
+	tests := []struct {
+		name       string
+		role       Role
+		key        string
+		wantStatus int
+	}{
+		{name: "admin on admin route", role: RoleAdmin, key: "admin-secret", wantStatus: http.StatusOK},
+		{name: "read-only on admin route", role: RoleAdmin, key: "ro-1", wantStatus: http.StatusForbidden},
+		{name: "read-only on read route", role: RoleReadOnly, key: "ro-2", wantStatus: http.StatusOK},
+		{name: "admin on read route", role: RoleReadOnly, key: "admin-secret", wantStatus: http.StatusOK},
+		{name: "unknown key", role: RoleReadOnly, key: "nope", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/r", RequireRole(auth, tt.role), func(c *gin.Context) {
+				p, _ := PrincipalFrom(c)
+				c.String(http.StatusOK, p.Role.String())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/r", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.key)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -2,8 +2,9 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 
 	"prmate/internal/config"
@@ -13,22 +14,40 @@ import (
 
 // Server wraps the HTTP server and provides lifecycle management
 type Server struct {
-	router *gin.Engine
-	config *config.Config
-	server *http.Server
+	router      *gin.Engine
+	adminRouter *gin.Engine // nil unless admin routes get their own listener
+	config      *config.Config
+	servers     []*http.Server
 }
 
-// NewServer creates a new HTTP server with Gin
+// NewServer creates a new HTTP server with Gin. When cfg.AdminPort is set a
+// second engine is created for admin routes, served on its own listener.
+// The listeners are built here, not in Start, so Shutdown always sees them.
 func NewServer(cfg *config.Config) *Server {
 	gin.SetMode(cfg.GinMode)
 
-	router := gin.New()
-	router.Use(gin.Logger(), gin.Recovery())
-
-	return &Server{
-		router: router,
+	s := &Server{
+		router: newEngine(cfg),
 		config: cfg,
 	}
+	s.servers = []*http.Server{s.newHTTPServer(cfg.Port, s.router)}
+	if cfg.AdminPort != "" {
+		s.adminRouter = newEngine(cfg)
+		s.servers = append(s.servers, s.newHTTPServer(cfg.AdminPort, s.adminRouter))
+	}
+	return s
+}
+
+// newEngine creates an engine that takes the client IP from X-Forwarded-For
+// only on requests from the configured proxies
+func newEngine(cfg *config.Config) *gin.Engine {
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.TrustedProxyList()); err != nil {
+		slog.Error("Invalid trusted proxies, trusting none", "error", err)
+		_ = router.SetTrustedProxies(nil)
+	}
+	router.Use(gin.Logger(), gin.Recovery(), Tracing(), Correlation(), ReportPanics())
+	return router
 }
 
 // Router returns the Gin router for registering handlers
@@ -36,29 +55,86 @@ func (s *Server) Router() *gin.Engine {
 	return s.router
 }
 
-// Start begins listening for HTTP requests
+// AdminRouter returns the router for admin and operator routes: the separate
+// admin engine when configured, otherwise the public router
+func (s *Server) AdminRouter() *gin.Engine {
+	if s.adminRouter != nil {
+		return s.adminRouter
+	}
+	return s.router
+}
+
+// Start begins listening for HTTP requests on the public port and, when
+// configured, the admin port. It returns when either listener fails.
 func (s *Server) Start() error {
-	s.server = &http.Server{
-		Addr:         fmt.Sprintf(":%s", s.config.Port),
-		Handler:      s.router,
+	// Client certificates are only required on the admin listener; GitHub
+	// cannot present one when delivering webhooks. Without a separate admin
+	// listener the admin routes would be served with no client check at all.
+	if s.config.TLSClientCAFile != "" && s.adminRouter == nil {
+		return errors.New("TLS_CLIENT_CA_FILE requires ADMIN_PORT so admin routes are served with client certificates")
+	}
+	publicTLS, err := loadTLSConfig(s.config, false)
+	if err != nil {
+		return err
+	}
+	s.servers[0].TLSConfig = publicTLS
+
+	if s.adminRouter != nil {
+		adminTLS, err := loadTLSConfig(s.config, true)
+		if err != nil {
+			return err
+		}
+		s.servers[1].TLSConfig = adminTLS
+	}
+
+	errCh := make(chan error, len(s.servers))
+	for _, srv := range s.servers {
+		go func(srv *http.Server) {
+			errCh <- listen(srv)
+		}(srv)
+	}
+
+	for range s.servers {
+		if err := <-errCh; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) newHTTPServer(port string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         fmt.Sprintf(":%s", port),
+		Handler:      handler,
 		ReadTimeout:  s.config.ReadTimeout,
 		WriteTimeout: s.config.WriteTimeout,
 		IdleTimeout:  s.config.IdleTimeout,
 	}
+}
 
-	log.Printf("Starting server on port %s", s.config.Port)
-	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		return fmt.Errorf("failed to start server: %w", err)
+func listen(srv *http.Server) error {
+	var err error
+	if srv.TLSConfig != nil {
+		slog.Info("Starting server", "addr", srv.Addr, "tls", true, "client_auth", srv.TLSConfig.ClientAuth.String())
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		slog.Info("Starting server", "addr", srv.Addr, "tls", false)
+		err = srv.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to start server on %s: %w", srv.Addr, err)
 	}
-
 	return nil
 }
 
-// Shutdown gracefully stops the server
+// Shutdown gracefully stops every listener
 func (s *Server) Shutdown(ctx context.Context) error {
-	log.Println("Shutting down server...")
-	if s.server != nil {
-		return s.server.Shutdown(ctx)
+	slog.Info("Shutting down server")
+	var errs []error
+	for _, srv := range s.servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown %s: %w", srv.Addr, err))
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
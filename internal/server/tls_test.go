@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"prmate/internal/config"
+)
+
+// writeSelfSigned writes a self-signed cert/key pair to dir and returns their paths
+func writeSelfSigned(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "prmate-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "tls.crt")
+	keyFile = filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	certFile, keyFile := writeSelfSigned(t, t.TempDir())
+
+	tests := []struct {
+		name           string
+		cfg            config.Config
+		verifyClients  bool
+		wantNil        bool
+		wantErr        bool
+		wantClientAuth tls.ClientAuthType
+	}{
+		{name: "plain http", cfg: config.Config{}, wantNil: true},
+		{name: "cert without key", cfg: config.Config{TLSCertFile: certFile}, wantErr: true},
+		{name: "client ca without cert", cfg: config.Config{TLSClientCAFile: certFile}, wantErr: true},
+		{name: "tls", cfg: config.Config{TLSCertFile: certFile, TLSKeyFile: keyFile}, wantClientAuth: tls.NoClientCert},
+		{
+			name:           "mtls on admin listener",
+			cfg:            config.Config{TLSCertFile: certFile, TLSKeyFile: keyFile, TLSClientCAFile: certFile},
+			verifyClients:  true,
+			wantClientAuth: tls.RequireAndVerifyClientCert,
+		},
+		{
+			name:           "client ca ignored on public listener",
+			cfg:            config.Config{TLSCertFile: certFile, TLSKeyFile: keyFile, TLSClientCAFile: certFile},
+			wantClientAuth: tls.NoClientCert,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadTLSConfig(&tt.cfg, tt.verifyClients)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("loadTLSConfig() = %v, wantNil %v", got, tt.wantNil)
+			}
+			if got != nil && got.ClientAuth != tt.wantClientAuth {
+				t.Errorf("ClientAuth = %v, want %v", got.ClientAuth, tt.wantClientAuth)
+			}
+		})
+	}
+}
+
+func TestServer_AdminRouter(t *testing.T) {
+	shared := NewServer(&config.Config{GinMode: "test"})
+	if shared.AdminRouter() != shared.Router() {
+		t.Error("AdminRouter() should be the public router without ADMIN_PORT")
+	}
+
+	split := NewServer(&config.Config{GinMode: "test", AdminPort: "9090"})
+	if split.AdminRouter() == split.Router() {
+		t.Error("AdminRouter() should be separate with ADMIN_PORT")
+	}
+}
+
+func TestServer_Start_ClientCAWithoutAdminPort(t *testing.T) {
+	certFile, keyFile := writeSelfSigned(t, t.TempDir())
+	srv := NewServer(&config.Config{GinMode: "test", Port: "0", TLSCertFile: certFile, TLSKeyFile: keyFile, TLSClientCAFile: certFile})
+
+	if err := srv.Start(); err == nil {
+		t.Fatal("Start() should refuse a client CA without a separate admin listener")
+	}
+}
+
+func TestServer_ShutdownBeforeStart(t *testing.T) {
+	srv := NewServer(&config.Config{GinMode: "test", Port: "0", AdminPort: "0"})
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	// Listeners shut down before Start must not begin serving
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() after Shutdown error = %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+// Package shadow compares the reviews PRMate ran in shadow mode, which
+// posted nothing, with what human reviewers said on the same pull requests.
+// A finding a human commented near counts as confirmed, a human comment
+// near a finding as caught, and PRMate's verdict is held against the
+// reviewers' approvals and change requests. Teams use the report to judge
+// whether PRMate's comments would help before turning them on.
+package shadow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/store"
+)
+
+const (
+	// lineSlack is how many lines apart a finding and a human comment on
+	// the same file may be and still be about the same code
+	lineSlack = 3
+	// maxPRs bounds the pull requests one report reads from GitHub
+	maxPRs = 100
+)
+
+// Human review verdicts on a pull request
+const (
+	VerdictChangesRequested = "changes_requested"
+	VerdictApproved         = "approved"
+	VerdictCommented        = "commented"
+)
+
+// GitHubClient reads the human reviews of a pull request
+type GitHubClient interface {
+	ListReviewComments(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.ReviewComment, error)
+	ListPRReviews(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.PRReview, error)
+}
+
+// PR compares the latest shadow review of one pull request with its human
+// reviews
+type PR struct {
+	Number        int    `json:"pr"`
+	HeadSHA       string `json:"head_sha"`
+	Findings      int    `json:"findings"`
+	Confirmed     int    `json:"confirmed"` // findings a human commented near
+	HumanComments int    `json:"human_comments"`
+	Caught        int    `json:"caught"` // human comments near a finding
+	Blocking      bool   `json:"would_request_changes"`
+	HumanVerdict  string `json:"human_verdict,omitempty"` // empty when no human reviewed
+	Error         string `json:"error,omitempty"`         // why the human reviews could not be read
+}
+
+// Report compares the shadow reviews of a repository with its human
+// reviews
+type Report struct {
+	Owner         string    `json:"owner"`
+	Repo          string    `json:"repo"`
+	Since         time.Time `json:"since"`
+	PRs           []PR      `json:"prs"`
+	Findings      int       `json:"findings"`
+	Confirmed     int       `json:"confirmed"`
+	HumanComments int       `json:"human_comments"`
+	Caught        int       `json:"caught"`
+	// Verdicts counts the pull requests a human approved or requested
+	// changes on; Agreed those where PRMate would have decided the same
+	Verdicts  int     `json:"verdicts"`
+	Agreed    int     `json:"agreed"`
+	Precision float64 `json:"precision"` // confirmed findings of all findings
+	Recall    float64 `json:"recall"`    // caught human comments of all human comments
+	Agreement float64 `json:"agreement"` // agreed verdicts of all verdicts
+}
+
+// Build compares the latest shadow review of each pull request of
+// owner/repo in records with the pull request's human reviews on GitHub,
+// newest first. Pull requests whose reviews cannot be read are listed with
+// the error and left out of the totals.
+func Build(ctx context.Context, gh GitHubClient, owner, repo string, records []store.ReviewRecord, since time.Time) (Report, error) {
+	r := Report{Owner: owner, Repo: repo, Since: since}
+
+	// Records are newest first, so the first of each pull request is its
+	// latest review
+	seen := make(map[int]bool)
+	for _, rec := range records {
+		if !rec.Shadow || rec.Error != "" || rec.Owner != owner || rec.Repo != repo || seen[rec.PRNumber] {
+			continue
+		}
+		seen[rec.PRNumber] = true
+		if len(r.PRs) == maxPRs {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return Report{}, err
+		}
+
+		pr := PR{Number: rec.PRNumber, HeadSHA: rec.HeadSHA, Findings: len(rec.Findings), Blocking: rec.Blocking}
+		if err := compare(ctx, gh, owner, repo, rec, &pr); err != nil {
+			pr.Error = err.Error()
+			r.PRs = append(r.PRs, pr)
+			continue
+		}
+		r.PRs = append(r.PRs, pr)
+		r.add(pr)
+	}
+
+	r.Precision = ratio(r.Confirmed, r.Findings)
+	r.Recall = ratio(r.Caught, r.HumanComments)
+	r.Agreement = ratio(r.Agreed, r.Verdicts)
+	return r, nil
+}
+
+// compare fills in pr from the human reviews of the pull request rec
+// reviewed
+func compare(ctx context.Context, gh GitHubClient, owner, repo string, rec store.ReviewRecord, pr *PR) error {
+	comments, err := gh.ListReviewComments(ctx, owner, repo, rec.PRNumber)
+	if err != nil {
+		return fmt.Errorf("list review comments: %w", err)
+	}
+	reviews, err := gh.ListPRReviews(ctx, owner, repo, rec.PRNumber)
+	if err != nil {
+		return fmt.Errorf("list reviews: %w", err)
+	}
+
+	// Comments on lines a later push removed have no line to compare
+	var human []ghclient.ReviewComment
+	for _, c := range comments {
+		if !c.Bot && c.Line > 0 {
+			human = append(human, c)
+		}
+	}
+	pr.HumanComments = len(human)
+	for _, f := range rec.Findings {
+		for _, c := range human {
+			if near(f, c) {
+				pr.Confirmed++
+				break
+			}
+		}
+	}
+	for _, c := range human {
+		for _, f := range rec.Findings {
+			if near(f, c) {
+				pr.Caught++
+				break
+			}
+		}
+	}
+	pr.HumanVerdict = verdict(reviews)
+	return nil
+}
+
+// add counts pr into the totals
+func (r *Report) add(pr PR) {
+	r.Findings += pr.Findings
+	r.Confirmed += pr.Confirmed
+	r.HumanComments += pr.HumanComments
+	r.Caught += pr.Caught
+	if pr.HumanVerdict == VerdictApproved || pr.HumanVerdict == VerdictChangesRequested {
+		r.Verdicts++
+		if pr.Blocking == (pr.HumanVerdict == VerdictChangesRequested) {
+			r.Agreed++
+		}
+	}
+}
+
+// near reports whether a human comment is about the code of a finding
+func near(f store.Finding, c ghclient.ReviewComment) bool {
+	d := f.Line - c.Line
+	return f.Path == c.Path && d >= -lineSlack && d <= lineSlack
+}
+
+// verdict sums up the latest review of each human reviewer: changes
+// requested by anyone outweigh approvals, and approvals comments
+func verdict(reviews []ghclient.PRReview) string {
+	sorted := make([]ghclient.PRReview, 0, len(reviews))
+	for _, r := range reviews {
+		if !r.Bot {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].SubmittedAt.Before(sorted[j].SubmittedAt) })
+
+	// A comment after an approval or change request does not withdraw it
+	latest := make(map[string]string)
+	for _, r := range sorted {
+		switch r.State {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			latest[r.Author] = r.State
+		case "COMMENTED":
+			if _, ok := latest[r.Author]; !ok {
+				latest[r.Author] = r.State
+			}
+		}
+	}
+
+	v := ""
+	for _, state := range latest {
+		switch {
+		case state == "CHANGES_REQUESTED":
+			return VerdictChangesRequested
+		case state == "APPROVED":
+			v = VerdictApproved
+		case state == "COMMENTED" && v == "":
+			v = VerdictCommented
+		}
+	}
+	return v
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
@@ -0,0 +1,110 @@
+package shadow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/store"
+)
+
+type fakeGitHub struct {
+	comments map[int][]ghclient.ReviewComment
+	reviews  map[int][]ghclient.PRReview
+	fail     map[int]bool
+}
+
+func (f *fakeGitHub) ListReviewComments(_ context.Context, _, _ string, pr int) ([]ghclient.ReviewComment, error) {
+	if f.fail[pr] {
+		return nil, errors.New("not found")
+	}
+	return f.comments[pr], nil
+}
+
+func (f *fakeGitHub) ListPRReviews(_ context.Context, _, _ string, pr int) ([]ghclient.PRReview, error) {
+	return f.reviews[pr], nil
+}
+
+func TestBuild(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	gh := &fakeGitHub{
+		comments: map[int][]ghclient.ReviewComment{
+			1: {
+				{Path: "a.go", Line: 12, Author: "alice"},             // near the finding on line 10
+				{Path: "a.go", Line: 40, Author: "alice"},             // missed
+				{Path: "a.go", Line: 10, Author: "linter", Bot: true}, // not human
+				{Path: "b.go", Line: 0, Author: "alice"},              // outdated
+			},
+			2: {{Path: "c.go", Line: 5, Author: "bob"}},
+		},
+		reviews: map[int][]ghclient.PRReview{
+			1: {
+				{Author: "alice", State: "CHANGES_REQUESTED", SubmittedAt: base},
+				{Author: "alice", State: "COMMENTED", SubmittedAt: base.Add(time.Hour)},
+			},
+			2: {
+				{Author: "bob", State: "CHANGES_REQUESTED", SubmittedAt: base},
+				{Author: "bob", State: "APPROVED", SubmittedAt: base.Add(time.Hour)},
+				{Author: "ci", State: "CHANGES_REQUESTED", Bot: true, SubmittedAt: base},
+			},
+		},
+		fail: map[int]bool{3: true},
+	}
+	records := []store.ReviewRecord{
+		{Owner: "o", Repo: "r", PRNumber: 1, Shadow: true, Blocking: true, Findings: []store.Finding{
+			{Path: "a.go", Line: 10, Rule: "errors", Severity: "error"},
+			{Path: "a.go", Line: 20, Rule: "naming", Severity: "warning"},
+		}},
+		{Owner: "o", Repo: "r", PRNumber: 1, Shadow: true, Findings: []store.Finding{{Path: "a.go", Line: 40}}}, // older
+		{Owner: "o", Repo: "r", PRNumber: 2, Shadow: true, Blocking: true, Findings: []store.Finding{{Path: "c.go", Line: 30}}},
+		{Owner: "o", Repo: "r", PRNumber: 3, Shadow: true},
+		{Owner: "o", Repo: "r", PRNumber: 4},                                 // posted
+		{Owner: "o", Repo: "r", PRNumber: 5, Shadow: true, Error: "timeout"}, // failed
+	}
+
+	r, err := Build(context.Background(), gh, "o", "r", records, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.PRs) != 3 {
+		t.Fatalf("PRs = %+v, want 1, 2 and 3", r.PRs)
+	}
+	want := PR{Number: 1, Findings: 2, Confirmed: 1, HumanComments: 2, Caught: 1, Blocking: true, HumanVerdict: VerdictChangesRequested}
+	if r.PRs[0] != want {
+		t.Errorf("PR 1 = %+v, want %+v", r.PRs[0], want)
+	}
+	if r.PRs[1].HumanVerdict != VerdictApproved || r.PRs[1].Caught != 0 {
+		t.Errorf("PR 2 = %+v, want approved and nothing caught", r.PRs[1])
+	}
+	if r.PRs[2].Error == "" {
+		t.Errorf("PR 3 = %+v, want the error", r.PRs[2])
+	}
+	if r.Findings != 3 || r.Confirmed != 1 || r.HumanComments != 3 || r.Caught != 1 || r.Verdicts != 2 || r.Agreed != 1 {
+		t.Errorf("totals = %+v", r)
+	}
+	if r.Precision != 1.0/3 || r.Recall != 1.0/3 || r.Agreement != 0.5 {
+		t.Errorf("precision %v, recall %v, agreement %v", r.Precision, r.Recall, r.Agreement)
+	}
+}
+
+func TestVerdict(t *testing.T) {
+	tests := []struct {
+		name    string
+		reviews []ghclient.PRReview
+		want    string
+	}{
+		{name: "no reviews", want: ""},
+		{name: "comments only", reviews: []ghclient.PRReview{{Author: "a", State: "COMMENTED"}}, want: VerdictCommented},
+		{name: "one of two requests changes", reviews: []ghclient.PRReview{{Author: "a", State: "APPROVED"}, {Author: "b", State: "CHANGES_REQUESTED"}}, want: VerdictChangesRequested},
+		{name: "dismissed", reviews: []ghclient.PRReview{{Author: "a", State: "DISMISSED"}}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verdict(tt.reviews); got != tt.want {
+				t.Errorf("verdict() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package smoke
+
+import "os/exec"
+
+// killGroup leaves cmd as is; only the command itself is killed when
+// cancelled
+func killGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package smoke
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killGroup has cmd run in its own process group and, when cancelled, kill
+// the group, so the tools a command started stop with it
+func killGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,193 @@
+// Package smoke runs build and test commands, such as go vet or npm run
+// typecheck, in the workspace a pull request is checked out in, and reads
+// the file positions compilers and linters report from their output. Their
+// failures give reviews a deterministic signal for repositories without CI.
+package smoke
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"prmate/internal/secrets"
+)
+
+const (
+	// maxOutput bounds the output kept of a failed command, from its end
+	maxOutput = 4000
+	// maxProblems bounds the problems read from the output of one command
+	maxProblems = 20
+)
+
+// Failure is a command that failed in the workspace
+type Failure struct {
+	Command  string
+	Output   string    // the end of the combined output, with secrets redacted
+	Problems []Problem // the file positions the output reports
+}
+
+// Problem is one file position a failed command reported
+type Problem struct {
+	Path    string // relative to the workspace
+	Line    int
+	Message string
+}
+
+// problemFormats match the problem lines of common tools: Go's
+// "path:line:col: msg", optionally prefixed by "vet: ", and TypeScript's
+// "path(line,col): msg" and pretty "path:line:col - msg"
+var problemFormats = []*regexp.Regexp{
+	regexp.MustCompile(`^(?:vet: )?([^\s:()]+\.\w+):(\d+)(?::\d+)?: (.+)$`),
+	regexp.MustCompile(`^([^\s:()]+\.\w+)\((\d+),\d+\): (.+)$`),
+	regexp.MustCompile(`^([^\s:()]+\.\w+):(\d+):\d+ - (.+)$`),
+}
+
+// Runner runs the configured commands one after the other
+type Runner struct {
+	commands []string
+	timeout  time.Duration
+}
+
+// NewRunner returns a runner of commands, each given timeout to finish. The
+// commands run through sh, so they may use pipes and &&.
+func NewRunner(commands []string, timeout time.Duration) *Runner {
+	return &Runner{commands: commands, timeout: timeout}
+}
+
+// ParseCommands splits a semicolon-separated list of commands
+func ParseCommands(s string) []string {
+	var out []string
+	for _, c := range strings.Split(s, ";") {
+		if c = strings.TrimSpace(c); c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Run runs every command in dir and returns those that failed. A command
+// that cannot be started or times out fails too. Run stops early when ctx
+// is cancelled.
+func (r *Runner) Run(ctx context.Context, dir string) []Failure {
+	var failures []Failure
+	for _, command := range r.commands {
+		if ctx.Err() != nil {
+			break
+		}
+		if f, failed := r.run(ctx, dir, command); failed {
+			failures = append(failures, f)
+		}
+	}
+	return failures
+}
+
+func (r *Runner) run(ctx context.Context, dir, command string) (Failure, bool) {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = environ()
+	cmd.Stdout, cmd.Stderr = &out, &out
+	killGroup(cmd)
+	// Processes the command leaves behind must not hold the output open
+	cmd.WaitDelay = 5 * time.Second
+	err := cmd.Run()
+	if err == nil {
+		return Failure{}, false
+	}
+
+	output := secrets.Redact(strings.TrimSpace(out.String()))
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		output = strings.TrimSpace(fmt.Sprintf("%s\n\ntimed out after %s", output, r.timeout))
+	} else if output == "" {
+		output = err.Error()
+	}
+	return Failure{
+		Command:  command,
+		Output:   tail(output, maxOutput),
+		Problems: problems(output, dir),
+	}, true
+}
+
+// problems reads the file positions in output. Absolute paths within dir
+// are made relative to it, and positions outside dir are dropped.
+func problems(output, dir string) []Problem {
+	var found []Problem
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		for _, format := range problemFormats {
+			m := format.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			path, ok := relative(m[1], dir)
+			n, _ := strconv.Atoi(m[2])
+			if ok && n > 0 {
+				found = append(found, Problem{Path: path, Line: n, Message: strings.TrimSpace(m[3])})
+			}
+			break
+		}
+		if len(found) == maxProblems {
+			break
+		}
+	}
+	return found
+}
+
+// relative returns path relative to dir, in slash form
+func relative(path, dir string) (string, bool) {
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return "", false
+		}
+		path = rel
+	}
+	path = filepath.ToSlash(filepath.Clean(path))
+	if strings.HasPrefix(path, "../") {
+		return "", false
+	}
+	return path, true
+}
+
+// environ is the environment commands run with: what tools need to find
+// themselves and their caches, but none of PRMate's credentials, since the
+// commands run code from the pull request
+func environ() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		switch {
+		case name == "PATH", name == "HOME", name == "TMPDIR", name == "LANG",
+			strings.HasPrefix(name, "GO") && !strings.HasPrefix(name, "GOOGLE_"),
+			strings.HasPrefix(strings.ToUpper(name), "NPM_CONFIG_"):
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// tail returns the last n bytes of s, starting at a line
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[len(s)-n:]
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[i+1:]
+	}
+	return "…\n" + s
+}
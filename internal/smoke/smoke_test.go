@@ -0,0 +1,78 @@
+package smoke
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCommands(t *testing.T) {
+	got := ParseCommands(" go vet ./... ; ;go build ./...;npm run typecheck ")
+	want := []string{"go vet ./...", "go build ./...", "npm run typecheck"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCommands() = %q, want %q", got, want)
+	}
+}
+
+func TestProblems(t *testing.T) {
+	output := strings.Join([]string{
+		"# prmate/internal/handlers",
+		"./internal/handlers/admin.go:42:9: undefined: ctx",
+		"vet: internal/store/store.go:10:2: unreachable code",
+		"main.go:7: missing return",
+		"src/app.ts(12,5): error TS2322: Type 'string' is not assignable to type 'number'.",
+		"src/util.ts:3:1 - error TS1005: ';' expected.",
+		"/work/pr-1/lib/a.go:5:1: syntax error",
+		"/elsewhere/b.go:5:1: outside the workspace",
+		"FAIL\tprmate/internal/store [build failed]",
+	}, "\n")
+
+	want := []Problem{
+		{Path: "internal/handlers/admin.go", Line: 42, Message: "undefined: ctx"},
+		{Path: "internal/store/store.go", Line: 10, Message: "unreachable code"},
+		{Path: "main.go", Line: 7, Message: "missing return"},
+		{Path: "src/app.ts", Line: 12, Message: "error TS2322: Type 'string' is not assignable to type 'number'."},
+		{Path: "src/util.ts", Line: 3, Message: "error TS1005: ';' expected."},
+		{Path: "lib/a.go", Line: 5, Message: "syntax error"},
+	}
+	if got := problems(output, "/work/pr-1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("problems() = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("OPENAI_API_KEY", "sk-not-for-pull-requests")
+	r := NewRunner([]string{
+		"true",
+		`echo "main.go:3:1: missing return" >&2; exit 1`,
+		`echo "key=${OPENAI_API_KEY:-unset}"; exit 2`,
+		"sleep 5",
+	}, 500*time.Millisecond)
+
+	failures := r.Run(context.Background(), dir)
+	if len(failures) != 3 {
+		t.Fatalf("got %d failures, want 3: %+v", len(failures), failures)
+	}
+	if f := failures[0]; f.Command != `echo "main.go:3:1: missing return" >&2; exit 1` ||
+		!reflect.DeepEqual(f.Problems, []Problem{{Path: "main.go", Line: 3, Message: "missing return"}}) {
+		t.Errorf("failure = %+v, want the problem on stderr", f)
+	}
+	if out := failures[1].Output; out != "key=unset" {
+		t.Errorf("output = %q, want commands to run without PRMate's credentials", out)
+	}
+	if out := failures[2].Output; !strings.Contains(out, "timed out after 500ms") {
+		t.Errorf("output = %q, want the timeout", out)
+	}
+}
+
+func TestTail(t *testing.T) {
+	if got := tail("short", 10); got != "short" {
+		t.Errorf("tail() = %q", got)
+	}
+	if got := tail("first line\nsecond\nthird", 12); got != "…\nthird" {
+		t.Errorf("tail() = %q, want the end from a line start", got)
+	}
+}
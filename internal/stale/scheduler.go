@@ -0,0 +1,155 @@
+package stale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"prmate/internal/config"
+	"prmate/internal/digest"
+	"prmate/internal/store"
+)
+
+const (
+	// runTimeout bounds one round of nudges
+	runTimeout = 10 * time.Minute
+	// lookback is how far back the review history is searched for the
+	// repositories PRMate works on
+	lookback = 90 * 24 * time.Hour
+)
+
+// RecordLister reads the review history the covered repositories come from
+type RecordLister interface {
+	ListReviews(ctx context.Context, f store.Filter) ([]store.ReviewRecord, error)
+}
+
+type instance struct {
+	repo     Repository
+	findings FindingSource
+}
+
+// Scheduler nudges stale pull requests in the repositories PRMate reviewed
+// recently, once a day. A nudge counts as activity, so a pull request that
+// stays quiet is nudged again after another period.
+type Scheduler struct {
+	records   RecordLister
+	policies  []config.StalePolicy
+	hour      int // UTC hour nudges are posted at
+	instances map[string]instance
+}
+
+// NewScheduler returns a scheduler applying policies at hour (UTC)
+func NewScheduler(records RecordLister, policies []config.StalePolicy, hour int) *Scheduler {
+	return &Scheduler{records: records, policies: policies, hour: hour, instances: make(map[string]instance)}
+}
+
+// AddInstance nudges pull requests on the named SCM instance through repo,
+// reading open findings from findings
+func (s *Scheduler) AddInstance(name string, repo Repository, findings FindingSource) {
+	s.instances[name] = instance{repo: repo, findings: findings}
+}
+
+// Start nudges stale pull requests every day until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		for {
+			due := digest.NextRun(time.Now(), "daily", s.hour)
+			timer := time.NewTimer(time.Until(due))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			runCtx, cancel := context.WithTimeout(ctx, runTimeout)
+			if err := s.Run(runCtx, due); err != nil {
+				slog.Warn("failed to nudge stale pull requests", "error", err)
+			}
+			cancel()
+		}
+	}()
+}
+
+// Run nudges every open pull request in a covered repository that has had
+// no activity for its policy's number of days as of now
+func (s *Scheduler) Run(ctx context.Context, now time.Time) error {
+	records, err := s.records.ListReviews(ctx, store.Filter{Since: now.Add(-lookback)})
+	if err != nil {
+		return fmt.Errorf("list reviews: %w", err)
+	}
+
+	type target struct{ instance, owner, repo string }
+	seen := make(map[target]bool)
+	var targets []target
+	for _, r := range records {
+		t := target{r.Instance, r.Owner, r.Repo}
+		if !seen[t] && s.policy(r.Owner, r.Repo) != nil {
+			seen[t] = true
+			targets = append(targets, t)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].owner+"/"+targets[i].repo < targets[j].owner+"/"+targets[j].repo
+	})
+
+	var errs []error
+	for _, t := range targets {
+		inst, ok := s.instances[t.instance]
+		if !ok {
+			slog.Warn("skipping stale pull requests on unknown SCM instance", "instance", t.instance, "repo", t.owner+"/"+t.repo)
+			continue
+		}
+		if err := s.nudgeRepo(ctx, inst, t.owner, t.repo, s.policy(t.owner, t.repo), now); err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", t.owner, t.repo, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Scheduler) nudgeRepo(ctx context.Context, inst instance, owner, repo string, policy *config.StalePolicy, now time.Time) error {
+	prs, err := inst.repo.ListOpenPullRequests(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	idle := time.Duration(policy.Days) * 24 * time.Hour
+	var errs []error
+	for _, pr := range prs {
+		if (pr.Draft && !policy.Drafts) || now.Sub(pr.UpdatedAt) < idle {
+			continue
+		}
+		status, err := Collect(ctx, inst.repo, inst.findings, owner, repo, pr, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("#%d: %w", pr.Number, err))
+			continue
+		}
+		if err := inst.repo.CreatePRComment(ctx, owner, repo, pr.Number, Render(status, policy.Mention)); err != nil {
+			errs = append(errs, fmt.Errorf("#%d: %w", pr.Number, err))
+			continue
+		}
+		slog.Info("nudged stale pull request", "repo", owner+"/"+repo, "pr", pr.Number, "idle_days", status.IdleDays)
+	}
+	return errors.Join(errs...)
+}
+
+// policy returns the first policy covering owner/repo, or nil
+func (s *Scheduler) policy(owner, repo string) *config.StalePolicy {
+	name := strings.ToLower(owner + "/" + repo)
+	for i, p := range s.policies {
+		if len(p.Repos) == 0 {
+			return &s.policies[i]
+		}
+		for _, pattern := range p.Repos {
+			if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+				return &s.policies[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package stale nudges pull requests nobody has touched for a while: it
+// posts a status summary (PRMate findings still open, unresolved review
+// threads, CI state) and mentions the people who can move the PR forward.
+package stale
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/review"
+)
+
+const (
+	// Marker tags nudge comments
+	Marker = "<!-- prmate-stale -->"
+	// maxListed bounds the findings and threads listed in a nudge
+	maxListed = 5
+)
+
+// Repository reads pull request state and posts nudges on one SCM instance
+type Repository interface {
+	ListOpenPullRequests(ctx context.Context, owner, repo string) ([]ghclient.PullRequest, error)
+	ListReviewThreads(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.ReviewThread, error)
+	GetCIState(ctx context.Context, owner, repo, ref string) (string, error)
+	CreatePRComment(ctx context.Context, owner, repo string, prNumber int, body string) error
+}
+
+// FindingSource returns the findings PRMate's latest review left open
+type FindingSource interface {
+	OpenFindings(ctx context.Context, owner, repo string, prNumber int) ([]review.OpenFinding, error)
+}
+
+// Status summarizes where a stale pull request stands
+type Status struct {
+	PR       ghclient.PullRequest
+	IdleDays int
+	CI       string                  // "success", "failure", "pending" or empty when nothing reported
+	Findings []review.OpenFinding    // open PRMate findings
+	Threads  []ghclient.ReviewThread // unresolved threads started by people
+}
+
+// Collect gathers the status of pr as of now
+func Collect(ctx context.Context, repo Repository, findings FindingSource, owner, name string, pr ghclient.PullRequest, now time.Time) (*Status, error) {
+	status := &Status{PR: pr, IdleDays: int(now.Sub(pr.UpdatedAt).Hours() / 24)}
+
+	ci, err := repo.GetCIState(ctx, owner, name, pr.HeadSHA)
+	if err != nil {
+		return nil, err
+	}
+	status.CI = ci
+
+	open, err := findings.OpenFindings(ctx, owner, name, pr.Number)
+	if err != nil {
+		return nil, fmt.Errorf("open findings: %w", err)
+	}
+	status.Findings = open
+
+	threads, err := repo.ListReviewThreads(ctx, owner, name, pr.Number)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range threads {
+		if !t.Resolved && !t.Bot {
+			status.Threads = append(status.Threads, t)
+		}
+	}
+	return status, nil
+}
+
+// Render builds the nudge comment; mention picks who is pinged: "all",
+// "author", "reviewers" or "none"
+func Render(s *Status, mention string) string {
+	var sb strings.Builder
+	sb.WriteString(Marker + "\n")
+	fmt.Fprintf(&sb, "## 💤 No activity for %d days\n\n", s.IdleDays)
+
+	if logins := mentions(s.PR, mention); len(logins) > 0 {
+		fmt.Fprintf(&sb, "%s: here is where this pull request stands.\n\n", strings.Join(logins, " "))
+	} else {
+		sb.WriteString("Here is where this pull request stands.\n\n")
+	}
+
+	fmt.Fprintf(&sb, "- **CI:** %s\n", ciText(s.CI))
+	fmt.Fprintf(&sb, "- **PRMate findings:** %d open\n", len(s.Findings))
+	for i, f := range s.Findings {
+		if i == maxListed {
+			fmt.Fprintf(&sb, "  - _and %d more_\n", len(s.Findings)-maxListed)
+			break
+		}
+		fmt.Fprintf(&sb, "  - `%s:%d` [%s] %s\n", f.Path, f.Line, f.Severity, f.Rule)
+	}
+	fmt.Fprintf(&sb, "- **Unresolved review threads:** %d\n", len(s.Threads))
+	for i, t := range s.Threads {
+		if i == maxListed {
+			fmt.Fprintf(&sb, "  - _and %d more_\n", len(s.Threads)-maxListed)
+			break
+		}
+		fmt.Fprintf(&sb, "  - `%s:%d` started by %s\n", t.Path, t.Line, t.Author)
+	}
+
+	sb.WriteString("\n" + nextStep(s) + "\n")
+	return sb.String()
+}
+
+func ciText(state string) string {
+	switch state {
+	case "success":
+		return "✅ passing"
+	case "failure":
+		return "❌ failing"
+	case "pending":
+		return "⏳ running"
+	}
+	return "no checks reported"
+}
+
+// nextStep suggests what would unblock the pull request
+func nextStep(s *Status) string {
+	switch {
+	case s.CI == "failure":
+		return "Fixing the failing checks is the first step to getting this merged."
+	case len(s.Findings) > 0 || len(s.Threads) > 0:
+		return "Addressing the open findings and threads above should unblock the review."
+	case len(s.PR.Reviewers) > 0:
+		return "Nothing is outstanding on the author's side; this is waiting for a review."
+	}
+	return "Nothing is outstanding; consider requesting a review, merging or closing this pull request."
+}
+
+// mentions returns the @logins to ping, without duplicates
+func mentions(pr ghclient.PullRequest, mention string) []string {
+	var logins []string
+	if (mention == "all" || mention == "author") && pr.Author != "" {
+		logins = append(logins, "@"+pr.Author)
+	}
+	if mention == "all" || mention == "reviewers" {
+		for _, r := range pr.Reviewers {
+			if r != "" && r != pr.Author {
+				logins = append(logins, "@"+r)
+			}
+		}
+	}
+	return logins
+}
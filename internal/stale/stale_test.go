@@ -0,0 +1,131 @@
+package stale
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"prmate/internal/config"
+	ghclient "prmate/internal/github"
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+var now = time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+
+type fakeLister struct{ records []store.ReviewRecord }
+
+func (f *fakeLister) ListReviews(ctx context.Context, flt store.Filter) ([]store.ReviewRecord, error) {
+	return f.records, nil
+}
+
+type fakeRepository struct {
+	prs      []ghclient.PullRequest
+	threads  []ghclient.ReviewThread
+	ci       string
+	comments map[string]string
+}
+
+func (f *fakeRepository) ListOpenPullRequests(ctx context.Context, owner, repo string) ([]ghclient.PullRequest, error) {
+	return f.prs, nil
+}
+
+func (f *fakeRepository) ListReviewThreads(ctx context.Context, owner, repo string, prNumber int) ([]ghclient.ReviewThread, error) {
+	return f.threads, nil
+}
+
+func (f *fakeRepository) GetCIState(ctx context.Context, owner, repo, ref string) (string, error) {
+	return f.ci, nil
+}
+
+func (f *fakeRepository) CreatePRComment(ctx context.Context, owner, repo string, prNumber int, body string) error {
+	if f.comments == nil {
+		f.comments = make(map[string]string)
+	}
+	f.comments[fmt.Sprintf("%s/%s#%d", owner, repo, prNumber)] = body
+	return nil
+}
+
+type fakeFindings []review.OpenFinding
+
+func (f fakeFindings) OpenFindings(ctx context.Context, owner, repo string, prNumber int) ([]review.OpenFinding, error) {
+	return f, nil
+}
+
+func TestRender(t *testing.T) {
+	status := &Status{
+		PR:       ghclient.PullRequest{Number: 4, Author: "alice", Reviewers: []string{"bob", "alice"}},
+		IdleDays: 9,
+		CI:       "failure",
+		Findings: []review.OpenFinding{{Path: "main.go", Line: 12, Severity: "error", Rule: "Wrap errors"}},
+		Threads:  []ghclient.ReviewThread{{Path: "api.go", Line: 3, Author: "carol"}},
+	}
+
+	body := Render(status, "all")
+	for _, want := range []string{Marker, "No activity for 9 days", "@alice @bob:", "❌ failing", "1 open", "`main.go:12` [error] Wrap errors", "`api.go:3` started by carol", "failing checks"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Render() missing %q in:\n%s", want, body)
+		}
+	}
+	if strings.Count(body, "@alice") != 1 {
+		t.Errorf("author mentioned more than once:\n%s", body)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	pr := ghclient.PullRequest{Author: "alice", Reviewers: []string{"bob"}}
+	tests := []struct {
+		mention string
+		want    string
+	}{
+		{mention: "all", want: "@alice @bob"},
+		{mention: "author", want: "@alice"},
+		{mention: "reviewers", want: "@bob"},
+		{mention: "none", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mention, func(t *testing.T) {
+			if got := strings.Join(mentions(pr, tt.mention), " "); got != tt.want {
+				t.Errorf("mentions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	repo := &fakeRepository{
+		prs: []ghclient.PullRequest{
+			{Number: 1, Author: "alice", UpdatedAt: now.AddDate(0, 0, -10)},
+			{Number: 2, Author: "alice", UpdatedAt: now.AddDate(0, 0, -2)},
+			{Number: 3, Author: "alice", UpdatedAt: now.AddDate(0, 0, -30), Draft: true},
+		},
+		threads: []ghclient.ReviewThread{
+			{Path: "a.go", Line: 1, Author: "bob"},
+			{Path: "b.go", Line: 2, Author: "bob", Resolved: true},
+			{Path: "c.go", Line: 3, Author: "prmate", Bot: true},
+		},
+		ci: "success",
+	}
+	records := &fakeLister{records: []store.ReviewRecord{
+		{Instance: "github", Owner: "acme", Repo: "api"},
+		{Instance: "github", Owner: "other", Repo: "lib"},
+	}}
+	s := NewScheduler(records, []config.StalePolicy{{Repos: []string{"acme/*"}, Days: 7, Mention: "author"}}, 8)
+	s.AddInstance("github", repo, fakeFindings{{Path: "main.go", Line: 1, Severity: "error", Rule: "r"}})
+
+	if err := s.Run(context.Background(), now); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	body, ok := repo.comments["acme/api#1"]
+	if !ok || len(repo.comments) != 1 {
+		t.Fatalf("nudged %v, want only acme/api#1", repo.comments)
+	}
+	for _, want := range []string{"No activity for 10 days", "@alice:", "✅ passing", "**Unresolved review threads:** 1"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("nudge missing %q in:\n%s", want, body)
+		}
+	}
+}
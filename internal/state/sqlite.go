@@ -0,0 +1,239 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS pull_requests (
+	instance    TEXT    NOT NULL,
+	owner       TEXT    NOT NULL,
+	repo        TEXT    NOT NULL,
+	pr          INTEGER NOT NULL,
+	head_sha    TEXT    NOT NULL,
+	reviewed_at TEXT    NOT NULL,
+	summary     TEXT,
+	PRIMARY KEY (instance, owner, repo, pr)
+);
+CREATE TABLE IF NOT EXISTS files (
+	instance    TEXT    NOT NULL,
+	owner       TEXT    NOT NULL,
+	repo        TEXT    NOT NULL,
+	pr          INTEGER NOT NULL,
+	path        TEXT    NOT NULL,
+	sha         TEXT    NOT NULL,
+	violations  INTEGER NOT NULL,
+	reviewed_at TEXT    NOT NULL,
+	PRIMARY KEY (instance, owner, repo, pr, path)
+);
+CREATE TABLE IF NOT EXISTS checkpoints (
+	instance    TEXT    NOT NULL,
+	owner       TEXT    NOT NULL,
+	repo        TEXT    NOT NULL,
+	pr          INTEGER NOT NULL,
+	head_sha    TEXT    NOT NULL,
+	path        TEXT    NOT NULL,
+	result      TEXT    NOT NULL,
+	PRIMARY KEY (instance, owner, repo, pr, path)
+);`
+
+// SQLite is a Store backed by a SQLite database file
+type SQLite struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at path, creating it, its directory and
+// its tables as needed
+func OpenSQLite(path string) (*SQLite, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create state dir: %w", err)
+		}
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("open state db: %w", err)
+	}
+	// One connection serializes writers, which SQLite would do anyway
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create state tables: %w", err)
+	}
+	return &SQLite{db: db}, nil
+}
+
+// Close closes the database
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// Load returns the state saved for key, or nil if there is none
+func (s *SQLite) Load(ctx context.Context, key Key) (*PR, error) {
+	var (
+		pr         PR
+		reviewedAt string
+		summary    sql.NullString
+	)
+	err := s.db.QueryRowContext(ctx,
+		`SELECT head_sha, reviewed_at, summary FROM pull_requests WHERE instance = ? AND owner = ? AND repo = ? AND pr = ?`,
+		key.Instance, key.Owner, key.Repo, key.PRNumber,
+	).Scan(&pr.HeadSHA, &reviewedAt, &summary)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load pull request state: %w", err)
+	}
+	if pr.ReviewedAt, err = parseTime(reviewedAt); err != nil {
+		return nil, err
+	}
+	if summary.Valid {
+		pr.Summary = []byte(summary.String)
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT path, sha, violations, reviewed_at FROM files WHERE instance = ? AND owner = ? AND repo = ? AND pr = ? ORDER BY path`,
+		key.Instance, key.Owner, key.Repo, key.PRNumber,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("load file state: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var f File
+		if err := rows.Scan(&f.Path, &f.SHA, &f.Violations, &reviewedAt); err != nil {
+			return nil, fmt.Errorf("scan file state: %w", err)
+		}
+		if f.ReviewedAt, err = parseTime(reviewedAt); err != nil {
+			return nil, err
+		}
+		pr.Files = append(pr.Files, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load file state: %w", err)
+	}
+	return &pr, nil
+}
+
+// Save replaces the state saved for key and drops its checkpoints
+func (s *SQLite) Save(ctx context.Context, key Key, pr PR) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	var summary any
+	if len(pr.Summary) > 0 {
+		summary = string(pr.Summary)
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO pull_requests (instance, owner, repo, pr, head_sha, reviewed_at, summary) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (instance, owner, repo, pr) DO UPDATE SET head_sha = excluded.head_sha, reviewed_at = excluded.reviewed_at, summary = excluded.summary`,
+		key.Instance, key.Owner, key.Repo, key.PRNumber, pr.HeadSHA, formatTime(pr.ReviewedAt), summary,
+	)
+	if err != nil {
+		return fmt.Errorf("save pull request state: %w", err)
+	}
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM files WHERE instance = ? AND owner = ? AND repo = ? AND pr = ?`,
+		key.Instance, key.Owner, key.Repo, key.PRNumber,
+	)
+	if err != nil {
+		return fmt.Errorf("clear file state: %w", err)
+	}
+	for _, f := range pr.Files {
+		_, err = tx.ExecContext(ctx,
+			`INSERT OR REPLACE INTO files (instance, owner, repo, pr, path, sha, violations, reviewed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			key.Instance, key.Owner, key.Repo, key.PRNumber, f.Path, f.SHA, f.Violations, formatTime(f.ReviewedAt),
+		)
+		if err != nil {
+			return fmt.Errorf("save file state %s: %w", f.Path, err)
+		}
+	}
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM checkpoints WHERE instance = ? AND owner = ? AND repo = ? AND pr = ?`,
+		key.Instance, key.Owner, key.Repo, key.PRNumber,
+	)
+	if err != nil {
+		return fmt.Errorf("clear checkpoints: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// Checkpoint records result as the outcome of reviewing path at headSHA,
+// dropping the checkpoints of other commits
+func (s *SQLite) Checkpoint(ctx context.Context, key Key, headSHA, path string, result json.RawMessage) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM checkpoints WHERE instance = ? AND owner = ? AND repo = ? AND pr = ? AND head_sha != ?`,
+		key.Instance, key.Owner, key.Repo, key.PRNumber, headSHA,
+	)
+	if err != nil {
+		return fmt.Errorf("clear stale checkpoints: %w", err)
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT OR REPLACE INTO checkpoints (instance, owner, repo, pr, head_sha, path, result) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		key.Instance, key.Owner, key.Repo, key.PRNumber, headSHA, path, string(result),
+	)
+	if err != nil {
+		return fmt.Errorf("save checkpoint %s: %w", path, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// Checkpoints returns the outcomes recorded for headSHA by path
+func (s *SQLite) Checkpoints(ctx context.Context, key Key, headSHA string) (map[string]json.RawMessage, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT path, result FROM checkpoints WHERE instance = ? AND owner = ? AND repo = ? AND pr = ? AND head_sha = ?`,
+		key.Instance, key.Owner, key.Repo, key.PRNumber, headSHA,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoints: %w", err)
+	}
+	defer rows.Close()
+	results := make(map[string]json.RawMessage)
+	for rows.Next() {
+		var path, result string
+		if err := rows.Scan(&path, &result); err != nil {
+			return nil, fmt.Errorf("scan checkpoint: %w", err)
+		}
+		results[path] = json.RawMessage(result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load checkpoints: %w", err)
+	}
+	return results, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse time %q: %w", s, err)
+	}
+	return t, nil
+}
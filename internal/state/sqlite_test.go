@@ -0,0 +1,148 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLite_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.db")
+	ctx := context.Background()
+
+	s, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite() error = %v", err)
+	}
+	key := Key{Owner: "o", Repo: "r", PRNumber: 7}
+	base := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	if got, err := s.Load(ctx, key); err != nil || got != nil {
+		t.Fatalf("Load() before Save = %v, %v; want nil, nil", got, err)
+	}
+
+	first := PR{
+		HeadSHA:    "aaa",
+		ReviewedAt: base,
+		Files: []File{
+			{Path: "a.go", SHA: "aaa", Violations: 2, ReviewedAt: base},
+			{Path: "b.go", SHA: "aaa", ReviewedAt: base},
+		},
+		Summary: []byte(`{"rules_applied":3}`),
+	}
+	if err := s.Save(ctx, key, first); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	second := PR{
+		HeadSHA:    "bbb",
+		ReviewedAt: base.Add(time.Hour),
+		Files:      []File{{Path: "a.go", SHA: "bbb", ReviewedAt: base.Add(time.Hour)}},
+	}
+	if err := s.Save(ctx, key, second); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := s.Save(ctx, Key{Instance: "acme", Owner: "o", Repo: "r", PRNumber: 7}, first); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	s.Close()
+
+	reopened, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	defer reopened.Close()
+
+	tests := []struct {
+		name string
+		key  Key
+		want PR
+	}{
+		{name: "latest save replaces", key: key, want: second},
+		{name: "instances stay apart", key: Key{Instance: "acme", Owner: "o", Repo: "r", PRNumber: 7}, want: first},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reopened.Load(ctx, tt.key)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got == nil {
+				t.Fatal("Load() = nil")
+			}
+			if got.HeadSHA != tt.want.HeadSHA || !got.ReviewedAt.Equal(tt.want.ReviewedAt) {
+				t.Errorf("Load() = %s at %v, want %s at %v", got.HeadSHA, got.ReviewedAt, tt.want.HeadSHA, tt.want.ReviewedAt)
+			}
+			if string(got.Summary) != string(tt.want.Summary) {
+				t.Errorf("Summary = %q, want %q", got.Summary, tt.want.Summary)
+			}
+			if len(got.Files) != len(tt.want.Files) {
+				t.Fatalf("Files = %+v, want %+v", got.Files, tt.want.Files)
+			}
+			for i, f := range tt.want.Files {
+				g := got.Files[i]
+				if g.Path != f.Path || g.SHA != f.SHA || g.Violations != f.Violations || !g.ReviewedAt.Equal(f.ReviewedAt) {
+					t.Errorf("Files[%d] = %+v, want %+v", i, g, f)
+				}
+			}
+		})
+	}
+}
+
+func TestWithInstance(t *testing.T) {
+	ctx := context.Background()
+	s, err := OpenSQLite(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite() error = %v", err)
+	}
+	defer s.Close()
+
+	key := Key{Owner: "o", Repo: "r", PRNumber: 1}
+	if err := WithInstance(s, "acme").Save(ctx, key, PR{HeadSHA: "aaa"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got, _ := s.Load(ctx, Key{Instance: "acme", Owner: "o", Repo: "r", PRNumber: 1}); got == nil || got.HeadSHA != "aaa" {
+		t.Errorf("Load(acme) = %+v, want head aaa", got)
+	}
+	if got, _ := WithInstance(s, "other").Load(ctx, key); got != nil {
+		t.Errorf("Load(other) = %+v, want nil", got)
+	}
+}
+
+func TestSQLite_Checkpoints(t *testing.T) {
+	ctx := context.Background()
+	s, err := OpenSQLite(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite() error = %v", err)
+	}
+	defer s.Close()
+
+	key := Key{Owner: "o", Repo: "r", PRNumber: 1}
+	for _, c := range []struct{ head, path, result string }{
+		{"aaa", "a.go", `{"n":1}`},
+		{"bbb", "a.go", `{"n":2}`},
+		{"bbb", "b.go", `{"n":3}`},
+	} {
+		if err := s.Checkpoint(ctx, key, c.head, c.path, json.RawMessage(c.result)); err != nil {
+			t.Fatalf("Checkpoint() error = %v", err)
+		}
+	}
+	if got, _ := s.Checkpoints(ctx, key, "aaa"); len(got) != 0 {
+		t.Errorf("Checkpoints(aaa) = %s, want those of an older commit dropped", got)
+	}
+	got, err := s.Checkpoints(ctx, key, "bbb")
+	if err != nil || len(got) != 2 || string(got["a.go"]) != `{"n":2}` || string(got["b.go"]) != `{"n":3}` {
+		t.Errorf("Checkpoints(bbb) = %s, %v; want a.go and b.go", got, err)
+	}
+	if got, _ := WithInstance(s, "acme").Checkpoints(ctx, key, "bbb"); len(got) != 0 {
+		t.Errorf("Checkpoints(acme) = %s, want instances kept apart", got)
+	}
+
+	if err := s.Save(ctx, key, PR{HeadSHA: "bbb"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got, _ := s.Checkpoints(ctx, key, "bbb"); len(got) != 0 {
+		t.Errorf("Checkpoints() after Save = %s, want none", got)
+	}
+}
@@ -0,0 +1,82 @@
+// Package state keeps what incremental reviews need to know about the
+// previous review of a pull request: the commit each file was reviewed at,
+// how many findings it had and when. Stores keep it outside the pull
+// request, so it survives the summary comment being edited or deleted.
+// They also keep checkpoints of the review under way, so a review cut short
+// resumes after the files it finished.
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Key identifies a pull request. Instance names the SCM instance, so pull
+// requests of repositories with the same name on two hosts stay apart.
+type Key struct {
+	Instance string
+	Owner    string
+	Repo     string
+	PRNumber int
+}
+
+// File is the review state of one file of a pull request
+type File struct {
+	Path       string
+	SHA        string // head commit the file was last reviewed at
+	Violations int
+	ReviewedAt time.Time
+}
+
+// PR is the state of the latest review of a pull request
+type PR struct {
+	HeadSHA    string
+	ReviewedAt time.Time
+	Files      []File
+	Summary    json.RawMessage // the rest of the review summary, opaque to the store
+}
+
+// Store loads and saves the state of pull requests
+type Store interface {
+	// Load returns the state saved for key, or nil if there is none
+	Load(ctx context.Context, key Key) (*PR, error)
+	// Save replaces the state saved for key and drops its checkpoints
+	Save(ctx context.Context, key Key, pr PR) error
+	// Checkpoint records result as the outcome of reviewing path at
+	// headSHA, dropping the checkpoints of other commits
+	Checkpoint(ctx context.Context, key Key, headSHA, path string, result json.RawMessage) error
+	// Checkpoints returns the outcomes recorded for headSHA by path
+	Checkpoints(ctx context.Context, key Key, headSHA string) (map[string]json.RawMessage, error)
+}
+
+// WithInstance returns a view of s whose keys all name instance, for the
+// services of one SCM instance
+func WithInstance(s Store, instance string) Store {
+	return instanceStore{store: s, instance: instance}
+}
+
+type instanceStore struct {
+	store    Store
+	instance string
+}
+
+func (s instanceStore) Load(ctx context.Context, key Key) (*PR, error) {
+	key.Instance = s.instance
+	return s.store.Load(ctx, key)
+}
+
+func (s instanceStore) Save(ctx context.Context, key Key, pr PR) error {
+	key.Instance = s.instance
+	return s.store.Save(ctx, key, pr)
+}
+
+func (s instanceStore) Checkpoint(ctx context.Context, key Key, headSHA, path string, result json.RawMessage) error {
+	key.Instance = s.instance
+	return s.store.Checkpoint(ctx, key, headSHA, path, result)
+}
+
+func (s instanceStore) Checkpoints(ctx context.Context, key Key, headSHA string) (map[string]json.RawMessage, error) {
+	key.Instance = s.instance
+	return s.store.Checkpoints(ctx, key, headSHA)
+}
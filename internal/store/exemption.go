@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Exemption lets findings of one rule go unreported until it expires,
+// either in the pull request it was granted on or, when it names a path,
+// in the files matching that path in every pull request of the repository
+type Exemption struct {
+	ID        string    `json:"id"`
+	Instance  string    `json:"instance,omitempty"`
+	Owner     string    `json:"owner"`
+	Repo      string    `json:"repo"`
+	PRNumber  int       `json:"pr"`             // pull request the exemption was granted on
+	Path      string    `json:"path,omitempty"` // glob of the exempted files; empty exempts the whole pull request
+	Rule      string    `json:"rule"`
+	Reason    string    `json:"reason"`
+	GrantedBy string    `json:"granted_by"`
+	GrantedAt time.Time `json:"granted_at"`
+	Until     time.Time `json:"until"`
+	Reminded  bool      `json:"reminded,omitempty"` // the expiry reminder was posted
+}
+
+// Active reports whether the exemption still holds at now
+func (e Exemption) Active(now time.Time) bool {
+	return now.Before(e.Until)
+}
+
+// Covers reports whether the exemption applies to pull request prNumber of
+// its repository
+func (e Exemption) Covers(prNumber int) bool {
+	return e.Path != "" || e.PRNumber == prNumber
+}
+
+// exemptionsPath is the JSON Lines file exemptions are kept in, next to
+// the review records at path
+func exemptionsPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return strings.TrimSuffix(path, ".jsonl") + ".exemptions.jsonl"
+}
+
+// loadExemptions reads the exemptions saved at path. A later line for the
+// same ID replaces the earlier one.
+func (s *FileStore) loadExemptions(path string) error {
+	return readJSONLines(path, "exemptions", s.putExemption)
+}
+
+// putExemption adds e in memory, replacing the exemption with its ID
+func (s *FileStore) putExemption(e Exemption) {
+	for i := range s.exemptions {
+		if s.exemptions[i].ID == e.ID {
+			s.exemptions[i] = e
+			return
+		}
+	}
+	s.exemptions = append(s.exemptions, e)
+}
+
+// SaveExemption adds e to the store, or updates the exemption with its ID
+func (s *FileStore) SaveExemption(ctx context.Context, e Exemption) error {
+	_ = ctx
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if path := exemptionsPath(s.path); path != "" {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("encode exemption: %w", err)
+		}
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("open exemptions: %w", err)
+		}
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			f.Close()
+			return fmt.Errorf("write exemption: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("close exemptions: %w", err)
+		}
+	}
+
+	s.putExemption(e)
+	return nil
+}
+
+// ListExemptions returns the exemptions granted on an SCM instance, expired
+// or not, oldest first
+func (s *FileStore) ListExemptions(ctx context.Context, instance string) ([]Exemption, error) {
+	_ = ctx
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]Exemption, 0)
+	for _, e := range s.exemptions {
+		if e.Instance == instance {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
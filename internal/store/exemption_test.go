@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStore_Exemptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviews.jsonl")
+	ctx := context.Background()
+	until := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore() error = %v", err)
+	}
+	for _, e := range []Exemption{
+		{ID: "1", Owner: "o", Repo: "a", PRNumber: 7, Rule: "no-println", Until: until},
+		{ID: "2", Instance: "acme", Owner: "o", Repo: "b", PRNumber: 8, Path: "legacy/**", Rule: "small-files", Until: until},
+		{ID: "1", Owner: "o", Repo: "a", PRNumber: 7, Rule: "no-println", Until: until, Reminded: true},
+	} {
+		if err := s.SaveExemption(ctx, e); err != nil {
+			t.Fatalf("SaveExemption() error = %v", err)
+		}
+	}
+
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	got, err := reopened.ListExemptions(ctx, "")
+	if err != nil {
+		t.Fatalf("ListExemptions() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "1" || !got[0].Reminded {
+		t.Errorf("ListExemptions() = %+v, want exemption 1 as last saved", got)
+	}
+	if got, _ := reopened.ListExemptions(ctx, "acme"); len(got) != 1 || got[0].ID != "2" {
+		t.Errorf("ListExemptions(acme) = %+v, want exemption 2", got)
+	}
+}
+
+func TestExemption_Scope(t *testing.T) {
+	until := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	pr := Exemption{PRNumber: 7, Until: until}
+	path := Exemption{PRNumber: 7, Path: "legacy/**", Until: until}
+
+	if !pr.Covers(7) || pr.Covers(8) {
+		t.Error("an exemption without path should cover its pull request only")
+	}
+	if !path.Covers(8) {
+		t.Error("an exemption with a path should cover every pull request")
+	}
+	if !pr.Active(until.Add(-time.Second)) || pr.Active(until) {
+		t.Error("an exemption should expire at Until")
+	}
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// readJSONLines decodes each line of the JSON Lines file at path into a new
+// T and passes it to add; a missing file has none. Lines that cannot be
+// parsed are skipped with a warning, and a partly written last line, as left
+// by a crash, is cut off so the next append starts on a line of its own.
+func readJSONLines[T any](path, what string, add func(T)) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open %s: %w", what, err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var complete int64 // bytes up to the end of the last complete line
+	for line := 1; ; line++ {
+		b, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("read %s: %w", what, err)
+		}
+		if len(b) == 0 {
+			return nil
+		}
+		var v T
+		jsonErr := json.Unmarshal(b, &v)
+		switch {
+		case err == io.EOF && jsonErr != nil:
+			slog.Warn("dropping partly written last line", "file", what, "path", path, "line", line, "error", jsonErr)
+			if err := os.Truncate(path, complete); err != nil {
+				return fmt.Errorf("truncate %s: %w", what, err)
+			}
+		case err == io.EOF:
+			// Complete the line so the next append does not run into it
+			add(v)
+			if err := appendLine(path, nil); err != nil {
+				return fmt.Errorf("complete %s: %w", what, err)
+			}
+		case jsonErr != nil:
+			slog.Warn("skipping unreadable line", "file", what, "path", path, "line", line, "error", jsonErr)
+		default:
+			add(v)
+		}
+		complete += int64(len(b))
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// appendLine appends data and a newline to the file at path
+func appendLine(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Preference is how a developer wants to hear about the findings on the
+// pull requests they author
+type Preference struct {
+	Instance    string    `json:"instance,omitempty"`
+	User        string    `json:"user"`
+	SummaryOnly bool      `json:"summary_only,omitempty"` // no inline comments, only the summary
+	Muted       []string  `json:"muted,omitempty"`        // rules not commented on
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// preferencesPath is the JSON Lines file preferences are kept in, next to
+// the review records at path
+func preferencesPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return strings.TrimSuffix(path, ".jsonl") + ".preferences.jsonl"
+}
+
+// preferenceKey identifies the preference of a user on an SCM instance;
+// GitHub logins are case-insensitive
+func preferenceKey(instance, user string) string {
+	return instance + "/" + strings.ToLower(user)
+}
+
+// loadPreferences reads the preferences saved at path. A later line for
+// the same user replaces the earlier one.
+func (s *FileStore) loadPreferences(path string) error {
+	return readJSONLines(path, "preferences", func(p Preference) {
+		s.preferences[preferenceKey(p.Instance, p.User)] = p
+	})
+}
+
+// SavePreference replaces the preference of p.User on p.Instance
+func (s *FileStore) SavePreference(ctx context.Context, p Preference) error {
+	_ = ctx
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if path := preferencesPath(s.path); path != "" {
+		line, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("encode preference: %w", err)
+		}
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("open preferences: %w", err)
+		}
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			f.Close()
+			return fmt.Errorf("write preference: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("close preferences: %w", err)
+		}
+	}
+
+	s.preferences[preferenceKey(p.Instance, p.User)] = p
+	return nil
+}
+
+// GetPreference returns the preference of user on an SCM instance, which
+// is the zero Preference for that user when they never set one
+func (s *FileStore) GetPreference(ctx context.Context, instance, user string) (Preference, error) {
+	_ = ctx
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if p, ok := s.preferences[preferenceKey(instance, user)]; ok {
+		p.Muted = append([]string(nil), p.Muted...)
+		return p, nil
+	}
+	return Preference{Instance: instance, User: user}, nil
+}
@@ -0,0 +1,44 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore_Preferences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviews.jsonl")
+	ctx := context.Background()
+
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore() error = %v", err)
+	}
+	for _, p := range []Preference{
+		{User: "octocat", Muted: []string{"no-println"}},
+		{Instance: "acme", User: "octocat", SummaryOnly: true},
+		{User: "Octocat", Muted: []string{"no-println", "small-files"}},
+	} {
+		if err := s.SavePreference(ctx, p); err != nil {
+			t.Fatalf("SavePreference() error = %v", err)
+		}
+	}
+
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	got, err := reopened.GetPreference(ctx, "", "octocat")
+	if err != nil {
+		t.Fatalf("GetPreference() error = %v", err)
+	}
+	if got.SummaryOnly || len(got.Muted) != 2 {
+		t.Errorf("GetPreference() = %+v, want the last saved", got)
+	}
+	if got, _ := reopened.GetPreference(ctx, "acme", "octocat"); !got.SummaryOnly || len(got.Muted) != 0 {
+		t.Errorf("GetPreference(acme) = %+v, want summary only", got)
+	}
+	if got, _ := reopened.GetPreference(ctx, "", "hubot"); got.User != "hubot" || got.SummaryOnly || got.Muted != nil {
+		t.Errorf("GetPreference(hubot) = %+v, want the default", got)
+	}
+}
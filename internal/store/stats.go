@@ -0,0 +1,178 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// RepoStats aggregates review activity for one repository
+type RepoStats struct {
+	Repo            string    `json:"repo"`
+	Reviews         int       `json:"reviews"`
+	Failed          int       `json:"failed"`
+	FilesReviewed   int       `json:"files_reviewed"`
+	ViolationsFound int       `json:"violations_found"`
+	EstimatedTokens int       `json:"estimated_tokens"`
+	LastReviewAt    time.Time `json:"last_review_at"`
+}
+
+// RuleTrend is the number of violations of one rule per day
+type RuleTrend struct {
+	Rule  string         `json:"rule"`
+	Total int            `json:"total"`
+	ByDay map[string]int `json:"by_day"` // YYYY-MM-DD -> count
+}
+
+// Stats is the aggregate view of a set of review records
+type Stats struct {
+	Reviews         int          `json:"reviews"`
+	Failed          int          `json:"failed"`
+	ViolationsFound int          `json:"violations_found"`
+	EstimatedTokens int          `json:"estimated_tokens"`
+	Repos           []RepoStats  `json:"repos"`
+	Rules           []RuleTrend  `json:"rules"`
+	Advice          []RuleAdvice `json:"advice"`
+}
+
+// Kinds of RuleAdvice
+const (
+	AdviceNoisy = "noisy" // violated in almost every pull request
+	AdviceStale = "stale" // never violated
+)
+
+// NoisyShare is the share of reviewed pull requests above which violating
+// a rule is considered noise rather than signal
+const NoisyShare = 0.8
+
+// MinAdvicePRs is the fewest reviewed pull requests a repository needs
+// before its rules are judged
+const MinAdvicePRs = 5
+
+// RuleAdvice recommends tuning one rule of a repository's .prmate.md
+type RuleAdvice struct {
+	Repo string `json:"repo"`
+	Rule string `json:"rule"`
+	Kind string `json:"kind"` // AdviceNoisy or AdviceStale
+	PRs  int    `json:"prs"`  // reviewed pull requests that violated the rule
+	Of   int    `json:"of"`   // reviewed pull requests
+}
+
+// Summarize aggregates records into per-repo stats and per-rule daily
+// trends, each sorted by descending volume
+func Summarize(records []ReviewRecord) Stats {
+	var stats Stats
+	repos := make(map[string]*RepoStats)
+	rules := make(map[string]*RuleTrend)
+
+	for _, r := range records {
+		stats.Reviews++
+		stats.ViolationsFound += r.ViolationsFound
+		stats.EstimatedTokens += r.EstimatedTokens
+
+		name := r.Owner + "/" + r.Repo
+		rs, ok := repos[name]
+		if !ok {
+			rs = &RepoStats{Repo: name}
+			repos[name] = rs
+		}
+		rs.Reviews++
+		rs.FilesReviewed += r.FilesReviewed
+		rs.ViolationsFound += r.ViolationsFound
+		rs.EstimatedTokens += r.EstimatedTokens
+		if r.FinishedAt.After(rs.LastReviewAt) {
+			rs.LastReviewAt = r.FinishedAt
+		}
+		if r.Error != "" {
+			stats.Failed++
+			rs.Failed++
+		}
+
+		day := r.FinishedAt.UTC().Format(time.DateOnly)
+		for rule, n := range r.RuleHits {
+			rt, ok := rules[rule]
+			if !ok {
+				rt = &RuleTrend{Rule: rule, ByDay: make(map[string]int)}
+				rules[rule] = rt
+			}
+			rt.Total += n
+			rt.ByDay[day] += n
+		}
+	}
+
+	stats.Repos = make([]RepoStats, 0, len(repos))
+	for _, rs := range repos {
+		stats.Repos = append(stats.Repos, *rs)
+	}
+	sort.Slice(stats.Repos, func(i, j int) bool {
+		if stats.Repos[i].Reviews != stats.Repos[j].Reviews {
+			return stats.Repos[i].Reviews > stats.Repos[j].Reviews
+		}
+		return stats.Repos[i].Repo < stats.Repos[j].Repo
+	})
+
+	stats.Rules = make([]RuleTrend, 0, len(rules))
+	for _, rt := range rules {
+		stats.Rules = append(stats.Rules, *rt)
+	}
+	sort.Slice(stats.Rules, func(i, j int) bool {
+		if stats.Rules[i].Total != stats.Rules[j].Total {
+			return stats.Rules[i].Total > stats.Rules[j].Total
+		}
+		return stats.Rules[i].Rule < stats.Rules[j].Rule
+	})
+
+	stats.Advice = NoisyRules(records)
+	if stats.Advice == nil {
+		stats.Advice = []RuleAdvice{}
+	}
+
+	return stats
+}
+
+// NoisyRules returns, per repository, the rules violated in more than
+// NoisyShare of the pull requests reviewed successfully. A pull request
+// counts once however often it was reviewed. Repositories with fewer than
+// MinAdvicePRs reviewed pull requests are skipped.
+func NoisyRules(records []ReviewRecord) []RuleAdvice {
+	reviewed := make(map[string]map[int]bool)            // repo -> PRs
+	violated := make(map[string]map[string]map[int]bool) // repo -> rule -> PRs
+	for _, r := range records {
+		if r.Error != "" {
+			continue
+		}
+		name := r.Owner + "/" + r.Repo
+		if reviewed[name] == nil {
+			reviewed[name] = make(map[int]bool)
+			violated[name] = make(map[string]map[int]bool)
+		}
+		reviewed[name][r.PRNumber] = true
+		for rule, n := range r.RuleHits {
+			if n == 0 {
+				continue
+			}
+			if violated[name][rule] == nil {
+				violated[name][rule] = make(map[int]bool)
+			}
+			violated[name][rule][r.PRNumber] = true
+		}
+	}
+
+	var advice []RuleAdvice
+	for name, prs := range reviewed {
+		if len(prs) < MinAdvicePRs {
+			continue
+		}
+		for rule, hit := range violated[name] {
+			if float64(len(hit)) > NoisyShare*float64(len(prs)) {
+				advice = append(advice, RuleAdvice{Repo: name, Rule: rule, Kind: AdviceNoisy, PRs: len(hit), Of: len(prs)})
+			}
+		}
+	}
+	sort.Slice(advice, func(i, j int) bool {
+		if advice[i].Repo != advice[j].Repo {
+			return advice[i].Repo < advice[j].Repo
+		}
+		return advice[i].Rule < advice[j].Rule
+	})
+	return advice
+}
@@ -0,0 +1,156 @@
+// Package store persists a record of every completed review so activity can
+// be inspected after the fact, independently of the hidden PR comments.
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReviewRecord is one completed (or failed) review
+type ReviewRecord struct {
+	ID              string         `json:"id"`
+	Instance        string         `json:"instance,omitempty"`
+	Owner           string         `json:"owner"`
+	Repo            string         `json:"repo"`
+	PRNumber        int            `json:"pr"`
+	HeadSHA         string         `json:"head_sha"`
+	CorrelationID   string         `json:"correlation_id,omitempty"`
+	StartedAt       time.Time      `json:"started_at"`
+	FinishedAt      time.Time      `json:"finished_at"`
+	FilesReviewed   int            `json:"files_reviewed"`
+	CommentsPosted  int            `json:"comments_posted"`
+	ViolationsFound int            `json:"violations_found"`
+	RuleHits        map[string]int `json:"rule_hits,omitempty"` // violations per rule
+	Files           []string       `json:"files,omitempty"`     // paths the pull request changed
+	EstimatedTokens int            `json:"estimated_tokens"`
+	Error           string         `json:"error,omitempty"`
+	Shadow          bool           `json:"shadow,omitempty"`   // reviewed in shadow mode, posting nothing
+	Blocking        bool           `json:"blocking,omitempty"` // the review requested, or in shadow mode would have requested, changes
+	Findings        []Finding      `json:"findings,omitempty"` // kept for shadow reviews, to compare with human reviews
+}
+
+// Finding is where a review found a problem
+type Finding struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+}
+
+// Filter narrows ListReviews results; zero values match everything
+type Filter struct {
+	Instance string
+	Owner    string
+	Repo     string
+	Since    time.Time
+	Limit    int
+}
+
+func (f Filter) matches(r ReviewRecord) bool {
+	if f.Instance != "" && f.Instance != r.Instance {
+		return false
+	}
+	if f.Owner != "" && f.Owner != r.Owner {
+		return false
+	}
+	if f.Repo != "" && f.Repo != r.Repo {
+		return false
+	}
+	if !f.Since.IsZero() && r.FinishedAt.Before(f.Since) {
+		return false
+	}
+	return true
+}
+
+// FileStore keeps review records in memory and appends them to a JSON Lines
+// file so they survive restarts. Rule exemptions and developer preferences
+// are kept the same way in files next to it. An empty path keeps records in
+// memory only.
+type FileStore struct {
+	mu          sync.RWMutex
+	path        string
+	records     []ReviewRecord
+	exemptions  []Exemption
+	preferences map[string]Preference // by instance and lowercase login
+}
+
+// OpenFileStore loads existing records from path, creating its directory
+// if needed. Unreadable lines are skipped with a warning.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, preferences: make(map[string]Preference)}
+	if path == "" {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create store dir: %w", err)
+	}
+	if err := s.loadExemptions(exemptionsPath(path)); err != nil {
+		return nil, err
+	}
+	if err := s.loadPreferences(preferencesPath(path)); err != nil {
+		return nil, err
+	}
+	err := readJSONLines(path, "review store", func(r ReviewRecord) {
+		s.records = append(s.records, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RecordReview appends r to the store
+func (s *FileStore) RecordReview(ctx context.Context, r ReviewRecord) error {
+	_ = ctx
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path != "" {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("encode review record: %w", err)
+		}
+		f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("open review store: %w", err)
+		}
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			f.Close()
+			return fmt.Errorf("write review record: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("close review store: %w", err)
+		}
+	}
+
+	s.records = append(s.records, r)
+	return nil
+}
+
+// ListReviews returns matching records, most recently finished first
+func (s *FileStore) ListReviews(ctx context.Context, f Filter) ([]ReviewRecord, error) {
+	_ = ctx
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]ReviewRecord, 0)
+	for _, r := range s.records {
+		if f.matches(r) {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].FinishedAt.After(out[j].FinishedAt) })
+
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[:f.Limit]
+	}
+	return out, nil
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileStore_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "reviews.jsonl")
+	ctx := context.Background()
+
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore() error = %v", err)
+	}
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	records := []ReviewRecord{
+		{ID: "1", Owner: "o", Repo: "a", PRNumber: 1, FinishedAt: base},
+		{ID: "2", Instance: "acme", Owner: "o", Repo: "b", PRNumber: 2, FinishedAt: base.Add(time.Hour)},
+		{ID: "3", Owner: "o", Repo: "a", PRNumber: 3, FinishedAt: base.Add(2 * time.Hour)},
+	}
+	for _, r := range records {
+		if err := s.RecordReview(ctx, r); err != nil {
+			t.Fatalf("RecordReview() error = %v", err)
+		}
+	}
+
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		wantIDs []string
+	}{
+		{name: "all newest first", filter: Filter{}, wantIDs: []string{"3", "2", "1"}},
+		{name: "by repo", filter: Filter{Repo: "a"}, wantIDs: []string{"3", "1"}},
+		{name: "by instance", filter: Filter{Instance: "acme"}, wantIDs: []string{"2"}},
+		{name: "since", filter: Filter{Since: base.Add(30 * time.Minute)}, wantIDs: []string{"3", "2"}},
+		{name: "limit", filter: Filter{Limit: 1}, wantIDs: []string{"3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reopened.ListReviews(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("ListReviews() error = %v", err)
+			}
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("len = %d, want %d", len(got), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if got[i].ID != id {
+					t.Errorf("got[%d].ID = %q, want %q", i, got[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	stats := Summarize([]ReviewRecord{
+		{Owner: "o", Repo: "a", FinishedAt: day1, ViolationsFound: 2, EstimatedTokens: 100, RuleHits: map[string]int{"naming": 2}},
+		{Owner: "o", Repo: "a", FinishedAt: day2, ViolationsFound: 1, EstimatedTokens: 50, RuleHits: map[string]int{"naming": 1}},
+		{Owner: "o", Repo: "b", FinishedAt: day2, Error: "boom", RuleHits: map[string]int{"errors": 1}},
+	})
+
+	if stats.Reviews != 3 || stats.Failed != 1 || stats.EstimatedTokens != 150 {
+		t.Errorf("totals = %+v", stats)
+	}
+	if len(stats.Repos) != 2 || stats.Repos[0].Repo != "o/a" || stats.Repos[0].Reviews != 2 {
+		t.Errorf("Repos = %+v, want o/a first with 2 reviews", stats.Repos)
+	}
+	if !stats.Repos[0].LastReviewAt.Equal(day2) {
+		t.Errorf("LastReviewAt = %v, want %v", stats.Repos[0].LastReviewAt, day2)
+	}
+	if len(stats.Rules) != 2 || stats.Rules[0].Rule != "naming" || stats.Rules[0].Total != 3 {
+		t.Fatalf("Rules = %+v, want naming first with 3", stats.Rules)
+	}
+	if stats.Rules[0].ByDay["2026-03-01"] != 2 || stats.Rules[0].ByDay["2026-03-02"] != 1 {
+		t.Errorf("ByDay = %v", stats.Rules[0].ByDay)
+	}
+}
+
+func TestNoisyRules(t *testing.T) {
+	var records []ReviewRecord
+	for pr := 1; pr <= 6; pr++ {
+		hits := map[string]int{"naming": 1}
+		if pr <= 4 {
+			hits["errors"] = 2
+		}
+		if pr == 6 {
+			delete(hits, "naming")
+		}
+		// Every PR is reviewed twice; the second push fixed everything
+		records = append(records,
+			ReviewRecord{Owner: "o", Repo: "a", PRNumber: pr, RuleHits: hits},
+			ReviewRecord{Owner: "o", Repo: "a", PRNumber: pr},
+		)
+	}
+	// Too few pull requests to judge
+	records = append(records, ReviewRecord{Owner: "o", Repo: "b", PRNumber: 1, RuleHits: map[string]int{"naming": 1}})
+	// Failed reviews do not count
+	records = append(records, ReviewRecord{Owner: "o", Repo: "a", PRNumber: 7, Error: "boom"})
+
+	got := NoisyRules(records)
+	want := []RuleAdvice{{Repo: "o/a", Rule: "naming", Kind: AdviceNoisy, PRs: 5, Of: 6}}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("NoisyRules() = %+v, want %+v", got, want)
+	}
+}
+
+func TestOpenFileStore_SkipsUnreadableLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviews.jsonl")
+	content := `{"id":"1","owner":"o","repo":"a"}` + "\n" + "not json\n" + `{"id":"2","owner":"o","re`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore() error = %v, want unreadable lines skipped", err)
+	}
+	if err := s.RecordReview(ctx, ReviewRecord{ID: "3", Owner: "o", Repo: "b"}); err != nil {
+		t.Fatalf("RecordReview() error = %v", err)
+	}
+
+	// The partly written line is gone, so the new record reads back too
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	got, _ := reopened.ListReviews(ctx, Filter{})
+	var ids []string
+	for _, r := range got {
+		ids = append(ids, r.ID)
+	}
+	sort.Strings(ids)
+	if strings.Join(ids, ",") != "1,3" {
+		t.Errorf("records %v, want 1 and 3", ids)
+	}
+}
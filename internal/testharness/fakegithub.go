@@ -0,0 +1,427 @@
+// Package testharness runs PRMate end to end without network access. It
+// provides a fake GitHub API on an httptest server, an LLM that replays
+// recorded responses, and golden-file comparison, so a scenario can deliver
+// a webhook and check every comment PRMate writes back. Forks can use it to
+// write scenario tests for their own rules and wiring.
+package testharness
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+// PR is a pull request served by FakeGitHub
+type PR struct {
+	Owner   string `json:"owner"`
+	Repo    string `json:"repo"`
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body,omitempty"`
+	Author  string `json:"author"`
+	HeadRef string `json:"head_ref"`
+	HeadSHA string `json:"head_sha"`
+	BaseRef string `json:"base_ref"`
+	BaseSHA string `json:"base_sha"`
+	Files   []File `json:"files"`
+}
+
+// File is a file a PR changes. Its content, when set, is served at every
+// ref.
+type File struct {
+	Path    string `json:"path"`
+	Status  string `json:"status,omitempty"` // modified when empty
+	Patch   string `json:"patch"`
+	Content string `json:"content,omitempty"`
+}
+
+// Comment is a comment PRMate wrote to the fake: an issue comment, or an
+// inline comment of a review
+type Comment struct {
+	ID   int64
+	Path string // inline comments only
+	Line int
+	Body string
+}
+
+// Review is a pull request review PRMate submitted to the fake
+type Review struct {
+	PR       int
+	CommitID string
+	Event    string
+	Body     string
+	Comments []Comment
+}
+
+// FakeGitHub is an in-memory GitHub REST and GraphQL API serving the pull
+// requests and repository files a test adds, and recording what PRMate
+// writes. Requests it does not implement fail with 404 and are logged.
+type FakeGitHub struct {
+	Server *httptest.Server
+
+	t        testing.TB
+	mu       sync.Mutex
+	prs      map[string]PR     // owner/repo#number
+	files    map[string]string // owner/repo:path
+	comments map[string][]Comment
+	reviews  []Review
+	inline   map[string][]Comment // review comments per owner/repo#number
+	nextID   int64
+}
+
+// NewFakeGitHub starts a fake GitHub API that is shut down when the test
+// ends
+func NewFakeGitHub(t testing.TB) *FakeGitHub {
+	t.Helper()
+	f := &FakeGitHub{
+		t:        t,
+		prs:      make(map[string]PR),
+		files:    make(map[string]string),
+		comments: make(map[string][]Comment),
+		inline:   make(map[string][]Comment),
+	}
+
+	mux := http.NewServeMux()
+	const repo = "/api/v3/repos/{owner}/{repo}"
+	mux.HandleFunc("GET "+repo+"/pulls/{number}", f.getPR)
+	mux.HandleFunc("GET "+repo+"/pulls/{number}/files", f.listFiles)
+	mux.HandleFunc("GET "+repo+"/pulls/{number}/comments", f.listReviewComments)
+	mux.HandleFunc("POST "+repo+"/pulls/{number}/reviews", f.createReview)
+	mux.HandleFunc("GET "+repo+"/contents/{path...}", f.getContents)
+	mux.HandleFunc("GET "+repo+"/issues/{number}/comments", f.listComments)
+	mux.HandleFunc("POST "+repo+"/issues/{number}/comments", f.createComment)
+	mux.HandleFunc("PATCH "+repo+"/issues/comments/{id}", f.editComment)
+	mux.HandleFunc("POST /api/graphql", f.graphQL)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Logf("fake github: %s %s is not implemented", r.Method, r.URL.Path)
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+	})
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Server.Close)
+	return f
+}
+
+// Client returns a GitHub client talking to the fake
+func (f *FakeGitHub) Client() *ghclient.Client {
+	f.t.Helper()
+	client, err := ghclient.NewEnterpriseClient("test-token", f.Server.URL)
+	if err != nil {
+		f.t.Fatalf("create fake github client: %v", err)
+	}
+	return client
+}
+
+// AddPR serves pr and the content of its files
+func (f *FakeGitHub) AddPR(pr PR) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prs[prKey(pr.Owner, pr.Repo, pr.Number)] = pr
+	for _, file := range pr.Files {
+		if file.Content != "" {
+			f.files[pr.Owner+"/"+pr.Repo+":"+file.Path] = file.Content
+		}
+	}
+}
+
+// SetFile serves content as path in owner/repo at every ref
+func (f *FakeGitHub) SetFile(owner, repo, path, content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.files[owner+"/"+repo+":"+path] = content
+}
+
+// Reviews returns the reviews submitted so far, oldest first
+func (f *FakeGitHub) Reviews() []Review {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Review(nil), f.reviews...)
+}
+
+// Comments returns the issue comments on a pull request, oldest first
+func (f *FakeGitHub) Comments(owner, repo string, number int) []Comment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Comment(nil), f.comments[prKey(owner, repo, number)]...)
+}
+
+func prKey(owner, repo string, number int) string {
+	return fmt.Sprintf("%s/%s#%d", owner, repo, number)
+}
+
+// pr looks up the pull request a request is about, answering 404 when
+// there is none
+func (f *FakeGitHub) pr(w http.ResponseWriter, r *http.Request) (PR, string, bool) {
+	number, _ := strconv.Atoi(r.PathValue("number"))
+	key := prKey(r.PathValue("owner"), r.PathValue("repo"), number)
+	pr, ok := f.prs[key]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+	}
+	return pr, key, ok
+}
+
+func (f *FakeGitHub) getPR(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pr, _, ok := f.pr(w, r)
+	if !ok {
+		return
+	}
+	fullName := pr.Owner + "/" + pr.Repo
+	writeJSON(w, http.StatusOK, map[string]any{
+		"number":   pr.Number,
+		"title":    pr.Title,
+		"body":     pr.Body,
+		"state":    "open",
+		"html_url": fmt.Sprintf("https://github.com/%s/pull/%d", fullName, pr.Number),
+		"user":     map[string]string{"login": pr.Author},
+		"head":     map[string]any{"ref": pr.HeadRef, "sha": pr.HeadSHA, "repo": map[string]string{"full_name": fullName}},
+		"base":     map[string]any{"ref": pr.BaseRef, "sha": pr.BaseSHA, "repo": map[string]string{"full_name": fullName}},
+	})
+}
+
+func (f *FakeGitHub) listFiles(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pr, _, ok := f.pr(w, r)
+	if !ok {
+		return
+	}
+	files := make([]map[string]any, 0, len(pr.Files))
+	for _, file := range pr.Files {
+		status := file.Status
+		if status == "" {
+			status = "modified"
+		}
+		additions, deletions := countLines(file.Patch)
+		files = append(files, map[string]any{
+			"filename":  file.Path,
+			"status":    status,
+			"additions": additions,
+			"deletions": deletions,
+			"patch":     file.Patch,
+		})
+	}
+	writeJSON(w, http.StatusOK, files)
+}
+
+func (f *FakeGitHub) getContents(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := r.PathValue("path")
+	repo := r.PathValue("owner") + "/" + r.PathValue("repo") + ":"
+	content, ok := f.files[repo+path]
+	if !ok {
+		// A directory lists the files directly in it
+		var entries []map[string]any
+		for key := range f.files {
+			name, ok := strings.CutPrefix(key, repo+path+"/")
+			if ok && !strings.Contains(name, "/") {
+				entries = append(entries, map[string]any{"type": "file", "name": name, "path": path + "/" + name})
+			}
+		}
+		if len(entries) == 0 {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, entries)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"type":     "file",
+		"path":     path,
+		"size":     len(content),
+		"encoding": "base64",
+		"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+	})
+}
+
+func (f *FakeGitHub) listComments(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, key, ok := f.pr(w, r)
+	if !ok {
+		return
+	}
+	out := make([]map[string]any, 0, len(f.comments[key]))
+	for _, c := range f.comments[key] {
+		out = append(out, map[string]any{"id": c.ID, "body": c.Body})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (f *FakeGitHub) createComment(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Body string `json:"body"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, key, ok := f.pr(w, r)
+	if !ok {
+		return
+	}
+	f.nextID++
+	c := Comment{ID: f.nextID, Body: req.Body}
+	f.comments[key] = append(f.comments[key], c)
+	writeJSON(w, http.StatusCreated, map[string]any{"id": c.ID, "body": c.Body})
+}
+
+func (f *FakeGitHub) editComment(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Body string `json:"body"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	prefix := r.PathValue("owner") + "/" + r.PathValue("repo") + "#"
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for key, comments := range f.comments {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		for i := range comments {
+			if comments[i].ID == id {
+				comments[i].Body = req.Body
+				writeJSON(w, http.StatusOK, map[string]any{"id": id, "body": req.Body})
+				return
+			}
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+}
+
+func (f *FakeGitHub) listReviewComments(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pr, key, ok := f.pr(w, r)
+	if !ok {
+		return
+	}
+	out := make([]map[string]any, 0, len(f.inline[key]))
+	for _, c := range f.inline[key] {
+		out = append(out, map[string]any{"id": c.ID, "path": c.Path, "line": c.Line, "side": "RIGHT", "body": c.Body, "commit_id": pr.HeadSHA})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (f *FakeGitHub) createReview(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		CommitID string `json:"commit_id"`
+		Body     string `json:"body"`
+		Event    string `json:"event"`
+		Comments []struct {
+			Path string `json:"path"`
+			Line int    `json:"line"`
+			Body string `json:"body"`
+		} `json:"comments"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pr, key, ok := f.pr(w, r)
+	if !ok {
+		return
+	}
+
+	f.nextID++
+	review := Review{PR: pr.Number, CommitID: req.CommitID, Event: req.Event, Body: req.Body}
+	id := f.nextID
+	for _, c := range req.Comments {
+		f.nextID++
+		comment := Comment{ID: f.nextID, Path: c.Path, Line: c.Line, Body: c.Body}
+		review.Comments = append(review.Comments, comment)
+		f.inline[key] = append(f.inline[key], comment)
+	}
+	f.reviews = append(f.reviews, review)
+	writeJSON(w, http.StatusOK, map[string]any{"id": id, "body": req.Body, "state": "COMMENTED"})
+}
+
+// graphQL answers the review threads query with one unresolved thread per
+// inline comment PRMate posted
+func (f *FakeGitHub) graphQL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Variables struct {
+			Owner  string `json:"owner"`
+			Repo   string `json:"repo"`
+			Number int    `json:"number"`
+		} `json:"variables"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	comments := append([]Comment(nil), f.inline[prKey(req.Variables.Owner, req.Variables.Repo, req.Variables.Number)]...)
+	sort.SliceStable(comments, func(i, j int) bool { return comments[i].ID < comments[j].ID })
+	nodes := make([]map[string]any, 0, len(comments))
+	for _, c := range comments {
+		nodes = append(nodes, map[string]any{
+			"isResolved": false,
+			"isOutdated": false,
+			"path":       c.Path,
+			"line":       c.Line,
+			"comments": map[string]any{
+				"totalCount": 1,
+				"nodes": []map[string]any{{
+					"viewerDidAuthor": true,
+					"body":            c.Body,
+					"author":          map[string]string{"login": "prmate[bot]", "__typename": "Bot"},
+				}},
+			},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"repository": map[string]any{"pullRequest": map[string]any{
+		"reviewThreads": map[string]any{"nodes": nodes, "pageInfo": map[string]any{"hasNextPage": false}},
+	}}}})
+}
+
+func countLines(patch string) (additions, deletions int) {
+	for _, hunk := range ghclient.ParsePatch(patch) {
+		for _, l := range hunk.Lines {
+			switch l.Type {
+			case "add":
+				additions++
+			case "remove":
+				deletions++
+			}
+		}
+	}
+	return additions, deletions
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	data, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(data, v)
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
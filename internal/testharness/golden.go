@@ -0,0 +1,37 @@
+package testharness
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// UpdateEnv names the environment variable that makes Golden rewrite golden
+// files instead of comparing against them:
+//
+//	UPDATE_GOLDEN=1 go test ./internal/testharness/...
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// Golden compares got with the golden file at path, failing the test on a
+// difference. With UPDATE_GOLDEN set it writes got to path instead.
+func Golden(t testing.TB, path, got string) {
+	t.Helper()
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create golden dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run with %s=1 to create it): %v", UpdateEnv, err)
+	}
+	if string(want) != got {
+		t.Errorf("output differs from %s (run with %s=1 to update it)\n--- got ---\n%s\n--- want ---\n%s", path, UpdateEnv, got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package testharness
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// noViolations is what RecordedLLM answers prompts no response matches
+const noViolations = `{"violations": []}`
+
+// Response is a recorded LLM answer to the prompts containing Match
+type Response struct {
+	Match    string `json:"match"`
+	Response string `json:"response"`
+}
+
+// RecordedLLM replays recorded responses instead of calling a model. A
+// prompt gets the first response whose Match it contains; prompts matching
+// none get an empty list of violations.
+type RecordedLLM struct {
+	Responses []Response
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+// GenerateText answers prompt from the recorded responses
+func (l *RecordedLLM) GenerateText(prompt string) (string, error) {
+	return l.GenerateTextWithContext(context.Background(), prompt)
+}
+
+// GenerateTextWithContext answers prompt from the recorded responses
+func (l *RecordedLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	l.mu.Lock()
+	l.prompts = append(l.prompts, prompt)
+	l.mu.Unlock()
+
+	for _, r := range l.Responses {
+		if strings.Contains(prompt, r.Match) {
+			return r.Response, nil
+		}
+	}
+	return noViolations, nil
+}
+
+// Prompts returns the prompts received so far, in order
+func (l *RecordedLLM) Prompts() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.prompts...)
+}
@@ -0,0 +1,140 @@
+package testharness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"prmate/internal/correlation"
+	"prmate/internal/prworkspace"
+	"prmate/internal/review"
+	"prmate/internal/webhook"
+)
+
+// Scenario is a recorded pull request with the repository files and LLM
+// responses needed to review it
+type Scenario struct {
+	Name      string            `json:"name"`
+	PR        PR                `json:"pr"`
+	RepoFiles map[string]string `json:"repo_files"` // path -> content, such as .prmate.md
+	LLM       []Response        `json:"llm"`
+}
+
+// LoadScenario reads a Scenario from a JSON file
+func LoadScenario(t testing.TB, path string) Scenario {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read scenario: %v", err)
+	}
+	var s Scenario
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("parse scenario %s: %v", path, err)
+	}
+	return s
+}
+
+// Env is a webhook processor wired to a FakeGitHub and a RecordedLLM, the
+// way the server wires one SCM instance
+type Env struct {
+	Scenario  Scenario
+	GitHub    *FakeGitHub
+	LLM       *RecordedLLM
+	Review    *review.Service
+	Processor *webhook.Processor
+}
+
+// NewEnv serves the scenario's pull request and files from a FakeGitHub and
+// builds a processor that reviews with cfg. Tests can add optional
+// dependencies to Processor before delivering events.
+func NewEnv(t testing.TB, s Scenario, cfg review.Config) *Env {
+	t.Helper()
+	gh := NewFakeGitHub(t)
+	gh.AddPR(s.PR)
+	for path, content := range s.RepoFiles {
+		gh.SetFile(s.PR.Owner, s.PR.Repo, path, content)
+	}
+
+	client := gh.Client()
+	llm := &RecordedLLM{Responses: s.LLM}
+	reviewSvc := review.NewService(client, llm, cfg)
+	return &Env{
+		Scenario:  s,
+		GitHub:    gh,
+		LLM:       llm,
+		Review:    reviewSvc,
+		Processor: webhook.NewProcessor(prworkspace.NewManager(t.TempDir()), nil, reviewSvc, client),
+	}
+}
+
+// Deliver hands a webhook event to the processor as the webhook handler
+// does. The correlation ID is fixed so that comment markers are stable.
+func (e *Env) Deliver(eventType string, payload []byte) error {
+	ctx := correlation.WithID(context.Background(), "scenario-"+e.Scenario.Name)
+	return e.Processor.Process(ctx, eventType, payload, "delivery-"+e.Scenario.Name)
+}
+
+// OpenPR delivers a pull_request event opening the scenario's pull request
+func (e *Env) OpenPR() error {
+	return e.Deliver("pull_request", PullRequestEvent(e.Scenario.PR, "opened"))
+}
+
+// PullRequestEvent builds the payload of a pull_request webhook for pr
+func PullRequestEvent(pr PR, action string) []byte {
+	payload, _ := json.Marshal(map[string]any{
+		"action": action,
+		"number": pr.Number,
+		"pull_request": map[string]any{
+			"number": pr.Number,
+			"title":  pr.Title,
+			"user":   map[string]string{"login": pr.Author},
+			"head":   map[string]string{"ref": pr.HeadRef, "sha": pr.HeadSHA},
+			"base":   map[string]string{"ref": pr.BaseRef, "sha": pr.BaseSHA},
+		},
+		"repository": map[string]any{
+			"name":      pr.Repo,
+			"full_name": pr.Owner + "/" + pr.Repo,
+			"owner":     map[string]string{"login": pr.Owner},
+		},
+		"sender": map[string]string{"login": pr.Author},
+	})
+	return payload
+}
+
+// timestamp matches the RFC 3339 times PRMate embeds in its comments
+var timestamp = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?Z`)
+
+// Transcript renders everything PRMate wrote to the scenario's pull
+// request, reviews first, for comparison with a golden file. Timestamps are
+// replaced with <time> so that the transcript is stable.
+func (e *Env) Transcript() string {
+	var b strings.Builder
+	for _, r := range e.GitHub.Reviews() {
+		if r.PR != e.Scenario.PR.Number {
+			continue
+		}
+		fmt.Fprintf(&b, "=== review %s at %s\n%s\n", r.Event, r.CommitID, r.Body)
+		comments := append([]Comment(nil), r.Comments...)
+		sort.SliceStable(comments, func(i, j int) bool {
+			if comments[i].Path != comments[j].Path {
+				return comments[i].Path < comments[j].Path
+			}
+			return comments[i].Line < comments[j].Line
+		})
+		for _, c := range comments {
+			fmt.Fprintf(&b, "--- %s:%d\n%s\n", c.Path, c.Line, c.Body)
+		}
+	}
+	for _, c := range e.GitHub.Comments(e.Scenario.PR.Owner, e.Scenario.PR.Repo, e.Scenario.PR.Number) {
+		fmt.Fprintf(&b, "=== comment\n%s\n", c.Body)
+	}
+	if b.Len() == 0 {
+		return "(nothing written)\n"
+	}
+	return timestamp.ReplaceAllString(b.String(), "<time>")
+}
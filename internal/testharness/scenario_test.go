@@ -0,0 +1,49 @@
+package testharness
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"prmate/internal/review"
+)
+
+// TestScenarios reviews every recorded pull request in testdata/scenarios
+// end to end and compares what PRMate wrote with testdata/golden
+func TestScenarios(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "scenarios", "*.json"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no scenarios found: %v", err)
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		t.Run(name, func(t *testing.T) {
+			env := NewEnv(t, LoadScenario(t, path), review.Config{})
+			if err := env.OpenPR(); err != nil {
+				t.Fatalf("deliver pull_request event: %v", err)
+			}
+			Golden(t, filepath.Join("testdata", "golden", name+".golden"), env.Transcript())
+		})
+	}
+}
+
+func TestRecordedLLM(t *testing.T) {
+	llm := &RecordedLLM{Responses: []Response{
+		{Match: "a.go", Response: "first"},
+		{Match: ".go", Response: "second"},
+	}}
+
+	for prompt, want := range map[string]string{
+		"review a.go": "first",
+		"review b.go": "second",
+		"review b.js": noViolations,
+	} {
+		if got, _ := llm.GenerateText(prompt); got != want {
+			t.Errorf("GenerateText(%q) = %q, want %q", prompt, got, want)
+		}
+	}
+	if len(llm.Prompts()) != 3 {
+		t.Errorf("Prompts() = %v, want the 3 prompts", llm.Prompts())
+	}
+}
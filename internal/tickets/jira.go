@@ -0,0 +1,121 @@
+package tickets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// requestTimeout bounds each tracker API call
+const requestTimeout = 30 * time.Second
+
+// Jira files issues through the Jira Cloud/Server REST API v2, which takes
+// plain-text descriptions
+type Jira struct {
+	baseURL string
+	email   string
+	token   string
+	client  *http.Client
+}
+
+// NewJira returns a tracker for the Jira site at baseURL, authenticating
+// with an account email and API token
+func NewJira(baseURL, email, token string) *Jira {
+	return &Jira{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		email:   email,
+		token:   token,
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (j *Jira) Create(ctx context.Context, project string, t Ticket) (Ref, error) {
+	fields := map[string]any{
+		"project":     map[string]string{"key": project},
+		"summary":     t.Title,
+		"description": t.Description,
+		"issuetype":   map[string]string{"name": t.IssueType},
+	}
+	if len(t.Labels) > 0 {
+		fields["labels"] = t.Labels
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := j.do(ctx, "/rest/api/2/issue", map[string]any{"fields": fields}, &created); err != nil {
+		return Ref{}, err
+	}
+	return Ref{Key: created.Key, URL: j.browseURL(created.Key)}, nil
+}
+
+func (j *Jira) Comment(ctx context.Context, key, body string) (Ref, error) {
+	if err := j.do(ctx, "/rest/api/2/issue/"+key+"/comment", map[string]string{"body": body}, nil); err != nil {
+		return Ref{}, err
+	}
+	return Ref{Key: key, URL: j.browseURL(key)}, nil
+}
+
+func (j *Jira) browseURL(key string) string {
+	return j.baseURL + "/browse/" + key
+}
+
+func (j *Jira) do(ctx context.Context, path string, in, out any) error {
+	req, err := newJSONRequest(ctx, j.baseURL+path, in)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(j.email, j.token)
+	return doJSON(j.client, req, out)
+}
+
+func newJSONRequest(ctx context.Context, target string, in any) (*http.Request, error) {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// doJSON sends req and decodes a successful JSON response into out, if set
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("tracker returned %d: %s", resp.StatusCode, truncate(string(data), 200))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) > n {
+		return s[:n] + "…"
+	}
+	return s
+}
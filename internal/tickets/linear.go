@@ -0,0 +1,108 @@
+package tickets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const linearEndpoint = "https://api.linear.app/graphql"
+
+// Linear files issues through the Linear GraphQL API. Labels are not set:
+// Linear only accepts existing label IDs.
+type Linear struct {
+	apiKey   string
+	endpoint string
+	client   *http.Client
+}
+
+// NewLinear returns a tracker authenticating with a Linear API key
+func NewLinear(apiKey string) *Linear {
+	return &Linear{apiKey: apiKey, endpoint: linearEndpoint, client: &http.Client{Timeout: requestTimeout}}
+}
+
+func (l *Linear) Create(ctx context.Context, team string, t Ticket) (Ref, error) {
+	var teams struct {
+		Teams struct {
+			Nodes []struct {
+				ID string `json:"id"`
+			} `json:"nodes"`
+		} `json:"teams"`
+	}
+	err := l.query(ctx, `query($key: String!) { teams(filter: {key: {eq: $key}}) { nodes { id } } }`,
+		map[string]any{"key": team}, &teams)
+	if err != nil {
+		return Ref{}, err
+	}
+	if len(teams.Teams.Nodes) == 0 {
+		return Ref{}, fmt.Errorf("linear team %q not found", team)
+	}
+
+	var created struct {
+		IssueCreate struct {
+			Success bool `json:"success"`
+			Issue   struct {
+				Identifier string `json:"identifier"`
+				URL        string `json:"url"`
+			} `json:"issue"`
+		} `json:"issueCreate"`
+	}
+	err = l.query(ctx, `mutation($input: IssueCreateInput!) { issueCreate(input: $input) { success issue { identifier url } } }`,
+		map[string]any{"input": map[string]any{
+			"teamId":      teams.Teams.Nodes[0].ID,
+			"title":       t.Title,
+			"description": t.Description,
+		}}, &created)
+	if err != nil {
+		return Ref{}, err
+	}
+	if !created.IssueCreate.Success {
+		return Ref{}, fmt.Errorf("linear did not create the issue")
+	}
+	return Ref{Key: created.IssueCreate.Issue.Identifier, URL: created.IssueCreate.Issue.URL}, nil
+}
+
+func (l *Linear) Comment(ctx context.Context, key, body string) (Ref, error) {
+	var created struct {
+		CommentCreate struct {
+			Success bool `json:"success"`
+			Comment struct {
+				URL string `json:"url"`
+			} `json:"comment"`
+		} `json:"commentCreate"`
+	}
+	// issueId accepts identifiers such as ENG-123 as well as UUIDs
+	err := l.query(ctx, `mutation($input: CommentCreateInput!) { commentCreate(input: $input) { success comment { url } } }`,
+		map[string]any{"input": map[string]any{"issueId": key, "body": body}}, &created)
+	if err != nil {
+		return Ref{}, err
+	}
+	if !created.CommentCreate.Success {
+		return Ref{}, fmt.Errorf("linear did not add the comment")
+	}
+	return Ref{Key: key, URL: created.CommentCreate.Comment.URL}, nil
+}
+
+// query runs a GraphQL operation and decodes its data into out
+func (l *Linear) query(ctx context.Context, query string, variables map[string]any, out any) error {
+	req, err := newJSONRequest(ctx, l.endpoint, map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", l.apiKey)
+
+	var resp struct {
+		Data   any `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	resp.Data = out
+	if err := doJSON(l.client, req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("linear: %s", resp.Errors[0].Message)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package tickets files issue tracker tickets for error-severity review
+// findings that are still open when a pull request is merged, so they are
+// tracked after the review comments scroll out of sight. Repositories are
+// routed to a Jira project or Linear team; a pull request whose title or
+// branch already names an issue of that project gets the findings added to
+// that issue instead of a new ticket.
+package tickets
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"prmate/internal/config"
+	"prmate/internal/review"
+)
+
+// maxTitle keeps ticket titles within tracker limits
+const maxTitle = 200
+
+// Tracker creates and comments on issues in one issue tracker
+type Tracker interface {
+	Create(ctx context.Context, project string, t Ticket) (Ref, error)
+	Comment(ctx context.Context, key, body string) (Ref, error)
+}
+
+// Ticket is a new issue
+type Ticket struct {
+	Title       string
+	Description string
+	IssueType   string // Jira only
+	Labels      []string
+}
+
+// Ref identifies an issue that received the findings
+type Ref struct {
+	Key     string
+	URL     string
+	Created bool // false when the findings were added to an existing issue
+}
+
+// PullRequest is the merged pull request findings are filed for
+type PullRequest struct {
+	Owner  string
+	Repo   string
+	Number int
+	Title  string
+	Branch string
+	URL    string
+}
+
+// Filer routes findings to the tracker configured for their repository
+type Filer struct {
+	routes   []config.TicketRoute
+	trackers map[string]Tracker // by route tracker name
+}
+
+// NewFiler returns a filer for routes; trackers holds one Tracker per
+// tracker name the routes use
+func NewFiler(routes []config.TicketRoute, trackers map[string]Tracker) *Filer {
+	return &Filer{routes: routes, trackers: trackers}
+}
+
+// File opens a ticket for the findings of pr that its route selects, or
+// comments on the issue pr already references. It returns nil when no
+// route covers the repository or no finding is selected.
+func (f *Filer) File(ctx context.Context, pr PullRequest, findings []review.OpenFinding) (*Ref, error) {
+	route, ok := f.route(pr.Owner, pr.Repo)
+	if !ok {
+		return nil, nil
+	}
+	selected := selectFindings(route.Rules, findings)
+	if len(selected) == 0 {
+		return nil, nil
+	}
+	tracker, ok := f.trackers[route.Tracker]
+	if !ok {
+		return nil, fmt.Errorf("no %s tracker configured", route.Tracker)
+	}
+
+	description := describe(pr, selected)
+	if key := referencedIssue(route.Project, pr.Title+" "+pr.Branch); key != "" {
+		ref, err := tracker.Comment(ctx, key, description)
+		if err != nil {
+			return nil, fmt.Errorf("comment on %s: %w", key, err)
+		}
+		return &ref, nil
+	}
+
+	title := fmt.Sprintf("Unresolved review findings from %s/%s#%d: %s", pr.Owner, pr.Repo, pr.Number, pr.Title)
+	if len(title) > maxTitle {
+		title = title[:maxTitle-1] + "…"
+	}
+	ref, err := tracker.Create(ctx, route.Project, Ticket{
+		Title:       title,
+		Description: description,
+		IssueType:   route.IssueType,
+		Labels:      append([]string{"prmate"}, route.Labels...),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create %s ticket: %w", route.Tracker, err)
+	}
+	ref.Created = true
+	return &ref, nil
+}
+
+// route returns the first route covering owner/repo
+func (f *Filer) route(owner, repo string) (config.TicketRoute, bool) {
+	name := strings.ToLower(owner + "/" + repo)
+	for _, r := range f.routes {
+		if len(r.Repos) == 0 {
+			return r, true
+		}
+		for _, p := range r.Repos {
+			if ok, _ := path.Match(strings.ToLower(p), name); ok {
+				return r, true
+			}
+		}
+	}
+	return config.TicketRoute{}, false
+}
+
+// selectFindings keeps findings whose rule contains one of rules; no rules
+// keeps every finding
+func selectFindings(rules []string, findings []review.OpenFinding) []review.OpenFinding {
+	if len(rules) == 0 {
+		return findings
+	}
+	var out []review.OpenFinding
+	for _, f := range findings {
+		rule := strings.ToLower(f.Rule)
+		for _, r := range rules {
+			if strings.Contains(rule, strings.ToLower(r)) {
+				out = append(out, f)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// referencedIssue returns the first issue key of project (such as SEC-42)
+// mentioned in text
+func referencedIssue(project, text string) string {
+	re := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(project) + `-[0-9]+\b`)
+	return strings.ToUpper(re.FindString(text))
+}
+
+// describe lists the findings in a form both Jira and Linear render as a
+// bullet list
+func describe(pr PullRequest, findings []review.OpenFinding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s/%s#%d was merged with %d unresolved error-severity findings from PRMate review.\n", pr.Owner, pr.Repo, pr.Number, len(findings))
+	if pr.URL != "" {
+		fmt.Fprintf(&b, "Pull request: %s\n", pr.URL)
+	}
+	b.WriteString("\n")
+	for _, f := range findings {
+		fmt.Fprintf(&b, "- %s:%d [%s] %s\n", f.Path, f.Line, f.Rule, f.Message)
+	}
+	return b.String()
+}
@@ -0,0 +1,199 @@
+package tickets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"prmate/internal/config"
+	"prmate/internal/review"
+)
+
+var findings = []review.OpenFinding{
+	{Path: "db/query.go", Line: 12, Rule: "security/no-sql-concat", Severity: "error", Message: "SQL built from request input"},
+	{Path: "api/handler.go", Line: 40, Rule: "architecture/no-db-in-handlers", Severity: "error", Message: "handler queries the database"},
+}
+
+type fakeTracker struct {
+	project string
+	ticket  Ticket
+	key     string
+	comment string
+}
+
+func (f *fakeTracker) Create(ctx context.Context, project string, t Ticket) (Ref, error) {
+	f.project, f.ticket = project, t
+	return Ref{Key: project + "-1", URL: "https://tracker/" + project + "-1"}, nil
+}
+
+func (f *fakeTracker) Comment(ctx context.Context, key, body string) (Ref, error) {
+	f.key, f.comment = key, body
+	return Ref{Key: key}, nil
+}
+
+func TestFiler_File(t *testing.T) {
+	routes := []config.TicketRoute{
+		{Repos: []string{"acme/payments"}, Tracker: "jira", Project: "SEC", IssueType: "Bug", Rules: []string{"security"}},
+		{Repos: []string{"acme/*"}, Tracker: "linear", Project: "ENG"},
+	}
+
+	tests := []struct {
+		name        string
+		pr          PullRequest
+		wantTracker string
+		wantCreated bool
+		wantKey     string
+		wantCount   int // findings in the description
+	}{
+		{name: "first matching route wins and filters rules", pr: PullRequest{Owner: "acme", Repo: "payments", Number: 5, Title: "Add refunds"},
+			wantTracker: "jira", wantCreated: true, wantKey: "SEC-1", wantCount: 1},
+		{name: "wildcard route", pr: PullRequest{Owner: "Acme", Repo: "web", Number: 6, Title: "New page"},
+			wantTracker: "linear", wantCreated: true, wantKey: "ENG-1", wantCount: 2},
+		{name: "links issue named in branch", pr: PullRequest{Owner: "acme", Repo: "web", Number: 7, Title: "Fix", Branch: "eng-42-fix-login"},
+			wantTracker: "linear", wantKey: "ENG-42", wantCount: 2},
+		{name: "no route", pr: PullRequest{Owner: "other", Repo: "web", Number: 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trackers := map[string]*fakeTracker{"jira": {}, "linear": {}}
+			f := NewFiler(routes, map[string]Tracker{"jira": trackers["jira"], "linear": trackers["linear"]})
+
+			ref, err := f.File(context.Background(), tt.pr, findings)
+			if err != nil {
+				t.Fatalf("File() error = %v", err)
+			}
+			if tt.wantTracker == "" {
+				if ref != nil {
+					t.Fatalf("File() = %+v, want nil", ref)
+				}
+				return
+			}
+			if ref == nil || ref.Key != tt.wantKey || ref.Created != tt.wantCreated {
+				t.Fatalf("File() = %+v, want key %s created %v", ref, tt.wantKey, tt.wantCreated)
+			}
+
+			tr := trackers[tt.wantTracker]
+			body := tr.ticket.Description
+			if !tt.wantCreated {
+				body = tr.comment
+			}
+			if got := strings.Count(body, "\n- "); got != tt.wantCount {
+				t.Errorf("description lists %d findings, want %d:\n%s", got, tt.wantCount, body)
+			}
+			if tt.wantCreated && tr.ticket.Labels[0] != "prmate" {
+				t.Errorf("labels = %v, want prmate first", tr.ticket.Labels)
+			}
+		})
+	}
+}
+
+func TestFiler_File_NoSelectedFindings(t *testing.T) {
+	tr := &fakeTracker{}
+	f := NewFiler([]config.TicketRoute{{Tracker: "jira", Project: "SEC", Rules: []string{"secrets"}}}, map[string]Tracker{"jira": tr})
+
+	ref, err := f.File(context.Background(), PullRequest{Owner: "acme", Repo: "api", Number: 1}, findings)
+	if err != nil || ref != nil {
+		t.Fatalf("File() = %+v, %v; want nil, nil", ref, err)
+	}
+	if tr.project != "" {
+		t.Error("tracker should not be called")
+	}
+}
+
+func TestJira_Create(t *testing.T) {
+	var got struct {
+		Fields struct {
+			Project   struct{ Key string }  `json:"project"`
+			Summary   string                `json:"summary"`
+			IssueType struct{ Name string } `json:"issuetype"`
+			Labels    []string              `json:"labels"`
+		} `json:"fields"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if user, pass, _ := r.BasicAuth(); user != "bot@acme.io" || pass != "token" {
+			t.Errorf("basic auth = %s:%s", user, pass)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"10001","key":"SEC-12"}`))
+	}))
+	defer srv.Close()
+
+	ref, err := NewJira(srv.URL+"/", "bot@acme.io", "token").Create(context.Background(), "SEC", Ticket{Title: "t", IssueType: "Bug", Labels: []string{"prmate"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if ref.Key != "SEC-12" || ref.URL != srv.URL+"/browse/SEC-12" {
+		t.Errorf("ref = %+v", ref)
+	}
+	if got.Fields.Project.Key != "SEC" || got.Fields.IssueType.Name != "Bug" || len(got.Fields.Labels) != 1 {
+		t.Errorf("fields = %+v", got.Fields)
+	}
+}
+
+func TestJira_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errors":{"project":"project is required"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewJira(srv.URL, "a", "b").Comment(context.Background(), "SEC-1", "body")
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Comment() error = %v, want status 400", err)
+	}
+}
+
+func TestLinear_Create(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_key" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		calls++
+		switch {
+		case strings.Contains(req.Query, "teams"):
+			_, _ = w.Write([]byte(`{"data":{"teams":{"nodes":[{"id":"team-1"}]}}}`))
+		case strings.Contains(req.Query, "issueCreate"):
+			if input := req.Variables["input"].(map[string]any); input["teamId"] != "team-1" {
+				t.Errorf("teamId = %v", input["teamId"])
+			}
+			_, _ = w.Write([]byte(`{"data":{"issueCreate":{"success":true,"issue":{"identifier":"ENG-9","url":"https://linear.app/acme/issue/ENG-9"}}}}`))
+		}
+	}))
+	defer srv.Close()
+
+	l := NewLinear("lin_api_key")
+	l.endpoint = srv.URL
+	ref, err := l.Create(context.Background(), "ENG", Ticket{Title: "t", Description: "d"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if calls != 2 || ref.Key != "ENG-9" || ref.URL != "https://linear.app/acme/issue/ENG-9" {
+		t.Errorf("calls = %d, ref = %+v", calls, ref)
+	}
+}
+
+func TestLinear_GraphQLError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors":[{"message":"Entity not found"}]}`))
+	}))
+	defer srv.Close()
+
+	l := NewLinear("k")
+	l.endpoint = srv.URL
+	if _, err := l.Comment(context.Background(), "ENG-404", "body"); err == nil || !strings.Contains(err.Error(), "Entity not found") {
+		t.Errorf("Comment() error = %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Package tracing wires OpenTelemetry tracing for the review pipeline.
+// When no OTLP endpoint is configured the global no-op tracer is kept, so
+// instrumented code pays almost nothing.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "prmate"
+
+// Config holds tracing exporter settings
+type Config struct {
+	Endpoint    string // OTLP/HTTP endpoint, e.g. http://otel-collector:4318; empty disables export
+	ServiceName string
+}
+
+// Setup installs a global tracer provider exporting via OTLP/HTTP and
+// returns a shutdown function that flushes pending spans
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("create otlp exporter: %w", err)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = instrumentationName
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.AlwaysSample())),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+type attributesKey struct{}
+
+// WithAttributes returns ctx with attrs set on every span Start begins from
+// it, such as the delivery ID shared by the spans of a webhook delivery. An
+// attribute replaces one of the same key already in ctx.
+func WithAttributes(ctx context.Context, attrs ...attribute.KeyValue) context.Context {
+	inherited := slices.DeleteFunc(slices.Clone(attributes(ctx)), func(kv attribute.KeyValue) bool {
+		return slices.ContainsFunc(attrs, func(a attribute.KeyValue) bool { return a.Key == kv.Key })
+	})
+	return context.WithValue(ctx, attributesKey{}, append(inherited, attrs...))
+}
+
+func attributes(ctx context.Context) []attribute.KeyValue {
+	attrs, _ := ctx.Value(attributesKey{}).([]attribute.KeyValue)
+	return attrs
+}
+
+// Start begins a span named name as a child of any span in ctx, with the
+// attributes of ctx and then attrs
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if inherited := attributes(ctx); len(inherited) > 0 {
+		attrs = append(slices.Clip(inherited), attrs...)
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// Detach returns a background context that carries ctx's span as parent,
+// and its span attributes, but none of its cancellation, for work that
+// outlives the originating request
+func Detach(ctx context.Context) context.Context {
+	detached := trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+	if attrs := attributes(ctx); len(attrs) > 0 {
+		detached = context.WithValue(detached, attributesKey{}, attrs)
+	}
+	return detached
+}
+
+// End records err on span (if any) and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup_NoEndpoint(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+}
+
+func TestDetach_KeepsSpanDropsCancellation(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	parent, cancel := context.WithCancel(trace.ContextWithSpanContext(context.Background(), sc))
+	cancel()
+
+	detached := Detach(parent)
+
+	if detached.Err() != nil {
+		t.Errorf("detached context should not be cancelled, got %v", detached.Err())
+	}
+	if got := trace.SpanContextFromContext(detached); !got.Equal(sc) {
+		t.Errorf("span context = %v, want %v", got, sc)
+	}
+}
+
+func TestWithAttributes_SetOnEverySpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx := WithAttributes(context.Background(), attribute.String("github.delivery_id", "d-1"), attribute.String("github.event", "push"))
+	ctx = WithAttributes(ctx, attribute.String("github.event", "pull_request"))
+	ctx, parent := Start(ctx, "webhook.process")
+	_, child := Start(Detach(ctx), "review.pr", attribute.Int("github.pr", 7))
+	child.End()
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended %d spans, want 2", len(spans))
+	}
+	for _, span := range spans {
+		got := make(map[attribute.Key]string)
+		for _, kv := range span.Attributes() {
+			got[kv.Key] = kv.Value.Emit()
+		}
+		if got["github.delivery_id"] != "d-1" || got["github.event"] != "pull_request" {
+			t.Errorf("span %s attributes = %v, want the delivery ID and the latest event", span.Name(), got)
+		}
+		if len(span.Attributes()) != len(got) {
+			t.Errorf("span %s repeats an attribute: %v", span.Name(), span.Attributes())
+		}
+	}
+	if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Error("detached span should be a child of the span it was detached from")
+	}
+}
@@ -0,0 +1,61 @@
+package triage
+
+import (
+	"strings"
+
+	"prmate/internal/checks"
+)
+
+// codeownersPaths are where GitHub looks for a CODEOWNERS file, in order
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// ownerRule is one line of a CODEOWNERS file
+type ownerRule struct {
+	pattern string
+	owners  []string
+}
+
+// parseCodeowners reads the rules of a CODEOWNERS file, in file order
+func parseCodeowners(content string) []ownerRule {
+	var rules []ownerRule
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		rules = append(rules, ownerRule{pattern: fields[0], owners: fields[1:]})
+	}
+	return rules
+}
+
+// ownersOf returns the owners of file: those of the last rule matching it,
+// as GitHub resolves them
+func ownersOf(rules []ownerRule, file string) []string {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if codeownersMatch(rules[i].pattern, file) {
+			return rules[i].owners
+		}
+	}
+	return nil
+}
+
+// codeownersMatch reports whether a CODEOWNERS pattern, which follows
+// gitignore rules, matches file
+func codeownersMatch(pattern, file string) bool {
+	anchored, dir := strings.HasPrefix(pattern, "/"), strings.HasSuffix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+	// A pattern with no inner slash matches at any depth
+	if !anchored && !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	// A pattern naming a directory matches every file under it; one ending
+	// in / matches nothing else
+	if checks.Match(pattern+"/*/**", file) {
+		return true
+	}
+	return !dir && checks.Match(pattern, file)
+}
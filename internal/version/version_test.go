@@ -0,0 +1,32 @@
+package version
+
+import "testing"
+
+func TestGet_Ldflags(t *testing.T) {
+	oldVersion, oldCommit, oldDate := Version, Commit, BuildDate
+	defer func() { Version, Commit, BuildDate = oldVersion, oldCommit, oldDate }()
+
+	Version, Commit, BuildDate = "v1.2.3", "0123456789abcdef", "2026-01-02T03:04:05Z"
+
+	info := Get()
+	if info.Version != "v1.2.3" || info.Commit != "0123456789abcdef" || info.BuildDate != "2026-01-02T03:04:05Z" {
+		t.Errorf("Get() = %+v, want ldflags values", info)
+	}
+	if got := info.String(); got != "v1.2.3 (0123456)" {
+		t.Errorf("String() = %q, want %q", got, "v1.2.3 (0123456)")
+	}
+	if info.GoVersion == "" {
+		t.Error("GoVersion should be set")
+	}
+}
+
+func TestGet_Defaults(t *testing.T) {
+	oldCommit, oldDate := Commit, BuildDate
+	defer func() { Commit, BuildDate = oldCommit, oldDate }()
+	Commit, BuildDate = "", ""
+
+	info := Get()
+	if info.Commit == "" || info.BuildDate == "" {
+		t.Errorf("Get() = %+v, want non-empty fallbacks", info)
+	}
+}
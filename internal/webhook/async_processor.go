@@ -4,7 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"prmate/internal/correlation"
+	"prmate/internal/errclass"
+	"prmate/internal/errreport"
+	"prmate/internal/logging"
+	"prmate/internal/tracing"
 )
 
 type AsyncConfig struct {
@@ -12,18 +23,48 @@ type AsyncConfig struct {
 	Workers   int
 }
 
+// ErrShuttingDown is returned by Enqueue once Stop has been called
+var ErrShuttingDown = errors.New("webhook processor shutting down")
+
+// maxRetries bounds how often a delivery that failed with a retryable
+// error, such as a rate limit or an open circuit breaker, is queued again
+const maxRetries = 3
+
 type AsyncProcessor struct {
 	processor *Processor
 	jobs      chan job
 
+	mu     sync.RWMutex // guards closed and closing jobs against concurrent sends
+	closed bool
+
+	ctx    context.Context // cancelled when Stop gives up on draining
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
+
+	processed atomic.Int64
+	failed    atomic.Int64
+	rejected  atomic.Int64
+	retried   atomic.Int64
+	crashed   atomic.Int64
+}
+
+// Counts are the deliveries an AsyncProcessor has handled since it started
+type Counts struct {
+	Processed int64 `json:"processed"` // finished, successfully or not
+	Failed    int64 `json:"failed"`
+	Rejected  int64 `json:"rejected"` // refused because the queue was full
+	Retried   int64 `json:"retried"`  // queued again after failing with a retryable error
+	Crashed   int64 `json:"crashed"`  // panicked; also counted as failed
 }
 
 type job struct {
+	baseCtx    context.Context // carries the enqueuing span and correlation ID, not its cancellation
 	eventType  string
 	payload    []byte
 	deliveryID string
+	attempts   int       // retries so far
+	reviewOnly bool      // a retry after the review alone failed; see withReviewOnly
+	queuedAt   time.Time // when the job last entered the queue
 }
 
 func NewAsyncProcessor(processor *Processor, cfg AsyncConfig) *AsyncProcessor {
@@ -39,35 +80,70 @@ func NewAsyncProcessor(processor *Processor, cfg AsyncConfig) *AsyncProcessor {
 	p := &AsyncProcessor{
 		processor: processor,
 		jobs:      make(chan job, cfg.QueueSize),
+		ctx:       ctx,
 		cancel:    cancel,
 	}
 
 	for i := 0; i < cfg.Workers; i++ {
 		p.wg.Add(1)
-		go p.worker(ctx)
+		go p.worker()
 	}
 
 	return p
 }
 
 func (p *AsyncProcessor) Enqueue(ctx context.Context, eventType string, payload []byte, deliveryID string) error {
-	_ = ctx
 	if p.processor == nil {
 		return errors.New("webhook processor is nil")
 	}
 
-	j := job{eventType: eventType, payload: append([]byte(nil), payload...), deliveryID: deliveryID}
+	baseCtx := correlation.WithID(tracing.Detach(ctx), correlation.FromContext(ctx))
+	j := job{baseCtx: baseCtx, eventType: eventType, payload: append([]byte(nil), payload...), deliveryID: deliveryID, queuedAt: time.Now()}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrShuttingDown
+	}
 
 	select {
 	case p.jobs <- j:
 		return nil
 	default:
-		return errors.New("webhook queue full")
+		p.rejected.Add(1)
+		return errclass.Wrap(errors.New("webhook queue full"), errclass.ErrQueueFull)
+	}
+}
+
+// Counts returns the deliveries processed, failed and rejected so far
+func (p *AsyncProcessor) Counts() Counts {
+	return Counts{Processed: p.processed.Load(), Failed: p.failed.Load(), Rejected: p.rejected.Load(), Retried: p.retried.Load(), Crashed: p.crashed.Load()}
+}
+
+// QueueStats returns the number of queued jobs and the queue capacity
+func (p *AsyncProcessor) QueueStats() (depth, capacity int) {
+	return len(p.jobs), cap(p.jobs)
+}
+
+// CheckCapacity reports an error when the queue cannot accept more jobs
+func (p *AsyncProcessor) CheckCapacity(ctx context.Context) error {
+	_ = ctx
+	depth, capacity := p.QueueStats()
+	if depth >= capacity {
+		return errclass.Wrap(fmt.Errorf("webhook queue full (%d/%d)", depth, capacity), errclass.ErrQueueFull)
 	}
+	return nil
 }
 
+// Stop refuses new jobs, lets workers drain the queue, and cancels whatever
+// is still running once ctx expires
 func (p *AsyncProcessor) Stop(ctx context.Context) error {
-	p.cancel()
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
 
 	done := make(chan struct{})
 	go func() {
@@ -77,20 +153,93 @@ func (p *AsyncProcessor) Stop(ctx context.Context) error {
 
 	select {
 	case <-ctx.Done():
-		return fmt.Errorf("stop webhook workers: %w", ctx.Err())
+		p.cancel()
+		return fmt.Errorf("drain webhook queue (%d jobs left): %w", len(p.jobs), ctx.Err())
 	case <-done:
+		p.cancel()
 		return nil
 	}
 }
 
-func (p *AsyncProcessor) worker(ctx context.Context) {
+func (p *AsyncProcessor) worker() {
 	defer p.wg.Done()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case j := <-p.jobs:
-			_ = p.processor.Process(context.Background(), j.eventType, j.payload, j.deliveryID)
+	for j := range p.jobs {
+		if p.ctx.Err() != nil {
+			continue // draining was abandoned; discard the rest
 		}
+		p.process(j)
+	}
+}
+
+// process runs one job. Failures are reported by Processor.Process; a panic
+// is recovered and reported here, failing only its own delivery so the worker
+// carries on with the next one.
+func (p *AsyncProcessor) process(j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.crashed.Add(1)
+			p.failed.Add(1)
+			ctx := logging.With(j.baseCtx, "delivery_id", j.deliveryID, "event", j.eventType)
+			logging.FromContext(ctx).Error("webhook processing panicked", "panic", r, "stack", string(debug.Stack()))
+			errreport.CapturePanic(ctx, r)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(j.baseCtx)
+	defer cancel()
+	if j.reviewOnly {
+		ctx = withReviewOnly(ctx)
+	}
+	stop := context.AfterFunc(p.ctx, cancel)
+	defer stop()
+
+	// The time spent waiting for a worker shows on the job's span
+	var err error
+	ctx, span := tracing.Start(ctx, "webhook.job",
+		attribute.Int64("webhook.queue_wait_ms", time.Since(j.queuedAt).Milliseconds()),
+		attribute.Int("webhook.attempt", j.attempts+1),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	defer p.processed.Add(1)
+	err = p.processor.Process(ctx, j.eventType, j.payload, j.deliveryID)
+	if err == nil {
+		return
+	}
+	logger := logging.FromContext(j.baseCtx)
+	if delay, ok := errclass.RetryAfter(err); ok && j.attempts < maxRetries {
+		logger.Warn("webhook processing failed with a retryable error, retrying", "delivery_id", j.deliveryID, "event", j.eventType, "retry_in", delay, "error", err)
+		// Only the review is repeated when the rest of the delivery succeeded
+		var reviewErr *reviewRetryError
+		j.reviewOnly = j.reviewOnly || errors.As(err, &reviewErr)
+		p.retry(j, delay)
+		return
 	}
+	p.failed.Add(1)
+	logger.Error("webhook processing failed", "delivery_id", j.deliveryID, "event", j.eventType, "error", err)
+}
+
+// retry queues j again after delay, unless the processor has stopped by then
+// or the queue is full
+func (p *AsyncProcessor) retry(j job, delay time.Duration) {
+	j.attempts++
+	p.retried.Add(1)
+	time.AfterFunc(delay, func() {
+		j.queuedAt = time.Now()
+		p.mu.RLock()
+		defer p.mu.RUnlock()
+		logger := logging.FromContext(j.baseCtx)
+		if p.closed {
+			p.failed.Add(1)
+			logger.Error("webhook retry dropped: processor stopped", "delivery_id", j.deliveryID, "event", j.eventType)
+			return
+		}
+		select {
+		case p.jobs <- j:
+		default:
+			p.failed.Add(1)
+			p.rejected.Add(1)
+			logger.Error("webhook retry dropped: queue full", "delivery_id", j.deliveryID, "event", j.eventType)
+		}
+	})
 }
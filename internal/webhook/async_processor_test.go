@@ -0,0 +1,311 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"prmate/internal/breaker"
+	"prmate/internal/errclass"
+	ghclient "prmate/internal/github"
+	"prmate/internal/review"
+	"prmate/internal/tracing"
+)
+
+func TestAsyncProcessor_EnqueueAfterStop(t *testing.T) {
+	p := NewAsyncProcessor(NewProcessor(&MockPRWorkspace{}, &MockScanService{}, nil, nil), AsyncConfig{})
+
+	if err := p.Enqueue(context.Background(), "ping", []byte(`{}`), "d1"); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if err := p.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if depth, _ := p.QueueStats(); depth != 0 {
+		t.Errorf("depth after Stop = %d, want 0", depth)
+	}
+
+	if err := p.Enqueue(context.Background(), "ping", []byte(`{}`), "d2"); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Enqueue() after Stop error = %v, want ErrShuttingDown", err)
+	}
+	// Stopping twice is harmless
+	if err := p.Stop(context.Background()); err != nil {
+		t.Errorf("second Stop() error = %v", err)
+	}
+}
+
+func TestAsyncProcessor_Counts(t *testing.T) {
+	p := NewAsyncProcessor(NewProcessor(&MockPRWorkspace{}, &MockScanService{}, nil, nil), AsyncConfig{})
+
+	_ = p.Enqueue(context.Background(), "ping", []byte(`{}`), "d1")
+	_ = p.Enqueue(context.Background(), "pull_request", []byte(`not json`), "d2")
+	if err := p.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if got := p.Counts(); got != (Counts{Processed: 2, Failed: 1}) {
+		t.Errorf("Counts() = %+v, want 2 processed and 1 failed", got)
+	}
+}
+
+// flakyWorkspace fails its first calls as if GitHub's breaker were open
+type flakyWorkspace struct {
+	MockPRWorkspace
+	failures atomic.Int32
+}
+
+func (f *flakyWorkspace) EnsurePRDir(ctx context.Context, repoFullName string, prNumber int) (string, error) {
+	if f.failures.Add(-1) >= 0 {
+		return "", &breaker.OpenError{Name: "github", RetryAt: time.Now()}
+	}
+	return "/tmp/pr", nil
+}
+
+func TestAsyncProcessor_RetriesOpenBreaker(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int32
+		want     Counts
+	}{
+		{name: "recovers", failures: 1, want: Counts{Processed: 2, Retried: 1}},
+		{name: "gives up", failures: 10, want: Counts{Processed: maxRetries + 1, Failed: 1, Retried: maxRetries}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := &flakyWorkspace{}
+			ws.failures.Store(tt.failures)
+			p := NewAsyncProcessor(NewProcessor(ws, nil, nil, nil), AsyncConfig{})
+
+			payload := []byte(`{"action":"opened","number":1,"pull_request":{"number":1},"repository":{"full_name":"o/r"}}`)
+			if err := p.Enqueue(context.Background(), "pull_request", payload, "d1"); err != nil {
+				t.Fatalf("Enqueue() error = %v", err)
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for p.Counts().Processed < tt.want.Processed && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if err := p.Stop(context.Background()); err != nil {
+				t.Fatalf("Stop() error = %v", err)
+			}
+			if got := p.Counts(); got != tt.want {
+				t.Errorf("Counts() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// flakyReviews fails its first reviews as if the LLM's breaker were open
+type flakyReviews struct {
+	MockReviewService
+	failures atomic.Int32
+	reviews  atomic.Int32
+}
+
+func (f *flakyReviews) ReviewPR(ctx context.Context, req review.ReviewRequest) (*review.ReviewResult, error) {
+	f.reviews.Add(1)
+	if f.failures.Add(-1) >= 0 {
+		return nil, &breaker.OpenError{Name: "llm", RetryAt: time.Now()}
+	}
+	return f.MockReviewService.ReviewPR(ctx, req)
+}
+
+// countingScans counts the scan directive checks of the pull requests
+type countingScans struct {
+	MockScanService
+	checks atomic.Int32
+}
+
+func (c *countingScans) CheckForScanDirective(ctx context.Context, owner, repo, branch string) (bool, []string, error) {
+	c.checks.Add(1)
+	return false, nil, nil
+}
+
+func TestAsyncProcessor_RetriesOnlyTheReview(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/pulls/1") {
+			w.Write([]byte(`{"number":1,"head":{"ref":"feature","sha":"head123"}}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reviews := &flakyReviews{MockReviewService: MockReviewService{hasPRMate: true}}
+	reviews.failures.Store(1)
+	scans := &countingScans{}
+	conflicts := &fakeConflictChecker{}
+	processor := NewProcessor(&MockPRWorkspace{}, scans, reviews, gh)
+	processor.SetConflictChecker(conflicts)
+	p := NewAsyncProcessor(processor, AsyncConfig{})
+
+	payload := []byte(`{"action":"opened","number":1,"pull_request":{"number":1,"head":{"ref":"feature"}},"repository":{"full_name":"o/r"}}`)
+	if err := p.Enqueue(context.Background(), "pull_request", payload, "d1"); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Counts().Processed < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := p.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if got := p.Counts(); got != (Counts{Processed: 2, Retried: 1}) {
+		t.Errorf("Counts() = %+v, want 2 processed and 1 retried", got)
+	}
+	if got := reviews.reviews.Load(); got != 2 {
+		t.Errorf("reviews = %d, want 2", got)
+	}
+	// The scan and the conflict check ran on the failed attempt only
+	if got := scans.checks.Load(); got != 1 {
+		t.Errorf("scan directive checks = %d, want 1", got)
+	}
+	if fmt.Sprint(conflicts.checked) != "[1]" {
+		t.Errorf("conflict checks = %v, want [1]", conflicts.checked)
+	}
+}
+
+func TestAsyncProcessor_RetriedReviewCommandAcknowledgesOnce(t *testing.T) {
+	var mu sync.Mutex
+	var replies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/pulls/42"):
+			w.Write([]byte(`{"number":42,"user":{"login":"octocat"},"head":{"sha":"head123","ref":"feature","repo":{"full_name":"owner/repo"}},"base":{"ref":"main","repo":{"full_name":"owner/repo"}}}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/issues/42/comments"):
+			var c struct {
+				Body string `json:"body"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&c)
+			mu.Lock()
+			replies = append(replies, c.Body)
+			mu.Unlock()
+			w.Write([]byte(`{"id":1}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reviews := &flakyReviews{MockReviewService: MockReviewService{hasPRMate: true}}
+	reviews.failures.Store(1)
+	p := NewAsyncProcessor(NewProcessor(&MockPRWorkspace{}, &MockScanService{}, reviews, gh), AsyncConfig{})
+
+	payload, _ := json.Marshal(map[string]any{
+		"action":     "created",
+		"issue":      map[string]any{"number": 42, "pull_request": map[string]any{}},
+		"comment":    map[string]any{"body": "@prmate review", "user": map[string]any{"login": "octocat"}},
+		"repository": map[string]any{"full_name": "owner/repo"},
+	})
+	if err := p.Enqueue(context.Background(), "issue_comment", payload, "d1"); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Counts().Processed < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := p.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if got := reviews.reviews.Load(); got != 2 {
+		t.Errorf("reviews = %d, want 2", got)
+	}
+	if len(replies) != 2 || !strings.Contains(replies[0], "PRMate is reviewing this pull request") || !strings.Contains(replies[1], "found no issues") {
+		t.Errorf("replies = %q, want one acknowledgement and the result", replies)
+	}
+}
+
+func TestReplied_NotRetryable(t *testing.T) {
+	err := fmt.Errorf("explain code: %w", replied(&breaker.OpenError{Name: "llm", RetryAt: time.Now()}))
+	if errclass.Retryable(err) {
+		t.Errorf("Retryable(%v) = true, want a failure already replied to left alone", err)
+	}
+}
+
+// panickingWorkspace panics on every pull request, like a handler bug would
+type panickingWorkspace struct {
+	MockPRWorkspace
+}
+
+func (*panickingWorkspace) EnsurePRDir(ctx context.Context, repoFullName string, prNumber int) (string, error) {
+	var sha string
+	return sha[:7], nil
+}
+
+func TestAsyncProcessor_RecoversPanic(t *testing.T) {
+	p := NewAsyncProcessor(NewProcessor(&panickingWorkspace{}, &MockScanService{}, nil, nil), AsyncConfig{Workers: 1})
+
+	pr := []byte(`{"action":"opened","pull_request":{"number":1},"repository":{"full_name":"o/r"}}`)
+	_ = p.Enqueue(context.Background(), "pull_request", pr, "d1")
+	_ = p.Enqueue(context.Background(), "pull_request", pr, "d2")
+	_ = p.Enqueue(context.Background(), "ping", []byte(`{}`), "d3")
+	if err := p.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	// The one worker survived both panics and handled the ping after them
+	if got := p.Counts(); got != (Counts{Processed: 3, Failed: 2, Crashed: 2}) {
+		t.Errorf("Counts() = %+v, want 3 processed, 2 failed and 2 crashed", got)
+	}
+}
+
+func TestAsyncProcessor_TracesDelivery(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	p := NewAsyncProcessor(NewProcessor(&MockPRWorkspace{}, &MockScanService{}, nil, nil), AsyncConfig{})
+	ctx, request := tracing.Start(context.Background(), "HTTP POST /webhook")
+	if err := p.Enqueue(ctx, "ping", []byte(`{}`), "d1"); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	request.End()
+	if err := p.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	job, process := spans["webhook.job"], spans["webhook.process"]
+	if job == nil || process == nil {
+		t.Fatalf("spans = %v, want webhook.job and webhook.process", spans)
+	}
+	if job.Parent().SpanID() != request.SpanContext().SpanID() || process.Parent().SpanID() != job.SpanContext().SpanID() {
+		t.Error("webhook.process should run in webhook.job, in the trace of the request")
+	}
+	var waited, delivery bool
+	for _, kv := range job.Attributes() {
+		waited = waited || kv.Key == "webhook.queue_wait_ms"
+	}
+	for _, kv := range process.Attributes() {
+		delivery = delivery || kv == attribute.String("github.delivery_id", "d1")
+	}
+	if !waited || !delivery {
+		t.Errorf("job attributes = %v, process attributes = %v; want the queue wait and the delivery ID", job.Attributes(), process.Attributes())
+	}
+}
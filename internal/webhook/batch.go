@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	ghclient "prmate/internal/github"
+)
+
+// Reasons a batch review skips an open pull request
+const (
+	SkipReviewed     = "already reviewed at head"
+	SkipNoPRMateFile = "no .prmate.md"
+)
+
+// BatchCandidate is an open pull request considered by a batch review
+type BatchCandidate struct {
+	PRNumber int    `json:"pr"`
+	Title    string `json:"title"`
+	HeadSHA  string `json:"head_sha"`
+	Skip     string `json:"skip,omitempty"` // empty when the PR should be reviewed
+}
+
+// PlanBatchReview lists the open pull requests of owner/repo and marks the
+// ones that need no review: those already reviewed at their current head
+// and those without a .prmate.md. It is used when first enabling PRMate on
+// an active repository.
+func (p *Processor) PlanBatchReview(ctx context.Context, owner, repo string) ([]BatchCandidate, error) {
+	if p.reviewService == nil || p.githubClient == nil {
+		return nil, fmt.Errorf("review service not configured")
+	}
+
+	prs, err := p.githubClient.ListOpenPullRequests(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return planBatch(prs,
+		func(pr ghclient.PullRequest) (string, error) {
+			return p.reviewService.LastReviewedSHA(ctx, owner, repo, pr.Number)
+		},
+		func(pr ghclient.PullRequest) bool {
+			return p.reviewService.HasPRMateFile(ctx, owner, repo, pr.ConfigRef())
+		},
+	)
+}
+
+// planBatch decides which of prs need a review
+func planBatch(prs []ghclient.PullRequest, lastReviewed func(ghclient.PullRequest) (string, error), hasPRMateFile func(ghclient.PullRequest) bool) ([]BatchCandidate, error) {
+	candidates := make([]BatchCandidate, 0, len(prs))
+	for _, pr := range prs {
+		c := BatchCandidate{PRNumber: pr.Number, Title: pr.Title, HeadSHA: pr.HeadSHA}
+
+		reviewed, err := lastReviewed(pr)
+		if err != nil {
+			return nil, fmt.Errorf("check last review of #%d: %w", pr.Number, err)
+		}
+		switch {
+		case reviewed != "" && reviewed == pr.HeadSHA:
+			c.Skip = SkipReviewed
+		case !hasPRMateFile(pr):
+			c.Skip = SkipNoPRMateFile
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+func TestPlanBatch(t *testing.T) {
+	prs := []ghclient.PullRequest{
+		{Number: 1, HeadSHA: "aaa", HeadRef: "feature-a"},
+		{Number: 2, HeadSHA: "bbb", HeadRef: "feature-b"},
+		{Number: 3, HeadSHA: "ccc", HeadRef: "no-rules"},
+		{Number: 4, HeadSHA: "ddd", HeadRef: "feature-d"},
+	}
+	reviewed := map[int]string{1: "aaa", 2: "old"}
+
+	got, err := planBatch(prs,
+		func(pr ghclient.PullRequest) (string, error) { return reviewed[pr.Number], nil },
+		func(pr ghclient.PullRequest) bool { return pr.HeadRef != "no-rules" },
+	)
+	if err != nil {
+		t.Fatalf("planBatch() error = %v", err)
+	}
+
+	want := map[int]string{1: SkipReviewed, 2: "", 3: SkipNoPRMateFile, 4: ""}
+	if len(got) != len(want) {
+		t.Fatalf("got %d candidates, want %d", len(got), len(want))
+	}
+	for _, c := range got {
+		if c.Skip != want[c.PRNumber] {
+			t.Errorf("#%d skip = %q, want %q", c.PRNumber, c.Skip, want[c.PRNumber])
+		}
+	}
+}
+
+func TestPlanBatch_LookupError(t *testing.T) {
+	_, err := planBatch([]ghclient.PullRequest{{Number: 1}},
+		func(ghclient.PullRequest) (string, error) { return "", errors.New("rate limited") },
+		func(ghclient.PullRequest) bool { return true },
+	)
+	if err == nil {
+		t.Fatal("expected an error when the last review cannot be looked up")
+	}
+}
@@ -0,0 +1,206 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"prmate/internal/correlation"
+	"prmate/internal/logging"
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+// exemptionCheckInterval is how often expired exemptions are looked for
+const exemptionCheckInterval = time.Hour
+
+// exemptUsage is replied to malformed exempt commands
+const exemptUsage = "Usage: `@prmate exempt <rule> [path=<glob>] reason=\"...\" until=YYYY-MM-DD`"
+
+// exemptCommand matches an "@prmate exempt <rule> key=value..." line; a
+// rule with spaces is quoted
+var exemptCommand = regexp.MustCompile(`(?im)^\s*@prmate\s+exempt\s+("[^"\n]+"|\S+)(.*)$`)
+
+// exemptArg matches one key=value argument of an exempt command
+var exemptArg = regexp.MustCompile(`(\w+)=(?:"([^"]*)"|(\S+))`)
+
+// ExemptionStore persists rule exemptions; store.FileStore satisfies it
+type ExemptionStore interface {
+	SaveExemption(ctx context.Context, e store.Exemption) error
+	ListExemptions(ctx context.Context, instance string) ([]store.Exemption, error)
+}
+
+// SetExemptions enables the "@prmate exempt" comment command, keeping
+// exemptions in s and suppressing their rules in reviews until they expire
+func (p *Processor) SetExemptions(s ExemptionStore) {
+	p.exemptions = s
+}
+
+// handleExempt records an exemption from rule for a pull request, or for
+// the files matching a path= argument in every pull request, when user may
+// write to the repository
+func (p *Processor) handleExempt(ctx context.Context, owner, repo string, prNumber int, user, rule, args string) error {
+	logger := logging.FromContext(ctx)
+	reply := func(body string) error {
+		if err := p.githubClient.CreatePRComment(ctx, owner, repo, prNumber, body); err != nil {
+			return fmt.Errorf("post exempt comment: %w", err)
+		}
+		return nil
+	}
+
+	perm, err := p.githubClient.GetPermission(ctx, owner, repo, user)
+	if err != nil {
+		return fmt.Errorf("get permission of %s: %w", user, err)
+	}
+	if perm != "admin" && perm != "write" {
+		logger.Info("Ignoring exempt command from user without write access", "user", user, "permission", perm)
+		return reply(fmt.Sprintf("🔒 @%s, only collaborators with write access can exempt rules.", user))
+	}
+
+	now := p.now().UTC()
+	e, err := parseExemption(rule, args, now)
+	if err != nil {
+		return reply(fmt.Sprintf("PRMate could not record the exemption: %v.\n\n%s", err, exemptUsage))
+	}
+	e.Instance, e.Owner, e.Repo, e.PRNumber = p.instance, owner, repo, prNumber
+	e.GrantedBy, e.GrantedAt = user, now
+	e.ID = correlation.NewID(fmt.Sprintf("%s/%s#%d-%s-%s-%d", owner, repo, prNumber, e.Rule, e.Path, now.UnixNano()))
+
+	// Granting an exemption again changes its reason and expiry
+	existing, err := p.exemptions.ListExemptions(ctx, p.instance)
+	if err != nil {
+		return fmt.Errorf("list exemptions: %w", err)
+	}
+	for _, x := range existing {
+		if x.Owner == owner && x.Repo == repo && strings.EqualFold(x.Rule, e.Rule) && x.Path == e.Path &&
+			(e.Path != "" || x.PRNumber == prNumber) && x.Active(now) {
+			e.ID = x.ID
+			break
+		}
+	}
+	if err := p.exemptions.SaveExemption(ctx, e); err != nil {
+		return fmt.Errorf("save exemption: %w", err)
+	}
+
+	logger.Info("Exempted rule", "user", user, "rule", e.Rule, "path", e.Path, "until", e.Until)
+	return reply(fmt.Sprintf("🛡️ PRMate no longer reports `%s` %s until %s (UTC), as granted by @%s: %s\n\n"+
+		"The exemption applies from the next review. PRMate will remind this pull request when it expires.",
+		e.Rule, exemptionScope(e), e.Until.Format(time.DateOnly), user, e.Reason))
+}
+
+// parseExemption reads the rule and arguments of an exempt command. The
+// exemption expires at the start of its until date, UTC, which must lie
+// ahead of now.
+func parseExemption(rule, args string, now time.Time) (store.Exemption, error) {
+	e := store.Exemption{Rule: strings.Trim(rule, `"`)}
+	for _, m := range exemptArg.FindAllStringSubmatch(args, -1) {
+		value := m[2] + m[3]
+		switch strings.ToLower(m[1]) {
+		case "reason":
+			e.Reason = strings.TrimSpace(value)
+		case "until":
+			until, err := time.Parse(time.DateOnly, value)
+			if err != nil {
+				return e, fmt.Errorf("until %q must be a date such as 2025-09-01", value)
+			}
+			e.Until = until
+		case "path":
+			e.Path = path.Clean(strings.TrimPrefix(value, "./"))
+		default:
+			return e, fmt.Errorf("unknown argument %q", m[1])
+		}
+	}
+
+	switch {
+	case e.Reason == "":
+		return e, errors.New("a reason is required")
+	case e.Until.IsZero():
+		return e, errors.New("an until date is required")
+	case !e.Until.After(now):
+		return e, fmt.Errorf("until %s has already passed", e.Until.Format(time.DateOnly))
+	}
+	return e, nil
+}
+
+// exemptionScope says where an exemption applies
+func exemptionScope(e store.Exemption) string {
+	if e.Path == "" {
+		return "in this pull request"
+	}
+	return fmt.Sprintf("in `%s` in any pull request of this repository", e.Path)
+}
+
+// applyExemptions has req suppress the rules exempted for its pull request.
+// Failures are logged, and the review then reports every finding.
+func (p *Processor) applyExemptions(ctx context.Context, req *review.ReviewRequest) {
+	if p.exemptions == nil {
+		return
+	}
+	exemptions, err := p.exemptions.ListExemptions(ctx, p.instance)
+	if err != nil {
+		logging.FromContext(ctx).Warn("could not list exemptions", "error", err)
+		return
+	}
+	now := p.now()
+	for _, e := range exemptions {
+		if e.Owner == req.Owner && e.Repo == req.Repo && e.Covers(req.PRNumber) && e.Active(now) {
+			req.Exemptions = append(req.Exemptions, review.Exemption{Rule: e.Rule, Path: e.Path})
+		}
+	}
+}
+
+// StartExemptionReminders reminds the pull requests exemptions were granted
+// on once they expire, checking every hour until ctx is cancelled
+func (p *Processor) StartExemptionReminders(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(exemptionCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := p.RemindExpiredExemptions(ctx); err != nil {
+				slog.Warn("failed to remind expired exemptions", "error", err)
+			}
+		}
+	}()
+}
+
+// RemindExpiredExemptions comments on the pull request of every expired
+// exemption not yet reminded about, mentioning who granted it
+func (p *Processor) RemindExpiredExemptions(ctx context.Context) error {
+	if p.exemptions == nil || p.githubClient == nil {
+		return nil
+	}
+	exemptions, err := p.exemptions.ListExemptions(ctx, p.instance)
+	if err != nil {
+		return fmt.Errorf("list exemptions: %w", err)
+	}
+
+	now := p.now()
+	var errs []error
+	for _, e := range exemptions {
+		if e.Reminded || e.Active(now) {
+			continue
+		}
+		body := fmt.Sprintf("⏰ @%s, the exemption from `%s` %s expired on %s (UTC). PRMate reports the rule again from the next review.\n\n"+
+			"It was granted on %s: %s",
+			e.GrantedBy, e.Rule, exemptionScope(e), e.Until.Format(time.DateOnly), e.GrantedAt.Format(time.DateOnly), e.Reason)
+		if err := p.githubClient.CreatePRComment(ctx, e.Owner, e.Repo, e.PRNumber, body); err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s#%d: %w", e.Owner, e.Repo, e.PRNumber, err))
+			continue
+		}
+		e.Reminded = true
+		if err := p.exemptions.SaveExemption(ctx, e); err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s#%d: %w", e.Owner, e.Repo, e.PRNumber, err))
+		}
+	}
+	return errors.Join(errs...)
+}
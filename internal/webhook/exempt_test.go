@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+func TestParseExemption(t *testing.T) {
+	now := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		rule    string
+		args    string
+		want    store.Exemption
+		wantErr string
+	}{
+		{name: "pull request", rule: "no-println", args: ` reason="Debug output until the logger lands" until=2025-09-01`,
+			want: store.Exemption{Rule: "no-println", Reason: "Debug output until the logger lands", Until: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)}},
+		{name: "path and quoted rule", rule: `"Error Handling"`, args: ` path=./legacy/** reason=vendored until=2026-01-01`,
+			want: store.Exemption{Rule: "Error Handling", Path: "legacy/**", Reason: "vendored", Until: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		{name: "no reason", rule: "no-println", args: " until=2025-09-01", wantErr: "reason is required"},
+		{name: "no until", rule: "no-println", args: ` reason="later"`, wantErr: "until date is required"},
+		{name: "bad date", rule: "no-println", args: ` reason="later" until=09/01/2025`, wantErr: "must be a date"},
+		{name: "past date", rule: "no-println", args: ` reason="later" until=2025-08-01`, wantErr: "already passed"},
+		{name: "unknown argument", rule: "no-println", args: ` reason="later" until=2025-09-01 scope=org`, wantErr: "unknown argument"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExemption(tt.rule, tt.args, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseExemption() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseExemption() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseExemption() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessor_Exemptions(t *testing.T) {
+	permission := "write"
+	var replies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/collaborators/octocat/permission"):
+			fmt.Fprintf(w, `{"permission":%q}`, permission)
+		case strings.HasSuffix(r.URL.Path, "/pulls/42"):
+			w.Write([]byte(`{"number":42,"head":{"sha":"head123","ref":"feature","repo":{"full_name":"owner/repo"}}}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/issues/42/comments"):
+			var c struct {
+				Body string `json:"body"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&c)
+			replies = append(replies, c.Body)
+			w.Write([]byte(`{"id":1}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exemptions, err := store.OpenFileStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reviews := &MockReviewService{}
+	p := NewProcessor(&MockPRWorkspace{}, nil, reviews, gh)
+	p.SetExemptions(exemptions)
+	now := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	comment := func(body string) {
+		t.Helper()
+		payload, _ := json.Marshal(map[string]any{
+			"action":     "created",
+			"issue":      map[string]any{"number": 42, "pull_request": map[string]any{}},
+			"comment":    map[string]any{"body": body, "user": map[string]any{"login": "octocat"}},
+			"repository": map[string]any{"full_name": "owner/repo"},
+		})
+		if err := p.Process(context.Background(), "issue_comment", payload, "test-delivery"); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+	}
+
+	permission = "read"
+	comment(`@prmate exempt no-println reason="debugging" until=2025-09-01`)
+	permission = "write"
+	comment(`@prmate exempt no-println until=2025-09-01`)
+	comment(`@prmate exempt no-println reason="debugging" until=2025-09-01`)
+	comment(`@prmate exempt small-files path=legacy/** reason="vendored" until=2025-08-15`)
+
+	if len(replies) != 4 {
+		t.Fatalf("replies = %q, want 4", replies)
+	}
+	for i, want := range []string{"only collaborators with write access", "reason is required", "no longer reports `no-println` in this pull request until 2025-09-01", "in `legacy/**` in any pull request"} {
+		if !strings.Contains(replies[i], want) {
+			t.Errorf("reply %d = %q, want %q", i, replies[i], want)
+		}
+	}
+
+	if _, err := p.ReviewPullRequest(context.Background(), "owner", "repo", 42); err != nil {
+		t.Fatalf("ReviewPullRequest() error = %v", err)
+	}
+	want := []review.Exemption{{Rule: "no-println"}, {Rule: "small-files", Path: "legacy/**"}}
+	if fmt.Sprint(reviews.reviewReq.Exemptions) != fmt.Sprint(want) {
+		t.Errorf("review exemptions = %+v, want %+v", reviews.reviewReq.Exemptions, want)
+	}
+
+	// The path exemption expires and is reminded about once
+	now = time.Date(2025, 8, 20, 0, 0, 0, 0, time.UTC)
+	for range 2 {
+		if err := p.RemindExpiredExemptions(context.Background()); err != nil {
+			t.Fatalf("RemindExpiredExemptions() error = %v", err)
+		}
+	}
+	if len(replies) != 5 || !strings.Contains(replies[4], "@octocat, the exemption from `small-files`") {
+		t.Errorf("reminders = %q, want one for small-files", replies[4:])
+	}
+	if _, err := p.ReviewPullRequest(context.Background(), "owner", "repo", 42); err != nil {
+		t.Fatalf("ReviewPullRequest() error = %v", err)
+	}
+	if len(reviews.reviewReq.Exemptions) != 1 {
+		t.Errorf("review exemptions = %+v, want the expired one gone", reviews.reviewReq.Exemptions)
+	}
+}
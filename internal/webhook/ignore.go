@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/logging"
+	"prmate/internal/review"
+)
+
+// ignoreCommand matches an "@prmate ignore" line, which may go on to say
+// why
+var ignoreCommand = regexp.MustCompile(`(?im)^\s*@prmate\s+ignore\b`)
+
+// handleReviewComment answers "@prmate ignore" replies to PRMate's inline
+// comments
+func (p *Processor) handleReviewComment(ctx context.Context, e *ghclient.ReviewCommentEvent) error {
+	if strings.ToLower(e.Action) != "created" || e.InReplyTo == 0 || !ignoreCommand.MatchString(e.Body) {
+		return nil
+	}
+	if p.reviewService == nil || p.githubClient == nil {
+		return nil
+	}
+	owner, repo, err := ghclient.ParseRepoFullName(e.Repo)
+	if err != nil {
+		return fmt.Errorf("parse repo name: %w", err)
+	}
+	ctx = logging.With(ctx, "repo", e.Repo, "pr", e.Number)
+	return p.handleIgnore(ctx, owner, repo, e.Number, e.Author, e.InReplyTo)
+}
+
+// handleIgnore records the finding of the inline comment commentID as
+// ignored, so later reviews of the pull request no longer report it, when
+// user wrote the pull request or may write to the repository
+func (p *Processor) handleIgnore(ctx context.Context, owner, repo string, prNumber int, user string, commentID int64) error {
+	logger := logging.FromContext(ctx)
+	reply := func(body string) error {
+		if err := p.githubClient.ReplyToReviewComment(ctx, owner, repo, prNumber, commentID, body); err != nil {
+			return fmt.Errorf("post ignore reply: %w", err)
+		}
+		return nil
+	}
+
+	comment, err := p.githubClient.GetReviewComment(ctx, owner, repo, commentID)
+	if err != nil {
+		return fmt.Errorf("get review comment: %w", err)
+	}
+	rule, _, ok := review.FindingComment(comment.Body)
+	if !ok {
+		return reply("PRMate can only ignore its own findings: reply `@prmate ignore` to one of its comments.")
+	}
+
+	pr, err := p.githubClient.GetPullRequest(ctx, owner, repo, prNumber)
+	if err != nil {
+		return fmt.Errorf("get pull request: %w", err)
+	}
+	if !strings.EqualFold(user, pr.Author) {
+		perm, err := p.githubClient.GetPermission(ctx, owner, repo, user)
+		if err != nil {
+			return fmt.Errorf("get permission of %s: %w", user, err)
+		}
+		if perm != "admin" && perm != "write" {
+			logger.Info("Ignoring ignore command from user without write access", "user", user, "permission", perm)
+			return reply(fmt.Sprintf("🔒 @%s, only the author and collaborators with write access can ignore findings.", user))
+		}
+	}
+
+	x := review.NewSuppression(comment.Path, comment.Line, rule, comment.DiffHunk, user)
+	err = p.reviewService.Suppress(ctx, owner, repo, prNumber, x)
+	if errors.Is(err, review.ErrNotReviewed) {
+		return reply("PRMate could not ignore this finding: it found no review of this pull request to record it in.")
+	}
+	if err != nil {
+		return fmt.Errorf("suppress finding: %w", err)
+	}
+
+	logger.Info("Ignored finding", "user", user, "path", x.Path, "line", x.Line, "rule", x.Rule)
+	return reply(fmt.Sprintf("🙈 PRMate no longer reports `%s` on this line in this pull request, as asked by @%s.", rule, user))
+}
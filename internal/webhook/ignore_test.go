@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/review"
+)
+
+func TestProcessor_IgnoreCommand(t *testing.T) {
+	permission := "read"
+	var replies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/permission"):
+			fmt.Fprintf(w, `{"permission":%q}`, permission)
+		case strings.HasSuffix(r.URL.Path, "/pulls/comments/10"):
+			w.Write([]byte(`{"id":10,"body":"⚠️ **Error Handling**: Wrap the error","path":"handler.go","line":6,"diff_hunk":"@@ -1,0 +4,3 @@\n+\tif err != nil {\n+\t\tlog(err)\n+\t\treturn err"}`))
+		case strings.HasSuffix(r.URL.Path, "/pulls/comments/11"):
+			w.Write([]byte(`{"id":11,"body":"Why not wrap it here?","path":"handler.go","line":6}`))
+		case strings.HasSuffix(r.URL.Path, "/pulls/42"):
+			w.Write([]byte(`{"number":42,"user":{"login":"octocat"},"head":{"sha":"head123","ref":"feature","repo":{"full_name":"owner/repo"}},"base":{"ref":"main","repo":{"full_name":"owner/repo"}}}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/pulls/42/comments"):
+			var c struct {
+				Body string `json:"body"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&c)
+			replies = append(replies, c.Body)
+			w.Write([]byte(`{"id":1}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reviews := &MockReviewService{}
+	p := NewProcessor(&MockPRWorkspace{}, nil, reviews, gh)
+	reply := func(user string, inReplyTo int64, body string) {
+		t.Helper()
+		payload, _ := json.Marshal(map[string]any{
+			"action":       "created",
+			"pull_request": map[string]any{"number": 42},
+			"comment":      map[string]any{"id": 20, "in_reply_to_id": inReplyTo, "body": body, "user": map[string]any{"login": user}},
+			"repository":   map[string]any{"full_name": "owner/repo"},
+		})
+		if err := p.Process(context.Background(), "pull_request_review_comment", payload, "test-delivery"); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+	}
+
+	reply("octocat", 10, "Makes sense, thanks")
+	reply("octocat", 0, "@prmate ignore")
+	if len(replies) != 0 {
+		t.Fatalf("replies = %q, want comments other than ignore replies passed over", replies)
+	}
+
+	reply("octocat", 11, "@prmate ignore")
+	if len(replies) != 1 || !strings.Contains(replies[0], "can only ignore its own findings") {
+		t.Fatalf("replies = %q, want other comments refused", replies)
+	}
+
+	reply("hubot", 10, "@prmate ignore")
+	if len(replies) != 2 || !strings.Contains(replies[1], "only the author and collaborators with write access") || len(reviews.suppressed) != 0 {
+		t.Fatalf("replies = %q, want the command refused", replies)
+	}
+
+	reviews.suppressErr = review.ErrNotReviewed
+	reply("octocat", 10, "@prmate ignore")
+	if len(replies) != 3 || !strings.Contains(replies[2], "found no review of this pull request") {
+		t.Fatalf("replies = %q, want no review to record it in", replies)
+	}
+
+	reviews.suppressErr = nil
+	permission = "write"
+	reply("hubot", 10, "@prmate ignore this is generated code")
+	want := review.Suppression{Path: "handler.go", Line: 6, Rule: "Error Handling", Code: "return err", By: "hubot"}
+	if len(reviews.suppressed) != 1 || reviews.suppressed[0] != want {
+		t.Fatalf("suppressed = %+v, want %+v", reviews.suppressed, want)
+	}
+	if len(replies) != 4 || !strings.Contains(replies[3], "no longer reports `Error Handling` on this line") {
+		t.Errorf("replies = %q, want the finding ignored", replies)
+	}
+}
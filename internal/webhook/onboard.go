@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"prmate/internal/errreport"
+	"prmate/internal/logging"
+	"prmate/internal/scan"
+)
+
+// Onboarder opens a pull request adding .prmate.md to a repository without
+// one; scan.Service satisfies it
+type Onboarder interface {
+	Onboard(ctx context.Context, owner, repo string) (*scan.Onboarding, error)
+}
+
+// SetOnboarder has o onboard repositories whose pull requests arrive without
+// .prmate.md, instead of only skipping their review
+func (p *Processor) SetOnboarder(o Onboarder) {
+	p.onboarder = o
+}
+
+// onboard starts onboarding a repository whose pull request has no
+// .prmate.md, once per repository while the server runs, and tells the
+// pull request when an onboarding pull request was opened for it
+func (p *Processor) onboard(ctx context.Context, owner, repo string, prNumber int) {
+	if p.onboarder == nil {
+		return
+	}
+	key := owner + "/" + repo
+	p.onboardMu.Lock()
+	started := p.onboarded[key]
+	p.onboarded[key] = true
+	p.onboardMu.Unlock()
+	if started {
+		return
+	}
+
+	logger := logging.FromContext(ctx)
+	onboarding, err := p.onboarder.Onboard(ctx, owner, repo)
+	if err != nil {
+		// The next pull request event tries again
+		p.onboardMu.Lock()
+		delete(p.onboarded, key)
+		p.onboardMu.Unlock()
+		logger.Error("onboarding failed", "error", err)
+		errreport.Capture(ctx, fmt.Errorf("onboarding: %w", err))
+		return
+	}
+	if onboarding == nil || !onboarding.Opened || p.githubClient == nil || onboarding.PRNumber == prNumber {
+		return
+	}
+	body := fmt.Sprintf("👋 PRMate is not set up for this repository yet, so this pull request was not reviewed. "+
+		"#%d adds a generated `.prmate.md`; pull requests are reviewed once it is merged.", onboarding.PRNumber)
+	if err := p.githubClient.CreatePRComment(ctx, owner, repo, prNumber, body); err != nil {
+		logger.Warn("could not point the pull request to onboarding", "error", err)
+	}
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/scan"
+)
+
+// fakeOnboarder fails its first call and opens pull request 7 afterwards
+type fakeOnboarder struct {
+	calls int
+}
+
+func (f *fakeOnboarder) Onboard(ctx context.Context, owner, repo string) (*scan.Onboarding, error) {
+	f.calls++
+	if f.calls == 1 {
+		return nil, errors.New("clone failed")
+	}
+	return &scan.Onboarding{PRNumber: 7, Opened: true}, nil
+}
+
+func TestProcessor_OnboardsRepositoryWithoutPRMateFile(t *testing.T) {
+	var replies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/issues/42/comments") {
+			var c struct {
+				Body string `json:"body"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&c)
+			replies = append(replies, c.Body)
+			w.Write([]byte(`{"id":1}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	onboarder := &fakeOnboarder{}
+	reviews := &MockReviewService{}
+	p := NewProcessor(&MockPRWorkspace{}, nil, reviews, gh)
+	p.SetOnboarder(onboarder)
+
+	payload, _ := json.Marshal(map[string]any{
+		"action":       "synchronize",
+		"pull_request": map[string]any{"number": 42, "head": map[string]any{"ref": "feature"}},
+		"repository":   map[string]any{"full_name": "owner/repo"},
+	})
+	// The failed attempt is retried on the next event, the opened pull
+	// request is not
+	for range 3 {
+		if err := p.Process(context.Background(), "pull_request", payload, "d1"); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+	}
+
+	if onboarder.calls != 2 {
+		t.Errorf("Onboard called %d times, want 2", onboarder.calls)
+	}
+	if reviews.reviewCalled {
+		t.Error("a pull request without .prmate.md should not be reviewed")
+	}
+	if len(replies) != 1 || !strings.Contains(replies[0], "#7 adds a generated `.prmate.md`") {
+		t.Errorf("replies = %q, want one pointing to #7", replies)
+	}
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"prmate/internal/errclass"
+	"prmate/internal/logging"
+	"prmate/internal/review"
+)
+
+// reviewCommand matches an "@prmate review" line
+var reviewCommand = regexp.MustCompile(`(?im)^\s*@prmate\s+review\s*$`)
+
+// handleReview reviews every changed file of a pull request at its current
+// head, for its author and collaborators with write access, replying when
+// the review starts and with its outcome. A failed review is reported by
+// ReviewPullRequest's own comment.
+func (p *Processor) handleReview(ctx context.Context, owner, repo string, prNumber int, user string) error {
+	logger := logging.FromContext(ctx)
+	reply := func(body string) error {
+		if err := p.githubClient.CreatePRComment(ctx, owner, repo, prNumber, body); err != nil {
+			return fmt.Errorf("post review comment: %w", err)
+		}
+		return nil
+	}
+
+	pr, err := p.githubClient.GetPullRequest(ctx, owner, repo, prNumber)
+	if err != nil {
+		return fmt.Errorf("get pull request: %w", err)
+	}
+	if !strings.EqualFold(user, pr.Author) {
+		perm, err := p.githubClient.GetPermission(ctx, owner, repo, user)
+		if err != nil {
+			return fmt.Errorf("get permission of %s: %w", user, err)
+		}
+		if perm != "admin" && perm != "write" {
+			logger.Info("Ignoring review command from user without write access", "user", user, "permission", perm)
+			return reply(fmt.Sprintf("🔒 @%s, only the author and collaborators with write access can ask PRMate for a review.", user))
+		}
+	}
+	if !p.reviewService.HasPRMateFile(ctx, owner, repo, pr.ConfigRef()) {
+		return reply(fmt.Sprintf("PRMate cannot review this pull request: there is no `.prmate.md` on `%s` to take its rules from.", pr.ConfigRef()))
+	}
+
+	logger.Info("Reviewing on request", "user", user, "head_sha", pr.HeadSHA)
+	// A retried review was acknowledged on the first attempt
+	if !reviewOnly(ctx) {
+		if err := reply(fmt.Sprintf("🔍 @%s, PRMate is reviewing this pull request at %s.", user, pr.HeadSHA)); err != nil {
+			return err
+		}
+	}
+	result, err := p.reviewPullRequest(ctx, owner, repo, prNumber, pr, true)
+	if err != nil {
+		if errclass.Retryable(err) {
+			return &reviewRetryError{err: err}
+		}
+		return err
+	}
+	return reply(reviewOutcome(result))
+}
+
+// reviewOutcome sums up a review run on request
+func reviewOutcome(result *review.ReviewResult) string {
+	if result.FilesReviewed == 0 {
+		return fmt.Sprintf("✅ PRMate found nothing to review at %s.", result.ReviewedCommit)
+	}
+	if result.ViolationsFound == 0 {
+		return fmt.Sprintf("✅ PRMate reviewed %d file(s) at %s and found no issues.", result.FilesReviewed, result.ReviewedCommit)
+	}
+	return fmt.Sprintf("⚠️ PRMate reviewed %d file(s) at %s and found %d issue(s). See the review and the summary comment for details.",
+		result.FilesReviewed, result.ReviewedCommit, result.ViolationsFound)
+}
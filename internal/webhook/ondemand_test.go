@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+)
+
+func TestProcessor_ReviewCommand(t *testing.T) {
+	permission := "read"
+	var replies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/permission"):
+			fmt.Fprintf(w, `{"permission":%q}`, permission)
+		case strings.HasSuffix(r.URL.Path, "/pulls/42"):
+			w.Write([]byte(`{"number":42,"user":{"login":"octocat"},"head":{"sha":"head123","ref":"feature","repo":{"full_name":"owner/repo"}},"base":{"ref":"main","repo":{"full_name":"owner/repo"}}}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/issues/42/comments"):
+			var c struct {
+				Body string `json:"body"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&c)
+			replies = append(replies, c.Body)
+			w.Write([]byte(`{"id":1}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reviews := &MockReviewService{}
+	p := NewProcessor(&MockPRWorkspace{}, &MockScanService{}, reviews, gh)
+	comment := func(user, body string) {
+		t.Helper()
+		payload, _ := json.Marshal(map[string]any{
+			"action":     "created",
+			"issue":      map[string]any{"number": 42, "pull_request": map[string]any{}},
+			"comment":    map[string]any{"body": body, "user": map[string]any{"login": user}},
+			"repository": map[string]any{"full_name": "owner/repo"},
+		})
+		if err := p.Process(context.Background(), "issue_comment", payload, "test-delivery"); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+	}
+
+	comment("hubot", "@prmate review")
+	if len(replies) != 1 || !strings.Contains(replies[0], "only the author and collaborators with write access") || reviews.reviewCalled {
+		t.Fatalf("replies = %q, want the review refused", replies)
+	}
+
+	comment("octocat", "Pushed the fix.\n@prmate review")
+	if len(replies) != 2 || !strings.Contains(replies[1], "no `.prmate.md` on `feature`") || reviews.reviewCalled {
+		t.Fatalf("replies = %q, want no rules to review by", replies)
+	}
+
+	reviews.hasPRMate = true
+	permission = "write"
+	comment("hubot", "@prmate review")
+	if !reviews.reviewCalled || !reviews.reviewReq.Full || reviews.reviewReq.HeadSHA != "head123" {
+		t.Fatalf("review request = %+v, want a full review of head123", reviews.reviewReq)
+	}
+	if len(replies) != 4 {
+		t.Fatalf("replies = %q, want progress and result", replies)
+	}
+	if !strings.Contains(replies[2], "@hubot, PRMate is reviewing this pull request at head123") {
+		t.Errorf("progress = %q", replies[2])
+	}
+	if !strings.Contains(replies[3], "reviewed 1 file(s)") || !strings.Contains(replies[3], "found no issues") {
+		t.Errorf("result = %q", replies[3])
+	}
+}
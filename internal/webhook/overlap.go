@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+
+	"prmate/internal/logging"
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+// ReviewHistory lists recorded reviews; store.FileStore satisfies it
+type ReviewHistory interface {
+	ListReviews(ctx context.Context, f store.Filter) ([]store.ReviewRecord, error)
+}
+
+// SetReviewHistory notes in each review summary the other open pull
+// requests changing the same files, as recorded by their latest review in h
+func (p *Processor) SetReviewHistory(h ReviewHistory) {
+	p.history = h
+}
+
+// applyOverlaps adds to req the files the other open pull requests of its
+// repository changed when last reviewed. Pull requests never reviewed are
+// left out. Failures are logged, and the review then notes no overlaps.
+func (p *Processor) applyOverlaps(ctx context.Context, req *review.ReviewRequest) {
+	if p.history == nil || p.githubClient == nil {
+		return
+	}
+	logger := logging.FromContext(ctx)
+
+	records, err := p.history.ListReviews(ctx, store.Filter{Instance: p.instance, Owner: req.Owner, Repo: req.Repo})
+	if err != nil {
+		logger.Warn("could not list recorded reviews", "error", err)
+		return
+	}
+	// Records are newest first; the latest review of each pull request
+	// that got as far as listing its files is kept
+	latest := make(map[int][]string)
+	var order []int
+	for _, r := range records {
+		if r.PRNumber == req.PRNumber || len(r.Files) == 0 {
+			continue
+		}
+		if _, ok := latest[r.PRNumber]; !ok {
+			latest[r.PRNumber] = r.Files
+			order = append(order, r.PRNumber)
+		}
+	}
+	if len(order) == 0 {
+		return
+	}
+
+	prs, err := p.githubClient.ListOpenPullRequests(ctx, req.Owner, req.Repo)
+	if err != nil {
+		logger.Warn("could not list open pull requests", "error", err)
+		return
+	}
+	open := make(map[int]bool, len(prs))
+	for _, pr := range prs {
+		open[pr.Number] = true
+	}
+	for _, number := range order {
+		if open[number] {
+			req.OtherPRs = append(req.OtherPRs, review.OpenPR{Number: number, Files: latest[number]})
+		}
+	}
+}
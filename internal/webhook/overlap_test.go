@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+func TestProcessor_ApplyOverlaps(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/owner/repo/pulls" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{"number": 7}, {"number": 12}, {"number": 13}]`))
+	}))
+	defer srv.Close()
+	gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := store.OpenFileStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	for _, r := range []store.ReviewRecord{
+		{ID: "1", Owner: "owner", Repo: "repo", PRNumber: 12, Files: []string{"old.go"}, FinishedAt: base},
+		{ID: "2", Owner: "owner", Repo: "repo", PRNumber: 12, Files: []string{"auth.go"}, FinishedAt: base.Add(time.Hour)},
+		{ID: "3", Owner: "owner", Repo: "repo", PRNumber: 12, Error: "timeout", FinishedAt: base.Add(2 * time.Hour)},
+		{ID: "4", Owner: "owner", Repo: "repo", PRNumber: 9, Files: []string{"auth.go"}, FinishedAt: base}, // closed since
+		{ID: "5", Owner: "owner", Repo: "other", PRNumber: 13, Files: []string{"auth.go"}, FinishedAt: base},
+		{ID: "6", Owner: "owner", Repo: "repo", PRNumber: 7, Files: []string{"auth.go"}, FinishedAt: base},
+	} {
+		if err := history.RecordReview(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := NewProcessor(&MockPRWorkspace{}, nil, nil, gh)
+	p.SetReviewHistory(history)
+	req := review.ReviewRequest{Owner: "owner", Repo: "repo", PRNumber: 7}
+	p.applyOverlaps(context.Background(), &req)
+
+	want := []review.OpenPR{{Number: 12, Files: []string{"auth.go"}}}
+	if fmt.Sprint(req.OtherPRs) != fmt.Sprint(want) {
+		t.Errorf("OtherPRs = %+v, want %+v", req.OtherPRs, want)
+	}
+}
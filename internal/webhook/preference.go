@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"prmate/internal/logging"
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+// preferenceCommand matches an "@prmate mute <rule>", "@prmate unmute
+// <rule>" or "@prmate inline on|off" line; a rule with spaces is quoted
+var preferenceCommand = regexp.MustCompile(`(?im)^\s*@prmate\s+(mute|unmute|inline)\s+("[^"\n]+"|\S+)\s*$`)
+
+// preferenceUsage is replied to malformed preference commands
+const preferenceUsage = "Usage: `@prmate mute <rule>`, `@prmate unmute <rule>` or `@prmate inline on|off`"
+
+// PreferenceStore persists how developers want to hear about findings;
+// store.FileStore satisfies it
+type PreferenceStore interface {
+	SavePreference(ctx context.Context, p store.Preference) error
+	GetPreference(ctx context.Context, instance, user string) (store.Preference, error)
+}
+
+// SetPreferences enables the "@prmate mute", "@prmate unmute" and
+// "@prmate inline" comment commands, keeping each developer's preferences
+// in s and applying them to the pull requests they author
+func (p *Processor) SetPreferences(s PreferenceStore) {
+	p.preferences = s
+}
+
+// handlePreference changes the preferences of user, who may only change
+// their own, and replies with the preferences now in place
+func (p *Processor) handlePreference(ctx context.Context, owner, repo string, prNumber int, user, verb, arg string) error {
+	logger := logging.FromContext(ctx)
+	reply := func(body string) error {
+		if err := p.githubClient.CreatePRComment(ctx, owner, repo, prNumber, body); err != nil {
+			return fmt.Errorf("post preference comment: %w", err)
+		}
+		return nil
+	}
+
+	pref, err := p.preferences.GetPreference(ctx, p.instance, user)
+	if err != nil {
+		return fmt.Errorf("get preference of %s: %w", user, err)
+	}
+	if err := changePreference(&pref, strings.ToLower(verb), strings.Trim(arg, `"`)); err != nil {
+		return reply(fmt.Sprintf("PRMate could not change your preferences: %v.\n\n%s", err, preferenceUsage))
+	}
+	pref.Instance, pref.User, pref.UpdatedAt = p.instance, user, p.now().UTC()
+	if err := p.preferences.SavePreference(ctx, pref); err != nil {
+		return fmt.Errorf("save preference: %w", err)
+	}
+
+	logger.Info("Changed preferences", "user", user, "summary_only", pref.SummaryOnly, "muted", len(pref.Muted))
+	return reply(fmt.Sprintf("🔕 @%s, %s\n\nThis applies to every pull request you author, from its next review. "+
+		"Findings are still counted in the summary, and a review still requests changes when one reaches the merge gate.",
+		user, describePreference(pref)))
+}
+
+// changePreference applies one preference command to pref
+func changePreference(pref *store.Preference, verb, arg string) error {
+	switch verb {
+	case "inline":
+		switch strings.ToLower(arg) {
+		case "off":
+			pref.SummaryOnly = true
+		case "on":
+			pref.SummaryOnly = false
+		default:
+			return fmt.Errorf("inline takes on or off, not %q", arg)
+		}
+	case "mute":
+		for _, rule := range pref.Muted {
+			if strings.EqualFold(rule, arg) {
+				return nil
+			}
+		}
+		pref.Muted = append(pref.Muted, arg)
+	case "unmute":
+		kept := pref.Muted[:0]
+		for _, rule := range pref.Muted {
+			if !strings.EqualFold(rule, arg) {
+				kept = append(kept, rule)
+			}
+		}
+		if len(kept) == len(pref.Muted) {
+			return fmt.Errorf("`%s` is not muted", arg)
+		}
+		pref.Muted = kept
+	}
+	return nil
+}
+
+// describePreference says what PRMate comments on for a developer
+func describePreference(pref store.Preference) string {
+	if pref.SummaryOnly {
+		return "PRMate no longer posts inline comments on your pull requests; their findings are listed in the summary comment."
+	}
+	if len(pref.Muted) == 0 {
+		return "PRMate comments on every finding on your pull requests."
+	}
+	quoted := make([]string, len(pref.Muted))
+	for i, rule := range pref.Muted {
+		quoted[i] = "`" + rule + "`"
+	}
+	return fmt.Sprintf("PRMate no longer comments on %s on your pull requests.", strings.Join(quoted, ", "))
+}
+
+// applyPreferences gives req the preferences of the pull request's author.
+// Failures are logged, and every finding is then commented on.
+func (p *Processor) applyPreferences(ctx context.Context, req *review.ReviewRequest, author string) {
+	if p.preferences == nil || author == "" {
+		return
+	}
+	pref, err := p.preferences.GetPreference(ctx, p.instance, author)
+	if err != nil {
+		logging.FromContext(ctx).Warn("could not get preferences of the author", "author", author, "error", err)
+		return
+	}
+	req.Preferences = review.Preferences{SummaryOnly: pref.SummaryOnly, Muted: pref.Muted}
+}
@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/store"
+)
+
+func TestProcessor_Preferences(t *testing.T) {
+	var replies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/pulls/42"):
+			w.Write([]byte(`{"number":42,"user":{"login":"Octocat"},"head":{"sha":"head123","ref":"feature","repo":{"full_name":"owner/repo"}}}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/issues/42/comments"):
+			var c struct {
+				Body string `json:"body"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&c)
+			replies = append(replies, c.Body)
+			w.Write([]byte(`{"id":1}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preferences, err := store.OpenFileStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reviews := &MockReviewService{}
+	p := NewProcessor(&MockPRWorkspace{}, nil, reviews, gh)
+	p.SetPreferences(preferences)
+
+	comment := func(user, body string) {
+		t.Helper()
+		payload, _ := json.Marshal(map[string]any{
+			"action":     "created",
+			"issue":      map[string]any{"number": 42, "pull_request": map[string]any{}},
+			"comment":    map[string]any{"body": body, "user": map[string]any{"login": user}},
+			"repository": map[string]any{"full_name": "owner/repo"},
+		})
+		if err := p.Process(context.Background(), "issue_comment", payload, "test-delivery"); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+	}
+
+	comment("octocat", "@prmate mute no-println")
+	comment("octocat", `@prmate mute "Error Handling"`)
+	comment("octocat", "@prmate unmute small-files")
+	comment("octocat", "@prmate inline maybe")
+	comment("hubot", "@prmate inline off")
+
+	if len(replies) != 5 {
+		t.Fatalf("replies = %q, want 5", replies)
+	}
+	for i, want := range []string{
+		"@octocat, PRMate no longer comments on `no-println` on your pull requests",
+		"`no-println`, `Error Handling`",
+		"`small-files` is not muted",
+		"inline takes on or off",
+		"@hubot, PRMate no longer posts inline comments",
+	} {
+		if !strings.Contains(replies[i], want) {
+			t.Errorf("reply %d = %q, want %q", i, replies[i], want)
+		}
+	}
+
+	// The author's preferences apply to their pull request, not those of
+	// whoever else commented on it
+	if _, err := p.ReviewPullRequest(context.Background(), "owner", "repo", 42); err != nil {
+		t.Fatalf("ReviewPullRequest() error = %v", err)
+	}
+	got := reviews.reviewReq.Preferences
+	if got.SummaryOnly || strings.Join(got.Muted, ",") != "no-println,Error Handling" {
+		t.Errorf("review preferences = %+v, want octocat's muted rules", got)
+	}
+
+	comment("octocat", "@prmate unmute no-println")
+	comment("octocat", "@prmate unmute \"error handling\"")
+	if !strings.Contains(replies[len(replies)-1], "PRMate comments on every finding") {
+		t.Errorf("reply = %q, want every finding commented on", replies[len(replies)-1])
+	}
+}
@@ -2,15 +2,37 @@ package webhook
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
-
-	"github.com/google/go-github/v82/github"
-
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"prmate/internal/artifacts"
+	"prmate/internal/audit"
+	"prmate/internal/budget"
+	"prmate/internal/checks"
+	"prmate/internal/correlation"
+	"prmate/internal/errclass"
+	"prmate/internal/errreport"
+	"prmate/internal/explain"
+	"prmate/internal/fix"
 	ghclient "prmate/internal/github"
+	"prmate/internal/logging"
+	"prmate/internal/notify"
+	"prmate/internal/prworkspace"
 	"prmate/internal/review"
 	"prmate/internal/scan"
+	"prmate/internal/store"
+	"prmate/internal/tickets"
+	"prmate/internal/tracing"
 )
 
 type PRWorkspace interface {
@@ -29,6 +51,66 @@ type ScanService interface {
 type ReviewService interface {
 	ReviewPR(ctx context.Context, req review.ReviewRequest) (*review.ReviewResult, error)
 	HasPRMateFile(ctx context.Context, owner, repo, ref string) bool
+	LastReviewedSHA(ctx context.Context, owner, repo string, prNumber int) (string, error)
+	OpenFindings(ctx context.Context, owner, repo string, prNumber int) ([]review.OpenFinding, error)
+	PostingWindow(ctx context.Context, owner, repo, ref string) (*checks.Window, error)
+	Suppress(ctx context.Context, owner, repo string, prNumber int, x review.Suppression) error
+	ForgetRules(ctx context.Context, owner, repo, ref string, paths []string)
+}
+
+// ReviewNotifier tells outside systems about finished reviews
+type ReviewNotifier interface {
+	Send(s notify.Summary)
+}
+
+// TicketFiler opens issue tracker tickets for findings still open at merge
+type TicketFiler interface {
+	File(ctx context.Context, pr tickets.PullRequest, findings []review.OpenFinding) (*tickets.Ref, error)
+}
+
+// Fixer pushes a commit fixing findings to a pull request branch
+type Fixer interface {
+	Apply(ctx context.Context, req fix.Request) (*fix.Result, error)
+}
+
+// Explainer explains a range of lines in a pull request
+type Explainer interface {
+	Explain(ctx context.Context, req explain.Request) (*explain.Result, error)
+}
+
+// ConflictChecker explains the merge conflicts of a pull request, if any
+type ConflictChecker interface {
+	Check(ctx context.Context, owner, repo string, prNumber int) (bool, error)
+}
+
+// ChangelogChecker asks for a changelog entry on pull requests that need
+// one
+type ChangelogChecker interface {
+	Check(ctx context.Context, owner, repo string, prNumber int) (bool, error)
+}
+
+// DependencyReviewer reports the dependency changes of a pull request
+type DependencyReviewer interface {
+	Review(ctx context.Context, owner, repo string, prNumber int) (bool, error)
+}
+
+// BudgetChecker reports the LLM token budget a repository has used up, if
+// any
+type BudgetChecker interface {
+	Check(ctx context.Context, owner, repo string) (*budget.Exceeded, error)
+}
+
+// budgetMarkerPrefix marks the notice posted when a pull request is first
+// reviewed over a budget, so each budget period is announced once
+const budgetMarkerPrefix = "<!-- prmate-budget:"
+
+// maxConflictChecks bounds the pull requests checked after one push to
+// their base branch
+const maxConflictChecks = 20
+
+// ReviewRecorder persists the outcome of each review
+type ReviewRecorder interface {
+	RecordReview(ctx context.Context, r store.ReviewRecord) error
 }
 
 type Processor struct {
@@ -36,6 +118,35 @@ type Processor struct {
 	scanService   ScanService
 	reviewService ReviewService
 	githubClient  *ghclient.Client
+	recorder      ReviewRecorder
+	instance      string
+	artifacts     artifacts.Store
+	notifier      ReviewNotifier
+	tickets       TicketFiler
+	fixer         Fixer
+	explainer     Explainer
+	conflicts     ConflictChecker
+	changelog     ChangelogChecker
+	deps          DependencyReviewer
+	budget        BudgetChecker
+	jobs          JobSubmitter
+	onboarder     Onboarder
+	triager       Triager
+	exemptions    ExemptionStore
+	preferences   PreferenceStore
+	smoke         SmokeRunner
+	history       ReviewHistory
+	shadow        bool
+	now           func() time.Time
+
+	shadowReviews  atomic.Int64
+	shadowFindings atomic.Int64
+
+	heldMu sync.Mutex
+	held   map[string]time.Time // pull requests waiting for their posting window, by owner/repo#number
+
+	onboardMu sync.Mutex
+	onboarded map[string]bool // repositories onboarding was started for, by owner/repo
 }
 
 func NewProcessor(prWorkspace PRWorkspace, scanService ScanService, reviewService ReviewService, githubClient *ghclient.Client) *Processor {
@@ -44,75 +155,365 @@ func NewProcessor(prWorkspace PRWorkspace, scanService ScanService, reviewServic
 		scanService:   scanService,
 		reviewService: reviewService,
 		githubClient:  githubClient,
+		now:           time.Now,
+		held:          make(map[string]time.Time),
+		onboarded:     make(map[string]bool),
 	}
 }
 
-func (p *Processor) Process(ctx context.Context, eventType string, payload []byte, deliveryID string) error {
-	_ = deliveryID
+// SetRecorder records every review run by this processor under the given
+// SCM instance name
+func (p *Processor) SetRecorder(instance string, recorder ReviewRecorder) {
+	p.instance = instance
+	p.recorder = recorder
+}
+
+// SetNotifier sends a summary of every review, successful or not, to n
+func (p *Processor) SetNotifier(n ReviewNotifier) {
+	p.notifier = n
+}
+
+// SetTicketFiler files tickets for the open findings of merged pull requests
+func (p *Processor) SetTicketFiler(f TicketFiler) {
+	p.tickets = f
+}
+
+// SetFixer enables the "@prmate fix" comment command
+func (p *Processor) SetFixer(f Fixer) {
+	p.fixer = f
+}
+
+// SetExplainer enables the "@prmate explain" comment command
+func (p *Processor) SetExplainer(e Explainer) {
+	p.explainer = e
+}
+
+// SetConflictChecker comments on pull requests that have merge conflicts,
+// checked on pull request updates and pushes to their base branch
+func (p *Processor) SetConflictChecker(c ConflictChecker) {
+	p.conflicts = c
+}
+
+// SetChangelogChecker checks opened and updated pull requests for a
+// changelog entry
+func (p *Processor) SetChangelogChecker(c ChangelogChecker) {
+	p.changelog = c
+}
+
+// SetDependencyReviewer reports manifest changes on opened and updated
+// pull requests
+func (p *Processor) SetDependencyReviewer(r DependencyReviewer) {
+	p.deps = r
+}
+
+// SetBudget reviews repositories that have spent their LLM token budget
+// with the deterministic checks only
+func (p *Processor) SetBudget(b BudgetChecker) {
+	p.budget = b
+}
+
+// SetArtifactStore keeps the full result of every successful review in store
+func (p *Processor) SetArtifactStore(store artifacts.Store) {
+	p.artifacts = store
+}
+
+func (p *Processor) Process(ctx context.Context, eventType string, payload []byte, deliveryID string) (err error) {
+	ctx = tracing.WithAttributes(ctx,
+		attribute.String("github.event", eventType),
+		attribute.String("github.delivery_id", deliveryID),
+	)
+	ctx, span := tracing.Start(ctx, "webhook.process")
+	defer func() {
+		errreport.Capture(ctx, err)
+		tracing.End(span, err)
+	}()
+
+	correlationID := correlation.FromContext(ctx)
+	if correlationID == "" {
+		correlationID = deliveryID
+		if !correlation.Valid(correlationID) {
+			correlationID = correlation.NewID("")
+		}
+		ctx = correlation.WithID(ctx, correlationID)
+	}
+	span.SetAttributes(attribute.String("prmate.correlation_id", correlationID))
+
+	ctx = logging.With(ctx, "correlation_id", correlationID, "delivery_id", deliveryID, "event", eventType)
+
 	if p.prWorkspace == nil {
 		return fmt.Errorf("pr workspace not configured")
 	}
 
-	event, err := github.ParseWebHook(eventType, payload)
+	event, err := ghclient.ParseEvent(eventType, payload)
 	if err != nil {
 		return fmt.Errorf("parse webhook event: %w", err)
 	}
 
 	switch e := event.(type) {
-	case *github.PingEvent:
+	case *ghclient.PingEvent:
 		return nil
-	case *github.PullRequestEvent:
+	case *ghclient.PullRequestEvent:
+		ctx = audit.WithActor(ctx, "github:"+e.Sender)
+		ctx = audit.WithReason(ctx, eventType+"."+e.Action)
 		return p.handlePullRequest(ctx, e)
-	case *github.IssueCommentEvent:
+	case *ghclient.IssueCommentEvent:
+		ctx = audit.WithActor(ctx, "github:"+e.Sender)
+		ctx = audit.WithReason(ctx, eventType+"."+e.Action)
 		return p.handleIssueComment(ctx, e)
+	case *ghclient.ReviewCommentEvent:
+		ctx = audit.WithActor(ctx, "github:"+e.Sender)
+		ctx = audit.WithReason(ctx, eventType+"."+e.Action)
+		return p.handleReviewComment(ctx, e)
+	case *ghclient.IssuesEvent:
+		ctx = audit.WithActor(ctx, "github:"+e.Sender)
+		ctx = audit.WithReason(ctx, eventType+"."+e.Action)
+		return p.handleIssues(ctx, e)
+	case *ghclient.PushEvent:
+		return p.handlePush(ctx, e)
 	default:
 		return nil
 	}
 }
 
-func (p *Processor) handlePullRequest(ctx context.Context, e *github.PullRequestEvent) error {
-	action := strings.ToLower(e.GetAction())
-	repoFullName := e.GetRepo().GetFullName()
-	prNumber := e.GetPullRequest().GetNumber()
-	branch := e.GetPullRequest().GetHead().GetRef()
+func (p *Processor) handlePullRequest(ctx context.Context, e *ghclient.PullRequestEvent) error {
+	action := strings.ToLower(e.Action)
+	repoFullName := e.Repo
+	prNumber := e.PullRequest.Number
 
 	owner, repo, err := ghclient.ParseRepoFullName(repoFullName)
 	if err != nil {
 		return fmt.Errorf("parse repo name: %w", err)
 	}
 
+	ctx = logging.With(ctx, "repo", repoFullName, "pr", prNumber, "action", action)
+	logger := logging.FromContext(ctx)
+
 	switch action {
 	case "opened", "reopened", "synchronize":
 		_, err := p.prWorkspace.EnsurePRDir(ctx, repoFullName, prNumber)
 		if err != nil {
+			if errors.Is(err, prworkspace.ErrDiskFull) && p.githubClient != nil {
+				_ = p.githubClient.CreatePRComment(ctx, owner, repo, prNumber,
+					"⚠️ PRMate skipped this pull request: the server is out of workspace disk space. "+
+						"It will be reviewed on the next push once space is freed.")
+			}
 			return fmt.Errorf("ensure pr workspace: %w", err)
 		}
 
-		// Check for @scan directive in .prmate.md
-		if p.scanService != nil {
-			if err := p.checkAndProcessScan(ctx, owner, repo, prNumber, branch); err != nil {
-				log.Printf("scan processing failed: %v", err)
+		// Outside the repository's posting window the review waits for it
+		if opens, closed := p.postingWindowOpens(ctx, owner, repo, e.PullRequest.ConfigRef()); closed {
+			p.hold(ctx, owner, repo, prNumber, opens)
+			return nil
+		}
+		return p.checkPullRequest(ctx, owner, repo, &e.PullRequest)
+	case "closed":
+		if err := p.prWorkspace.DeletePRDir(ctx, repoFullName, prNumber); err != nil {
+			return fmt.Errorf("delete pr workspace: %w", err)
+		}
+		if e.Merged && p.tickets != nil && p.reviewService != nil {
+			if err := p.fileTickets(ctx, owner, repo, &e.PullRequest); err != nil {
+				logger.Error("ticket filing failed", "error", err)
+				errreport.Capture(ctx, fmt.Errorf("ticket filing: %w", err))
 				// Don't fail the webhook, just log
 			}
 		}
+		return nil
+	default:
+		return nil
+	}
+}
 
-		// After scan (or if .prmate.md already exists), run the review
-		if p.reviewService != nil {
-			if err := p.runPRReview(ctx, owner, repo, prNumber, branch); err != nil {
-				log.Printf("review processing failed: %v", err)
+// checkPullRequest runs the scan, review and checks an opened or updated
+// pull request gets, posting their results
+func (p *Processor) checkPullRequest(ctx context.Context, owner, repo string, pr *ghclient.PullRequest) error {
+	logger := logging.FromContext(ctx)
+	prNumber := pr.Number
+	retryingReview := reviewOnly(ctx)
+
+	// Check for @scan directive in .prmate.md. Scans push to the pull
+	// request's branch, which forks do not let PRMate do. A retried review
+	// skips the scan, which ran on the first attempt.
+	if p.scanService != nil && !retryingReview && pr.FromFork() {
+		logger.Info("Skipping scan of a pull request from a fork", "head_repo", pr.HeadRepo)
+	} else if p.scanService != nil && !retryingReview {
+		if err := p.checkAndProcessScan(ctx, owner, repo, prNumber, pr.HeadRef); err != nil {
+			logger.Error("scan processing failed", "error", err)
+			errreport.Capture(ctx, fmt.Errorf("scan processing: %w", err))
+			// Don't fail the webhook, just log
+		}
+	}
+
+	// After scan (or if .prmate.md already exists), run the review
+	var reviewErr error
+	if p.reviewService != nil {
+		if err := p.runPRReview(ctx, owner, repo, prNumber, pr.ConfigRef()); err != nil {
+			if errclass.Retryable(err) {
+				// The delivery is retried once the dependency is back,
+				// after the checks below have run
+				reviewErr = &reviewRetryError{err: err}
+			} else {
+				logger.Error("review processing failed", "error", err)
+				errreport.Capture(ctx, fmt.Errorf("review processing: %w", err))
 				// Don't fail the webhook, just log
 			}
 		}
+	}
+	if retryingReview {
+		return reviewErr
+	}
+
+	if p.conflicts != nil {
+		if _, err := p.conflicts.Check(ctx, owner, repo, prNumber); err != nil {
+			logger.Error("conflict check failed", "error", err)
+			errreport.Capture(ctx, fmt.Errorf("conflict check: %w", err))
+			// Don't fail the webhook, just log
+		}
+	}
+
+	if p.changelog != nil {
+		if _, err := p.changelog.Check(ctx, owner, repo, prNumber); err != nil {
+			logger.Error("changelog check failed", "error", err)
+			errreport.Capture(ctx, fmt.Errorf("changelog check: %w", err))
+			// Don't fail the webhook, just log
+		}
+	}
+
+	if p.deps != nil {
+		if _, err := p.deps.Review(ctx, owner, repo, prNumber); err != nil {
+			logger.Error("dependency review failed", "error", err)
+			errreport.Capture(ctx, fmt.Errorf("dependency review: %w", err))
+			// Don't fail the webhook, just log
+		}
+	}
+
+	return reviewErr
+}
 
+// reviewRetryError is returned for a pull request whose review failed with a
+// retryable error after its scan and other checks ran, or after an
+// "@prmate review" was acknowledged. A retry of the delivery carries
+// withReviewOnly so only the review runs again.
+type reviewRetryError struct {
+	err error
+}
+
+func (e *reviewRetryError) Error() string { return "review: " + e.err.Error() }
+func (e *reviewRetryError) Unwrap() error { return e.err }
+
+// repliedError is a failure already reported on the pull request. It hides
+// the class of the error it wraps, so the delivery is not retried to repeat
+// the command and its reply.
+type repliedError struct {
+	err error
+}
+
+func replied(err error) error { return &repliedError{err: err} }
+
+func (e *repliedError) Error() string { return e.err.Error() }
+
+type reviewOnlyKey struct{}
+
+// withReviewOnly marks ctx as the retry of a delivery whose review failed,
+// so checkPullRequest runs the review alone
+func withReviewOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reviewOnlyKey{}, true)
+}
+
+func reviewOnly(ctx context.Context) bool {
+	v, _ := ctx.Value(reviewOnlyKey{}).(bool)
+	return v
+}
+
+// handlePush drops the rules cached for the pushed branch when the push
+// changed them, and checks the open pull requests based on the branch for
+// merge conflicts the push introduced
+func (p *Processor) handlePush(ctx context.Context, e *ghclient.PushEvent) error {
+	branch, ok := strings.CutPrefix(e.Ref, "refs/heads/")
+	if !ok {
 		return nil
-	case "closed":
-		if err := p.prWorkspace.DeletePRDir(ctx, repoFullName, prNumber); err != nil {
-			return fmt.Errorf("delete pr workspace: %w", err)
+	}
+	owner, repo, err := ghclient.ParseRepoFullName(e.Repo)
+	if err != nil {
+		return fmt.Errorf("parse repo name: %w", err)
+	}
+	ctx = logging.With(ctx, "repo", e.Repo, "branch", branch)
+	logger := logging.FromContext(ctx)
+
+	if p.reviewService != nil {
+		changed := e.Files
+		if e.Forced || e.Deleted {
+			changed = nil // the branch may now hold any rules
 		}
+		p.reviewService.ForgetRules(ctx, owner, repo, branch, changed)
+	}
+	if p.conflicts == nil || p.githubClient == nil || e.Deleted {
 		return nil
-	default:
+	}
+
+	prs, err := p.githubClient.ListOpenPullRequests(ctx, owner, repo)
+	if err != nil {
+		return fmt.Errorf("list open pull requests: %w", err)
+	}
+	checked := 0
+	for _, pr := range prs {
+		if pr.BaseRef != branch {
+			continue
+		}
+		if checked == maxConflictChecks {
+			logger.Info("Skipping conflict checks beyond the limit", "limit", maxConflictChecks)
+			break
+		}
+		checked++
+		if _, err := p.conflicts.Check(logging.With(ctx, "pr", pr.Number), owner, repo, pr.Number); err != nil {
+			logger.Error("conflict check failed", "pr", pr.Number, "error", err)
+			errreport.Capture(ctx, fmt.Errorf("conflict check: %w", err))
+		}
+	}
+	return nil
+}
+
+// fileTickets hands the findings still open on a merged pull request to the
+// ticket filer and links the resulting ticket from the pull request
+func (p *Processor) fileTickets(ctx context.Context, owner, repo string, pr *ghclient.PullRequest) error {
+	findings, err := p.reviewService.OpenFindings(ctx, owner, repo, pr.Number)
+	if err != nil {
+		return fmt.Errorf("get open findings: %w", err)
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	logger := logging.FromContext(ctx)
+	if p.githubClient != nil && p.githubClient.DryRun(owner, repo) {
+		logger.Info("dry run: skipping ticket for open findings", "findings", len(findings))
 		return nil
 	}
+
+	ref, err := p.tickets.File(ctx, tickets.PullRequest{
+		Owner:  owner,
+		Repo:   repo,
+		Number: pr.Number,
+		Title:  pr.Title,
+		Branch: pr.HeadRef,
+		URL:    pr.HTMLURL,
+	}, findings)
+	if err != nil || ref == nil {
+		return err
+	}
+	logger.Info("filed ticket for open findings", "ticket", ref.Key, "created", ref.Created, "findings", len(findings))
+
+	if p.githubClient == nil {
+		return nil
+	}
+	verb := "Added the unresolved findings to"
+	if ref.Created {
+		verb = "Opened"
+	}
+	body := fmt.Sprintf("🎫 This pull request was merged with %d unresolved error-severity findings. %s [%s](%s) to track them.", len(findings), verb, ref.Key, ref.URL)
+	if err := p.githubClient.CreatePRComment(ctx, owner, repo, pr.Number, body); err != nil {
+		return fmt.Errorf("post ticket comment: %w", err)
+	}
+	return nil
 }
 
 func (p *Processor) checkAndProcessScan(ctx context.Context, owner, repo string, prNumber int, branch string) error {
@@ -125,7 +526,8 @@ func (p *Processor) checkAndProcessScan(ctx context.Context, owner, repo string,
 		return nil
 	}
 
-	log.Printf("Found @scan directive in %s/%s PR #%d, external repos: %v", owner, repo, prNumber, externalRepos)
+	logger := logging.FromContext(ctx)
+	logger.Info("Found @scan directive", "external_repos", externalRepos)
 
 	// Process the scan
 	req := scan.ScanRequest{
@@ -152,84 +554,461 @@ func (p *Processor) checkAndProcessScan(ctx context.Context, owner, repo string,
 			"✅ PRMate scan completed. `.prmate.md` has been updated with codebase context.")
 	}
 
-	log.Printf("Scan completed for %s/%s PR #%d, temp file: %s", owner, repo, prNumber, result.TempFilePath)
+	logger.Info("Scan completed", "temp_file", result.TempFilePath, "artifact", result.ArtifactLocation)
 
 	return nil
 }
 
 // handleIssueComment processes issue/PR comment events for @prmate directive
-func (p *Processor) handleIssueComment(ctx context.Context, e *github.IssueCommentEvent) error {
+func (p *Processor) handleIssueComment(ctx context.Context, e *ghclient.IssueCommentEvent) error {
 	// Only handle PR comments (issues with pull_request field)
-	if e.GetIssue().GetPullRequestLinks() == nil {
+	if !e.OnPullRequest {
 		return nil
 	}
 
-	action := strings.ToLower(e.GetAction())
+	action := strings.ToLower(e.Action)
 	if action != "created" {
 		return nil
 	}
 
-	body := e.GetComment().GetBody()
+	body := e.Body
+	if m := fixCommand.FindStringSubmatch(body); m != nil {
+		if p.fixer == nil || p.reviewService == nil || p.githubClient == nil {
+			return nil
+		}
+		owner, repo, err := ghclient.ParseRepoFullName(e.Repo)
+		if err != nil {
+			return fmt.Errorf("parse repo name: %w", err)
+		}
+		ctx = logging.With(ctx, "repo", e.Repo, "pr", e.Number)
+		return p.handleFix(ctx, owner, repo, e.Number, e.Author, m[1])
+	}
+	if m := explainCommand.FindStringSubmatch(body); m != nil {
+		if p.explainer == nil || p.githubClient == nil {
+			return nil
+		}
+		owner, repo, err := ghclient.ParseRepoFullName(e.Repo)
+		if err != nil {
+			return fmt.Errorf("parse repo name: %w", err)
+		}
+		start, _ := strconv.Atoi(m[2])
+		end := start
+		if m[3] != "" {
+			end, _ = strconv.Atoi(m[3])
+		}
+		ctx = logging.With(ctx, "repo", e.Repo, "pr", e.Number)
+		return p.handleExplain(ctx, owner, repo, e.Number, e.Author, m[1], start, end)
+	}
+	if m := exemptCommand.FindStringSubmatch(body); m != nil {
+		if p.exemptions == nil || p.githubClient == nil {
+			return nil
+		}
+		owner, repo, err := ghclient.ParseRepoFullName(e.Repo)
+		if err != nil {
+			return fmt.Errorf("parse repo name: %w", err)
+		}
+		ctx = logging.With(ctx, "repo", e.Repo, "pr", e.Number)
+		return p.handleExempt(ctx, owner, repo, e.Number, e.Author, m[1], m[2])
+	}
+	if m := preferenceCommand.FindStringSubmatch(body); m != nil {
+		if p.preferences == nil || p.githubClient == nil {
+			return nil
+		}
+		owner, repo, err := ghclient.ParseRepoFullName(e.Repo)
+		if err != nil {
+			return fmt.Errorf("parse repo name: %w", err)
+		}
+		ctx = logging.With(ctx, "repo", e.Repo, "pr", e.Number)
+		return p.handlePreference(ctx, owner, repo, e.Number, e.Author, m[1], m[2])
+	}
+	if reviewCommand.MatchString(body) {
+		if p.reviewService == nil || p.githubClient == nil {
+			return nil
+		}
+		owner, repo, err := ghclient.ParseRepoFullName(e.Repo)
+		if err != nil {
+			return fmt.Errorf("parse repo name: %w", err)
+		}
+		ctx = logging.With(ctx, "repo", e.Repo, "pr", e.Number)
+		return p.handleReview(ctx, owner, repo, e.Number, e.Author)
+	}
 	if !p.scanService.CheckForPRMateDirective(body) {
 		return nil
 	}
 
-	repoFullName := e.GetRepo().GetFullName()
-	prNumber := e.GetIssue().GetNumber()
+	repoFullName := e.Repo
+	prNumber := e.Number
 
 	owner, repo, err := ghclient.ParseRepoFullName(repoFullName)
 	if err != nil {
 		return fmt.Errorf("parse repo name: %w", err)
 	}
 
-	// Get PR branch
-	branch, err := p.githubClient.GetPRBranch(ctx, owner, repo, prNumber)
+	pr, err := p.githubClient.GetPullRequest(ctx, owner, repo, prNumber)
 	if err != nil {
-		return fmt.Errorf("get pr branch: %w", err)
+		return fmt.Errorf("get pull request: %w", err)
 	}
 
-	log.Printf("Found @prmate directive in comment on %s/%s PR #%d", owner, repo, prNumber)
+	ctx = logging.With(ctx, "repo", repoFullName, "pr", prNumber)
+	logging.FromContext(ctx).Info("Found @prmate directive in comment")
+	if pr.FromFork() {
+		// Scans push to the branch; tell whoever asked for one why not
+		hasScan, _, err := p.scanService.CheckForScanDirective(ctx, owner, repo, pr.ConfigRef())
+		if err != nil {
+			return fmt.Errorf("check scan directive: %w", err)
+		}
+		if !hasScan {
+			return nil
+		}
+		if err := p.githubClient.CreatePRComment(ctx, owner, repo, prNumber,
+			"PRMate can only scan branches of this repository, not forks, since it pushes the updated `.prmate.md` to the branch."); err != nil {
+			return fmt.Errorf("post scan comment: %w", err)
+		}
+		return nil
+	}
 
 	// Check for @scan directive and process
-	return p.checkAndProcessScan(ctx, owner, repo, prNumber, branch)
+	return p.checkAndProcessScan(ctx, owner, repo, prNumber, pr.HeadRef)
+}
+
+// fixCommand matches an "@prmate fix" line, optionally naming one file
+var fixCommand = regexp.MustCompile(`(?im)^\s*@prmate\s+fix(?:\s+(\S+))?\s*$`)
+
+// handleFix pushes a commit fixing the open findings on a pull request,
+// or only those in file, when user may write to the repository
+func (p *Processor) handleFix(ctx context.Context, owner, repo string, prNumber int, user, file string) error {
+	logger := logging.FromContext(ctx)
+	reply := func(body string) error {
+		if err := p.githubClient.CreatePRComment(ctx, owner, repo, prNumber, body); err != nil {
+			return fmt.Errorf("post fix comment: %w", err)
+		}
+		return nil
+	}
+
+	perm, err := p.githubClient.GetPermission(ctx, owner, repo, user)
+	if err != nil {
+		return fmt.Errorf("get permission of %s: %w", user, err)
+	}
+	if perm != "admin" && perm != "write" {
+		logger.Info("Ignoring fix command from user without write access", "user", user, "permission", perm)
+		return reply(fmt.Sprintf("🔒 @%s, only collaborators with write access can ask PRMate to push fixes.", user))
+	}
+
+	pr, err := p.githubClient.GetPullRequest(ctx, owner, repo, prNumber)
+	if err != nil {
+		return fmt.Errorf("get pull request: %w", err)
+	}
+	if !strings.EqualFold(pr.HeadRepo, owner+"/"+repo) {
+		return reply("PRMate can only push fixes to branches of this repository, not to forks.")
+	}
+
+	findings, err := p.reviewService.OpenFindings(ctx, owner, repo, prNumber)
+	if err != nil {
+		return fmt.Errorf("get open findings: %w", err)
+	}
+	if file != "" {
+		file = path.Clean(strings.TrimPrefix(file, "./"))
+		var inFile []review.OpenFinding
+		for _, f := range findings {
+			if f.Path == file {
+				inFile = append(inFile, f)
+			}
+		}
+		findings = inFile
+	}
+	if len(findings) == 0 {
+		where := ""
+		if file != "" {
+			where = fmt.Sprintf(" in `%s`", file)
+		}
+		return reply(fmt.Sprintf("There are no open PRMate findings%s to fix.", where))
+	}
+
+	dir, err := p.prWorkspace.EnsurePRDir(ctx, owner+"/"+repo, prNumber)
+	if err != nil {
+		return fmt.Errorf("ensure pr workspace: %w", err)
+	}
+
+	logger.Info("Applying fixes", "user", user, "findings", len(findings), "file", file)
+	result, err := p.fixer.Apply(ctx, fix.Request{
+		Owner:     owner,
+		Repo:      repo,
+		PRNumber:  prNumber,
+		Branch:    pr.HeadRef,
+		Dir:       dir,
+		Requester: user,
+		Findings:  findings,
+	})
+	switch {
+	case errors.Is(err, fix.ErrNothingToFix):
+		return reply("PRMate could not produce a change for these findings." + skippedList(result))
+	case err != nil:
+		_ = reply(fmt.Sprintf("❌ PRMate could not push fixes: %v", err))
+		return fmt.Errorf("apply fixes: %w", replied(err))
+	case result.CommitSHA == "":
+		return reply(fmt.Sprintf("🔧 Dry run: PRMate would push this change for %d finding(s):\n\n```diff\n%s\n```%s",
+			result.Fixed, truncateDiff(result.Diff), skippedList(result)))
+	}
+	return reply(fmt.Sprintf("🔧 Pushed %s fixing %d finding(s) in %s.%s",
+		result.CommitSHA, result.Fixed, "`"+strings.Join(result.Files, "`, `")+"`", skippedList(result)))
 }
 
-// runPRReview performs a PR review if .prmate.md exists
-func (p *Processor) runPRReview(ctx context.Context, owner, repo string, prNumber int, branch string) error {
+// explainCommand matches an "@prmate explain path:start-end" line; a single
+// line may be given as "path:line"
+var explainCommand = regexp.MustCompile(`(?im)^\s*@prmate\s+explain\s+(\S+?):(\d+)(?:-(\d+))?\s*$`)
+
+// handleExplain replies with an explanation of lines start to end of file
+// at the head of the pull request, for its author and collaborators with
+// write access
+func (p *Processor) handleExplain(ctx context.Context, owner, repo string, prNumber int, user, file string, start, end int) error {
+	logger := logging.FromContext(ctx)
+	reply := func(body string) error {
+		if err := p.githubClient.CreatePRComment(ctx, owner, repo, prNumber, body); err != nil {
+			return fmt.Errorf("post explain comment: %w", err)
+		}
+		return nil
+	}
+
+	pr, err := p.githubClient.GetPullRequest(ctx, owner, repo, prNumber)
+	if err != nil {
+		return fmt.Errorf("get pull request: %w", err)
+	}
+	if !strings.EqualFold(user, pr.Author) {
+		perm, err := p.githubClient.GetPermission(ctx, owner, repo, user)
+		if err != nil {
+			return fmt.Errorf("get permission of %s: %w", user, err)
+		}
+		if perm != "admin" && perm != "write" {
+			logger.Info("Ignoring explain command from user without write access", "user", user, "permission", perm)
+			return reply(fmt.Sprintf("🔒 @%s, only the author and collaborators with write access can ask PRMate to explain code.", user))
+		}
+	}
+
+	file = path.Clean(strings.TrimPrefix(file, "./"))
+	logger.Info("Explaining code", "user", user, "file", file, "start", start, "end", end)
+	result, err := p.explainer.Explain(ctx, explain.Request{
+		Owner: owner,
+		Repo:  repo,
+		Ref:   pr.HeadSHA,
+		Path:  file,
+		Start: start,
+		End:   end,
+	})
+	switch {
+	case errors.Is(err, explain.ErrBadRange):
+		return reply(fmt.Sprintf("PRMate could not explain `%s`: %v.", file, err))
+	case errors.Is(err, errclass.ErrNotFound):
+		return reply(fmt.Sprintf("PRMate could not explain `%s`: the file is not in this pull request's head commit.", file))
+	case err != nil:
+		_ = reply(fmt.Sprintf("❌ PRMate could not explain `%s`: %v", file, err))
+		return fmt.Errorf("explain code: %w", replied(err))
+	}
+
+	lines := fmt.Sprintf("line %d", start)
+	if end != start {
+		lines = fmt.Sprintf("lines %d-%d", start, end)
+	}
+	body := fmt.Sprintf("💡 **`%s` %s** at %s\n\n%s", file, lines, pr.HeadSHA, result.Explanation)
+	if len(result.Dependencies) > 0 {
+		body += "\n\n<sub>Context: `" + strings.Join(result.Dependencies, "`, `") + "`</sub>"
+	}
+	return reply(body)
+}
+
+// skippedList explains the files a fix left alone
+func skippedList(result *fix.Result) string {
+	if result == nil || len(result.Skipped) == 0 {
+		return ""
+	}
+	return "\n\nSkipped:\n- " + strings.Join(result.Skipped, "\n- ")
+}
+
+// truncateDiff keeps a dry-run diff well inside GitHub's comment size limit
+func truncateDiff(diff string) string {
+	const limit = 50000
+	if len(diff) <= limit {
+		return diff
+	}
+	return diff[:limit] + "\n… diff truncated"
+}
+
+// runPRReview performs a PR review if .prmate.md exists at ref
+func (p *Processor) runPRReview(ctx context.Context, owner, repo string, prNumber int, ref string) error {
 	// Check if .prmate.md exists
-	if !p.reviewService.HasPRMateFile(ctx, owner, repo, branch) {
-		log.Printf("No .prmate.md found for %s/%s, skipping review", owner, repo)
+	logger := logging.FromContext(ctx)
+	if !p.reviewService.HasPRMateFile(ctx, owner, repo, ref) {
+		logger.Info("No .prmate.md found, skipping review")
+		p.onboard(ctx, owner, repo, prNumber)
 		return nil
 	}
 
+	_, err := p.ReviewPullRequest(ctx, owner, repo, prNumber)
+	return err
+}
+
+// ReviewPullRequest fetches the PR's current head and reviews it, commenting
+// on the PR if the review fails. Used by webhooks and manual triggers alike.
+func (p *Processor) ReviewPullRequest(ctx context.Context, owner, repo string, prNumber int) (*review.ReviewResult, error) {
+	if p.reviewService == nil || p.githubClient == nil {
+		return nil, fmt.Errorf("review service not configured")
+	}
+
 	// Get PR details for the review
 	pr, err := p.githubClient.GetPullRequest(ctx, owner, repo, prNumber)
 	if err != nil {
-		return fmt.Errorf("get pull request: %w", err)
+		return nil, fmt.Errorf("get pull request: %w", err)
 	}
+	return p.reviewPullRequest(ctx, owner, repo, prNumber, pr, false)
+}
 
-	log.Printf("Starting PR review for %s/%s PR #%d (commit: %s)", owner, repo, prNumber, pr.HeadSHA[:7])
+// reviewPullRequest reviews pr at its head, every changed file when full
+func (p *Processor) reviewPullRequest(ctx context.Context, owner, repo string, prNumber int, pr *ghclient.PullRequest, full bool) (*review.ReviewResult, error) {
+	logger := logging.FromContext(ctx)
+	logger.Info("Starting PR review", "head_sha", pr.HeadSHA, "full", full)
 
 	req := review.ReviewRequest{
 		Owner:    owner,
 		Repo:     repo,
 		PRNumber: prNumber,
 		HeadSHA:  pr.HeadSHA,
-		HeadRef:  pr.HeadRef,
+		HeadRef:  pr.ContentRef(),
 		BaseSHA:  pr.BaseSHA,
+		// A fork is reviewed by the rules of the repository it targets
+		ConfigRef: pr.ConfigRef(),
+		Full:      full,
 	}
+	p.applyBudget(ctx, &req)
+	p.applyExemptions(ctx, &req)
+	p.applyPreferences(ctx, &req, pr.Author)
+	p.applySmoke(ctx, &req, pr)
+	p.applyOverlaps(ctx, &req)
 
+	startedAt := time.Now().UTC()
 	result, err := p.reviewService.ReviewPR(ctx, req)
+	p.recordReview(ctx, req, startedAt, result, err)
+	if p.notifier != nil {
+		summary := notify.NewSummary(req, pr.Title, pr.HTMLURL, result, err)
+		summary.Instance = p.instance
+		p.notifier.Send(summary)
+	}
 	if err != nil {
-		if p.githubClient != nil {
+		// A review stopped by a rate limit or an open circuit breaker is
+		// retried later
+		if !errclass.Retryable(err) {
 			_ = p.githubClient.CreatePRComment(ctx, owner, repo, prNumber,
 				fmt.Sprintf("❌ PRMate review failed: %v", err))
 		}
-		return fmt.Errorf("review pr: %w", err)
+		return nil, fmt.Errorf("review pr: %w", err)
 	}
 
-	log.Printf("Review completed for %s/%s PR #%d: %d files reviewed, %d issues found",
-		owner, repo, prNumber, result.FilesReviewed, result.ViolationsFound)
+	logger.Info("Review completed", "files_reviewed", result.FilesReviewed, "issues_found", result.ViolationsFound)
+	p.storeReviewArtifact(ctx, req, result)
 
-	return nil
+	return result, nil
+}
+
+// applyBudget limits req to the deterministic checks when its repository
+// is over a token budget, telling the pull request once per budget period.
+// Failures are logged, and the review then runs as usual.
+func (p *Processor) applyBudget(ctx context.Context, req *review.ReviewRequest) {
+	if p.budget == nil {
+		return
+	}
+	logger := logging.FromContext(ctx)
+
+	exceeded, err := p.budget.Check(ctx, req.Owner, req.Repo)
+	if err != nil {
+		logger.Warn("failed to check token budget", "error", err)
+		return
+	}
+	if exceeded == nil {
+		return
+	}
+	req.ChecksOnly = true
+	logger.Warn("Token budget exceeded, reviewing with deterministic checks only",
+		"budget", exceeded.Key(), "used", exceeded.Used, "limit", exceeded.Limit)
+
+	marker := budgetMarkerPrefix + exceeded.Key() + " -->"
+	comments, err := p.githubClient.ListPRComments(ctx, req.Owner, req.Repo, req.PRNumber)
+	if err != nil {
+		logger.Warn("could not list PR comments", "error", err)
+		return
+	}
+	for _, c := range comments {
+		if strings.Contains(c, marker) {
+			return
+		}
+	}
+
+	scope := "Repository `" + exceeded.Scope + "`"
+	if !strings.Contains(exceeded.Scope, "/") {
+		scope = "Organization `" + exceeded.Scope + "`"
+	}
+	body := fmt.Sprintf("%s\n⚠️ **Token budget reached**: %s has used %d of its %s budget of %d LLM tokens. "+
+		"Reviews run the deterministic checks from `.prmate.md` only until %s.",
+		marker, scope, exceeded.Used, exceeded.Period, exceeded.Limit, exceeded.Resets.Format("2006-01-02 15:04 MST"))
+	if err := p.githubClient.CreatePRComment(ctx, req.Owner, req.Repo, req.PRNumber, body); err != nil {
+		logger.Warn("failed to post token budget notice", "error", err)
+	}
+}
+
+// storeReviewArtifact keeps the review result as JSON; failures are logged,
+// not returned
+func (p *Processor) storeReviewArtifact(ctx context.Context, req review.ReviewRequest, result *review.ReviewResult) {
+	if p.artifacts == nil {
+		return
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to encode review artifact", "error", err)
+		return
+	}
+	key := fmt.Sprintf("reviews/%s/%s/pr-%d/%s.json", req.Owner, req.Repo, req.PRNumber, req.HeadSHA)
+	if _, err := p.artifacts.Put(ctx, key, data, "application/json"); err != nil {
+		logging.FromContext(ctx).Warn("failed to store review artifact", "error", err)
+	}
+}
+
+// recordReview stores the review outcome; failures are logged, not returned
+func (p *Processor) recordReview(ctx context.Context, req review.ReviewRequest, startedAt time.Time, result *review.ReviewResult, reviewErr error) {
+	if p.recorder == nil {
+		return
+	}
+
+	rec := store.ReviewRecord{
+		ID:            correlation.NewID(fmt.Sprintf("%s-%d", req.HeadSHA, startedAt.UnixNano())),
+		Instance:      p.instance,
+		Owner:         req.Owner,
+		Repo:          req.Repo,
+		PRNumber:      req.PRNumber,
+		HeadSHA:       req.HeadSHA,
+		CorrelationID: correlation.FromContext(ctx),
+		StartedAt:     startedAt,
+		FinishedAt:    time.Now().UTC(),
+	}
+	if reviewErr != nil {
+		rec.Error = reviewErr.Error()
+	}
+	if result != nil {
+		rec.FilesReviewed = result.FilesReviewed
+		rec.CommentsPosted = result.CommentsPosted
+		rec.ViolationsFound = result.ViolationsFound
+		rec.EstimatedTokens = result.EstimatedTokens
+		rec.Files = result.Files
+		rec.Blocking = result.Blocking
+		if len(result.Violations) > 0 {
+			rec.RuleHits = make(map[string]int)
+			for _, v := range result.Violations {
+				rec.RuleHits[v.Rule]++
+			}
+		}
+	}
+	if p.shadow {
+		p.recordShadow(&rec, result)
+	}
+
+	if err := p.recorder.RecordReview(ctx, rec); err != nil {
+		logging.FromContext(ctx).Warn("failed to record review", "error", err)
+	}
 }
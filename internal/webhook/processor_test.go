@@ -3,11 +3,23 @@ package webhook
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
-
+	"time"
+
+	"prmate/internal/budget"
+	"prmate/internal/checks"
+	"prmate/internal/errclass"
+	"prmate/internal/explain"
+	"prmate/internal/fix"
+	ghclient "prmate/internal/github"
 	"prmate/internal/review"
 	"prmate/internal/scan"
+	"prmate/internal/tickets"
 )
 
 // MockPRWorkspace is a test double for PRWorkspace
@@ -67,11 +79,19 @@ func (m *MockScanService) CheckForPRMateDirective(content string) bool {
 // MockReviewService is a test double for ReviewService
 type MockReviewService struct {
 	reviewCalled bool
+	reviewReq    review.ReviewRequest
 	hasPRMate    bool
+	prmateRef    string // ref HasPRMateFile was last asked about
+	openFindings []review.OpenFinding
+	window       *checks.Window
+	suppressed   []review.Suppression
+	suppressErr  error
+	forgotten    []string // refs ForgetRules was called for, with the paths pushed
 }
 
 func (m *MockReviewService) ReviewPR(ctx context.Context, req review.ReviewRequest) (*review.ReviewResult, error) {
 	m.reviewCalled = true
+	m.reviewReq = req
 	return &review.ReviewResult{
 		FilesReviewed:   1,
 		CommentsPosted:  0,
@@ -80,9 +100,34 @@ func (m *MockReviewService) ReviewPR(ctx context.Context, req review.ReviewReque
 }
 
 func (m *MockReviewService) HasPRMateFile(ctx context.Context, owner, repo, ref string) bool {
+	m.prmateRef = ref
 	return m.hasPRMate
 }
 
+func (m *MockReviewService) LastReviewedSHA(ctx context.Context, owner, repo string, prNumber int) (string, error) {
+	return "", nil
+}
+
+func (m *MockReviewService) OpenFindings(ctx context.Context, owner, repo string, prNumber int) ([]review.OpenFinding, error) {
+	return m.openFindings, nil
+}
+
+func (m *MockReviewService) Suppress(ctx context.Context, owner, repo string, prNumber int, x review.Suppression) error {
+	if m.suppressErr != nil {
+		return m.suppressErr
+	}
+	m.suppressed = append(m.suppressed, x)
+	return nil
+}
+
+func (m *MockReviewService) ForgetRules(ctx context.Context, owner, repo, ref string, paths []string) {
+	m.forgotten = append(m.forgotten, fmt.Sprintf("%s/%s@%s %v", owner, repo, ref, paths))
+}
+
+func (m *MockReviewService) PostingWindow(ctx context.Context, owner, repo, ref string) (*checks.Window, error) {
+	return m.window, nil
+}
+
 func TestProcessor_Process_PingEvent(t *testing.T) {
 	mockWorkspace := &MockPRWorkspace{}
 	mockScan := &MockScanService{}
@@ -163,6 +208,57 @@ func TestProcessor_Process_PRClosed(t *testing.T) {
 	}
 }
 
+type fakeTicketFiler struct {
+	pr       tickets.PullRequest
+	findings []review.OpenFinding
+}
+
+func (f *fakeTicketFiler) File(ctx context.Context, pr tickets.PullRequest, findings []review.OpenFinding) (*tickets.Ref, error) {
+	f.pr, f.findings = pr, findings
+	return nil, nil
+}
+
+func TestProcessor_Process_MergedPRFilesTicket(t *testing.T) {
+	open := []review.OpenFinding{{Path: "a.go", Line: 3, Rule: "no-sql-concat", Severity: "error", Message: "SQL built from input"}}
+
+	tests := []struct {
+		name   string
+		merged bool
+		want   int
+	}{
+		{name: "merged", merged: true, want: 1},
+		{name: "closed without merge", merged: false, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filer := &fakeTicketFiler{}
+			p := NewProcessor(&MockPRWorkspace{}, nil, &MockReviewService{openFindings: open}, nil)
+			p.SetTicketFiler(filer)
+
+			payload, _ := json.Marshal(map[string]interface{}{
+				"action": "closed",
+				"pull_request": map[string]interface{}{
+					"number": 42,
+					"title":  "SEC-7 harden login",
+					"merged": tt.merged,
+					"head":   map[string]interface{}{"ref": "feature-branch"},
+				},
+				"repository": map[string]interface{}{"full_name": "owner/repo"},
+			})
+			if err := p.Process(context.Background(), "pull_request", payload, "test-delivery"); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if len(filer.findings) != tt.want {
+				t.Fatalf("filed %d findings, want %d", len(filer.findings), tt.want)
+			}
+			if tt.want > 0 && (filer.pr.Number != 42 || filer.pr.Title != "SEC-7 harden login" || filer.pr.Branch != "feature-branch") {
+				t.Errorf("pr = %+v", filer.pr)
+			}
+		})
+	}
+}
+
 func TestProcessor_Process_NilWorkspace(t *testing.T) {
 	p := NewProcessor(nil, nil, nil, nil)
 
@@ -228,6 +324,44 @@ func TestProcessor_Process_WithScanDirective(t *testing.T) {
 	}
 }
 
+func TestProcessor_Process_ForkPullRequest(t *testing.T) {
+	const pr = `{"number":42,"state":"open",
+		"head":{"ref":"main","sha":"fork123","repo":{"full_name":"someone/repo"}},
+		"base":{"ref":"main","sha":"base123","repo":{"full_name":"owner/repo"}}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/pulls/42") {
+			w.Write([]byte(pr))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockScan := &MockScanService{hasScanDirective: true}
+	reviews := &MockReviewService{hasPRMate: true}
+	p := NewProcessor(&MockPRWorkspace{}, mockScan, reviews, gh)
+
+	payload := []byte(`{"action":"synchronize","repository":{"full_name":"owner/repo"},"pull_request":` + pr + `}`)
+	if err := p.Process(context.Background(), "pull_request", payload, "test-delivery"); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if mockScan.processCalled {
+		t.Error("a scan should never push to the branch of a fork")
+	}
+	if reviews.prmateRef != "main" {
+		t.Errorf(".prmate.md looked up at %q, want the base branch", reviews.prmateRef)
+	}
+	req := reviews.reviewReq
+	if !reviews.reviewCalled || req.HeadRef != "fork123" || req.ConfigRef != "main" || req.HeadSHA != "fork123" {
+		t.Errorf("review request = %+v, want files read at the head commit and rules at the base branch", req)
+	}
+}
+
 func TestNewProcessor(t *testing.T) {
 	mockWorkspace := &MockPRWorkspace{}
 	mockScan := &MockScanService{}
@@ -245,3 +379,303 @@ func TestNewProcessor(t *testing.T) {
 		t.Error("reviewService not set correctly")
 	}
 }
+
+type fakeFixer struct {
+	req *fix.Request
+}
+
+func (f *fakeFixer) Apply(ctx context.Context, req fix.Request) (*fix.Result, error) {
+	f.req = &req
+	return &fix.Result{Files: []string{"a.go"}, Fixed: 1, CommitSHA: "abc1234"}, nil
+}
+
+func TestProcessor_Process_FixCommand(t *testing.T) {
+	open := []review.OpenFinding{
+		{Path: "a.go", Line: 3, Rule: "wrap-errors", Severity: "error", Message: "wrap it"},
+		{Path: "b.go", Line: 9, Rule: "naming", Severity: "warning", Message: "rename"},
+	}
+
+	tests := []struct {
+		name       string
+		comment    string
+		permission string
+		headRepo   string
+		wantFiles  []string
+		wantReply  string
+	}{
+		{name: "all findings", comment: "@prmate fix", permission: "write", headRepo: "owner/repo", wantFiles: []string{"a.go", "b.go"}, wantReply: "Pushed abc1234"},
+		{name: "one file", comment: "Thanks!\n@prmate fix ./b.go", permission: "admin", headRepo: "owner/repo", wantFiles: []string{"b.go"}, wantReply: "Pushed abc1234"},
+		{name: "file without findings", comment: "@prmate fix c.go", permission: "write", headRepo: "owner/repo", wantReply: "no open PRMate findings in `c.go`"},
+		{name: "read-only user", comment: "@prmate fix", permission: "read", headRepo: "owner/repo", wantReply: "only collaborators with write access"},
+		{name: "fork", comment: "@prmate fix", permission: "write", headRepo: "someone/repo", wantReply: "not to forks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replies []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/collaborators/octocat/permission"):
+					fmt.Fprintf(w, `{"permission":%q}`, tt.permission)
+				case strings.HasSuffix(r.URL.Path, "/pulls/42"):
+					fmt.Fprintf(w, `{"number":42,"head":{"ref":"feature","repo":{"full_name":%q}}}`, tt.headRepo)
+				case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/issues/42/comments"):
+					var c struct {
+						Body string `json:"body"`
+					}
+					_ = json.NewDecoder(r.Body).Decode(&c)
+					replies = append(replies, c.Body)
+					w.Write([]byte(`{"id":1}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+			gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fixer := &fakeFixer{}
+			p := NewProcessor(&MockPRWorkspace{}, nil, &MockReviewService{openFindings: open}, gh)
+			p.SetFixer(fixer)
+
+			payload, _ := json.Marshal(map[string]interface{}{
+				"action":     "created",
+				"issue":      map[string]interface{}{"number": 42, "pull_request": map[string]interface{}{}},
+				"comment":    map[string]interface{}{"body": tt.comment, "user": map[string]interface{}{"login": "octocat"}},
+				"repository": map[string]interface{}{"full_name": "owner/repo"},
+			})
+			if err := p.Process(context.Background(), "issue_comment", payload, "test-delivery"); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			var gotFiles []string
+			if fixer.req != nil {
+				for _, f := range fixer.req.Findings {
+					gotFiles = append(gotFiles, f.Path)
+				}
+				if fixer.req.Branch != "feature" || fixer.req.Requester != "octocat" || fixer.req.Dir == "" {
+					t.Errorf("request = %+v", fixer.req)
+				}
+			}
+			if strings.Join(gotFiles, ",") != strings.Join(tt.wantFiles, ",") {
+				t.Errorf("fixed findings in %v, want %v", gotFiles, tt.wantFiles)
+			}
+			if len(replies) != 1 || !strings.Contains(replies[0], tt.wantReply) {
+				t.Errorf("replies = %q, want one containing %q", replies, tt.wantReply)
+			}
+		})
+	}
+}
+
+type fakeExplainer struct {
+	req *explain.Request
+}
+
+func (f *fakeExplainer) Explain(ctx context.Context, req explain.Request) (*explain.Result, error) {
+	f.req = &req
+	if req.Path == "missing.go" {
+		return nil, fmt.Errorf("read missing.go: %w", errclass.Wrap(errors.New("404 Not Found"), errclass.ErrNotFound))
+	}
+	if req.Start > 200 {
+		return nil, fmt.Errorf("%w: %s has 100 lines", explain.ErrBadRange, req.Path)
+	}
+	return &explain.Result{Explanation: "It loads the user.", Dependencies: []string{"internal/store/types.go"}}, nil
+}
+
+func TestProcessor_Process_ExplainCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		comment    string
+		user       string
+		permission string
+		wantReq    *explain.Request
+		wantReply  string
+	}{
+		{name: "author", comment: "@prmate explain ./api/handler.go:120-160", user: "author",
+			wantReq: &explain.Request{Owner: "owner", Repo: "repo", Ref: "head123", Path: "api/handler.go", Start: 120, End: 160}, wantReply: "It loads the user."},
+		{name: "one line", comment: "What is this?\n@prmate explain a.go:7", user: "octocat", permission: "write",
+			wantReq: &explain.Request{Owner: "owner", Repo: "repo", Ref: "head123", Path: "a.go", Start: 7, End: 7}, wantReply: "**`a.go` line 7** at head123"},
+		{name: "bad range", comment: "@prmate explain a.go:220-230", user: "author",
+			wantReq: &explain.Request{Owner: "owner", Repo: "repo", Ref: "head123", Path: "a.go", Start: 220, End: 230}, wantReply: "a.go has 100 lines"},
+		{name: "missing file", comment: "@prmate explain missing.go:1", user: "author",
+			wantReq: &explain.Request{Owner: "owner", Repo: "repo", Ref: "head123", Path: "missing.go", Start: 1, End: 1}, wantReply: "not in this pull request's head commit"},
+		{name: "read-only user", comment: "@prmate explain a.go:1-2", user: "octocat", permission: "read", wantReply: "only the author and collaborators"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replies []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/collaborators/octocat/permission"):
+					fmt.Fprintf(w, `{"permission":%q}`, tt.permission)
+				case strings.HasSuffix(r.URL.Path, "/pulls/42"):
+					w.Write([]byte(`{"number":42,"user":{"login":"author"},"head":{"ref":"feature","sha":"head123"}}`))
+				case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/issues/42/comments"):
+					var c struct {
+						Body string `json:"body"`
+					}
+					_ = json.NewDecoder(r.Body).Decode(&c)
+					replies = append(replies, c.Body)
+					w.Write([]byte(`{"id":1}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+			gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			explainer := &fakeExplainer{}
+			p := NewProcessor(&MockPRWorkspace{}, nil, &MockReviewService{}, gh)
+			p.SetExplainer(explainer)
+
+			payload, _ := json.Marshal(map[string]interface{}{
+				"action":     "created",
+				"issue":      map[string]interface{}{"number": 42, "pull_request": map[string]interface{}{}},
+				"comment":    map[string]interface{}{"body": tt.comment, "user": map[string]interface{}{"login": tt.user}},
+				"repository": map[string]interface{}{"full_name": "owner/repo"},
+			})
+			if err := p.Process(context.Background(), "issue_comment", payload, "test-delivery"); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if fmt.Sprint(explainer.req) != fmt.Sprint(tt.wantReq) {
+				t.Errorf("request = %+v, want %+v", explainer.req, tt.wantReq)
+			}
+			if len(replies) != 1 || !strings.Contains(replies[0], tt.wantReply) {
+				t.Errorf("replies = %q, want one containing %q", replies, tt.wantReply)
+			}
+		})
+	}
+}
+
+type fakeBudget struct {
+	exceeded *budget.Exceeded
+}
+
+func (f fakeBudget) Check(ctx context.Context, owner, repo string) (*budget.Exceeded, error) {
+	return f.exceeded, nil
+}
+
+func TestProcessor_ReviewPullRequest_Budget(t *testing.T) {
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	over := &budget.Exceeded{Scope: "owner/repo", Period: "daily", Used: 1200, Limit: 1000, Start: day, Resets: day.AddDate(0, 0, 1)}
+
+	tests := []struct {
+		name           string
+		exceeded       *budget.Exceeded
+		existing       string
+		wantChecksOnly bool
+		wantNotices    int
+	}{
+		{name: "within budget"},
+		{name: "over budget", exceeded: over, wantChecksOnly: true, wantNotices: 1},
+		{name: "already announced", exceeded: over, existing: "<!-- prmate-budget:owner/repo:daily:2026-10-15 -->", wantChecksOnly: true},
+		{name: "announced in an earlier period", exceeded: over, existing: "<!-- prmate-budget:owner/repo:daily:2026-10-14 -->", wantChecksOnly: true, wantNotices: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var notices []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/pulls/42"):
+					w.Write([]byte(`{"number":42,"head":{"ref":"feature","sha":"head123"}}`))
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/issues/42/comments"):
+					fmt.Fprintf(w, `[{"id":1,"body":%q}]`, tt.existing)
+				case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/issues/42/comments"):
+					var c struct {
+						Body string `json:"body"`
+					}
+					_ = json.NewDecoder(r.Body).Decode(&c)
+					notices = append(notices, c.Body)
+					w.Write([]byte(`{"id":2}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+			gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reviews := &MockReviewService{}
+			p := NewProcessor(&MockPRWorkspace{}, nil, reviews, gh)
+			p.SetBudget(fakeBudget{exceeded: tt.exceeded})
+
+			if _, err := p.ReviewPullRequest(context.Background(), "owner", "repo", 42); err != nil {
+				t.Fatalf("ReviewPullRequest() error = %v", err)
+			}
+			if reviews.reviewReq.ChecksOnly != tt.wantChecksOnly {
+				t.Errorf("ChecksOnly = %v, want %v", reviews.reviewReq.ChecksOnly, tt.wantChecksOnly)
+			}
+			if len(notices) != tt.wantNotices {
+				t.Fatalf("notices = %q, want %d", notices, tt.wantNotices)
+			}
+			if tt.wantNotices > 0 && !strings.Contains(notices[0], "1200 of its daily budget of 1000") {
+				t.Errorf("notice = %q", notices[0])
+			}
+		})
+	}
+}
+
+type fakeConflictChecker struct {
+	checked []int
+}
+
+func (f *fakeConflictChecker) Check(ctx context.Context, owner, repo string, prNumber int) (bool, error) {
+	f.checked = append(f.checked, prNumber)
+	return false, nil
+}
+
+func TestProcessor_Process_PushChecksConflicts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"number":1,"base":{"ref":"main"}},{"number":2,"base":{"ref":"release"}},{"number":3,"base":{"ref":"main"}}]`))
+	}))
+	defer srv.Close()
+	gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checker := &fakeConflictChecker{}
+	p := NewProcessor(&MockPRWorkspace{}, nil, nil, gh)
+	p.SetConflictChecker(checker)
+
+	for _, ref := range []string{"refs/heads/main", "refs/tags/v1.0.0"} {
+		payload, _ := json.Marshal(map[string]interface{}{
+			"ref":        ref,
+			"repository": map[string]interface{}{"full_name": "owner/repo"},
+		})
+		if err := p.Process(context.Background(), "push", payload, "test-delivery"); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+	}
+	if fmt.Sprint(checker.checked) != "[1 3]" {
+		t.Errorf("checked PRs %v, want [1 3]", checker.checked)
+	}
+}
+
+func TestProcessor_Process_PushForgetsRules(t *testing.T) {
+	reviews := &MockReviewService{}
+	p := NewProcessor(&MockPRWorkspace{}, nil, reviews, nil)
+
+	for _, push := range []map[string]any{
+		{"ref": "refs/heads/main", "commits": []any{map[string]any{"modified": []string{".prmate.md", "a.go"}}}},
+		{"ref": "refs/heads/feature", "forced": true, "commits": []any{map[string]any{"added": []string{"b.go"}}}},
+		{"ref": "refs/tags/v1.0.0"},
+	} {
+		push["repository"] = map[string]any{"full_name": "owner/repo"}
+		payload, _ := json.Marshal(push)
+		if err := p.Process(context.Background(), "push", payload, "test-delivery"); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+	}
+	want := "[owner/repo@main [.prmate.md a.go] owner/repo@feature []]"
+	if got := fmt.Sprint(reviews.forgotten); got != want {
+		t.Errorf("forgotten = %s, want %s", got, want)
+	}
+}
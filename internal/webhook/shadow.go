@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+// SetShadowMode runs every review in full while posting nothing: the
+// GitHub client must be in dry-run mode too. Reviews are recorded with
+// their findings, so they can be compared with human reviews of the same
+// pull requests, and are not held for a posting window.
+func (p *Processor) SetShadowMode(on bool) {
+	p.shadow = on
+}
+
+// ShadowStats returns how many reviews ran in shadow mode since the start,
+// and the findings they would have posted
+func (p *Processor) ShadowStats() (reviews, findings int64) {
+	return p.shadowReviews.Load(), p.shadowFindings.Load()
+}
+
+// recordShadow marks rec as a shadow review, keeping the findings of
+// result in place of the comments it did not post
+func (p *Processor) recordShadow(rec *store.ReviewRecord, result *review.ReviewResult) {
+	rec.Shadow = true
+	rec.CommentsPosted = 0
+	p.shadowReviews.Add(1)
+	if result == nil {
+		return
+	}
+	p.shadowFindings.Add(int64(len(result.Violations)))
+	for _, v := range result.Violations {
+		rec.Findings = append(rec.Findings, store.Finding{Path: v.Path, Line: v.Line, Rule: v.Rule, Severity: v.Severity})
+	}
+}
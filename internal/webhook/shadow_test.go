@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"prmate/internal/review"
+	"prmate/internal/store"
+)
+
+func TestProcessor_RecordShadowReview(t *testing.T) {
+	records, err := store.OpenFileStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewProcessor(&MockPRWorkspace{}, nil, nil, nil)
+	p.SetRecorder("default", records)
+	p.SetShadowMode(true)
+
+	req := review.ReviewRequest{Owner: "owner", Repo: "repo", PRNumber: 7, HeadSHA: "abc"}
+	result := &review.ReviewResult{
+		CommentsPosted:  2,
+		ViolationsFound: 2,
+		Blocking:        true,
+		Violations: []review.FileViolation{
+			{Path: "a.go", Line: 3, Rule: "errors", Severity: "error", Message: "Wrap it"},
+			{Path: "b.go", Line: 9, Rule: "naming", Severity: "warning"},
+		},
+	}
+	p.recordReview(context.Background(), req, time.Now(), result, nil)
+
+	got, err := records.ListReviews(context.Background(), store.Filter{})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("ListReviews() = %v, %v", got, err)
+	}
+	rec := got[0]
+	if !rec.Shadow || !rec.Blocking || rec.CommentsPosted != 0 || rec.ViolationsFound != 2 {
+		t.Errorf("record = %+v, want a blocking shadow review posting nothing", rec)
+	}
+	if len(rec.Findings) != 2 || rec.Findings[0] != (store.Finding{Path: "a.go", Line: 3, Rule: "errors", Severity: "error"}) {
+		t.Errorf("Findings = %+v", rec.Findings)
+	}
+	if reviews, findings := p.ShadowStats(); reviews != 1 || findings != 2 {
+		t.Errorf("ShadowStats() = %d, %d, want 1, 2", reviews, findings)
+	}
+}
@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"context"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/logging"
+	"prmate/internal/review"
+	"prmate/internal/smoke"
+)
+
+// SmokeRunner runs build and test commands in a checked out pull request;
+// smoke.Runner satisfies it
+type SmokeRunner interface {
+	Run(ctx context.Context, dir string) []smoke.Failure
+}
+
+// SetSmoke runs r in the workspace of each pull request before it is
+// reviewed, reporting the commands that fail as findings
+func (p *Processor) SetSmoke(r SmokeRunner) {
+	p.smoke = r
+}
+
+// applySmoke runs the smoke checks in the workspace of pr, adding their
+// failures to req. Pull requests from forks are not checked, since the
+// commands run their code on the server. Failures to prepare the workspace
+// are logged, and the review then goes without smoke checks.
+func (p *Processor) applySmoke(ctx context.Context, req *review.ReviewRequest, pr *ghclient.PullRequest) {
+	if p.smoke == nil || p.prWorkspace == nil {
+		return
+	}
+	logger := logging.FromContext(ctx)
+	if pr.FromFork() {
+		logger.Info("Skipping smoke checks of a pull request from a fork", "head_repo", pr.HeadRepo)
+		return
+	}
+
+	dir, err := p.prWorkspace.EnsurePRDir(ctx, req.Owner+"/"+req.Repo, req.PRNumber)
+	if err != nil {
+		logger.Warn("could not prepare workspace for smoke checks", "error", err)
+		return
+	}
+	req.Smoke = p.smoke.Run(ctx, dir)
+	if len(req.Smoke) > 0 {
+		logger.Info("Smoke checks failed", "failed", len(req.Smoke))
+	}
+}
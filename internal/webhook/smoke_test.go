@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/review"
+	"prmate/internal/smoke"
+)
+
+// fakeSmoke fails every run, recording the directories it ran in
+type fakeSmoke struct {
+	dirs []string
+}
+
+func (f *fakeSmoke) Run(ctx context.Context, dir string) []smoke.Failure {
+	f.dirs = append(f.dirs, dir)
+	return []smoke.Failure{{Command: "go vet ./...", Output: "main.go:3:1: missing return"}}
+}
+
+func TestProcessor_ApplySmoke(t *testing.T) {
+	tests := []struct {
+		name     string
+		pr       ghclient.PullRequest
+		wantDirs int
+	}{
+		{name: "same repository", pr: ghclient.PullRequest{Number: 7, HeadRepo: "owner/repo", BaseRepo: "owner/repo"}, wantDirs: 1},
+		{name: "fork", pr: ghclient.PullRequest{Number: 7, HeadRepo: "someone/repo", BaseRepo: "owner/repo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := &MockPRWorkspace{}
+			runner := &fakeSmoke{}
+			p := NewProcessor(ws, nil, nil, nil)
+			p.SetSmoke(runner)
+
+			req := review.ReviewRequest{Owner: "owner", Repo: "repo", PRNumber: 7}
+			p.applySmoke(context.Background(), &req, &tt.pr)
+
+			if len(runner.dirs) != tt.wantDirs || len(req.Smoke) != tt.wantDirs {
+				t.Fatalf("ran in %q with failures %+v, want %d runs", runner.dirs, req.Smoke, tt.wantDirs)
+			}
+			if tt.wantDirs > 0 && runner.dirs[0] != "/tmp/owner/repo/7" {
+				t.Errorf("ran in %q, want the pull request's workspace", runner.dirs[0])
+			}
+		})
+	}
+}
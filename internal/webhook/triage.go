@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/logging"
+	"prmate/internal/triage"
+)
+
+// triageMarker marks triage comments, so redelivered issue events are not
+// triaged twice
+const triageMarker = "<!-- prmate-triage -->"
+
+// Triager classifies new issues; triage.Triager satisfies it
+type Triager interface {
+	Triage(ctx context.Context, req triage.Request) (*triage.Result, error)
+}
+
+// SetTriager has t triage newly opened issues with a comment
+func (p *Processor) SetTriager(t Triager) {
+	p.triager = t
+}
+
+// handleIssues triages opened issues. Issues opened by bots and the reports
+// PRMate files itself, labeled prmate-*, are left alone, as are repositories
+// over their token budget.
+func (p *Processor) handleIssues(ctx context.Context, e *ghclient.IssuesEvent) error {
+	if p.triager == nil || p.githubClient == nil || !strings.EqualFold(e.Action, "opened") {
+		return nil
+	}
+	if strings.HasSuffix(e.Author, "[bot]") {
+		return nil
+	}
+	for _, l := range e.Labels {
+		if strings.HasPrefix(l, "prmate") {
+			return nil
+		}
+	}
+
+	owner, repo, err := ghclient.ParseRepoFullName(e.Repo)
+	if err != nil {
+		return fmt.Errorf("parse repo name: %w", err)
+	}
+	ctx = logging.With(ctx, "repo", e.Repo, "issue", e.Number)
+	logger := logging.FromContext(ctx)
+
+	if p.budget != nil {
+		exceeded, err := p.budget.Check(ctx, owner, repo)
+		if err != nil {
+			logger.Warn("failed to check token budget", "error", err)
+		} else if exceeded != nil {
+			logger.Info("Token budget exceeded, not triaging issue", "budget", exceeded.Key())
+			return nil
+		}
+	}
+
+	comments, err := p.githubClient.ListPRComments(ctx, owner, repo, e.Number)
+	if err != nil {
+		return fmt.Errorf("list issue comments: %w", err)
+	}
+	for _, c := range comments {
+		if strings.Contains(c, triageMarker) {
+			return nil
+		}
+	}
+
+	result, err := p.triager.Triage(ctx, triage.Request{Owner: owner, Repo: repo, Number: e.Number, Title: e.Title, Body: e.Body})
+	if err != nil {
+		return fmt.Errorf("triage issue: %w", err)
+	}
+	if result == nil {
+		logger.Info("No .prmate.md found, skipping triage")
+		return nil
+	}
+	if err := p.githubClient.CreatePRComment(ctx, owner, repo, e.Number, triageComment(result)); err != nil {
+		return fmt.Errorf("post triage comment: %w", err)
+	}
+	return nil
+}
+
+// triageKinds describe each kind of issue in the triage comment
+var triageKinds = map[string]string{
+	triage.KindBug:      "🐛 This looks like a **bug report**.",
+	triage.KindFeature:  "✨ This looks like a **feature request**.",
+	triage.KindQuestion: "❓ This looks like a **question**.",
+}
+
+// triageComment formats a triage for the issue
+func triageComment(r *triage.Result) string {
+	var sb strings.Builder
+	sb.WriteString(triageMarker + "\n")
+	sb.WriteString(triageKinds[r.Kind])
+	if r.Summary != "" {
+		sb.WriteString(" " + r.Summary)
+	}
+	sb.WriteString("\n")
+	if len(r.Labels) > 0 {
+		sb.WriteString("\n**Suggested labels:** `" + strings.Join(r.Labels, "`, `") + "`\n")
+	}
+	if len(r.Files) > 0 {
+		sb.WriteString("\n**Likely relevant files:**\n")
+		for _, f := range r.Files {
+			sb.WriteString("- `" + f + "`\n")
+		}
+	}
+	if len(r.Owners) > 0 {
+		// Owners are named without @ so the triage does not ping them
+		sb.WriteString("\n**Code owners:** `" + strings.Join(r.Owners, "`, `") + "`\n")
+	}
+	sb.WriteString("\n<sub>Triaged by PRMate from the codebase context in `.prmate.md`. It may be wrong; maintainers decide.</sub>")
+	return sb.String()
+}
@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ghclient "prmate/internal/github"
+	"prmate/internal/triage"
+)
+
+type fakeTriager struct {
+	requests []triage.Request
+}
+
+func (f *fakeTriager) Triage(ctx context.Context, req triage.Request) (*triage.Result, error) {
+	f.requests = append(f.requests, req)
+	return &triage.Result{
+		Kind:    triage.KindBug,
+		Summary: "Login crashes.",
+		Labels:  []string{"bug"},
+		Files:   []string{"auth/login.go"},
+		Owners:  []string{"@acme/auth"},
+	}, nil
+}
+
+func TestProcessor_TriagesOpenedIssues(t *testing.T) {
+	var comments []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/issues/9/comments") {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodPost {
+			var c struct {
+				Body string `json:"body"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&c)
+			comments = append(comments, c.Body)
+			w.Write([]byte(`{"id":1}`))
+			return
+		}
+		var list []map[string]string
+		for _, c := range comments {
+			list = append(list, map[string]string{"body": c})
+		}
+		json.NewEncoder(w).Encode(list)
+	}))
+	defer srv.Close()
+	gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	triager := &fakeTriager{}
+	p := NewProcessor(&MockPRWorkspace{}, nil, &MockReviewService{}, gh)
+	p.SetTriager(triager)
+
+	event := func(action, author string, labels ...string) []byte {
+		var ls []map[string]string
+		for _, l := range labels {
+			ls = append(ls, map[string]string{"name": l})
+		}
+		payload, _ := json.Marshal(map[string]any{
+			"action":     action,
+			"issue":      map[string]any{"number": 9, "title": "Login crash", "body": "It crashes", "user": map[string]any{"login": author}, "labels": ls},
+			"repository": map[string]any{"full_name": "acme/api"},
+		})
+		return payload
+	}
+	for _, payload := range [][]byte{
+		event("opened", "octocat"),
+		event("opened", "octocat"), // redelivered
+		event("edited", "octocat"),
+		event("opened", "dependabot[bot]"),
+		event("opened", "prmate", "prmate-report"),
+	} {
+		if err := p.Process(context.Background(), "issues", payload, "d1"); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+	}
+
+	if len(triager.requests) != 1 {
+		t.Fatalf("Triage called %d times, want once", len(triager.requests))
+	}
+	if req := triager.requests[0]; req.Owner != "acme" || req.Repo != "api" || req.Number != 9 || req.Title != "Login crash" || req.Body != "It crashes" {
+		t.Errorf("Triage request = %+v", req)
+	}
+	if len(comments) != 1 {
+		t.Fatalf("comments = %q, want one", comments)
+	}
+	for _, want := range []string{triageMarker, "**bug report**. Login crashes.", "`bug`", "- `auth/login.go`", "`@acme/auth`"} {
+		if !strings.Contains(comments[0], want) {
+			t.Errorf("comment does not contain %q:\n%s", want, comments[0])
+		}
+	}
+}
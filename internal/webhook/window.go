@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"prmate/internal/errreport"
+	"prmate/internal/jobs"
+	"prmate/internal/logging"
+)
+
+// JobSubmitter runs work in the background; jobs.Queue satisfies it
+type JobSubmitter interface {
+	Submit(ctx context.Context, kind string, fn jobs.Func) (string, error)
+}
+
+// SetJobs runs reviews held for a posting window on jobs once it opens, so
+// they share its workers instead of all starting at the same moment
+func (p *Processor) SetJobs(j JobSubmitter) {
+	p.jobs = j
+}
+
+// HeldReviews returns how many pull requests wait for their posting window
+func (p *Processor) HeldReviews() int {
+	p.heldMu.Lock()
+	defer p.heldMu.Unlock()
+	return len(p.held)
+}
+
+// postingWindowOpens returns when the posting window .prmate.md at ref
+// declares opens next, and whether it is closed now
+func (p *Processor) postingWindowOpens(ctx context.Context, owner, repo, ref string) (time.Time, bool) {
+	// A shadow review posts nothing, so it needs no window
+	if p.reviewService == nil || p.githubClient == nil || p.shadow {
+		return time.Time{}, false
+	}
+	window, err := p.reviewService.PostingWindow(ctx, owner, repo, ref)
+	if err != nil {
+		logging.FromContext(ctx).Warn("could not read posting window, posting now", "error", err)
+		return time.Time{}, false
+	}
+	now := p.now()
+	opens := window.NextOpen(now)
+	return opens, opens.After(now)
+}
+
+// hold runs the checks of a pull request once its posting window opens.
+// A pull request is held once however often it is pushed to meanwhile; the
+// review then covers its head at that time. Held reviews live in memory, so
+// after a restart the next push brings them back.
+func (p *Processor) hold(ctx context.Context, owner, repo string, prNumber int, opens time.Time) {
+	key := fmt.Sprintf("%s/%s#%d", owner, repo, prNumber)
+	logger := logging.FromContext(ctx)
+
+	p.heldMu.Lock()
+	defer p.heldMu.Unlock()
+	if _, ok := p.held[key]; ok {
+		logger.Info("Review already held for the posting window", "opens", opens)
+		return
+	}
+	p.held[key] = opens
+	logger.Info("Posting window closed, holding review", "opens", opens)
+
+	ctx = context.WithoutCancel(ctx)
+	time.AfterFunc(opens.Sub(p.now()), func() {
+		p.heldMu.Lock()
+		delete(p.held, key)
+		p.heldMu.Unlock()
+		p.release(ctx, owner, repo, prNumber)
+	})
+}
+
+// release runs the checks of a held pull request, on the job queue when
+// there is one
+func (p *Processor) release(ctx context.Context, owner, repo string, prNumber int) {
+	logger := logging.FromContext(ctx)
+	run := func(ctx context.Context) (any, error) {
+		pr, err := p.githubClient.GetPullRequest(ctx, owner, repo, prNumber)
+		if err != nil {
+			return nil, fmt.Errorf("get pull request: %w", err)
+		}
+		if pr.State != "open" {
+			logging.FromContext(ctx).Info("Held pull request was closed, skipping review")
+			return nil, nil
+		}
+		// .prmate.md may have moved the window since the review was held
+		if opens, closed := p.postingWindowOpens(ctx, owner, repo, pr.ConfigRef()); closed {
+			p.hold(ctx, owner, repo, prNumber, opens)
+			return nil, nil
+		}
+		return nil, p.checkPullRequest(ctx, owner, repo, pr)
+	}
+
+	logger.Info("Posting window open, running held review")
+	if p.jobs != nil {
+		_, err := p.jobs.Submit(ctx, "held-review", run)
+		if err == nil {
+			return
+		}
+		logger.Warn("could not queue held review, running it now", "error", err)
+	}
+	if _, err := run(ctx); err != nil {
+		logger.Error("held review failed", "error", err)
+		errreport.Capture(ctx, fmt.Errorf("held review: %w", err))
+	}
+}
@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"prmate/internal/checks"
+	ghclient "prmate/internal/github"
+	"prmate/internal/jobs"
+)
+
+// fakeJobs runs submitted work at once and reports its kind on done
+type fakeJobs struct {
+	done chan string
+}
+
+func (f *fakeJobs) Submit(ctx context.Context, kind string, fn jobs.Func) (string, error) {
+	_, err := fn(ctx)
+	if err != nil {
+		kind += ": " + err.Error()
+	}
+	f.done <- kind
+	return "job-1", nil
+}
+
+func TestProcessor_HoldsReviewOutsidePostingWindow(t *testing.T) {
+	set, err := checks.Parse("```prmate-checks\npost-hours: Mon-Fri 09:00-17:00\n```\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/pulls/1") {
+			w.Write([]byte(`{"number":1,"state":"open","head":{"ref":"feature","sha":"head123"}}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	gh, err := ghclient.NewEnterpriseClient("token", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reviews := &MockReviewService{hasPRMate: true, window: set.Window}
+	jobQueue := &fakeJobs{done: make(chan string, 1)}
+	p := NewProcessor(&MockPRWorkspace{}, nil, reviews, gh)
+	p.SetJobs(jobQueue)
+	// A Thursday morning, 100ms before posting opens
+	base, start := time.Date(2026, 10, 15, 8, 59, 59, 900_000_000, time.UTC), time.Now()
+	p.now = func() time.Time { return base.Add(time.Since(start)) }
+
+	payload, _ := json.Marshal(map[string]any{
+		"action":       "synchronize",
+		"pull_request": map[string]any{"number": 1, "head": map[string]any{"ref": "feature"}},
+		"repository":   map[string]any{"full_name": "owner/repo"},
+	})
+	for range 2 {
+		if err := p.Process(context.Background(), "pull_request", payload, "d1"); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+	}
+	if held := p.HeldReviews(); held != 1 {
+		t.Fatalf("HeldReviews() = %d, want the pull request held once", held)
+	}
+
+	select {
+	case kind := <-jobQueue.done:
+		if kind != "held-review" {
+			t.Fatalf("submitted %q, want held-review", kind)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("held review never ran")
+	}
+	if !reviews.reviewCalled || reviews.reviewReq.HeadSHA != "head123" {
+		t.Errorf("review = %v %+v, want the head at release reviewed", reviews.reviewCalled, reviews.reviewReq)
+	}
+	if held := p.HeldReviews(); held != 0 {
+		t.Errorf("HeldReviews() = %d after release, want 0", held)
+	}
+}
@@ -0,0 +1,293 @@
+// Package workflow finds security problems in GitHub Actions workflows:
+// pull_request_target and workflow_run jobs checking out the code of a pull
+// request, secrets handed to that code, third-party actions not pinned to a
+// commit, and permissions granted more widely than needed. It reads the
+// workflow itself, so findings need no LLM and are the same on every run.
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"prmate/internal/scanner"
+)
+
+// Rule names of workflow findings; the LLM review of a workflow reports
+// the same problems under the same names
+const (
+	RuleUntrustedCheckout = "Untrusted checkout"
+	RuleForkSecrets       = "Secrets exposed to forks"
+	RuleUnpinnedAction    = "Unpinned action"
+	RuleBroadPermissions  = "Broad permissions"
+)
+
+// Finding is a security problem on one line of a workflow
+type Finding struct {
+	Line     int
+	Rule     string
+	Message  string
+	Severity string
+}
+
+var (
+	// untrustedRef matches expressions naming the code of a pull request
+	// rather than of the base repository
+	untrustedRef = regexp.MustCompile(`github\.event\.pull_request\.head\.|github\.head_ref|github\.event\.workflow_run\.head_|refs/pull/`)
+	secretRef    = regexp.MustCompile(`secrets\.([A-Za-z_][A-Za-z0-9_-]*)|secrets\[`)
+)
+
+// privilegedTriggers run with the base repository's secrets and a write
+// token even for pull requests from forks
+var privilegedTriggers = []string{"pull_request_target", "workflow_run"}
+
+// trustedOwners maintain actions that need no pinning
+var trustedOwners = map[string]bool{"actions": true, "github": true}
+
+// Scan reports the problems in a workflow, ordered by line. Content that
+// is not a YAML mapping yields none.
+func Scan(content string) []Finding {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	trigger := privilegedTrigger(get(root, "on"))
+	jobs := get(root, "jobs")
+	var findings []Finding
+	findings = append(findings, permissionFindings(get(root, "permissions"), trigger, jobs != nil && len(jobs.Content) > 2)...)
+	if jobs != nil && jobs.Kind == yaml.MappingNode {
+		for i := 1; i < len(jobs.Content); i += 2 {
+			findings = append(findings, jobFindings(jobs.Content[i], trigger)...)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	return findings
+}
+
+// privilegedTrigger returns the first privileged event in an on: block,
+// or "" when the workflow has none
+func privilegedTrigger(on *yaml.Node) string {
+	if on == nil {
+		return ""
+	}
+	var events []string
+	switch on.Kind {
+	case yaml.ScalarNode:
+		events = []string{on.Value}
+	case yaml.SequenceNode:
+		for _, n := range on.Content {
+			events = append(events, n.Value)
+		}
+	case yaml.MappingNode:
+		for i := 0; i < len(on.Content); i += 2 {
+			events = append(events, on.Content[i].Value)
+		}
+	}
+	for _, e := range events {
+		for _, p := range privilegedTriggers {
+			if e == p {
+				return e
+			}
+		}
+	}
+	return ""
+}
+
+// permissionFindings reports a permissions: block granting write-all, or a
+// workflow-wide block granting write access to the jobs of a workflow
+// with several
+func permissionFindings(perms *yaml.Node, trigger string, workflowWide bool) []Finding {
+	if perms == nil {
+		return nil
+	}
+	severity := "warning"
+	if trigger != "" {
+		severity = "error"
+	}
+	if perms.Kind == yaml.ScalarNode && perms.Value == "write-all" {
+		return []Finding{{
+			Line:     perms.Line,
+			Rule:     RuleBroadPermissions,
+			Message:  "write-all gives GITHUB_TOKEN write access to everything in the repository. Grant only the scopes the job needs, for example `contents: read`.",
+			Severity: severity,
+		}}
+	}
+	if !workflowWide || perms.Kind != yaml.MappingNode {
+		return nil
+	}
+	var scopes []string
+	line := 0
+	for i := 0; i+1 < len(perms.Content); i += 2 {
+		if perms.Content[i+1].Value == "write" {
+			scopes = append(scopes, perms.Content[i].Value)
+			if line == 0 {
+				line = perms.Content[i+1].Line
+			}
+		}
+	}
+	if len(scopes) == 0 {
+		return nil
+	}
+	return []Finding{{
+		Line:     line,
+		Rule:     RuleBroadPermissions,
+		Message:  fmt.Sprintf("Write access to %s is granted to every job of the workflow. Grant it in the permissions: of the jobs that need it instead.", strings.Join(scopes, ", ")),
+		Severity: "warning",
+	}}
+}
+
+// jobFindings reports the problems of one job
+func jobFindings(job *yaml.Node, trigger string) []Finding {
+	if job.Kind != yaml.MappingNode {
+		return nil
+	}
+	findings := permissionFindings(get(job, "permissions"), trigger, false)
+	// A job calling a reusable workflow has uses: of its own
+	if f, ok := unpinned(get(job, "uses")); ok {
+		findings = append(findings, f)
+	}
+
+	untrusted := false
+	if steps := get(job, "steps"); steps != nil && steps.Kind == yaml.SequenceNode {
+		for _, step := range steps.Content {
+			if step.Kind != yaml.MappingNode {
+				continue
+			}
+			uses := get(step, "uses")
+			if f, ok := unpinned(uses); ok {
+				findings = append(findings, f)
+			}
+			if trigger == "" || uses == nil || !strings.HasPrefix(uses.Value, "actions/checkout@") {
+				continue
+			}
+			if ref := untrustedCheckout(get(step, "with")); ref != nil {
+				untrusted = true
+				findings = append(findings, Finding{
+					Line:     ref.Line,
+					Rule:     RuleUntrustedCheckout,
+					Message:  fmt.Sprintf("On %s this job runs with the repository's secrets and a write token, and this checks out the pull request's code: a pull request from a fork can then run anything with them. Review the code in a separate pull_request workflow, or never build or run what is checked out.", trigger),
+					Severity: "error",
+				})
+			}
+		}
+	}
+
+	// Once the job runs code from the pull request, every secret it can
+	// reach is the fork's
+	if untrusted {
+		for _, s := range secretLines(job) {
+			findings = append(findings, Finding{
+				Line:     s.line,
+				Rule:     RuleForkSecrets,
+				Message:  fmt.Sprintf("%s is available to a job that runs code from the pull request, so a fork can read it. Move its use to a job that does not check out the pull request.", s.name),
+				Severity: "error",
+			})
+		}
+	}
+	return findings
+}
+
+// unpinned reports a uses: reference to a third-party action or reusable
+// workflow that is not pinned to a full commit SHA
+func unpinned(uses *yaml.Node) (Finding, bool) {
+	if uses == nil || uses.Kind != yaml.ScalarNode {
+		return Finding{}, false
+	}
+	ref := uses.Value
+	owner, _, ok := strings.Cut(ref, "/")
+	if !ok || strings.HasPrefix(ref, "./") || strings.HasPrefix(ref, "docker://") || trustedOwners[owner] {
+		return Finding{}, false
+	}
+	name, version, ok := strings.Cut(ref, "@")
+	if !ok || scanner.ActionPinned(ref) {
+		return Finding{}, false
+	}
+	return Finding{
+		Line:     uses.Line,
+		Rule:     RuleUnpinnedAction,
+		Message:  fmt.Sprintf("%s is used at %s, which its owner can move to other code. Pin it to a full commit SHA, keeping the version in a comment.", name, version),
+		Severity: "warning",
+	}, true
+}
+
+// untrustedCheckout returns the with: value of a checkout step naming the
+// pull request's code, or nil
+func untrustedCheckout(with *yaml.Node) *yaml.Node {
+	for _, key := range []string{"ref", "repository"} {
+		if v := get(with, key); v != nil && untrustedRef.MatchString(v.Value) {
+			return v
+		}
+	}
+	return nil
+}
+
+type secretUse struct {
+	line int
+	name string
+}
+
+// secretLines finds the secrets a job refers to, other than GITHUB_TOKEN,
+// and secrets: inherit
+func secretLines(n *yaml.Node) []secretUse {
+	var uses []secretUse
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if k, v := n.Content[i], n.Content[i+1]; k.Value == "secrets" && v.Value == "inherit" {
+					uses = append(uses, secretUse{line: v.Line, name: "Every secret, passed with secrets: inherit,"})
+				}
+			}
+		}
+		if n.Kind == yaml.ScalarNode {
+			for i, text := range strings.Split(n.Value, "\n") {
+				for _, m := range secretRef.FindAllStringSubmatch(text, -1) {
+					if strings.EqualFold(m[1], "GITHUB_TOKEN") {
+						continue
+					}
+					name := "A secret"
+					if m[1] != "" {
+						name = "secrets." + m[1]
+					}
+					uses = append(uses, secretUse{line: scalarLine(n, i), name: name})
+				}
+			}
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(n)
+	return uses
+}
+
+// scalarLine returns the file line of line i of a scalar's value. A block
+// scalar starts on the line after its | or > indicator.
+func scalarLine(n *yaml.Node, i int) int {
+	if n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return n.Line + 1 + i
+	}
+	return n.Line + i
+}
+
+// get returns the value of key in a mapping node, or nil
+func get(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow string
+		want     []string // line:rule:severity
+	}{
+		{
+			name: "pull_request_target checking out the pull request",
+			workflow: `on: pull_request_target
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env:
+      TOKEN: ${{ secrets.GITHUB_TOKEN }}
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ref: ${{ github.event.pull_request.head.sha }}
+      - run: |
+          make test
+          curl -H "Authorization: ${{ secrets.DEPLOY_KEY }}" https://example.com
+`,
+			want: []string{"10:Untrusted checkout:error", "13:Secrets exposed to forks:error"},
+		},
+		{
+			name: "pull_request_target checking out the base",
+			workflow: `on:
+  pull_request_target:
+    types: [labeled]
+jobs:
+  label:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: ./label.sh ${{ secrets.BOT_TOKEN }}
+`,
+		},
+		{
+			name: "pull_request checkout is not privileged",
+			workflow: `on: [pull_request]
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ref: ${{ github.event.pull_request.head.sha }}
+      - run: echo ${{ secrets.NPM_TOKEN }}
+`,
+		},
+		{
+			name: "workflow_run with inherited secrets",
+			workflow: `on:
+  workflow_run:
+    workflows: [CI]
+jobs:
+  report:
+    runs-on: ubuntu-latest
+    secrets: inherit
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ref: ${{ github.event.workflow_run.head_sha }}
+`,
+			want: []string{"7:Secrets exposed to forks:error", "11:Untrusted checkout:error"},
+		},
+		{
+			name: "third-party actions",
+			workflow: `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/setup-go@v5
+      - uses: golangci/golangci-lint-action@v6
+      - uses: docker/login-action@9780b0c442fbb1117ed29e0efdff1e18412f7567 # v3
+      - uses: ./.github/actions/local
+      - uses: docker://alpine:3.20
+  release:
+    uses: acme/workflows/.github/workflows/release.yml@main
+`,
+			want: []string{"7:Unpinned action:warning", "12:Unpinned action:warning"},
+		},
+		{
+			name: "permissions",
+			workflow: `on: pull_request_target
+permissions:
+  contents: read
+  pull-requests: write
+jobs:
+  a:
+    runs-on: ubuntu-latest
+    permissions: write-all
+    steps:
+      - run: echo a
+  b:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo b
+`,
+			want: []string{"4:Broad permissions:warning", "8:Broad permissions:error"},
+		},
+		{
+			name: "workflow-wide write with one job",
+			workflow: `on: push
+permissions:
+  contents: write
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo release
+`,
+		},
+		{name: "not yaml", workflow: "on: [push\njobs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range Scan(tt.workflow) {
+				got = append(got, fmt.Sprintf("%d:%s:%s", f.Line, f.Rule, f.Severity))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"prmate/internal/github"
+	"prmate/internal/learn"
+)
+
+const learnUsage = `Usage: prmate learn --repo <owner/repo> [--prs <n>] [--instance <name>] [flags]
+
+Reads the review feedback and reverts of the repository's most recently
+merged pull requests and proposes a Learned Rules section for .prmate.md
+from the feedback that recurs. The section is printed for you to review
+and add to .prmate.md; the pull requests each rule came from go to stderr.
+`
+
+// runLearn implements `prmate learn`
+func runLearn(args []string) int {
+	var repoName, instanceName string
+	var limit int
+	cfg, _, err := parseFlags("learn", learnUsage, args, func(fs *flag.FlagSet) {
+		fs.StringVar(&repoName, "repo", "", "Repository to learn from, as owner/repo")
+		fs.IntVar(&limit, "prs", 50, "Number of recently merged pull requests to read")
+		fs.StringVar(&instanceName, "instance", "default", "SCM instance the repository lives on (see --scm-instances)")
+	})
+	if err != nil {
+		return exitCode(err)
+	}
+	if cfg.Offline {
+		fmt.Fprintln(os.Stderr, "prmate learn needs the LLM; it cannot run in offline mode")
+		return 2
+	}
+
+	owner, repo, err := github.ParseRepoFullName(repoName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--repo: %v\n", err)
+		return 2
+	}
+	inst, err := findInstance(cfg, instanceName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	githubClient, err := newInstanceClient(inst)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "create github client: %v\n", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	llmSvc, err := startLLM(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer llmSvc.Stop()
+
+	proposal, err := learn.NewLearner(githubClient, llmSvc).Learn(ctx, owner, repo, limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "learn: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "Read %d merged pull requests: %d review comments, %d reverts\n", proposal.PRsScanned, proposal.Feedback, proposal.Reverts)
+	if len(proposal.Rules) == 0 {
+		fmt.Fprintln(os.Stderr, "No recurring feedback found.")
+		return 0
+	}
+	for _, r := range proposal.Rules {
+		prs := make([]string, len(r.PRs))
+		for i, n := range r.PRs {
+			prs[i] = fmt.Sprintf("#%d", n)
+		}
+		fmt.Fprintf(os.Stderr, "  %s: %s\n", r.Rule, strings.Join(prs, ", "))
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprint(os.Stdout, proposal.Markdown())
+	return 0
+}
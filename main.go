@@ -2,103 +2,217 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
+	"time"
 
 	"prmate/internal/config"
 	"prmate/internal/copilot"
-	"prmate/internal/github"
-	"prmate/internal/handlers"
 	"prmate/internal/llm"
-	"prmate/internal/prworkspace"
+	"prmate/internal/logging"
 	"prmate/internal/review"
-	"prmate/internal/scan"
-	"prmate/internal/server"
-	"prmate/internal/weather"
-	"prmate/internal/webhook"
 )
 
 // LLMService defines the interface for LLM providers used by the application
 type LLMService interface {
 	GenerateText(prompt string) (string, error)
+	GenerateTextWithContext(ctx context.Context, prompt string) (string, error)
+	Ping(ctx context.Context) error
 	Start() error
 	Stop() error
 }
 
+// command is one prmate subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+// commands lists the subcommands in the order `prmate help` shows them
+func commands() []command {
+	return []command{
+		{"serve", "Run the webhook server (default)", runServe},
+		{"review", "Review a local diff and print findings", runReview},
+		{"review-all", "Review every open pull request not yet reviewed at its head", runReviewAll},
+		{"hook", "Check staged changes before committing (git pre-commit hook)", runHook},
+		{"scan", "Scan a checkout or remote repo and write .prmate.md", runScan},
+		{"learn", "Propose Learned Rules from a repo's merged pull requests", runLearn},
+		{"validate", "Check configuration and a .prmate.md file", runValidate},
+		{"ci", "Review the pull request of a GitHub Actions run once", runCI},
+		{"eval", "Compare prompt or model configurations on recorded pull requests", runEval},
+	}
+}
+
 func main() {
-	// Load configuration
+	os.Exit(run(os.Args[1:]))
+}
+
+// run dispatches args to a subcommand. Plain `prmate [flags]` still starts
+// the server so existing deployments keep working.
+func run(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runServe(args)
+	}
+
+	name := args[0]
+	if name == "help" {
+		if len(args) > 1 {
+			return run([]string{args[1], "-h"})
+		}
+		printUsage(os.Stdout)
+		return 0
+	}
+	for _, c := range commands() {
+		if c.name == name {
+			return c.run(args[1:])
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	printUsage(os.Stderr)
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: prmate <command> [flags]\n\nCommands:\n")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(w, "\nRun 'prmate help <command>' for the flags of a command.\n")
+}
+
+// parseFlags loads configuration from the environment and the config file,
+// parses args with the shared configuration flags plus any that setup
+// registers, and sets up logging. On error the command should return
+// exitCode(err).
+func parseFlags(name, usage string, args []string, setup func(fs *flag.FlagSet)) (*config.Config, *flag.FlagSet, error) {
 	cfg := config.Load()
 
-	// Initialize LLM service based on configuration
-	var llmSvc LLMService
+	fs := flag.NewFlagSet("prmate "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	if setup != nil {
+		setup(fs)
+	}
+	cfg.RegisterFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "%s\nFlags:\n", usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid logging configuration: %v\n", err)
+		return nil, nil, err
+	}
+	return cfg, fs, nil
+}
+
+// exitCode maps a parseFlags error to a process exit code
+func exitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	return 2
+}
+
+// newLLMService returns the LLM provider selected by cfg, not yet started
+func newLLMService(cfg *config.Config) LLMService {
+	if cfg.Offline {
+		slog.Info("Offline mode: only deterministic checks run, the LLM is disabled")
+		return llm.Disabled{}
+	}
+
 	switch cfg.LLMProvider {
 	case "openai":
-		log.Printf("Using OpenAI LLM provider (model: %s)", cfg.OpenAIModel)
-		llmSvc = llm.NewOpenAIProvider(llm.OpenAIConfig{
+		slog.Info("Using OpenAI LLM provider", "model", cfg.OpenAIModel)
+		return llm.NewOpenAIProvider(llm.OpenAIConfig{
 			APIKey:  cfg.OpenAIAPIKey,
 			BaseURL: cfg.OpenAIBaseURL,
 			Model:   cfg.OpenAIModel,
+			Timeout: cfg.LLMTimeout,
+		})
+	case "ollama":
+		slog.Info("Using Ollama LLM provider", "model", cfg.OllamaModel, "url", cfg.OllamaBaseURL)
+		return llm.NewOllamaProvider(llm.OllamaConfig{
+			BaseURL: cfg.OllamaBaseURL,
+			Model:   cfg.OllamaModel,
+			Timeout: cfg.LLMTimeout,
 		})
 	default:
-		log.Printf("Using Copilot LLM provider (model: %s)", cfg.CopilotModel)
-		llmSvc = copilot.NewService(cfg.CopilotModel)
-	}
-
-	if err := llmSvc.Start(); err != nil {
-		log.Fatalf("Failed to start LLM service: %v", err)
-	}
-	defer llmSvc.Stop()
-
-	// Initialize GitHub client
-	githubClient := github.NewClient(cfg.GitHubToken)
-
-	// Initialize services
-	weatherSvc := weather.NewService()
-	prWorkspaceMgr := prworkspace.NewManager(cfg.WorkBaseDir)
-	scanSvc := scan.NewService(githubClient)
-	reviewSvc := review.NewService(githubClient, llmSvc)
-	webhookProc := webhook.NewProcessor(prWorkspaceMgr, scanSvc, reviewSvc, githubClient)
-	webhookAsync := webhook.NewAsyncProcessor(webhookProc, webhook.AsyncConfig{QueueSize: cfg.WebhookQueueSize, Workers: cfg.WebhookWorkers})
-
-	// Setup HTTP server
-	srv := server.NewServer(cfg)
-	handler := handlers.NewHandler(llmSvc, weatherSvc, webhookAsync, cfg.WebhookSecret)
-
-	// Register routes
-	srv.Router().GET("/health", handler.Health)
-	srv.Router().POST("/api/weather-joke", handler.WeatherJoke)
-	srv.Router().POST("/webhook", handler.GitHubWebhook)
-
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- srv.Start()
-	}()
-
-	// Wait for interrupt signal for graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	select {
-	case <-quit:
-		log.Println("Shutdown signal received")
-	case err := <-errCh:
-		if err != nil {
-			log.Printf("Server error: %v", err)
-		}
+		slog.Info("Using Copilot LLM provider", "model", cfg.CopilotModel)
+		copilotSvc := copilot.NewService(cfg.CopilotModel)
+		copilotSvc.SetTimeout(cfg.LLMTimeout)
+		return copilotSvc
 	}
+}
 
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
-	defer cancel()
+// newBranding returns the comment wording configured in cfg on top of
+// PRMate's defaults
+func newBranding(cfg *config.Config) (*review.Branding, error) {
+	b := review.DefaultBranding(cfg.CommentEmoji)
+	overrides, err := cfg.SeverityEmojiMap()
+	if err != nil {
+		return nil, err
+	}
+	for severity, emoji := range overrides {
+		b.SeverityEmoji[severity] = emoji
+	}
+	b.CommentPrefix = cfg.CommentPrefix
+	if cfg.ReviewHeader != "" {
+		b.ReviewHeader = cfg.ReviewHeader
+	}
+	if cfg.SummaryHeader != "" {
+		b.SummaryHeader = cfg.SummaryHeader
+	}
+	switch cfg.CommentFooter {
+	case "":
+	case "none":
+		b.Footer = ""
+	default:
+		b.Footer = cfg.CommentFooter
+	}
+	return &b, nil
+}
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("Server forced to shutdown: %v", err)
+// startLLM creates and starts the LLM provider selected by cfg
+func startLLM(cfg *config.Config) (LLMService, error) {
+	svc := newLLMService(cfg)
+	if err := svc.Start(); err != nil {
+		return nil, fmt.Errorf("start LLM service: %w", err)
 	}
+	if err := checkModel(cfg, svc); err != nil {
+		_ = svc.Stop()
+		return nil, err
+	}
+	return svc, nil
+}
 
-	if err := webhookAsync.Stop(ctx); err != nil {
-		log.Printf("Webhook processor shutdown error: %v", err)
+// checkModel looks the configured model up among those the provider
+// offers; a missing model only fails startup with MODEL_CHECK=fail
+func checkModel(cfg *config.Config, svc LLMService) error {
+	lister, ok := svc.(llm.ModelLister)
+	if !ok || cfg.ModelCheck == "off" {
+		return nil
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-	log.Println("Server exited")
+	models, err := llm.CheckModel(ctx, lister, cfg.LLMModel())
+	switch {
+	case errors.Is(err, llm.ErrUnknownModel) && cfg.ModelCheck == "fail":
+		return err
+	case err != nil:
+		slog.Warn("Could not confirm the configured LLM model", "model", cfg.LLMModel(), "error", err)
+	default:
+		slog.Info("LLM model available", "model", cfg.LLMModel(), "models", len(models))
+	}
+	return nil
 }
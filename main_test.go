@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"expvar"
+	"strings"
+	"testing"
+	"time"
+
+	"prmate/internal/breaker"
+	"prmate/internal/config"
+	"prmate/internal/health"
+	"prmate/internal/llm"
+)
+
+// listingLLM is an offline provider that can also list models
+type listingLLM struct {
+	llm.Disabled
+	models []llm.Model
+	err    error
+}
+
+func (l listingLLM) ListModels(ctx context.Context) ([]llm.Model, error) {
+	return l.models, l.err
+}
+
+func TestCheckModel(t *testing.T) {
+	offered := listingLLM{models: []llm.Model{{ID: "gpt-5-mini"}, {ID: "claude-sonnet-4.5"}}}
+
+	tests := []struct {
+		name    string
+		check   string
+		model   string
+		svc     LLMService
+		wantErr bool
+	}{
+		{name: "model offered", check: "fail", model: "gpt-5-mini", svc: offered},
+		{name: "model missing warns", check: "warn", model: "gpt-9", svc: offered},
+		{name: "model missing fails", check: "fail", model: "gpt-9", svc: offered, wantErr: true},
+		{name: "check off", check: "off", model: "gpt-9", svc: offered},
+		{name: "listing error only warns", check: "fail", model: "gpt-5-mini", svc: listingLLM{err: errors.New("unreachable")}},
+		{name: "provider cannot list", check: "fail", model: "gpt-9", svc: llm.Disabled{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{LLMProvider: "copilot", CopilotModel: tt.model, ModelCheck: tt.check}
+			err := checkModel(cfg, tt.svc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkModel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, llm.ErrUnknownModel) {
+				t.Errorf("checkModel() error = %v, want ErrUnknownModel", err)
+			}
+		})
+	}
+}
+
+func TestPublishInstanceStats_Twice(t *testing.T) {
+	publishInstanceStats(nil)
+	// A second server in the same process must not panic on Publish
+	publishInstanceStats([]*pipeline{})
+
+	if got := expvar.Get("instances").String(); got != "{}" {
+		t.Errorf("instances = %s, want the pipelines last published", got)
+	}
+}
+
+func TestPublishBreakers_Twice(t *testing.T) {
+	readiness := health.NewChecker(time.Second, 0)
+	publishBreakers(readiness, nil)
+	// A second server in the same process must not panic on Publish
+	publishBreakers(readiness, []*breaker.Breaker{breaker.New("llm", breaker.Config{})})
+
+	if got := expvar.Get("breakers").String(); !strings.Contains(got, `"llm"`) {
+		t.Errorf("breakers = %s, want the breakers last published", got)
+	}
+}
+
+func TestParseFlags_FlagsOverrideEnv(t *testing.T) {
+	t.Setenv("PORT", "9000")
+	t.Setenv("WEBHOOK_WORKERS", "3")
+
+	cfg, _, err := parseFlags("serve", serveUsage, []string{"--port", "7000", "--shutdown-timeout", "30s"}, nil)
+	if err != nil {
+		t.Fatalf("parseFlags() error = %v", err)
+	}
+
+	if cfg.Port != "7000" {
+		t.Errorf("Port = %q, want %q", cfg.Port, "7000")
+	}
+	if cfg.WebhookWorkers != 3 {
+		t.Errorf("WebhookWorkers = %d, want 3 (from env)", cfg.WebhookWorkers)
+	}
+	if cfg.ShutdownTimeout != 30*time.Second {
+		t.Errorf("ShutdownTimeout = %v, want 30s", cfg.ShutdownTimeout)
+	}
+}
+
+func TestParseFlags_UsageHidesSecrets(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "ghp_supersecret")
+
+	_, fs, err := parseFlags("serve", serveUsage, nil, nil)
+	if err != nil {
+		t.Fatalf("parseFlags() error = %v", err)
+	}
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+	fs.Usage()
+
+	help := out.String()
+	if strings.Contains(help, "ghp_supersecret") {
+		t.Error("help output leaks GITHUB_TOKEN value")
+	}
+	if !strings.Contains(help, "[GITHUB_TOKEN]") {
+		t.Error("help output should mention the GITHUB_TOKEN env var")
+	}
+}
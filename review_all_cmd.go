@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"prmate/internal/audit"
+	"prmate/internal/config"
+	"prmate/internal/github"
+	"prmate/internal/review"
+	"prmate/internal/webhook"
+)
+
+const reviewAllUsage = `Usage: prmate review-all --repo <owner/repo> [--instance <name>] [--list] [flags]
+
+Reviews every open pull request that has not been reviewed at its current
+head, one after another. Use --list to only show what would be reviewed.
+`
+
+// runReviewAll implements `prmate review-all`
+func runReviewAll(args []string) int {
+	var repoName, instanceName string
+	var listOnly bool
+	cfg, _, err := parseFlags("review-all", reviewAllUsage, args, func(fs *flag.FlagSet) {
+		fs.StringVar(&repoName, "repo", "", "Repository to review, as owner/repo")
+		fs.StringVar(&instanceName, "instance", "default", "SCM instance the repository lives on (see --scm-instances)")
+		fs.BoolVar(&listOnly, "list", false, "List open pull requests and whether they would be reviewed, without reviewing")
+	})
+	if err != nil {
+		return exitCode(err)
+	}
+
+	owner, repo, err := github.ParseRepoFullName(repoName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--repo: %v\n", err)
+		return 2
+	}
+	inst, err := findInstance(cfg, instanceName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	githubClient, err := newInstanceClient(inst)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "create github client: %v\n", err)
+		return 2
+	}
+	githubClient.SetDryRun(cfg.DryRun, cfg.DryRunRepoList())
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ctx = audit.WithReason(ctx, "batch review")
+
+	llmSvc := newLLMService(cfg)
+	branding, err := newBranding(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	reviewSvc := review.NewService(githubClient, llmSvc, review.Config{
+		LLMTimeout:    cfg.LLMTimeout,
+		ReviewTimeout: cfg.ReviewTimeout,
+		Offline:       cfg.Offline,
+		Branding:      branding,
+		SecretScan:    cfg.SecretScan,
+		WorkflowScan:  cfg.WorkflowScan,
+		Persona:       cfg.ReviewPersona,
+	})
+	processor := webhook.NewProcessor(nil, nil, reviewSvc, githubClient)
+	notifier, err := newNotifier(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	processor.SetNotifier(notifier)
+	defer notifier.Flush(context.Background())
+
+	candidates, err := processor.PlanBatchReview(ctx, owner, repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "plan batch review: %v\n", err)
+		return 1
+	}
+	pending := printBatchPlan(os.Stdout, candidates)
+	if listOnly || pending == 0 {
+		return 0
+	}
+
+	if err := llmSvc.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "start LLM service: %v\n", err)
+		return 1
+	}
+	defer llmSvc.Stop()
+
+	failed := 0
+	for _, c := range candidates {
+		if c.Skip != "" {
+			continue
+		}
+		result, err := processor.ReviewPullRequest(ctx, owner, repo, c.PRNumber)
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "interrupted")
+			return 1
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stdout, "#%d: failed: %v\n", c.PRNumber, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "#%d: %d files reviewed, %d findings\n", c.PRNumber, result.FilesReviewed, result.ViolationsFound)
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d reviews failed\n", failed, pending)
+		return 1
+	}
+	return 0
+}
+
+// findInstance returns the configured SCM instance called name
+func findInstance(cfg *config.Config, name string) (config.SCMInstance, error) {
+	instances, err := cfg.SCMInstances()
+	if err != nil {
+		return config.SCMInstance{}, err
+	}
+	for _, inst := range instances {
+		if strings.EqualFold(inst.Name, name) {
+			return inst, nil
+		}
+	}
+	return config.SCMInstance{}, fmt.Errorf("unknown scm instance %q", name)
+}
+
+// printBatchPlan writes one line per open PR and returns how many will be
+// reviewed
+func printBatchPlan(w io.Writer, candidates []webhook.BatchCandidate) int {
+	pending := 0
+	for _, c := range candidates {
+		action := "review"
+		if c.Skip != "" {
+			action = "skip: " + c.Skip
+		} else {
+			pending++
+		}
+		fmt.Fprintf(w, "#%-6d %-40.40s %s\n", c.PRNumber, c.Title, action)
+	}
+	fmt.Fprintf(w, "\n%d open pull requests, %d to review\n\n", len(candidates), pending)
+	return pending
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"prmate/internal/review"
+)
+
+const reviewUsage = `Usage: prmate review [--diff <range> | --staged] [--dir <path>] [flags]
+
+Reviews local changes against the checkout's .prmate.md and prints findings
+as they are found. LLM provider flags and environment variables are the same as for the server.
+`
+
+// runReview implements `prmate review`: it reviews a local diff against the
+// checkout's .prmate.md and prints findings, without touching GitHub
+func runReview(args []string) int {
+	var diffRange, dir string
+	var staged bool
+	cfg, _, err := parseFlags("review", reviewUsage, args, func(fs *flag.FlagSet) {
+		fs.StringVar(&diffRange, "diff", "", "Git revision range to review, e.g. HEAD~1..HEAD")
+		fs.BoolVar(&staged, "staged", false, "Review staged changes (the default when --diff is not given)")
+		fs.StringVar(&dir, "dir", ".", "Git checkout to review")
+	})
+	if err != nil {
+		return exitCode(err)
+	}
+	if diffRange == "" {
+		staged = true
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	repo := review.NewLocalRepo(dir)
+	files, headRef, err := repo.Diff(ctx, diffRange, staged)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read diff: %v\n", err)
+		return 1
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stdout, "No changes to review.")
+		return 0
+	}
+
+	llmSvc, err := startLLM(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer llmSvc.Stop()
+
+	svc := review.NewLocalService(repo, llmSvc, review.Config{
+		LLMTimeout:    cfg.LLMTimeout,
+		ReviewTimeout: cfg.ReviewTimeout,
+		Offline:       cfg.Offline,
+		SecretScan:    cfg.SecretScan,
+		WorkflowScan:  cfg.WorkflowScan,
+		Persona:       cfg.ReviewPersona,
+		Concurrency:   cfg.ReviewConcurrency,
+	})
+	// Findings are printed as they arrive, streamed when the provider can
+	svc.SetFindingHandler(func(v review.FileViolation) {
+		printFinding(os.Stdout, v)
+	})
+	result, err := svc.ReviewFiles(ctx, review.ReviewRequest{HeadRef: headRef}, files)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "review: %v\n", err)
+		return 1
+	}
+
+	printSummary(os.Stdout, result)
+	return 0
+}
+
+// printFindings writes one line per violation in file:line order, followed
+// by a summary
+func printFindings(w io.Writer, result *review.ReviewResult) {
+	violations := append([]review.FileViolation(nil), result.Violations...)
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Path != violations[j].Path {
+			return violations[i].Path < violations[j].Path
+		}
+		return violations[i].Line < violations[j].Line
+	})
+
+	for _, v := range violations {
+		printFinding(w, v)
+	}
+	printSummary(w, result)
+}
+
+// printFinding writes one violation as a line
+func printFinding(w io.Writer, v review.FileViolation) {
+	fmt.Fprintf(w, "%s:%d: [%s] %s: %s\n", v.Path, v.Line, v.Severity, v.Rule, v.Message)
+}
+
+// printSummary writes the counts of a review
+func printSummary(w io.Writer, result *review.ReviewResult) {
+	fmt.Fprintf(w, "\n%d files reviewed, %d findings\n", result.FilesReviewed, result.ViolationsFound)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"prmate/internal/scan"
+	"prmate/internal/scanner"
+)
+
+const scanUsage = `Usage: prmate scan [flags] [<path> | <owner/repo>]
+
+Scans a local checkout (default ".") or a remote repository and generates .prmate.md.
+With --dry-run the scan runs but nothing is written.
+`
+
+// runScan implements `prmate scan`: it scans a local checkout or a remote
+// repository and writes the generated .prmate.md
+func runScan(args []string) int {
+	var output, externals string
+	var toStdout bool
+	cfg, fs, err := parseFlags("scan", scanUsage, args, func(fs *flag.FlagSet) {
+		fs.StringVar(&output, "output", "", "File to write (default <path>/.prmate.md for a local checkout)")
+		fs.BoolVar(&toStdout, "stdout", false, "Print the generated .prmate.md instead of writing it (the default for remote repos)")
+		fs.StringVar(&externals, "scan", "", "Comma-separated external repos to learn from, like the @scan directive (default: the checkout's @scan list)")
+	})
+	if err != nil {
+		return exitCode(err)
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+	target := "."
+	if fs.NArg() == 1 {
+		target = fs.Arg(0)
+	}
+
+	local := isDir(target)
+	if output == "" && local {
+		output = filepath.Join(target, ".prmate.md")
+	}
+	if output == "" {
+		toStdout = true
+	}
+
+	externalRepos := splitRepos(externals)
+	if externals == "" && local {
+		externalRepos = scanDirectiveRepos(filepath.Join(target, ".prmate.md"))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if cfg.ScanTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.ScanTimeout)
+		defer cancel()
+	}
+
+	content, err := scan.Generate(ctx, target, externalRepos, cfg.GitHubToken, cfg.CloneTimeout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scan: %v\n", err)
+		return 1
+	}
+
+	switch {
+	case toStdout:
+		fmt.Fprint(os.Stdout, content)
+	case cfg.DryRun:
+		fmt.Fprintf(os.Stdout, "dry run: would write %d bytes to %s (%d external repos)\n", len(content), output, len(externalRepos))
+	default:
+		if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "write %s: %v\n", output, err)
+			return 1
+		}
+		fmt.Fprintf(os.Stdout, "Wrote %s\n", output)
+	}
+	return 0
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// splitRepos parses a comma- or whitespace-separated repo list
+func splitRepos(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
+// scanDirectiveRepos returns the @scan list of an existing .prmate.md, if any
+func scanDirectiveRepos(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	reader := scanner.NewInstructionsReader()
+	if !reader.HasScanDirective(string(data)) {
+		return nil
+	}
+	return reader.ParseScanDirective(string(data))
+}
@@ -0,0 +1,712 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"prmate/internal/adaptive"
+	"prmate/internal/artifacts"
+	"prmate/internal/audit"
+	"prmate/internal/breaker"
+	"prmate/internal/budget"
+	"prmate/internal/changelog"
+	"prmate/internal/config"
+	"prmate/internal/conflicts"
+	"prmate/internal/deps"
+	"prmate/internal/digest"
+	"prmate/internal/errreport"
+	"prmate/internal/explain"
+	"prmate/internal/export"
+	"prmate/internal/fix"
+	"prmate/internal/github"
+	"prmate/internal/handlers"
+	"prmate/internal/health"
+	"prmate/internal/jobs"
+	"prmate/internal/learn"
+	"prmate/internal/llm"
+	"prmate/internal/notify"
+	"prmate/internal/prworkspace"
+	"prmate/internal/quality"
+	"prmate/internal/review"
+	"prmate/internal/scan"
+	"prmate/internal/server"
+	"prmate/internal/smoke"
+	"prmate/internal/stale"
+	"prmate/internal/state"
+	"prmate/internal/store"
+	"prmate/internal/tickets"
+	"prmate/internal/tracing"
+	"prmate/internal/triage"
+	"prmate/internal/version"
+	"prmate/internal/webhook"
+)
+
+const serveUsage = `Usage: prmate serve [flags]
+
+Runs the webhook server. This is the default when no command is given.
+Every flag can also be set through the environment variable shown in brackets.
+Flags take precedence over environment variables, which take precedence over
+the config file.
+`
+
+// pipeline holds the services wired for one SCM instance
+type pipeline struct {
+	instance     config.SCMInstance
+	githubClient *github.Client
+	workspace    *prworkspace.Manager
+	scanSvc      *scan.Service
+	reviewSvc    *review.Service
+	processor    *webhook.Processor
+	async        *webhook.AsyncProcessor
+}
+
+// runServe implements `prmate serve`: the long-running webhook server
+func runServe(args []string) int {
+	cfg, _, err := parseFlags("serve", serveUsage, args, nil)
+	if err != nil {
+		return exitCode(err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fatal("Invalid configuration", "error", err)
+	}
+
+	build := version.Get()
+	slog.Info("Starting PRMate", "version", build.Version, "commit", build.Commit, "build_date", build.BuildDate, "go_version", build.GoVersion)
+
+	instances, err := cfg.SCMInstances()
+	if err != nil {
+		fatal("Invalid SCM configuration", "error", err)
+	}
+
+	if err := errreport.Setup(errreport.Config{
+		SentryDSN:   cfg.SentryDSN,
+		WebhookURL:  cfg.ErrorReportURL,
+		Environment: cfg.Environment,
+		Release:     build.String(),
+	}); err != nil {
+		fatal("Failed to set up error reporting", "error", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Endpoint:    cfg.OTLPEndpoint,
+		ServiceName: cfg.OTELServiceName,
+	})
+	if err != nil {
+		fatal("Failed to set up tracing", "error", err)
+	}
+
+	// Initialize LLM service based on configuration
+	llmSvc, err := startLLM(cfg)
+	if err != nil {
+		fatal("Failed to start LLM service", "error", err)
+	}
+
+	readiness := health.NewChecker(cfg.ReadinessCheckTimeout, cfg.ReadinessCacheTTL)
+	readiness.Register("llm", llmSvc.Ping)
+
+	reviewStore, err := store.OpenFileStore(cfg.ReviewStorePath)
+	if err != nil {
+		fatal("Failed to open review store", "path", cfg.ReviewStorePath, "error", err)
+	}
+	dashboard := handlers.NewDashboardHandler(reviewStore)
+
+	// Review state outlives the summary comments it is also kept in
+	var reviewState state.Store
+	if cfg.StateDBPath != "none" {
+		db, err := state.OpenSQLite(cfg.StateDBPath)
+		if err != nil {
+			fatal("Failed to open review state", "path", cfg.StateDBPath, "error", err)
+		}
+		reviewState = db
+	}
+
+	auditLog, err := audit.OpenLog(cfg.AuditLogPath)
+	if err != nil {
+		fatal("Failed to open audit log", "path", cfg.AuditLogPath, "error", err)
+	}
+
+	var artifactStore artifacts.Store
+	if cfg.ArtifactStoreURL != "" {
+		artifactStore, err = artifacts.Open(cfg.ArtifactStoreURL, artifacts.Credentials{
+			AccessKeyID:     cfg.ArtifactAccessKeyID,
+			SecretAccessKey: cfg.ArtifactSecretKey,
+		})
+		if err != nil {
+			fatal("Failed to configure artifact store", "error", err)
+		}
+	}
+
+	notifier, err := newNotifier(cfg)
+	if err != nil {
+		fatal("Failed to configure notifications", "error", err)
+	}
+
+	ticketFiler, err := newTicketFiler(cfg)
+	if err != nil {
+		fatal("Failed to configure ticket routes", "error", err)
+	}
+
+	// Manual reviews and scans triggered through the admin API
+	jobQueue := jobs.NewQueue(jobs.Config{QueueSize: cfg.WebhookQueueSize, Workers: cfg.WebhookWorkers})
+	jobQueue.SetEventEmitter(notifier)
+	adminHandler := handlers.NewAdminHandler(cfg, jobQueue)
+	if lister, ok := llmSvc.(llm.ModelLister); ok {
+		adminHandler.SetModelLister(lister)
+	}
+	dashboard.AddQueue("jobs", jobQueue)
+
+	// Background maintenance stops once intake has stopped during shutdown
+	janitorCtx, stopJanitors := context.WithCancel(context.Background())
+	defer stopJanitors()
+
+	// Object stores expire artifacts through their own lifecycle rules
+	if files, ok := artifactStore.(*artifacts.FileStore); ok {
+		files.StartJanitor(janitorCtx, cfg.ArtifactRetention, cfg.WorkspaceCleanup)
+	}
+
+	digests, err := cfg.Digests()
+	if err != nil {
+		fatal("Invalid digest configuration", "error", err)
+	}
+	if len(digests) > 0 {
+		mailer := digest.NewSMTP(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+		digest.NewScheduler(reviewStore, mailer, digests, cfg.DigestHour).Start(janitorCtx)
+		slog.Info("Scheduled review digests", "digests", len(digests), "hour_utc", cfg.DigestHour)
+	}
+
+	if cfg.ExportURL != "" {
+		sink, err := export.Open(cfg.ExportURL, cfg.ExportCredentialsFile)
+		if err != nil {
+			fatal("Failed to configure review export", "error", err)
+		}
+		export.NewExporter(reviewStore, sink, cfg.ExportInterval).Start(janitorCtx)
+		slog.Info("Exporting review records", "url", cfg.ExportURL, "interval", cfg.ExportInterval)
+	}
+
+	var reports *quality.Scheduler
+	if repos := cfg.QualityReportRepoList(); len(repos) > 0 {
+		reports = quality.NewScheduler(reviewStore, repos, cfg.DigestHour)
+	}
+
+	stalePolicies, err := cfg.StalePolicies()
+	if err != nil {
+		fatal("Invalid stale PR configuration", "error", err)
+	}
+	var nudges *stale.Scheduler
+	if len(stalePolicies) > 0 {
+		nudges = stale.NewScheduler(reviewStore, stalePolicies, cfg.DigestHour)
+	}
+
+	workspaceHandler := handlers.NewWorkspaceHandler()
+
+	// Reviews trade fidelity for speed while the queues or the LLM are slow
+	var load *adaptive.Controller
+	loadCfg := adaptive.Config{QueueDepth: cfg.DegradeQueueDepth, Latency: cfg.DegradeLatency, Model: cfg.DegradedModel}
+	if loadCfg.Enabled() && !cfg.Offline {
+		load = adaptive.NewController(loadCfg)
+		load.AddQueue(jobQueue)
+		publishDegradation(load)
+	}
+
+	// Circuit breakers stop calling GitHub or the LLM while they fail;
+	// work refused by an open breaker is retried after the cool-down
+	breakerCfg := breaker.Config{Failures: cfg.BreakerFailures, CoolDown: cfg.BreakerCoolDown}
+	var breakers []*breaker.Breaker
+	guardedLLM := llmSvc
+	if !cfg.Offline {
+		b := breaker.New("llm", breakerCfg)
+		breakers = append(breakers, b)
+		guardedLLM = breakerLLM{LLMService: llmSvc, breaker: b}
+		adminHandler.SetPrompter(guardedLLM)
+	}
+
+	// Build one webhook pipeline per SCM instance; the first is github.com
+	var handler *handlers.Handler
+	pipelines := make([]*pipeline, 0, len(instances))
+	for i, inst := range instances {
+		// Each instance may override server-wide settings, so that one
+		// server can serve several organizations as separate tenants
+		instCfg := cfg.ForInstance(inst)
+		p, err := newPipeline(instCfg, inst, i == 0, guardedLLM, load)
+		if err != nil {
+			fatal("Failed to configure SCM instance", "instance", inst.Name, "error", err)
+		}
+		p.githubClient.SetAuditor(auditLog)
+		if reviewState != nil {
+			p.reviewSvc.SetStateStore(state.WithInstance(reviewState, inst.Name))
+		}
+		githubBreaker := breaker.New("github:"+inst.Name, breakerCfg)
+		p.githubClient.SetBreaker(githubBreaker)
+		breakers = append(breakers, githubBreaker)
+		// Shadow mode writes nothing to GitHub, as in dry-run mode, but
+		// records every review for the shadow report
+		p.githubClient.SetDryRun(instCfg.DryRun || instCfg.ShadowMode, cfg.DryRunRepoList())
+		p.processor.SetShadowMode(instCfg.ShadowMode)
+		pipelines = append(pipelines, p)
+		if load != nil {
+			load.AddQueue(p.async)
+		}
+
+		readiness.Register("github:"+inst.Name, p.githubClient.CheckAuth)
+		readiness.Register("queue:"+inst.Name, p.async.CheckCapacity)
+		readiness.Register("workspace:"+inst.Name, p.workspace.CheckWritable)
+		p.workspace.StartJanitor(janitorCtx, cfg.WorkspaceTTL, cfg.WorkspaceCleanup)
+		p.workspace.SetQuota(int64(instCfg.DiskQuotaBytes), scan.WorkDirGlob())
+		p.scanSvc.SetSpaceChecker(p.workspace)
+		p.processor.SetRecorder(inst.Name, reviewStore)
+		p.processor.SetReviewHistory(reviewStore)
+		if instCfg.ExemptCommand {
+			p.processor.SetExemptions(reviewStore)
+			p.processor.StartExemptionReminders(janitorCtx)
+		}
+		if instCfg.MuteCommand {
+			p.processor.SetPreferences(reviewStore)
+		}
+		limits := budget.Limits{
+			RepoDaily:   instCfg.RepoBudgetDaily,
+			RepoMonthly: instCfg.RepoBudgetMonthly,
+			OrgDaily:    instCfg.OrgBudgetDaily,
+			OrgMonthly:  instCfg.OrgBudgetMonthly,
+		}
+		if limits.Enabled() && !instCfg.Offline {
+			p.processor.SetBudget(budget.NewTracker(reviewStore, inst.Name, limits))
+		}
+		// Instances sharing a store keep their artifacts apart
+		instArtifacts := artifacts.WithPrefix(artifactStore, inst.Name)
+		if instArtifacts != nil {
+			p.processor.SetArtifactStore(instArtifacts)
+			p.scanSvc.SetArtifactStore(instArtifacts)
+		}
+		p.processor.SetNotifier(notifier)
+		p.processor.SetJobs(jobQueue)
+		if ticketFiler != nil {
+			p.processor.SetTicketFiler(ticketFiler)
+		}
+		p.scanSvc.SetEventEmitter(notifier)
+		dashboard.AddQueue("webhook:"+inst.Name, p.async)
+		dashboard.AddRules(inst.Name, p.githubClient)
+		dashboard.AddRepos(inst.Name, p.githubClient)
+		dashboard.AddHumanReviews(inst.Name, p.githubClient)
+		adminHandler.AddReviewer(inst.Name, p.processor)
+		adminHandler.AddScanner(inst.Name, p.scanSvc)
+		adminHandler.AddRules(inst.Name, p.githubClient)
+		if !instCfg.Offline {
+			adminHandler.AddLearner(inst.Name, learn.NewLearner(p.githubClient, guardedLLM))
+		}
+		workspaceHandler.AddManager(inst.Name, p.workspace)
+		if reports != nil {
+			reports.AddInstance(inst.Name, p.githubClient)
+		}
+		if nudges != nil {
+			nudges.AddInstance(inst.Name, p.githubClient, p.reviewSvc)
+		}
+
+		if handler == nil {
+			handler = handlers.NewHandler(p.async, inst.WebhookSecret)
+			continue
+		}
+		slog.Info("Routing webhooks to SCM instance", "host", inst.Host, "instance", inst.Name, "routes", inst.Routes)
+		for _, key := range inst.Routes {
+			handler.AddWebhookRoute(key, inst.WebhookSecret, p.async)
+		}
+	}
+	publishInstanceStats(pipelines)
+	publishBreakers(readiness, breakers)
+
+	if reports != nil {
+		reports.Start(janitorCtx)
+		slog.Info("Scheduled weekly quality reports", "repos", cfg.QualityReportRepos, "hour_utc", cfg.DigestHour)
+	}
+	if nudges != nil {
+		nudges.Start(janitorCtx)
+		slog.Info("Scheduled stale pull request nudges", "policies", len(stalePolicies), "hour_utc", cfg.DigestHour)
+	}
+
+	// Setup HTTP server
+	srv := server.NewServer(cfg)
+
+	// Bound request rate and size on everything that accepts input
+	limiter := server.NewRateLimiter(cfg.RateLimitRPS, cfg.RateLimitBurst)
+	webhookLimiter := server.NewRateLimiter(cfg.WebhookRPS, cfg.RateLimitBurst)
+	maxBody := server.MaxBodySize(int64(cfg.MaxBodyBytes))
+
+	// Register routes
+	srv.Router().GET("/health", handler.Health)
+	srv.Router().GET("/livez", handler.Livez)
+	srv.Router().GET("/readyz", handlers.Readyz(readiness))
+	srv.Router().GET("/version", handlers.Version)
+	public := srv.Router().Group("", webhookLimiter.Middleware(), maxBody)
+	public.POST("/webhook", handler.GitHubWebhook)
+
+	// Admin routes can change state; read-only routes only expose activity.
+	// Both live on the admin listener when ADMIN_PORT is set.
+	auth := server.NewAPIKeyAuthenticator(cfg.AdminAPIKey, cfg.ReadOnlyKeys())
+	admin := srv.AdminRouter().Group("/api", limiter.Middleware(), maxBody, server.RequireRole(auth, server.RoleAdmin))
+	admin.GET("/config", adminHandler.Config)
+	admin.GET("/models", adminHandler.Models)
+	admin.POST("/reviews", adminHandler.TriggerReview)
+	admin.POST("/reviews/batch", adminHandler.TriggerBatchReview)
+	admin.POST("/scans", adminHandler.TriggerScan)
+	admin.POST("/learn", adminHandler.TriggerLearn)
+	admin.POST("/prompt", adminHandler.Prompt)
+	admin.DELETE("/workspaces/:owner/:repo/:pr", workspaceHandler.Delete)
+	admin.POST("/workspaces/cleanup", workspaceHandler.Cleanup)
+	readOnly := srv.AdminRouter().Group("/api", limiter.Middleware(), maxBody, server.RequireRole(auth, server.RoleReadOnly))
+	readOnly.GET("/jobs/:id", adminHandler.GetJob)
+	readOnly.GET("/dashboard/reviews", dashboard.Reviews)
+	readOnly.GET("/dashboard/stats", dashboard.Stats)
+	readOnly.GET("/orgs/:org/compliance", dashboard.Compliance)
+	readOnly.GET("/shadow/:owner/:repo", dashboard.ShadowReport)
+	readOnly.GET("/audit", handlers.NewAuditHandler(auditLog).List)
+	readOnly.GET("/workspaces", workspaceHandler.List)
+	srv.AdminRouter().GET("/dashboard", dashboard.Page)
+	server.RegisterDebugRoutes(srv.AdminRouter().Group("/debug", server.RequireRole(auth, server.RoleAdmin)))
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Start()
+	}()
+
+	// Wait for interrupt signal for graceful shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case <-quit:
+		slog.Info("Shutdown signal received")
+	case err := <-errCh:
+		if err != nil {
+			slog.Error("Server error", "error", err)
+		}
+	}
+
+	// Graceful shutdown with timeout, in phases so nothing is accepted into a
+	// queue that is already draining and nothing still running loses its LLM
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Phase 1: stop intake. In-flight requests finish enqueueing first.
+	slog.Info("Shutdown: stopping intake")
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Error("Server forced to shutdown", "error", err)
+	}
+
+	// Phase 2: drain queued webhooks and jobs concurrently
+	slog.Info("Shutdown: draining queues")
+	stopJanitors()
+	var drain sync.WaitGroup
+	for _, p := range pipelines {
+		drain.Go(func() {
+			if err := p.async.Stop(ctx); err != nil {
+				slog.Error("Webhook processor shutdown error", "instance", p.instance.Name, "error", err)
+			}
+		})
+	}
+	drain.Go(func() {
+		if err := jobQueue.Stop(ctx); err != nil {
+			slog.Error("Job queue shutdown error", "error", err)
+		}
+	})
+	drain.Wait()
+
+	// Phase 3: stop clients now that no worker can use them
+	slog.Info("Shutdown: stopping LLM service")
+	if err := llmSvc.Stop(); err != nil {
+		slog.Error("LLM service shutdown error", "error", err)
+	}
+
+	if err := notifier.Flush(ctx); err != nil {
+		slog.Error("Notification flush error", "error", err)
+	}
+
+	if err := errreport.Flush(ctx); err != nil {
+		slog.Error("Error report flush error", "error", err)
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("Tracing shutdown error", "error", err)
+	}
+
+	slog.Info("Server exited")
+	return 0
+}
+
+// fatal logs msg at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// newNotifier returns the dispatcher for every notification destination
+// and event webhook configured in cfg
+func newNotifier(cfg *config.Config) (*notify.Dispatcher, error) {
+	d := notify.NewDispatcher()
+	if cfg.TeamsWebhookURL != "" {
+		d.Add("teams", notify.NewTeams(cfg.TeamsWebhookURL))
+	}
+	if cfg.DiscordWebhookURL != "" {
+		discord := notify.NewDiscord(cfg.DiscordWebhookURL, notify.DiscordOptions{
+			Username:  cfg.DiscordUsername,
+			AvatarURL: cfg.DiscordAvatarURL,
+			Findings:  cfg.DiscordFindings,
+			Scans:     cfg.DiscordScans,
+		})
+		d.Add("discord", discord)
+		d.AddSink("discord", discord)
+	}
+
+	channels, err := cfg.NotifyChannels()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range slices.Sorted(maps.Keys(channels)) {
+		ch := channels[name]
+		if ch.Teams != "" {
+			d.AddChannel(name, "teams:"+name, notify.NewTeams(ch.Teams))
+		}
+		if ch.Discord != "" {
+			d.AddChannel(name, "discord:"+name, notify.NewDiscord(ch.Discord, notify.DiscordOptions{
+				Username:  cfg.DiscordUsername,
+				AvatarURL: cfg.DiscordAvatarURL,
+				Findings:  cfg.DiscordFindings,
+			}))
+		}
+	}
+
+	types := cfg.EventWebhookEventList()
+	for _, t := range types {
+		if !slices.Contains(notify.EventTypes, t) {
+			return nil, fmt.Errorf("EVENT_WEBHOOK_EVENTS: unknown event type %q (want %s)", t, strings.Join(notify.EventTypes, ", "))
+		}
+	}
+	for _, u := range cfg.EventWebhookURLList() {
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("EVENT_WEBHOOK_URLS: invalid URL %q", u)
+		}
+		d.AddSink("webhook:"+parsed.Host, notify.NewWebhook(u, cfg.EventWebhookSecret, types))
+	}
+	return d, nil
+}
+
+// newTicketFiler returns the filer for the TICKET_ROUTES in cfg, or nil
+// when none are configured
+func newTicketFiler(cfg *config.Config) (*tickets.Filer, error) {
+	routes, err := cfg.TicketRoutes()
+	if err != nil || len(routes) == 0 {
+		return nil, err
+	}
+	trackers := make(map[string]tickets.Tracker)
+	if cfg.JiraBaseURL != "" {
+		trackers["jira"] = tickets.NewJira(cfg.JiraBaseURL, cfg.JiraEmail, cfg.JiraAPIToken)
+	}
+	if cfg.LinearAPIKey != "" {
+		trackers["linear"] = tickets.NewLinear(cfg.LinearAPIKey)
+	}
+	return tickets.NewFiler(routes, trackers), nil
+}
+
+var (
+	publishInstancesOnce   sync.Once
+	instancePipelines      atomic.Pointer[[]*pipeline]
+	publishDegradationOnce sync.Once
+	degradation            atomic.Pointer[adaptive.Controller]
+	publishBreakersOnce    sync.Once
+	breakerList            atomic.Pointer[[]*breaker.Breaker]
+)
+
+// publishInstanceStats exposes the queue fill, delivery counts and shadow
+// reviews of every SCM instance under "instances" in /debug/vars. expvar
+// panics on a second Publish, so a later call only swaps the pipelines.
+func publishInstanceStats(pipelines []*pipeline) {
+	instancePipelines.Store(&pipelines)
+	publishInstancesOnce.Do(func() {
+		expvar.Publish("instances", expvar.Func(instanceStats))
+	})
+}
+
+// instanceStats reports on the pipelines last passed to publishInstanceStats
+func instanceStats() any {
+	pipelines := *instancePipelines.Load()
+	stats := make(map[string]any, len(pipelines))
+	for _, p := range pipelines {
+		depth, capacity := p.async.QueueStats()
+		shadowReviews, shadowFindings := p.processor.ShadowStats()
+		stats[p.instance.Name] = map[string]any{
+			"queue_depth":     depth,
+			"queue_capacity":  capacity,
+			"deliveries":      p.async.Counts(),
+			"held_reviews":    p.processor.HeldReviews(),
+			"shadow_reviews":  shadowReviews,
+			"shadow_findings": shadowFindings,
+		}
+	}
+	return stats
+}
+
+// publishBreakers reports every circuit breaker under "breakers" in
+// /debug/vars, and an open one as a failing /readyz check
+func publishBreakers(readiness *health.Checker, breakers []*breaker.Breaker) {
+	for _, b := range breakers {
+		readiness.Register("breaker:"+b.Name(), b.Check)
+	}
+	breakerList.Store(&breakers)
+	publishBreakersOnce.Do(func() {
+		expvar.Publish("breakers", expvar.Func(func() any {
+			breakers := *breakerList.Load()
+			states := make(map[string]breaker.State, len(breakers))
+			for _, b := range breakers {
+				states[b.Name()] = b.State()
+			}
+			return states
+		}))
+	})
+}
+
+// publishDegradation reports the adaptive load state under "degradation" in
+// /debug/vars; like publishInstanceStats, a later call only swaps the controller
+func publishDegradation(load *adaptive.Controller) {
+	degradation.Store(load)
+	publishDegradationOnce.Do(func() {
+		expvar.Publish("degradation", expvar.Func(func() any { return degradation.Load().State() }))
+	})
+}
+
+// breakerLLM fails LLM calls at once while its breaker is open. Calls
+// abandoned by their caller are not counted as failures.
+type breakerLLM struct {
+	LLMService
+	breaker *breaker.Breaker
+}
+
+func (l breakerLLM) GenerateText(prompt string) (string, error) {
+	return l.GenerateTextWithContext(context.Background(), prompt)
+}
+
+func (l breakerLLM) GenerateTextWithContext(ctx context.Context, prompt string) (string, error) {
+	if err := l.breaker.Allow(); err != nil {
+		return "", err
+	}
+	out, err := l.LLMService.GenerateTextWithContext(ctx, prompt)
+	l.breaker.Done(err != nil && !errors.Is(ctx.Err(), context.Canceled))
+	return out, err
+}
+
+// newInstanceClient returns the GitHub client for inst: github.com, or a
+// GitHub Enterprise Server when the instance has an API URL
+func newInstanceClient(inst config.SCMInstance) (*github.Client, error) {
+	if inst.APIURL == "" {
+		return github.NewClient(inst.Token), nil
+	}
+	return github.NewEnterpriseClient(inst.Token, inst.APIURL)
+}
+
+// newPipeline wires the GitHub client, workspace, scan and review services
+// for a single SCM instance behind an async webhook processor. Reviews
+// degrade under load when load is not nil.
+func newPipeline(cfg *config.Config, inst config.SCMInstance, isDefault bool, llmSvc LLMService, load *adaptive.Controller) (*pipeline, error) {
+	githubClient, err := newInstanceClient(inst)
+	if err != nil {
+		return nil, err
+	}
+	workBaseDir := cfg.WorkBaseDir
+	switch {
+	case isDefault:
+	case inst.SharesHost():
+		// Tenants on one host must not share PR workspaces
+		workBaseDir = filepath.Join(cfg.WorkBaseDir, inst.Host+"@"+inst.Name)
+	default:
+		workBaseDir = filepath.Join(cfg.WorkBaseDir, inst.Host)
+	}
+
+	prWorkspaceMgr := prworkspace.NewManager(workBaseDir)
+	prWorkspaceMgr.SetRemote(githubClient, cfg.CloneTimeout)
+	scanSvc := scan.NewService(githubClient, scan.Config{
+		CloneTimeout: cfg.CloneTimeout,
+		ScanTimeout:  cfg.ScanTimeout,
+	})
+	branding, err := newBranding(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var reviewLLM review.LLMProvider = llmSvc
+	if load != nil {
+		reviewLLM = load.Wrap(llmSvc)
+	}
+	reviewSvc := review.NewService(githubClient, reviewLLM, review.Config{
+		LLMTimeout:       cfg.LLMTimeout,
+		ReviewTimeout:    cfg.ReviewTimeout,
+		Concurrency:      cfg.ReviewConcurrency,
+		ProgressInterval: cfg.ProgressInterval,
+		Offline:          cfg.Offline,
+		Branding:         branding,
+		SecretScan:       cfg.SecretScan,
+		WorkflowScan:     cfg.WorkflowScan,
+		Persona:          cfg.ReviewPersona,
+	})
+	reviewSvc.SetRulesCacheTTL(cfg.RulesCacheTTL)
+	if load != nil {
+		reviewSvc.SetLoad(load)
+	}
+	webhookProc := webhook.NewProcessor(prWorkspaceMgr, scanSvc, reviewSvc, githubClient)
+	if cfg.FixCommand && !cfg.Offline {
+		webhookProc.SetFixer(fix.NewService(llmSvc, githubClient))
+	}
+	if cfg.ExplainCommand && !cfg.Offline {
+		webhookProc.SetExplainer(explain.NewExplainer(githubClient, llmSvc))
+	}
+	if cfg.ConflictHelp {
+		var explainer conflicts.LLMProvider
+		if !cfg.Offline {
+			explainer = llmSvc
+		}
+		webhookProc.SetConflictChecker(conflicts.NewHelper(githubClient, explainer, cfg.ConflictDiffs))
+	}
+	if cfg.Changelog {
+		var drafter changelog.LLMProvider
+		if !cfg.Offline {
+			drafter = llmSvc
+		}
+		webhookProc.SetChangelogChecker(changelog.NewChecker(githubClient, drafter))
+	}
+	if cfg.Onboarding {
+		webhookProc.SetOnboarder(scanSvc)
+	}
+	if cfg.IssueTriage && !cfg.Offline {
+		webhookProc.SetTriager(triage.NewTriager(githubClient, llmSvc))
+	}
+	if commands := smoke.ParseCommands(cfg.SmokeChecks); len(commands) > 0 {
+		webhookProc.SetSmoke(smoke.NewRunner(commands, cfg.SmokeTimeout))
+	}
+	if cfg.DepsReview {
+		var registry deps.Registry
+		if cfg.DepsRegistry != "none" {
+			registry = deps.NewDepsDev(cfg.DepsRegistry)
+		}
+		webhookProc.SetDependencyReviewer(deps.NewReviewer(githubClient, registry))
+	}
+	webhookAsync := webhook.NewAsyncProcessor(webhookProc, webhook.AsyncConfig{QueueSize: cfg.WebhookQueueSize, Workers: cfg.WebhookWorkers})
+
+	return &pipeline{
+		instance:     inst,
+		githubClient: githubClient,
+		workspace:    prWorkspaceMgr,
+		scanSvc:      scanSvc,
+		reviewSvc:    reviewSvc,
+		processor:    webhookProc,
+		async:        webhookAsync,
+	}, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"prmate/internal/artifacts"
+	"prmate/internal/config"
+	"prmate/internal/export"
+	"prmate/internal/review"
+	"prmate/internal/scanner"
+)
+
+const validateUsage = `Usage: prmate validate [flags] [<path to .prmate.md or checkout>]
+
+Checks the configuration the server would start with and, when present, a
+.prmate.md file (default ./.prmate.md). Exits 1 if any problem is found.
+`
+
+// runValidate implements `prmate validate`
+func runValidate(args []string) int {
+	cfg, fs, err := parseFlags("validate", validateUsage, args, nil)
+	if err != nil {
+		return exitCode(err)
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+
+	problems := validateConfig(cfg)
+
+	path, explicit := ".prmate.md", fs.NArg() == 1
+	if explicit {
+		path = fs.Arg(0)
+		if isDir(path) {
+			path = filepath.Join(path, ".prmate.md")
+		}
+	}
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		summary, fileProblems := validateRulesFile(string(content))
+		fmt.Fprintf(os.Stdout, "%s: %s\n", path, summary)
+		for _, p := range fileProblems {
+			problems = append(problems, path+": "+p)
+		}
+	case os.IsNotExist(err) && !explicit:
+		fmt.Fprintf(os.Stdout, "%s: not found, skipped\n", path)
+	default:
+		problems = append(problems, err.Error())
+	}
+
+	for _, p := range problems {
+		fmt.Fprintf(os.Stdout, "✗ %s\n", p)
+	}
+	if len(problems) > 0 {
+		return 1
+	}
+	fmt.Fprintln(os.Stdout, "✓ configuration is valid")
+	return 0
+}
+
+// validateConfig returns one line per configuration problem
+func validateConfig(cfg *config.Config) []string {
+	var problems []string
+	if err := cfg.Validate(); err != nil {
+		problems = append(problems, strings.Split(err.Error(), "\n")...)
+	}
+	if cfg.ArtifactStoreURL != "" {
+		if _, err := artifacts.Open(cfg.ArtifactStoreURL, artifacts.Credentials{
+			AccessKeyID:     cfg.ArtifactAccessKeyID,
+			SecretAccessKey: cfg.ArtifactSecretKey,
+		}); err != nil {
+			problems = append(problems, fmt.Sprintf("ARTIFACT_STORE_URL: %v", err))
+		}
+	}
+	if cfg.ExportURL != "" {
+		if _, err := export.Open(cfg.ExportURL, cfg.ExportCredentialsFile); err != nil {
+			problems = append(problems, fmt.Sprintf("EXPORT_URL: %v", err))
+		}
+	}
+	if _, err := newNotifier(cfg); err != nil {
+		problems = append(problems, err.Error())
+	}
+	return problems
+}
+
+// validateRulesFile summarises what a review would take from a .prmate.md
+// and reports content that would leave it with nothing to check
+func validateRulesFile(content string) (summary string, problems []string) {
+	parsed, err := review.ParseRules(content)
+	if err != nil {
+		return "invalid", []string{err.Error()}
+	}
+	summary = fmt.Sprintf("%d rules, %d checklist items, %d checks", len(parsed.Rules), len(parsed.Checklist), len(parsed.Checks.Checks))
+	if parsed.Checks.Assets != nil {
+		summary += ", asset policy"
+	}
+	if parsed.Checks.Docs != nil {
+		summary += ", docs policy"
+	}
+	if n := len(parsed.Checks.Protect); n > 0 {
+		summary += fmt.Sprintf(", %d protected paths", n)
+	}
+	if parsed.Checks.Language != "" {
+		summary += ", findings in " + parsed.Checks.Language
+	}
+	if parsed.Checks.Window != nil {
+		summary += ", posting window"
+	}
+	if n := len(parsed.Checks.Routes); n > 0 {
+		summary += fmt.Sprintf(", %d routes", n)
+	}
+	if parsed.Persona != "" {
+		summary += ", " + parsed.Persona + " persona"
+	}
+
+	reader := scanner.NewInstructionsReader()
+	if reader.HasScanDirective(content) {
+		summary += fmt.Sprintf(", @scan of %d repos pending", len(reader.ParseScanDirective(content)))
+	}
+	if len(parsed.Rules) == 0 && len(parsed.Checklist) == 0 && parsed.Checks.Empty() && len(parsed.Checks.Routes) == 0 {
+		problems = append(problems, "no rules, checklist items or checks; reviews would have nothing to check against")
+	}
+	return summary, problems
+}
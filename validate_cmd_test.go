@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRulesFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		wantSummary  string
+		wantProblems int
+	}{
+		{
+			name:        "rules and checklist",
+			content:     "# Context\n## Learned Rules\n- Wrap errors with fmt.Errorf and %w\n## Review Checklist\n- [ ] Tests cover the change\n",
+			wantSummary: "1 rules, 1 checklist items, 0 checks",
+		},
+		{
+			name:        "checks only",
+			content:     "```prmate-checks\nid: no-todo\npattern: TODO\n```\n",
+			wantSummary: "0 rules, 0 checklist items, 1 checks",
+		},
+		{
+			name:        "review language",
+			content:     "```prmate-checks\nid: no-todo\npattern: TODO\n\nlanguage: sv\n```\n",
+			wantSummary: "1 checks, findings in sv",
+		},
+		{
+			name:        "persona",
+			content:     "---\npersona: mentor\n---\n# Context\n## Learned Rules\n- Wrap errors\n",
+			wantSummary: "1 rules, 0 checklist items, 0 checks, mentor persona",
+		},
+		{
+			name:         "unknown persona",
+			content:      "---\npersona: pirate\n---\n# Context\n## Learned Rules\n- Wrap errors\n",
+			wantSummary:  "invalid",
+			wantProblems: 1,
+		},
+		{
+			name:         "malformed checks",
+			content:      "```prmate-checks\npattern: TODO\n```\n",
+			wantSummary:  "invalid",
+			wantProblems: 1,
+		},
+		{
+			name:         "nothing to check",
+			content:      "# Context\nJust prose.\n",
+			wantSummary:  "0 rules, 0 checklist items, 0 checks",
+			wantProblems: 1,
+		},
+		{
+			name:         "pending scan",
+			content:      "<!-- PRMate\n@scan\nacme/standards\n-->\n",
+			wantSummary:  "@scan of 1 repos pending",
+			wantProblems: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, problems := validateRulesFile(tt.content)
+			if !strings.Contains(summary, tt.wantSummary) {
+				t.Errorf("summary = %q, want it to contain %q", summary, tt.wantSummary)
+			}
+			if len(problems) != tt.wantProblems {
+				t.Errorf("problems = %v, want %d", problems, tt.wantProblems)
+			}
+		})
+	}
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	if got := run([]string{"frobnicate"}); got != 2 {
+		t.Errorf("run(frobnicate) = %d, want 2", got)
+	}
+}